@@ -1,10 +1,51 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/FirewineXie/envm/internal/arch"
+	"github.com/FirewineXie/envm/internal/commands/commands-bun"
+	"github.com/FirewineXie/envm/internal/commands/commands-ci"
+	"github.com/FirewineXie/envm/internal/commands/commands-daemon"
+	"github.com/FirewineXie/envm/internal/commands/commands-dedup"
+	"github.com/FirewineXie/envm/internal/commands/commands-deno"
+	"github.com/FirewineXie/envm/internal/commands/commands-docs"
+	"github.com/FirewineXie/envm/internal/commands/commands-doctor"
+	"github.com/FirewineXie/envm/internal/commands/commands-env"
+	"github.com/FirewineXie/envm/internal/commands/commands-explain"
+	"github.com/FirewineXie/envm/internal/commands/commands-flutter"
+	"github.com/FirewineXie/envm/internal/commands/commands-generate"
 	"github.com/FirewineXie/envm/internal/commands/commands-go"
+	"github.com/FirewineXie/envm/internal/commands/commands-gotools"
+	"github.com/FirewineXie/envm/internal/commands/commands-gradle"
+	"github.com/FirewineXie/envm/internal/commands/commands-history"
+	"github.com/FirewineXie/envm/internal/commands/commands-hook"
 	"github.com/FirewineXie/envm/internal/commands/commands-java"
+	"github.com/FirewineXie/envm/internal/commands/commands-list"
+	"github.com/FirewineXie/envm/internal/commands/commands-matrix"
+	"github.com/FirewineXie/envm/internal/commands/commands-maven"
+	"github.com/FirewineXie/envm/internal/commands/commands-mirrors"
 	"github.com/FirewineXie/envm/internal/commands/commands-node"
+	"github.com/FirewineXie/envm/internal/commands/commands-notify"
+	"github.com/FirewineXie/envm/internal/commands/commands-outdated"
+	"github.com/FirewineXie/envm/internal/commands/commands-php"
+	"github.com/FirewineXie/envm/internal/commands/commands-plugin"
+	"github.com/FirewineXie/envm/internal/commands/commands-reinstall"
+	"github.com/FirewineXie/envm/internal/commands/commands-release"
+	"github.com/FirewineXie/envm/internal/commands/commands-relocate"
+	"github.com/FirewineXie/envm/internal/commands/commands-remote"
+	"github.com/FirewineXie/envm/internal/commands/commands-sbom"
+	"github.com/FirewineXie/envm/internal/commands/commands-serve"
+	"github.com/FirewineXie/envm/internal/commands/commands-shim"
+	"github.com/FirewineXie/envm/internal/commands/commands-stats"
+	"github.com/FirewineXie/envm/internal/commands/commands-status"
+	"github.com/FirewineXie/envm/internal/commands/commands-sync"
+	"github.com/FirewineXie/envm/internal/commands/commands-tool"
+	"github.com/FirewineXie/envm/internal/commands/commands-try"
+	"github.com/FirewineXie/envm/internal/commands/commands-verify"
+	"github.com/FirewineXie/envm/internal/commands/commands-windows"
+	"github.com/FirewineXie/envm/internal/commands/commands-workspace"
+	"github.com/FirewineXie/envm/internal/commands/commands-zig"
 	"github.com/FirewineXie/envm/internal/config"
 	"github.com/urfave/cli"
 )
@@ -47,38 +88,553 @@ var (
 			},
 			Subcommands: nodeCommands,
 		},
+		{
+			Name:      "deno",
+			Usage:     "envm deno",
+			UsageText: "envm deno",
+			Before: func(context *cli.Context) error {
+				return config.VerifyEnvLanguage(config.DENO)
+			},
+			Subcommands: denoCommands,
+		},
+		{
+			Name:      "bun",
+			Usage:     "envm bun",
+			UsageText: "envm bun",
+			Before: func(context *cli.Context) error {
+				return config.VerifyEnvLanguage(config.BUN)
+			},
+			Subcommands: bunCommands,
+		},
+		{
+			Name:      "zig",
+			Usage:     "envm zig",
+			UsageText: "envm zig",
+			Before: func(context *cli.Context) error {
+				return config.VerifyEnvLanguage(config.ZIG)
+			},
+			Subcommands: zigCommands,
+		},
+		{
+			Name:      "mvn",
+			Usage:     "envm mvn",
+			UsageText: "envm mvn",
+			Before: func(context *cli.Context) error {
+				return config.VerifyEnvLanguage(config.MAVEN)
+			},
+			Subcommands: mavenCommands,
+		},
+		{
+			Name:      "gradle",
+			Usage:     "envm gradle",
+			UsageText: "envm gradle",
+			Before: func(context *cli.Context) error {
+				return config.VerifyEnvLanguage(config.GRADLE)
+			},
+			Subcommands: gradleCommands,
+		},
+		{
+			Name:      "php",
+			Usage:     "envm php",
+			UsageText: "envm php",
+			Before: func(context *cli.Context) error {
+				return config.VerifyEnvLanguage(config.PHP)
+			},
+			Subcommands: phpCommands,
+		},
+		{
+			Name:      "flutter",
+			Usage:     "envm flutter",
+			UsageText: "envm flutter",
+			Before: func(context *cli.Context) error {
+				return config.VerifyEnvLanguage(config.FLUTTER)
+			},
+			Subcommands: flutterCommands,
+		},
+		{
+			Name:        "tool",
+			Usage:       "envm tool, single binary tools released on github or declared in config.toml",
+			UsageText:   "envm tool",
+			Subcommands: toolCommands,
+		},
+		{
+			Name:        "plugin",
+			Usage:       "envm plugin, community maintained language backends",
+			UsageText:   "envm plugin",
+			Subcommands: pluginCommands,
+		},
+		{
+			Name:        "mirrors",
+			Usage:       "envm mirrors, download mirrors configured in config.toml [mirrors]",
+			UsageText:   "envm mirrors",
+			Subcommands: mirrorsCommands,
+		},
+		{
+			Name:      "sync",
+			Usage:     "install and use the versions listed in asdf .tool-versions",
+			UsageText: "envm sync [--write]",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "write",
+					Usage: "write the active versions into .tool-versions",
+				},
+			},
+			Action: commands_sync.CommandSync,
+		},
+		{
+			Name:      "install",
+			Usage:     "install the missing versions listed in .envmrc",
+			UsageText: "envm install",
+			Action:    commands_sync.CommandInstall,
+		},
+		{
+			Name:      "use",
+			Usage:     "use all the versions listed in .envmrc",
+			UsageText: "envm use [--porcelain]",
+			Flags: []cli.Flag{
+				cli.BoolFlag{Name: "porcelain", Usage: "print only <language>\t<version> of each activated version, all other output goes to stderr"},
+			},
+			Action: commands_sync.CommandUse,
+		},
+		{
+			Name:      "env",
+			Usage:     "print the environment of the selected versions without touching symlinks or PATH",
+			UsageText: "eval \"$(envm env bash|zsh)\", envm env fish | source, envm env powershell | Out-String | Invoke-Expression, envm env --output activate.cmd cmd && call activate.cmd, envm env --format dotenv > .env.toolchain",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "output, o", Usage: "write the activation script into the file instead of stdout"},
+				cli.StringFlag{Name: "format", Usage: "dotenv prints KEY=VALUE lines for docker compose, with the PATH additions in ENVM_PATH"},
+			},
+			Action: commands_env.CommandEnv,
+		},
+		{
+			Name:            "exec",
+			Usage:           "run a command with the selected versions, --with <language>@<version> overrides them for this command",
+			UsageText:       "envm exec [--with go@1.22 --with java@17 ...] [--] <command> [args...]",
+			SkipFlagParsing: true,
+			Action:          commands_env.CommandExec,
+		},
+		{
+			Name:            "try",
+			Usage:           "try a version in a new shell or a command without changing the global version, the shell ends when --for expires, without arguments list the running trials",
+			UsageText:       "envm try <language>@<version> [--for 2h] [-- command [args...]], e.g. envm try go@1.23rc1 --for 2h",
+			SkipFlagParsing: true,
+			Action:          commands_try.CommandTry,
+		},
+		{
+			Name:      "ls",
+			Usage:     "list the installed versions of every language, * marks the global default and > the version selected by the shell or project",
+			UsageText: "envm ls [--output table|tsv|csv] [language...]",
+			Flags: []cli.Flag{
+				commands_remote.OutputFlag,
+			},
+			Action: commands_list.CommandList,
+		},
+		{
+			Name:      "link",
+			Usage:     "register a toolchain built elsewhere on disk as a named version without copying it",
+			UsageText: "envm link <language>[@name] <path>, e.g. envm link go@custom-pgo ~/src/go",
+			Action:    commands_list.CommandLink,
+		},
+		{
+			Name:      "ls-remote",
+			Usage:     "list the remote versions of several languages concurrently",
+			UsageText: "envm ls-remote [--all] [--jobs 4] [--timeout 15s] [--output table|tsv|csv] [--os os] [--arch arch] [--kind kind] [--limit N] [--sort asc|desc] [language...]",
+			Flags: append([]cli.Flag{
+				commands_remote.OutputFlag,
+				cli.BoolFlag{Name: "all", Usage: "list all languages"},
+				cli.IntFlag{Name: "jobs", Value: 4, Usage: "number of sources queried at the same time"},
+				cli.DurationFlag{Name: "timeout", Value: 15 * time.Second, Usage: "timeout of each source"},
+			}, commands_remote.FilterFlags...),
+			Action: commands_remote.CommandListRemote,
+		},
+		{
+			Name:      "refresh",
+			Usage:     "refresh the cached remote version lists and prune the ones no longer used, or print a schedule running it periodically",
+			UsageText: "envm refresh [--all] [--stale] [--jitter 10m] [--prune-after 720h], envm refresh --schedule cron|systemd|launchd|schtasks [--every 12h]",
+			Flags:     commands_remote.RefreshFlags,
+			Action:    commands_remote.CommandRefresh,
+		},
+		{
+			Name:      "status",
+			Usage:     "show the selected version of every language, without network access",
+			UsageText: "envm status [--porcelain] [--wide] [language...]",
+			Flags: []cli.Flag{
+				cli.BoolFlag{Name: "porcelain", Usage: "stable tab separated output for prompt integration"},
+				cli.BoolFlag{Name: "wide", Usage: "print the full sources instead of fitting them into the terminal width"},
+			},
+			Action: commands_status.CommandStatus,
+		},
+		{
+			Name:      "check",
+			Usage:     "check that the versions found in PATH match the versions pinned by the project and print how to fix them, used by git hooks",
+			UsageText: "envm check, exits with 1 when a version does not match",
+			Action:    commands_status.CommandCheck,
+		},
+		{
+			Name:      "current",
+			Usage:     "show the globally active versions and repair symlinks pointing to deleted versions",
+			UsageText: "envm current [language]",
+			Action:    commands_status.CommandCurrent,
+		},
+		{
+			Name:      "dedup",
+			Usage:     "hard-link identical files across the installed versions to reclaim disk space",
+			UsageText: "envm dedup [--dry-run] [language...]",
+			Flags: []cli.Flag{
+				cli.BoolFlag{Name: "dry-run", Usage: "only report how much space can be reclaimed"},
+			},
+			Action: commands_dedup.CommandDedup,
+		},
+		{
+			Name:      "history",
+			Usage:     "show who installed, uninstalled or switched which versions, with the source url and checksum",
+			UsageText: "envm history [--action install] [--since 168h] [--limit 50] [--json] [--wide] [language]",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "action", Usage: "only show install, uninstall or activate"},
+				cli.DurationFlag{Name: "since", Usage: "only show the records in the duration, e.g. 24h"},
+				cli.IntFlag{Name: "limit", Value: 50, Usage: "number of the latest records shown, 0 for all"},
+				cli.BoolFlag{Name: "json", Usage: "print one json record per line"},
+				cli.BoolFlag{Name: "wide", Usage: "print the full source urls instead of fitting them into the terminal width"},
+			},
+			Action: commands_history.CommandHistory,
+		},
+		{
+			Name:      "last",
+			Usage:     "show the last used, previous and last installed version of each language, switch back with envm <language> active -",
+			UsageText: "envm last [language]",
+			Action:    commands_history.CommandLast,
+		},
+		{
+			Name:      "sbom",
+			Usage:     "print a software bill of materials of every installed toolchain for compliance pipelines",
+			UsageText: "envm sbom [--format cyclonedx|spdx] [--output sbom.json]",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "format", Value: "cyclonedx", Usage: "cyclonedx (1.5) or spdx (2.3), both in JSON"},
+				cli.StringFlag{Name: "output, o", Usage: "write the bill of materials into the file instead of stdout"},
+			},
+			Action: commands_sbom.CommandSbom,
+		},
+		{
+			Name:      "serve",
+			Usage:     "serve a local JSON API for editor plugins and GUI frontends: list installed and remote versions, install with progress as server-sent events, switch versions",
+			UsageText: "envm serve [--listen 127.0.0.1:0] [--token <token>], prints {\"url\", \"token\", \"pid\"} as one JSON line once listening",
+			Flags:     commands_serve.Flags,
+			Action:    commands_serve.CommandServe,
+		},
+		{
+			Name:      "daemon",
+			Usage:     "run a background process that watches the version files and answers the shell hook without reading them on every directory change, installing missing versions ahead when auto_install is set",
+			UsageText: "envm daemon [start|stop|status|run], run stays in the foreground for service managers",
+			Action:    commands_daemon.CommandDaemon,
+		},
+		{
+			Name:      "notify",
+			Usage:     "turn on or off the notices about new versions of the active toolchains, shown at most once a day after commands",
+			UsageText: "envm notify [on|off|security]",
+			Action:    commands_notify.CommandNotify,
+		},
+		{
+			Name:      "stats",
+			Usage:     "show local usage stats (installs per language, version list cache hit rate, download speed per host) to tune mirrors and settings, never uploaded",
+			UsageText: "envm stats [on|off|reset]",
+			Action:    commands_stats.CommandStats,
+		},
+		{
+			Name:      "outdated",
+			Usage:     "list installed versions that have a newer patch release, --security only those with known security fixes",
+			UsageText: "envm outdated [--security] [language...]",
+			Flags: []cli.Flag{
+				cli.BoolFlag{Name: "security", Usage: "only list versions whose newer releases include security fixes (go and node)"},
+			},
+			Action: commands_outdated.CommandOutdated,
+		},
+		{
+			Name:      "doctor",
+			Usage:     "check the envm setup and explain which binaries in PATH shadow the selected versions",
+			UsageText: "envm doctor",
+			Action:    commands_doctor.CommandDoctor,
+		},
+		{
+			Name:      "explain",
+			Usage:     "print the possible causes of an error code (ENVM-NET-002) and the steps to fix it, without arguments list all codes",
+			UsageText: "envm explain [error-code], e.g. envm explain ENVM-CHK-001",
+			Action:    commands_explain.CommandExplain,
+		},
+		{
+			Name:      "verify",
+			Usage:     "compare the running envm binary with the checksums published in its release",
+			UsageText: "envm verify --self [--refresh]",
+			Flags: []cli.Flag{
+				cli.BoolFlag{Name: "self", Usage: "verify the envm binary itself"},
+				cli.BoolFlag{Name: "refresh", Usage: "download the checksums again instead of using the cached ones"},
+			},
+			Action: commands_verify.CommandVerify,
+		},
+		{
+			Name:  "ci",
+			Usage: "install versions inside CI jobs",
+			Subcommands: []cli.Command{
+				{
+					Name:      "github",
+					Usage:     "install and export the versions to later steps through $GITHUB_PATH, $GITHUB_ENV and step outputs",
+					UsageText: "envm ci github [language@version...], without arguments the versions declared by the project are installed",
+					Action:    commands_ci.CommandGithub,
+				},
+			},
+		},
+		{
+			Name:  "generate",
+			Usage: "generate container configs and git hooks for the versions pinned by the project",
+			Subcommands: []cli.Command{
+				{
+					Name:      "dockerfile",
+					Usage:     "print a Dockerfile installing the pinned versions with envm",
+					UsageText: "envm generate dockerfile [--base debian:bookworm-slim] [--output Dockerfile.toolchain]",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "base", Usage: "debian based image the toolchains are installed into"},
+						cli.StringFlag{Name: "output, o", Usage: "write the Dockerfile into the file instead of stdout"},
+					},
+					Action: commands_generate.CommandDockerfile,
+				},
+				{
+					Name:      "devcontainer",
+					Usage:     "write a Dockerfile and devcontainer.json installing the pinned versions with envm",
+					UsageText: "envm generate devcontainer [--dir .devcontainer] [--force]",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "base", Usage: "debian based image the toolchains are installed into"},
+						cli.StringFlag{Name: "dir", Value: ".devcontainer", Usage: "directory of the generated files"},
+						cli.BoolFlag{Name: "force", Usage: "overwrite existing files"},
+					},
+					Action: commands_generate.CommandDevcontainer,
+				},
+				{
+					Name:      "git-hook",
+					Usage:     "write a pre-commit or pre-push hook which stops commits made with toolchains other than the pinned ones",
+					UsageText: "envm generate git-hook [--hook pre-commit|pre-push] [--format git|husky|pre-commit] [--force]",
+					Flags:     commands_generate.GitHookFlags,
+					Action:    commands_generate.CommandGitHook,
+				},
+			},
+		},
+		{
+			Name:      "hook",
+			Usage:     "print the shell hook which switches versions by .go-version/.nvmrc/.java-version/.envmrc",
+			UsageText: "eval \"$(envm hook bash|zsh)\", envm hook fish | source, envm hook powershell | Out-String | Invoke-Expression",
+			Action:    commands_hook.CommandHook,
+		},
+		{
+			Name:      "completion",
+			Usage:     "print the completion script of bash, zsh, fish or powershell",
+			UsageText: "envm completion fish > ~/.config/fish/completions/envm.fish, envm completion install [shell]",
+			Action:    commands_hook.CommandCompletion,
+			Subcommands: []cli.Command{
+				{
+					Name:      "install",
+					Usage:     "write the completion script where the shell loads it and source it from the rc file when needed, the shell is detected from $SHELL by default",
+					UsageText: "envm completion install [bash|zsh|fish|powershell]",
+					Action:    commands_hook.CommandCompletionInstall,
+				},
+			},
+		},
+		{
+			Name:      "docs",
+			Usage:     "generate the man page or markdown reference of all commands from their definitions",
+			UsageText: "envm docs man --output envm.1, envm docs markdown > docs/commands.md",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "output, o", Usage: "write the document into the file instead of stdout"},
+			},
+			Action: commands_docs.CommandDocs,
+		},
+		{
+			Name:      "release",
+			Usage:     "for maintainers, generate the homebrew formula, scoop and winget manifests of a published release with its checksums",
+			UsageText: "envm release [--version v1.0.2] [--dir dist] [homebrew|scoop|winget...]",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "version", Usage: "tag of the release, defaults to the version of this envm"},
+				cli.StringFlag{Name: "dir", Value: ".", Usage: "directory of the generated files"},
+			},
+			Action: commands_release.CommandRelease,
+		},
+		{
+			Name:      "psmodule",
+			Usage:     "write the PowerShell module with the hook, completion and envm env integration",
+			UsageText: "envm psmodule [dir], then Import-Module envm in $PROFILE",
+			Action:    commands_hook.CommandPSModule,
+		},
+		{
+			Name:      "registry",
+			Usage:     "windows only, write PATH and GOROOT/JAVA_HOME of the symlinks into the registry",
+			UsageText: "envm registry [--system]",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "system",
+					Usage: "update the system environment instead of the current user, requires administrator",
+				},
+			},
+			Action: commands_windows.CommandRegistry,
+		},
+		{
+			Name:      "workspace",
+			Usage:     "envm workspace, repositories with several sub-projects pinning different versions",
+			UsageText: "envm workspace",
+			Subcommands: []cli.Command{
+				{
+					Name:      "status",
+					Usage:     "List the versions required by each directory with a version file under [dir], the current directory by default",
+					UsageText: "envm workspace status [--output table|tsv|csv] [dir]",
+					Flags:     commands_remote.ListingFlags,
+					Action:    commands_workspace.CommandStatus,
+				},
+				{
+					Name:      "install",
+					Usage:     "Install every missing version required by the sub-projects under [dir] in one pass",
+					UsageText: "envm workspace install [dir]",
+					Action:    commands_workspace.CommandInstall,
+				},
+			},
+		},
+		{
+			Name:      "reinstall",
+			Usage:     "rebuild an installed version from the archive kept by keep_downloads, without network access",
+			UsageText: "envm reinstall <language>@<version>",
+			Action:    commands_reinstall.CommandReinstall,
+		},
+		{
+			Name:      "relocate",
+			Usage:     "move all installed versions to <new-root>/downloads, e.g. off a full drive, and update the symlinks and config.toml",
+			UsageText: "envm relocate <new-root>",
+			Action:    commands_relocate.CommandRelocate,
+		},
+		{
+			Name:      "rehash",
+			Usage:     "regenerate the shims in <ENVM_HOME>/shims (<ENVM_HOME>/<language>/bin with bin_per_language), add the directory to PATH to select versions per invocation",
+			UsageText: "envm rehash",
+			Action:    commands_shim.CommandRehash,
+		},
+		{
+			Name:   commands_remote.RefreshCommand,
+			Hidden: true,
+			Action: commands_remote.CommandRefreshIndex,
+		},
+		{
+			Name:            "shim-exec",
+			Usage:           "used by the shims, version is selected by ENVM_<LANG>_VERSION, project version files and then the global symlink",
+			Hidden:          true,
+			SkipFlagParsing: true,
+			Action:          commands_shim.CommandExec,
+		},
+		{
+			Name:      "direnv",
+			Usage:     "print use_envm for direnv, save it as ~/.config/direnv/lib/envm.sh and add use envm to .envrc",
+			UsageText: "envm direnv > ~/.config/direnv/lib/envm.sh",
+			Action:    commands_hook.CommandDirenv,
+		},
+		{
+			Name:            "direnv-env",
+			Usage:           "used by use_envm",
+			Hidden:          true,
+			SkipFlagParsing: true,
+			Action:          commands_hook.CommandDirenvEnv,
+		},
+		{
+			Name:   "hook-env",
+			Usage:  "used by the shell hook",
+			Hidden: true,
+			Action: commands_hook.CommandHookEnv,
+		},
 	}
 
 	goCommands = []cli.Command{
 		{
 			Name:      "ls",
 			Usage:     "List installed versions",
-			UsageText: "envm ls",
-			Action:    commands_go.CommandListInstalled,
+			UsageText: "envm ls [--output table|tsv|csv]",
+			Flags:     commands_remote.ListingFlags,
+			Action:    commands_remote.Listing(config.GO, commands_go.CommandListInstalled),
 		},
 		{
 			Name:      "lsr",
 			Usage:     "List remote versions available for install",
-			UsageText: "envm lsr [stable|archived]",
-			Action:    commands_go.CommandListRemote,
+			UsageText: "envm lsr [--os os] [--arch arch] [--limit N] [--sort asc|desc] [stable|archived]",
+			Flags: append([]cli.Flag{
+				cli.IntFlag{Name: "latest", Usage: "same as --limit", Hidden: true},
+			}, commands_remote.FilterFlags...),
+			Action: commands_go.CommandListRemote,
 		},
 		{
 			Name:      "active",
-			Usage:     "Switch to specified version",
-			UsageText: "envm active <version>",
-			Action:    commands_go.CommandUse,
+			Usage:     "Switch to specified version, without version select it by go.mod go/toolchain directives",
+			UsageText: "envm active [--arch GOARCH] [<version>|-], - switches back to the previous version",
+			Flags:     append([]cli.Flag{commands_go.ArchFlag}, commands_env.SessionFlags...),
+			Action:    commands_remote.Normalize(config.GO, false, commands_env.Session(config.GO, commands_go.CommandUse)),
 		},
 		{
 			Name:      "install",
 			Usage:     "Download and install a <version>",
-			UsageText: "envm install <version>",
-			Action:    commands_go.CommandInstall,
+			UsageText: "envm install [--json] [--arch GOARCH] <version|latest>",
+			Flags:     append([]cli.Flag{commands_go.ArchFlag}, commands_remote.InstallFlags...),
+			After:     commands_dedup.AfterInstall(config.GO),
+			Action:    commands_remote.Latest(config.GO, commands_go.CommandInstall),
+		},
+		{
+			Name:      "build-from-source",
+			Usage:     "Download the source of a <version> and build it with an existing go, for platforms without official binaries",
+			UsageText: "envm go build-from-source [--bootstrap <GOROOT>] <version>",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "bootstrap", Usage: "GOROOT of the go used for the build, defaults to GOROOT_BOOTSTRAP, the active version or go in PATH"},
+			},
+			Action: commands_remote.Normalize(config.GO, true, commands_go.CommandBuildFromSource),
 		},
 		{
 			Name:      "uninstall",
 			Usage:     "Uninstall a version",
-			UsageText: "gvm uninstall <version>",
-			Action:    commands_go.CommandUninstall,
+			UsageText: "gvm uninstall [--force] [version], without version choose from the installed versions, --prune removes all but the active one",
+			Flags:     commands_remote.ProtectFlags,
+			Action:    commands_remote.Normalize(config.GO, false, commands_remote.Protect(config.GO, commands_go.CommandUninstall)),
+		},
+		{
+			Name:      "env-diff",
+			Usage:     "Compare go env, supported ports and bundled tools of two installed versions, or of a version and the active one",
+			UsageText: "envm go env-diff [<version>] <version>",
+			Action:    commands_go.CommandEnvDiff,
+		},
+		{
+			Name:      "test-matrix",
+			Usage:     "Run a command with each of several go versions, installing the missing ones, and report pass or fail per version",
+			UsageText: "envm go test-matrix [--parallel N] [--fail-fast] <versions> -- <command> [args...], e.g. envm go test-matrix 1.20,1.22..1.23 -- go test ./...",
+			Flags:     commands_matrix.Flags,
+			// -- 之后的命令原样传递，参数需要写在版本之前
+			SkipArgReorder: true,
+			Action:         commands_matrix.CommandTestMatrix(config.GO),
+		},
+		{
+			Name:      "tools",
+			Usage:     "Build gopls, dlv, golangci-lint and staticcheck with each go version, the shims run the build matching the selected go",
+			UsageText: "envm go tools",
+			Subcommands: []cli.Command{
+				{
+					Name:      "install",
+					Usage:     "go install the tools with the selected go, all of gopls, dlv, golangci-lint and staticcheck by default",
+					UsageText: "envm go tools install [--go <version>] [tool[@version]|package[@version]...]",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "go", Usage: "installed go version used for the build, defaults to the version selected in the current directory"},
+					},
+					Action: commands_gotools.CommandInstall,
+				},
+				{
+					Name:      "list",
+					Usage:     "List the tools built with each installed go version",
+					UsageText: "envm go tools list",
+					Action:    commands_gotools.CommandList,
+				},
+			},
+		},
+		{
+			Name:      "changelog",
+			Usage:     "Print the release notes url of a version, the active one by default",
+			UsageText: "envm go changelog [--open] [version]",
+			Flags:     commands_remote.ChangelogFlags,
+			Action:    commands_remote.Changelog(config.GO),
 		},
 	}
 
@@ -86,52 +642,518 @@ var (
 		{
 			Name:      "ls",
 			Usage:     "List installed versions",
-			UsageText: "envm java  ls",
-			Action:    commands_java.CommandListInstalled,
+			UsageText: "envm java  ls [--output table|tsv|csv]",
+			Flags:     commands_remote.ListingFlags,
+			Action:    commands_remote.Listing(config.JAVA, commands_java.CommandListInstalled),
 		},
 		{
 			Name:      "active",
 			Usage:     "Switch to specified version",
-			UsageText: "envm java active <version>",
-			Action:    commands_java.CommandUse,
+			UsageText: "envm java active <version>|-",
+			Flags:     commands_env.SessionFlags,
+			Action:    commands_remote.Normalize(config.JAVA, false, commands_env.Session(config.JAVA, commands_java.CommandUse)),
 		},
 		{
 			Name:      "uninstall",
 			Usage:     "Uninstall a version",
-			UsageText: "envm java uninstall <version>",
-			Action:    commands_java.CommandUninstall,
+			UsageText: "envm java uninstall [--force] [version], without version choose from the installed versions, --prune removes all but the active one",
+			Flags:     commands_remote.ProtectFlags,
+			Action:    commands_remote.Normalize(config.JAVA, false, commands_remote.Protect(config.JAVA, commands_java.CommandUninstall)),
+		},
+		{
+			Name:      "changelog",
+			Usage:     "Print the release notes url of a version, the active one by default",
+			UsageText: "envm java changelog [--open] [version]",
+			Flags:     commands_remote.ChangelogFlags,
+			Action:    commands_remote.Changelog(config.JAVA),
 		},
 	}
 	nodeCommands = []cli.Command{
 		{
 			Name:      "ls",
-			Usage:     "envm node ls",
+			Usage:     "envm node ls [--output table|tsv|csv]",
 			UsageText: "List installed versions",
-			Action:    commands_node.CommandListInstalled,
+			Flags:     commands_remote.ListingFlags,
+			Action:    commands_remote.Listing(config.NODE, commands_node.CommandListInstalled),
+		},
+		{
+			Name:      "lsr",
+			Usage:     "List remote versions available for install",
+			UsageText: "envm lsr [--os os] [--arch arch] [--limit N] [--sort asc|desc] [all|lts|current|stable|unstable]",
+			Flags: append([]cli.Flag{
+				cli.IntFlag{Name: "latest", Usage: "same as --limit", Hidden: true},
+			}, commands_remote.FilterFlags...),
+			Action: commands_node.CommandListRemote,
+		},
+		{
+			Name:      "active",
+			Usage:     "Switch to specified version",
+			UsageText: "envm active <version>|-",
+			Flags:     commands_env.SessionFlags,
+			Action:    commands_remote.Normalize(config.NODE, false, commands_env.Session(config.NODE, commands_node.CommandUse)),
+		},
+		{
+			Name:      "install",
+			Usage:     "Download and install a <version>",
+			UsageText: "envm install [--json] <version|latest>",
+			Flags:     commands_remote.InstallFlags,
+			After:     commands_dedup.AfterInstall(config.NODE),
+			Action:    commands_remote.Latest(config.NODE, commands_node.CommandInstall),
+		},
+		{
+			Name:      "uninstall",
+			Usage:     "Uninstall a version",
+			UsageText: "gvm uninstall [--force] [version], without version choose from the installed versions, --prune removes all but the active one",
+			Flags:     commands_remote.ProtectFlags,
+			Action:    commands_remote.Normalize(config.NODE, false, commands_remote.Protect(config.NODE, commands_node.CommandUninstall)),
+		},
+		{
+			Name:      "changelog",
+			Usage:     "Print the release notes url of a version, the active one by default",
+			UsageText: "envm node changelog [--open] [version]",
+			Flags:     commands_remote.ChangelogFlags,
+			Action:    commands_remote.Changelog(config.NODE),
+		},
+	}
+	denoCommands = []cli.Command{
+		{
+			Name:      "ls",
+			Usage:     "List installed versions",
+			UsageText: "envm deno ls [--output table|tsv|csv]",
+			Flags:     commands_remote.ListingFlags,
+			Action:    commands_remote.Listing(config.DENO, commands_deno.CommandListInstalled),
+		},
+		{
+			Name:      "lsr",
+			Usage:     "List remote versions available for install",
+			UsageText: "envm deno lsr [--os os] [--arch arch] [--kind kind] [--limit N] [--sort asc|desc]",
+			Flags:     commands_remote.FilterFlags,
+			Action:    commands_deno.CommandListRemote,
+		},
+		{
+			Name:      "active",
+			Usage:     "Switch to specified version",
+			UsageText: "envm deno active <version>|-",
+			Flags:     commands_env.SessionFlags,
+			Action:    commands_remote.Normalize(config.DENO, false, commands_env.Session(config.DENO, commands_deno.CommandUse)),
+		},
+		{
+			Name:      "install",
+			Usage:     "Download and install a <version>",
+			UsageText: "envm deno install [--json] <version|latest>",
+			Flags:     commands_remote.InstallFlags,
+			After:     commands_dedup.AfterInstall(config.DENO),
+			Action:    commands_remote.Latest(config.DENO, commands_deno.CommandInstall),
+		},
+		{
+			Name:      "uninstall",
+			Usage:     "Uninstall a version",
+			UsageText: "envm deno uninstall [--force] [version], without version choose from the installed versions, --prune removes all but the active one",
+			Flags:     commands_remote.ProtectFlags,
+			Action:    commands_remote.Normalize(config.DENO, false, commands_remote.Protect(config.DENO, commands_deno.CommandUninstall)),
+		},
+		{
+			Name:      "changelog",
+			Usage:     "Print the release notes url of a version, the active one by default",
+			UsageText: "envm deno changelog [--open] [version]",
+			Flags:     commands_remote.ChangelogFlags,
+			Action:    commands_remote.Changelog(config.DENO),
+		},
+	}
+	bunCommands = []cli.Command{
+		{
+			Name:      "ls",
+			Usage:     "List installed versions",
+			UsageText: "envm bun ls [--output table|tsv|csv]",
+			Flags:     commands_remote.ListingFlags,
+			Action:    commands_remote.Listing(config.BUN, commands_bun.CommandListInstalled),
+		},
+		{
+			Name:      "lsr",
+			Usage:     "List remote versions available for install",
+			UsageText: "envm bun lsr [--os os] [--arch arch] [--kind kind] [--limit N] [--sort asc|desc]",
+			Flags:     commands_remote.FilterFlags,
+			Action:    commands_bun.CommandListRemote,
+		},
+		{
+			Name:      "active",
+			Usage:     "Switch to specified version",
+			UsageText: "envm bun active <version>|-",
+			Flags:     commands_env.SessionFlags,
+			Action:    commands_remote.Normalize(config.BUN, false, commands_env.Session(config.BUN, commands_bun.CommandUse)),
+		},
+		{
+			Name:      "install",
+			Usage:     "Download and install a <version>",
+			UsageText: "envm bun install [--json] <version|latest>",
+			Flags:     commands_remote.InstallFlags,
+			After:     commands_dedup.AfterInstall(config.BUN),
+			Action:    commands_remote.Latest(config.BUN, commands_bun.CommandInstall),
+		},
+		{
+			Name:      "uninstall",
+			Usage:     "Uninstall a version",
+			UsageText: "envm bun uninstall [--force] [version], without version choose from the installed versions, --prune removes all but the active one",
+			Flags:     commands_remote.ProtectFlags,
+			Action:    commands_remote.Normalize(config.BUN, false, commands_remote.Protect(config.BUN, commands_bun.CommandUninstall)),
+		},
+		{
+			Name:      "changelog",
+			Usage:     "Print the release notes url of a version, the active one by default",
+			UsageText: "envm bun changelog [--open] [version]",
+			Flags:     commands_remote.ChangelogFlags,
+			Action:    commands_remote.Changelog(config.BUN),
+		},
+	}
+	zigCommands = []cli.Command{
+		{
+			Name:      "ls",
+			Usage:     "List installed versions",
+			UsageText: "envm zig ls [--output table|tsv|csv]",
+			Flags:     commands_remote.ListingFlags,
+			Action:    commands_remote.Listing(config.ZIG, commands_zig.CommandListInstalled),
+		},
+		{
+			Name:      "lsr",
+			Usage:     "List remote versions available for install",
+			UsageText: "envm zig lsr [--os os] [--arch arch] [--kind kind] [--limit N] [--sort asc|desc]",
+			Flags:     commands_remote.FilterFlags,
+			Action:    commands_zig.CommandListRemote,
+		},
+		{
+			Name:      "active",
+			Usage:     "Switch to specified version",
+			UsageText: "envm zig active <version>|-",
+			Flags:     commands_env.SessionFlags,
+			Action:    commands_remote.Normalize(config.ZIG, false, commands_env.Session(config.ZIG, commands_zig.CommandUse)),
+		},
+		{
+			Name:      "install",
+			Usage:     "Download and install a <version> (master for nightly)",
+			UsageText: "envm zig install [--json] <version|master|latest>",
+			Flags:     commands_remote.InstallFlags,
+			After:     commands_dedup.AfterInstall(config.ZIG),
+			Action:    commands_remote.Latest(config.ZIG, commands_zig.CommandInstall),
+		},
+		{
+			Name:      "uninstall",
+			Usage:     "Uninstall a version",
+			UsageText: "envm zig uninstall [--force] [version], without version choose from the installed versions, --prune removes all but the active one",
+			Flags:     commands_remote.ProtectFlags,
+			Action:    commands_remote.Normalize(config.ZIG, false, commands_remote.Protect(config.ZIG, commands_zig.CommandUninstall)),
+		},
+		{
+			Name:      "changelog",
+			Usage:     "Print the release notes url of a version, the active one by default",
+			UsageText: "envm zig changelog [--open] [version]",
+			Flags:     commands_remote.ChangelogFlags,
+			Action:    commands_remote.Changelog(config.ZIG),
+		},
+	}
+	mavenCommands = []cli.Command{
+		{
+			Name:      "ls",
+			Usage:     "List installed versions",
+			UsageText: "envm mvn ls [--output table|tsv|csv]",
+			Flags:     commands_remote.ListingFlags,
+			Action:    commands_remote.Listing(config.MAVEN, commands_maven.CommandListInstalled),
+		},
+		{
+			Name:      "lsr",
+			Usage:     "List remote versions available for install",
+			UsageText: "envm mvn lsr [--os os] [--arch arch] [--kind kind] [--limit N] [--sort asc|desc]",
+			Flags:     commands_remote.FilterFlags,
+			Action:    commands_maven.CommandListRemote,
+		},
+		{
+			Name:      "active",
+			Usage:     "Switch to specified version",
+			UsageText: "envm mvn active <version>|-",
+			Flags:     commands_env.SessionFlags,
+			Action:    commands_remote.Normalize(config.MAVEN, false, commands_env.Session(config.MAVEN, commands_maven.CommandUse)),
+		},
+		{
+			Name:      "install",
+			Usage:     "Download and install a <version>, defaults to the version of .mvn/wrapper",
+			UsageText: "envm mvn install [--json] [version|latest]",
+			Flags:     commands_remote.InstallFlags,
+			After:     commands_dedup.AfterInstall(config.MAVEN),
+			Action:    commands_remote.Latest(config.MAVEN, commands_maven.CommandInstall),
+		},
+		{
+			Name:      "wrapper",
+			Usage:     "Install the version required by .mvn/wrapper and make it available offline",
+			UsageText: "envm mvn wrapper",
+			Action:    commands_maven.CommandWrapper,
+		},
+		{
+			Name:      "uninstall",
+			Usage:     "Uninstall a version",
+			UsageText: "envm mvn uninstall [--force] [version], without version choose from the installed versions, --prune removes all but the active one",
+			Flags:     commands_remote.ProtectFlags,
+			Action:    commands_remote.Normalize(config.MAVEN, false, commands_remote.Protect(config.MAVEN, commands_maven.CommandUninstall)),
+		},
+		{
+			Name:      "changelog",
+			Usage:     "Print the release notes url of a version, the active one by default",
+			UsageText: "envm mvn changelog [--open] [version]",
+			Flags:     commands_remote.ChangelogFlags,
+			Action:    commands_remote.Changelog(config.MAVEN),
+		},
+	}
+	gradleCommands = []cli.Command{
+		{
+			Name:      "ls",
+			Usage:     "List installed versions",
+			UsageText: "envm gradle ls [--output table|tsv|csv]",
+			Flags:     commands_remote.ListingFlags,
+			Action:    commands_remote.Listing(config.GRADLE, commands_gradle.CommandListInstalled),
+		},
+		{
+			Name:      "lsr",
+			Usage:     "List remote versions available for install",
+			UsageText: "envm gradle lsr [--os os] [--arch arch] [--kind kind] [--limit N] [--sort asc|desc]",
+			Flags:     commands_remote.FilterFlags,
+			Action:    commands_gradle.CommandListRemote,
+		},
+		{
+			Name:      "active",
+			Usage:     "Switch to specified version",
+			UsageText: "envm gradle active <version>|-",
+			Flags:     commands_env.SessionFlags,
+			Action:    commands_remote.Normalize(config.GRADLE, false, commands_env.Session(config.GRADLE, commands_gradle.CommandUse)),
+		},
+		{
+			Name:      "install",
+			Usage:     "Download and install a <version>, defaults to the version of gradle-wrapper.properties",
+			UsageText: "envm gradle install [--json] [version|latest]",
+			Flags:     commands_remote.InstallFlags,
+			After:     commands_dedup.AfterInstall(config.GRADLE),
+			Action:    commands_remote.Latest(config.GRADLE, commands_gradle.CommandInstall),
+		},
+		{
+			Name:      "wrapper",
+			Usage:     "Install the version required by gradle-wrapper.properties and make it available offline",
+			UsageText: "envm gradle wrapper",
+			Action:    commands_gradle.CommandWrapper,
+		},
+		{
+			Name:      "uninstall",
+			Usage:     "Uninstall a version",
+			UsageText: "envm gradle uninstall [--force] [version], without version choose from the installed versions, --prune removes all but the active one",
+			Flags:     commands_remote.ProtectFlags,
+			Action:    commands_remote.Normalize(config.GRADLE, false, commands_remote.Protect(config.GRADLE, commands_gradle.CommandUninstall)),
+		},
+		{
+			Name:      "changelog",
+			Usage:     "Print the release notes url of a version, the active one by default",
+			UsageText: "envm gradle changelog [--open] [version]",
+			Flags:     commands_remote.ChangelogFlags,
+			Action:    commands_remote.Changelog(config.GRADLE),
+		},
+	}
+	phpCommands = []cli.Command{
+		{
+			Name:      "ls",
+			Usage:     "List installed versions",
+			UsageText: "envm php ls [--output table|tsv|csv]",
+			Flags:     commands_remote.ListingFlags,
+			Action:    commands_remote.Listing(config.PHP, commands_php.CommandListInstalled),
+		},
+		{
+			Name:      "lsr",
+			Usage:     "List remote versions available for install",
+			UsageText: "envm php lsr [--os os] [--arch arch] [--kind kind] [--limit N] [--sort asc|desc]",
+			Flags:     commands_remote.FilterFlags,
+			Action:    commands_php.CommandListRemote,
+		},
+		{
+			Name:      "active",
+			Usage:     "Switch to specified version",
+			UsageText: "envm php active <version>|-",
+			Flags:     commands_env.SessionFlags,
+			Action:    commands_remote.Normalize(config.PHP, false, commands_env.Session(config.PHP, commands_php.CommandUse)),
+		},
+		{
+			Name:      "install",
+			Usage:     "Download and install a <version>, built from source except on windows",
+			UsageText: "envm php install [--json] <version|latest>",
+			Flags:     commands_remote.InstallFlags,
+			After:     commands_dedup.AfterInstall(config.PHP),
+			Action:    commands_remote.Latest(config.PHP, commands_php.CommandInstall),
+		},
+		{
+			Name:      "ini",
+			Usage:     "Print the php.ini path of the active version",
+			UsageText: "envm php ini",
+			Action:    commands_php.CommandIni,
+		},
+		{
+			Name:      "uninstall",
+			Usage:     "Uninstall a version",
+			UsageText: "envm php uninstall [--force] [version], without version choose from the installed versions, --prune removes all but the active one",
+			Flags:     commands_remote.ProtectFlags,
+			Action:    commands_remote.Normalize(config.PHP, false, commands_remote.Protect(config.PHP, commands_php.CommandUninstall)),
+		},
+		{
+			Name:      "changelog",
+			Usage:     "Print the release notes url of a version, the active one by default",
+			UsageText: "envm php changelog [--open] [version]",
+			Flags:     commands_remote.ChangelogFlags,
+			Action:    commands_remote.Changelog(config.PHP),
+		},
+	}
+	flutterCommands = []cli.Command{
+		{
+			Name:      "ls",
+			Usage:     "List installed versions",
+			UsageText: "envm flutter ls [--output table|tsv|csv]",
+			Flags:     commands_remote.ListingFlags,
+			Action:    commands_remote.Listing(config.FLUTTER, commands_flutter.CommandListInstalled),
 		},
 		{
 			Name:      "lsr",
 			Usage:     "List remote versions available for install",
-			UsageText: "envm lsr [all|lts|current|stable|unstable]",
-			Action:    commands_node.CommandListRemote,
+			UsageText: "envm flutter lsr [--os os] [--arch arch] [--limit N] [--sort asc|desc] [stable|beta]",
+			Flags:     commands_remote.FilterFlags,
+			Action:    commands_flutter.CommandListRemote,
+		},
+		{
+			Name:      "active",
+			Usage:     "Switch to specified version",
+			UsageText: "envm flutter active <version>|-",
+			Flags:     commands_env.SessionFlags,
+			Action:    commands_remote.Normalize(config.FLUTTER, false, commands_env.Session(config.FLUTTER, commands_flutter.CommandUse)),
+		},
+		{
+			Name:      "install",
+			Usage:     "Download and install a <version>, or the current release of a channel",
+			UsageText: "envm flutter install [--json] <version|stable|beta|latest>",
+			Flags:     commands_remote.InstallFlags,
+			After:     commands_dedup.AfterInstall(config.FLUTTER),
+			Action:    commands_remote.Latest(config.FLUTTER, commands_flutter.CommandInstall),
+		},
+		{
+			Name:      "env",
+			Usage:     "Print the PUB_CACHE setting which follows the active version",
+			UsageText: "envm flutter env",
+			Action:    commands_flutter.CommandEnv,
+		},
+		{
+			Name:      "uninstall",
+			Usage:     "Uninstall a version",
+			UsageText: "envm flutter uninstall [--force] [version], without version choose from the installed versions, --prune removes all but the active one",
+			Flags:     commands_remote.ProtectFlags,
+			Action:    commands_remote.Normalize(config.FLUTTER, false, commands_remote.Protect(config.FLUTTER, commands_flutter.CommandUninstall)),
+		},
+		{
+			Name:      "changelog",
+			Usage:     "Print the release notes url of a version, the active one by default",
+			UsageText: "envm flutter changelog [--open] [version]",
+			Flags:     commands_remote.ChangelogFlags,
+			Action:    commands_remote.Changelog(config.FLUTTER),
+		},
+	}
+	toolCommands = []cli.Command{
+		{
+			Name:      "list",
+			Usage:     "List supported tools",
+			UsageText: "envm tool list",
+			Action:    commands_tool.CommandList,
+		},
+		{
+			Name:      "ls",
+			Usage:     "List installed tools",
+			UsageText: "envm tool ls",
+			Action:    commands_tool.CommandListInstalled,
+		},
+		{
+			Name:      "lsr",
+			Usage:     "List remote versions of a tool",
+			UsageText: "envm tool lsr [--limit N] [--sort asc|desc] <name>",
+			Flags:     commands_remote.FilterFlags,
+			Action:    commands_tool.CommandListRemote,
+		},
+		{
+			Name:      "install",
+			Usage:     "Download, verify and link a tool into $ENVM_HOME/bin",
+			UsageText: "envm tool install <name>@<version>",
+			Action:    commands_tool.CommandInstall,
 		},
 		{
 			Name:      "active",
 			Usage:     "Switch to specified version",
-			UsageText: "envm active <version>",
-			Action:    commands_node.CommandUse,
+			UsageText: "envm tool active <name>@<version>",
+			Action:    commands_tool.CommandUse,
+		},
+		{
+			Name:      "uninstall",
+			Usage:     "Uninstall a version",
+			UsageText: "envm tool uninstall <name>@<version>",
+			Action:    commands_tool.CommandUninstall,
+		},
+	}
+	pluginCommands = []cli.Command{
+		{
+			Name:      "add",
+			Usage:     "Add a plugin from a git repository",
+			UsageText: "envm plugin add <name> <git-url>",
+			Action:    commands_plugin.CommandAdd,
+		},
+		{
+			Name:      "remove",
+			Usage:     "Remove a plugin, installed versions are kept",
+			UsageText: "envm plugin remove <name>",
+			Action:    commands_plugin.CommandRemove,
+		},
+		{
+			Name:      "list",
+			Usage:     "List added plugins",
+			UsageText: "envm plugin list",
+			Action:    commands_plugin.CommandList,
+		},
+		{
+			Name:      "ls",
+			Usage:     "List installed versions of a plugin",
+			UsageText: "envm plugin ls <name>",
+			Action:    commands_plugin.CommandListInstalled,
+		},
+		{
+			Name:      "lsr",
+			Usage:     "List remote versions of a plugin",
+			UsageText: "envm plugin lsr [--limit N] [--sort asc|desc] <name>",
+			Flags:     commands_remote.FilterFlags,
+			Action:    commands_plugin.CommandListRemote,
 		},
 		{
 			Name:      "install",
 			Usage:     "Download and install a <version>",
-			UsageText: "envm install <version>",
-			Action:    commands_node.CommandInstall,
+			UsageText: "envm plugin install <name> <version>",
+			Action:    commands_plugin.CommandInstall,
+		},
+		{
+			Name:      "active",
+			Usage:     "Switch to specified version",
+			UsageText: "envm plugin active <name> <version>",
+			Action:    commands_plugin.CommandUse,
 		},
 		{
 			Name:      "uninstall",
 			Usage:     "Uninstall a version",
-			UsageText: "gvm uninstall <version>",
-			Action:    commands_node.CommandUninstall,
+			UsageText: "envm plugin uninstall <name> <version>",
+			Action:    commands_plugin.CommandUninstall,
+		},
+	}
+	mirrorsCommands = []cli.Command{
+		{
+			Name:      "test",
+			Usage:     "measure the latency and speed of each download mirror with a 1MB ranged download",
+			UsageText: "envm mirrors test [--reorder] [--timeout 15s] [language...]",
+			Flags: []cli.Flag{
+				cli.BoolFlag{Name: "reorder", Usage: "save the mirrors of each language in config.toml ordered by the measured speed, failed mirrors last"},
+				cli.DurationFlag{Name: "timeout", Value: 15 * time.Second, Usage: "timeout of each mirror"},
+			},
+			Action: commands_mirrors.CommandTest,
 		},
 	}
 )
@@ -2,7 +2,16 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/FirewineXie/envm/internal/commands/commands-explain"
+	"github.com/FirewineXie/envm/internal/commands/commands-notify"
+	"github.com/FirewineXie/envm/internal/commands/commands-remote"
+	"github.com/FirewineXie/envm/internal/commands/commands-stats"
 	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/cabundle"
+	"github.com/FirewineXie/envm/internal/logic/netpolicy"
+	"github.com/FirewineXie/envm/internal/logic/proxyauth"
+	"github.com/FirewineXie/envm/internal/logic/suggest"
+	"github.com/FirewineXie/envm/util"
 
 	"github.com/urfave/cli"
 	"os"
@@ -24,14 +33,121 @@ func Execute() {
 			Name: "Firewine",
 		},
 	}
+	app.Flags = []cli.Flag{
+		cli.BoolFlag{
+			Name:   "timings",
+			Usage:  "print how long each phase (resolve, download, verify, extract, activate) took",
+			EnvVar: "ENVM_TIMINGS",
+		},
+		cli.BoolFlag{
+			Name:   "yes, y",
+			Usage:  "answer yes to every prompt",
+			EnvVar: "ENVM_YES",
+		},
+		cli.BoolFlag{
+			Name:   "non-interactive",
+			Usage:  "never wait for input, prompts are answered no and progress is printed line by line, enabled automatically when stdin is not a terminal",
+			EnvVar: "ENVM_NON_INTERACTIVE",
+		},
+		cli.BoolFlag{
+			Name:  "insecure",
+			Usage: "install packages which have no checksum instead of refusing them, mismatched checksums still fail",
+		},
+	}
 	app.Before = func(context *cli.Context) error {
+		if context.Bool("timings") {
+			util.EnableTimings()
+		}
+		util.Insecure = context.Bool("insecure")
+		util.Shared = config.IsShared()
+		util.AssumeYes = context.Bool("yes")
+		util.DefaultYes = config.Default().Settings.ConfirmDefault == "yes"
+		util.NonInteractive = context.Bool("non-interactive") || !util.IsTerminal(os.Stdin)
+		util.PlainProgress = util.NonInteractive || !util.IsTerminal(os.Stdout)
+		// 先修改 http.DefaultTransport 的根证书及代理设置，再由 netpolicy 包装
+		if err := cabundle.Install(config.CABundle()); err != nil {
+			return fmt.Errorf("ca_bundle: %w", err)
+		}
+		proxy, err := proxyConfig()
+		if err != nil {
+			return err
+		}
+		if proxy.Enabled() {
+			if err = proxyauth.Install(proxy); err != nil {
+				return err
+			}
+		}
+		if policy := networkPolicy(context.Args()); policy.Enabled() {
+			netpolicy.Install(policy)
+		}
+		// 记录本地的下载统计，见 envm stats
+		commands_stats.Record()
 		return config.VerifyEnv()
 	}
 
+	// 命令返回 ExitError 时 urfave/cli 直接退出，不会执行 After，失败时也需要输出耗时
+	exit := cli.OsExiter
+	cli.OsExiter = func(code int) {
+		util.PrintTimings(os.Stderr)
+		exit(code)
+	}
+
 	app.Commands = baseCommands
+	// 失败时输出错误码，envm explain <code> 查看原因及修复步骤
+	app.ExitErrHandler = commands_explain.HandleExit
+	// 子命令的 app 同样使用该函数，ctx.App.Commands 为当前层级的命令
+	app.CommandNotFound = commandNotFound
+	app.After = func(context *cli.Context) error {
+		// 输出到 stderr，不影响 envm env 等命令的输出
+		util.PrintTimings(os.Stderr)
+		// 配置了 notify 时根据缓存提示新版本
+		commands_notify.Check(context)
+		// 命令结束后按需在后台刷新远程版本列表缓存
+		return commands_remote.Prefetch(context)
+	}
+	// PowerShell 模块等通过 --generate-bash-completion 补全子命令
+	app.EnableBashCompletion = true
 
 	if err := app.Run(os.Args); err != nil {
-		fmt.Fprintf(os.Stderr, "[g] %s\n", err.Error())
+		commands_explain.Report(os.Stderr, "[g] ", err)
 		os.Exit(1)
 	}
 }
+
+// commandNotFound 输出与写错的命令最接近的命令
+func commandNotFound(ctx *cli.Context, name string) {
+	names := make([]string, 0)
+	for _, command := range ctx.App.VisibleCommands() {
+		for _, alias := range command.Names() {
+			// h 等单字母别名几乎与任何输入都接近
+			if len(alias) > 1 {
+				names = append(names, alias)
+			}
+		}
+	}
+	message := suggest.Message(suggest.Closest(name, names, 3))
+	if message == "" {
+		message = ", run " + ctx.App.HelpName + " help for the list of commands"
+	}
+	fmt.Fprintf(os.Stderr, "%q is not a command%s\n", name, message)
+	cli.OsExiter(1)
+}
+
+// networkPolicy config.toml [network] 中全局及本次执行的命令(args 为全局参数之后的命令及参数)的域名规则
+func networkPolicy(args []string) netpolicy.Policy {
+	network := config.Default().Settings.Network
+	commands := make(map[string]netpolicy.Policy, len(network.Commands))
+	for command, rule := range network.Commands {
+		commands[command] = netpolicy.Policy{Allow: rule.Allow, Deny: rule.Deny}
+	}
+	return netpolicy.Resolve(netpolicy.Policy{Allow: network.Allow, Deny: network.Deny}, commands, args)
+}
+
+// proxyConfig config.toml [proxy] 及 ENVM_PROXY* 环境变量中的代理配置
+func proxyConfig() (proxyauth.Config, error) {
+	proxy := config.Proxy()
+	if proxy.Auth != "" && proxy.Auth != config.ProxyBasic && proxy.Auth != config.ProxyNTLM {
+		return proxyauth.Config{}, fmt.Errorf("proxy auth must be %s or %s, got %q", config.ProxyBasic, config.ProxyNTLM, proxy.Auth)
+	}
+	return proxyauth.Config{URL: proxy.URL, Username: proxy.Username, Password: proxy.Password, NTLM: proxy.Auth == config.ProxyNTLM}, nil
+}
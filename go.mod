@@ -3,17 +3,20 @@ module github.com/FirewineXie/envm
 go 1.21
 
 require (
+	github.com/BurntSushi/toml v1.4.0
 	github.com/PuerkitoBio/goquery v1.9.1
 	github.com/blang/semver/v4 v4.0.0
+	github.com/cpuguy83/go-md2man/v2 v2.0.4
 	github.com/mholt/archiver/v3 v3.5.1
 	github.com/smartystreets/goconvey v1.8.1
 	github.com/urfave/cli v1.22.14
+	golang.org/x/crypto v0.22.0
+	golang.org/x/net v0.24.0
 )
 
 require (
 	github.com/andybalholm/brotli v1.0.1 // indirect
 	github.com/andybalholm/cascadia v1.3.2 // indirect
-	github.com/cpuguy83/go-md2man/v2 v2.0.4 // indirect
 	github.com/dsnet/compress v0.0.2-0.20210315054119-f66993602bf5 // indirect
 	github.com/golang/snappy v0.0.4 // indirect
 	github.com/gopherjs/gopherjs v1.17.2 // indirect
@@ -26,5 +29,6 @@ require (
 	github.com/smarty/assertions v1.15.1 // indirect
 	github.com/ulikunitz/xz v0.5.12 // indirect
 	github.com/xi2/xz v0.0.0-20171230120015-48954b6210f8 // indirect
-	golang.org/x/net v0.24.0 // indirect
+	golang.org/x/sys v0.19.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
 )
@@ -8,3 +8,12 @@ import (
 func CommandArch(ctx *cli.Context) {
 	fmt.Println(Validate())
 }
+
+// Emulated goos/goarch 上没有原生的安装包时可以通过系统的模拟层运行的其它架构，按优先顺序排列
+// windows/arm64 上 Windows 11 可以模拟 x64，Windows 10 只能模拟 x86
+func Emulated(goos, goarch string) []string {
+	if goos == "windows" && goarch == "arm64" {
+		return []string{"amd64", "386"}
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package arch
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestEmulated(t *testing.T) {
+	Convey("windows/arm64 先尝试 x64 再尝试 x86", t, func() {
+		So(Emulated("windows", "arm64"), ShouldResemble, []string{"amd64", "386"})
+		So(Emulated("windows", "amd64"), ShouldBeEmpty)
+		So(Emulated("linux", "arm64"), ShouldBeEmpty)
+	})
+}
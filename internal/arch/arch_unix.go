@@ -4,6 +4,7 @@ package arch
 
 import (
 	"os/exec"
+	"runtime"
 	"strings"
 )
 
@@ -21,3 +22,8 @@ func GetArch() (string, error) {
 	output, err := command.CombinedOutput()
 	return string(output), err
 }
+
+// Native 返回系统本身的架构(GOARCH 的写法)，unix 上与 envm 的架构相同
+func Native() string {
+	return runtime.GOARCH
+}
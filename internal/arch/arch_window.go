@@ -5,18 +5,54 @@ package arch
 import (
 	"os"
 	"strings"
+	"syscall"
+	"unsafe"
 )
 
-// Validate 通过系统变量，查看系统配置
-func Validate() string {
+var procIsWow64Process2 = syscall.NewLazyDLL("kernel32.dll").NewProc("IsWow64Process2")
 
-	str := os.Getenv("PROCESSOR_ARCHITECTURE")
+// machines IMAGE_FILE_MACHINE_* 对应的 GOARCH
+var machines = map[uint16]string{
+	0x014c: "386",
+	0x01c4: "arm",
+	0x8664: "amd64",
+	0xaa64: "arm64",
+}
 
-	return strings.ToLower(str)
+// processors PROCESSOR_ARCHITECTURE 对应的 GOARCH
+var processors = map[string]string{
+	"x86":   "386",
+	"amd64": "amd64",
+	"arm64": "arm64",
+}
+
+// Validate 通过系统变量，查看系统配置
+func Validate() string {
+	return Native()
 }
 
 func GetArch() (string, error) {
-	str := os.Getenv("PROCESSOR_ARCHITECTURE")
+	return Native(), nil
+}
 
-	return strings.ToLower(str), nil
+// Native 返回系统本身的架构(GOARCH 的写法)，而不是 envm 的架构
+// x64 的 envm 在 arm64 上通过模拟运行时 PROCESSOR_ARCHITECTURE 为 AMD64，IsWow64Process2 返回的才是真实的架构
+func Native() string {
+	if procIsWow64Process2.Find() == nil {
+		var process, native uint16
+		current, _ := syscall.GetCurrentProcess()
+		ok, _, _ := procIsWow64Process2.Call(uintptr(current), uintptr(unsafe.Pointer(&process)), uintptr(unsafe.Pointer(&native)))
+		if arch, found := machines[native]; ok != 0 && found {
+			return arch
+		}
+	}
+	// Windows 10 1511 之前没有 IsWow64Process2，32 位进程的 PROCESSOR_ARCHITEW6432 为系统的架构
+	str := os.Getenv("PROCESSOR_ARCHITEW6432")
+	if str == "" {
+		str = os.Getenv("PROCESSOR_ARCHITECTURE")
+	}
+	if arch, found := processors[strings.ToLower(str)]; found {
+		return arch
+	}
+	return strings.ToLower(str)
 }
@@ -0,0 +1,128 @@
+package commands_ci
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/logic/ghactions"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// target 需要安装的语言版本
+type target struct {
+	language *languages.Language
+	input    string
+}
+
+// CommandGithub envm ci github [language@version...] 在 GitHub Actions 中安装版本并交给后续步骤
+// 没有参数时安装当前项目版本文件中声明的版本，安装后将 bin 目录写入 $GITHUB_PATH、HomeEnv 等写入 $GITHUB_ENV
+// 并设置步骤输出 <language>-version、<language>-path，只有一个语言时同时设置 version、path
+// 每个语言的安装日志折叠为一组，失败输出为 ::error 注解，全部完成后再以失败退出
+func CommandGithub(ctx *cli.Context) error {
+	files, err := ghactions.FromEnv()
+	if err != nil {
+		return common.Exit(err)
+	}
+	targets, err := parseTargets(ctx.Args())
+	if err != nil {
+		return common.Exit(err)
+	}
+	// 日志不是终端，\r 刷新的进度条只会产生大量的行
+	util.Quiet = true
+
+	failed := 0
+	for _, t := range targets {
+		name := t.language.Name
+		fmt.Println(ghactions.Group(fmt.Sprintf("envm install %s %s", name, t.input)))
+		version, err := install(t)
+		if err == nil {
+			err = export(files, t.language, version, len(targets) == 1)
+		}
+		fmt.Println(ghactions.EndGroup)
+		if err != nil {
+			failed++
+			fmt.Println(ghactions.Error("envm "+name, fmt.Sprintf("install %s %s failed + %v", name, t.input, err)))
+			continue
+		}
+		fmt.Printf("%s %s => %s\n", name, version, t.language.BinDir(version))
+	}
+	if failed > 0 {
+		return cli.NewExitError(fmt.Sprintf("%d of %d languages failed to install", failed, len(targets)), 1)
+	}
+	return nil
+}
+
+// parseTargets 解析 language@version 参数，没有参数时使用当前项目声明的版本
+func parseTargets(args cli.Args) (targets []target, err error) {
+	if len(args) == 0 {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range languages.Resolve(wd) {
+			targets = append(targets, target{item.Language, item.Version})
+		}
+		if len(targets) == 0 {
+			return nil, errors.New("no language@version given and the project declares no versions")
+		}
+		return targets, nil
+	}
+	for _, arg := range args {
+		name, version, _ := strings.Cut(arg, "@")
+		language := languages.Find(name)
+		if language == nil || version == "" {
+			return nil, fmt.Errorf("invalid %q, use <language>@<version>, e.g. go@1.22", arg)
+		}
+		targets = append(targets, target{language, version})
+	}
+	return targets, nil
+}
+
+// install 解析版本后安装，返回实际安装的版本，latest 及部分版本号(1.22)从远程版本列表中选择
+func install(t target) (string, error) {
+	language := t.language
+	version, err := language.ResolveVersion(t.input)
+	if err != nil {
+		return "", err
+	}
+	if !common.IsInstalled(language.Link().Downloads, language.Prefix, version) {
+		if err = language.Preflight(); err != nil {
+			return "", err
+		}
+	}
+	if err = language.Install(context.Background(), version); err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+// export 将安装结果写入 GitHub Actions 的环境文件
+func export(files ghactions.Files, language *languages.Language, version string, single bool) error {
+	if err := files.AddPath(language.BinDir(version)); err != nil {
+		return err
+	}
+	for name, value := range language.Environ(version) {
+		if err := files.SetEnv(name, value); err != nil {
+			return err
+		}
+	}
+	outputs := [][2]string{
+		{language.Name + "-version", version},
+		{language.Name + "-path", language.InstallDir(version)},
+	}
+	if single {
+		outputs = append(outputs, [2]string{"version", version}, [2]string{"path", language.InstallDir(version)})
+	}
+	for _, output := range outputs {
+		if err := files.SetOutput(output[0], output[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package commands_daemon
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/daemon"
+	"github.com/FirewineXie/envm/internal/logic/project"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+const (
+	// pollInterval 检查版本文件及安装目录是否变化的间隔
+	pollInterval = time.Second
+	// queryTimeout hook 查询守护进程的超时，超时后直接读取版本文件
+	queryTimeout = 200 * time.Millisecond
+	// cacheLimit 缓存的目录数
+	cacheLimit = 512
+)
+
+// CommandDaemon envm daemon [start|stop|status|run]，没有参数时同 status
+// start 在后台启动守护进程，run 在前台运行，用于 systemd、launchd 等服务管理器
+func CommandDaemon(ctx *cli.Context) error {
+	switch ctx.Args().First() {
+	case "", "status":
+		return status()
+	case "start":
+		return start()
+	case "stop":
+		return stop()
+	case "run":
+		return run()
+	default:
+		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
+	}
+}
+
+// Resolve 由 shell hook 调用，守护进程运行时通过 socket 查询 dir 中声明的版本，ok 为 false 时调用者直接读取版本文件
+func Resolve(dir string) (items []languages.Resolved, ok bool) {
+	socket := config.DaemonSocket()
+	if _, err := os.Stat(socket); err != nil {
+		return nil, false
+	}
+	resp, err := daemon.Query(socket, daemon.Request{Op: daemon.OpResolve, Dir: dir}, queryTimeout)
+	if err != nil {
+		return nil, false
+	}
+	for _, e := range resp.Entries {
+		language := languages.Find(e.Language)
+		if language == nil {
+			continue
+		}
+		items = append(items, languages.Resolved{
+			Language:  language,
+			Pin:       project.Pin{Name: e.Language, Version: e.Version, File: e.File},
+			Requested: e.Requested,
+		})
+	}
+	return items, true
+}
+
+// resolve 解析 dir 中声明的版本，已安装的版本发生变化时部分版本号(1.22)的结果随之变化，同时监视各语言的安装目录
+func resolve(dir string) (entries []daemon.Entry, watched []string) {
+	for _, item := range languages.Resolve(dir) {
+		entries = append(entries, daemon.Entry{Language: item.Language.Name, Version: item.Version, Requested: item.Requested, File: item.File})
+		watched = append(watched, item.File, item.Language.Link().Downloads)
+	}
+	return entries, watched
+}
+
+// run 在前台运行守护进程，收到 stop 请求或 SIGINT/SIGTERM 时退出
+func run() error {
+	socket := config.DaemonSocket()
+	if _, err := daemon.Query(socket, daemon.Request{Op: daemon.OpStatus}, queryTimeout); err == nil {
+		return cli.NewExitError("envm daemon is already running, stop it with envm daemon stop", 1)
+	}
+	// 上一次异常退出留下的 socket 文件
+	_ = os.Remove(socket)
+	if err := os.MkdirAll(filepath.Dir(socket), os.ModePerm); err != nil {
+		return common.Exit(err)
+	}
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		return common.Exit(fmt.Errorf("listen on %s error + %w", socket, err))
+	}
+	defer os.Remove(socket)
+
+	cache := daemon.NewCache(resolve, cacheLimit)
+	started := time.Now()
+	// 后台安装不显示进度，在启动安装的 goroutine 之前设置，之后不再修改
+	util.Quiet = true
+	installer := newInstaller()
+	stopped := make(chan struct{})
+	var once sync.Once
+	shutdown := func() { once.Do(func() { close(stopped); _ = l.Close() }) }
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		select {
+		case <-signals:
+			shutdown()
+		case <-stopped:
+		}
+	}()
+
+	// 轮询监视的路径，变化的目录在后台重新解析并按 auto_install 预先安装
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopped:
+				return
+			case <-ticker.C:
+				for _, dir := range cache.Refresh() {
+					installer.add(cache.Get(dir))
+				}
+			}
+		}
+	}()
+
+	fmt.Fprintf(util.Output, "envm daemon listening on %s\n", socket)
+	err = daemon.Serve(l, func(req daemon.Request) daemon.Response {
+		switch req.Op {
+		case daemon.OpResolve:
+			if !filepath.IsAbs(req.Dir) {
+				return daemon.Response{Error: "dir must be an absolute path"}
+			}
+			entries := cache.Get(req.Dir)
+			installer.add(entries)
+			return daemon.Response{Entries: entries}
+		case daemon.OpStatus:
+			dirs, hits, misses := cache.Stats()
+			return daemon.Response{Status: &daemon.Status{PID: os.Getpid(), Started: started, Dirs: dirs, Hits: hits, Misses: misses}}
+		case daemon.OpStop:
+			// 先回应再关闭
+			time.AfterFunc(10*time.Millisecond, shutdown)
+			return daemon.Response{}
+		}
+		return daemon.Response{Error: "unknown op " + req.Op}
+	})
+	if err != nil {
+		return common.Exit(err)
+	}
+	return nil
+}
+
+// installer 在后台依次安装缺少的版本，只在配置了 auto_install 时安装，每个版本只尝试一次
+type installer struct {
+	mu    sync.Mutex
+	tried map[string]bool
+	queue chan daemon.Entry
+}
+
+func newInstaller() *installer {
+	i := &installer{tried: make(map[string]bool), queue: make(chan daemon.Entry, 64)}
+	go func() {
+		for e := range i.queue {
+			if language := languages.Find(e.Language); language != nil {
+				// AutoInstall 没有配置 auto_install 时不安装
+				language.AutoInstall(e.Version)
+			}
+		}
+	}()
+	return i
+}
+
+// add 将没有安装的版本加入队列，队列已满时跳过，由 hook 在需要时安装
+func (i *installer) add(entries []daemon.Entry) {
+	if !config.Default().Settings.AutoInstall {
+		return
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, e := range entries {
+		language := languages.Find(e.Language)
+		key := e.Language + "@" + e.Version
+		if language == nil || i.tried[key] || common.IsInstalled(language.Link().Downloads, language.Prefix, e.Version) {
+			continue
+		}
+		select {
+		case i.queue <- e:
+			i.tried[key] = true
+		default:
+		}
+	}
+}
+
+// start 在后台启动 envm daemon run，等待 socket 可以连接
+func start() error {
+	socket := config.DaemonSocket()
+	if resp, err := daemon.Query(socket, daemon.Request{Op: daemon.OpStatus}, queryTimeout); err == nil {
+		fmt.Printf("envm daemon is already running (pid %d)\n", resp.Status.PID)
+		return nil
+	}
+	envm, err := os.Executable()
+	if err != nil {
+		return common.Exit(err)
+	}
+	if err = util.StartDetached(envm, "daemon", "run"); err != nil {
+		return common.Exit(fmt.Errorf("start envm daemon error + %w", err))
+	}
+	for deadline := time.Now().Add(3 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		if resp, err := daemon.Query(socket, daemon.Request{Op: daemon.OpStatus}, queryTimeout); err == nil {
+			fmt.Printf("envm daemon started (pid %d), the shell hook now asks it instead of reading the version files\n", resp.Status.PID)
+			return nil
+		}
+	}
+	return cli.NewExitError("envm daemon did not start, run envm daemon run to see the error", 1)
+}
+
+// stop 请求守护进程退出
+func stop() error {
+	if _, err := daemon.Query(config.DaemonSocket(), daemon.Request{Op: daemon.OpStop}, time.Second); err != nil {
+		if errors.Is(err, os.ErrNotExist) || isRefused(err) {
+			fmt.Println("envm daemon is not running")
+			return nil
+		}
+		return common.Exit(err)
+	}
+	fmt.Println("envm daemon stopped")
+	return nil
+}
+
+// status 输出守护进程的状态
+func status() error {
+	resp, err := daemon.Query(config.DaemonSocket(), daemon.Request{Op: daemon.OpStatus}, time.Second)
+	if err != nil {
+		fmt.Println("envm daemon is not running, the shell hook reads the version files directly; start it with envm daemon start")
+		return nil
+	}
+	s := resp.Status
+	fmt.Printf("envm daemon is running (pid %d) since %s, listening on %s\n", s.PID, s.Started.Format(time.DateTime), config.DaemonSocket())
+	fmt.Printf("%d directories cached, %d hits, %d misses\n", s.Dirs, s.Hits, s.Misses)
+	return nil
+}
+
+// isRefused socket 文件存在但没有进程监听
+func isRefused(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
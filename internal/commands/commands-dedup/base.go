@@ -0,0 +1,88 @@
+package commands_dedup
+
+import (
+	"fmt"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/dedup"
+	"github.com/urfave/cli"
+)
+
+// CommandDedup 将已安装版本中相同的文件硬链接为同一份，不指定语言时处理所有语言
+func CommandDedup(ctx *cli.Context) error {
+	items := languages.All()
+	if ctx.NArg() > 0 {
+		items = nil
+		for _, name := range ctx.Args() {
+			language := languages.Find(name)
+			if language == nil {
+				return cli.NewExitError(name+" is not supported by envm", 1)
+			}
+			items = append(items, language)
+		}
+	}
+	dryRun := ctx.Bool("dry-run")
+	result, err := Dedup(items, dryRun)
+	if err != nil {
+		return common.Exit(fmt.Errorf("dedup error + %w", err))
+	}
+	action := "linked"
+	if dryRun {
+		action = "can link"
+	}
+	fmt.Printf("%s %d files, %.1f MB reclaimed\n", action, result.Linked, float64(result.Saved)/1024/1024)
+	if result.Failed > 0 {
+		fmt.Printf("%d files can not be linked\n", result.Failed)
+	}
+	return nil
+}
+
+// Dedup 对 items 中每个语言的已安装版本去重，不同语言之间没有相同的文件，分别处理
+func Dedup(items []*languages.Language, dryRun bool) (total dedup.Result, err error) {
+	for _, language := range items {
+		dirs := make([]string, 0)
+		for _, version := range common.GetInstalled(language.Link().Downloads, language.Prefix) {
+			dirs = append(dirs, language.InstallDir(version))
+		}
+		if len(dirs) < 2 {
+			continue
+		}
+		result, err := dedup.Run(dirs, dryRun)
+		if err != nil {
+			return total, err
+		}
+		total.Linked += result.Linked
+		total.Saved += result.Saved
+		total.Failed += result.Failed
+	}
+	return total, nil
+}
+
+// AfterInstall 配置了 dedup 时在安装后对该语言去重，用作 install 命令的 After
+func AfterInstall(name string) func(ctx *cli.Context) error {
+	return func(ctx *cli.Context) error {
+		if ctx.Args().First() == "" {
+			return nil
+		}
+		return Auto(name)
+	}
+}
+
+// Auto 配置了 dedup 时对 name 指定的语言去重，去重失败不影响安装结果
+func Auto(name string) error {
+	language := languages.Find(name)
+	if !config.Default().Settings.Dedup || language == nil {
+		return nil
+	}
+	result, err := Dedup([]*languages.Language{language}, false)
+	if err != nil {
+		fmt.Printf("dedup %s failed + %v\n", name, err)
+		return nil
+	}
+	if result.Linked > 0 {
+		fmt.Printf("dedup %s: linked %d files, %.1f MB reclaimed\n", name, result.Linked, float64(result.Saved)/1024/1024)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package commands_deno
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+
+	"github.com/FirewineXie/envm/internal/arch"
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/listing"
+	"github.com/FirewineXie/envm/internal/logic/web-deno"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+var configLocal = config.Default().LinkSetting[config.DENO]
+
+func CommandUninstall(ctx *cli.Context) error {
+	current := common.GetLinkedVersion(configLocal.Symlink, config.DENO)
+	if err := common.UninstallVersion(configLocal.Downloads, config.DENO, ctx.Args().First(), current); err != nil {
+		return common.Exit(fmt.Errorf("删除该版本失败+%w", err))
+	}
+	fmt.Fprintln(util.Output, "finish uninstall")
+	return nil
+}
+
+// CommandInstall 安装命令
+func CommandInstall(ctx *cli.Context) error {
+	versionS := ctx.Args().First()
+	if versionS == "" {
+		return cli.ShowSubcommandHelp(ctx)
+	}
+	if err := Install(context.Background(), versionS); err != nil {
+		return common.Exit(err)
+	}
+	fmt.Fprintln(util.Output, "Installed successfully")
+	return nil
+}
+
+// Install 下载并安装指定版本
+func Install(ctx context.Context, versionS string) error {
+	release, err := common.Reserve(configLocal.Downloads, config.DENO+versionS)
+	if err != nil {
+		return err
+	}
+	defer release()
+	if common.IsInstalled(configLocal.Downloads, config.DENO, versionS) {
+		fmt.Fprintln(util.Output, "this version is downloaded")
+		return nil
+	}
+	versions, err := web_deno.AllVersions()
+	if err != nil {
+		return fmt.Errorf("collect version error + %w", err)
+	}
+	var version *web_deno.VersionDeno
+	for _, v := range versions {
+		if v.Name == versionS {
+			version = v
+			break
+		}
+	}
+	if version == nil {
+		return util.ErrVersionNotFound
+	}
+	findPackage, err := common.FindPackage(version.FindPackage, runtime.GOOS, arch.Native(), false)
+	if err != nil {
+		return fmt.Errorf("find version of system error + %w", err)
+	}
+	if err = common.InstallBinaryArchive(ctx, findPackage, configLocal.Downloads, config.DENO, versionS); err != nil {
+		return err
+	}
+	common.RecordInstall(configLocal.Downloads, config.DENO+versionS, findPackage)
+	return nil
+}
+
+// CommandUse 激活使用
+func CommandUse(ctx *cli.Context) error {
+	v, err := common.GetVersion(ctx, configLocal.Downloads, config.DENO, true)
+	if err != nil {
+		return err
+	}
+	if err = Activate(v); err != nil {
+		return common.Exit(err)
+	}
+	fmt.Fprintln(util.Output, "Now using deno "+v)
+	return nil
+}
+
+// Activate 将 symlink 指向已经安装的版本
+func Activate(v string) error {
+	if !common.IsInstalled(configLocal.Downloads, config.DENO, v) {
+		return errors.New("you have not install it,please install before use")
+	}
+	return common.ActiveVersion(configLocal.Downloads, config.DENO+v, configLocal.Symlink)
+}
+
+// Releases 返回所有版本及提供安装包的平台
+func Releases() ([]listing.Release, error) {
+	versions, err := web_deno.AllVersions()
+	if err != nil {
+		return nil, err
+	}
+	releases := make([]listing.Release, 0, len(versions))
+	for _, version := range versions {
+		releases = append(releases, listing.Release{Version: version.Name, Platforms: listing.Platforms(version.Packages)})
+	}
+	return releases, nil
+}
+
+// CommandListRemote 获取远程的可下载的版本
+func CommandListRemote(ctx *cli.Context) error {
+	releases, err := Releases()
+	if err != nil {
+		return common.Exit(fmt.Errorf("collect version error + %w", err))
+	}
+	return common.PrintReleases(ctx, releases, "")
+}
+
+// ListRemote 返回所有可以安装的版本
+func ListRemote() ([]string, error) {
+	versions, err := web_deno.AllVersions()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(versions))
+	for _, version := range versions {
+		names = append(names, version.Name)
+	}
+	return names, nil
+}
+
+// CommandListInstalled 展示已经安装
+func CommandListInstalled(ctx *cli.Context) {
+	common.ListInstalled(configLocal.Downloads, config.DENO, common.GetLinkedVersion(configLocal.Symlink, config.DENO))
+}
@@ -0,0 +1,89 @@
+package commands_docs
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/cpuguy83/go-md2man/v2/md2man"
+	"github.com/urfave/cli"
+)
+
+// 支持的文档格式
+const (
+	Man      = "man"
+	Markdown = "markdown"
+)
+
+// CommandDocs 根据命令定义生成 man page(section 1) 或 markdown 文档，打包(homebrew、apt)时随程序一起发布
+// 包含所有命令、子命令、参数及 UsageText 中的示例，隐藏的内部命令不输出，--output 时写入文件
+func CommandDocs(ctx *cli.Context) error {
+	doc := markdown(ctx.App)
+	switch format := ctx.Args().First(); format {
+	case Markdown:
+	case Man:
+		doc = string(md2man.Render([]byte(doc)))
+	default:
+		return cli.NewExitError(fmt.Sprintf("unsupported format %q, use %s or %s", format, Man, Markdown), 1)
+	}
+	output := ctx.String("output")
+	if output == "" {
+		fmt.Print(doc)
+		return nil
+	}
+	if err := os.WriteFile(output, []byte(doc), 0644); err != nil {
+		return common.Exit(err)
+	}
+	fmt.Println("write " + output)
+	return nil
+}
+
+// markdown 生成 markdown 文档，开头的 % 标题块被 md2man 转换为 man page 的 .TH
+func markdown(app *cli.App) string {
+	var doc strings.Builder
+	fmt.Fprintf(&doc, "%% %s 1 \"\" \"%s %s\" \"User Commands\"\n\n", app.Name, app.Name, app.Version)
+	fmt.Fprintf(&doc, "# NAME\n\n%s - %s\n\n", app.Name, app.Usage)
+	fmt.Fprintf(&doc, "# SYNOPSIS\n\n`%s [global options] command [command options] [arguments...]`\n\n", app.Name)
+	if len(app.Flags) > 0 {
+		doc.WriteString("# GLOBAL OPTIONS\n\n")
+		writeFlags(&doc, app.Flags)
+	}
+	doc.WriteString("# COMMANDS\n\n")
+	writeCommands(&doc, app.Name, app.Commands)
+	return doc.String()
+}
+
+// writeCommands 依次输出命令及其子命令，标题为完整的命令路径，例如 envm go install
+func writeCommands(doc *strings.Builder, parent string, commands []cli.Command) {
+	for _, command := range commands {
+		if command.Hidden || command.Name == "help" {
+			continue
+		}
+		path := parent + " " + command.Name
+		fmt.Fprintf(doc, "## %s\n\n", path)
+		if command.Usage != "" {
+			fmt.Fprintf(doc, "%s\n\n", command.Usage)
+		}
+		if len(command.Aliases) > 0 {
+			fmt.Fprintf(doc, "Aliases: %s\n\n", strings.Join(command.Aliases, ", "))
+		}
+		if command.UsageText != "" {
+			fmt.Fprintf(doc, "Usage:\n\n    %s\n\n", command.UsageText)
+		}
+		writeFlags(doc, command.Flags)
+		writeCommands(doc, path, command.Subcommands)
+	}
+}
+
+// writeFlags 每个参数一项，内容与 --help 相同，包括默认值及环境变量
+func writeFlags(doc *strings.Builder, flags []cli.Flag) {
+	if len(flags) == 0 {
+		return
+	}
+	for _, flag := range flags {
+		name, usage, _ := strings.Cut(flag.String(), "\t")
+		fmt.Fprintf(doc, "- `%s`: %s\n", name, usage)
+	}
+	doc.WriteString("\n")
+}
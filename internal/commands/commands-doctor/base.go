@@ -0,0 +1,168 @@
+package commands_doctor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/FirewineXie/envm/internal/commands/commands-shim"
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/wsl"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// CommandDoctor 检查 envm 的配置，并说明 PATH 中哪些可执行文件覆盖了 envm 选择的版本
+func CommandDoctor(ctx *cli.Context) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return common.Exit(err)
+	}
+	problems := 0
+	fmt.Println("ENVM_HOME     " + config.Default().Root)
+	if config.IsShared() {
+		fmt.Println("shared root   " + config.SharedRoot())
+	}
+	if config.Default().Settings.Portable {
+		fmt.Println("portable      true")
+	}
+	if reason := common.ReadOnlyReason(); reason != "" {
+		fmt.Println("read-only     " + reason)
+	}
+	problems += checkWSL()
+	checkLayout(os.Getenv("PATH"))
+
+	path := os.Getenv("PATH")
+	for _, item := range languages.Selections(wd) {
+		problems += checkShadow(item, path)
+	}
+	if problems > 0 {
+		return cli.NewExitError(fmt.Sprintf("%d problems found", problems), 1)
+	}
+	fmt.Println("no problems found")
+	return nil
+}
+
+// checkWSL 在 WSL 中检查安装目录是否在 windows 盘符下，以及 windows 上是否也安装了 envm
+func checkWSL() (problems int) {
+	if !wsl.Detected() {
+		return 0
+	}
+	fmt.Println("wsl           true")
+	if wsl.IsWindowsPath(config.SharedRoot()) {
+		problems++
+		fmt.Printf("! %s is on a windows drive, linux versions installed there are slow and windows versions can not run in WSL, set ENVM_HOME to a linux path\n", config.SharedRoot())
+	}
+	for _, root := range wsl.WindowsEnvm(os.Getenv("PATH")) {
+		fmt.Printf("windows envm  %s, its directories are removed from PATH by envm env, envm exec and the shell hook\n", root)
+	}
+	return problems
+}
+
+// checkLayout bin_per_language 时列出每个语言的 shims 目录是否在 PATH 中，不在 PATH 中的语言不由 envm 管理
+func checkLayout(path string) {
+	if !config.Default().Settings.BinPerLanguage {
+		return
+	}
+	fmt.Println("bin layout    per language")
+	for _, language := range languages.All() {
+		dir := config.LanguageShimsDir(language.Name)
+		if exists, _ := util.PathExists(dir); !exists {
+			continue
+		}
+		state := "in PATH"
+		if !inPath(dir, path) {
+			state = "not in PATH, " + language.Name + " is not managed by envm"
+		}
+		fmt.Printf("  %-10s  %s (%s)\n", language.Name, dir, state)
+	}
+}
+
+// inPath 判断 dir 是否在 PATH 中
+func inPath(dir, path string) bool {
+	for _, item := range filepath.SplitList(path) {
+		if filepath.Clean(item) == filepath.Clean(dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkShadow 检查 item 中的每个可执行文件在 PATH 中第一个找到的是否是 envm 管理的版本
+func checkShadow(item languages.Selection, path string) (problems int) {
+	language := item.Language
+	title := fmt.Sprintf("%s %s (set by %s)", language.Name, item.Version, item.Source)
+	binDir := language.BinDir(item.Version)
+	if exists, _ := util.PathExists(language.InstallDir(item.Version)); !exists {
+		fmt.Printf("! %s is not installed\n", title)
+		return 1
+	}
+	if err := language.Check(item.Version); err != nil {
+		fmt.Printf("! %s\n", err)
+		return 1
+	}
+
+	// envm 的版本可以来自版本目录(envm env/hook)、symlink 或 shims
+	shims := config.LanguageShimsDir(language.Name)
+	owned := map[string]bool{filepath.Clean(binDir): true, filepath.Clean(shims): true}
+	if symlink := language.Link().Symlink; symlink != "" {
+		owned[filepath.Clean(filepath.Join(symlink, language.Bin))] = true
+	}
+	suggest := binDir
+	if config.Default().Settings.BinPerLanguage {
+		suggest = shims
+		managed := false
+		for dir := range owned {
+			managed = managed || inPath(dir, path)
+		}
+		if !managed {
+			// 从 PATH 中移除该语言的目录表示不再由 envm 管理，不是问题
+			fmt.Printf("%s: not managed, %s is not in PATH\n", title, shims)
+			return 0
+		}
+	}
+
+	fmt.Println(title)
+	names := commands_shim.Executables(binDir)
+	sort.Strings(names)
+	for _, name := range names {
+		found := lookAll(name, path)
+		switch {
+		case len(found) == 0:
+			problems++
+			fmt.Printf("  ! %-12s not in PATH, use envm env, envm hook or add %s to PATH\n", name, suggest)
+		case !owned[filepath.Dir(found[0])]:
+			problems++
+			fmt.Printf("  ! %-12s %s shadows envm's %s\n", name, found[0], filepath.Join(binDir, name))
+		default:
+			fmt.Printf("    %-12s %s\n", name, found[0])
+		}
+		for _, shadowed := range found[1:] {
+			note := ""
+			if wsl.IsWindowsPath(shadowed) {
+				note = " (windows)"
+			}
+			fmt.Printf("    %-12s   shadows %s%s\n", "", shadowed, note)
+		}
+	}
+	return problems
+}
+
+// lookAll 按 PATH 的顺序返回所有名为 name 的可执行文件，同一个目录只返回一次
+func lookAll(name, path string) (found []string) {
+	seen := map[string]bool{}
+	for _, dir := range filepath.SplitList(path) {
+		dir = filepath.Clean(dir)
+		if dir == "." || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		if binary, ok := commands_shim.FindBinary(dir, name); ok {
+			found = append(found, binary)
+		}
+	}
+	return found
+}
@@ -0,0 +1,169 @@
+package commands_env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/commands/commands-shim"
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/logic/shell"
+	"github.com/FirewineXie/envm/internal/logic/wsl"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// envPath 上一次 envm env 加入 PATH 的目录，再次执行时先移除
+const envPath = "ENVM_ENV_PATH"
+
+// dotenvPath --format dotenv 时需要加入 PATH 的目录，.env 中无法引用原来的 PATH，由使用者自行拼接
+const dotenvPath = "ENVM_PATH"
+
+// environment 当前目录下选择的版本对应的环境变量，PATH 中包含 shims 目录(如果存在)，bin_per_language 时为每个语言的目录
+func environment() (map[string]string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	paths, env := languages.Environment(wd)
+	paths = append(paths, commands_shim.Dirs()...)
+	env["PATH"] = shell.PrependPath(wsl.Path(os.Getenv("PATH")), filepath.SplitList(os.Getenv(envPath)), paths)
+	env[envPath] = strings.Join(paths, string(os.PathListSeparator))
+	return env, nil
+}
+
+// CommandEnv 输出当前目录下选择的版本需要的环境变量，例如 eval "$(envm env bash)"
+// 版本的优先级为 ENVM_<LANG>_VERSION > 项目版本文件 > envm <lang> active 选择的版本
+// --output 时写入激活脚本，例如 envm env --output activate.cmd cmd 后在 cmd.exe 中 call activate.cmd
+// --format dotenv 时输出 KEY=VALUE，加入 PATH 的目录单独放在 ENVM_PATH 中，例如 envm env --format dotenv > .env.toolchain
+func CommandEnv(ctx *cli.Context) error {
+	sh := ctx.Args().First()
+	switch format := ctx.String("format"); format {
+	case "":
+		if sh == "" {
+			sh = shell.Bash
+		}
+		if !shell.Supported(sh) {
+			return cli.NewExitError("supported shells: "+strings.Join(shell.Shells, ", "), 1)
+		}
+	case shell.Dotenv:
+		sh = shell.Dotenv
+	default:
+		return cli.NewExitError(fmt.Sprintf("unsupported format %q, only %s is supported", format, shell.Dotenv), 1)
+	}
+	env, err := environment()
+	if err != nil {
+		return common.Exit(err)
+	}
+	if sh == shell.Dotenv {
+		env[dotenvPath] = env[envPath]
+		delete(env, envPath)
+		delete(env, "PATH")
+	}
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		lines = append(lines, shell.Export(sh, name, env[name]))
+	}
+
+	output := ctx.String("output")
+	if output == "" {
+		fmt.Println(strings.Join(lines, "\n"))
+		return nil
+	}
+	// cmd.exe 执行 .cmd 文件时需要 CRLF 换行
+	newline := "\n"
+	if sh == shell.Cmd {
+		newline = "\r\n"
+	}
+	if err = os.WriteFile(output, []byte(strings.Join(lines, newline)+newline), 0644); err != nil {
+		return common.Exit(err)
+	}
+	fmt.Println("write " + output)
+	return nil
+}
+
+// withs 解析 exec 命令开头的 --with <lang>@<version>(或 --with=<lang>@<version>)，返回这些声明及剩余的参数
+func withs(args []string) (pins []string, rest []string, err error) {
+	for len(args) > 0 {
+		switch {
+		case args[0] == "--with":
+			if len(args) < 2 {
+				return nil, nil, errors.New("--with requires <language>@<version>")
+			}
+			pins, args = append(pins, args[1]), args[2:]
+		case strings.HasPrefix(args[0], "--with="):
+			pins, args = append(pins, strings.TrimPrefix(args[0], "--with=")), args[1:]
+		default:
+			return pins, args, nil
+		}
+	}
+	return pins, args, nil
+}
+
+// compose 将 --with 声明的版本设置到 ENVM_<LANG>_VERSION，优先于项目版本文件，执行的命令中再调用 envm 时同样生效
+// 部分版本号(1.22)选择已经安装的最新匹配版本，没有安装时按 auto_install 配置安装
+func compose(pins []string) error {
+	defer languages.RedirectInstallOutput()()
+	for _, pin := range pins {
+		name, version, _ := strings.Cut(pin, "@")
+		language := languages.Find(name)
+		if language == nil {
+			return fmt.Errorf("%s is not supported by envm", name)
+		}
+		if version == "" {
+			return fmt.Errorf("--with %s: version is missing, use %s@<version>", pin, name)
+		}
+		version, ok := language.AutoInstall(language.InstalledVersion(version))
+		if !ok {
+			return fmt.Errorf("%s %s is not installed, install it with envm %s install %s", language.Name, version, language.Name, version)
+		}
+		if err := os.Setenv(language.VersionEnv(), version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CommandExec 使用当前目录下选择的版本执行命令，不修改任何全局配置
+// --with go@1.22 --with java@17 临时组合多个语言的版本，例如在多语言的 monorepo 中构建
+func CommandExec(ctx *cli.Context) error {
+	pins, args, err := withs(ctx.Args())
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	if len(args) > 0 && args[0] == "--" {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return cli.ShowSubcommandHelp(ctx)
+	}
+	if err = compose(pins); err != nil {
+		return common.Exit(err)
+	}
+	env, err := environment()
+	if err != nil {
+		return common.Exit(err)
+	}
+	for name, value := range env {
+		_ = os.Setenv(name, value)
+	}
+	// PATH 已经修改，LookPath 会找到选择的版本
+	binary, err := exec.LookPath(args[0])
+	if err != nil {
+		return cli.NewExitError(err.Error(), 127)
+	}
+	if err = util.Exec(binary, args); err != nil {
+		return common.Exit(err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package commands_env
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/logic/shell"
+	"github.com/FirewineXie/envm/internal/logic/wsl"
+	"github.com/urfave/cli"
+)
+
+// SessionFlags active 命令的 --session 相关参数
+var SessionFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "session",
+		Usage: "only switch the current shell, e.g. eval \"$(envm go active --session 1.22.3)\"",
+	},
+	cli.StringFlag{
+		Name:  "shell",
+		Value: shell.Bash,
+		Usage: "shell of the --session output: " + strings.Join(shell.Shells, ", "),
+	},
+}
+
+// Session 包装语言的 active 命令，指定 --session 时只输出当前 shell 需要执行的语句，不修改 symlink
+func Session(name string, action func(ctx *cli.Context) error) func(ctx *cli.Context) error {
+	return func(ctx *cli.Context) error {
+		if !ctx.Bool("session") {
+			return action(ctx)
+		}
+		if err := sessionUse(name, ctx.Args().First(), ctx.String("shell")); err != nil {
+			return common.Exit(err)
+		}
+		return nil
+	}
+}
+
+// sessionUse 通过 ENVM_<LANG>_VERSION 记录会话中的版本，shims 及 envm env 同样使用该版本
+func sessionUse(name, version, sh string) error {
+	language := languages.Find(name)
+	if language == nil {
+		return fmt.Errorf("unknown language %s", name)
+	}
+	if !shell.Supported(sh) {
+		return fmt.Errorf("supported shells: %s", strings.Join(shell.Shells, ", "))
+	}
+	if !common.IsInstalled(language.Link().Downloads, language.Prefix, version) {
+		return fmt.Errorf("you have not install it,please install before use")
+	}
+	env := SessionEnv(language, version)
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Println(shell.Export(sh, name, env[name]))
+	}
+	return nil
+}
+
+// SessionEnv 返回只在当前会话中使用 version 需要设置的环境变量，envm try 启动的 shell 同样使用
+func SessionEnv(language *languages.Language, version string) map[string]string {
+	upper := strings.ToUpper(language.Name)
+	pathEnv := "ENVM_" + upper + "_SESSION_PATH"
+	bin := language.BinDir(version)
+
+	env := language.Environ(version)
+	env["ENVM_"+upper+"_VERSION"] = version
+	env[pathEnv] = bin
+	env["PATH"] = shell.PrependPath(wsl.Path(os.Getenv("PATH")), filepath.SplitList(os.Getenv(pathEnv)), []string{bin})
+	return env
+}
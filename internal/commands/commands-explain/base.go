@@ -0,0 +1,70 @@
+package commands_explain
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/logic/errcode"
+	"github.com/FirewineXie/envm/internal/logic/suggest"
+	"github.com/urfave/cli"
+)
+
+// CommandExplain 输出错误码可能的原因及修复步骤，没有参数时列出所有错误码
+func CommandExplain(ctx *cli.Context) error {
+	if !ctx.Args().Present() {
+		for _, id := range errcode.IDs() {
+			code, _ := errcode.Lookup(id)
+			fmt.Printf("%-14s %s\n", code.ID, code.Title)
+		}
+		return nil
+	}
+	code, ok := errcode.Lookup(ctx.Args().First())
+	if !ok {
+		message := suggest.Message(suggest.Closest(ctx.Args().First(), errcode.IDs(), 3))
+		if message == "" {
+			message = ", run envm explain for the list of codes"
+		}
+		return cli.NewExitError(fmt.Sprintf("unknown error code %q%s", ctx.Args().First(), message), 1)
+	}
+	fmt.Printf("%s: %s\n\npossible causes:\n", code.ID, code.Title)
+	for _, cause := range code.Causes {
+		fmt.Println("  - " + cause)
+	}
+	fmt.Println("\nhow to fix:")
+	for i, step := range code.Steps {
+		fmt.Printf("  %d. %s\n", i+1, step)
+	}
+	return nil
+}
+
+// Report 输出带错误码的失败信息及查看说明的命令，issue 中附上错误码即可定位失败的类型
+func Report(w io.Writer, prefix string, err error) {
+	report(w, prefix, errcode.Of(err), err)
+}
+
+func report(w io.Writer, prefix, id string, err error) {
+	fmt.Fprintf(w, "%s[%s] %s\n", prefix, id, err.Error())
+	fmt.Fprintf(w, "run envm explain %s for the causes and how to fix it\n", id)
+}
+
+// HandleExit 替代 cli.HandleExitCoder，命令返回的失败都输出错误码
+// common.Exit 包装的错误按类型分配，cli.NewExitError 等没有原始错误的使用 errcode.Rejected，没有内容的只退出
+func HandleExit(_ *cli.Context, err error) {
+	var coder cli.ExitCoder
+	if err == nil || !errors.As(err, &coder) {
+		cli.HandleExitCoder(err)
+		return
+	}
+	if err.Error() != "" {
+		id := errcode.Of(err)
+		var exitErr *common.ExitError
+		if id == errcode.Unknown && !errors.As(err, &exitErr) {
+			id = errcode.Rejected
+		}
+		report(os.Stderr, "", id, err)
+	}
+	cli.OsExiter(coder.ExitCode())
+}
@@ -0,0 +1,159 @@
+package commands_flutter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/FirewineXie/envm/internal/arch"
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/listing"
+	"github.com/FirewineXie/envm/internal/logic/web-flutter"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+var configLocal = config.Default().LinkSetting[config.FLUTTER]
+
+// pubCache 每个版本独立的 pub 缓存目录
+const pubCache = ".pub-cache"
+
+func CommandUninstall(ctx *cli.Context) error {
+	current := common.GetLinkedVersion(configLocal.Symlink, config.FLUTTER)
+	if err := common.UninstallVersion(configLocal.Downloads, config.FLUTTER, ctx.Args().First(), current); err != nil {
+		return common.Exit(fmt.Errorf("删除该版本失败+%w", err))
+	}
+	fmt.Fprintln(util.Output, "finish uninstall")
+	return nil
+}
+
+// CommandInstall 安装命令，版本可以是 stable/beta，表示该渠道的当前版本
+func CommandInstall(ctx *cli.Context) error {
+	versionS := ctx.Args().First()
+	if versionS == "" {
+		return cli.ShowSubcommandHelp(ctx)
+	}
+	if err := Install(context.Background(), versionS); err != nil {
+		return common.Exit(err)
+	}
+	return nil
+}
+
+// Install 下载并安装指定版本，版本可以是 stable/beta
+func Install(ctx context.Context, versionS string) error {
+	collector, err := web_flutter.NewCollector(runtime.GOOS)
+	if err != nil {
+		return fmt.Errorf("collect version error + %w", err)
+	}
+	version, err := collector.FindVersion(versionS)
+	if err != nil {
+		return err
+	}
+	release, err := common.Reserve(configLocal.Downloads, config.FLUTTER+version.Name)
+	if err != nil {
+		return err
+	}
+	defer release()
+	if common.IsInstalled(configLocal.Downloads, config.FLUTTER, version.Name) {
+		fmt.Fprintln(util.Output, "this version is downloaded")
+		return nil
+	}
+	findPackage, err := common.FindPackage(version.FindPackage, runtime.GOOS, arch.Native(), false)
+	if err != nil {
+		return fmt.Errorf("find version of system error + %w", err)
+	}
+	// 安装包超过 1GB, 下载中断后重新执行 install 会断点续传
+	if err = common.InstallDirArchive(ctx, findPackage, configLocal.Downloads, config.FLUTTER, version.Name); err != nil {
+		return fmt.Errorf("install version error + %w, run install again to resume", err)
+	}
+	if err = os.MkdirAll(filepath.Join(configLocal.Downloads, config.FLUTTER+version.Name, pubCache), os.ModePerm); err != nil {
+		return err
+	}
+	common.RecordInstall(configLocal.Downloads, config.FLUTTER+version.Name, findPackage)
+	fmt.Fprintf(util.Output, "Installed successfully %s (%s, dart %s)\n", version.Name, version.Channel, version.DartVersion)
+	return nil
+}
+
+// CommandEnv 输出 PUB_CACHE 配置，指向 symlink 下的缓存目录，切换版本时缓存随之切换
+func CommandEnv(ctx *cli.Context) error {
+	cache := filepath.Join(configLocal.Symlink, pubCache)
+	if runtime.GOOS == "windows" {
+		fmt.Fprintf(util.Output, "$env:PUB_CACHE = \"%s\"\n", cache)
+		return nil
+	}
+	fmt.Fprintf(util.Output, "export PUB_CACHE=\"%s\"\n", cache)
+	return nil
+}
+
+// CommandUse 激活使用
+func CommandUse(ctx *cli.Context) error {
+	v, err := common.GetVersion(ctx, configLocal.Downloads, config.FLUTTER, true)
+	if err != nil {
+		return err
+	}
+	if err = Activate(v); err != nil {
+		return common.Exit(err)
+	}
+	fmt.Fprintln(util.Output, "Now using flutter "+v)
+	return nil
+}
+
+// Activate 将 symlink 指向已经安装的版本
+func Activate(v string) error {
+	if !common.IsInstalled(configLocal.Downloads, config.FLUTTER, v) {
+		return errors.New("you have not install it,please install before use")
+	}
+	return common.ActiveVersion(configLocal.Downloads, config.FLUTTER+v, configLocal.Symlink)
+}
+
+// ListRemote 返回当前系统所有渠道可以安装的版本
+func ListRemote() ([]string, error) {
+	collector, err := web_flutter.NewCollector(runtime.GOOS)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0)
+	for _, version := range collector.ChannelVersions("") {
+		names = append(names, version.Name)
+	}
+	return names, nil
+}
+
+// Releases 返回当前系统 stable 与 beta 渠道的版本，类型为渠道，发布信息按系统区分，平台只包含当前系统
+func Releases() ([]listing.Release, error) {
+	collector, err := web_flutter.NewCollector(runtime.GOOS)
+	if err != nil {
+		return nil, err
+	}
+	releases := make([]listing.Release, 0)
+	for _, version := range collector.ChannelVersions("") {
+		r := listing.Release{Version: version.Name, Kind: version.Channel, Note: fmt.Sprintf("%-8s dart %s", version.Channel, version.DartVersion)}
+		for _, pkg := range version.Packages {
+			r.Platforms = append(r.Platforms, listing.Platform(runtime.GOOS, pkg.Arch))
+		}
+		releases = append(releases, r)
+	}
+	return releases, nil
+}
+
+// CommandListRemote 获取远程的可下载的版本，位置参数 stable|beta 同 --kind
+func CommandListRemote(ctx *cli.Context) error {
+	channel := ctx.Args().First()
+	if channel != "" && channel != web_flutter.Stable && channel != web_flutter.Beta {
+		return cli.ShowSubcommandHelp(ctx)
+	}
+	releases, err := Releases()
+	if err != nil {
+		return common.Exit(fmt.Errorf("collect version error + %w", err))
+	}
+	return common.PrintReleases(ctx, releases, channel)
+}
+
+// CommandListInstalled 展示已经安装
+func CommandListInstalled(ctx *cli.Context) {
+	common.ListInstalled(configLocal.Downloads, config.FLUTTER, common.GetLinkedVersion(configLocal.Symlink, config.FLUTTER))
+}
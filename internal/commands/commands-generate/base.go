@@ -0,0 +1,102 @@
+package commands_generate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/logic/container"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// options 根据当前目录所在项目声明的版本生成 Dockerfile 的参数
+func options(ctx *cli.Context, base string) (container.Options, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return container.Options{}, err
+	}
+	items := languages.Resolve(wd)
+	if len(items) == 0 {
+		return container.Options{}, errors.New("no version is pinned in " + wd + ", add them to .envmrc first")
+	}
+	opts := container.Options{Base: base, EnvmVersion: ctx.App.Version}
+	seen := map[string]bool{}
+	for _, item := range items {
+		opts.Toolchains = append(opts.Toolchains, container.Toolchain{Name: item.Language.Name, Version: item.Version,
+			Bin: item.Language.LinuxBin(), HomeEnv: item.Language.HomeEnv})
+		if !seen[item.File] {
+			seen[item.File] = true
+			// 注释中不出现本机的绝对路径
+			source, err := filepath.Rel(wd, item.File)
+			if err != nil {
+				source = filepath.Base(item.File)
+			}
+			opts.Sources = append(opts.Sources, filepath.ToSlash(source))
+		}
+	}
+	return opts, nil
+}
+
+// CommandDockerfile 输出在镜像中安装项目声明版本的 Dockerfile，--output 时写入文件
+func CommandDockerfile(ctx *cli.Context) error {
+	opts, err := options(ctx, ctx.String("base"))
+	if err != nil {
+		return common.Exit(err)
+	}
+	dockerfile := container.Dockerfile(opts)
+	output := ctx.String("output")
+	if output == "" {
+		fmt.Print(dockerfile)
+		return nil
+	}
+	if err = os.WriteFile(output, []byte(dockerfile), 0644); err != nil {
+		return common.Exit(err)
+	}
+	fmt.Println("write " + output)
+	return nil
+}
+
+// CommandDevcontainer 在 --dir(默认 .devcontainer) 中生成 Dockerfile 及 devcontainer.json，已经存在时询问是否覆盖，--force 直接覆盖
+func CommandDevcontainer(ctx *cli.Context) error {
+	base := ctx.String("base")
+	if base == "" {
+		base = container.DevcontainerBase
+	}
+	opts, err := options(ctx, base)
+	if err != nil {
+		return common.Exit(err)
+	}
+	dir, err := filepath.Abs(ctx.String("dir"))
+	if err != nil {
+		return common.Exit(err)
+	}
+	devcontainer, err := container.Devcontainer(filepath.Base(filepath.Dir(dir)))
+	if err != nil {
+		return common.Exit(err)
+	}
+	files := map[string][]byte{
+		filepath.Join(dir, "Dockerfile"):        []byte(container.Dockerfile(opts)),
+		filepath.Join(dir, "devcontainer.json"): devcontainer,
+	}
+	if !ctx.Bool("force") {
+		for name := range files {
+			if exists, _ := util.PathExists(name); exists && !util.ConfirmRemoval(name+" already exists, overwrite it?") {
+				return cli.NewExitError(name+" already exists, use --force to overwrite it", 1)
+			}
+		}
+	}
+	if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+		return common.Exit(err)
+	}
+	for _, name := range []string{filepath.Join(dir, "Dockerfile"), filepath.Join(dir, "devcontainer.json")} {
+		if err = os.WriteFile(name, files[name], 0644); err != nil {
+			return common.Exit(err)
+		}
+		fmt.Println("write " + name)
+	}
+	return nil
+}
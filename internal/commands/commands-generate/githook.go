@@ -0,0 +1,78 @@
+package commands_generate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/logic/githook"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// GitHookFlags envm generate git-hook 的参数
+var GitHookFlags = []cli.Flag{
+	cli.StringFlag{Name: "hook", Value: "pre-commit", Usage: strings.Join(githook.Hooks, " or ")},
+	cli.StringFlag{Name: "format", Value: githook.FormatGit, Usage: githook.FormatGit + " writes into the hooks directory of the repository, " +
+		githook.FormatHusky + " writes .husky/<hook> to commit with the project, " + githook.FormatPreCommit + " prints an entry of .pre-commit-config.yaml"},
+	cli.BoolFlag{Name: "force", Usage: "overwrite an existing hook which was not generated by envm"},
+}
+
+// CommandGitHook 生成提交或推送前执行 envm check 的 git hook，版本与项目声明不一致时阻止提交并提示修复方法
+// git 格式写入 git rev-parse --git-path hooks(包括 core.hooksPath)，husky 写入仓库根目录的 .husky
+func CommandGitHook(ctx *cli.Context) error {
+	hook, format := ctx.String("hook"), ctx.String("format")
+	if err := githook.Check(hook, format); err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	if format == githook.FormatPreCommit {
+		fmt.Print(githook.PreCommitConfig(hook))
+		return nil
+	}
+
+	var dir string
+	var err error
+	if format == githook.FormatHusky {
+		dir, err = gitPath("--show-toplevel")
+		dir = filepath.Join(dir, ".husky")
+	} else {
+		dir, err = gitPath("--git-path", "hooks")
+	}
+	if err != nil {
+		return common.Exit(err)
+	}
+	name := filepath.Join(dir, hook)
+	if data, err := os.ReadFile(name); err == nil && !strings.Contains(string(data), githook.Marker) && !ctx.Bool("force") {
+		if !util.ConfirmRemoval(name + " already exists and was not generated by envm, overwrite it?") {
+			return cli.NewExitError(name+" already exists, use --force to overwrite it", 1)
+		}
+	}
+	if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+		return common.Exit(err)
+	}
+	if err = os.WriteFile(name, []byte(githook.Script(hook)), 0755); err != nil {
+		return common.Exit(err)
+	}
+	fmt.Println("write " + name)
+	if format == githook.FormatHusky {
+		fmt.Println("commit it so every developer runs the check, husky installs it with npm install")
+	}
+	return nil
+}
+
+// gitPath 执行 git rev-parse，返回当前仓库中的绝对路径，--git-path 输出的是相对当前目录的路径
+func gitPath(args ...string) (string, error) {
+	output, err := exec.Command("git", append([]string{"rev-parse"}, args...)...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("not in a git repository: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("run git error + %w", err)
+	}
+	return filepath.Abs(strings.TrimSpace(string(output)))
+}
@@ -1,136 +1,275 @@
 package commands_go
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/FirewineXie/envm/internal/arch"
 	"github.com/FirewineXie/envm/internal/commands/common"
 	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/advisory"
+	"github.com/FirewineXie/envm/internal/logic/listing"
 	"github.com/FirewineXie/envm/internal/logic/web-go"
 	"github.com/FirewineXie/envm/util"
-	"github.com/mholt/archiver/v3"
 
 	"github.com/urfave/cli"
 	"os"
 	"os/exec"
-	"path"
 	"path/filepath"
-	"regexp"
 	"runtime"
 )
 
 var configLocal = config.Default().LinkSetting[config.GO]
 
+// ArchFlag install 及 active 的 --arch，安装及使用其它架构的工具链
+var ArchFlag = cli.StringFlag{Name: "arch", Usage: "GOARCH of the toolchain, e.g. 386 on amd64 windows for legacy builds, defaults to the system architecture"}
+
 func CommandUninstall(ctx *cli.Context) error {
 	versionS := ctx.Args().First()
 
 	version := common.GetCurrentVersion("go")
-	if versionS == version {
+	// envm link 登记的名称不会出现在 go version 中，同时比较 symlink 指向的版本
+	if versionS == version || versionS == common.GetLinkedVersion(configLocal.Symlink, "go") {
 		return cli.NewExitError("不能卸载当前版本", 1)
 	}
-	err := os.RemoveAll(filepath.Join(configLocal.Downloads, "go"+versionS))
+	err := common.RemoveDir(filepath.Join(configLocal.Downloads, "go"+versionS))
 	if err != nil {
-		return cli.NewExitError("删除该版本失败+"+err.Error(), 1)
+		return common.Exit(fmt.Errorf("删除该版本失败+%w", err))
 	}
-	fmt.Println("finish uninstall")
+	// 该版本构建的工具不能被其它版本使用
+	_ = os.RemoveAll(filepath.Dir(ToolsBin(versionS)))
+	fmt.Fprintln(util.Output, "finish uninstall")
 	return nil
 }
 
 // CommandInstall 安装命令
 func CommandInstall(ctx *cli.Context) error {
-	versionS := ctx.Args().First()
-	collector, err := web_go.NewCollector("")
+	if err := InstallArch(context.Background(), ctx.Args().First(), ctx.String("arch")); err != nil {
+		return common.Exit(err)
+	}
+	fmt.Fprintln(util.Output, "Installed successfully")
+	return nil
+}
+
+// Install 下载并安装指定版本的系统架构的工具链
+func Install(ctx context.Context, versionS string) error {
+	return InstallArch(ctx, versionS, "")
+}
+
+// InstallArch 下载并安装指定版本 goarch 架构的工具链，goarch 为空时使用系统的架构，架构记录在版本目录中
+// 同一个版本只能安装一种架构，指定的架构与已安装的不同时返回错误
+func InstallArch(ctx context.Context, versionS, goarch string) error {
+	if versionS == "" {
+		return errors.New("version can not be empty")
+	}
+	release, err := common.Reserve(configLocal.Downloads, "go"+versionS)
 	if err != nil {
-		return cli.NewExitError(fmt.Sprintf("collect version error1 + %v", err), 1)
+		return err
 	}
-	var version *web_go.VersionGO
-	versions, err := collector.AllVersions()
-	for _, v := range versions {
-		if v.Name == versionS {
-			version = v
-			break
+	defer release()
+	if common.IsInstalled(configLocal.Downloads, "go", versionS) {
+		if err = checkArch(versionS, goarch); err != nil {
+			return err
 		}
+		fmt.Fprintln(util.Output, "this version is downloaded")
+		return nil
+	}
+	if goarch == "" {
+		goarch = arch.Native()
+	}
+	version, err := findVersion(versionS)
+	if err != nil {
+		return err
 	}
-	findPackage, err := version.FindPackage(util.ArchiveKind, runtime.GOOS, arch.Validate())
+	// 安装程序(pkg/msi)中同样是完整的 go 目录，展开后与压缩包相同
+	findPackage, err := common.FindPackage(version.FindPackage, runtime.GOOS, goarch, true)
 	if err != nil {
-		return cli.NewExitError(fmt.Sprintf("find version of system error + %v", err), 1)
+		return fmt.Errorf("find version of system error + %w", err)
 	}
 	downloadPath := filepath.Clean(filepath.Join(configLocal.Downloads, findPackage.FileName))
-	findPackage.URL = "https://golang.google.cn" + findPackage.URL
-	err = findPackage.DownloadV2(downloadPath)
+	// 版本列表中的地址为 /dl/ 开头的路径，依次拼接 config.toml 中配置的镜像
+	err = common.FromMirrors(ctx, config.GO, "", findPackage, func() error {
+		return findPackage.DownloadContext(ctx, downloadPath)
+	})
 	if err != nil {
-		return cli.NewExitError(fmt.Sprintf("download version error + %v", err), 1)
+		return fmt.Errorf("download version error + %w", err)
 	}
-	err = findPackage.VerifyChecksum(downloadPath)
+	defer util.RemoveDownloaded(downloadPath)()
+	err = findPackage.Verify(downloadPath)
 	if err != nil {
-		return cli.NewExitError(fmt.Sprintf("verify version error + %v", err), 1)
+		return fmt.Errorf("verify version error + %w", err)
 	}
+	common.PinChecksum(findPackage, downloadPath)
 
-	// 解压安装包
-	unchivePath := filepath.Clean(filepath.Join(configLocal.Downloads))
-	if err = archiver.Unarchive(downloadPath, unchivePath); err != nil {
-		return cli.NewExitError(fmt.Sprintf(" %s", err.Error()), 1)
+	// 解压到临时目录后将包内的 go 目录重命名为 go<version>
+	if err = common.ExtractDir(downloadPath, "go", filepath.Join(configLocal.Downloads, "go"+versionS)); err != nil {
+		return err
 	}
-	err = os.Remove(downloadPath)
-	// 目录重命名
-	if err = os.Rename(filepath.Join(unchivePath, "go"), filepath.Clean(filepath.Join(configLocal.Downloads, "go"+versionS))); err != nil {
-		return cli.NewExitError(fmt.Sprintf(" %s", err.Error()), 1)
+	if err = common.WriteMetadata(configLocal.Downloads, "go"+versionS, common.Metadata{Arch: goarch}); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: record the arch of go%s error + %v\n", versionS, err)
 	}
-	fmt.Println("Installed successfully")
+	common.KeepArchive(configLocal.Downloads, "go"+versionS, downloadPath, common.KeptArchive{Dir: "go"})
+	common.RecordInstall(configLocal.Downloads, "go"+versionS, findPackage)
 	return nil
 }
 
-// CommandUse 激活使用go版本
-func CommandUse(ctx *cli.Context) error {
-	v, err := common.GetVersion(ctx, configLocal.Downloads, "go", true)
+// findVersion 从下载页面查找指定版本及其安装包列表
+func findVersion(versionS string) (*web_go.VersionGO, error) {
+	defer util.Phase(util.PhaseResolve)()
+	collector, err := web_go.NewCollector("")
 	if err != nil {
+		return nil, fmt.Errorf("collect version error1 + %w", err)
+	}
+	versions, err := collector.AllVersions()
+	for _, v := range versions {
+		if v.Name == versionS {
+			return v, nil
+		}
+	}
+	return nil, util.ErrVersionNotFound
+}
 
-		return err
+// CommandUse 激活使用go版本，没有指定版本时根据当前模块 go.mod 的 go/toolchain 指令选择
+func CommandUse(ctx *cli.Context) error {
+	var v string
+	var err error
+	if ctx.Args().First() == "" {
+		v, err = selectFromGoMod()
+	} else {
+		v, err = common.GetVersion(ctx, configLocal.Downloads, "go", true)
+		if err == nil {
+			warnGoMod(v)
+		} else if goarch := ctx.String("arch"); goarch != "" {
+			err = fmt.Errorf("%w, run envm go install --arch %s %s", err, goarch, ctx.Args().First())
+		}
 	}
-	// active use
-	_ = os.Remove(configLocal.Symlink)
-	fmt.Println(path.Join(configLocal.Downloads, "go"+v), configLocal.Symlink)
-	if err := os.Symlink(path.Join(configLocal.Downloads, "go"+v), configLocal.Symlink); err != nil {
-		return cli.NewExitError(fmt.Sprintf("%s", err.Error()), 1)
+	if err == nil {
+		err = checkArch(v, ctx.String("arch"))
+	}
+	if err != nil {
+		return common.Exit(err)
+	}
+	if err = Activate(v); err != nil {
+		return common.Exit(err)
 	}
 	output, err := exec.Command("go", "version").Output()
 	if err != nil {
 		return err
 	}
-	fmt.Println(string(output))
+	fmt.Fprintln(util.Output, string(output))
 	return nil
 }
 
-// CommandListRemote 获取远程的可下载的版本
-func CommandListRemote(ctx *cli.Context) error {
-	versionType := ctx.Args().First()
+// Activate 将 symlink 指向已经安装的版本
+func Activate(v string) error {
+	if !common.IsInstalled(configLocal.Downloads, "go", v) {
+		return errors.New("you have not install it,please install before use")
+	}
+	if err := common.ActiveVersion(configLocal.Downloads, "go"+v, configLocal.Symlink); err != nil {
+		return err
+	}
+	return configureEnv(v)
+}
+
+// installedArch 已安装版本的架构，安装时没有记录的为系统的架构
+func installedArch(version string) string {
+	if goarch := common.ReadMetadata(configLocal.Downloads, "go"+version).Arch; goarch != "" {
+		return goarch
+	}
+	return arch.Native()
+}
 
+// checkArch goarch 与已安装版本的架构不同时返回错误，goarch 为空时不检查
+func checkArch(version, goarch string) error {
+	if installed := installedArch(version); goarch != "" && goarch != installed {
+		return fmt.Errorf("go %s is installed for %s, run envm go uninstall %s and envm go install --arch %s %s", version, installed, version, goarch, version)
+	}
+	return nil
+}
+
+// SecurityReleases 返回发布历史中包含安全修复的版本
+func SecurityReleases() ([]string, error) {
+	page, err := util.FetchContent(advisory.GoReleaseURL)
+	if err != nil {
+		return nil, err
+	}
+	return advisory.ParseGoReleases(page), nil
+}
+
+// ListRemote 返回可以安装的稳定版本
+func ListRemote() ([]string, error) {
+	names := make([]string, 0)
+	err := web_go.EachVersion("", func(name, section string) bool {
+		if section != web_go.SectionStable {
+			return false
+		}
+		names = append(names, name)
+		return true
+	})
+	return names, err
+}
+
+// Releases 返回所有版本及提供安装包的平台，类型为 stable 或 archived
+func Releases() ([]listing.Release, error) {
 	collector, err := web_go.NewCollector("")
 	if err != nil {
-		return cli.NewExitError(fmt.Sprintf("collect version error1 + %v", err), 1)
+		return nil, err
 	}
-	if versionType == "stable" {
-		versions, err := collector.StableVersions()
+	releases := make([]listing.Release, 0)
+	for _, section := range []string{web_go.SectionStable, web_go.SectionArchived} {
+		list := collector.StableVersions
+		if section == web_go.SectionArchived {
+			list = collector.ArchivedVersions
+		}
+		versions, err := list()
 		if err != nil {
-			return cli.NewExitError(fmt.Sprintf("collect version error2 + %v", err), 1)
+			return nil, err
 		}
 		for _, version := range versions {
-			fmt.Println(version.Name)
+			releases = append(releases, listing.Release{Version: version.Name, Kind: section, Platforms: listing.Platforms(version.Packages)})
 		}
-		return nil
 	}
-	if versionType == "archived" {
-		versions, err := collector.ArchivedVersions()
-		if err != nil {
+	return releases, nil
+}
 
-			return err
+// CommandListRemote 获取远程的可下载的版本，位置参数 stable|archived 同 --kind
+// 不按平台过滤且不排序时边读取页面边输出，取够 --limit 个后不再读取
+func CommandListRemote(ctx *cli.Context) error {
+	versionType := ctx.Args().First()
+	if versionType != "" && versionType != web_go.SectionStable && versionType != web_go.SectionArchived {
+		return cli.ShowSubcommandHelp(ctx)
+	}
+	f, err := common.ReleaseFilter(ctx, versionType)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	if f.Kind != "" && f.Kind != web_go.SectionStable && f.Kind != web_go.SectionArchived {
+		return cli.NewExitError(fmt.Sprintf("unknown kind %s, kinds: %s, %s", f.Kind, web_go.SectionStable, web_go.SectionArchived), 1)
+	}
+	if f.OS != "" || f.Arch != "" || f.Sort != "" {
+		releases, err := Releases()
+		if err != nil {
+			return common.Exit(fmt.Errorf("collect version error + %w", err))
 		}
-		for _, version := range versions {
-			fmt.Println(version.Name)
+		for _, r := range listing.Apply(releases, f) {
+			common.PrintRelease(r)
 		}
 		return nil
 	}
-
-	return cli.ShowSubcommandHelp(ctx)
+	printed := 0
+	err = web_go.EachVersion("", func(name, section string) bool {
+		if !f.Match(listing.Release{Version: name, Kind: section}) {
+			// 页面中稳定版本在归档版本之前
+			return section == web_go.SectionStable
+		}
+		fmt.Fprintln(util.Output, name)
+		printed++
+		return f.Limit <= 0 || printed < f.Limit
+	})
+	if err != nil {
+		return common.Exit(fmt.Errorf("collect version error + %w", err))
+	}
+	return nil
 }
 
 // CommandListInstalled 展示已经安装的go 版本
@@ -149,14 +288,18 @@ func CommandListInstalled(ctx *cli.Context) {
 		} else {
 			str = str + "    "
 		}
-		str = str + regexp.MustCompile("go").ReplaceAllString(version, "")
+		// envm link 登记的名称中可能包含 go(custom-pgo)，不能替换
+		str = str + version
+		if goarch := installedArch(version); goarch != arch.Native() {
+			str = str + " (" + goarch + ")"
+		}
 		if in == goVersion {
 			str = str + " (Currently using " + in + " executable)"
 		}
-		fmt.Printf(str + "\n")
+		fmt.Fprintf(util.Output, str+"\n")
 
 	}
 	if len(v) == 0 {
-		fmt.Println("No installations recognized.")
+		fmt.Fprintln(util.Output, "No installations recognized.")
 	}
 }
@@ -0,0 +1,56 @@
+package commands_go
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/util"
+)
+
+// gopath 版本独立的 GOPATH，<gopath_root>/<version>
+func gopath(version string) string {
+	return filepath.Join(config.GopathRoot(), version)
+}
+
+// ToolsBin envm go tools 为版本安装的工具所在的目录，与 GOBIN 无关，切换版本时通过 shims 使用对应的构建
+func ToolsBin(version string) string {
+	return filepath.Join(config.GoToolsRoot(), version, "bin")
+}
+
+// Env 返回版本需要的环境变量，开启 per_version_gopath 时包含独立的 GOPATH/GOBIN
+// GOROOT 由调用方根据安装目录设置
+func Env(version string) map[string]string {
+	if !config.Default().Settings.Go.PerVersionGopath {
+		return nil
+	}
+	return map[string]string{
+		"GOPATH": gopath(version),
+		"GOBIN":  filepath.Join(gopath(version), "bin"),
+	}
+}
+
+// configureEnv 激活后通过 go env -w 写入独立的 GOPATH/GOBIN，新打开的终端不需要额外配置
+// 同时提示与 symlink 不一致的 GOROOT 环境变量，否则切换不会生效
+func configureEnv(version string) error {
+	if goroot := os.Getenv("GOROOT"); goroot != "" && filepath.Clean(goroot) != configLocal.Symlink {
+		fmt.Fprintf(util.Output, "warning: GOROOT=%s overrides the linked version, unset it or set it to %s\n", goroot, configLocal.Symlink)
+	}
+	env := Env(version)
+	// portable 模式下不修改全局的 go env，由 envm env/exec 设置
+	if env == nil || config.Default().Settings.Portable {
+		return nil
+	}
+	if err := os.MkdirAll(env["GOBIN"], os.ModePerm); err != nil {
+		return err
+	}
+	binary := filepath.Join(configLocal.Downloads, "go"+version, "bin", "go")
+	output, err := exec.Command(binary, "env", "-w", "GOPATH="+env["GOPATH"], "GOBIN="+env["GOBIN"]).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("go env -w error + %w %s", err, output)
+	}
+	fmt.Fprintf(util.Output, "GOPATH=%s\n", env["GOPATH"])
+	return nil
+}
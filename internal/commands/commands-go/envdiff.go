@@ -0,0 +1,147 @@
+package commands_go
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/goenv"
+	"github.com/FirewineXie/envm/internal/logic/normalize"
+	"github.com/FirewineXie/envm/internal/logic/tabular"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// snapshot 一个 go 版本的环境、支持的平台及自带的工具
+type snapshot struct {
+	env   map[string]string
+	ports []string
+	tools []string
+}
+
+// CommandEnvDiff 比较两个已经安装的版本的 go env、go tool dist list 及 GOTOOLDIR 中的工具，只列出不同的部分
+// 只指定一个版本时与当前激活的版本比较
+func CommandEnvDiff(ctx *cli.Context) error {
+	args := ctx.Args()
+	switch len(args) {
+	case 1:
+		current := common.GetLinkedVersion(configLocal.Symlink, "go")
+		if current == "" {
+			return cli.NewExitError("no go version is active, use envm go env-diff <version> <version>", 1)
+		}
+		args = append([]string{current}, args...)
+	case 2:
+	default:
+		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
+	}
+	versions := make([]string, 2)
+	snapshots := make([]snapshot, 2)
+	for i, input := range args {
+		version, err := installedVersion(input)
+		if err != nil {
+			return common.Exit(err)
+		}
+		if snapshots[i], err = takeSnapshot(filepath.Join(configLocal.Downloads, "go"+version)); err != nil {
+			return common.Exit(fmt.Errorf("go %s: %w", version, err))
+		}
+		versions[i] = version
+	}
+
+	changes := goenv.Diff(snapshots[0].env, snapshots[1].env)
+	if len(changes) == 0 {
+		fmt.Println("environment: same")
+	} else {
+		rows := [][]string{{"KEY", versions[0], versions[1]}}
+		for _, change := range changes {
+			rows = append(rows, []string{change.Key, change.A, change.B})
+		}
+		fmt.Println("environment")
+		if err := tabular.Render(os.Stdout, rows, util.TerminalWidth()); err != nil {
+			return err
+		}
+	}
+	printOnly("ports", versions, snapshots[0].ports, snapshots[1].ports)
+	printOnly("tools", versions, snapshots[0].tools, snapshots[1].tools)
+	return nil
+}
+
+// installedVersion 将 input 解析为已经安装的版本，部分版本号(1.22)选择最新的匹配版本，envm link 登记的名称原样使用
+func installedVersion(input string) (string, error) {
+	installed := common.GetInstalled(configLocal.Downloads, "go")
+	if common.IsInstalled(configLocal.Downloads, "go", input) {
+		return input, nil
+	}
+	version, err := normalize.Clean(config.GO, input)
+	if err != nil {
+		return "", err
+	}
+	if resolved, ok := normalize.Resolve(version, installed); ok {
+		return resolved, nil
+	}
+	return "", fmt.Errorf("go %s is not installed, installed versions: %s", version, strings.Join(installed, ", "))
+}
+
+// takeSnapshot 执行 dir 中的 go 得到环境及支持的平台，不受当前 GOROOT 及 go.mod 中 toolchain 的影响
+func takeSnapshot(dir string) (snapshot, error) {
+	binary := filepath.Join(dir, "bin", "go")
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+	environ := make([]string, 0, len(os.Environ())+1)
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, "GOROOT=") && !strings.HasPrefix(kv, "GOTOOLCHAIN=") {
+			environ = append(environ, kv)
+		}
+	}
+	environ = append(environ, "GOTOOLCHAIN=local")
+	run := func(args ...string) ([]byte, error) {
+		cmd := exec.Command(binary, args...)
+		cmd.Env = environ
+		output, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("%s %s error + %w", binary, strings.Join(args, " "), err)
+		}
+		return output, nil
+	}
+
+	output, err := run("env", "-json")
+	if err != nil {
+		return snapshot{}, err
+	}
+	env, err := goenv.ParseEnv(output)
+	if err != nil {
+		return snapshot{}, fmt.Errorf("parse go env error + %w", err)
+	}
+	output, err = run("tool", "dist", "list")
+	if err != nil {
+		return snapshot{}, err
+	}
+	tools := make([]string, 0)
+	if entries, err := os.ReadDir(env["GOTOOLDIR"]); err == nil {
+		for _, entry := range entries {
+			tools = append(tools, strings.TrimSuffix(entry.Name(), ".exe"))
+		}
+	}
+	return snapshot{env: env, ports: goenv.ParseList(string(output)), tools: tools}, nil
+}
+
+// printOnly 输出只在其中一个版本中存在的项
+func printOnly(title string, versions []string, a, b []string) {
+	onlyA, onlyB := goenv.Compare(a, b)
+	if len(onlyA) == 0 && len(onlyB) == 0 {
+		fmt.Printf("\n%s: same\n", title)
+		return
+	}
+	fmt.Printf("\n%s\n", title)
+	for _, item := range onlyA {
+		fmt.Printf("  - %s (only in %s)\n", item, versions[0])
+	}
+	for _, item := range onlyB {
+		fmt.Printf("  + %s (only in %s)\n", item, versions[1])
+	}
+}
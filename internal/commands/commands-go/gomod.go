@@ -0,0 +1,66 @@
+package commands_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/logic/gomod"
+	"github.com/FirewineXie/envm/util"
+)
+
+// findGoMod 从当前目录向上查找 go.mod
+func findGoMod() (string, gomod.Requirement, bool) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", gomod.Requirement{}, false
+	}
+	filename, ok := util.FindUpward(wd, gomod.FileName)
+	if !ok {
+		return "", gomod.Requirement{}, false
+	}
+	req, err := gomod.Read(filename)
+	return filename, req, err == nil && req.Go != ""
+}
+
+// selectFromGoMod 按 go 工具链的规则选择版本:
+// toolchain 指定的版本已安装时直接使用，其次当前版本满足 go 指令时保持不变，再次使用已安装中满足要求的最新版本，
+// 都不满足时询问是否安装推荐的版本
+func selectFromGoMod() (string, error) {
+	filename, req, ok := findGoMod()
+	if !ok {
+		return "", errors.New("please specify a version, or run it in a go module directory")
+	}
+	installed := common.GetInstalled(configLocal.Downloads, "go")
+	if req.Toolchain != "" && common.IsInstalled(configLocal.Downloads, "go", req.Toolchain) {
+		return req.Toolchain, nil
+	}
+	if current := common.GetLinkedVersion(configLocal.Symlink, "go"); current != "" && req.Satisfied(current) {
+		return current, nil
+	}
+	// 已安装版本按倒序排列
+	for _, version := range installed {
+		if req.Satisfied(version) {
+			return version, nil
+		}
+	}
+
+	preferred := req.Preferred()
+	if !util.Confirm(fmt.Sprintf("%s requires go %s, install go %s?", filename, req.Go, preferred)) {
+		return "", fmt.Errorf("no installed version satisfies go %s", req.Go)
+	}
+	if err := Install(context.Background(), preferred); err != nil {
+		return "", err
+	}
+	return preferred, nil
+}
+
+// warnGoMod 指定的版本低于当前模块的 go 指令时给出提示
+func warnGoMod(version string) {
+	filename, req, ok := findGoMod()
+	if ok && !req.Satisfied(version) {
+		fmt.Fprintf(util.Output, "warning: %s requires go %s, go %s may switch toolchains or fail to build\n", filename, req.Go, version)
+	}
+}
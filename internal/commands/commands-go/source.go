@@ -0,0 +1,121 @@
+package commands_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// CommandBuildFromSource 下载源码包并使用已有的 go 编译，用于没有官方二进制包的平台(部分 BSD、新的架构)
+// 编译结果安装到 <downloads>/go<version>，之后与其它版本一样通过 active 使用
+func CommandBuildFromSource(ctx *cli.Context) error {
+	version := ctx.Args().First()
+	if version == "" {
+		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
+	}
+	bootstrap, err := bootstrapRoot(ctx.String("bootstrap"))
+	if err != nil {
+		return common.Exit(err)
+	}
+	if err = BuildFromSource(context.Background(), version, bootstrap); err != nil {
+		return common.Exit(err)
+	}
+	fmt.Fprintln(util.Output, "Installed successfully")
+	return nil
+}
+
+// bootstrapRoot 编译使用的 go，依次为 --bootstrap、GOROOT_BOOTSTRAP、当前激活的版本、PATH 中的 go
+func bootstrapRoot(flag string) (string, error) {
+	if flag != "" {
+		return flag, nil
+	}
+	if root := os.Getenv("GOROOT_BOOTSTRAP"); root != "" {
+		return root, nil
+	}
+	if current := common.GetLinkedVersion(configLocal.Symlink, "go"); current != "" {
+		return filepath.Join(configLocal.Downloads, "go"+current), nil
+	}
+	if output, err := exec.Command("go", "env", "GOROOT").Output(); err == nil {
+		if root := strings.TrimSpace(string(output)); root != "" {
+			return root, nil
+		}
+	}
+	return "", errors.New("no go toolchain to bootstrap the build, pass --bootstrap <GOROOT> or set GOROOT_BOOTSTRAP")
+}
+
+// BuildFromSource 下载并校验源码包，解压到 go<version> 后执行 src/make.bash(windows 为 make.bat)
+// 编译失败或被中断时删除该目录，不留下不完整的版本
+func BuildFromSource(ctx context.Context, versionS, bootstrap string) error {
+	release, err := common.Reserve(configLocal.Downloads, "go"+versionS)
+	if err != nil {
+		return err
+	}
+	defer release()
+	if common.IsInstalled(configLocal.Downloads, "go", versionS) {
+		fmt.Fprintln(util.Output, "this version is downloaded")
+		return nil
+	}
+	version, err := findVersion(versionS)
+	if err != nil {
+		return err
+	}
+	source, err := version.SourcePackage()
+	if err != nil {
+		return fmt.Errorf("find source of go %s error + %w", versionS, err)
+	}
+	downloadPath := filepath.Clean(filepath.Join(configLocal.Downloads, source.FileName))
+	err = common.FromMirrors(ctx, config.GO, "", source, func() error {
+		return source.DownloadContext(ctx, downloadPath)
+	})
+	if err != nil {
+		return fmt.Errorf("download source error + %w", err)
+	}
+	defer util.RemoveDownloaded(downloadPath)()
+	if err = source.Verify(downloadPath); err != nil {
+		return fmt.Errorf("verify source error + %w", err)
+	}
+	common.PinChecksum(source, downloadPath)
+
+	target := filepath.Join(configLocal.Downloads, "go"+versionS)
+	if err = common.ExtractDir(downloadPath, "go", target); err != nil {
+		return err
+	}
+	defer util.OnInterrupt(func() { _ = os.RemoveAll(target) })()
+	if err = build(ctx, target, bootstrap); err != nil {
+		_ = os.RemoveAll(target)
+		return fmt.Errorf("build go %s with %s error + %w", versionS, bootstrap, err)
+	}
+	common.RecordInstall(configLocal.Downloads, "go"+versionS, source)
+	return nil
+}
+
+// build 在 <root>/src 中执行 make 脚本，输出直接展示编译过程
+func build(ctx context.Context, root, bootstrap string) error {
+	cmd := exec.CommandContext(ctx, "./make.bash")
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", "make.bat")
+	}
+	cmd.Dir = filepath.Join(root, "src")
+	// 编译结果不受当前环境中 GOROOT、GOOS 等变量的影响
+	env := make([]string, 0, len(os.Environ())+1)
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, "GOROOT=") && !strings.HasPrefix(kv, "GOOS=") && !strings.HasPrefix(kv, "GOARCH=") &&
+			!strings.HasPrefix(kv, "GOROOT_BOOTSTRAP=") && !strings.HasPrefix(kv, "GOTOOLCHAIN=") {
+			env = append(env, kv)
+		}
+	}
+	cmd.Env = append(env, "GOROOT_BOOTSTRAP="+bootstrap, "GOTOOLCHAIN=local")
+	cmd.Stdout, cmd.Stderr = util.Output, util.Output
+	fmt.Fprintf(util.Output, "==> building in %s with GOROOT_BOOTSTRAP=%s\n", cmd.Dir, bootstrap)
+	return cmd.Run()
+}
@@ -0,0 +1,127 @@
+package commands_gotools
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/commands/commands-go"
+	"github.com/FirewineXie/envm/internal/commands/commands-shim"
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/gotools"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// CommandInstall 使用选择的 go 版本(--go 或 shell > 项目 > 全局)通过 go install 构建工具，安装到该版本单独的目录
+// 不指定工具时安装 gotools.Catalog 中的全部工具；安装后重新生成 shims，切换 go 版本时 shims 执行对应版本构建的工具
+func CommandInstall(ctx *cli.Context) error {
+	version, err := goVersion(ctx.String("go"))
+	if err != nil {
+		return common.Exit(err)
+	}
+	tools := gotools.Catalog
+	if ctx.NArg() > 0 {
+		tools = make([]gotools.Tool, 0, ctx.NArg())
+		for _, arg := range ctx.Args() {
+			tool, err := gotools.Parse(arg)
+			if err != nil {
+				return common.Exit(err)
+			}
+			tools = append(tools, tool)
+		}
+	}
+	language := languages.Find(config.GO)
+	goBinary, ok := commands_shim.FindBinary(language.BinDir(version), "go")
+	if !ok {
+		return cli.NewExitError(fmt.Sprintf("go is not found in %s", language.BinDir(version)), 1)
+	}
+	bin := commands_go.ToolsBin(version)
+	if err = os.MkdirAll(bin, os.ModePerm); err != nil {
+		return common.Exit(err)
+	}
+
+	failed := make([]string, 0)
+	for _, tool := range tools {
+		fmt.Fprintf(util.Output, "installing %s with go %s\n", tool.Target(), version)
+		cmd := exec.Command(goBinary, "install", tool.Target())
+		cmd.Env = environ(language, version, bin)
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		if err = cmd.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "install %s error + %v\n", tool.Name, err)
+			failed = append(failed, tool.Name)
+		}
+	}
+	if _, err = commands_shim.Rehash(); err != nil {
+		return common.Exit(fmt.Errorf("rehash error + %w", err))
+	}
+	if len(failed) > 0 {
+		return cli.NewExitError(fmt.Sprintf("%s failed to install with go %s, older go versions may need an older tool version such as gopls@v0.14.2", strings.Join(failed, ", "), version), 1)
+	}
+	fmt.Fprintf(util.Output, "installed into %s, add %s to PATH to use the build matching the selected go\n", bin, config.LanguageShimsDir(config.GO))
+	return nil
+}
+
+// CommandList 列出每个已安装的 go 版本构建的工具，* 为当前目录选择的版本
+func CommandList(ctx *cli.Context) error {
+	language := languages.Find(config.GO)
+	wd, _ := os.Getwd()
+	selected, _ := language.Selected(wd)
+	for _, version := range common.GetInstalled(language.Link().Downloads, language.Prefix) {
+		mark := "  "
+		if version == selected {
+			mark = "* "
+		}
+		names := commands_shim.Executables(commands_go.ToolsBin(version))
+		if len(names) == 0 {
+			fmt.Printf("%s%s: no tools\n", mark, version)
+			continue
+		}
+		fmt.Printf("%s%s: %s\n", mark, version, strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// goVersion input 为空时使用当前目录选择的版本，只能是已经安装的版本
+func goVersion(input string) (string, error) {
+	language := languages.Find(config.GO)
+	if input == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+		version, _ := language.Selected(wd)
+		if version == "" {
+			return "", fmt.Errorf("no go version selected, use envm go active or --go <version>")
+		}
+		input = version
+	}
+	version := language.InstalledVersion(input)
+	if !common.IsInstalled(language.Link().Downloads, language.Prefix, version) {
+		return "", fmt.Errorf("go %s is not installed", input)
+	}
+	return version, nil
+}
+
+// environ go install 的环境变量，GOTOOLCHAIN=local 保证工具由该版本构建，而不是 go.mod 要求的其它版本
+func environ(language *languages.Language, version, bin string) []string {
+	env := language.Environ(version)
+	env["GOBIN"] = bin
+	env["GOTOOLCHAIN"] = "local"
+	env["PATH"] = language.BinDir(version) + string(os.PathListSeparator) + os.Getenv("PATH")
+	environ := make([]string, 0, len(os.Environ())+len(env))
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if _, ok := env[name]; !ok {
+			environ = append(environ, kv)
+		}
+	}
+	for name, value := range env {
+		environ = append(environ, name+"="+value)
+	}
+	return environ
+}
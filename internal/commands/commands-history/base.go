@@ -0,0 +1,100 @@
+package commands_history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/history"
+	"github.com/FirewineXie/envm/internal/logic/tabular"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// CommandHistory 展示审计日志中的安装、卸载、切换记录，可以在参数中指定语言或工具名
+// 默认只展示最近的 --limit 条，--json 时每行输出一条完整的记录，--wide 时不按终端宽度截断
+func CommandHistory(ctx *cli.Context) error {
+	filter := history.Filter{Language: ctx.Args().First(), Action: ctx.String("action")}
+	switch filter.Action {
+	case "", history.ActionInstall, history.ActionUninstall, history.ActionActivate:
+	default:
+		return cli.NewExitError(fmt.Sprintf("unknown action %q, use install, uninstall or activate", filter.Action), 1)
+	}
+	if since := ctx.Duration("since"); since > 0 {
+		filter.Since = time.Now().Add(-since)
+	}
+	records, err := history.Read(config.HistoryFile(), filter)
+	if err != nil {
+		return common.Exit(fmt.Errorf("read history error + %w", err))
+	}
+	if limit := ctx.Int("limit"); limit > 0 && len(records) > limit {
+		records = records[len(records)-limit:]
+	}
+
+	if ctx.Bool("json") {
+		encoder := json.NewEncoder(os.Stdout)
+		for _, record := range records {
+			if err = encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(records) == 0 {
+		fmt.Println("No history recorded.")
+		return nil
+	}
+	rows := make([][]string, len(records))
+	for i, record := range records {
+		rows[i] = []string{record.Time.Local().Format("2006-01-02 15:04:05"), record.User, record.Action,
+			record.Language, record.Version, record.Source, shorten(record.Checksum)}
+	}
+	// 下载地址较长，按终端宽度截断中间部分，--wide 输出完整的值
+	width := util.TerminalWidth()
+	if ctx.Bool("wide") {
+		width = 0
+	}
+	return tabular.Render(os.Stdout, rows, width)
+}
+
+// CommandLast 按语言展示最近使用、上一次使用(envm <lang> active - 切换到的版本)及最近安装的版本，可以在参数中指定语言
+func CommandLast(ctx *cli.Context) error {
+	records, err := history.Read(config.HistoryFile(), history.Filter{Language: ctx.Args().First()})
+	if err != nil {
+		return common.Exit(fmt.Errorf("read history error + %w", err))
+	}
+	used, installed := history.Latest(records, history.ActionActivate), history.Latest(records, history.ActionInstall)
+	rows := [][]string{{"LANGUAGE", "USED", "PREVIOUS", "INSTALLED"}}
+	for _, name := range config.Languages {
+		if _, ok := used[name]; !ok {
+			if _, ok = installed[name]; !ok {
+				continue
+			}
+		}
+		rows = append(rows, []string{name, when(used[name]), history.Previous(records, name, used[name].Version), when(installed[name])})
+	}
+	if len(rows) == 1 {
+		fmt.Println("No history recorded.")
+		return nil
+	}
+	return tabular.Render(os.Stdout, rows, util.TerminalWidth())
+}
+
+// when 版本及记录的时间，没有记录时返回空
+func when(record history.Record) string {
+	if record.Version == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s)", record.Version, record.Time.Local().Format("2006-01-02 15:04"))
+}
+
+// shorten 校验和只展示前 12 位，完整的值使用 --json 查看
+func shorten(checksum string) string {
+	if len(checksum) > 12 {
+		return checksum[:12]
+	}
+	return checksum
+}
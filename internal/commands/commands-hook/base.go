@@ -0,0 +1,216 @@
+package commands_hook
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/commands/commands-daemon"
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/shell"
+	"github.com/FirewineXie/envm/internal/logic/wsl"
+	"github.com/urfave/cli"
+)
+
+const (
+	// envHookPath 上一次加入 PATH 的目录，切换目录时先移除
+	envHookPath = "ENVM_HOOK_PATH"
+	// envHookKey 上一次生效的版本，没有变化时不输出任何内容
+	envHookKey = "ENVM_HOOK_KEY"
+	// envHookVars 上一次设置的 GOROOT/JAVA_HOME 等变量名，离开项目时删除
+	envHookVars = "ENVM_HOOK_VARS"
+)
+
+// scripts 各个 shell 的 hook，每次提示符都执行 envm hook-env，编辑版本文件、envm use 或切换全局版本后不需要 cd 即可生效
+// 版本没有变化时 hook-env 根据 ENVM_HOOK_KEY 直接返回，不输出任何内容；envm daemon 运行时不再逐级读取版本文件
+var scripts = map[string]string{
+	shell.Bash: `_envm_hook() {
+  eval "$(envm hook-env bash)"
+}
+case ";${PROMPT_COMMAND:-};" in
+  *";_envm_hook;"*) ;;
+  *) PROMPT_COMMAND="_envm_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}" ;;
+esac
+`,
+	shell.Zsh: `_envm_hook() {
+  eval "$(envm hook-env zsh)"
+}
+autoload -Uz add-zsh-hook
+add-zsh-hook precmd _envm_hook
+`,
+	shell.Fish: `function _envm_hook --on-event fish_prompt
+  envm hook-env fish | source
+end
+`,
+	shell.PowerShell: `$global:_EnvmPrompt = $function:prompt
+function global:prompt {
+  envm hook-env powershell | Out-String | Invoke-Expression
+  & $global:_EnvmPrompt
+}
+`,
+}
+
+// CommandHook 输出 shell hook，使用方式如 eval "$(envm hook bash)"
+func CommandHook(ctx *cli.Context) error {
+	sh := ctx.Args().First()
+	if sh == shell.Cmd {
+		return cli.NewExitError("cmd.exe has no prompt hook, run envm env --output activate.cmd cmd && call activate.cmd instead", 1)
+	}
+	if !shell.Supported(sh) {
+		return cli.NewExitError("supported shells: "+strings.Join(shell.Shells, ", "), 1)
+	}
+	fmt.Print(scripts[sh])
+	return nil
+}
+
+// CommandHookEnv 根据当前目录的项目版本文件输出需要修改的环境变量，由 hook 调用
+func CommandHookEnv(ctx *cli.Context) error {
+	sh := ctx.Args().First()
+	if !shell.Supported(sh) {
+		sh = shell.Bash
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	dirs := make([]string, 0)
+	keys := make([]string, 0)
+	env := map[string]string{}
+	// envm daemon 运行时直接使用其缓存的结果，不需要逐级读取版本文件
+	resolved, ok := commands_daemon.Resolve(wd)
+	if !ok {
+		resolved = languages.Resolve(wd)
+	}
+	// 提示在版本变化时才输出，hook 每次提示符都会执行
+	missing := make([]string, 0)
+	restore := languages.RedirectInstallOutput()
+	// 上一次提示符已经安装失败的版本不再重试，直到版本变化或执行 envm sync
+	failed := map[string]bool{}
+	for _, key := range strings.Split(os.Getenv(envHookKey), ",") {
+		if strings.HasSuffix(key, "!") {
+			failed[key] = true
+		}
+	}
+	for _, item := range resolved {
+		version, installed := item.Version, common.IsInstalled(item.Language.Link().Downloads, item.Language.Prefix, item.Version)
+		if !installed && !failed[item.Language.Name+"@"+item.Version+"!"] {
+			version, installed = item.Language.AutoInstall(item.Version)
+		}
+		if !installed {
+			keys = append(keys, item.Language.Name+"@"+item.Version+"!")
+			missing = append(missing, fmt.Sprintf("envm: %s %s from %s is not installed, run envm sync or set auto_install in config.toml", item.Language.Name, item.Requested, item.File))
+			continue
+		}
+		keys = append(keys, item.Language.Name+"@"+version)
+		dirs = append(dirs, item.Language.BinDir(version))
+		for name, value := range item.Language.Environ(version) {
+			env[name] = value
+		}
+	}
+	restore()
+	globalKeys, globalVars := globalEnv(resolved)
+	keys = append(keys, globalKeys...)
+	for name, value := range globalVars {
+		if _, ok := env[name]; !ok {
+			env[name] = value
+		}
+	}
+	// 全局 symlink 损坏也记录在 key 中，只在状态变化时提示一次
+	broken := brokenLinks(resolved)
+	for _, language := range broken {
+		keys = append(keys, language.Name+"~")
+	}
+	key := strings.Join(keys, ",")
+	if key == os.Getenv(envHookKey) {
+		return nil
+	}
+	// 只在进入项目、版本变化时记录，不在每次提示符刷新时写文件
+	languages.Remember(resolved)
+	for _, message := range missing {
+		fmt.Fprintln(os.Stderr, message)
+	}
+	for _, language := range broken {
+		problem, _ := common.LinkProblem(language.Link().Symlink)
+		fmt.Fprintf(os.Stderr, "envm: %s symlink %s %s, run envm current to repair\n", language.Name, language.Link().Symlink, problem)
+	}
+
+	previous := filepath.SplitList(os.Getenv(envHookPath))
+	fmt.Println(shell.Export(sh, "PATH", shell.PrependPath(wsl.Path(os.Getenv("PATH")), previous, dirs)))
+	for _, name := range strings.Fields(os.Getenv(envHookVars)) {
+		if _, ok := env[name]; !ok {
+			fmt.Println(shell.Unset(sh, name))
+		}
+	}
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Println(shell.Export(sh, name, env[name]))
+	}
+	if len(dirs) == 0 && len(env) == 0 {
+		fmt.Println(shell.Unset(sh, envHookPath))
+		fmt.Println(shell.Unset(sh, envHookVars))
+		if key == "" {
+			fmt.Println(shell.Unset(sh, envHookKey))
+		} else {
+			fmt.Println(shell.Export(sh, envHookKey, key))
+		}
+		return nil
+	}
+	fmt.Println(shell.Export(sh, envHookPath, strings.Join(dirs, string(os.PathListSeparator))))
+	fmt.Println(shell.Export(sh, envHookKey, key))
+	fmt.Println(shell.Export(sh, envHookVars, strings.Join(names, " ")))
+	return nil
+}
+
+// globalEnv 项目中没有声明的语言使用全局版本，返回配置了 version_env 的全局版本及其环境变量
+// 全局版本记录在 key 中，hook 每次提示符都执行 hook-env，envm use 切换后下一次提示符即更新
+func globalEnv(pinned []languages.Resolved) (keys []string, env map[string]string) {
+	env = map[string]string{}
+	if len(config.Default().Settings.VersionEnv) == 0 {
+		return nil, env
+	}
+	skip := map[string]bool{}
+	for _, item := range pinned {
+		skip[item.Language.Name] = true
+	}
+	for _, language := range languages.All() {
+		if skip[language.Name] {
+			continue
+		}
+		version := language.CurrentVersion()
+		if version == "" {
+			continue
+		}
+		vars := config.VersionEnv(language.Name, version)
+		if len(vars) == 0 {
+			continue
+		}
+		keys = append(keys, language.Name+"@"+version+"^")
+		for name, value := range vars {
+			env[name] = value
+		}
+	}
+	return keys, env
+}
+
+// brokenLinks 项目中没有声明的语言使用全局的 symlink，返回 symlink 被删除、被改变或指向的目录已经被删除的语言
+func brokenLinks(pinned []languages.Resolved) (broken []*languages.Language) {
+	skip := map[string]bool{}
+	for _, item := range pinned {
+		skip[item.Language.Name] = true
+	}
+	for _, language := range languages.All() {
+		if problem, _ := common.LinkProblem(language.Link().Symlink); !skip[language.Name] && problem != "" {
+			broken = append(broken, language)
+		}
+	}
+	return broken
+}
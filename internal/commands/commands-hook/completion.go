@@ -0,0 +1,178 @@
+package commands_hook
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/logic/shell"
+	"github.com/urfave/cli"
+)
+
+// completionShells 支持命令补全的 shell
+var completionShells = []string{shell.Bash, shell.Zsh, shell.Fish, shell.PowerShell}
+
+// completionScripts bash、zsh 及 PowerShell 的补全脚本，都通过 --generate-bash-completion 获取子命令
+var completionScripts = map[string]string{
+	shell.Bash: `_envm_complete() {
+  local cur opts
+  COMPREPLY=()
+  cur="${COMP_WORDS[COMP_CWORD]}"
+  if [[ "$cur" == "-"* ]]; then
+    opts=$(envm "${COMP_WORDS[@]:1:$COMP_CWORD-1}" "$cur" --generate-bash-completion 2>/dev/null)
+  else
+    opts=$(envm "${COMP_WORDS[@]:1:$COMP_CWORD-1}" --generate-bash-completion 2>/dev/null)
+  fi
+  COMPREPLY=($(compgen -W "$opts" -- "$cur"))
+}
+complete -o bashdefault -o default -F _envm_complete envm
+`,
+	shell.Zsh: `_envm() {
+  local -a opts
+  local cur=${words[-1]}
+  if [[ "$cur" == "-"* ]]; then
+    opts=("${(@f)$(envm ${words[@]:1:#words[@]-2} $cur --generate-bash-completion 2>/dev/null)}")
+  else
+    opts=("${(@f)$(envm ${words[@]:1:#words[@]-2} --generate-bash-completion 2>/dev/null)}")
+  fi
+  if [[ "${opts[1]}" != "" ]]; then
+    _describe 'values' opts
+  else
+    _files
+  fi
+}
+(( $+functions[compdef] )) || { autoload -Uz compinit && compinit }
+compdef _envm envm
+`,
+	shell.PowerShell: powershellCompleter,
+}
+
+// powershellCompleter PowerShell 的补全，同时包含在 envm psmodule 生成的模块中
+const powershellCompleter = `Register-ArgumentCompleter -Native -CommandName envm -ScriptBlock {
+  param($wordToComplete, $commandAst, $cursorPosition)
+  $words = @($commandAst.CommandElements | Select-Object -Skip 1 | ForEach-Object { $_.ToString() })
+  if ($wordToComplete) {
+    $words = @($words | Select-Object -SkipLast 1)
+  }
+  envm @words --generate-bash-completion 2>$null | Where-Object { $_ -like "$wordToComplete*" } | ForEach-Object {
+    [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+  }
+}
+`
+
+// completionScript 返回 sh 的补全脚本，fish 由 urfave/cli 根据命令定义生成
+func completionScript(app *cli.App, sh string) (string, error) {
+	if sh == shell.Fish {
+		return app.ToFishCompletion()
+	}
+	script, ok := completionScripts[sh]
+	if !ok {
+		return "", fmt.Errorf("supported shells: %s", strings.Join(completionShells, ", "))
+	}
+	return script, nil
+}
+
+// CommandCompletion 输出命令补全脚本，例如 envm completion fish > ~/.config/fish/completions/envm.fish
+func CommandCompletion(ctx *cli.Context) error {
+	script, err := completionScript(ctx.App, ctx.Args().First())
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	fmt.Print(script)
+	return nil
+}
+
+// CommandCompletionInstall 将补全脚本写入 shell 自动加载的位置，需要时在启动脚本中加入加载语句
+// 没有指定 shell 时根据 $SHELL 判断，windows 上为 PowerShell；重复执行只更新有变化的脚本，不会重复修改启动脚本
+func CommandCompletionInstall(ctx *cli.Context) error {
+	sh := ctx.Args().First()
+	if sh == "" {
+		if sh = shell.Detect(runtime.GOOS, os.Getenv); sh == "" {
+			return cli.NewExitError("can not detect the shell from $SHELL, use envm completion install "+strings.Join(completionShells, "|"), 1)
+		}
+	}
+	script, err := completionScript(ctx.App, sh)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return common.Exit(err)
+	}
+	targets := shell.CompletionTargets(sh, runtime.GOOS, home, os.Getenv)
+	if sh == shell.PowerShell && len(targets) > 1 {
+		targets = existingProfiles(targets)
+	}
+	for _, target := range targets {
+		if err = installCompletion(sh, script, target); err != nil {
+			return common.Exit(err)
+		}
+	}
+	fmt.Printf("%s completion is installed, open a new shell to use it\n", sh)
+	return nil
+}
+
+// existingProfiles 只安装到已经使用过的 PowerShell(profile 目录存在)，都没有时使用系统自带的 Windows PowerShell
+func existingProfiles(targets []shell.CompletionTarget) []shell.CompletionTarget {
+	existing := make([]shell.CompletionTarget, 0, len(targets))
+	for _, target := range targets {
+		if _, err := os.Stat(filepath.Dir(target.RC)); err == nil {
+			existing = append(existing, target)
+		}
+	}
+	if len(existing) == 0 {
+		return targets[len(targets)-1:]
+	}
+	return existing
+}
+
+// installCompletion 写入补全脚本并在启动脚本中加入加载语句
+func installCompletion(sh, script string, target shell.CompletionTarget) error {
+	data := []byte(script)
+	if sh == shell.PowerShell && runtime.GOOS == "windows" {
+		data = []byte(strings.ReplaceAll(script, "\n", "\r\n"))
+	}
+	if err := writeIfChanged(target.File, data); err != nil {
+		return err
+	}
+	if target.RC == "" {
+		return nil
+	}
+	content, err := os.ReadFile(target.RC)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	updated, changed := shell.AppendLine(string(content), shell.SourceLine(sh, target.File))
+	if !changed {
+		fmt.Printf("%s already loads %s\n", target.RC, target.File)
+		return nil
+	}
+	if err = os.MkdirAll(filepath.Dir(target.RC), os.ModePerm); err != nil {
+		return err
+	}
+	if err = os.WriteFile(target.RC, []byte(updated), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("add loading %s to %s\n", target.File, target.RC)
+	return nil
+}
+
+// writeIfChanged 内容相同时不写入，envm 升级后再次执行只更新有变化的脚本
+func writeIfChanged(file string, data []byte) error {
+	if existing, err := os.ReadFile(file); err == nil && bytes.Equal(existing, data) {
+		fmt.Printf("%s is up to date\n", file)
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return err
+	}
+	fmt.Println("write " + file)
+	return nil
+}
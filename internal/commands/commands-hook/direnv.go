@@ -0,0 +1,77 @@
+package commands_hook
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/logic/project"
+	"github.com/FirewineXie/envm/internal/logic/shell"
+	"github.com/urfave/cli"
+)
+
+// direnvScript 放到 ~/.config/direnv/lib/envm.sh，在 .envrc 中使用 use envm 或 use envm go 1.22.3
+const direnvScript = `use_envm() {
+  eval "$(envm direnv-env "$@")"
+}
+`
+
+// CommandDirenv 输出 direnv 使用的 use_envm 函数
+func CommandDirenv(ctx *cli.Context) {
+	fmt.Print(direnvScript)
+}
+
+// CommandDirenvEnv 由 use_envm 调用，参数为 <name> <version> 对，没有参数时使用项目版本文件中的声明
+func CommandDirenvEnv(ctx *cli.Context) error {
+	items, err := direnvItems(ctx.Args())
+	if err != nil {
+		return common.Exit(err)
+	}
+	languages.Remember(items)
+	for _, item := range items {
+		if item.File != "" {
+			fmt.Println("watch_file " + shell.Quote(shell.Bash, item.File))
+		}
+		if !common.IsInstalled(item.Language.Link().Downloads, item.Language.Prefix, item.Version) {
+			fmt.Fprintf(os.Stderr, "envm: %s %s is not installed, run envm sync\n", item.Language.Name, item.Requested)
+			continue
+		}
+		fmt.Println("PATH_add " + shell.Quote(shell.Bash, item.Language.BinDir(item.Version)))
+		env := item.Language.Environ(item.Version)
+		names := make([]string, 0, len(env))
+		for name := range env {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Println(shell.Export(shell.Bash, name, env[name]))
+		}
+	}
+	return nil
+}
+
+func direnvItems(args []string) ([]languages.Resolved, error) {
+	if len(args) == 0 {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		return languages.Resolve(wd), nil
+	}
+	if len(args)%2 != 0 {
+		return nil, errors.New("usage: use envm [<name> <version>]...")
+	}
+	items := make([]languages.Resolved, 0)
+	for i := 0; i < len(args); i += 2 {
+		language := languages.Find(args[i])
+		if language == nil {
+			return nil, fmt.Errorf("unknown language %s", args[i])
+		}
+		pin := project.Pin{Name: args[i], Version: language.InstalledVersion(args[i+1])}
+		items = append(items, languages.Resolved{Language: language, Pin: pin, Requested: args[i+1]})
+	}
+	return items, nil
+}
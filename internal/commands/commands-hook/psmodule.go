@@ -0,0 +1,95 @@
+package commands_hook
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/logic/shell"
+	"github.com/urfave/cli"
+)
+
+// moduleName PowerShell 模块名，在 $PROFILE 中通过 Import-Module envm 导入
+const moduleName = "envm"
+
+// moduleGUID 模块的 GUID，升级时保持不变
+const moduleGUID = "5a0f1c52-7c1e-4c8e-9d6b-2f3e8a4b6c1d"
+
+// moduleScript 模块导入时安装 prompt hook 及命令补全，移除模块时恢复原来的 prompt
+// 补全通过 --generate-bash-completion 获取子命令，与 bash/zsh 一致
+var moduleScript = `if (-not $global:_EnvmPrompt) {
+` + scripts[shell.PowerShell] + `}
+
+$ExecutionContext.SessionState.Module.OnRemove = {
+  if ($global:_EnvmPrompt) {
+    Set-Item function:\global:prompt $global:_EnvmPrompt
+    Remove-Variable _EnvmPrompt -Scope Global
+  }
+}
+
+function Use-EnvmEnv {
+  envm env powershell | Out-String | Invoke-Expression
+}
+
+function Use-EnvmVersion {
+  param(
+    [Parameter(Mandatory)][string]$Language,
+    [Parameter(Mandatory)][string]$Version
+  )
+  envm $Language active --session --shell powershell $Version | Out-String | Invoke-Expression
+}
+
+` + powershellCompleter + `
+Export-ModuleMember -Function Use-EnvmEnv, Use-EnvmVersion
+`
+
+const moduleManifest = `@{
+  RootModule        = '%s.psm1'
+  ModuleVersion     = '%s'
+  GUID              = '%s'
+  Author            = '%s'
+  Description       = 'envm shell integration: prompt hook, completion and envm env'
+  PowerShellVersion = '5.0'
+  FunctionsToExport = @('Use-EnvmEnv', 'Use-EnvmVersion')
+  CmdletsToExport   = @()
+  VariablesToExport = @()
+  AliasesToExport   = @()
+}
+`
+
+// CommandPSModule 将 PowerShell 的 hook、补全及 envm env 封装为模块写入 dir，默认为 PSModulePath 中的第一个目录
+func CommandPSModule(ctx *cli.Context) error {
+	dir := ctx.Args().First()
+	if dir == "" {
+		paths := filepath.SplitList(os.Getenv("PSModulePath"))
+		if len(paths) == 0 || paths[0] == "" {
+			return cli.NewExitError(errors.New("PSModulePath is not set, run it in PowerShell or pass the module directory"), 1)
+		}
+		dir = filepath.Join(paths[0], moduleName)
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return common.Exit(err)
+	}
+
+	author := ""
+	if len(ctx.App.Authors) > 0 {
+		author = ctx.App.Authors[0].Name
+	}
+	version := strings.TrimPrefix(ctx.App.Version, "v")
+	files := map[string]string{
+		moduleName + ".psm1": moduleScript,
+		moduleName + ".psd1": fmt.Sprintf(moduleManifest, moduleName, version, moduleGUID, author),
+	}
+	for name, content := range files {
+		// Windows PowerShell 5 需要 BOM 才能识别 UTF-8
+		data := append([]byte("\xef\xbb\xbf"), []byte(strings.ReplaceAll(content, "\n", "\r\n"))...)
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			return common.Exit(err)
+		}
+	}
+	fmt.Printf("write %s, add Import-Module %s to $PROFILE\n", dir, moduleName)
+	return nil
+}
@@ -1,20 +1,25 @@
 package commands_java
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/FirewineXie/envm/internal/commands/common"
 	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/listing"
 	"github.com/FirewineXie/envm/internal/logic/web-java"
+	"github.com/FirewineXie/envm/util"
 	"github.com/urfave/cli"
-	"os"
 	"os/exec"
-	"path"
 	"path/filepath"
 	"regexp"
 )
 
 var configLocal = config.Default().LinkSetting[config.JAVA]
 
+// prefix jdk 安装目录的前缀
+const prefix = "jdk-"
+
 func CommandUninstall(ctx *cli.Context) error {
 	versionS := ctx.Args().First()
 
@@ -22,34 +27,39 @@ func CommandUninstall(ctx *cli.Context) error {
 	if versionS == version {
 		return cli.NewExitError("不能卸载当前版本", 1)
 	}
-	err := os.RemoveAll(filepath.Join(configLocal.Downloads, "jdk-"+versionS))
+	err := common.RemoveDir(filepath.Join(configLocal.Downloads, prefix+versionS))
 	if err != nil {
-		return cli.NewExitError("删除该版本失败+"+err.Error(), 1)
+		return common.Exit(fmt.Errorf("删除该版本失败+%w", err))
 	}
-	fmt.Println("finish uninstall")
+	fmt.Fprintln(util.Output, "finish uninstall")
 	return nil
 }
 
 // CommandUse 激活使用
 func CommandUse(ctx *cli.Context) error {
-	v, err := common.GetVersion(ctx, configLocal.Downloads, "go", true)
+	v, err := common.GetVersion(ctx, configLocal.Downloads, prefix, true)
 	if err != nil {
 		return err
 	}
-	// active use
-	_ = os.Remove(configLocal.Symlink)
-	fmt.Println(path.Join(configLocal.Downloads, v), configLocal.Symlink)
-	if err := os.Symlink(path.Join(configLocal.Downloads, v), configLocal.Symlink); err != nil {
-		return cli.NewExitError(fmt.Sprintf("%s", err.Error()), 1)
+	if err = Activate(v); err != nil {
+		return common.Exit(err)
 	}
 	output, err := exec.Command("java", "--version").Output()
 	if err != nil {
 		return err
 	}
-	fmt.Println(string(output))
+	fmt.Fprintln(util.Output, string(output))
 	return nil
 }
 
+// Activate 将 symlink 指向已经安装的版本
+func Activate(v string) error {
+	if !common.IsInstalled(configLocal.Downloads, prefix, v) {
+		return errors.New("you have not install it,please install before use")
+	}
+	return common.ActiveVersion(configLocal.Downloads, prefix+v, configLocal.Symlink)
+}
+
 // CommandListInstalled 展示已经安装
 func CommandListInstalled(ctx *cli.Context) {
 	in := common.GetCurrentVersion("java")
@@ -70,38 +80,56 @@ func CommandListInstalled(ctx *cli.Context) {
 		if in == goVersion {
 			str = str + " (Currently using " + in + " executable)"
 		}
-		fmt.Printf(str + "\n")
+		fmt.Fprintf(util.Output, str+"\n")
 
 	}
 	if len(v) == 0 {
-		fmt.Println("No installations recognized.")
+		fmt.Fprintln(util.Output, "No installations recognized.")
 	}
 }
 
-func CommandListRemote(ctx *cli.Context) error {
-
+// ListRemote 返回最近的几个 LTS 版本
+func ListRemote() ([]string, error) {
 	collector, err := web_java.NewCollector("")
 	if err != nil {
-		return cli.NewExitError(fmt.Sprintf("collect version error1 + %v", err), 1)
+		return nil, err
 	}
-	items, err := collector.LatestFiveVersion()
+	versions, err := collector.LatestFiveVersion()
 	if err != nil {
-		return cli.NewExitError(fmt.Sprintf("collect version error1 + %v", err), 1)
-	} else {
-		for i, version := range items {
-			if i == 20 {
-				break
-			}
-			fmt.Println(version.Name)
-		}
+		return nil, err
+	}
+	names := make([]string, 0, len(versions))
+	for _, version := range versions {
+		names = append(names, version.Name)
+	}
+	return names, nil
+}
 
+// CommandListRemote 获取远程的可下载的版本
+func CommandListRemote(ctx *cli.Context) error {
+	versions, err := ListRemote()
+	if err != nil {
+		return common.Exit(fmt.Errorf("collect version error1 + %w", err))
 	}
-	fmt.Println("detail see website")
+	if err = common.PrintReleases(ctx, listing.FromVersions(versions), ""); err != nil {
+		return err
+	}
+	fmt.Fprintln(util.Output, "detail see website")
 	return nil
 }
 
 func CommandInstall(ctx *cli.Context) error {
-
-	fmt.Println("Installed successfully")
+	if err := Install(context.Background(), ctx.Args().First()); err != nil {
+		return common.Exit(err)
+	}
+	fmt.Fprintln(util.Output, "Installed successfully")
 	return nil
 }
+
+// Install jdk 暂不支持自动安装，需要手动下载解压到 <downloads>/jdk-<version>
+func Install(ctx context.Context, versionS string) error {
+	if common.IsInstalled(configLocal.Downloads, prefix, versionS) {
+		return nil
+	}
+	return fmt.Errorf("java %s is not installed, download it manually into %s", versionS, filepath.Join(configLocal.Downloads, prefix+versionS))
+}
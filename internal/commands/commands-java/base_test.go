@@ -1,6 +1,8 @@
 package commands_java
 
 import (
+	"flag"
+
 	. "github.com/smartystreets/goconvey/convey"
 	"github.com/urfave/cli"
 	"testing"
@@ -22,7 +24,8 @@ func TestCommandListRemote(t *testing.T) {
 
 func TestCommandInstall(t *testing.T) {
 	Convey("测试 标记", t, func() {
-
-		CommandInstall(&cli.Context{})
+		set := flag.NewFlagSet("install", flag.ContinueOnError)
+		_ = set.Parse([]string{"17.0.2"})
+		CommandInstall(cli.NewContext(cli.NewApp(), set, nil))
 	})
 }
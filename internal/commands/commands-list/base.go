@@ -0,0 +1,104 @@
+package commands_list
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/arch"
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/logic/origin"
+	"github.com/FirewineXie/envm/internal/logic/tabular"
+	"github.com/urfave/cli"
+)
+
+// CommandList 按语言分组列出已经安装的版本，可以在参数中指定语言
+// * 为全局默认版本，> 为当前 shell(ENVM_<LANG>_VERSION)或当前项目选择的版本，链接到外部目录的版本标注来源
+// --output 为 tsv/csv 时每行输出 语言、版本、是否为全局版本、选择的来源类型、外部来源、外部目录
+func CommandList(ctx *cli.Context) error {
+	format := ctx.String("output")
+	if err := tabular.Check(format); err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	items := languages.All()
+	if ctx.NArg() > 0 {
+		items = items[:0:0]
+		for _, name := range ctx.Args() {
+			language := languages.Find(name)
+			if language == nil {
+				return cli.NewExitError(name+" is not supported by envm", 1)
+			}
+			items = append(items, language)
+		}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return common.Exit(err)
+	}
+	selected := map[string]languages.Selection{}
+	for _, selection := range languages.Selections(wd) {
+		if selection.Scope != languages.ScopeGlobal {
+			selected[selection.Language.Name] = selection
+		}
+	}
+
+	table := format == "" || format == tabular.Table
+	rows := make([][]string, 0)
+	found := 0
+	for _, language := range items {
+		installed := common.GetInstalled(language.Link().Downloads, language.Prefix)
+		if len(installed) == 0 {
+			continue
+		}
+		found++
+		if table {
+			fmt.Println(language.Name)
+		}
+		current := language.CurrentVersion()
+		selection := selected[language.Name]
+		for _, version := range installed {
+			target := common.External(language.Link().Downloads, language.Prefix+version)
+			label := ""
+			if target != "" {
+				label = origin.Label(target)
+			}
+			scope := ""
+			if selection.Version == version {
+				scope = selection.Scope
+			}
+			if !table {
+				rows = append(rows, []string{language.Name, version, strconv.FormatBool(version == current), scope, label, target})
+				continue
+			}
+			marker, notes := " ", make([]string, 0)
+			if version == current {
+				marker = "*"
+				notes = append(notes, "global")
+			}
+			if scope != "" {
+				marker = ">"
+				notes = append(notes, fmt.Sprintf("%s via %s", scope, selection.Source))
+			}
+			if goarch := common.ReadMetadata(language.Link().Downloads, language.Prefix+version).Arch; goarch != "" && goarch != arch.Native() {
+				notes = append(notes, goarch)
+			}
+			if target != "" {
+				notes = append(notes, fmt.Sprintf("%s, linked to %s", label, target))
+			}
+			line := fmt.Sprintf("  %s %s", marker, version)
+			if len(notes) > 0 {
+				line = fmt.Sprintf("  %s %-12s (%s)", marker, version, strings.Join(notes, "; "))
+			}
+			fmt.Println(line)
+		}
+	}
+	if !table {
+		return tabular.Write(os.Stdout, format, []string{"language", "version", "global", "selected", "origin", "target"}, rows)
+	}
+	if found == 0 {
+		fmt.Println("No installations recognized.")
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package commands_list
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/logic/health"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// CommandLink 将磁盘上任意位置自行编译的工具链登记为一个命名版本，只在安装目录中创建链接，不复制文件
+// envm link go@custom-pgo ~/src/go，省略名称时使用目录名(去掉语言前缀)，之后可以 active、use、exec 该名称
+// uninstall 只删除链接，不会删除外部目录
+func CommandLink(ctx *cli.Context) error {
+	if ctx.NArg() != 2 {
+		return cli.NewExitError("usage: envm link <language>[@name] <path>", 1)
+	}
+	name, version, _ := strings.Cut(ctx.Args().Get(0), "@")
+	language := languages.Find(name)
+	if language == nil {
+		return cli.NewExitError(name+" is not supported by envm", 1)
+	}
+	target, err := filepath.Abs(ctx.Args().Get(1))
+	if err != nil {
+		return common.Exit(err)
+	}
+	if info, err := os.Stat(target); err != nil {
+		return common.Exit(err)
+	} else if !info.IsDir() {
+		return cli.NewExitError(target+" is not a directory", 1)
+	}
+	if version == "" {
+		version = strings.TrimPrefix(filepath.Base(target), language.Prefix)
+	}
+	if version == "" || version == "." || version == ".." || strings.ContainsAny(version, `/\`) {
+		return cli.NewExitError(fmt.Sprintf("invalid name %q, use envm link %s@<name> %s", version, language.Name, ctx.Args().Get(1)), 1)
+	}
+
+	link := language.InstallDir(version)
+	if exists, _ := util.PathExists(link); exists {
+		return cli.NewExitError(fmt.Sprintf("%s %s already exists, uninstall it first or choose another name", language.Name, version), 1)
+	}
+	if err = os.MkdirAll(language.Link().Downloads, os.ModePerm); err != nil {
+		return common.Exit(err)
+	}
+	if err = util.Symlink(target, link); err != nil {
+		return common.Exit(fmt.Errorf("link %s error + %w", target, err))
+	}
+	// 目录中缺少可执行文件时不登记
+	if err = language.Check(version); err != nil {
+		_ = os.Remove(link)
+		var broken *health.BrokenError
+		if errors.As(err, &broken) {
+			return cli.NewExitError(fmt.Sprintf("%s is not a %s toolchain: %s", target, language.Name, broken.Reason), 1)
+		}
+		return common.Exit(err)
+	}
+	common.RecordInstall(language.Link().Downloads, language.Prefix+version, nil)
+	fmt.Fprintf(util.Output, "linked %s %s -> %s\n", language.Name, version, target)
+	fmt.Fprintf(util.Output, "run `envm %s active %s` or pin it in .envmrc to use it\n", language.Name, version)
+	return nil
+}
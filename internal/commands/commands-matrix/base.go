@@ -0,0 +1,198 @@
+package commands_matrix
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/matrix"
+	"github.com/FirewineXie/envm/internal/logic/tabular"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// Flags test-matrix 的参数
+var Flags = []cli.Flag{
+	cli.IntFlag{Name: "parallel, p", Value: 1, Usage: "number of versions tested at the same time, the output of each version is printed when it finishes"},
+	cli.BoolFlag{Name: "fail-fast", Usage: "do not start the remaining versions after the first failure"},
+}
+
+// result 一个版本的执行结果
+type result struct {
+	input   string
+	version string
+	status  string
+	elapsed time.Duration
+	err     error
+}
+
+const (
+	statusPass    = "pass"
+	statusFail    = "fail"
+	statusSkipped = "skipped"
+)
+
+// CommandTestMatrix 返回 envm <language> test-matrix <versions> -- <command> 的 Action
+// 依次确认每个版本已经安装(没有安装时安装)，再通过 envm exec --with 在每个版本下执行命令，最后汇总每个版本的结果
+// 版本可以用逗号分隔或写为范围，见 matrix.Expand；任意版本失败时以 1 退出
+func CommandTestMatrix(name string) func(ctx *cli.Context) error {
+	return func(ctx *cli.Context) error {
+		language := languages.Find(name)
+		if language == nil {
+			return cli.NewExitError(name+" is not supported by envm", 1)
+		}
+		specs, command := split(ctx.Args())
+		if len(specs) == 0 || len(command) == 0 {
+			return cli.ShowCommandHelp(ctx, ctx.Command.Name)
+		}
+		for _, spec := range specs {
+			if strings.HasPrefix(spec, "-") {
+				return cli.NewExitError(fmt.Sprintf("%s must be written before the versions", spec), 1)
+			}
+		}
+		inputs, err := matrix.Expand(specs)
+		if err != nil {
+			return cli.NewExitError(err.Error(), 1)
+		}
+		parallel := ctx.Int("parallel")
+		if parallel < 1 {
+			return cli.NewExitError("--parallel must be at least 1", 1)
+		}
+
+		// 先依次安装，安装的输出不与命令的输出交错
+		results := make([]*result, 0, len(inputs))
+		for _, input := range inputs {
+			r := &result{input: input, version: input}
+			if r.version, r.err = language.EnsureInstalled(input); r.err != nil {
+				r.version, r.status = input, statusFail
+				fmt.Fprintf(os.Stderr, "%s %s: %v\n", language.Name, input, r.err)
+			}
+			results = append(results, r)
+		}
+		run(language.Name, command, results, parallel, ctx.Bool("fail-fast"))
+		return summary(language.Name, results)
+	}
+}
+
+// split 以 -- 分隔版本及命令
+func split(args []string) (specs, command []string) {
+	for i, arg := range args {
+		if arg == "--" {
+			return args[:i], args[i+1:]
+		}
+	}
+	return args, nil
+}
+
+// run 在每个已经安装的版本下执行命令，最多同时执行 parallel 个
+// 只有一个同时执行时直接输出；并行时每个版本的输出先缓存，结束后整段输出，避免交错
+func run(name string, command []string, results []*result, parallel int, failFast bool) {
+	var (
+		mu     sync.Mutex
+		failed bool
+		wg     sync.WaitGroup
+	)
+	slots := make(chan struct{}, parallel)
+	for _, r := range results {
+		if r.status != "" {
+			mu.Lock()
+			failed = true
+			mu.Unlock()
+			continue
+		}
+		slots <- struct{}{}
+		mu.Lock()
+		stop := failFast && failed
+		mu.Unlock()
+		if stop {
+			<-slots
+			r.status = statusSkipped
+			continue
+		}
+		wg.Add(1)
+		go func(r *result) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			var output bytes.Buffer
+			in, out := io.Reader(nil), io.Writer(&output)
+			if parallel == 1 {
+				fmt.Fprintf(util.Output, "==> %s %s\n", name, r.version)
+				in, out = os.Stdin, os.Stdout
+			}
+			started := time.Now()
+			r.err = execute(name, r.version, command, in, out)
+			r.elapsed = time.Since(started)
+			r.status = statusPass
+			if r.err != nil {
+				r.status = statusFail
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if r.err != nil {
+				failed = true
+			}
+			if parallel > 1 {
+				fmt.Fprintf(util.Output, "==> %s %s (%s)\n", name, r.version, r.status)
+				_, _ = util.Output.Write(output.Bytes())
+			}
+		}(r)
+	}
+	wg.Wait()
+}
+
+// execute 通过 envm exec --with <name>@<version> 执行命令，stdout 及 stderr 都写到 out，并行时 in 为空
+// go 设置 GOTOOLCHAIN=local，go.mod 中的 toolchain 不会切换到其它版本
+func execute(name, version string, command []string, in io.Reader, out io.Writer) error {
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	args := append([]string{"exec", "--with", name + "@" + version, "--"}, command...)
+	cmd := exec.Command(self, args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = in, out, out
+	cmd.Env = os.Environ()
+	if name == config.GO {
+		cmd.Env = append(cmd.Env, "GOTOOLCHAIN=local")
+	}
+	return cmd.Run()
+}
+
+// summary 输出每个版本的结果，有失败的版本时返回退出码为 1 的错误
+func summary(name string, results []*result) error {
+	rows := make([][]string, 0, len(results))
+	failed := 0
+	for _, r := range results {
+		duration, detail := "", ""
+		if r.elapsed > 0 {
+			duration = r.elapsed.Round(100 * time.Millisecond).String()
+		}
+		var exitErr *exec.ExitError
+		switch {
+		case errors.As(r.err, &exitErr):
+			detail = fmt.Sprintf("exit code %d", exitErr.ExitCode())
+		case r.err != nil:
+			detail = r.err.Error()
+		}
+		if r.status != statusPass {
+			failed++
+		}
+		rows = append(rows, []string{r.version, r.status, duration, detail})
+	}
+	fmt.Fprintln(util.Output)
+	if err := tabular.Write(util.Output, tabular.Table, []string{strings.ToUpper(name), "RESULT", "TIME", "DETAIL"}, rows); err != nil {
+		return common.Exit(err)
+	}
+	if failed > 0 {
+		return cli.NewExitError(fmt.Sprintf("%d of %d versions did not pass", failed, len(results)), 1)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package commands_maven
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/FirewineXie/envm/internal/arch"
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/listing"
+	"github.com/FirewineXie/envm/internal/logic/web-maven"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+var configLocal = config.Default().LinkSetting[config.MAVEN]
+
+func CommandUninstall(ctx *cli.Context) error {
+	current := common.GetLinkedVersion(configLocal.Symlink, config.MAVEN)
+	if err := common.UninstallVersion(configLocal.Downloads, config.MAVEN, ctx.Args().First(), current); err != nil {
+		return common.Exit(fmt.Errorf("删除该版本失败+%w", err))
+	}
+	fmt.Fprintln(util.Output, "finish uninstall")
+	return nil
+}
+
+// CommandInstall 安装命令，未指定版本时使用 maven wrapper 中配置的版本
+func CommandInstall(ctx *cli.Context) error {
+	versionS := ctx.Args().First()
+	if versionS == "" {
+		url, err := common.ReadWrapperURL(".", web_maven.WrapperProperties)
+		if err != nil {
+			return cli.ShowSubcommandHelp(ctx)
+		}
+		var ok bool
+		if versionS, ok = web_maven.VersionFromURL(url); !ok {
+			return cli.NewExitError("can not parse version of "+url, 1)
+		}
+		fmt.Fprintln(util.Output, "use version "+versionS+" from "+web_maven.WrapperProperties)
+	}
+	if err := Install(context.Background(), versionS); err != nil {
+		return common.Exit(err)
+	}
+	fmt.Fprintln(util.Output, "Installed successfully")
+	return nil
+}
+
+// Install 下载并安装指定版本
+func Install(ctx context.Context, versionS string) error {
+	release, err := common.Reserve(configLocal.Downloads, config.MAVEN+versionS)
+	if err != nil {
+		return err
+	}
+	defer release()
+	if common.IsInstalled(configLocal.Downloads, config.MAVEN, versionS) {
+		fmt.Fprintln(util.Output, "this version is downloaded")
+		return nil
+	}
+	collector, err := web_maven.NewCollector("")
+	if err != nil {
+		return fmt.Errorf("collect version error + %w", err)
+	}
+	version, err := collector.FindVersion(versionS)
+	if err != nil {
+		return err
+	}
+	findPackage, err := common.FindPackage(version.FindPackage, runtime.GOOS, arch.Native(), false)
+	if err != nil {
+		return fmt.Errorf("find version of system error + %w", err)
+	}
+	if err = common.InstallDirArchive(ctx, findPackage, configLocal.Downloads, config.MAVEN, versionS); err != nil {
+		return err
+	}
+	common.RecordInstall(configLocal.Downloads, config.MAVEN+versionS, findPackage)
+	return nil
+}
+
+// CommandWrapper 安装 maven wrapper 需要的版本，并登记到 ~/.m2/wrapper 中供 mvnw 离线使用
+func CommandWrapper(ctx *cli.Context) error {
+	url, err := common.ReadWrapperURL(".", web_maven.WrapperProperties)
+	if err != nil {
+		return common.Exit(err)
+	}
+	versionS, ok := web_maven.VersionFromURL(url)
+	if !ok {
+		return cli.NewExitError("can not parse version of "+url, 1)
+	}
+	if err = Install(context.Background(), versionS); err != nil {
+		return common.Exit(err)
+	}
+	userHome := os.Getenv("MAVEN_USER_HOME")
+	if userHome == "" {
+		home, _ := os.UserHomeDir()
+		userHome = filepath.Join(home, ".m2")
+	}
+	distDir, err := common.SeedWrapper(userHome, url, filepath.Join(configLocal.Downloads, config.MAVEN+versionS), "apache-maven-"+versionS)
+	if err != nil {
+		return common.Exit(err)
+	}
+	fmt.Fprintln(util.Output, "maven wrapper "+versionS+" is ready: "+distDir)
+	return nil
+}
+
+// CommandUse 激活使用
+func CommandUse(ctx *cli.Context) error {
+	v, err := common.GetVersion(ctx, configLocal.Downloads, config.MAVEN, true)
+	if err != nil {
+		return err
+	}
+	if err = Activate(v); err != nil {
+		return common.Exit(err)
+	}
+	fmt.Fprintln(util.Output, "Now using maven "+v)
+	return nil
+}
+
+// Activate 将 symlink 指向已经安装的版本
+func Activate(v string) error {
+	if !common.IsInstalled(configLocal.Downloads, config.MAVEN, v) {
+		return errors.New("you have not install it,please install before use")
+	}
+	return common.ActiveVersion(configLocal.Downloads, config.MAVEN+v, configLocal.Symlink)
+}
+
+// Releases 返回所有版本，maven 与平台无关
+func Releases() ([]listing.Release, error) {
+	versions, err := ListRemote()
+	if err != nil {
+		return nil, err
+	}
+	return listing.FromVersions(versions), nil
+}
+
+// CommandListRemote 获取远程的可下载的版本
+func CommandListRemote(ctx *cli.Context) error {
+	releases, err := Releases()
+	if err != nil {
+		return common.Exit(fmt.Errorf("collect version error + %w", err))
+	}
+	return common.PrintReleases(ctx, releases, "")
+}
+
+// ListRemote 返回所有可以安装的版本
+func ListRemote() ([]string, error) {
+	collector, err := web_maven.NewCollector("")
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0)
+	for _, version := range collector.AllVersions() {
+		names = append(names, version.Name)
+	}
+	return names, nil
+}
+
+// CommandListInstalled 展示已经安装
+func CommandListInstalled(ctx *cli.Context) {
+	common.ListInstalled(configLocal.Downloads, config.MAVEN, common.GetLinkedVersion(configLocal.Symlink, config.MAVEN))
+}
@@ -0,0 +1,107 @@
+package commands_mirrors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/mirrors"
+	"github.com/FirewineXie/envm/internal/logic/tabular"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// probeSize 每个镜像下载的字节数
+const probeSize = 1 << 20
+
+// probe 测速使用的文件，official 同 common.FromMirrors，选择长期存在且大于 probeSize 的源码包
+type probe struct {
+	official string
+	file     string
+}
+
+var probes = map[string]probe{
+	config.GO:   {official: "", file: "/dl/go1.21.0.src.tar.gz"},
+	config.NODE: {official: "https://nodejs.org/dist/", file: "https://nodejs.org/dist/v20.0.0/node-v20.0.0.tar.gz"},
+}
+
+// CommandTest 通过 Range 请求下载同一个文件的前 1MB，测量每个下载镜像的延迟及吞吐量
+// --reorder 按结果重新排列 config.toml 中的镜像顺序，失败的镜像排在最后而不是删除
+func CommandTest(ctx *cli.Context) error {
+	languages := []string(ctx.Args())
+	if len(languages) == 0 {
+		languages = config.MirrorLanguages()
+	}
+	for _, language := range languages {
+		if _, ok := probes[language]; !ok {
+			return cli.NewExitError(fmt.Sprintf("mirrors are supported for %s only", strings.Join(config.MirrorLanguages(), ", ")), 1)
+		}
+	}
+	client := &http.Client{Timeout: ctx.Duration("timeout")}
+
+	rows := [][]string{{"LANGUAGE", "MIRROR", "LATENCY", "SPEED", "STATUS"}}
+	reordered := make(map[string][]string)
+	for _, language := range languages {
+		list := config.Mirrors(language)
+		results := make([]mirrors.Result, 0, len(list))
+		for _, mirror := range list {
+			url := mirrors.Rewrite(probes[language].file, probes[language].official, mirror)
+			result := mirrors.Probe(context.Background(), client, mirror, url, probeSize)
+			results = append(results, result)
+			rows = append(rows, row(language, result))
+		}
+		mirrors.Sort(results)
+		if order := mirrors.Order(results); strings.Join(order, "\n") != strings.Join(list, "\n") {
+			reordered[language] = order
+		}
+	}
+	if err := tabular.Render(os.Stdout, rows, util.TerminalWidth()); err != nil {
+		return err
+	}
+
+	if !ctx.Bool("reorder") {
+		for language, order := range reordered {
+			fmt.Fprintf(util.Output, "%s mirrors would be reordered to %s, run with --reorder to save it\n", language, strings.Join(order, ", "))
+		}
+		return nil
+	}
+	for _, language := range languages {
+		order, ok := reordered[language]
+		if !ok {
+			continue
+		}
+		if err := config.SetSetting("mirrors."+language, tomlArray(order)); err != nil {
+			return common.Exit(fmt.Errorf("update %s error + %w", config.SettingsFile(), err))
+		}
+		fmt.Fprintf(util.Output, "%s mirrors reordered in %s\n", language, config.SettingsFile())
+	}
+	return nil
+}
+
+func row(language string, result mirrors.Result) []string {
+	if result.Err != nil {
+		return []string{language, result.Mirror, "-", "-", result.Err.Error()}
+	}
+	return []string{
+		language,
+		result.Mirror,
+		result.Latency.Round(time.Millisecond).String(),
+		fmt.Sprintf("%.1f MB/s", result.Throughput/1024/1024),
+		"ok",
+	}
+}
+
+// tomlArray 将 items 格式化为 TOML 的字符串数组
+func tomlArray(items []string) string {
+	quoted := make([]string, 0, len(items))
+	for _, item := range items {
+		quoted = append(quoted, strconv.Quote(item))
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
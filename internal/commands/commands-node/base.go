@@ -1,21 +1,23 @@
 package commands_node
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/FirewineXie/envm/internal/arch"
 	"github.com/FirewineXie/envm/internal/commands/common"
 	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/advisory"
+	"github.com/FirewineXie/envm/internal/logic/listing"
 	"github.com/FirewineXie/envm/internal/logic/web-node"
 	"github.com/FirewineXie/envm/util"
 
-	"github.com/mholt/archiver/v3"
 	"github.com/urfave/cli"
-	"os"
 	"os/exec"
-	"path"
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"strings"
 )
 
 var configLocal = config.Default().LinkSetting[config.NODE]
@@ -27,11 +29,11 @@ func CommandUninstall(ctx *cli.Context) error {
 	if versionS == version {
 		return cli.NewExitError("不能卸载当前版本", 1)
 	}
-	err := os.RemoveAll(filepath.Join(configLocal.Downloads, "node"+versionS))
+	err := common.RemoveDir(filepath.Join(configLocal.Downloads, "node"+versionS))
 	if err != nil {
-		return cli.NewExitError("删除该版本失败+"+err.Error(), 1)
+		return common.Exit(fmt.Errorf("删除该版本失败+%w", err))
 	}
-	fmt.Println("finish uninstall")
+	fmt.Fprintln(util.Output, "finish uninstall")
 	return nil
 }
 
@@ -41,129 +43,186 @@ func CommandInstall(ctx *cli.Context) error {
 	return commandInstall(versionS)
 }
 func commandInstall(versionS string) error {
+	if err := Install(context.Background(), versionS); err != nil {
+		return common.Exit(err)
+	}
+	fmt.Fprintln(util.Output, "Installed successfully")
+	return nil
+}
+
+// Install 下载并安装指定版本
+func Install(ctx context.Context, versionS string) error {
 	if versionS == "" {
-		return cli.NewExitError(fmt.Sprintf("find version for not empty"), 1)
+		return errors.New("find version for not empty")
 	}
+	resolved := util.Phase(util.PhaseResolve)
 	_, _, _, _, _, _, err := web_node.GetAvailable()
+	resolved()
 	if err != nil {
-		return cli.NewExitError("get mirror version failed"+err.Error(), 1)
+		return errors.New("get mirror version failed" + err.Error())
 	}
 	// 1. 验证版本号，是否正确
 	element, ok := web_node.GetMeta()[versionS]
 	if !ok {
-		return cli.NewExitError(fmt.Sprintf("find version for not found"), 1)
+		return util.ErrVersionNotFound
 	}
 
+	release, err := common.Reserve(configLocal.Downloads, config.NODE+versionS)
+	if err != nil {
+		return err
+	}
+	defer release()
 	// 3. 此版本是否已经下载，如果已经下载，则忽略
 	if getInstalled(versionS) {
-		fmt.Println("this version is downloaded")
+		fmt.Fprintln(util.Output, "this version is downloaded")
 		return nil
 	}
 
 	// 4. 此版本是否有该系统架构当前的版本
-	findPackage, err := element.FindPackage(util.ArchiveKind, runtime.GOOS, arch.Validate())
+	// 安装程序中的文件按 /usr/local 布局，只使用压缩包
+	findPackage, err := common.FindPackage(element.FindPackage, runtime.GOOS, arch.Native(), false)
 	if err != nil {
-		return cli.NewExitError(fmt.Sprintf("find version of system error + %v", err), 1)
+		return fmt.Errorf("find version of system error + %w", err)
 	}
 
-	downloadPath := filepath.Clean(filepath.Join(configLocal.Downloads, findPackage.ArchiveName))
-	err = findPackage.DownloadV2(downloadPath)
+	// 下载并使用 SHASUMS256.txt 校验
+	var downloadPath string
+	err = common.FromMirrors(ctx, config.NODE, web_node.DefaultURL, findPackage, func() (err error) {
+		downloadPath, err = common.DownloadPackage(ctx, findPackage, configLocal.Downloads)
+		return err
+	})
 	if err != nil {
-		return cli.NewExitError(fmt.Sprintf("download version error + %v", err), 1)
+		return fmt.Errorf("download version error + %w", err)
 	}
-	//err = findPackage.VerifyChecksum(downloadPath)
-	//if err != nil {
-	//	return cli.NewExitError(fmt.Sprintf("verify version error + %v", err), 1)
-	//}
 
-	// 解压安装包
-	unchivePath := filepath.Clean(filepath.Join(configLocal.Downloads))
-	if err = archiver.Unarchive(downloadPath, unchivePath); err != nil {
-		return cli.NewExitError(fmt.Sprintf(" %s", err.Error()), 1)
-	}
-	err = os.Remove(downloadPath)
-	// 目录重命名
-	if err = os.Rename(filepath.Join(unchivePath, findPackage.FileName), filepath.Clean(filepath.Join(unchivePath, "node"+versionS))); err != nil {
-		return cli.NewExitError(fmt.Sprintf(" %s", err.Error()), 1)
+	// 解压到临时目录后将包内的目录重命名为 node<version>
+	defer util.RemoveDownloaded(downloadPath)()
+	if err = common.ExtractDir(downloadPath, findPackage.FileName, filepath.Join(configLocal.Downloads, "node"+versionS)); err != nil {
+		return err
 	}
-	fmt.Println("Installed successfully")
+	common.KeepArchive(configLocal.Downloads, "node"+versionS, downloadPath, common.KeptArchive{Dir: findPackage.FileName})
+	common.RecordInstall(configLocal.Downloads, "node"+versionS, findPackage)
 	return nil
 }
 
 // CommandUse 激活使用
 func CommandUse(ctx *cli.Context) error {
-	v, err := common.GetVersion(ctx, configLocal.Downloads, "go", true)
+	v, err := common.GetVersion(ctx, configLocal.Downloads, "node", true)
 	if err != nil {
 
 		return err
 	}
-	// active use
-	if configLocal.Symlink == "" {
-		return cli.NewExitError("not config symlink", 1)
-	}
-	_ = os.Remove(configLocal.Symlink)
-	fmt.Println(path.Join(configLocal.Downloads, "node"+v), configLocal.Symlink)
-	if err := os.Symlink(path.Join(configLocal.Downloads, "node"+v), configLocal.Symlink); err != nil {
-		return cli.NewExitError(fmt.Sprintf("%s", err.Error()), 1)
+	if err = Activate(v); err != nil {
+		return common.Exit(err)
 	}
 	output, err := exec.Command("node", "--version").Output()
 	if err != nil {
 		return err
 	}
-	fmt.Println(string(output))
+	fmt.Fprintln(util.Output, string(output))
 	return nil
 }
 
-// CommandListRemote 获取远程的可下载的版本
-func CommandListRemote(ctx *cli.Context) error {
-	versionType := ctx.Args().First()
+// Activate 将 symlink 指向已经安装的版本
+func Activate(v string) error {
+	if !getInstalled(v) {
+		return errors.New("you have not install it,please install before use")
+	}
+	return common.ActiveVersion(configLocal.Downloads, "node"+v, configLocal.Symlink)
+}
 
-	all, lts, current, stable, unstable, _, err := web_node.GetAvailable()
+// SecurityReleases 返回 index.json 中标记为安全修复的版本
+func SecurityReleases() ([]string, error) {
+	data, err := web_node.DownloadContent(advisory.NodeIndexURL)
 	if err != nil {
-		return cli.NewExitError(fmt.Sprintf("%s", err.Error()), 1)
+		return nil, err
 	}
-	releases := 20
+	return advisory.ParseNodeIndex(data)
+}
 
-	switch versionType {
-	case "all":
-		for i, version := range all {
-			if i == releases {
-				break
-			}
-			fmt.Println(version)
+// ListRemote 返回所有可以安装的版本
+func ListRemote() ([]string, error) {
+	all := make([]string, 0)
+	err := web_node.EachRelease(func(element web_node.FileData) bool {
+		all = append(all, element.Version[1:])
+		return true
+	})
+	return all, err
+}
+
+// release 将 index.json 中的版本转换为 listing.Release，平台来自 files 中的 linux-x64、osx-arm64-tar 等
+func release(element web_node.FileData) listing.Release {
+	r := listing.Release{Version: element.Version[1:], Kind: web_node.Classify(element)}
+	seen := make(map[string]bool)
+	for _, file := range element.Files {
+		split := strings.Split(file, "-")
+		if len(split) < 2 {
+			// headers、src
+			continue
 		}
-	case "lts":
-		for i, version := range lts {
-			if i == releases {
-				break
-			}
-			fmt.Println(version)
+		platform := listing.Platform(split[0], split[1])
+		if !seen[platform] {
+			seen[platform] = true
+			r.Platforms = append(r.Platforms, platform)
 		}
-	case "current":
-		for i, version := range current {
-			if i == releases {
-				break
-			}
-			fmt.Println(version)
+	}
+	return r
+}
+
+// Releases 返回所有版本及提供安装包的平台
+func Releases() ([]listing.Release, error) {
+	releases := make([]listing.Release, 0)
+	err := web_node.EachRelease(func(element web_node.FileData) bool {
+		releases = append(releases, release(element))
+		return true
+	})
+	return releases, err
+}
+
+// CommandListRemote 获取远程的可下载的版本，位置参数 lts|current|stable|unstable 同 --kind，all 不过滤
+// 不排序时边读取 index.json 边输出，取够 --limit 个后不再读取
+func CommandListRemote(ctx *cli.Context) error {
+	versionType := ctx.Args().First()
+	switch versionType {
+	case "", "all", web_node.KindLTS, web_node.KindCurrent, web_node.KindStable, web_node.KindUnstable:
+	default:
+		return cli.ShowSubcommandHelp(ctx)
+	}
+	if versionType == "all" {
+		versionType = ""
+	}
+	f, err := common.ReleaseFilter(ctx, versionType)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	switch f.Kind {
+	case "", web_node.KindLTS, web_node.KindCurrent, web_node.KindStable, web_node.KindUnstable:
+	default:
+		return cli.NewExitError(fmt.Sprintf("unknown kind %s, kinds: %s", f.Kind, strings.Join([]string{web_node.KindLTS, web_node.KindCurrent, web_node.KindStable, web_node.KindUnstable}, ", ")), 1)
+	}
+	releases := make([]listing.Release, 0)
+	err = web_node.EachRelease(func(element web_node.FileData) bool {
+		r := release(element)
+		if !f.Match(r) {
+			return true
 		}
-	case "stable":
-		for i, version := range stable {
-			if i == releases {
-				break
-			}
-			fmt.Println(version)
+		if f.Sort != "" {
+			releases = append(releases, r)
+			return true
 		}
-	case "unstable":
-		for i, version := range unstable {
-			if i == releases {
-				break
-			}
-			fmt.Println(version)
+		common.PrintRelease(r)
+		releases = append(releases, r)
+		return f.Limit <= 0 || len(releases) < f.Limit
+	})
+	if err != nil {
+		return common.Exit(err)
+	}
+	if f.Sort != "" {
+		for _, r := range listing.Apply(releases, f) {
+			common.PrintRelease(r)
 		}
-
 	}
-
-	return cli.ShowSubcommandHelp(ctx)
+	return nil
 }
 
 // CommandListInstalled 展示已经安装
@@ -186,11 +245,11 @@ func CommandListInstalled(ctx *cli.Context) {
 		if in == goVersion {
 			str = str + " (Currently using " + in + " executable)"
 		}
-		fmt.Printf(str + "\n")
+		fmt.Fprintf(util.Output, str+"\n")
 
 	}
 	if len(v) == 0 {
-		fmt.Println("No installations recognized.")
+		fmt.Fprintln(util.Output, "No installations recognized.")
 	}
 }
 
@@ -0,0 +1,113 @@
+package commands_notify
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/advisory"
+	"github.com/FirewineXie/envm/internal/logic/index"
+	"github.com/FirewineXie/envm/internal/logic/notify"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// silent 输出会被 shell eval 或由其它程序读取的命令，以及 notify 本身，结束后不提示
+var silent = map[string]bool{
+	"hook": true, "hook-env": true, "direnv": true, "direnv-env": true, "env": true, "exec": true,
+	"shim-exec": true, "completion": true, "psmodule": true, "refresh-index": true, "notify": true,
+	"daemon": true, "serve": true, "refresh": true, "check": true,
+}
+
+// Check 命令结束后提示已激活的语言有新版本，只读取缓存的远程版本列表，不访问网络
+// 需要配置 notify = true，输出到 stderr，每个语言每天最多提示一次
+// 缓存了安全修复的版本列表时标注新版本包含安全修复，notify_security_only = true 时只提示这些版本
+func Check(ctx *cli.Context) {
+	settings := config.Default().Settings
+	if !settings.Notify || silent[ctx.Args().First()] || !util.IsTerminal(os.Stderr) {
+		return
+	}
+	notices := map[string]string{}
+	keys := make([]string, 0)
+	for _, language := range languages.All() {
+		current := language.CurrentVersion()
+		if current == "" {
+			continue
+		}
+		cached, err := index.Read(config.IndexDir(), language.Name)
+		if err != nil {
+			continue
+		}
+		version, patch := notify.Newer(current, cached.Versions)
+		if version == "" {
+			continue
+		}
+		kind := "new release"
+		if patch {
+			kind = "patch release"
+		}
+		if security, err := index.Read(config.IndexDir(), language.Name+"-security"); err == nil {
+			if _, fixes := advisory.Successors(current, cached.Versions, security.Versions); len(fixes) > 0 {
+				kind = "security fix in " + strings.Join(fixes, ", ")
+			} else if settings.NotifySecurityOnly {
+				continue
+			}
+		} else if settings.NotifySecurityOnly {
+			continue
+		}
+		notices[language.Name] = fmt.Sprintf("envm: %s %s is available (%s, using %s); run `envm %s install %s`",
+			language.Name, version, kind, current, language.Name, version)
+		keys = append(keys, language.Name)
+	}
+	due := notify.Due(config.NotifyFile(), keys)
+	for _, name := range due {
+		fmt.Fprintln(os.Stderr, notices[name])
+	}
+	if len(due) > 0 {
+		fmt.Fprintln(os.Stderr, "envm: disable these notices with `envm notify off`")
+		_ = notify.Mark(config.NotifyFile(), due)
+	}
+}
+
+// CommandNotify envm notify on|off|security 修改 config.toml 中的 notify，没有参数时显示当前状态
+// security 同时打开 notify_security_only，on 关闭它
+func CommandNotify(ctx *cli.Context) error {
+	var value string
+	switch ctx.Args().First() {
+	case "":
+		state := "off"
+		if config.Default().Settings.Notify {
+			state = "on"
+		}
+		if config.Default().Settings.Notify && config.Default().Settings.NotifySecurityOnly {
+			state = "on for security fixes only"
+		}
+		fmt.Printf("new version notices are %s (notify in %s)\n", state, config.SettingsFile())
+		return nil
+	case "on", "security":
+		value = "true"
+	case "off":
+		value = "false"
+	default:
+		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
+	}
+	if err := config.SetSetting("notify", value); err != nil {
+		return common.Exit(fmt.Errorf("update %s error + %w", config.SettingsFile(), err))
+	}
+	if value == "true" {
+		securityOnly := strconv.FormatBool(ctx.Args().First() == "security")
+		if err := config.SetSetting("notify_security_only", securityOnly); err != nil {
+			return common.Exit(fmt.Errorf("update %s error + %w", config.SettingsFile(), err))
+		}
+	}
+	state := ctx.Args().First()
+	if state == "security" {
+		state = "on for security fixes only"
+	}
+	fmt.Printf("new version notices are %s\n", state)
+	return nil
+}
@@ -0,0 +1,69 @@
+package commands_outdated
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/logic/advisory"
+	"github.com/urfave/cli"
+)
+
+// CommandOutdated 列出已安装的版本中同一个 major.minor 下有更新版本的，可以在参数中指定语言
+// --security 只列出更新版本中包含安全修复的，目前只有 go、node 提供安全修复的来源
+func CommandOutdated(ctx *cli.Context) error {
+	items := languages.All()
+	if ctx.NArg() > 0 {
+		items = items[:0:0]
+		for _, name := range ctx.Args() {
+			language := languages.Find(name)
+			if language == nil {
+				return cli.NewExitError(name+" is not supported by envm", 1)
+			}
+			items = append(items, language)
+		}
+	}
+
+	security := ctx.Bool("security")
+	found := 0
+	for _, language := range items {
+		installed := common.GetInstalled(language.Link().Downloads, language.Prefix)
+		if len(installed) == 0 {
+			continue
+		}
+		if security && language.Advisories == nil {
+			continue
+		}
+		versions, err := language.RemoteVersions(false)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: list remote versions of %s error + %v\n", language.Name, err)
+			continue
+		}
+		fixed, err := language.SecurityReleases(false)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: list security releases of %s error + %v\n", language.Name, err)
+		}
+		for _, version := range installed {
+			latest, fixes := advisory.Successors(version, versions, fixed)
+			if latest == "" || security && len(fixes) == 0 {
+				continue
+			}
+			found++
+			line := fmt.Sprintf("%-8s %-12s -> %-12s", language.Name, version, latest)
+			if len(fixes) > 0 {
+				line += " security fixes in " + strings.Join(fixes, ", ")
+			}
+			fmt.Println(line)
+		}
+	}
+	if found == 0 {
+		if security {
+			fmt.Println("no installed version has a known security fix")
+		} else {
+			fmt.Println("all installed versions are up to date")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package commands_php
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/arch"
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/listing"
+	"github.com/FirewineXie/envm/internal/logic/web-php"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+var configLocal = config.Default().LinkSetting[config.PHP]
+
+func CommandUninstall(ctx *cli.Context) error {
+	current := common.GetLinkedVersion(configLocal.Symlink, config.PHP)
+	if err := common.UninstallVersion(configLocal.Downloads, config.PHP, ctx.Args().First(), current); err != nil {
+		return common.Exit(fmt.Errorf("删除该版本失败+%w", err))
+	}
+	fmt.Fprintln(util.Output, "finish uninstall")
+	return nil
+}
+
+// getVersions windows 使用 windows.php.net 的二进制包，其余系统使用 php.net 源码包
+func getVersions() ([]*web_php.VersionPHP, error) {
+	if runtime.GOOS == "windows" {
+		return web_php.WindowsVersions()
+	}
+	return web_php.SourceVersions()
+}
+
+// CommandInstall 安装命令
+func CommandInstall(ctx *cli.Context) error {
+	versionS := ctx.Args().First()
+	if versionS == "" {
+		return cli.ShowSubcommandHelp(ctx)
+	}
+	if err := Install(context.Background(), versionS); err != nil {
+		return common.Exit(err)
+	}
+	return nil
+}
+
+// Install 下载并安装指定版本，非 windows 系统从源码编译
+func Install(ctx context.Context, versionS string) error {
+	release, err := common.Reserve(configLocal.Downloads, config.PHP+versionS)
+	if err != nil {
+		return err
+	}
+	defer release()
+	if common.IsInstalled(configLocal.Downloads, config.PHP, versionS) {
+		fmt.Fprintln(util.Output, "this version is downloaded")
+		return nil
+	}
+	versions, err := getVersions()
+	if err != nil {
+		return fmt.Errorf("collect version error + %w", err)
+	}
+	var version *web_php.VersionPHP
+	for _, v := range versions {
+		if v.Name == versionS {
+			version = v
+			break
+		}
+	}
+	if version == nil {
+		return util.ErrVersionNotFound
+	}
+	findPackage, err := common.FindPackage(version.FindPackage, runtime.GOOS, arch.Native(), false)
+	if err != nil {
+		return fmt.Errorf("find version of system error + %w", err)
+	}
+
+	target := filepath.Join(configLocal.Downloads, config.PHP+versionS)
+	if findPackage.Kind == util.SourceKind {
+		err = buildFromSource(ctx, findPackage, target)
+	} else {
+		err = installBinary(ctx, findPackage, versionS, target)
+	}
+	if err != nil {
+		_ = os.RemoveAll(target)
+		return fmt.Errorf("install version error + %w", err)
+	}
+	common.RecordInstall(configLocal.Downloads, config.PHP+versionS, findPackage)
+	fmt.Fprintln(util.Output, "Installed successfully")
+	return nil
+}
+
+// installBinary windows 二进制包解压即可使用，扩展目录为 <target>/ext
+func installBinary(ctx context.Context, pkg *util.Package, version, target string) error {
+	if err := common.InstallDirArchive(ctx, pkg, configLocal.Downloads, config.PHP, version); err != nil {
+		return err
+	}
+	return scaffoldIni(filepath.Join(target, "php.ini-development"), filepath.Join(target, "php.ini"), filepath.Join(target, "ext"))
+}
+
+// buildFromSource 编译安装源码包，php.ini 及 conf.d 放在 <target>/etc 下，与其它版本互不影响
+func buildFromSource(ctx context.Context, pkg *util.Package, target string) error {
+	downloadPath, err := common.DownloadPackage(ctx, pkg, configLocal.Downloads)
+	if err != nil {
+		return err
+	}
+	defer util.RemoveDownloaded(downloadPath)()
+
+	src := target + ".src"
+	if err = common.ExtractDir(downloadPath, pkg.FileName, src); err != nil {
+		return err
+	}
+	defer os.RemoveAll(src)
+
+	etc := filepath.Join(target, "etc")
+	steps := [][]string{
+		{"./configure", "--prefix=" + target, "--with-config-file-path=" + etc, "--with-config-file-scan-dir=" + filepath.Join(etc, "conf.d")},
+		{"make", "-j" + strconv.Itoa(runtime.NumCPU())},
+		{"make", "install"},
+	}
+	for _, step := range steps {
+		fmt.Fprintln(util.Output, strings.Join(step, " "))
+		cmd := exec.CommandContext(ctx, step[0], step[1:]...)
+		cmd.Dir = src
+		cmd.Stdout = util.Output
+		cmd.Stderr = os.Stderr
+		if err = cmd.Run(); err != nil {
+			return fmt.Errorf("%s: %w", step[0], err)
+		}
+	}
+
+	output, err := exec.Command(filepath.Join(target, "bin", "php-config"), "--extension-dir").Output()
+	if err != nil {
+		return err
+	}
+	return scaffoldIni(filepath.Join(src, "php.ini-development"), filepath.Join(etc, "php.ini"), strings.TrimSpace(string(output)))
+}
+
+// scaffoldIni 基于模板生成 php.ini 并固定 extension_dir，已存在时不覆盖
+func scaffoldIni(template, ini, extensionDir string) error {
+	if exists, _ := util.PathExists(ini); exists {
+		return nil
+	}
+	content, err := os.ReadFile(template)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(string(content), "\n")
+	replaced := false
+	for i, line := range lines {
+		if !replaced && strings.HasPrefix(strings.TrimSpace(line), ";extension_dir") {
+			lines[i] = fmt.Sprintf("extension_dir = %q", extensionDir)
+			replaced = true
+		}
+	}
+	if !replaced {
+		lines = append(lines, fmt.Sprintf("extension_dir = %q", extensionDir))
+	}
+	if err = os.MkdirAll(filepath.Join(filepath.Dir(ini), "conf.d"), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(ini, []byte(strings.Join(lines, "\n")), 0644)
+}
+
+// CommandIni 输出当前版本使用的 php.ini 路径
+func CommandIni(ctx *cli.Context) error {
+	current := common.GetLinkedVersion(configLocal.Symlink, config.PHP)
+	if current == "" {
+		return cli.NewExitError("no active php version", 1)
+	}
+	target := filepath.Join(configLocal.Downloads, config.PHP+current)
+	ini := filepath.Join(target, "etc", "php.ini")
+	if runtime.GOOS == "windows" {
+		ini = filepath.Join(target, "php.ini")
+	}
+	fmt.Fprintln(util.Output, ini)
+	return nil
+}
+
+// CommandUse 激活使用
+func CommandUse(ctx *cli.Context) error {
+	v, err := common.GetVersion(ctx, configLocal.Downloads, config.PHP, true)
+	if err != nil {
+		return err
+	}
+	if err = Activate(v); err != nil {
+		return common.Exit(err)
+	}
+	fmt.Fprintln(util.Output, "Now using php "+v)
+	return nil
+}
+
+// Activate 将 symlink 指向已经安装的版本
+func Activate(v string) error {
+	if !common.IsInstalled(configLocal.Downloads, config.PHP, v) {
+		return errors.New("you have not install it,please install before use")
+	}
+	return common.ActiveVersion(configLocal.Downloads, config.PHP+v, configLocal.Symlink)
+}
+
+// Releases 返回所有版本，windows 上为二进制包的平台，其它系统从源码编译，与平台无关
+func Releases() ([]listing.Release, error) {
+	versions, err := getVersions()
+	if err != nil {
+		return nil, err
+	}
+	releases := make([]listing.Release, 0, len(versions))
+	for _, version := range versions {
+		releases = append(releases, listing.Release{Version: version.Name, Platforms: listing.Platforms(version.Packages)})
+	}
+	return releases, nil
+}
+
+// CommandListRemote 获取远程的可下载的版本
+func CommandListRemote(ctx *cli.Context) error {
+	releases, err := Releases()
+	if err != nil {
+		return common.Exit(fmt.Errorf("collect version error + %w", err))
+	}
+	return common.PrintReleases(ctx, releases, "")
+}
+
+// ListRemote 返回所有可以安装的版本
+func ListRemote() ([]string, error) {
+	versions, err := getVersions()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(versions))
+	for _, version := range versions {
+		names = append(names, version.Name)
+	}
+	return names, nil
+}
+
+// CommandListInstalled 展示已经安装
+func CommandListInstalled(ctx *cli.Context) {
+	common.ListInstalled(configLocal.Downloads, config.PHP, common.GetLinkedVersion(configLocal.Symlink, config.PHP))
+}
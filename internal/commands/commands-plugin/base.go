@@ -0,0 +1,193 @@
+package commands_plugin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/listing"
+	"github.com/FirewineXie/envm/internal/logic/netpolicy"
+	"github.com/FirewineXie/envm/internal/logic/plugin"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// open 打开第一个参数指定的插件
+func open(ctx *cli.Context) (*plugin.Plugin, config.SubConfig, error) {
+	name := ctx.Args().First()
+	if name == "" {
+		return nil, config.SubConfig{}, errors.New("plugin name can not be empty")
+	}
+	p, err := plugin.Open(config.PluginRoot(), name)
+	return p, config.PluginLink(name), err
+}
+
+// CommandAdd 通过 git 安装插件
+func CommandAdd(ctx *cli.Context) error {
+	name, url := ctx.Args().Get(0), ctx.Args().Get(1)
+	if name == "" || url == "" {
+		return cli.ShowSubcommandHelp(ctx)
+	}
+	// git 不经过 envm 的 HTTP 请求，clone 前按 [network] 的规则检查仓库地址
+	if host := netpolicy.GitHost(url); host != "" {
+		if err := netpolicy.Active().Check(host); err != nil {
+			return common.Exit(err)
+		}
+	}
+	dir, err := plugin.Path(config.PluginRoot(), name)
+	if err != nil {
+		return common.Exit(err)
+	}
+	cmd := exec.Command("git", "clone", "--depth", "1", url, dir)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err = cmd.Run(); err != nil {
+		return common.Exit(fmt.Errorf("add plugin failed + %w", err))
+	}
+	if _, err = plugin.Open(config.PluginRoot(), name); err != nil {
+		_ = os.RemoveAll(dir)
+		return common.Exit(err)
+	}
+	fmt.Println("plugin " + name + " added")
+	return nil
+}
+
+// CommandRemove 删除插件，已安装的版本保留，删除前需要确认
+func CommandRemove(ctx *cli.Context) error {
+	p, _, err := open(ctx)
+	if err != nil {
+		return common.Exit(err)
+	}
+	if !util.ConfirmRemoval(fmt.Sprintf("remove plugin %s?", p.Name)) {
+		return cli.NewExitError("remove canceled", 1)
+	}
+	if err = os.RemoveAll(p.Dir); err != nil {
+		return common.Exit(err)
+	}
+	fmt.Println("plugin " + p.Name + " removed")
+	return nil
+}
+
+// CommandList 展示已安装的插件
+func CommandList(ctx *cli.Context) {
+	plugins := plugin.List(config.PluginRoot())
+	for _, p := range plugins {
+		fmt.Printf("%-16s %s\n", p.Name, p.Dir)
+	}
+	if len(plugins) == 0 {
+		fmt.Println("No plugins installed.")
+	}
+}
+
+// CommandListRemote 执行插件的 list-remote 钩子
+func CommandListRemote(ctx *cli.Context) error {
+	p, _, err := open(ctx)
+	if err != nil {
+		return common.Exit(err)
+	}
+	versions, err := p.ListRemote()
+	if err != nil {
+		return common.Exit(err)
+	}
+	return common.PrintReleases(ctx, listing.FromVersions(versions), "")
+}
+
+// CommandInstall 执行插件的 download/install 钩子
+func CommandInstall(ctx *cli.Context) error {
+	name, version := ctx.Args().Get(0), ctx.Args().Get(1)
+	if name == "" || version == "" {
+		return cli.ShowSubcommandHelp(ctx)
+	}
+	if err := Install(name, version); err != nil {
+		return common.Exit(err)
+	}
+	fmt.Println("Installed successfully")
+	return nil
+}
+
+// Install 使用插件安装指定版本
+func Install(name, version string) error {
+	p, err := plugin.Open(config.PluginRoot(), name)
+	if err != nil {
+		return err
+	}
+	link := config.PluginLink(name)
+	if common.IsInstalled(link.Downloads, p.Name, version) {
+		fmt.Println("this version is downloaded")
+		return nil
+	}
+	if err = common.Preflight(p.Name, link); err != nil {
+		return err
+	}
+	installPath := filepath.Join(link.Downloads, p.Name+version)
+	if err = p.Install(version, installPath+".download", installPath); err != nil {
+		return err
+	}
+	common.RecordInstall(link.Downloads, p.Name+version, nil)
+	return nil
+}
+
+// CommandUse 链接到指定版本并执行插件的 activate 钩子
+func CommandUse(ctx *cli.Context) error {
+	name, version := ctx.Args().Get(0), ctx.Args().Get(1)
+	if name == "" {
+		return cli.ShowSubcommandHelp(ctx)
+	}
+	if err := Activate(name, version); err != nil {
+		return common.Exit(err)
+	}
+	fmt.Printf("Now using %s %s\n", name, version)
+	return nil
+}
+
+// Activate 链接到插件的指定版本，并输出 activate 钩子的结果
+func Activate(name, version string) error {
+	p, err := plugin.Open(config.PluginRoot(), name)
+	if err != nil {
+		return err
+	}
+	link := config.PluginLink(name)
+	if !common.IsInstalled(link.Downloads, p.Name, version) {
+		return errors.New("you have not install it,please install before use")
+	}
+	if err = os.MkdirAll(filepath.Dir(link.Symlink), os.ModePerm); err != nil {
+		return err
+	}
+	if err = common.ActiveVersion(link.Downloads, p.Name+version, link.Symlink); err != nil {
+		return err
+	}
+	output, err := p.Run(plugin.HookActivate, plugin.Env{Version: version, InstallPath: filepath.Join(link.Downloads, p.Name+version)})
+	if err != nil && !errors.Is(err, plugin.ErrHookNotFound) {
+		return err
+	}
+	fmt.Print(string(output))
+	return nil
+}
+
+// CommandListInstalled 展示插件已安装的版本
+func CommandListInstalled(ctx *cli.Context) error {
+	p, link, err := open(ctx)
+	if err != nil {
+		return common.Exit(err)
+	}
+	common.ListInstalled(link.Downloads, p.Name, common.GetLinkedVersion(link.Symlink, p.Name))
+	return nil
+}
+
+// CommandUninstall 卸载插件的指定版本
+func CommandUninstall(ctx *cli.Context) error {
+	p, link, err := open(ctx)
+	if err != nil {
+		return common.Exit(err)
+	}
+	current := common.GetLinkedVersion(link.Symlink, p.Name)
+	if err = common.UninstallVersion(link.Downloads, p.Name, ctx.Args().Get(1), current); err != nil {
+		return common.Exit(fmt.Errorf("删除该版本失败+%w", err))
+	}
+	fmt.Println("finish uninstall")
+	return nil
+}
@@ -0,0 +1,30 @@
+package commands_reinstall
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// CommandReinstall 从 keep_downloads 保留的安装包重新生成已安装版本的目录，不访问网络
+// 用于安装目录被误删改或损坏(envm verify 报告的问题)后恢复，版本保持激活状态
+func CommandReinstall(ctx *cli.Context) error {
+	name, input, _ := strings.Cut(ctx.Args().First(), "@")
+	language := languages.Find(name)
+	if language == nil || input == "" {
+		return cli.NewExitError("usage: envm reinstall <language>@<version>, e.g. go@1.22.3", 1)
+	}
+	version := language.InstalledVersion(input)
+	if err := common.Reinstall(language.Link().Downloads, language.Prefix+version); err != nil {
+		return common.Exit(fmt.Errorf("reinstall %s %s error + %w", language.Name, version, err))
+	}
+	if err := language.Check(version); err != nil {
+		return common.Exit(err)
+	}
+	fmt.Fprintf(util.Output, "%s %s reinstalled from the kept archive\n", language.Name, version)
+	return nil
+}
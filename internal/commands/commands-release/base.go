@@ -0,0 +1,106 @@
+package commands_release
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/commands/commands-verify"
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/logic/packaging"
+	"github.com/FirewineXie/envm/internal/logic/selfcheck"
+	web_github "github.com/FirewineXie/envm/internal/logic/web-github"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// 支持的包管理器
+const (
+	Homebrew = "homebrew"
+	Scoop    = "scoop"
+	Winget   = "winget"
+)
+
+// Managers 不指定包管理器时生成的全部文件
+var Managers = []string{Homebrew, Scoop, Winget}
+
+// CommandRelease 根据 release 中的附件及校验文件生成包管理器的 formula/manifest，写入 --dir
+// homebrew 为 envm.rb，scoop 为 envm.json，winget 为 winget-pkgs 仓库中的目录结构
+// --version 默认为当前 envm 的版本，发布新版本后执行一次即可与 release 保持一致
+func CommandRelease(ctx *cli.Context) error {
+	managers := Managers
+	if ctx.NArg() > 0 {
+		managers = ctx.Args()
+		for _, manager := range managers {
+			if manager != Homebrew && manager != Scoop && manager != Winget {
+				return cli.NewExitError(fmt.Sprintf("unsupported package manager %q, use %s", manager, strings.Join(Managers, ", ")), 1)
+			}
+		}
+	}
+	tag := ctx.String("version")
+	if tag == "" {
+		tag = ctx.App.Version
+	}
+	p, err := load(tag, ctx.App.Usage)
+	if err != nil {
+		return common.Exit(fmt.Errorf("load release %s error + %w", tag, err))
+	}
+
+	dir := ctx.String("dir")
+	files := map[string][]byte{}
+	for _, manager := range managers {
+		switch manager {
+		case Homebrew:
+			files[filepath.Join(dir, "envm.rb")] = []byte(packaging.Homebrew(p))
+		case Scoop:
+			data, err := packaging.Scoop(p)
+			if err != nil {
+				return common.Exit(err)
+			}
+			files[filepath.Join(dir, "envm.json")] = data
+		case Winget:
+			for name, content := range packaging.Winget(p) {
+				files[filepath.Join(dir, filepath.FromSlash(packaging.WingetDir(p.Version)), name)] = []byte(content)
+			}
+		}
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err = os.MkdirAll(filepath.Dir(name), os.ModePerm); err == nil {
+			err = os.WriteFile(name, files[name], 0644)
+		}
+		if err != nil {
+			return common.Exit(err)
+		}
+		fmt.Println("write " + name)
+	}
+	return nil
+}
+
+// load 查询 release 并下载其中的校验文件
+func load(tag, description string) (packaging.Package, error) {
+	release, err := web_github.FindRelease(commands_verify.Repo, tag)
+	if err != nil {
+		return packaging.Package{}, err
+	}
+	asset := selfcheck.ChecksumAsset(release)
+	if asset == nil {
+		return packaging.Package{}, fmt.Errorf("release publishes none of %s", strings.Join(selfcheck.ChecksumAssets, ", "))
+	}
+	checksums, err := util.FetchContent(asset.URL)
+	if err != nil {
+		return packaging.Package{}, err
+	}
+	artifacts, err := packaging.Artifacts(release.Assets, checksums)
+	if err != nil {
+		return packaging.Package{}, err
+	}
+	return packaging.Package{Version: strings.TrimPrefix(tag, "v"), Description: description,
+		ChecksumURL: asset.URL, Artifacts: artifacts}, nil
+}
@@ -0,0 +1,64 @@
+package commands_relocate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/relocate"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// CommandRelocate 将所有已安装的版本迁移到 <new-root>/downloads，例如原来的磁盘已满
+// 先移动(跨磁盘时复制)安装目录，再写入 config.toml 的 install_root，最后修改 symlink 及 state.json，复制时最后删除原目录
+// 任何一步失败都保留原来的安装目录及配置；PATH 及 shims 指向 symlink 或 envm 本身，不需要修改
+func CommandRelocate(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return cli.NewExitError("usage: envm relocate <new-root>", 1)
+	}
+	if config.IsShared() {
+		return cli.NewExitError(fmt.Sprintf("versions are installed into the shared root %s, move it and update ENVM_SHARED_ROOT or shared_root instead", config.SharedRoot()), 1)
+	}
+	newRoot, err := filepath.Abs(ctx.Args().First())
+	if err != nil {
+		return common.Exit(err)
+	}
+	oldDownloads := config.Default().Downloads
+	newDownloads := filepath.Join(newRoot, "downloads")
+	if relocate.Within(newDownloads, oldDownloads) || relocate.Within(oldDownloads, newDownloads) {
+		return cli.NewExitError(fmt.Sprintf("%s overlaps the current install directory %s", newDownloads, oldDownloads), 1)
+	}
+	if !util.ConfirmRemoval(fmt.Sprintf("move all installed versions from %s to %s?", oldDownloads, newDownloads)) {
+		return cli.NewExitError("relocate canceled", 1)
+	}
+
+	fmt.Fprintf(util.Output, "moving %s to %s\n", oldDownloads, newDownloads)
+	copied, err := relocate.Move(oldDownloads, newDownloads)
+	if err != nil {
+		return common.Exit(fmt.Errorf("move installed versions error + %w", err))
+	}
+	if err = config.SetSetting("install_root", strconv.Quote(newRoot)); err != nil {
+		// 还原，保持原来的配置仍然可用
+		if copied {
+			_ = os.RemoveAll(newDownloads)
+		} else {
+			_ = os.Rename(newDownloads, oldDownloads)
+		}
+		return common.Exit(fmt.Errorf("update %s error + %w", config.SettingsFile(), err))
+	}
+	count, err := common.Retarget(oldDownloads, newDownloads)
+	if err != nil {
+		return common.Exit(fmt.Errorf("%w, run envm current to repair the remaining symlinks", err))
+	}
+	if copied {
+		if err = os.RemoveAll(oldDownloads); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: remove %s error + %v, delete it manually\n", oldDownloads, err)
+		}
+	}
+	fmt.Fprintf(util.Output, "relocated to %s, %d symlinks updated, install_root is written into %s\n", newRoot, count, config.SettingsFile())
+	return nil
+}
@@ -0,0 +1,133 @@
+package commands_remote
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/logic/listing"
+	"github.com/FirewineXie/envm/internal/logic/tabular"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// result 一个语言的远程版本
+type result struct {
+	versions []string
+	err      error
+}
+
+// CommandListRemote 同时查询多个语言可以安装的版本，--all 时查询所有语言，每个语言的版本按 --os、--arch、--kind 过滤，--sort 排序后取 --limit 个
+// 最多同时查询 --jobs 个来源，每个来源最多等待 --timeout，慢的来源不会拖慢整个命令
+// --output 为 tsv/csv 时每行输出 语言、版本，查询失败的语言输出到 stderr
+func CommandListRemote(ctx *cli.Context) error {
+	format := ctx.String("output")
+	if err := tabular.Check(format); err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	items := make([]*languages.Language, 0)
+	for _, name := range ctx.Args() {
+		language := languages.Find(name)
+		if language == nil {
+			return cli.NewExitError(name+" is not supported by envm", 1)
+		}
+		items = append(items, language)
+	}
+	if ctx.Bool("all") {
+		items = languages.All()
+	}
+	if len(items) == 0 {
+		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
+	}
+
+	f, err := common.ReleaseFilter(ctx, "")
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	done := util.Phase(util.PhaseResolve)
+	results := fetchAll(items, ctx.Int("jobs"), ctx.Duration("timeout"), func(language *languages.Language) ([]string, error) {
+		releases, err := language.RemoteReleases(true, f)
+		if err != nil {
+			return nil, err
+		}
+		return listing.Versions(listing.Apply(releases, f)), nil
+	})
+	done()
+	failed := 0
+	table := format == "" || format == tabular.Table
+	rows := make([][]string, 0)
+	for i, language := range items {
+		if table {
+			fmt.Printf("==> %s\n", language.Name)
+		}
+		if results[i].err != nil {
+			failed++
+			if table {
+				fmt.Printf("collect version error + %v\n", results[i].err)
+			} else {
+				fmt.Fprintf(os.Stderr, "%s: collect version error + %v\n", language.Name, results[i].err)
+			}
+			continue
+		}
+		for _, version := range results[i].versions {
+			if table {
+				fmt.Println(version)
+			}
+			rows = append(rows, []string{language.Name, version})
+		}
+	}
+	if !table {
+		if err := tabular.Write(os.Stdout, format, []string{"language", "version"}, rows); err != nil {
+			return common.Exit(err)
+		}
+	}
+	if failed > 0 {
+		return cli.NewExitError(fmt.Sprintf("%d sources failed", failed), 1)
+	}
+	return nil
+}
+
+// refresh 重新获取远程版本并更新缓存，offline 时使用缓存
+func refresh(language *languages.Language) ([]string, error) {
+	return language.RemoteVersions(true)
+}
+
+// fetchAll 使用 jobs 个 worker 并发调用 list 查询，结果的顺序与 items 一致
+func fetchAll(items []*languages.Language, jobs int, timeout time.Duration, list func(*languages.Language) ([]string, error)) []result {
+	if jobs < 1 {
+		jobs = 1
+	}
+	results := make([]result, len(items))
+	slots := make(chan struct{}, jobs)
+	var wg sync.WaitGroup
+	for i, language := range items {
+		wg.Add(1)
+		go func(i int, language *languages.Language) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			results[i] = fetch(language, timeout, list)
+		}(i, language)
+	}
+	wg.Wait()
+	return results
+}
+
+// fetch 查询一个语言，超时后直接返回，未完成的请求在后台随进程结束
+func fetch(language *languages.Language, timeout time.Duration, list func(*languages.Language) ([]string, error)) result {
+	done := make(chan result, 1)
+	go func() {
+		versions, err := list(language)
+		done <- result{versions, err}
+	}()
+	select {
+	case r := <-done:
+		return r
+	case <-time.After(timeout):
+		return result{err: fmt.Errorf("%s did not respond in %s", language.Name, timeout)}
+	}
+}
@@ -0,0 +1,55 @@
+package commands_remote
+
+import (
+	"errors"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/config"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/urfave/cli"
+)
+
+func TestFetchAll(t *testing.T) {
+	Convey("慢的来源超时，不影响其它来源", t, func() {
+		slow := &languages.Language{Name: "slow", ListRemote: func() ([]string, error) {
+			time.Sleep(time.Second)
+			return []string{"1.0.0"}, nil
+		}}
+		fast := &languages.Language{Name: "fast", ListRemote: func() ([]string, error) {
+			return []string{"2.0.0", "1.0.0"}, nil
+		}}
+		broken := &languages.Language{Name: "broken", ListRemote: func() ([]string, error) {
+			return nil, errors.New("offline")
+		}}
+
+		start := time.Now()
+		list := func(language *languages.Language) ([]string, error) {
+			return language.ListRemote()
+		}
+		results := fetchAll([]*languages.Language{slow, fast, broken}, 2, 50*time.Millisecond, list)
+		So(time.Since(start), ShouldBeLessThan, 500*time.Millisecond)
+		So(results[0].err, ShouldNotBeNil)
+		So(results[1].versions, ShouldResemble, []string{"2.0.0", "1.0.0"})
+		So(results[2].err.Error(), ShouldEqual, "offline")
+	})
+}
+
+func TestNormalizePrevious(t *testing.T) {
+	Convey("只有 active 的 - 表示上一个版本，uninstall 的 - 不是版本号", t, func() {
+		set := flag.NewFlagSet("uninstall", flag.ContinueOnError)
+		So(set.Parse([]string{"-"}), ShouldBeNil)
+		ctx := cli.NewContext(cli.NewApp(), set, nil)
+		ctx.Command = cli.Command{Name: "uninstall"}
+
+		called := false
+		err := Normalize(config.GO, false, func(ctx *cli.Context) error {
+			called = true
+			return nil
+		})(ctx)
+		So(err, ShouldNotBeNil)
+		So(called, ShouldBeFalse)
+	})
+}
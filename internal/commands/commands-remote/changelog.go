@@ -0,0 +1,41 @@
+package commands_remote
+
+import (
+	"fmt"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/logic/changelog"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// ChangelogFlags 语言的 changelog 命令的参数
+var ChangelogFlags = []cli.Flag{
+	cli.BoolFlag{Name: "open", Usage: "open the release notes in the browser"},
+}
+
+// Changelog 返回语言的 changelog 命令，输出版本的发布说明地址，--open 时在浏览器中打开
+// 省略版本时使用当前激活的版本，部分版本号(1.22)按远程版本列表解析为最新的匹配版本
+func Changelog(name string) func(ctx *cli.Context) error {
+	return Normalize(name, true, func(ctx *cli.Context) error {
+		version := ctx.Args().First()
+		if version == "" {
+			version = languages.Find(name).CurrentVersion()
+		}
+		if version == "" {
+			return cli.NewExitError(fmt.Sprintf("no %s version is active, use envm %s changelog <version>", name, name), 1)
+		}
+		url, ok := changelog.URL(name, version)
+		if !ok {
+			return cli.NewExitError("release notes of "+name+" are not known", 1)
+		}
+		fmt.Println(url)
+		if ctx.Bool("open") {
+			if err := util.OpenURL(url); err != nil {
+				return common.Exit(fmt.Errorf("open browser error + %w", err))
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,149 @@
+package commands_remote
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/index"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+const (
+	// RefreshCommand 后台刷新缓存的隐藏命令
+	RefreshCommand = "refresh-index"
+	// refreshLock 记录上一次启动后台刷新的时间，避免连续的命令重复启动
+	refreshLock = ".refresh"
+	// refreshInterval 两次后台刷新之间的最短间隔
+	refreshInterval = 10 * time.Minute
+)
+
+// Latest 包装语言的 install 命令，版本为 latest 时安装远程版本列表中最新的正式版本，其它版本由 Normalize 规范化
+// 远程版本列表优先使用缓存，配置了 prefetch 时缓存由后台刷新，通常不需要等待网络
+// 版本没有安装时先检查安装目录及 symlink 所在目录的写入权限，再开始下载
+// --json 时见 events
+func Latest(name string, action func(ctx *cli.Context) error) func(ctx *cli.Context) error {
+	install := latest(name, action)
+	return func(ctx *cli.Context) error {
+		if ctx.Bool("json") {
+			return events(name, ctx, action)
+		}
+		return install(ctx)
+	}
+}
+
+// InstallFlags 语言的 install 命令的 flag
+var InstallFlags = []cli.Flag{
+	cli.BoolFlag{Name: "json", Usage: "print newline delimited JSON events (resolve, download-progress, verify, extract, done) instead of the progress bar"},
+}
+
+// events 以 JSON lines 在 stdout 输出安装过程的事件，最后输出 done，其它输出都转到 stderr
+// 命令结束后进程即退出，不恢复 stdout
+func events(name string, ctx *cli.Context, action func(ctx *cli.Context) error) error {
+	util.Events, util.Quiet = os.Stdout, true
+	os.Stdout, util.Output = os.Stderr, os.Stderr
+	version := ctx.Args().First()
+	err := latest(name, func(ctx *cli.Context) error {
+		version = ctx.Args().First()
+		return action(ctx)
+	})(ctx)
+	done := util.Event{Event: util.EventDone, Language: name, Version: version}
+	if err != nil {
+		done.Error = err.Error()
+	} else {
+		done.Dir = languages.Find(name).InstallDir(version)
+	}
+	util.Emit(done)
+	return err
+}
+
+// latest 处理 latest 及版本规范化后执行 install 命令
+func latest(name string, action func(ctx *cli.Context) error) func(ctx *cli.Context) error {
+	action = preflight(name, action)
+	normalized := Normalize(name, true, action)
+	return func(ctx *cli.Context) error {
+		if ctx.Args().First() != "latest" {
+			return normalized(ctx)
+		}
+		language := languages.Find(name)
+		done := util.Phase(util.PhaseResolve)
+		versions, err := language.RemoteVersions(false)
+		done()
+		if err != nil {
+			return common.Exit(fmt.Errorf("collect version error + %w", err))
+		}
+		version := index.Latest(versions)
+		if version == "" {
+			return cli.NewExitError("can not find the latest version of "+name, 1)
+		}
+		fmt.Printf("latest %s is %s\n", name, version)
+
+		resolved, err := withVersion(ctx, version)
+		if err != nil {
+			return err
+		}
+		return action(resolved)
+	}
+}
+
+// preflight 包装 install 命令，已经安装的版本不检查权限，由 action 提示已经下载
+func preflight(name string, action func(ctx *cli.Context) error) func(ctx *cli.Context) error {
+	return func(ctx *cli.Context) error {
+		language := languages.Find(name)
+		if version := ctx.Args().First(); version != "" && !common.IsInstalled(language.Link().Downloads, language.Prefix, version) {
+			if err := language.Preflight(); err != nil {
+				return common.Exit(err)
+			}
+		}
+		return action(ctx)
+	}
+}
+
+// stale 返回缓存已经过期的语言，从未查询过的语言不包括在内
+func stale() (items []*languages.Language) {
+	for _, language := range languages.All() {
+		if index.Stale(config.IndexDir(), language.Name, config.IndexTTL()) {
+			items = append(items, language)
+		}
+	}
+	return items
+}
+
+// Prefetch 用作 app 的 After，配置了 prefetch 且有过期的缓存时在后台启动 envm refresh-index
+// 只检查缓存文件的修改时间，不影响命令本身的速度
+func Prefetch(ctx *cli.Context) error {
+	settings := config.Default().Settings
+	if !settings.Prefetch || settings.Offline || ctx.Args().First() == RefreshCommand {
+		return nil
+	}
+	if len(stale()) == 0 {
+		return nil
+	}
+	lock := filepath.Join(config.IndexDir(), refreshLock)
+	if info, err := os.Stat(lock); err == nil && time.Since(info.ModTime()) < refreshInterval {
+		return nil
+	}
+	if err := os.WriteFile(lock, nil, 0644); err != nil {
+		return nil
+	}
+	if envm, err := os.Executable(); err == nil {
+		_ = util.StartDetached(envm, RefreshCommand)
+	}
+	return nil
+}
+
+// CommandRefreshIndex 刷新所有过期的远程版本列表缓存
+func CommandRefreshIndex(ctx *cli.Context) error {
+	items := stale()
+	for i, r := range fetchAll(items, 4, time.Minute, refresh) {
+		if r.err != nil {
+			fmt.Printf("refresh %s failed + %v\n", items[i].Name, r.err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package commands_remote
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/history"
+	"github.com/FirewineXie/envm/internal/logic/normalize"
+	"github.com/FirewineXie/envm/internal/logic/suggest"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// Normalize 包装语言的 install/active/uninstall 命令，执行前规范化第一个参数中的版本号
+// go1.22.3、v20.12.2、jdk-17 等写法去掉前缀，格式错误时直接给出建议，不再进入下载流程
+// 只写了部分版本号(1.22、17)时，remote 为 true 从远程版本列表中选择最新的匹配版本，否则从已经安装的版本中选择
+// active 的 - 表示审计日志中上一次切换到的版本，见 previous；其它命令(uninstall 等)的 - 不是版本号，直接报错
+func Normalize(name string, remote bool, action func(ctx *cli.Context) error) func(ctx *cli.Context) error {
+	return func(ctx *cli.Context) error {
+		input := ctx.Args().First()
+		if input == "" || normalize.Keywords[input] {
+			return action(ctx)
+		}
+		if input == "-" {
+			if remote || ctx.Command.Name != "active" {
+				return cli.NewExitError(fmt.Sprintf("- is not a version, only envm %s active - switches to the previous version", name), 1)
+			}
+			version, err := previous(name)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "%s - -> %s\n", name, version)
+			if ctx, err = withVersion(ctx, version); err != nil {
+				return err
+			}
+			return action(ctx)
+		}
+		language := languages.Find(name)
+		// envm link 登记的名称(custom-pgo)不是版本号，原样使用
+		if !remote && common.IsInstalled(language.Link().Downloads, language.Prefix, input) {
+			return action(ctx)
+		}
+		version, err := normalize.Clean(name, input)
+		if err != nil {
+			return common.Exit(err)
+		}
+
+		var candidates []string
+		if remote {
+			// 部分语言的远程列表只包含较新的版本，查不到时原样交给安装流程
+			candidates, _ = language.RemoteVersions(false)
+		} else {
+			candidates = common.GetInstalled(language.Link().Downloads, language.Prefix)
+		}
+		found := false
+		if resolved, ok := normalize.Resolve(version, candidates); ok {
+			version, found = resolved, true
+		} else if !remote && len(candidates) > 0 {
+			return cli.NewExitError(fmt.Sprintf("%s %s is not installed%s installed versions: %s",
+				name, version, suggestion(version, candidates), strings.Join(candidates, ", ")), 1)
+		}
+		if version != input {
+			// 输出到 stderr，active --session 的 stdout 会被 eval
+			fmt.Fprintf(os.Stderr, "%s %s -> %s\n", name, input, version)
+			if ctx, err = withVersion(ctx, version); err != nil {
+				return err
+			}
+		}
+		err = action(ctx)
+		if !found && len(candidates) > 0 && errors.Is(err, util.ErrVersionNotFound) {
+			return common.Exit(fmt.Errorf("%s %s: %w%s", name, version, err, suggest.Message(suggest.Closest(version, candidates, 3))))
+		}
+		return err
+	}
+}
+
+// suggestion 返回 ", did you mean x?"，没有接近的版本时返回 ","
+func suggestion(version string, candidates []string) string {
+	if message := suggest.Message(suggest.Closest(version, candidates, 3)); message != "" {
+		return message
+	}
+	return ","
+}
+
+// withVersion 将第一个参数替换为 version 后重新构造 context，保留命令行中设置的 flag
+func withVersion(ctx *cli.Context, version string) (*cli.Context, error) {
+	set := flag.NewFlagSet(ctx.Command.Name, flag.ContinueOnError)
+	for _, f := range ctx.Command.Flags {
+		f.Apply(set)
+	}
+	for _, name := range ctx.FlagNames() {
+		if ctx.IsSet(name) {
+			if err := set.Set(name, ctx.String(name)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := set.Parse(append([]string{version}, ctx.Args().Tail()...)); err != nil {
+		return nil, err
+	}
+	resolved := cli.NewContext(ctx.App, set, ctx.Parent())
+	resolved.Command = ctx.Command
+	return resolved, nil
+}
+
+// previous 审计日志中 name 最近一次切换到的、不是当前版本的版本
+func previous(name string) (string, error) {
+	records, err := history.Read(config.HistoryFile(), history.Filter{Language: name, Action: history.ActionActivate})
+	if err != nil {
+		return "", common.Exit(fmt.Errorf("read history error + %w", err))
+	}
+	version := history.Previous(records, name, languages.Find(name).CurrentVersion())
+	if version == "" {
+		return "", cli.NewExitError("no previous "+name+" version recorded in envm history", 1)
+	}
+	return version, nil
+}
@@ -0,0 +1,47 @@
+package commands_remote
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/logic/tabular"
+	"github.com/urfave/cli"
+)
+
+// OutputFlag 列表命令的输出格式
+var OutputFlag = cli.StringFlag{Name: "output, o", Value: tabular.Table, Usage: "table, or tsv/csv with a header line for cut, awk and spreadsheets"}
+
+// ListingFlags ls 命令的 flag
+var ListingFlags = []cli.Flag{OutputFlag}
+
+// FilterFlags lsr 及 ls-remote 过滤远程版本的参数
+var FilterFlags = []cli.Flag{
+	cli.StringFlag{Name: "os", Usage: "only list versions with a package for the os, e.g. linux, darwin, windows"},
+	cli.StringFlag{Name: "arch", Usage: "only list versions with a package for the arch, e.g. amd64, arm64"},
+	cli.StringFlag{Name: "kind", Usage: "only list versions of the kind, e.g. lts of node, archived of go"},
+	cli.IntFlag{Name: "limit", Value: 20, Usage: "number of versions shown, 0 lists all"},
+	cli.StringFlag{Name: "sort", Usage: "asc or desc by version number, the order of the source by default"},
+}
+
+// Listing 包装语言的 ls 命令，--output 为 tsv/csv 时每行输出 语言、版本、是否为当前版本，table 时使用原来的输出
+func Listing(name string, action func(ctx *cli.Context)) func(ctx *cli.Context) error {
+	return func(ctx *cli.Context) error {
+		format := ctx.String("output")
+		if err := tabular.Check(format); err != nil {
+			return cli.NewExitError(err.Error(), 1)
+		}
+		if format == "" || format == tabular.Table {
+			action(ctx)
+			return nil
+		}
+		language := languages.Find(name)
+		current := language.CurrentVersion()
+		rows := make([][]string, 0)
+		for _, version := range common.GetInstalled(language.Link().Downloads, language.Prefix) {
+			rows = append(rows, []string{language.Name, version, strconv.FormatBool(version == current)})
+		}
+		return tabular.Write(os.Stdout, format, []string{"language", "version", "current"}, rows)
+	}
+}
@@ -0,0 +1,142 @@
+package commands_remote
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// ProtectFlags uninstall 命令的 flag
+var ProtectFlags = []cli.Flag{
+	cli.BoolFlag{Name: "force, f", Usage: "uninstall even if recently visited projects still declare the version"},
+	cli.BoolFlag{Name: "prune", Usage: "uninstall every version except the active one and those recently visited projects declare"},
+}
+
+// Protect 包装语言的 uninstall 命令，shell hook 最近看到的项目中仍然声明了该版本时要求 --force
+// 当前激活的版本(全局默认)由各语言的 uninstall 命令直接拒绝，--force 也不能卸载
+// 指定版本时卸载前需要确认(--yes 跳过)，没有指定版本时列出已经安装的版本供多选，见 choose；--prune 见 prune
+func Protect(name string, action func(ctx *cli.Context) error) func(ctx *cli.Context) error {
+	return func(ctx *cli.Context) error {
+		version := ctx.Args().First()
+		if ctx.Bool("prune") {
+			if version != "" {
+				return cli.NewExitError("--prune can not be used with a version", 1)
+			}
+			return prune(name, ctx, action)
+		}
+		if version == "" {
+			return choose(name, ctx, action)
+		}
+		if err := protected(name, version, ctx.Bool("force")); err != nil {
+			return err
+		}
+		// 没有安装或者正在使用的版本由 uninstall 命令报错，不需要询问
+		language := languages.Find(name)
+		if common.IsInstalled(language.Link().Downloads, language.Prefix, version) && version != language.CurrentVersion() &&
+			!util.ConfirmRemoval(fmt.Sprintf("uninstall %s %s?", name, version)) {
+			return cli.NewExitError("uninstall canceled", 1)
+		}
+		return action(ctx)
+	}
+}
+
+// protected 版本仍然被项目声明且没有 --force 时返回错误
+func protected(name, version string, force bool) error {
+	if force {
+		return nil
+	}
+	if files := languages.Find(name).References(version); len(files) > 0 {
+		return cli.NewExitError(fmt.Sprintf("%s %s is still declared in:\n  %s\nuse --force to uninstall it anyway",
+			name, version, strings.Join(files, "\n  ")), 1)
+	}
+	return nil
+}
+
+// choose 列出已经安装的版本及占用的空间，当前激活的版本不能选择，依次卸载选中的版本
+func choose(name string, ctx *cli.Context, action func(ctx *cli.Context) error) error {
+	language := languages.Find(name)
+	installed := common.GetInstalled(language.Link().Downloads, language.Prefix)
+	if len(installed) == 0 {
+		fmt.Println("No installations recognized.")
+		return nil
+	}
+	current := language.CurrentVersion()
+	items := make([]string, len(installed))
+	locked := map[int]bool{}
+	for i, version := range installed {
+		items[i] = fmt.Sprintf("%-12s %8.1f MB", version, float64(dirSize(language.InstallDir(version)))/1024/1024)
+		if version == current {
+			items[i] += "  (active)"
+			locked[i] = true
+		}
+	}
+	chosen := util.Choose("installed "+name+" versions:", items, locked)
+	versions := make([]string, 0, len(chosen))
+	for _, i := range chosen {
+		versions = append(versions, installed[i])
+	}
+	return uninstallAll(name, versions, ctx, action)
+}
+
+// prune 卸载除当前激活的版本及最近访问的项目仍然声明的版本(--force 时同样卸载)之外的所有版本，卸载前确认一次
+func prune(name string, ctx *cli.Context, action func(ctx *cli.Context) error) error {
+	language := languages.Find(name)
+	current := language.CurrentVersion()
+	versions := make([]string, 0)
+	for _, version := range common.GetInstalled(language.Link().Downloads, language.Prefix) {
+		if version != current && protected(name, version, ctx.Bool("force")) == nil {
+			versions = append(versions, version)
+		}
+	}
+	if len(versions) == 0 {
+		fmt.Println("nothing to prune")
+		return nil
+	}
+	if !util.ConfirmRemoval(fmt.Sprintf("uninstall %s %s?", name, strings.Join(versions, ", "))) {
+		return cli.NewExitError("uninstall canceled", 1)
+	}
+	return uninstallAll(name, versions, ctx, action)
+}
+
+// uninstallAll 依次卸载 versions，某个版本失败时继续卸载其它版本
+func uninstallAll(name string, versions []string, ctx *cli.Context, action func(ctx *cli.Context) error) error {
+	failed := 0
+	for _, version := range versions {
+		fmt.Printf("==> uninstall %s %s\n", name, version)
+		err := protected(name, version, ctx.Bool("force"))
+		if err == nil {
+			var resolved *cli.Context
+			if resolved, err = withVersion(ctx, version); err == nil {
+				err = action(resolved)
+			}
+		}
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "uninstall %s %s failed + %v\n", name, version, err)
+		}
+	}
+	if failed > 0 {
+		return cli.NewExitError(fmt.Sprintf("%d versions failed to uninstall", failed), 1)
+	}
+	return nil
+}
+
+// dirSize 目录中所有文件的大小，无法读取的文件被忽略
+func dirSize(dir string) (size int64) {
+	_ = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && entry.Type().IsRegular() {
+			if info, err := entry.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
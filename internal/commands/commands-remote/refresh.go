@@ -0,0 +1,141 @@
+package commands_remote
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/index"
+	"github.com/FirewineXie/envm/internal/logic/schedule"
+	"github.com/urfave/cli"
+)
+
+// securitySuffix 安全修复版本列表的缓存名称后缀，见 languages.SecurityReleases
+const securitySuffix = "-security"
+
+// RefreshFlags envm refresh 的参数
+var RefreshFlags = []cli.Flag{
+	cli.BoolFlag{Name: "all", Usage: "refresh every language, not only the installed ones queried before"},
+	cli.BoolFlag{Name: "stale", Usage: "refresh only the version lists older than index_ttl"},
+	cli.DurationFlag{Name: "jitter", Usage: "wait a random time up to this long before going to the network, for scheduled runs"},
+	cli.DurationFlag{Name: "prune-after", Value: 30 * 24 * time.Hour, Usage: "remove the version lists not refreshed for this long instead of refreshing them"},
+	cli.IntFlag{Name: "jobs", Value: 4, Usage: "number of sources queried at the same time"},
+	cli.DurationFlag{Name: "timeout", Value: time.Minute, Usage: "timeout of each source"},
+	cli.StringFlag{Name: "schedule", Usage: "print a " + strings.Join(schedule.Formats, "|") + " entry running envm refresh periodically instead of refreshing"},
+	cli.DurationFlag{Name: "every", Usage: "interval of --schedule, 1h to 24h, defaults to half of index_ttl"},
+}
+
+// CommandRefresh 清理过期的远程版本列表缓存后刷新其余的缓存，定时执行后交互式的命令不需要等待网络
+// 默认只刷新查询过且有已安装版本的语言，其它语言的缓存不再刷新，超过 --prune-after 后删除
+// --schedule 时输出定时执行本命令的配置，每台机器在整点后的随机分钟执行，并附带 --jitter，避免同时访问镜像
+func CommandRefresh(ctx *cli.Context) error {
+	if format := ctx.String("schedule"); format != "" {
+		return printSchedule(ctx, format)
+	}
+	dir := config.IndexDir()
+	removed, err := index.Prune(dir, known, ctx.Duration("prune-after"))
+	for _, name := range removed {
+		fmt.Printf("pruned %s\n", name)
+	}
+	if err != nil {
+		return common.Exit(fmt.Errorf("prune %s error + %w", dir, err))
+	}
+	if config.Default().Settings.Offline {
+		fmt.Println("offline is set, the version lists are not refreshed")
+		return nil
+	}
+
+	items := refreshItems(ctx.Bool("all"), ctx.Bool("stale"))
+	if len(items) == 0 {
+		fmt.Println("nothing to refresh")
+		return nil
+	}
+	if jitter := ctx.Duration("jitter"); jitter > 0 {
+		time.Sleep(time.Duration(rand.Int63n(int64(jitter))))
+	}
+	failed := 0
+	for i, r := range fetchAll(items, ctx.Int("jobs"), ctx.Duration("timeout"), refreshAll) {
+		if r.err != nil {
+			failed++
+			fmt.Printf("refresh %s failed + %v\n", items[i].Name, r.err)
+			continue
+		}
+		fmt.Printf("refreshed %s, %d versions\n", items[i].Name, len(r.versions))
+	}
+	if failed > 0 {
+		return cli.NewExitError(fmt.Sprintf("%d sources failed", failed), 1)
+	}
+	return nil
+}
+
+// known 缓存名称是否为支持的语言或其安全修复版本列表
+func known(name string) bool {
+	return languages.Find(strings.TrimSuffix(name, securitySuffix)) != nil
+}
+
+// refreshItems 返回需要刷新的语言，all 为 false 时只包括有缓存且有已安装版本的语言，stale 时只包括已经过期的语言
+func refreshItems(all, stale bool) (items []*languages.Language) {
+	cached := make(map[string]bool)
+	for _, name := range index.Cached(config.IndexDir()) {
+		cached[name] = true
+	}
+	for _, language := range languages.All() {
+		if !all && (!cached[language.Name] || len(common.GetInstalled(language.Link().Downloads, language.Prefix)) == 0) {
+			continue
+		}
+		if stale && cached[language.Name] && !index.Stale(config.IndexDir(), language.Name, config.IndexTTL()) {
+			continue
+		}
+		items = append(items, language)
+	}
+	return items
+}
+
+// refreshAll 刷新版本列表，缓存过安全修复版本列表时一起刷新
+func refreshAll(language *languages.Language) ([]string, error) {
+	versions, err := language.RemoteVersions(true)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = index.Read(config.IndexDir(), language.Name+securitySuffix); err == nil {
+		if _, err = language.SecurityReleases(true); err != nil {
+			return nil, fmt.Errorf("refresh the security releases error + %w", err)
+		}
+	}
+	return versions, nil
+}
+
+// printSchedule 输出定时执行 envm refresh 的配置
+func printSchedule(ctx *cli.Context, format string) error {
+	envm, err := os.Executable()
+	if err != nil {
+		return common.Exit(err)
+	}
+	every := ctx.Duration("every")
+	if every == 0 {
+		every = (config.IndexTTL() / 2).Round(time.Hour)
+		every = max(time.Hour, min(every, 24*time.Hour))
+	}
+	jitter := ctx.Duration("jitter")
+	if jitter == 0 {
+		jitter = 10 * time.Minute
+	}
+	job := schedule.Job{
+		Name:        "envm-refresh",
+		Command:     []string{envm, "refresh", "--jitter", jitter.String()},
+		Every:       every,
+		Minute:      rand.Intn(60),
+		Description: "refresh the envm remote version lists",
+	}
+	out, err := schedule.Format(format, job)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	fmt.Print(out)
+	return nil
+}
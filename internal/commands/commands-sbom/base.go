@@ -0,0 +1,73 @@
+package commands_sbom
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/FirewineXie/envm/internal/commands/commands-tool"
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/history"
+	"github.com/FirewineXie/envm/internal/logic/sbom"
+	web_tool "github.com/FirewineXie/envm/internal/logic/web-tool"
+	"github.com/urfave/cli"
+)
+
+// CommandSbom 输出所有已安装的语言及工具版本的物料清单，默认为 CycloneDX 格式
+// 下载地址及校验和来自审计日志中最近一次安装的记录，审计日志之前安装的版本没有这两项
+func CommandSbom(ctx *cli.Context) error {
+	records, err := installs()
+	if err != nil {
+		return common.Exit(fmt.Errorf("read history error + %w", err))
+	}
+	var components []sbom.Component
+	add := func(name, version, supplier string) {
+		record := records[name+"@"+version]
+		components = append(components, sbom.Component{Name: name, Version: version, Supplier: supplier,
+			Checksum: record.Checksum, URL: record.Source})
+	}
+	for _, language := range languages.All() {
+		for _, version := range common.GetInstalled(language.Link().Downloads, language.Prefix) {
+			add(language.Name, version, language.Supplier)
+		}
+	}
+	for _, tool := range web_tool.All() {
+		// github 发布的工具以仓库的 owner 作为发布者
+		supplier, _, _ := strings.Cut(tool.Repo, "/")
+		for _, version := range commands_tool.Installed(tool) {
+			add(tool.Name, version, supplier)
+		}
+	}
+
+	doc := sbom.Document{Tool: ctx.App.Name, ToolVersion: ctx.App.Version, Created: time.Now(), Components: components}
+	data, err := sbom.Encode(doc, ctx.String("format"))
+	if err != nil {
+		return common.Exit(err)
+	}
+	data = append(data, '\n')
+	if output := ctx.String("output"); output != "" {
+		if err = os.WriteFile(output, data, 0644); err != nil {
+			return common.Exit(err)
+		}
+		fmt.Printf("write %d components to %s\n", len(components), output)
+		return nil
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
+
+// installs 返回每个版本最近一次的安装记录，键为 <language>@<version>
+func installs() (map[string]history.Record, error) {
+	records, err := history.Read(config.HistoryFile(), history.Filter{Action: history.ActionInstall})
+	if err != nil {
+		return nil, err
+	}
+	latest := make(map[string]history.Record, len(records))
+	for _, record := range records {
+		latest[record.Language+"@"+record.Version] = record
+	}
+	return latest, nil
+}
@@ -0,0 +1,180 @@
+package commands_serve
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/logic/api"
+	"github.com/urfave/cli"
+)
+
+// Flags envm serve 的参数
+var Flags = []cli.Flag{
+	cli.StringFlag{Name: "listen", Value: "127.0.0.1:0", Usage: "address to listen on, port 0 picks a free port"},
+	cli.StringFlag{Name: "token", Usage: "token required in Authorization: Bearer <token>, a random one is generated by default", EnvVar: "ENVM_SERVE_TOKEN"},
+}
+
+// started 启动后在 stdout 输出的一行 JSON，插件据此得到实际监听的地址及 token
+type started struct {
+	URL   string `json:"url"`
+	Token string `json:"token"`
+	PID   int    `json:"pid"`
+}
+
+// CommandServe 在本地提供 HTTP JSON 接口(见 api 包)，供编辑器插件及图形界面调用
+// 安装及切换在子进程 envm <language> install --json 及 envm <language> active 中执行，与命令行的行为完全相同
+func CommandServe(ctx *cli.Context) error {
+	token := ctx.String("token")
+	if token == "" {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return common.Exit(err)
+		}
+		token = hex.EncodeToString(b)
+	}
+	envm, err := os.Executable()
+	if err != nil {
+		return common.Exit(err)
+	}
+	l, err := net.Listen("tcp", ctx.String("listen"))
+	if err != nil {
+		return common.Exit(fmt.Errorf("listen on %s error + %w", ctx.String("listen"), err))
+	}
+	if addr, ok := l.Addr().(*net.TCPAddr); ok && !addr.IP.IsLoopback() {
+		fmt.Fprintf(os.Stderr, "envm: listening on %s which is not a loopback address, anyone with the token can install and switch versions\n", addr)
+	}
+
+	server := &http.Server{Handler: api.Handler(&backend{envm: envm}, token), ReadHeaderTimeout: 10 * time.Second}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		<-signals
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdown)
+	}()
+
+	line, _ := json.Marshal(started{URL: "http://" + l.Addr().String() + "/" + api.Version, Token: token, PID: os.Getpid()})
+	fmt.Println(string(line))
+	if err = server.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return common.Exit(err)
+	}
+	return nil
+}
+
+// backend 基于 languages 实现 api.Backend
+type backend struct {
+	envm string
+}
+
+func (b *backend) find(name string) (*languages.Language, error) {
+	language := languages.Find(name)
+	if language == nil {
+		return nil, fmt.Errorf("%w %s", api.ErrUnknownLanguage, name)
+	}
+	return language, nil
+}
+
+func (b *backend) Languages() []api.Language {
+	items := make([]api.Language, 0, len(languages.All()))
+	for _, language := range languages.All() {
+		items = append(items, api.Language{Name: language.Name, Current: language.CurrentVersion()})
+	}
+	return items
+}
+
+func (b *backend) Installed(name string) (*api.Installed, error) {
+	language, err := b.find(name)
+	if err != nil {
+		return nil, err
+	}
+	versions := common.GetInstalled(language.Link().Downloads, language.Prefix)
+	if versions == nil {
+		versions = []string{}
+	}
+	return &api.Installed{Language: language.Name, Current: language.CurrentVersion(), Versions: versions}, nil
+}
+
+func (b *backend) Remote(name string, refresh bool) (*api.Remote, error) {
+	language, err := b.find(name)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := language.RemoteVersions(refresh)
+	if err != nil {
+		return nil, err
+	}
+	return &api.Remote{Language: language.Name, Versions: versions}, nil
+}
+
+// Install 转发子进程输出的事件，客户端断开时终止子进程
+func (b *backend) Install(ctx context.Context, name, version string, emit func(event string, data []byte)) error {
+	language, err := b.find(name)
+	if err != nil {
+		return err
+	}
+	// -- 之后的参数不会被当作 flag 解析
+	cmd := exec.CommandContext(ctx, b.envm, language.Name, "install", "--json", "--", version)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err = cmd.Start(); err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		var event struct {
+			Event string `json:"event"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &event) != nil || event.Event == "" {
+			continue
+		}
+		emit(event.Event, scanner.Bytes())
+	}
+	if err = cmd.Wait(); err != nil {
+		return commandError(err, stderr.String())
+	}
+	return nil
+}
+
+func (b *backend) Use(name, version string) (*api.Switched, error) {
+	language, err := b.find(name)
+	if err != nil {
+		return nil, err
+	}
+	output, err := exec.Command(b.envm, language.Name, "active", "--", version).CombinedOutput()
+	if err != nil {
+		return nil, commandError(err, string(output))
+	}
+	// version 可能是 1.22 等部分版本号，返回实际切换到的版本
+	return &api.Switched{Language: language.Name, Version: language.CurrentVersion()}, nil
+}
+
+// commandError 子进程失败时使用其输出的最后一行作为错误
+func commandError(err error, output string) error {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+		return errors.New(last)
+	}
+	return err
+}
@@ -0,0 +1,215 @@
+package commands_shim
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// indexName shims 目录中记录可执行文件所属语言的索引
+const indexName = ".index.json"
+
+// CommandRehash 根据已经安装的版本重新生成 shims
+func CommandRehash(ctx *cli.Context) error {
+	count, err := Rehash()
+	if err != nil {
+		return common.Exit(fmt.Errorf("rehash error + %w", err))
+	}
+	if !config.Default().Settings.BinPerLanguage {
+		fmt.Printf("%d shims in %s\n", count, config.ShimsDir())
+		return nil
+	}
+	fmt.Printf("%d shims, add the directory of each language to PATH\n", count)
+	for _, dir := range Dirs() {
+		fmt.Println("  " + dir)
+	}
+	return nil
+}
+
+// Dirs 返回已经生成的 shims 目录，bin_per_language 时每个已安装的语言一个
+func Dirs() (dirs []string) {
+	for _, language := range languages.All() {
+		dir := config.LanguageShimsDir(language.Name)
+		if exists, _ := util.PathExists(dir); exists && (len(dirs) == 0 || dirs[len(dirs)-1] != dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// Rehash 为所有已安装版本中的可执行文件生成 shim，并删除不再需要的 shim
+// bin_per_language 时每个语言的 shims 写入各自的目录，切换布局后删除另一种布局中的 shims
+func Rehash() (int, error) {
+	envm, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+
+	// 共用的 shims 目录即使为空也创建，与之前的行为一致
+	indexes := map[string]map[string]string{}
+	if !config.Default().Settings.BinPerLanguage {
+		indexes[config.ShimsDir()] = map[string]string{}
+	}
+	for _, language := range languages.All() {
+		dir := config.LanguageShimsDir(language.Name)
+		for _, version := range common.GetInstalled(language.Link().Downloads, language.Prefix) {
+			for _, bin := range language.BinDirs(version) {
+				for _, name := range Executables(bin) {
+					if indexes[dir] == nil {
+						indexes[dir] = map[string]string{}
+					}
+					if _, ok := indexes[dir][name]; !ok {
+						indexes[dir][name] = language.Name
+					}
+				}
+			}
+		}
+	}
+
+	candidates := []string{config.ShimsDir()}
+	for _, language := range languages.All() {
+		candidates = append(candidates, config.LanguageBinDir(language.Name))
+	}
+	for _, dir := range candidates {
+		if _, ok := indexes[dir]; !ok {
+			removeShims(dir)
+		}
+	}
+	count := 0
+	for dir, index := range indexes {
+		if err = writeShims(dir, index, envm); err != nil {
+			return 0, err
+		}
+		count += len(index)
+	}
+	return count, nil
+}
+
+// writeShims 在 dir 中生成 index 中的 shims 并删除不再需要的 shim
+func writeShims(dir string, index map[string]string, envm string) error {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	old, _ := readIndex(dir)
+	for name := range old {
+		if _, ok := index[name]; !ok {
+			_ = os.Remove(shimPath(dir, name))
+		}
+	}
+	for name := range index {
+		if err := writeShim(dir, name, envm); err != nil {
+			return err
+		}
+	}
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, indexName), data, 0644)
+}
+
+// removeShims 删除 dir 中由 envm 生成的 shims，目录为空时一起删除，不删除其它文件
+func removeShims(dir string) {
+	index, err := readIndex(dir)
+	if err != nil {
+		return
+	}
+	for name := range index {
+		_ = os.Remove(shimPath(dir, name))
+	}
+	_ = os.Remove(filepath.Join(dir, indexName))
+	if os.Remove(dir) == nil && filepath.Base(dir) == "bin" {
+		// <root>/<language>/bin 的上一级目录只用于 shims
+		_ = os.Remove(filepath.Dir(dir))
+	}
+}
+
+func readIndex(dir string) (index map[string]string, err error) {
+	data, err := os.ReadFile(filepath.Join(dir, indexName))
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &index)
+	return index, err
+}
+
+// shimLanguage 返回 shim 所属的语言
+// bin_per_language 时同名的可执行文件可能属于多个语言，与 shell 找到 shim 的方式一致，使用 PATH 中第一个包含它的目录
+func shimLanguage(name string) (string, error) {
+	if !config.Default().Settings.BinPerLanguage {
+		index, err := readIndex(config.ShimsDir())
+		if err != nil {
+			return "", err
+		}
+		return index[name], nil
+	}
+	dirs := map[string]string{}
+	for _, language := range languages.All() {
+		dirs[filepath.Clean(config.LanguageBinDir(language.Name))] = language.Name
+	}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if language, ok := dirs[filepath.Clean(dir)]; ok {
+			if index, _ := readIndex(dir); index[name] != "" {
+				return language, nil
+			}
+		}
+	}
+	// 通过绝对路径调用的 shim 不在 PATH 中
+	for _, language := range languages.All() {
+		if index, _ := readIndex(config.LanguageBinDir(language.Name)); index[name] != "" {
+			return language.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// CommandExec 由 shim 调用，按 shell > 项目 > 全局的顺序选择版本并执行
+func CommandExec(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) == 0 {
+		return cli.ShowSubcommandHelp(ctx)
+	}
+	name := args[0]
+	owner, err := shimLanguage(name)
+	if err != nil {
+		return cli.NewExitError("shims index is missing, run envm rehash", 1)
+	}
+	language := languages.Find(owner)
+	if language == nil {
+		return cli.NewExitError(name+" is not managed by envm, run envm rehash", 1)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return common.Exit(err)
+	}
+	version, source := language.Selected(wd)
+	if version == "" {
+		return cli.NewExitError(fmt.Sprintf("no %s version selected, use envm %s use or a project version file", language.Name, language.Name), 1)
+	}
+	if !common.IsInstalled(language.Link().Downloads, language.Prefix, version) {
+		return cli.NewExitError(fmt.Sprintf("%s %s (set by %s) is not installed", language.Name, version, source), 1)
+	}
+	binary, ok := "", false
+	for _, dir := range language.BinDirs(version) {
+		if binary, ok = FindBinary(dir, name); ok {
+			break
+		}
+	}
+	if !ok {
+		return cli.NewExitError(fmt.Sprintf("%s is not found in %s %s", name, language.Name, version), 1)
+	}
+	for name, value := range language.Environ(version) {
+		_ = os.Setenv(name, value)
+	}
+	if err = util.Exec(binary, args); err != nil {
+		return common.Exit(err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package commands_shim
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Executables 返回目录下所有可执行文件的名称
+func Executables(dir string) (names []string) {
+	files, _ := os.ReadDir(dir)
+	for _, file := range files {
+		info, err := os.Stat(filepath.Join(dir, file.Name()))
+		if err != nil || info.IsDir() || info.Mode()&0111 == 0 {
+			continue
+		}
+		names = append(names, file.Name())
+	}
+	return names
+}
+
+func shimPath(dir, name string) string {
+	return filepath.Join(dir, name)
+}
+
+func writeShim(dir, name, envm string) error {
+	quote := func(s string) string {
+		return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+	}
+	script := "#!/bin/sh\nexec " + quote(envm) + " shim-exec " + quote(name) + " \"$@\"\n"
+	return os.WriteFile(shimPath(dir, name), []byte(script), 0755)
+}
+
+// FindBinary 返回目录下名为 name 的可执行文件
+func FindBinary(dir, name string) (string, bool) {
+	binary := filepath.Join(dir, name)
+	info, err := os.Stat(binary)
+	return binary, err == nil && !info.IsDir()
+}
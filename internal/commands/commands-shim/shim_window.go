@@ -0,0 +1,47 @@
+//go:build windows
+
+package commands_shim
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// extensions windows 上可以直接执行的文件后缀
+var extensions = []string{".exe", ".cmd", ".bat"}
+
+// Executables 返回目录下所有可执行文件的名称(不含后缀)
+func Executables(dir string) (names []string) {
+	files, _ := os.ReadDir(dir)
+	for _, file := range files {
+		ext := strings.ToLower(filepath.Ext(file.Name()))
+		for _, e := range extensions {
+			if ext == e && !file.IsDir() {
+				names = append(names, strings.TrimSuffix(file.Name(), filepath.Ext(file.Name())))
+				break
+			}
+		}
+	}
+	return names
+}
+
+func shimPath(dir, name string) string {
+	return filepath.Join(dir, name+".cmd")
+}
+
+func writeShim(dir, name, envm string) error {
+	script := "@echo off\r\n\"" + envm + "\" shim-exec " + name + " %*\r\nexit /b %ERRORLEVEL%\r\n"
+	return os.WriteFile(shimPath(dir, name), []byte(script), 0755)
+}
+
+// FindBinary 返回目录下名为 name 的可执行文件
+func FindBinary(dir, name string) (string, bool) {
+	for _, ext := range extensions {
+		binary := filepath.Join(dir, name+ext)
+		if info, err := os.Stat(binary); err == nil && !info.IsDir() {
+			return binary, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,106 @@
+package commands_stats
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/stats"
+	"github.com/FirewineXie/envm/internal/logic/tabular"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// Record 在每个命令开始前调用，记录安装包的下载统计
+func Record() {
+	util.Downloaded = common.RecordDownload
+}
+
+// CommandStats envm stats [on|off|reset] 显示本地的使用统计，on/off 修改 config.toml 中的 disable_stats，reset 清空统计
+// 统计只保存在本机，从不上传，用于比较镜像的下载速度及版本列表缓存的效果
+func CommandStats(ctx *cli.Context) error {
+	var value string
+	switch ctx.Args().First() {
+	case "":
+		return show()
+	case "on":
+		value = "false"
+	case "off":
+		value = "true"
+	case "reset":
+		if err := stats.Remove(config.StatsFile()); err != nil {
+			return common.Exit(err)
+		}
+		fmt.Println("usage stats are cleared")
+		return nil
+	default:
+		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
+	}
+	if err := config.SetSetting("disable_stats", value); err != nil {
+		return common.Exit(fmt.Errorf("update %s error + %w", config.SettingsFile(), err))
+	}
+	fmt.Printf("usage stats are %s\n", ctx.Args().First())
+	return nil
+}
+
+// show 输出安装次数、版本列表缓存命中率及各域名的平均下载速度
+func show() error {
+	s, err := stats.Read(config.StatsFile())
+	if err != nil {
+		return common.Exit(fmt.Errorf("read %s error + %w", config.StatsFile(), err))
+	}
+	state := "on"
+	if config.Default().Settings.DisableStats {
+		state = "off (envm stats on to record them)"
+	}
+	fmt.Printf("usage stats are %s, they are kept in %s and never uploaded\n", state, config.StatsFile())
+	if s.Since.IsZero() {
+		fmt.Println("nothing is recorded yet")
+		return nil
+	}
+	fmt.Printf("recorded since %s\n", s.Since.Format(time.DateTime))
+
+	fmt.Println("\ninstalls")
+	names := make([]string, 0, len(s.Installs))
+	for name := range s.Installs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	rows := make([][]string, 0, len(names))
+	for _, name := range names {
+		rows = append(rows, []string{name, fmt.Sprint(s.Installs[name])})
+	}
+	if err = write([]string{"LANGUAGE", "INSTALLS"}, rows); err != nil {
+		return err
+	}
+
+	fmt.Println("\nversion list cache")
+	if rate, ok := s.HitRate(); ok {
+		fmt.Printf("%d hits, %d fetches, %.0f%% hit rate (index_ttl in %s)\n", s.IndexHits, s.IndexMisses, rate*100, config.SettingsFile())
+	} else {
+		fmt.Println("no version list was read")
+	}
+
+	fmt.Println("\ndownloads")
+	rows = rows[:0]
+	for _, host := range s.Hosts() {
+		t := s.Downloads[host]
+		rows = append(rows, []string{host, fmt.Sprint(t.Count), fmt.Sprintf("%.1f MB", float64(t.Bytes)/1024/1024), fmt.Sprintf("%.1f MB/s", t.Speed()/1024/1024)})
+	}
+	return write([]string{"HOST", "DOWNLOADS", "SIZE", "AVERAGE SPEED"}, rows)
+}
+
+// write 输出一个表格，没有数据时输出 none
+func write(header []string, rows [][]string) error {
+	if len(rows) == 0 {
+		fmt.Println("none")
+		return nil
+	}
+	if err := tabular.Write(os.Stdout, tabular.Table, header, rows); err != nil {
+		return common.Exit(err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package commands_status
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/logic/tabular"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// CommandStatus 展示当前目录下每个语言选择的版本，只读取本地文件，不访问网络，适合在提示符中调用
+// --porcelain 时每行输出 名称\t版本\t来源类型\t安装状态(installed/missing/broken)\t来源，格式保持稳定，例如:
+// go	1.22.3	project	installed	/home/me/app/.go-version
+// 可以在参数中指定语言只输出部分语言，例如 envm status --porcelain go
+// 默认输出按终端宽度截断来源的中间部分，--wide 输出完整的路径
+func CommandStatus(ctx *cli.Context) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return common.Exit(err)
+	}
+	filter := map[string]bool{}
+	for _, name := range ctx.Args() {
+		language := languages.Find(name)
+		if language == nil {
+			return cli.NewExitError(name+" is not supported by envm", 1)
+		}
+		filter[language.Name] = true
+	}
+
+	porcelain := ctx.Bool("porcelain")
+	rows := make([][]string, 0)
+	for _, item := range languages.Selections(wd) {
+		if len(filter) > 0 && !filter[item.Language.Name] {
+			continue
+		}
+		// 只检查安装目录中的几个文件，不遍历 downloads
+		state := "installed"
+		if installed, _ := util.PathExists(item.Language.InstallDir(item.Version)); !installed {
+			state = "missing"
+		} else if item.Language.Check(item.Version) != nil {
+			state = "broken"
+		}
+		if porcelain {
+			fmt.Printf("%s\t%s\t%s\t%s\t%s\n", item.Language.Name, item.Version, item.Scope, state, item.Source)
+			continue
+		}
+		note := ""
+		switch state {
+		case "missing":
+			note = "not installed"
+		case "broken":
+			note = "broken, run envm doctor"
+		}
+		rows = append(rows, []string{item.Language.Name, item.Version, "(set by " + item.Source + ")", note})
+	}
+	width := util.TerminalWidth()
+	if ctx.Bool("wide") {
+		width = 0
+	}
+	return tabular.Render(os.Stdout, rows, width)
+}
+
+// CommandCurrent 展示全局激活(symlink 指向)的版本
+// symlink 被删除或被改变时根据 state.json 询问是否恢复，指向的目录已经被删除时询问是否改为指向已经安装的最新版本
+func CommandCurrent(ctx *cli.Context) error {
+	items := languages.All()
+	if name := ctx.Args().First(); name != "" {
+		language := languages.Find(name)
+		if language == nil {
+			return cli.NewExitError(name+" is not supported by envm", 1)
+		}
+		items = []*languages.Language{language}
+	}
+	broken := 0
+	for _, language := range items {
+		link := language.Link()
+		ok, err := common.RepairLink(language.Name, link.Downloads, language.Prefix, link.Symlink)
+		if err != nil {
+			return common.Exit(err)
+		}
+		if !ok {
+			broken++
+			continue
+		}
+		if version := language.CurrentVersion(); version != "" {
+			fmt.Printf("%-8s %s\n", language.Name, version)
+		}
+	}
+	if broken > 0 {
+		return cli.NewExitError(fmt.Sprintf("%d broken symlinks", broken), 1)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package commands_status
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/health"
+	"github.com/FirewineXie/envm/internal/logic/normalize"
+	"github.com/urfave/cli"
+)
+
+// CommandCheck 检查 PATH 中实际使用的版本是否与当前目录所在项目声明的版本一致，不一致时输出修复方法并以 1 退出
+// 由 envm generate git-hook 生成的 hook 调用，只读取本地文件，不访问网络
+func CommandCheck(ctx *cli.Context) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return common.Exit(err)
+	}
+	items := languages.Resolve(wd)
+	if len(items) == 0 {
+		fmt.Println("no version is pinned in " + wd)
+		return nil
+	}
+	failed := 0
+	for _, item := range items {
+		source := item.File
+		if rel, err := filepath.Rel(wd, item.File); err == nil {
+			source = rel
+		}
+		title := fmt.Sprintf("%s %s (pinned by %s)", item.Language.Name, item.Requested, source)
+		if normalize.Keywords[item.Requested] {
+			fmt.Printf("  %s: not a version, not checked\n", title)
+			continue
+		}
+		active, binary := activeVersion(item.Language, wd)
+		if problem, fix := compare(item, active, binary); problem != "" {
+			failed++
+			fmt.Printf("! %s: %s\n    fix: %s\n", title, problem, fix)
+			continue
+		}
+		fmt.Printf("  %s: %s is active\n", title, active)
+	}
+	if failed > 0 {
+		return cli.NewExitError(fmt.Sprintf("%d toolchains do not match the project", failed), 1)
+	}
+	return nil
+}
+
+// compare 比较实际使用的版本与项目声明的版本，一致时返回空
+func compare(item languages.Resolved, active, binary string) (problem, fix string) {
+	language := item.Language
+	installed := common.IsInstalled(language.Link().Downloads, language.Prefix, item.Version)
+	switch {
+	case !installed && language.Name == config.JAVA:
+		// jdk 需要手动下载
+		return "not installed", "download and extract it into " + language.InstallDir(item.Version)
+	case !installed:
+		return "not installed", fmt.Sprintf("envm %s install %s", language.Name, item.Requested)
+	case binary == "":
+		return "not in PATH", "add the shims to PATH or enable the shell hook, see envm doctor"
+	case active == "":
+		return binary + " is not managed by envm", "remove it from PATH or put envm's directories before it, see envm doctor"
+	}
+	requested, err := normalize.Clean(language.Name, item.Requested)
+	if err != nil {
+		requested = item.Requested
+	}
+	if _, ok := normalize.Resolve(requested, []string{active}); ok {
+		return "", ""
+	}
+	problem = fmt.Sprintf("%s is active (%s)", active, binary)
+	if env := language.VersionEnv(); os.Getenv(env) != "" {
+		return problem, fmt.Sprintf("unset %s, it overrides the project version in this shell", env)
+	}
+	return problem, fmt.Sprintf("envm %s active %s, or enable the shell hook to switch automatically, see envm hook", language.Name, item.Version)
+}
+
+// activeVersion 返回 PATH 中第一个语言可执行文件的路径及其对应的 envm 版本，不是 envm 管理的文件时版本为空
+// 可执行文件可以来自 shims(按 dir 选择版本)、全局 symlink 或版本目录(envm env、shell hook)
+func activeVersion(language *languages.Language, dir string) (version, binary string) {
+	spec, ok := health.Specs[language.Name]
+	if !ok || len(spec.Binaries) == 0 {
+		return "", ""
+	}
+	name := filepath.Base(spec.Binaries[0])
+	binary, err := exec.LookPath(strings.TrimSuffix(name, filepath.Ext(name)))
+	if err != nil {
+		return "", ""
+	}
+	if binary, err = filepath.Abs(binary); err != nil {
+		return "", ""
+	}
+	link := language.Link()
+	switch binDir := filepath.Dir(binary); {
+	case binDir == filepath.Clean(config.LanguageShimsDir(language.Name)):
+		version, _ = language.Selected(dir)
+	case link.Symlink != "" && within(filepath.Clean(link.Symlink), binDir):
+		version = language.CurrentVersion()
+	case within(filepath.Clean(link.Downloads), binDir):
+		rel, _ := filepath.Rel(link.Downloads, binDir)
+		first, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+		version = strings.TrimPrefix(first, language.Prefix)
+	}
+	return version, binary
+}
+
+// within 判断 path 是否为 dir 或其子路径
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
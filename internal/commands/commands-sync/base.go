@@ -0,0 +1,190 @@
+package commands_sync
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/FirewineXie/envm/internal/commands/commands-dedup"
+	"github.com/FirewineXie/envm/internal/commands/commands-plugin"
+	"github.com/FirewineXie/envm/internal/commands/commands-tool"
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/plugin"
+	"github.com/FirewineXie/envm/internal/logic/project"
+	"github.com/FirewineXie/envm/internal/logic/toolversions"
+	"github.com/FirewineXie/envm/internal/logic/web-tool"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// CommandSync 安装并激活 .tool-versions 中声明的版本，--write 时将当前激活的版本写入 .tool-versions
+func CommandSync(ctx *cli.Context) error {
+	if ctx.Bool("write") {
+		return write()
+	}
+	return apply(toolversions.FileName, "sync", true, true, nil)
+}
+
+// CommandInstall 安装 .envmrc 中声明但还没有安装的版本
+func CommandInstall(ctx *cli.Context) error {
+	return apply(project.EnvmrcName, "install", true, false, nil)
+}
+
+// CommandUse 激活 .envmrc 中声明的所有版本，配置了 auto_install 时先以 quiet 模式安装缺少的版本
+// --porcelain 时所有提示及错误都输出到 stderr，stdout 只为每个激活成功的版本输出一行 名称\t版本，可以在 shell hook 中捕获
+func CommandUse(ctx *cli.Context) error {
+	auto := config.Default().Settings.AutoInstall
+	if auto {
+		util.Quiet = true
+	}
+	var porcelain io.Writer
+	if ctx.Bool("porcelain") {
+		stdout, output := os.Stdout, util.Output
+		os.Stdout, util.Output, util.Quiet = os.Stderr, os.Stderr, true
+		defer func() {
+			os.Stdout, util.Output = stdout, output
+		}()
+		porcelain = stdout
+	}
+	return apply(project.EnvmrcName, "use", auto, true, porcelain)
+}
+
+// apply 从当前目录向上查找 name 指定的版本文件，按需安装及激活其中的每一项
+// porcelain 不为空时每个成功的项输出一行 名称\t版本
+func apply(name, action string, install, activate bool, porcelain io.Writer) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return common.Exit(err)
+	}
+	filename, ok := util.FindUpward(wd, name)
+	if !ok {
+		return cli.NewExitError("can not find "+name, 1)
+	}
+	f, err := toolversions.Read(filename)
+	if err != nil {
+		return common.Exit(err)
+	}
+
+	failed := 0
+	installed := make([]string, 0)
+	for _, entry := range f.Entries() {
+		installFunc, activateFunc := resolve(entry.Name)
+		if installFunc == nil {
+			fmt.Printf("skip %s, not supported by envm\n", entry.Name)
+			continue
+		}
+		err = nil
+		version := resolveVersion(entry.Name, entry.Version(), install, activate)
+		if version != entry.Version() {
+			fmt.Printf("==> %s %s -> %s\n", entry.Name, entry.Version(), version)
+		} else {
+			fmt.Printf("==> %s %s\n", entry.Name, version)
+		}
+		if install {
+			// 只对新安装的语言去重
+			language := languages.Find(entry.Name)
+			fresh := language != nil && !common.IsInstalled(language.Link().Downloads, language.Prefix, version)
+			if fresh {
+				err = language.Preflight()
+			}
+			if !fresh || err == nil {
+				err = installFunc(version)
+			}
+			if err == nil && fresh {
+				installed = append(installed, language.Name)
+			}
+		}
+		if err == nil && activate {
+			err = activateFunc(version)
+		}
+		if err != nil {
+			failed++
+			fmt.Printf("%s %s %s failed + %v\n", action, entry.Name, version, err)
+		} else if porcelain != nil {
+			fmt.Fprintf(porcelain, "%s\t%s\n", entry.Name, version)
+		}
+	}
+	for _, name := range installed {
+		_ = commands_dedup.Auto(name)
+	}
+	if failed > 0 {
+		return cli.NewExitError(fmt.Sprintf("%d tools failed to %s", failed, action), 1)
+	}
+	fmt.Printf("finish %s %s\n", action, filename)
+	return nil
+}
+
+// resolveVersion 解析语言的部分版本号(1.22)，需要激活时优先选择已经安装的最新匹配版本
+// 没有匹配的已安装版本且需要安装时选择远程版本列表中最新的匹配版本，远程版本列表不可用时退回已安装的版本
+// 工具及插件的版本原样返回
+func resolveVersion(name, version string, install, activate bool) string {
+	language := languages.Find(name)
+	if language == nil {
+		return version
+	}
+	resolved := version
+	if activate {
+		resolved = language.InstalledVersion(version)
+	}
+	if install && !common.IsInstalled(language.Link().Downloads, language.Prefix, resolved) {
+		if remote, err := language.ResolveVersion(version); err == nil {
+			resolved = remote
+		}
+	}
+	// 远程版本列表不可用时使用已经安装的匹配版本
+	if installed := language.InstalledVersion(version); resolved == version && installed != version {
+		resolved = installed
+	}
+	return resolved
+}
+
+// resolve 根据 asdf 名称返回安装与激活函数，内置语言优先，其次是工具(如 protoc-gen-go)，最后是同名插件
+func resolve(name string) (install, activate func(version string) error) {
+	if language := languages.Find(name); language != nil {
+		return func(version string) error {
+			return language.Install(context.Background(), version)
+		}, language.Activate
+	}
+	if _, err := web_tool.Find(name); err == nil {
+		return func(version string) error {
+				return commands_tool.Install(name, version)
+			}, func(version string) error {
+				return commands_tool.Activate(name, version)
+			}
+	}
+	if _, err := plugin.Open(config.PluginRoot(), name); err == nil {
+		return func(version string) error {
+				return commands_plugin.Install(name, version)
+			}, func(version string) error {
+				return commands_plugin.Activate(name, version)
+			}
+	}
+	return nil, nil
+}
+
+// write 将当前激活的版本写入当前目录的 .tool-versions，保留文件中的其它内容
+func write() error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return common.Exit(err)
+	}
+	filename := filepath.Join(wd, toolversions.FileName)
+	f, err := toolversions.Read(filename)
+	if err != nil {
+		return common.Exit(err)
+	}
+	for _, language := range languages.All() {
+		if version := language.CurrentVersion(); version != "" {
+			f.Set(language.AsdfName(), version)
+		}
+	}
+	if err = f.Write(filename); err != nil {
+		return common.Exit(err)
+	}
+	fmt.Println("write " + filename)
+	return nil
+}
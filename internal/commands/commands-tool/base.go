@@ -0,0 +1,245 @@
+package commands_tool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/arch"
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/listing"
+	"github.com/FirewineXie/envm/internal/logic/web-tool"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// configLocal Symlink 为工具的 bin 目录，Downloads 下每个工具一个目录
+var configLocal = config.Default().LinkSetting[config.TOOL]
+
+func init() {
+	for _, setting := range config.Default().Settings.Tools {
+		web_tool.Register(&web_tool.Tool{
+			Name:     setting.Name,
+			URL:      setting.URL,
+			Checksum: setting.Checksum,
+			Binary:   setting.Binary,
+			Releases: setting.Versions,
+			OS:       setting.OS,
+			Arch:     setting.Arch,
+			Ext:      setting.Ext,
+		})
+	}
+}
+
+// parseArg 解析 name@version
+func parseArg(ctx *cli.Context) (tool *web_tool.Tool, version string, err error) {
+	name, version, _ := strings.Cut(ctx.Args().First(), "@")
+	if name == "" {
+		return nil, "", errors.New("usage: <name>@<version>")
+	}
+	tool, err = web_tool.Find(name)
+	if err != nil {
+		return nil, "", err
+	}
+	return tool, strings.TrimPrefix(version, "v"), nil
+}
+
+// toolDownloads 工具的安装目录 <downloads>/tool/<name>
+func toolDownloads(tool *web_tool.Tool) string {
+	return filepath.Join(configLocal.Downloads, tool.Name)
+}
+
+// binaryLink 工具在 bin 目录下的链接
+func binaryLink(tool *web_tool.Tool) string {
+	return filepath.Join(configLocal.Symlink, tool.BinaryName(runtime.GOOS))
+}
+
+// CommandList 展示所有支持的工具
+func CommandList(ctx *cli.Context) {
+	for _, tool := range web_tool.All() {
+		if tool.URL != "" {
+			fmt.Printf("%-16s %s\n", tool.Name, tool.URL)
+			continue
+		}
+		fmt.Printf("%-16s github.com/%s\n", tool.Name, tool.Repo)
+	}
+}
+
+// CommandInstall 安装指定版本并链接到 bin 目录
+func CommandInstall(ctx *cli.Context) error {
+	tool, version, err := parseArg(ctx)
+	if err != nil {
+		return common.Exit(err)
+	}
+	if version == "" {
+		return cli.NewExitError("please specify a version, e.g. "+tool.Name+"@1.0.0", 1)
+	}
+	if err = Install(tool.Name, version); err != nil {
+		return common.Exit(err)
+	}
+	if err = Activate(tool.Name, version); err != nil {
+		return common.Exit(err)
+	}
+	fmt.Printf("Installed successfully %s@%s\n", tool.Name, version)
+	return nil
+}
+
+// Install 安装工具的指定版本，已经安装时直接返回
+func Install(name, version string) error {
+	tool, err := web_tool.Find(name)
+	if err != nil {
+		return err
+	}
+	downloads := toolDownloads(tool)
+	if common.IsInstalled(downloads, tool.Name, version) {
+		return nil
+	}
+	// 工具的 symlink 位于 bin 目录中
+	link := config.SubConfig{Symlink: filepath.Join(configLocal.Symlink, tool.Name), Downloads: downloads}
+	if err = common.Preflight(config.TOOL, link); err != nil {
+		return err
+	}
+	if err = install(tool, version, downloads); err != nil {
+		_ = os.RemoveAll(filepath.Join(downloads, tool.Name+version))
+		return fmt.Errorf("install %s error + %w", tool.Name, err)
+	}
+	return nil
+}
+
+func install(tool *web_tool.Tool, version, downloads string) error {
+	pkg, err := common.FindPackage(func(_, goos, goarch string) (*util.Package, error) {
+		return tool.FindPackage(version, goos, goarch)
+	}, runtime.GOOS, arch.Native(), false)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(downloads, os.ModePerm); err != nil {
+		return err
+	}
+	downloadPath, err := common.DownloadPackage(context.Background(), pkg, downloads)
+	if err != nil {
+		return err
+	}
+	defer util.RemoveDownloaded(downloadPath)()
+
+	dir := filepath.Join(downloads, tool.Name+version)
+	binary := filepath.Join(dir, "bin", tool.BinaryName(runtime.GOOS))
+	if pkg.Kind == util.BinaryKind {
+		if err = os.MkdirAll(filepath.Dir(binary), os.ModePerm); err != nil {
+			return err
+		}
+		err = os.Rename(downloadPath, binary)
+	} else {
+		files := map[string]string{pkg.FileName: binary}
+		// protoc 通过可执行文件真实路径的 ../include 查找 well-known types
+		if tool.Include != "" {
+			files[tool.Include] = filepath.Join(dir, "include")
+		}
+		err = common.ExtractFiles(downloadPath, dir+".tmp", files)
+	}
+	if err != nil {
+		return err
+	}
+	if err = os.Chmod(binary, 0755); err != nil {
+		return err
+	}
+	common.RecordInstall(downloads, tool.Name+version, pkg)
+	return nil
+}
+
+// Activate 将 bin 目录下的链接指向已经安装的版本
+func Activate(name, version string) error {
+	tool, err := web_tool.Find(name)
+	if err != nil {
+		return err
+	}
+	if !common.IsInstalled(toolDownloads(tool), tool.Name, version) {
+		return errors.New("you have not install it,please install before use")
+	}
+	return activate(tool, version)
+}
+
+// activate 将 bin 目录下的链接指向指定版本
+func activate(tool *web_tool.Tool, version string) error {
+	if err := os.MkdirAll(configLocal.Symlink, os.ModePerm); err != nil {
+		return err
+	}
+	binary := filepath.Join(toolDownloads(tool), tool.Name+version, "bin", tool.BinaryName(runtime.GOOS))
+	link := binaryLink(tool)
+	_ = os.Remove(link)
+	return util.Symlink(binary, link)
+}
+
+// currentVersion 通过 bin 目录下的链接获取当前版本
+func currentVersion(tool *web_tool.Tool) string {
+	target, err := os.Readlink(binaryLink(tool))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(filepath.Base(filepath.Dir(filepath.Dir(target))), tool.Name)
+}
+
+// CommandUse 切换到已经安装的版本
+func CommandUse(ctx *cli.Context) error {
+	tool, version, err := parseArg(ctx)
+	if err != nil {
+		return common.Exit(err)
+	}
+	if err = Activate(tool.Name, version); err != nil {
+		return common.Exit(err)
+	}
+	fmt.Printf("Now using %s@%s\n", tool.Name, version)
+	return nil
+}
+
+// CommandUninstall 卸载指定版本，不能卸载当前版本，卸载前需要确认
+func CommandUninstall(ctx *cli.Context) error {
+	tool, version, err := parseArg(ctx)
+	if err != nil {
+		return common.Exit(err)
+	}
+	current := currentVersion(tool)
+	if common.IsInstalled(toolDownloads(tool), tool.Name, version) && version != current &&
+		!util.ConfirmRemoval(fmt.Sprintf("uninstall %s %s?", tool.Name, version)) {
+		return cli.NewExitError("uninstall canceled", 1)
+	}
+	if err = common.UninstallVersion(toolDownloads(tool), tool.Name, version, current); err != nil {
+		return common.Exit(fmt.Errorf("删除该版本失败+%w", err))
+	}
+	fmt.Println("finish uninstall")
+	return nil
+}
+
+// Installed 返回工具已经安装的版本
+func Installed(tool *web_tool.Tool) []string {
+	return common.GetInstalled(toolDownloads(tool), tool.Name)
+}
+
+// CommandListInstalled 展示已经安装的工具及版本
+func CommandListInstalled(ctx *cli.Context) {
+	for _, tool := range web_tool.All() {
+		if len(Installed(tool)) == 0 {
+			continue
+		}
+		fmt.Println(tool.Name)
+		common.ListInstalled(toolDownloads(tool), tool.Name, currentVersion(tool))
+	}
+}
+
+// CommandListRemote 获取工具远程的可下载的版本
+func CommandListRemote(ctx *cli.Context) error {
+	tool, _, err := parseArg(ctx)
+	if err != nil {
+		return common.Exit(err)
+	}
+	versions, err := tool.Versions()
+	if err != nil {
+		return common.Exit(fmt.Errorf("collect version error + %w", err))
+	}
+	return common.PrintReleases(ctx, listing.FromVersions(versions), "")
+}
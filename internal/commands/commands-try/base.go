@@ -0,0 +1,224 @@
+package commands_try
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/FirewineXie/envm/internal/commands/commands-env"
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/tabular"
+	"github.com/FirewineXie/envm/internal/logic/trial"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// envTry 试用期间启动的 shell 中设置为 <language>@<version>，可以显示在提示符中
+const envTry = "ENVM_TRY"
+
+// CommandTry 在新的 shell(或 -- 后的命令)中试用某个版本，与 active --session 相同只设置该 shell 的环境变量，不修改 symlink
+// 其它终端、IDE 仍然使用原来的版本，shell 退出后试用结束，--for 到期时结束该 shell
+func CommandTry(ctx *cli.Context) error {
+	if ctx.NArg() == 0 {
+		return list()
+	}
+	pin, duration, command, err := parseArgs(ctx.Args())
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	if pin == "" {
+		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
+	}
+	name, input, _ := strings.Cut(pin, "@")
+	language := languages.Find(name)
+	if language == nil {
+		return cli.NewExitError(name+" is not supported by envm", 1)
+	}
+	if input == "" {
+		return cli.NewExitError(fmt.Sprintf("version is missing, use envm try %s@<version>", name), 1)
+	}
+
+	dir := config.TrialsDir()
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return common.Exit(err)
+	}
+	unlock, locked, err := util.TryLockFile(trial.LockFile(dir, language.Name))
+	if err != nil {
+		return common.Exit(err)
+	}
+	if !locked {
+		return cli.NewExitError(fmt.Sprintf("%s is already being tried, exit the shell of the other envm try first", language.Name), 1)
+	}
+	defer unlock()
+
+	version, err := language.EnsureInstalled(input)
+	if err != nil {
+		return common.Exit(err)
+	}
+	previous := language.CurrentVersion()
+
+	t := &trial.Trial{Language: language.Name, Version: version, Previous: previous, Started: time.Now(), PID: os.Getpid()}
+	if duration > 0 {
+		t.Until = t.Started.Add(duration)
+	}
+	if err = trial.Write(dir, t); err != nil {
+		return common.Exit(fmt.Errorf("record the trial error + %w", err))
+	}
+	defer trial.Remove(dir, language.Name)
+	others := "other shells are not affected"
+	if previous != "" {
+		others = "other shells keep using " + previous
+	}
+	if t.Until.IsZero() {
+		fmt.Fprintf(os.Stderr, "trying %s %s in a new shell until it exits, %s\n", language.Name, version, others)
+	} else {
+		fmt.Fprintf(os.Stderr, "trying %s %s in a new shell until %s, %s\n", language.Name, version, t.Until.Format(time.TimeOnly), others)
+	}
+
+	env := commands_env.SessionEnv(language, version)
+	env[envTry] = language.Name + "@" + version
+	cmd, err := start(command, env)
+	if err != nil {
+		return common.Exit(err)
+	}
+	// Ctrl-C 由 shell 处理；关闭终端或被终止时删除记录后退出，shell 同样收到信号
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(signals)
+	go func() {
+		for sig := range signals {
+			if sig != os.Interrupt {
+				_ = trial.Remove(dir, language.Name)
+				os.Exit(1)
+			}
+		}
+	}()
+	var expired atomic.Bool
+	if duration > 0 {
+		timer := time.AfterFunc(duration, func() {
+			expired.Store(true)
+			fmt.Fprintf(os.Stderr, "\nenvm: trial of %s %s expired, ending the shell\n", language.Name, version)
+			stop(cmd)
+		})
+		defer timer.Stop()
+	}
+
+	if err = cmd.Wait(); err != nil && !expired.Load() {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return cli.NewExitError("", exitErr.ExitCode())
+		}
+		return common.Exit(err)
+	}
+	return nil
+}
+
+// parseArgs 解析 <language>@<version> [--for 2h] [-- command...]，--for 可以写在版本之前或之后
+func parseArgs(args []string) (pin string, duration time.Duration, command []string, err error) {
+	for len(args) > 0 {
+		arg := args[0]
+		switch {
+		case arg == "--":
+			return pin, duration, args[1:], nil
+		case arg == "-h" || arg == "--help":
+			return "", 0, nil, nil
+		case arg == "--for" || arg == "-for":
+			if len(args) < 2 {
+				return "", 0, nil, errors.New("--for requires a duration, e.g. --for 2h")
+			}
+			arg, args = "--for="+args[1], args[1:]
+			fallthrough
+		case strings.HasPrefix(arg, "--for="):
+			if duration, err = time.ParseDuration(strings.TrimPrefix(arg, "--for=")); err != nil || duration <= 0 {
+				return "", 0, nil, fmt.Errorf("invalid --for %s, use a positive duration such as 30m or 2h", strings.TrimPrefix(arg, "--for="))
+			}
+		case pin == "" && !strings.HasPrefix(arg, "-"):
+			pin = arg
+		default:
+			// 版本之后的其它参数为需要执行的命令
+			if pin != "" {
+				return pin, duration, args, nil
+			}
+			return "", 0, nil, fmt.Errorf("unknown flag %s", arg)
+		}
+		args = args[1:]
+	}
+	return pin, duration, nil, nil
+}
+
+// start 启动 command，为空时启动用户的 shell，env 覆盖当前进程中的同名环境变量
+func start(command []string, env map[string]string) (*exec.Cmd, error) {
+	if len(command) == 0 {
+		command = userShell()
+	}
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = os.Environ()
+	for name, value := range env {
+		cmd.Env = append(cmd.Env, name+"="+value)
+	}
+	return cmd, cmd.Start()
+}
+
+// stop 结束试用的 shell，交互式 shell 忽略 SIGTERM，先发送 SIGHUP，不支持时(windows)直接结束进程
+func stop(cmd *exec.Cmd) {
+	if err := cmd.Process.Signal(syscall.SIGHUP); err != nil {
+		_ = cmd.Process.Kill()
+	}
+}
+
+// userShell 返回 $SHELL，windows 上没有设置时使用 PowerShell
+func userShell() []string {
+	if sh := os.Getenv("SHELL"); sh != "" {
+		return []string{sh}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"powershell.exe", "-NoLogo"}
+	}
+	return []string{"/bin/sh"}
+}
+
+// list 列出正在试用的版本，envm try 意外退出后留下的记录被删除
+func list() error {
+	dir := config.TrialsDir()
+	trials := make([]*trial.Trial, 0)
+	for _, t := range trial.List(dir) {
+		unlock, locked, err := util.TryLockFile(trial.LockFile(dir, t.Language))
+		if err == nil && locked {
+			// 持有该锁的 envm try 已经退出
+			_ = trial.Remove(dir, t.Language)
+			unlock()
+			continue
+		}
+		trials = append(trials, t)
+	}
+	if len(trials) == 0 {
+		fmt.Fprintln(util.Output, "no version is being tried")
+		return nil
+	}
+	rows := make([][]string, 0, len(trials))
+	for _, t := range trials {
+		until := "shell exit"
+		if !t.Until.IsZero() {
+			until = t.Until.Format(time.DateTime)
+		}
+		previous := t.Previous
+		if previous == "" {
+			previous = "-"
+		}
+		rows = append(rows, []string{t.Language, t.Version, previous, t.Started.Format(time.DateTime), until, fmt.Sprint(t.PID)})
+	}
+	if err := tabular.Write(os.Stdout, tabular.Table, []string{"LANGUAGE", "VERSION", "OTHER SHELLS", "STARTED", "UNTIL", "PID"}, rows); err != nil {
+		return common.Exit(err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package commands_verify
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/selfcheck"
+	web_github "github.com/FirewineXie/envm/internal/logic/web-github"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// Repo 发布 envm release 的 github 仓库
+const Repo = "FirewineXie/envm"
+
+// CommandVerify envm verify --self 校验正在运行的 envm 是否与 release 中发布的校验和一致
+// 同一个版本的校验文件不会变化，下载后缓存，之后的校验不需要访问网络
+func CommandVerify(ctx *cli.Context) error {
+	if !ctx.Bool("self") {
+		return cli.ShowCommandHelp(ctx, ctx.Command.Name)
+	}
+	version := ctx.App.Version
+	binary, err := os.Executable()
+	if err != nil {
+		return common.Exit(err)
+	}
+	if resolved, err := filepath.EvalSymlinks(binary); err == nil {
+		binary = resolved
+	}
+	sum, err := selfcheck.Sum(binary)
+	if err != nil {
+		return common.Exit(fmt.Errorf("read %s error + %w", binary, err))
+	}
+	checksums, err := loadChecksums(version, ctx.Bool("refresh"))
+	if err != nil {
+		return common.Exit(fmt.Errorf("load the published checksums of envm %s error + %w", version, err))
+	}
+	if name, ok := selfcheck.Match(checksums, sum); ok {
+		fmt.Printf("%s matches %s of envm %s\n", binary, name, version)
+		return nil
+	}
+	return common.Exit(fmt.Errorf("%w\n  binary:  %s\n  sha256:  %s\n  release: envm %s, %d published checksums\n"+
+		"the binary may be corrupted or tampered with, download it again from the release page",
+		selfcheck.ErrMismatch, binary, sum, version, selfcheck.Entries(checksums)))
+}
+
+// loadChecksums 读取缓存的校验文件，没有缓存或 refresh 时从 release 下载
+func loadChecksums(version string, refresh bool) ([]byte, error) {
+	file := config.SelfChecksumFile(version)
+	if !refresh {
+		if data, err := os.ReadFile(file); err == nil {
+			return data, nil
+		}
+	}
+	if config.Default().Settings.Offline {
+		return nil, fmt.Errorf("offline is set and %s is not cached", file)
+	}
+	release, err := web_github.FindRelease(Repo, version)
+	if err != nil {
+		return nil, err
+	}
+	asset := selfcheck.ChecksumAsset(release)
+	if asset == nil {
+		return nil, fmt.Errorf("release %s publishes none of %s", version, strings.Join(selfcheck.ChecksumAssets, ", "))
+	}
+	data, err := util.FetchContent(asset.URL)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(filepath.Dir(file), os.ModePerm); err == nil {
+		err = os.WriteFile(file, data, 0644)
+	}
+	if err != nil {
+		fmt.Printf("warning: cache %s error + %v\n", file, err)
+	}
+	return data, nil
+}
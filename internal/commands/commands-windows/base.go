@@ -0,0 +1,61 @@
+package commands_windows
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/FirewineXie/envm/internal/commands/commands-shim"
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/winenv"
+	"github.com/urfave/cli"
+)
+
+// CommandRegistry 将各语言的 symlink 写入注册表中的 PATH 及 GOROOT/JAVA_HOME 等变量，并通知系统刷新
+func CommandRegistry(ctx *cli.Context) error {
+	scope := winenv.User
+	if ctx.Bool("system") {
+		scope = winenv.System
+	}
+	if err := common.Elevate(UpdateRegistry(scope)); err != nil {
+		return common.Exit(fmt.Errorf("update %s environment error + %w", scope, err))
+	}
+	fmt.Printf("%s environment updated, open a new terminal to use it\n", scope)
+	return nil
+}
+
+// UpdateRegistry 更新注册表中的环境变量，symlink 的路径不随版本变化，切换版本后不需要再次执行
+func UpdateRegistry(scope winenv.Scope) error {
+	if config.Default().Settings.Portable {
+		return errors.New("registry is not modified in portable mode, use envm env or shims instead")
+	}
+	path, err := winenv.Get(scope, "Path")
+	if err != nil {
+		return err
+	}
+	for _, language := range languages.All() {
+		symlink := language.Link().Symlink
+		if symlink == "" {
+			continue
+		}
+		path = winenv.AddPath(path, filepath.Join(symlink, language.Bin))
+		if language.HomeEnv == "" {
+			continue
+		}
+		if err = winenv.Set(scope, language.HomeEnv, symlink); err != nil {
+			return err
+		}
+		fmt.Printf("%s=%s\n", language.HomeEnv, symlink)
+	}
+	path = winenv.AddPath(path, config.Default().LinkSetting[config.TOOL].Symlink)
+	for _, dir := range commands_shim.Dirs() {
+		path = winenv.AddPath(path, dir)
+	}
+	if err = winenv.Set(scope, "Path", path); err != nil {
+		return err
+	}
+	fmt.Printf("Path=%s\n", path)
+	return winenv.Broadcast()
+}
@@ -0,0 +1,162 @@
+package commands_workspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/logic/project"
+	"github.com/FirewineXie/envm/internal/logic/tabular"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// member 工作区中的一个子项目及其需要的版本，包括从上级目录继承的声明
+type member struct {
+	dir   string
+	items []languages.Resolved
+}
+
+// members 查找 ctx 第一个参数(默认为当前目录)下所有声明了版本的子项目
+func members(ctx *cli.Context) (string, []member, error) {
+	root := ctx.Args().First()
+	if root == "" {
+		root = "."
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return "", nil, err
+	}
+	dirs, err := project.Projects(root)
+	if err != nil {
+		return "", nil, err
+	}
+	result := make([]member, 0, len(dirs))
+	for _, dir := range dirs {
+		if items := languages.Resolve(dir); len(items) > 0 {
+			result = append(result, member{dir: dir, items: items})
+		}
+	}
+	return root, result, nil
+}
+
+// relative 相对于工作区根目录的路径
+func relative(root, path string) string {
+	if rel, err := filepath.Rel(root, path); err == nil {
+		return rel
+	}
+	return path
+}
+
+// CommandStatus 列出工作区中每个子项目需要的版本、声明所在的文件及是否已经安装
+func CommandStatus(ctx *cli.Context) error {
+	format := ctx.String("output")
+	if err := tabular.Check(format); err != nil {
+		return common.Exit(err)
+	}
+	root, list, err := members(ctx)
+	if err != nil {
+		return common.Exit(err)
+	}
+	if len(list) == 0 {
+		fmt.Fprintf(os.Stderr, "no version files found under %s\n", root)
+		return nil
+	}
+	rows := make([][]string, 0)
+	missing := 0
+	for _, m := range list {
+		for _, item := range m.items {
+			status := "installed"
+			if !common.IsInstalled(item.Language.Link().Downloads, item.Language.Prefix, item.Version) {
+				status = "missing"
+				missing++
+			}
+			rows = append(rows, []string{relative(root, m.dir), item.Language.Name, item.Requested, item.Version, status, relative(root, item.File)})
+		}
+	}
+	if err = tabular.Write(os.Stdout, format, []string{"DIR", "LANGUAGE", "REQUESTED", "VERSION", "STATUS", "FILE"}, rows); err != nil {
+		return common.Exit(err)
+	}
+	if missing > 0 {
+		fmt.Fprintf(os.Stderr, "%d versions are missing, run envm workspace install\n", missing)
+	}
+	return nil
+}
+
+// need 需要安装的一个版本及使用它的子项目
+type need struct {
+	language  *languages.Language
+	requested string
+	dirs      []string
+}
+
+// CommandInstall 一次安装工作区中所有子项目需要而没有安装的版本，相同的版本只安装一次
+// 某个版本安装失败时继续安装其它版本，最后以失败退出
+func CommandInstall(ctx *cli.Context) error {
+	root, list, err := members(ctx)
+	if err != nil {
+		return common.Exit(err)
+	}
+	needs := make(map[string]*need)
+	for _, m := range list {
+		for _, item := range m.items {
+			if common.IsInstalled(item.Language.Link().Downloads, item.Language.Prefix, item.Version) {
+				continue
+			}
+			key := item.Language.Name + "@" + item.Requested
+			if needs[key] == nil {
+				needs[key] = &need{language: item.Language, requested: item.Requested}
+			}
+			needs[key].dirs = append(needs[key].dirs, relative(root, m.dir))
+		}
+	}
+	if len(needs) == 0 {
+		fmt.Fprintln(util.Output, "all versions required by the workspace are installed")
+		return nil
+	}
+	keys := make([]string, 0, len(needs))
+	for key := range needs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	failed := make([]string, 0)
+	for _, key := range keys {
+		n := needs[key]
+		fmt.Fprintf(util.Output, "installing %s %s for %s\n", n.language.Name, n.requested, strings.Join(n.dirs, ", "))
+		version, err := install(n)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "install %s %s error + %v\n", n.language.Name, n.requested, err)
+			failed = append(failed, key)
+			continue
+		}
+		fmt.Fprintf(util.Output, "%s %s installed\n", n.language.Name, version)
+	}
+	if len(failed) > 0 {
+		return cli.NewExitError(fmt.Sprintf("%d of %d versions failed to install: %s", len(failed), len(keys), strings.Join(failed, ", ")), 1)
+	}
+	return nil
+}
+
+// install 解析版本后安装，部分版本号(1.22)从远程版本列表中选择最新的匹配版本
+func install(n *need) (string, error) {
+	version, err := n.language.ResolveVersion(n.requested)
+	if err != nil {
+		return "", err
+	}
+	if common.IsInstalled(n.language.Link().Downloads, n.language.Prefix, version) {
+		return version, nil
+	}
+	if err = n.language.Preflight(); err != nil {
+		return "", err
+	}
+	if err = n.language.Install(context.Background(), version); err != nil {
+		return "", err
+	}
+	return version, nil
+}
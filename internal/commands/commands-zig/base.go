@@ -0,0 +1,153 @@
+package commands_zig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+
+	"github.com/FirewineXie/envm/internal/arch"
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/listing"
+	"github.com/FirewineXie/envm/internal/logic/web-zig"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+var configLocal = config.Default().LinkSetting[config.ZIG]
+
+func CommandUninstall(ctx *cli.Context) error {
+	current := common.GetLinkedVersion(configLocal.Symlink, config.ZIG)
+	if err := common.UninstallVersion(configLocal.Downloads, config.ZIG, ctx.Args().First(), current); err != nil {
+		return common.Exit(fmt.Errorf("删除该版本失败+%w", err))
+	}
+	fmt.Fprintln(util.Output, "finish uninstall")
+	return nil
+}
+
+// CommandInstall 安装命令，版本为 master 时安装最新的 nightly 构建
+func CommandInstall(ctx *cli.Context) error {
+	versionS := ctx.Args().First()
+	if versionS == "" {
+		return cli.ShowSubcommandHelp(ctx)
+	}
+	if err := Install(context.Background(), versionS); err != nil {
+		return common.Exit(err)
+	}
+	return nil
+}
+
+// Install 下载、校验签名并安装指定版本
+func Install(ctx context.Context, versionS string) error {
+	collector, err := web_zig.NewCollector("")
+	if err != nil {
+		return fmt.Errorf("collect version error + %w", err)
+	}
+	version, err := collector.FindVersion(versionS)
+	if err != nil {
+		return err
+	}
+	release, err := common.Reserve(configLocal.Downloads, config.ZIG+version.Name)
+	if err != nil {
+		return err
+	}
+	defer release()
+	if common.IsInstalled(configLocal.Downloads, config.ZIG, version.Name) {
+		fmt.Fprintln(util.Output, "this version is downloaded")
+		return nil
+	}
+	findPackage, err := common.FindPackage(version.FindPackage, runtime.GOOS, arch.Native(), false)
+	if err != nil {
+		return fmt.Errorf("find version of system error + %w", err)
+	}
+
+	downloadPath, err := common.DownloadPackage(ctx, findPackage, configLocal.Downloads)
+	if err != nil {
+		return fmt.Errorf("download version error + %w", err)
+	}
+	defer util.RemoveDownloaded(downloadPath)()
+
+	signature, err := util.FetchContent(web_zig.SignatureURL(findPackage))
+	if err != nil {
+		return fmt.Errorf("download signature error + %w", err)
+	}
+	if err = util.VerifyMinisign(downloadPath, signature, web_zig.PublicKey); err != nil {
+		return fmt.Errorf("verify signature error + %w", err)
+	}
+
+	target := filepath.Join(configLocal.Downloads, config.ZIG+version.Name)
+	if err = common.ExtractDir(downloadPath, findPackage.FileName, target); err != nil {
+		return err
+	}
+	common.KeepArchive(configLocal.Downloads, config.ZIG+version.Name, downloadPath, common.KeptArchive{Dir: findPackage.FileName})
+	common.RecordInstall(configLocal.Downloads, config.ZIG+version.Name, findPackage)
+	fmt.Fprintln(util.Output, "Installed successfully "+version.Name)
+	return nil
+}
+
+// CommandUse 激活使用
+func CommandUse(ctx *cli.Context) error {
+	v, err := common.GetVersion(ctx, configLocal.Downloads, config.ZIG, true)
+	if err != nil {
+		return err
+	}
+	if err = Activate(v); err != nil {
+		return common.Exit(err)
+	}
+	fmt.Fprintln(util.Output, "Now using zig "+v)
+	return nil
+}
+
+// Activate 将 symlink 指向已经安装的版本
+func Activate(v string) error {
+	if !common.IsInstalled(configLocal.Downloads, config.ZIG, v) {
+		return errors.New("you have not install it,please install before use")
+	}
+	return common.ActiveVersion(configLocal.Downloads, config.ZIG+v, configLocal.Symlink)
+}
+
+// ListRemote 返回所有可以安装的版本，包括 master
+func ListRemote() ([]string, error) {
+	collector, err := web_zig.NewCollector("")
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0)
+	for _, version := range collector.AllVersions() {
+		names = append(names, version.Name)
+	}
+	return names, nil
+}
+
+// Releases 返回所有版本及提供安装包的平台，类型为 release 或 nightly 构建的 master
+func Releases() ([]listing.Release, error) {
+	collector, err := web_zig.NewCollector("")
+	if err != nil {
+		return nil, err
+	}
+	releases := make([]listing.Release, 0)
+	for _, version := range collector.AllVersions() {
+		r := listing.Release{Version: version.Name, Kind: "release", Platforms: listing.Platforms(version.Packages)}
+		if version.Nightly {
+			r.Kind, r.Note = web_zig.Master, fmt.Sprintf("(%s %s)", web_zig.Master, version.Date)
+		}
+		releases = append(releases, r)
+	}
+	return releases, nil
+}
+
+// CommandListRemote 获取远程的可下载的版本
+func CommandListRemote(ctx *cli.Context) error {
+	releases, err := Releases()
+	if err != nil {
+		return common.Exit(fmt.Errorf("collect version error + %w", err))
+	}
+	return common.PrintReleases(ctx, releases, "")
+}
+
+// CommandListInstalled 展示已经安装
+func CommandListInstalled(ctx *cli.Context) {
+	common.ListInstalled(configLocal.Downloads, config.ZIG, common.GetLinkedVersion(configLocal.Symlink, config.ZIG))
+}
@@ -0,0 +1,76 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/logic/syncdir"
+	"github.com/FirewineXie/envm/util"
+)
+
+// sourceMarker activation = "copy" 时复制得到的目录中记录来源版本目录的文件，存在该文件的目录才由 envm 管理
+const sourceMarker = ".envm-source"
+
+// errIncompleteCopy 上一次复制被中断，目录中的文件来自不同的版本
+var errIncompleteCopy = errors.New("incomplete copy")
+
+// copyVersion 将版本目录 target 增量复制到 current，只复制有变化的文件
+// 复制期间标记为空，中断后不会被当作完整的版本；current 是其它程序创建的非空目录时拒绝覆盖
+func copyVersion(target, current string) error {
+	info, err := os.Lstat(current)
+	switch {
+	case err == nil && info.Mode()&os.ModeSymlink != 0:
+		// 之前通过 symlink 激活
+		if err = os.Remove(current); err != nil {
+			return err
+		}
+	case err == nil && info.IsDir():
+		if _, err = os.Lstat(filepath.Join(current, sourceMarker)); err != nil {
+			if entries, _ := os.ReadDir(current); len(entries) > 0 {
+				return fmt.Errorf("%s already exists and was not created by envm, remove it or change the symlink setting", current)
+			}
+		}
+	case err == nil:
+		return fmt.Errorf("%s already exists and is not a directory", current)
+	}
+	if err = os.MkdirAll(current, os.ModePerm); err != nil {
+		return err
+	}
+	if err = os.WriteFile(filepath.Join(current, sourceMarker), nil, 0644); err != nil {
+		return err
+	}
+	stats, err := syncdir.Sync(target, current, sourceMarker)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(util.Output, "%d files copied, %d removed, %d unchanged\n", stats.Copied, stats.Removed, stats.Skipped)
+	return os.WriteFile(filepath.Join(current, sourceMarker), []byte(target), 0644)
+}
+
+// copiedSource 返回复制得到的目录的来源版本目录，不是复制得到的目录时返回 os.ErrNotExist
+func copiedSource(current string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(current, sourceMarker))
+	if err != nil {
+		return "", os.ErrNotExist
+	}
+	source := strings.TrimSpace(string(data))
+	if source == "" {
+		return "", errIncompleteCopy
+	}
+	return source, nil
+}
+
+// removeCopy 改回 symlink 激活前删除复制得到的目录，不是 envm 复制的目录时不删除
+func removeCopy(current string) error {
+	info, err := os.Lstat(current)
+	if err != nil || !info.IsDir() {
+		return nil
+	}
+	if _, err = os.Lstat(filepath.Join(current, sourceMarker)); err != nil {
+		return nil
+	}
+	return os.RemoveAll(current)
+}
@@ -0,0 +1,127 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/relocate"
+	"github.com/FirewineXie/envm/internal/logic/selfcheck"
+	"github.com/FirewineXie/envm/util"
+)
+
+// KeptArchive keep_downloads 保留的安装包及重新解压的方式，记录在 <archives>/<language>/<dirName>.json
+type KeptArchive struct {
+	// Archive 同目录中保留的安装包文件名
+	Archive string `json:"archive"`
+	SHA256  string `json:"sha256"`
+	// Dir 包内的目录，同 ExtractDir 的 dirName
+	Dir string `json:"dir"`
+	// Target 解压位置相对安装目录的路径，为空表示安装目录本身
+	Target string `json:"target,omitempty"`
+	// Executable 解压后为 Target 中的文件加上可执行权限，zip 不一定保留
+	Executable bool `json:"executable,omitempty"`
+}
+
+// ErrNoArchive 没有保留该版本的安装包
+var ErrNoArchive = errors.New("no kept archive")
+
+// archiveRecord <downloads>/<dirName> 的保留记录，downloads 的最后一级为语言
+func archiveRecord(downloads, dirName string) string {
+	return filepath.Join(config.ArchivesDir(), filepath.Base(downloads), dirName+".json")
+}
+
+// KeepArchive 安装成功后，开启 keep_downloads 时将安装包 downloadPath 移动到 archives 目录并记录解压方式
+// 调用方随后删除 downloadPath 不受影响；保留失败只给出警告，不影响安装结果
+func KeepArchive(downloads, dirName, downloadPath string, kept KeptArchive) {
+	if !config.Default().Settings.KeepDownloads {
+		return
+	}
+	if err := keepArchive(downloads, dirName, downloadPath, kept); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: keep %s error + %v\n", downloadPath, err)
+	}
+}
+
+func keepArchive(downloads, dirName, downloadPath string, kept KeptArchive) error {
+	sum, err := selfcheck.Sum(downloadPath)
+	if err != nil {
+		return err
+	}
+	record := archiveRecord(downloads, dirName)
+	kept.Archive, kept.SHA256 = filepath.Base(downloadPath), sum
+	archive := filepath.Join(filepath.Dir(record), kept.Archive)
+	_ = os.Remove(archive)
+	if _, err = relocate.Move(downloadPath, archive); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(kept, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(record, data, 0644)
+}
+
+// Reinstall 从保留的安装包重新生成 <downloads>/<dirName>，不访问网络
+// 先校验安装包，解压失败时恢复原来的安装目录
+func Reinstall(downloads, dirName string) error {
+	if err := CheckWritable("reinstalling " + dirName); err != nil {
+		return err
+	}
+	record := archiveRecord(downloads, dirName)
+	data, err := os.ReadFile(record)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%w for %s, enable keep_downloads in %s and install it again", ErrNoArchive, dirName, config.SettingsFile())
+	}
+	if err != nil {
+		return err
+	}
+	var kept KeptArchive
+	if err = json.Unmarshal(data, &kept); err != nil {
+		return fmt.Errorf("parse %s error + %w", record, err)
+	}
+	archive := filepath.Join(filepath.Dir(record), kept.Archive)
+	sum, err := selfcheck.Sum(archive)
+	if err != nil {
+		return err
+	}
+	if sum != kept.SHA256 {
+		return fmt.Errorf("%w: kept archive %s has sha256 %s, recorded %s", util.ErrChecksum, archive, sum, kept.SHA256)
+	}
+
+	installDir := filepath.Join(downloads, dirName)
+	old := installDir + ".old"
+	_ = os.RemoveAll(old)
+	if exists, _ := util.PathExists(installDir); exists {
+		if err = os.Rename(installDir, old); err != nil {
+			return err
+		}
+	}
+	restore := func() {
+		_ = os.RemoveAll(installDir)
+		_ = os.Rename(old, installDir)
+	}
+	defer util.OnInterrupt(restore)()
+	target := filepath.Join(installDir, kept.Target)
+	if err = ExtractDir(archive, kept.Dir, target); err != nil {
+		restore()
+		return err
+	}
+	if kept.Executable {
+		files, _ := os.ReadDir(target)
+		for _, file := range files {
+			if !file.IsDir() {
+				_ = os.Chmod(filepath.Join(target, file.Name()), 0755)
+			}
+		}
+	}
+	// 安装包中没有安装时记录的信息，从原来的目录中保留
+	if data, err := os.ReadFile(filepath.Join(old, metadataFile)); err == nil {
+		_ = os.WriteFile(filepath.Join(installDir, metadataFile), data, 0644)
+	}
+	_ = os.RemoveAll(old)
+	RecordInstall(downloads, dirName, &util.Package{URL: archive, Checksum: kept.SHA256})
+	return nil
+}
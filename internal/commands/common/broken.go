@@ -0,0 +1,89 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/util"
+)
+
+// BrokenLink 返回 symlink 指向但已经被删除的目录，symlink 不存在或指向的目录存在时返回空
+func BrokenLink(symlink string) string {
+	if symlink == "" {
+		return ""
+	}
+	target, err := readLink(symlink)
+	if err != nil {
+		return ""
+	}
+	if exists, _ := util.PathExists(target); exists {
+		return ""
+	}
+	return target
+}
+
+// LinkProblem 检查 symlink 是否与最后一次激活的记录一致，一致时返回空，否则返回问题的描述及应该指向的目录
+// 指向的目录已经被删除时 expected 为空，需要改为指向其它版本
+func LinkProblem(symlink string) (problem, expected string) {
+	if symlink == "" {
+		return "", ""
+	}
+	if target := BrokenLink(symlink); target != "" {
+		return fmt.Sprintf("points to %s which has been deleted", target), ""
+	}
+	if config.Default().Settings.Portable {
+		return "", ""
+	}
+	expected, ok := readState()[symlink]
+	if !ok {
+		return "", ""
+	}
+	if exists, _ := util.PathExists(expected); !exists {
+		return "", ""
+	}
+	if _, err := os.Lstat(symlink); err != nil {
+		return fmt.Sprintf("is missing, %s should be active", expected), expected
+	}
+	if _, err := copiedSource(symlink); errors.Is(err, errIncompleteCopy) {
+		return fmt.Sprintf("is an incomplete copy, %s should be active", expected), expected
+	}
+	// windows 上无法创建链接时复制了文件，无法读取指向，视为正常
+	if target, err := readLink(symlink); err == nil && filepath.Clean(target) != filepath.Clean(expected) {
+		return fmt.Sprintf("points to %s instead of %s", target, expected), expected
+	}
+	return "", ""
+}
+
+// RepairLink symlink 被删除或被改为指向其它目录时提示，确认后恢复为最后一次激活的版本
+// 指向的目录已经被删除时提示，确认后改为指向已经安装的最新版本，返回 symlink 是否可以使用
+func RepairLink(name, downloads, language, symlink string) (bool, error) {
+	problem, expected := LinkProblem(symlink)
+	if problem == "" {
+		return true, nil
+	}
+	fmt.Fprintf(util.Output, "%s symlink %s %s\n", name, symlink, problem)
+	if expected != "" {
+		if !util.Confirm("restore it?") {
+			return false, nil
+		}
+		if err := ActiveVersion(filepath.Dir(expected), filepath.Base(expected), symlink); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	installed := GetInstalled(downloads, language)
+	if len(installed) == 0 {
+		fmt.Fprintf(util.Output, "no %s version is installed, run envm %s install <version>\n", name, name)
+		return false, nil
+	}
+	if !util.Confirm(fmt.Sprintf("repoint it to %s %s?", name, installed[0])) {
+		return false, nil
+	}
+	if err := ActiveVersion(downloads, language+installed[0], symlink); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,47 @@
+package common
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/checksum"
+	"github.com/FirewineXie/envm/util"
+)
+
+// checksumSpecs config.toml 中的 checksum_providers，令牌从 token_env 指定的环境变量读取
+func checksumSpecs() []checksum.Spec {
+	providers := config.Default().Settings.ChecksumProviders
+	specs := make([]checksum.Spec, 0, len(providers))
+	for _, p := range providers {
+		spec := checksum.Spec{Mirror: p.Mirror, Type: p.Type, URL: p.URL, Field: p.Field, Algorithm: p.Algorithm}
+		if p.TokenEnv != "" {
+			spec.Token = os.Getenv(p.TokenEnv)
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+// ProviderChecksum 版本列表中没有校验和且下载地址匹配 checksum_providers 时，从制品库的接口获取校验和并设置 pkg.Checksum 及 pkg.Algorithm
+// 没有匹配的来源时返回 false，调用者继续使用 pkg.ChecksumURL
+func ProviderChecksum(pkg *util.Package) (bool, error) {
+	spec := checksum.Match(checksumSpecs(), pkg.URL)
+	if spec == nil {
+		return false, nil
+	}
+	provider, err := checksum.New(*spec)
+	if err != nil {
+		return true, err
+	}
+	sums, err := provider.Lookup(pkg.URL)
+	if err != nil {
+		return true, fmt.Errorf("get the checksum of %s from %s error + %w", pkg.URL, spec.Mirror, err)
+	}
+	algorithm, value, ok := checksum.Pick(sums, pkg.Algorithm)
+	if !ok {
+		return true, fmt.Errorf("%s provides no SHA256, SHA512 or SHA1 checksum of %s", spec.Mirror, pkg.URL)
+	}
+	pkg.Algorithm, pkg.Checksum = algorithm, value
+	return true, nil
+}
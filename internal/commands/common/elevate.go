@@ -0,0 +1,34 @@
+package common
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/elevate"
+	"github.com/FirewineXie/envm/util"
+)
+
+// Elevate 权限不足时(系统 PATH、Program Files 下的安装目录等)询问是否以管理员权限重新执行当前命令
+// 重新执行后直接以其退出码结束当前进程，其它情况原样返回错误
+func Elevate(err error) error {
+	if !elevate.Required(err) || elevate.IsElevated() {
+		return err
+	}
+	if !elevate.Supported {
+		if config.IsShared() {
+			return fmt.Errorf("%w, installing into the shared root %s requires write permission, try sudo -E", err, config.SharedRoot())
+		}
+		return err
+	}
+	fmt.Fprintln(util.Output, err)
+	if !util.Confirm("administrator rights are required, relaunch this command elevated?") {
+		return fmt.Errorf("%w, run it again as administrator", err)
+	}
+	code, relaunchErr := elevate.Relaunch(os.Args[1:])
+	if relaunchErr != nil {
+		return fmt.Errorf("%w, relaunch error + %v", err, relaunchErr)
+	}
+	os.Exit(code)
+	return nil
+}
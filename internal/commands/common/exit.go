@@ -0,0 +1,29 @@
+package common
+
+// ExitError 命令失败时的退出错误，输出及退出码与 cli.NewExitError 相同
+// 保留原始错误，包装命令的 Normalize 等及库的调用方可以继续通过 errors.Is/As 判断失败的类型
+type ExitError struct {
+	Err  error
+	Code int
+}
+
+// Exit 以退出码 1 包装 err，err 为空时返回空
+func Exit(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ExitError{Err: err, Code: 1}
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+// ExitCode 实现 cli.ExitCoder
+func (e *ExitError) ExitCode() int {
+	return e.Code
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,43 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/health"
+	"github.com/FirewineXie/envm/internal/logic/history"
+	"github.com/FirewineXie/envm/internal/logic/stats"
+	"github.com/FirewineXie/envm/util"
+)
+
+// versionDir 从版本目录 <downloads>/<language>/<prefix><version> 得到语言及版本
+func versionDir(dir string) (language, version string) {
+	language = filepath.Base(filepath.Dir(dir))
+	prefix := health.Specs[language].Prefix
+	if prefix == "" {
+		prefix = language
+	}
+	return language, strings.TrimPrefix(filepath.Base(dir), prefix)
+}
+
+// record 追加审计记录，写入失败只提示，不影响已经完成的操作
+func record(action, dir string, pkg *util.Package) {
+	language, version := versionDir(dir)
+	entry := history.Record{Action: action, Language: language, Version: version}
+	if pkg != nil {
+		entry.Source, entry.Checksum = pkg.URL, pkg.Checksum
+	}
+	if err := history.Append(config.HistoryFile(), entry); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: write history error + %v\n", err)
+	}
+}
+
+// RecordInstall 安装到 <downloads>/<dirName> 成功后记录来源及校验和，pkg 为空表示不是通过安装包安装的(插件)
+func RecordInstall(downloads, dirName string, pkg *util.Package) {
+	record(history.ActionInstall, filepath.Join(downloads, dirName), pkg)
+	language, _ := versionDir(filepath.Join(downloads, dirName))
+	RecordStats(func(s *stats.Stats) { s.Installs[language]++ })
+}
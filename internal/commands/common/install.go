@@ -0,0 +1,274 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/extract"
+	"github.com/FirewineXie/envm/internal/logic/health"
+	"github.com/FirewineXie/envm/internal/logic/history"
+	"github.com/FirewineXie/envm/internal/logic/web-github"
+	"github.com/FirewineXie/envm/util"
+)
+
+// DownloadPackage 下载安装包到 downloads 目录并校验，返回安装包路径
+// 没有校验和(包括获取校验文件失败)时拒绝安装，除非指定了 --insecure；下载地址匹配 checksum_providers 时从制品库接口获取校验和
+func DownloadPackage(ctx context.Context, pkg *util.Package, downloads string) (string, error) {
+	downloadPath := filepath.Clean(filepath.Join(downloads, pkg.ArchiveName))
+	if err := pkg.DownloadContext(ctx, downloadPath); err != nil {
+		return "", Elevate(err)
+	}
+
+	if pkg.Checksum == "" {
+		// 内部制品库通过接口提供校验和，同目录通常没有校验文件
+		provided, err := ProviderChecksum(pkg)
+		if err != nil && !util.Insecure {
+			_ = os.Remove(downloadPath)
+			return "", err
+		}
+		if provided {
+			pkg.ChecksumURL = ""
+		}
+	}
+	if pkg.Checksum == "" && pkg.ChecksumURL != "" {
+		checksum, err := web_github.FetchChecksum(pkg.ChecksumURL, path.Base(pkg.URL))
+		if err != nil && !util.Insecure {
+			_ = os.Remove(downloadPath)
+			return "", err
+		}
+		pkg.Checksum = checksum
+	}
+	if err := pkg.Verify(downloadPath); err != nil {
+		_ = os.Remove(downloadPath)
+		return "", err
+	}
+	PinChecksum(pkg, downloadPath)
+	return downloadPath, nil
+}
+
+// ExtractDir 解压安装包，并将包内的 dirName 目录移动到 target
+// dirName 为空表示将整个压缩包内容作为 target
+func ExtractDir(archivePath, dirName, target string) error {
+	return ExtractFiles(archivePath, target+".tmp", map[string]string{dirName: target})
+}
+
+// ExtractFiles 解压安装包到临时目录 tmp，并将 files 中包内的路径分别移动到对应的目标路径
+// 被中断时删除临时目录及已经移动的目标路径，不留下不完整的安装
+func ExtractFiles(archivePath, tmp string, files map[string]string) error {
+	if config.IsShared() {
+		// 其它用户同时安装同一个版本时等待，之后目标已经存在则不再解压
+		unlock, err := util.LockFile(tmp + ".lock")
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		if installedAll(files) {
+			return nil
+		}
+	}
+	_ = os.RemoveAll(tmp)
+	moved := make([]string, 0, len(files))
+	defer util.OnInterrupt(func() {
+		_ = os.RemoveAll(tmp)
+		for _, target := range moved {
+			_ = os.RemoveAll(target)
+		}
+	})()
+	if err := extract.Unarchive(archivePath, tmp); err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	for name, target := range files {
+		if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+			return err
+		}
+		source := filepath.Join(tmp, name)
+		if _, err := os.Stat(source); os.IsNotExist(err) && name != "" {
+			// 安装程序(pkg/msi)展开后的目录位于安装位置对应的子目录中
+			if found := extract.FindDir(tmp, filepath.Base(name)); found != "" {
+				source = found
+			}
+		}
+		if err := os.Rename(source, target); err != nil {
+			return err
+		}
+		moved = append(moved, target)
+		if config.IsShared() {
+			shareReadable(target)
+		}
+	}
+	return nil
+}
+
+// installedAll files 中的目标路径是否都已经存在
+func installedAll(files map[string]string) bool {
+	for _, target := range files {
+		if exists, _ := util.PathExists(target); !exists {
+			return false
+		}
+	}
+	return true
+}
+
+// shareReadable 共享目录中安装的文件需要所有用户可读，可执行文件所有用户可执行
+func shareReadable(dir string) {
+	_ = filepath.Walk(dir, func(name string, info os.FileInfo, err error) error {
+		if err != nil || info.Mode()&os.ModeSymlink != 0 {
+			return nil
+		}
+		mode := info.Mode().Perm() | 0444
+		if info.IsDir() || mode&0100 != 0 {
+			mode |= 0111
+		}
+		_ = os.Chmod(name, mode)
+		return nil
+	})
+}
+
+// InstallDirArchive 下载并解压整目录发布的工具链到 <downloads>/<language><version>
+func InstallDirArchive(ctx context.Context, pkg *util.Package, downloads, language, version string) error {
+	downloadPath, err := DownloadPackage(ctx, pkg, downloads)
+	if err != nil {
+		return err
+	}
+	defer util.RemoveDownloaded(downloadPath)()
+	if err = ExtractDir(downloadPath, pkg.FileName, filepath.Join(downloads, language+version)); err != nil {
+		return err
+	}
+	KeepArchive(downloads, language+version, downloadPath, KeptArchive{Dir: pkg.FileName})
+	return nil
+}
+
+// InstallBinaryArchive 下载单文件工具的压缩包，解压后将可执行文件放到 <downloads>/<language><version>/bin
+// pkg.FileName 为压缩包内可执行文件所在的目录，为空表示在压缩包根目录
+func InstallBinaryArchive(ctx context.Context, pkg *util.Package, downloads, language, version string) error {
+	downloadPath, err := DownloadPackage(ctx, pkg, downloads)
+	if err != nil {
+		return err
+	}
+	defer util.RemoveDownloaded(downloadPath)()
+
+	bin := filepath.Join(downloads, language+version, "bin")
+	if err = ExtractDir(downloadPath, pkg.FileName, bin); err != nil {
+		return err
+	}
+	files, err := os.ReadDir(bin)
+	if err != nil {
+		return err
+	}
+	// zip 不一定保留可执行权限
+	for _, file := range files {
+		if !file.IsDir() {
+			_ = os.Chmod(filepath.Join(bin, file.Name()), 0755)
+		}
+	}
+	KeepArchive(downloads, language+version, downloadPath, KeptArchive{Dir: pkg.FileName, Target: "bin", Executable: true})
+	return nil
+}
+
+// ActiveVersion 将 symlink 指向 <downloads>/<dirName>，安装目录不完整时拒绝切换，切换成功后记录到审计日志并执行 post_use
+// downloads 为 <ENVM_HOME>/downloads/<language>，按目录名确定语言的检查规则
+func ActiveVersion(downloads, dirName, symlink string) error {
+	defer util.Phase(util.PhaseActivate)()
+	if symlink == "" {
+		return errors.New("not config symlink")
+	}
+	if err := health.CheckDir(filepath.Base(downloads), downloads, dirName); err != nil {
+		return err
+	}
+	previous := linkedVersion(symlink)
+	dir := filepath.Join(downloads, dirName)
+	if config.Default().Settings.Portable {
+		if err := setState(symlink, dir); err != nil {
+			return err
+		}
+		record(history.ActionActivate, dir, nil)
+		postUse(dir, previous)
+		return nil
+	}
+	if target := BrokenLink(symlink); target != "" {
+		fmt.Fprintf(util.Output, "replace broken symlink %s, %s has been deleted\n", symlink, target)
+	}
+	// 中断时恢复之前的指向，避免 symlink 被删除后没有重新创建
+	if previous, err := os.Readlink(symlink); err == nil {
+		defer util.OnInterrupt(func() {
+			_ = os.Remove(symlink)
+			_ = util.Symlink(previous, symlink)
+		})()
+	}
+	// windows 上无法创建链接时复制文件，被复制的程序正在运行时替换会失败
+	err := Elevate(retryInUse(func() error {
+		if config.CopyActivation() {
+			return copyVersion(dir, symlink)
+		}
+		if err := removeCopy(symlink); err != nil {
+			return err
+		}
+		_ = os.Remove(symlink)
+		return util.Symlink(dir, symlink)
+	}, symlink))
+	if err != nil {
+		return err
+	}
+	// symlink 之外再记录一份期望的指向，symlink 被破坏后 envm current 据此修复
+	if err = setState(symlink, dir); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: write state error + %v\n", err)
+	}
+	record(history.ActionActivate, dir, nil)
+	postUse(dir, previous)
+	return nil
+}
+
+// GetLinkedVersion 通过 symlink 指向的目录获取当前使用的版本，未激活时返回空
+func GetLinkedVersion(symlink, language string) string {
+	target, err := readLink(symlink)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(filepath.Base(target), language)
+}
+
+// ListInstalled 打印已经安装的版本，并标记当前使用的版本
+func ListInstalled(downloads, language, current string) {
+	v := GetInstalled(downloads, language)
+	for _, version := range v {
+		if version == current {
+			fmt.Fprintf(util.Output, "  * %s (Currently using %s%s)\n", version, language, version)
+			continue
+		}
+		fmt.Fprintf(util.Output, "    %s\n", version)
+	}
+	if len(v) == 0 {
+		fmt.Fprintln(util.Output, "No installations recognized.")
+	}
+}
+
+// IsInstalled 判断版本是否已经安装
+func IsInstalled(downloads, language, version string) bool {
+	for _, installed := range GetInstalled(downloads, language) {
+		if installed == version {
+			return true
+		}
+	}
+	return false
+}
+
+// UninstallVersion 删除 <downloads>/<language><version>，不允许删除当前版本
+func UninstallVersion(downloads, language, version, current string) error {
+	if version == "" {
+		return errors.New("version can not be empty")
+	}
+	if version == current {
+		return errors.New("不能卸载当前版本")
+	}
+	if !IsInstalled(downloads, language, version) {
+		return errors.New("this version is not installed")
+	}
+	return RemoveDir(filepath.Join(downloads, language+version))
+}
@@ -0,0 +1,78 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// racyWindow 目录修改时间距今不足该时长时不缓存，避免粗粒度时间戳的文件系统在同一时刻内的修改被忽略
+const racyWindow = 2 * time.Second
+
+// scan 一个安装目录的扫描结果，目录中增删版本目录会改变它的修改时间
+type scan struct {
+	modTime time.Time
+	dirs    []string
+}
+
+// scans 只保存在内存中，envm daemon、serve 等常驻进程及同一个命令中的多次查询(IsInstalled、ls 所有语言)不再读取目录
+// ls、current、补全等一次性的命令每个目录仍然读取一次；不写入文件: 写缓存的开销大于一次 os.ReadDir，且 envm 之外的修改可能让文件中的结果过期
+var (
+	scansMu sync.Mutex
+	scans   = map[string]scan{}
+)
+
+// linkedDir 判断 name 是否为指向目录的链接，例如链接到系统 JDK、nvm 安装的版本
+func linkedDir(name string) bool {
+	if info, err := os.Lstat(name); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return false
+	}
+	info, err := os.Stat(name)
+	return err == nil && info.IsDir()
+}
+
+// External 返回 <downloads>/<dirName> 链接到的外部目录，不是链接时返回空
+func External(downloads, dirName string) string {
+	name := filepath.Join(downloads, dirName)
+	if !linkedDir(name) {
+		return ""
+	}
+	target, err := filepath.EvalSymlinks(name)
+	if err != nil {
+		return ""
+	}
+	return target
+}
+
+// installedDirs 返回 root 下的子目录名(包括指向目录的链接)，root 的修改时间未变化时使用缓存，不再读取目录
+func installedDirs(root string) []string {
+	root = filepath.Clean(root)
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil
+	}
+
+	scansMu.Lock()
+	defer scansMu.Unlock()
+	if cached, ok := scans[root]; ok && cached.modTime.Equal(info.ModTime()) {
+		return cached.dirs
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+	dirs := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || linkedDir(filepath.Join(root, entry.Name())) {
+			dirs = append(dirs, entry.Name())
+		}
+	}
+	if time.Since(info.ModTime()) > racyWindow {
+		scans[root] = scan{modTime: info.ModTime(), dirs: dirs}
+	} else {
+		delete(scans, root)
+	}
+	return dirs
+}
@@ -0,0 +1,51 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestInstalledDirs(t *testing.T) {
+	Convey("目录修改时间未变化时使用缓存", t, func() {
+		root := t.TempDir()
+		So(os.Mkdir(filepath.Join(root, "go1.22.3"), os.ModePerm), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(root, "go1.22.3.lock"), nil, 0644), ShouldBeNil)
+		old := time.Now().Add(-time.Hour).Truncate(time.Second)
+		So(os.Chtimes(root, old, old), ShouldBeNil)
+
+		So(installedDirs(root), ShouldResemble, []string{"go1.22.3"})
+		scansMu.Lock()
+		_, cached := scans[root]
+		scansMu.Unlock()
+		So(cached, ShouldBeTrue)
+
+		// 修改时间被还原时仍然使用缓存，说明没有再次读取目录
+		So(os.Mkdir(filepath.Join(root, "go1.21.0"), os.ModePerm), ShouldBeNil)
+		So(os.Chtimes(root, old, old), ShouldBeNil)
+		So(installedDirs(root), ShouldResemble, []string{"go1.22.3"})
+
+		So(os.Chtimes(root, old.Add(time.Minute), old.Add(time.Minute)), ShouldBeNil)
+		So(installedDirs(root), ShouldResemble, []string{"go1.21.0", "go1.22.3"})
+	})
+
+	Convey("刚修改过的目录不缓存", t, func() {
+		root := t.TempDir()
+		So(os.Mkdir(filepath.Join(root, "node20.12.2"), os.ModePerm), ShouldBeNil)
+		now := time.Now()
+		So(os.Chtimes(root, now, now), ShouldBeNil)
+
+		So(installedDirs(root), ShouldResemble, []string{"node20.12.2"})
+		scansMu.Lock()
+		_, cached := scans[root]
+		scansMu.Unlock()
+		So(cached, ShouldBeFalse)
+	})
+
+	Convey("目录不存在时返回空", t, func() {
+		So(installedDirs(filepath.Join(t.TempDir(), "missing")), ShouldBeEmpty)
+	})
+}
@@ -0,0 +1,70 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/FirewineXie/envm/internal/logic/history"
+	"github.com/FirewineXie/envm/internal/logic/inuse"
+	"github.com/FirewineXie/envm/util"
+)
+
+// waitInUse 列出可执行文件位于 dirs 中的进程，询问是否在它们退出后重试，没有这样的进程时返回 nil
+func waitInUse(cause error, dirs ...string) error {
+	for {
+		processes := inuse.Processes(dirs...)
+		if len(processes) == 0 {
+			return nil
+		}
+		busy := &inuse.Error{Dir: dirs[0], Processes: processes, Err: cause}
+		fmt.Fprintln(util.Output, busy)
+		if !util.Confirm("retry after they exit?") {
+			return busy
+		}
+	}
+}
+
+// RemoveDir 删除版本目录，windows 上目录中的程序正在运行时先列出这些进程并询问是否重试，避免只删除一半
+// 删除成功后记录到审计日志，链接到外部目录的版本只删除链接
+func RemoveDir(dir string) error {
+	if err := CheckWritable("uninstalling " + filepath.Base(dir)); err != nil {
+		return err
+	}
+	// envm link 登记的外部目录只删除链接，不能删除链接指向的目录
+	if linkedDir(dir) {
+		if err := os.Remove(dir); err != nil {
+			return err
+		}
+		record(history.ActionUninstall, dir, nil)
+		return nil
+	}
+	if inuse.Locks {
+		if err := waitInUse(nil, dir); err != nil {
+			return err
+		}
+	}
+	if err := retryInUse(func() error { return os.RemoveAll(dir) }, dir); err != nil {
+		return err
+	}
+	record(history.ActionUninstall, dir, nil)
+	return nil
+}
+
+// retryInUse 执行 op，因为文件被占用失败时列出占用的进程并询问是否重试
+func retryInUse(op func() error, dirs ...string) error {
+	for {
+		err := op()
+		if err == nil || !inuse.Busy(err) {
+			return err
+		}
+		processes := inuse.Processes(dirs...)
+		if len(processes) == 0 {
+			// 被没有运行其中程序的进程打开了文件，例如 IDE 打开了 jar
+			return &inuse.Error{Dir: dirs[0], Err: err}
+		}
+		if err = waitInUse(err, dirs...); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/FirewineXie/envm/internal/arch"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/util"
+)
+
+// FindPackage 按 config.toml 中 package_kinds 配置的顺序查找 goos/goarch 的安装包，都没有时使用压缩包
+// installer 为 false 的语言不使用安装程序，其中的目录结构与压缩包不同，展开后无法直接使用
+// goarch 没有任何安装包时(例如 windows/arm64 上较早的版本)依次尝试 arch.Emulated 中的架构并给出警告
+func FindPackage(find func(kind, goos, goarch string) (*util.Package, error), goos, goarch string, installer bool) (*util.Package, error) {
+	pkg, err := findKind(find, goos, goarch, installer)
+	if !errors.Is(err, util.ErrPackageNotFound) {
+		return pkg, err
+	}
+	for _, emulated := range arch.Emulated(goos, goarch) {
+		pkg, err = findKind(find, goos, emulated, installer)
+		if errors.Is(err, util.ErrPackageNotFound) {
+			continue
+		}
+		if err == nil {
+			fmt.Fprintf(os.Stderr, "warning: no native %s/%s package, using the %s/%s package which runs under emulation\n", goos, goarch, goos, emulated)
+		}
+		return pkg, err
+	}
+	return nil, util.ErrPackageNotFound
+}
+
+// findKind 按 package_kinds 的顺序查找 goos/goarch 的安装包
+func findKind(find func(kind, goos, goarch string) (*util.Package, error), goos, goarch string, installer bool) (*util.Package, error) {
+	for _, kind := range config.PackageKinds(goos) {
+		if kind == util.InstallerKind && !installer {
+			continue
+		}
+		pkg, err := find(kind, goos, goarch)
+		if err == nil || !errors.Is(err, util.ErrPackageNotFound) {
+			return pkg, err
+		}
+	}
+	return nil, util.ErrPackageNotFound
+}
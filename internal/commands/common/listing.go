@@ -0,0 +1,70 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/logic/listing"
+	"github.com/FirewineXie/envm/util"
+	"github.com/urfave/cli"
+)
+
+// ReleaseFilter 读取 commands_remote.FilterFlags 中的过滤参数，kind 为位置参数中的版本类型(例如 envm node lsr lts)，不为空时优先于 --kind
+// 兼容旧的 --latest 参数
+func ReleaseFilter(ctx *cli.Context, kind string) (listing.Filter, error) {
+	f := listing.Filter{
+		OS:    ctx.String("os"),
+		Arch:  ctx.String("arch"),
+		Kind:  ctx.String("kind"),
+		Limit: ctx.Int("limit"),
+		Sort:  ctx.String("sort"),
+	}
+	if kind != "" {
+		f.Kind = kind
+	}
+	if ctx.IsSet("latest") {
+		f.Limit = ctx.Int("latest")
+	}
+	return f, f.Check()
+}
+
+// CheckKind 检查 kind 是否为 releases 中的版本类型
+func CheckKind(f listing.Filter, releases []listing.Release) error {
+	if f.Kind == "" {
+		return nil
+	}
+	kinds := listing.Kinds(releases)
+	for _, kind := range kinds {
+		if strings.EqualFold(kind, f.Kind) {
+			return nil
+		}
+	}
+	if len(kinds) == 0 {
+		return fmt.Errorf("the versions are not classified, --kind is not supported here")
+	}
+	return fmt.Errorf("unknown kind %s, kinds: %s", f.Kind, strings.Join(kinds, ", "))
+}
+
+// PrintReleases 按过滤参数输出远程版本，kind 同 ReleaseFilter
+func PrintReleases(ctx *cli.Context, releases []listing.Release, kind string) error {
+	f, err := ReleaseFilter(ctx, kind)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	if err = CheckKind(f, releases); err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	for _, r := range listing.Apply(releases, f) {
+		PrintRelease(r)
+	}
+	return nil
+}
+
+// PrintRelease 输出一个版本，有说明时对齐输出在版本后
+func PrintRelease(r listing.Release) {
+	if r.Note == "" {
+		fmt.Fprintln(util.Output, r.Version)
+		return
+	}
+	fmt.Fprintf(util.Output, "%-20s %s\n", r.Version, r.Note)
+}
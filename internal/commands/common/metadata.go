@@ -0,0 +1,40 @@
+package common
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// metadataFile 版本目录中记录安装信息的文件，随版本目录一起卸载、迁移
+const metadataFile = ".envm.json"
+
+// Metadata 安装时记录的版本信息
+type Metadata struct {
+	// Arch 工具链的架构(GOARCH 的写法)，为空表示安装时没有记录，即系统的架构
+	Arch string `json:"arch,omitempty"`
+}
+
+// ReadMetadata 读取 <downloads>/<dirName> 的安装信息，没有记录时返回空
+func ReadMetadata(downloads, dirName string) Metadata {
+	var m Metadata
+	if data, err := os.ReadFile(filepath.Join(downloads, dirName, metadataFile)); err == nil {
+		_ = json.Unmarshal(data, &m)
+	}
+	return m
+}
+
+// WriteMetadata 写入 <downloads>/<dirName> 的安装信息
+// dedup 可能将各版本相同的记录硬链接为同一个文件，先写临时文件再替换，不修改其它版本的记录
+func WriteMetadata(downloads, dirName string, m Metadata) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	filename := filepath.Join(downloads, dirName, metadataFile)
+	tmp := filename + ".tmp"
+	if err = os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
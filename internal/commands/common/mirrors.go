@@ -0,0 +1,33 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/mirrors"
+	"github.com/FirewineXie/envm/util"
+)
+
+// FromMirrors 依次从 config.toml 中 language 的下载镜像执行 download，pkg.URL 及 pkg.ChecksumURL 中的 official 前缀替换为镜像地址
+// 镜像无法访问或返回的文件与版本列表不一致时尝试下一个；校验和不匹配时不再尝试，避免从其它镜像换到同样被篡改的文件
+func FromMirrors(ctx context.Context, language, official string, pkg *util.Package, download func() error) error {
+	list := config.Mirrors(language)
+	url, checksumURL := pkg.URL, pkg.ChecksumURL
+	var err error
+	for i, mirror := range list {
+		pkg.URL = mirrors.Rewrite(url, official, mirror)
+		pkg.ChecksumURL = mirrors.Rewrite(checksumURL, official, mirror)
+		err = download()
+		if err == nil || ctx.Err() != nil || i == len(list)-1 {
+			return err
+		}
+		if !errors.Is(err, util.ErrNetwork) && !errors.Is(err, util.ErrArtifactMismatch) {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "warning: %v, trying the next mirror %s\n", err, list[i+1])
+	}
+	return err
+}
@@ -0,0 +1,40 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/pinning"
+	"github.com/FirewineXie/envm/internal/logic/selfcheck"
+	"github.com/FirewineXie/envm/util"
+)
+
+// PinChecksum 校验通过后按文件名记录安装包的 sha256，见 pinning
+// 与第一次安装时记录的不一致时醒目地警告，镜像的版本列表及安装包可能同时被篡改，仅靠列表中的校验和无法发现
+// 记录失败只提示，不影响安装
+func PinChecksum(pkg *util.Package, filename string) {
+	sum, err := selfcheck.Sum(filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: pin checksum error + %v\n", err)
+		return
+	}
+	source := pkg.FinalURL
+	if source == "" {
+		source = pkg.URL
+	}
+	err = pinning.Check(config.ChecksumsFile(), filepath.Base(filename), pinning.Pin{SHA256: sum, Source: source})
+	var mismatch *pinning.MismatchError
+	if errors.As(err, &mismatch) {
+		banner := strings.Repeat("!", 72)
+		fmt.Fprintf(os.Stderr, "%s\nWARNING: %v\n%s\n", banner, err, banner)
+		util.Emit(util.Event{Event: util.EventChecksumChanged, Error: err.Error()})
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: pin checksum error + %v\n", err)
+	}
+}
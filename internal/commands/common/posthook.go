@@ -0,0 +1,42 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/util"
+)
+
+// linkedVersion 返回 symlink 当前指向的版本，未激活时返回空
+func linkedVersion(symlink string) string {
+	target, err := readLink(symlink)
+	if err != nil {
+		return ""
+	}
+	_, version := versionDir(filepath.Clean(target))
+	return version
+}
+
+// postUse 切换成功后执行 config.toml 中的 post_use，命令通过 sh -c (windows 下 cmd /C) 执行
+// 环境变量 ENVM_LANG、ENVM_OLD_VERSION(之前没有激活的版本时为空)、ENVM_NEW_VERSION 描述本次切换
+// 切换已经完成，命令失败只提示
+func postUse(dir, previous string) {
+	command := config.Default().Settings.PostUse
+	if command == "" {
+		return
+	}
+	language, version := versionDir(dir)
+	cmd := exec.Command("sh", "-c", command)
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	}
+	cmd.Env = append(os.Environ(), "ENVM_LANG="+language, "ENVM_OLD_VERSION="+previous, "ENVM_NEW_VERSION="+version)
+	cmd.Stdout, cmd.Stderr = util.Output, os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: post_use %q error + %v\n", command, err)
+	}
+}
@@ -0,0 +1,87 @@
+package common
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/elevate"
+	"github.com/FirewineXie/envm/internal/logic/writable"
+)
+
+// PermissionError 安装需要写入的目录没有权限，Remedy 说明提权或更换 ENVM_HOME 能否解决
+type PermissionError struct {
+	// Dir 没有写入权限的目录，安装目录不存在时为最近的已存在的上级目录
+	Dir string
+	// Purpose 该目录的用途，例如 go install directory
+	Purpose string
+	Remedy  string
+	Err     error
+}
+
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("can not write to %s (%s): permission denied\n  %s", e.Dir, e.Purpose, e.Remedy)
+}
+
+func (e *PermissionError) Unwrap() error {
+	return e.Err
+}
+
+// Preflight 下载前检查安装目录及 symlink 所在目录是否可以写入，避免下载几分钟后才因为权限失败
+// portable 模式不创建 symlink，只检查安装目录；只读模式下直接拒绝
+func Preflight(name string, link config.SubConfig) error {
+	if err := CheckWritable("installing " + name); err != nil {
+		return err
+	}
+	checks := []struct{ purpose, dir string }{{name + " install directory", link.Downloads}}
+	if link.Symlink != "" && !config.Default().Settings.Portable {
+		checks = append(checks, struct{ purpose, dir string }{name + " symlink directory", filepath.Dir(link.Symlink)})
+	}
+	for _, check := range checks {
+		dir, err := writable.Check(check.dir)
+		if err == nil {
+			continue
+		}
+		if !elevate.Required(err) {
+			return err
+		}
+		err = &PermissionError{Dir: dir, Purpose: check.purpose, Remedy: remedy(name, check.dir), Err: err}
+		if elevate.Supported {
+			return Elevate(err)
+		}
+		return err
+	}
+	return nil
+}
+
+// remedy 根据目录所在的位置给出解决办法
+func remedy(name, dir string) string {
+	elevation := "sudo -E"
+	if elevate.Supported {
+		elevation = "an administrator prompt"
+	}
+	switch {
+	case elevate.IsElevated():
+		return "envm already runs with administrator rights, check the owner, ACL and mount options of the directory"
+	case config.IsShared() && within(dir, config.SharedRoot()):
+		return fmt.Sprintf("it belongs to the shared root %s, run it with %s or ask an administrator for write access, "+
+			"a different ENVM_HOME does not help unless the shared root is unset", config.SharedRoot(), elevation)
+	case within(dir, config.Default().Root):
+		return fmt.Sprintf("ENVM_HOME %s is not writable by you, fix its owner or set ENVM_HOME to a writable directory, "+
+			"running with %s would leave files you can not modify later", config.Default().Root, elevation)
+	default:
+		return fmt.Sprintf("the directory is outside ENVM_HOME, run it with %s or set ENVM_%s_SYMLINK to a writable location, "+
+			"a different ENVM_HOME does not help", elevation, strings.ToUpper(name))
+	}
+}
+
+// within path 是否位于 dir 中，windows 上不区分大小写
+func within(path, dir string) bool {
+	path, dir = filepath.Clean(path), filepath.Clean(dir)
+	if runtime.GOOS == "windows" {
+		path, dir = strings.ToLower(path), strings.ToLower(dir)
+	}
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
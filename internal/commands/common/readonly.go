@@ -0,0 +1,55 @@
+package common
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/elevate"
+	"github.com/FirewineXie/envm/internal/logic/writable"
+)
+
+// ReadOnlyError 只读模式下拒绝安装及卸载
+type ReadOnlyError struct {
+	// Action 被拒绝的操作，例如 installing go1.22.3
+	Action string
+	// Reason 进入只读模式的原因
+	Reason string
+}
+
+func (e *ReadOnlyError) Error() string {
+	return fmt.Sprintf("envm is read-only here (%s), %s is refused\n"+
+		"  the versions provisioned by the administrator can still be listed with envm ls and switched with envm <language> active, "+
+		"ask an administrator to install or uninstall versions", e.Reason, e.Action)
+}
+
+var readOnly struct {
+	once   sync.Once
+	reason string
+}
+
+// ReadOnlyReason 返回进入只读模式的原因，可以安装时返回空
+// 除了 read_only 及 ENVM_READ_ONLY，使用共享安装目录且当前用户(非管理员)没有写入权限时同样为只读，例如锁定的构建机
+// 只检查一次，同一个命令中多次安装不重复创建临时文件
+func ReadOnlyReason() string {
+	readOnly.once.Do(func() {
+		if readOnly.reason = config.ReadOnlyReason(); readOnly.reason != "" {
+			return
+		}
+		if !config.IsShared() || elevate.IsElevated() {
+			return
+		}
+		if dir, err := writable.Check(config.Default().Downloads); elevate.Required(err) {
+			readOnly.reason = fmt.Sprintf("%s in the shared root is not writable by you", dir)
+		}
+	})
+	return readOnly.reason
+}
+
+// CheckWritable 只读模式下返回 ReadOnlyError，action 为被拒绝的操作
+func CheckWritable(action string) error {
+	if reason := ReadOnlyReason(); reason != "" {
+		return &ReadOnlyError{Action: action, Reason: reason}
+	}
+	return nil
+}
@@ -4,10 +4,12 @@ import (
 	"errors"
 	"github.com/blang/semver/v4"
 	"github.com/urfave/cli"
-	"io/ioutil"
 	"os/exec"
 	"path"
+	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -46,32 +48,72 @@ func GetCurrentVersion(language string) (version string) {
 
 }
 
-// 获取下载的版本列表
+// 获取下载的版本列表，按版本号倒序
+// 目录名保持原样返回，兼容 gradle 8.7 这类非严格 semver 的版本号及 erlang 26.2.1.1 这类四段的版本号
+// 目录扫描结果按目录修改时间缓存，见 installedDirs
 func GetInstalled(root string, language string) []string {
-	list := make([]semver.Version, 0)
-	files, _ := ioutil.ReadDir(path.Clean(root))
-	for i := len(files) - 1; i >= 0; i-- {
-		if files[i].IsDir() {
-			isGo, _ := regexp.MatchString(language, files[i].Name())
-			if isGo {
-				currentVersionString := strings.Replace(files[i].Name(), language, "", 1)
-				if currentVersion, err := semver.Make(currentVersionString); err == nil {
-					list = append(list, currentVersion)
-				}
-
+	type installed struct {
+		name    string
+		version semver.Version
+		// extra 第四段及之后的版本号，前三段相同时比较
+		extra []uint64
+	}
+	list := make([]installed, 0)
+	// envm link 登记的外部目录可以使用任意名称，例如 custom-pgo，排在所有版本号之后
+	named := make([]string, 0)
+	dirs := installedDirs(path.Clean(root))
+	for i := len(dirs) - 1; i >= 0; i-- {
+		isGo, _ := regexp.MatchString(language, dirs[i])
+		if isGo {
+			currentVersionString := strings.Replace(dirs[i], language, "", 1)
+			if currentVersion, err := semver.ParseTolerant(currentVersionString); err == nil {
+				list = append(list, installed{currentVersionString, currentVersion, nil})
+			} else if currentVersion, extra, ok := parseLongVersion(currentVersionString); ok {
+				list = append(list, installed{currentVersionString, currentVersion, extra})
+			} else if currentVersionString != "" && linkedDir(filepath.Join(root, dirs[i])) {
+				named = append(named, currentVersionString)
 			}
+
 		}
 	}
 
-	semver.Sort(list)
+	sort.SliceStable(list, func(i, j int) bool {
+		if c := list[i].version.Compare(list[j].version); c != 0 {
+			return c < 0
+		}
+		for k := 0; k < len(list[i].extra) && k < len(list[j].extra); k++ {
+			if list[i].extra[k] != list[j].extra[k] {
+				return list[i].extra[k] < list[j].extra[k]
+			}
+		}
+		return len(list[i].extra) < len(list[j].extra)
+	})
 
 	loggableList := make([]string, 0)
 
 	for _, version := range list {
-		loggableList = append(loggableList, version.String())
+		loggableList = append(loggableList, version.name)
 	}
 	loggableList = reverseStringArray(loggableList)
-	return loggableList
+	sort.Strings(named)
+	return append(loggableList, named...)
+}
+
+// parseLongVersion 解析 semver 不支持的四段及以上的纯数字版本号(26.2.1.1)，前三段作为 semver 版本，其余返回 extra
+func parseLongVersion(version string) (v semver.Version, extra []uint64, ok bool) {
+	parts := strings.Split(version, ".")
+	if len(parts) <= 3 {
+		return v, nil, false
+	}
+	numbers := make([]uint64, 0, len(parts))
+	for _, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return v, nil, false
+		}
+		numbers = append(numbers, n)
+	}
+	return semver.Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, numbers[3:], true
 }
 
 func reverseStringArray(str []string) []string {
@@ -1,21 +1,41 @@
 package common
 
 import (
-	"fmt"
-	. "github.com/smartystreets/goconvey/convey"
+	"os"
+	"path/filepath"
 	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
 )
 
 func Test_getCurrentVersion(t *testing.T) {
-	Convey("验证go 当前版本", t, func() {
-		version := getCurrentVersion()
-		So(version, ShouldEqual, "go1.17.1")
+	Convey("命令不存在时版本未知", t, func() {
+		So(GetCurrentVersion("envm-no-such-command"), ShouldEqual, "Unknown")
 	})
 }
 
 func Test_getInstalled(t *testing.T) {
 	Convey("测试已经安装的go 版本", t, func() {
-		fmt.Println(getInstalled("D:\\programs"))
+		root := t.TempDir()
+		for _, name := range []string{"go1.9.1", "go1.22.3", "go1.21.0", "go1.22.3.tmp"} {
+			So(os.Mkdir(filepath.Join(root, name), os.ModePerm), ShouldBeNil)
+		}
+		So(os.WriteFile(filepath.Join(root, "go1.20.0.lock"), nil, 0644), ShouldBeNil)
+
+		So(GetInstalled(root, "go"), ShouldResemble, []string{"1.22.3", "1.21.0", "1.9.1"})
+		So(IsInstalled(root, "go", "1.21.0"), ShouldBeTrue)
+		So(IsInstalled(root, "go", "1.20.0"), ShouldBeFalse)
+	})
+}
+
+func Test_getInstalledLongVersion(t *testing.T) {
+	Convey("插件安装的四段版本号", t, func() {
+		root := t.TempDir()
+		for _, name := range []string{"erlang26.2.1.1", "erlang26.2.1", "erlang26.2.1.10", "erlang25.3.2.8", "erlang26.2.1.1.download"} {
+			So(os.Mkdir(filepath.Join(root, name), os.ModePerm), ShouldBeNil)
+		}
 
+		So(GetInstalled(root, "erlang"), ShouldResemble, []string{"26.2.1.10", "26.2.1.1", "26.2.1", "25.3.2.8"})
+		So(IsInstalled(root, "erlang", "26.2.1.1"), ShouldBeTrue)
 	})
 }
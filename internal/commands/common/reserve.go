@@ -0,0 +1,47 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/FirewineXie/envm/util"
+)
+
+// reservation 正在安装某个版本的进程，写入 <downloads>/<dirName>.install.json，等待的进程据此提示
+type reservation struct {
+	PID     int       `json:"pid"`
+	Command string    `json:"command"`
+	Started time.Time `json:"started"`
+}
+
+// Reserve 安装 <downloads>/<dirName> 前锁定该版本，其它进程正在安装同一个版本时提示后等待
+// 等待结束后调用者需要再次检查是否已经安装，直接使用对方安装的结果；进程退出时锁由系统释放，崩溃后不需要手动清理
+func Reserve(downloads, dirName string) (release func(), err error) {
+	if err = CheckWritable("installing " + dirName); err != nil {
+		return nil, err
+	}
+	lockFile := filepath.Join(downloads, dirName+".install.lock")
+	statusFile := filepath.Join(downloads, dirName+".install.json")
+	unlock, err := util.LockFileWait(lockFile, func() {
+		message := fmt.Sprintf("%s is being installed by another envm process", dirName)
+		var holder reservation
+		if data, err := os.ReadFile(statusFile); err == nil && json.Unmarshal(data, &holder) == nil {
+			message = fmt.Sprintf("%s is being installed by pid %d (%s) since %s", dirName, holder.PID, holder.Command, holder.Started.Format(time.TimeOnly))
+		}
+		fmt.Fprintln(os.Stderr, message+", waiting for it to finish")
+	})
+	if err != nil {
+		return nil, err
+	}
+	// 状态文件只用于提示，写入失败(例如共享目录中其它用户创建的文件)不影响安装
+	data, _ := json.Marshal(reservation{PID: os.Getpid(), Command: strings.Join(append([]string{filepath.Base(os.Args[0])}, os.Args[1:]...), " "), Started: time.Now()})
+	_ = os.WriteFile(statusFile, data, 0644)
+	return func() {
+		_ = os.Remove(statusFile)
+		unlock()
+	}, nil
+}
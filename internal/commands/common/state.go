@@ -0,0 +1,137 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/relocate"
+	"github.com/FirewineXie/envm/util"
+)
+
+// readState 读取 symlink 到版本目录的映射
+// portable 模式下代替 symlink，其它模式下记录期望的指向，symlink 被杀毒、同步软件破坏后据此修复
+func readState() map[string]string {
+	state := map[string]string{}
+	data, err := os.ReadFile(config.StateFile())
+	if err == nil {
+		_ = json.Unmarshal(data, &state)
+	}
+	return state
+}
+
+// setState 记录 symlink 指向的目录，先写入临时文件并刷到磁盘再重命名，中途崩溃也不会留下损坏的 state.json
+func setState(symlink, target string) error {
+	state := readState()
+	state[symlink] = target
+	return writeState(state)
+}
+
+// writeState 写入 state.json，见 setState
+func writeState(state map[string]string) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(config.StateFile()), os.ModePerm); err != nil {
+		return err
+	}
+	tmp := config.StateFile() + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, config.StateFile())
+}
+
+// readLink 返回 symlink 指向的目录，portable 模式下从 state.json 读取，activation = "copy" 时为复制的来源
+func readLink(symlink string) (string, error) {
+	if !config.Default().Settings.Portable {
+		target, err := os.Readlink(symlink)
+		if err != nil {
+			// 与 activation 的当前配置无关，切换配置后仍然可以读取之前复制的目录
+			if source, copyErr := copiedSource(symlink); copyErr == nil {
+				return source, nil
+			}
+		}
+		return target, err
+	}
+	if target, ok := readState()[symlink]; ok {
+		return target, nil
+	}
+	return "", os.ErrNotExist
+}
+
+// Retarget 安装目录从 oldRoot 迁移到 newRoot 后，将各语言的 symlink、工具的链接及 state.json 中指向 oldRoot 下的路径改为 newRoot 下的同一个路径
+// 返回修改的链接数量，portable 模式下只修改 state.json
+func Retarget(oldRoot, newRoot string) (int, error) {
+	links := map[string]bool{}
+	for name, link := range config.Default().LinkSetting {
+		if name != config.TOOL && link.Symlink != "" {
+			links[link.Symlink] = true
+		}
+	}
+	// 工具的可执行文件分别链接到 <root>/bin 中
+	if entries, err := os.ReadDir(config.Default().LinkSetting[config.TOOL].Symlink); err == nil {
+		for _, entry := range entries {
+			links[filepath.Join(config.Default().LinkSetting[config.TOOL].Symlink, entry.Name())] = true
+		}
+	}
+	state := readState()
+	for symlink := range state {
+		links[symlink] = true
+	}
+
+	count := 0
+	if !config.Default().Settings.Portable {
+		for symlink := range links {
+			target, err := os.Readlink(symlink)
+			if err != nil {
+				// 复制得到的目录不需要移动，只修改记录的来源
+				if source, err := copiedSource(symlink); err == nil {
+					if rebased, ok := relocate.Rebase(source, oldRoot, newRoot); ok {
+						if err = os.WriteFile(filepath.Join(symlink, sourceMarker), []byte(rebased), 0644); err != nil {
+							return count, fmt.Errorf("update %s error + %w", symlink, err)
+						}
+						count++
+					}
+				}
+				continue
+			}
+			rebased, ok := relocate.Rebase(target, oldRoot, newRoot)
+			if !ok {
+				continue
+			}
+			_ = os.Remove(symlink)
+			if err = util.Symlink(rebased, symlink); err != nil {
+				return count, fmt.Errorf("relink %s error + %w", symlink, err)
+			}
+			count++
+		}
+	}
+	changed := false
+	for symlink, target := range state {
+		if rebased, ok := relocate.Rebase(target, oldRoot, newRoot); ok {
+			state[symlink], changed = rebased, true
+			if config.Default().Settings.Portable {
+				count++
+			}
+		}
+	}
+	if changed {
+		return count, writeState(state)
+	}
+	return count, nil
+}
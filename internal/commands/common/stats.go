@@ -0,0 +1,26 @@
+package common
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/stats"
+)
+
+// RecordStats 更新本地的使用统计，配置了 disable_stats 时不记录；统计只用于 envm stats，失败时忽略，不影响命令
+func RecordStats(fn func(s *stats.Stats)) {
+	if config.Default().Settings.DisableStats {
+		return
+	}
+	_ = stats.Update(config.StatsFile(), fn)
+}
+
+// RecordDownload 用作 util.Downloaded，按下载地址的域名累计下载的字节数及耗时
+func RecordDownload(rawURL string, bytes int64, elapsed time.Duration) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return
+	}
+	RecordStats(func(s *stats.Stats) { s.AddDownload(u.Hostname(), bytes, elapsed) })
+}
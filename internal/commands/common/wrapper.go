@@ -0,0 +1,59 @@
+package common
+
+import (
+	"crypto/md5"
+	"errors"
+	"math/big"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/FirewineXie/envm/util"
+)
+
+// ErrWrapperNotFound 当前目录及上级目录中没有 wrapper 配置
+var ErrWrapperNotFound = errors.New("wrapper properties not found")
+
+// ReadWrapperURL 从 dir 向上查找 wrapper 配置文件，返回其中的 distributionUrl
+func ReadWrapperURL(dir, properties string) (string, error) {
+	filename, ok := util.FindUpward(dir, filepath.FromSlash(properties))
+	if !ok {
+		return "", ErrWrapperNotFound
+	}
+	values, err := util.ReadProperties(filename)
+	if err != nil {
+		return "", err
+	}
+	url := values["distributionUrl"]
+	if url == "" {
+		return "", ErrWrapperNotFound
+	}
+	return url, nil
+}
+
+// WrapperDistDir 计算 wrapper 解压发行包的目录 <userHome>/wrapper/dists/<name>/<hash>
+// hash 算法与 gradle wrapper(以及 maven-wrapper 3.2 之前的 jar 版本) 的 PathAssembler 一致
+func WrapperDistDir(userHome, distributionURL string) string {
+	baseName := path.Base(distributionURL)
+	distName := strings.TrimSuffix(strings.TrimSuffix(baseName, ".zip"), ".tar.gz")
+	sum := md5.Sum([]byte(distributionURL))
+	hash := new(big.Int).SetBytes(sum[:]).Text(36)
+	return filepath.Join(userHome, "wrapper", "dists", distName, hash)
+}
+
+// SeedWrapper 将已安装的版本登记到 wrapper 的缓存目录并写入 .ok 标记，使 wrapper 离线可用
+func SeedWrapper(userHome, distributionURL, installDir, dirName string) (string, error) {
+	distDir := WrapperDistDir(userHome, distributionURL)
+	if err := os.MkdirAll(distDir, os.ModePerm); err != nil {
+		return "", err
+	}
+	link := filepath.Join(distDir, dirName)
+	if exists, _ := util.PathExists(link); !exists {
+		if err := util.Symlink(installDir, link); err != nil {
+			return "", err
+		}
+	}
+	marker := filepath.Join(distDir, path.Base(distributionURL)+".ok")
+	return distDir, os.WriteFile(marker, nil, 0644)
+}
@@ -0,0 +1,479 @@
+// Package languages 汇总 envm 支持的语言，供 sync 等需要按名称批量操作的命令使用
+package languages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/FirewineXie/envm/internal/commands/commands-bun"
+	"github.com/FirewineXie/envm/internal/commands/commands-deno"
+	"github.com/FirewineXie/envm/internal/commands/commands-flutter"
+	"github.com/FirewineXie/envm/internal/commands/commands-go"
+	"github.com/FirewineXie/envm/internal/commands/commands-gradle"
+	"github.com/FirewineXie/envm/internal/commands/commands-java"
+	"github.com/FirewineXie/envm/internal/commands/commands-maven"
+	"github.com/FirewineXie/envm/internal/commands/commands-node"
+	"github.com/FirewineXie/envm/internal/commands/commands-php"
+	"github.com/FirewineXie/envm/internal/commands/commands-zig"
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/health"
+	"github.com/FirewineXie/envm/internal/logic/index"
+	"github.com/FirewineXie/envm/internal/logic/listing"
+	"github.com/FirewineXie/envm/internal/logic/normalize"
+	"github.com/FirewineXie/envm/internal/logic/project"
+	"github.com/FirewineXie/envm/internal/logic/stats"
+	"github.com/FirewineXie/envm/util"
+)
+
+// Language 语言的安装与激活入口
+type Language struct {
+	// Name envm 中的名称，同 config 中的常量
+	Name string
+	// Aliases 其它工具(如 asdf)中使用的名称
+	Aliases []string
+	// Prefix 安装目录的前缀，<downloads>/<Prefix><version>
+	Prefix string
+	// Bin 可执行文件在安装目录中的相对路径，为空表示在安装目录下
+	Bin string
+	// UnixBin 与 windows 上的 Bin 不同时为 linux/macOS 上的 Bin，生成容器配置时使用
+	UnixBin string
+	// HomeEnv 指向安装目录的环境变量，例如 GOROOT、JAVA_HOME
+	HomeEnv string
+	// Supplier 发布安装包的组织，写入 SBOM
+	Supplier string
+	// Env 版本需要的其它环境变量，可以为空
+	Env func(version string) map[string]string
+	// ToolBin 为该版本单独构建的工具的目录，例如 envm go tools 安装的 gopls，可以为空
+	ToolBin  func(version string) string
+	Install  func(ctx context.Context, version string) error
+	Activate func(version string) error
+	// ListRemote 返回可以安装的版本，需要访问网络
+	ListRemote func() ([]string, error)
+	// Releases 返回可以安装的版本及其类型、平台，需要访问网络，按类型或平台过滤时使用，可以为空
+	Releases func() ([]listing.Release, error)
+	// Advisories 返回包含安全修复的版本，需要访问网络，没有发布说明来源的语言为空
+	Advisories func() ([]string, error)
+}
+
+// Link 返回该语言的 symlink 及下载目录配置
+func (l *Language) Link() config.SubConfig {
+	return config.Default().LinkSetting[l.Name]
+}
+
+// Preflight 下载前检查安装目录及 symlink 所在目录是否可以写入
+func (l *Language) Preflight() error {
+	return common.Preflight(l.Name, l.Link())
+}
+
+// LinuxBin 返回 linux 上可执行文件在安装目录中的相对路径
+func (l *Language) LinuxBin() string {
+	if l.UnixBin != "" {
+		return l.UnixBin
+	}
+	return l.Bin
+}
+
+// bin 个别语言在 windows 上将可执行文件放在安装目录下
+func bin(unix, windows string) string {
+	if runtime.GOOS == "windows" {
+		return windows
+	}
+	return unix
+}
+
+var languages = []*Language{
+	{Name: config.GO, Aliases: []string{"golang"}, Prefix: config.GO, Bin: "bin", HomeEnv: "GOROOT", Env: commands_go.Env, ToolBin: commands_go.ToolsBin, Supplier: "Google LLC", Install: commands_go.Install, Activate: commands_go.Activate, ListRemote: commands_go.ListRemote, Releases: commands_go.Releases, Advisories: commands_go.SecurityReleases},
+	{Name: config.JAVA, Prefix: "jdk-", Bin: "bin", HomeEnv: "JAVA_HOME", Supplier: "Oracle Corporation", Install: commands_java.Install, Activate: commands_java.Activate, ListRemote: commands_java.ListRemote},
+	{Name: config.NODE, Aliases: []string{"nodejs"}, Prefix: config.NODE, Bin: bin("bin", ""), UnixBin: "bin", Supplier: "OpenJS Foundation", Install: commands_node.Install, Activate: commands_node.Activate, ListRemote: commands_node.ListRemote, Releases: commands_node.Releases, Advisories: commands_node.SecurityReleases},
+	{Name: config.DENO, Prefix: config.DENO, Bin: "bin", Supplier: "Deno Land Inc.", Install: commands_deno.Install, Activate: commands_deno.Activate, ListRemote: commands_deno.ListRemote, Releases: commands_deno.Releases},
+	{Name: config.BUN, Prefix: config.BUN, Bin: "bin", Supplier: "Oven", Install: commands_bun.Install, Activate: commands_bun.Activate, ListRemote: commands_bun.ListRemote, Releases: commands_bun.Releases},
+	{Name: config.ZIG, Prefix: config.ZIG, Supplier: "Zig Software Foundation", Install: commands_zig.Install, Activate: commands_zig.Activate, ListRemote: commands_zig.ListRemote, Releases: commands_zig.Releases},
+	{Name: config.MAVEN, Aliases: []string{"maven"}, Prefix: config.MAVEN, Bin: "bin", HomeEnv: "MAVEN_HOME", Supplier: "The Apache Software Foundation", Install: commands_maven.Install, Activate: commands_maven.Activate, ListRemote: commands_maven.ListRemote, Releases: commands_maven.Releases},
+	{Name: config.GRADLE, Prefix: config.GRADLE, Bin: "bin", HomeEnv: "GRADLE_HOME", Supplier: "Gradle Inc.", Install: commands_gradle.Install, Activate: commands_gradle.Activate, ListRemote: commands_gradle.ListRemote, Releases: commands_gradle.Releases},
+	{Name: config.PHP, Prefix: config.PHP, Bin: bin("bin", ""), UnixBin: "bin", Supplier: "The PHP Group", Install: commands_php.Install, Activate: commands_php.Activate, ListRemote: commands_php.ListRemote, Releases: commands_php.Releases},
+	{Name: config.FLUTTER, Prefix: config.FLUTTER, Bin: "bin", HomeEnv: "FLUTTER_ROOT", Supplier: "Google LLC", Install: commands_flutter.Install, Activate: commands_flutter.Activate, ListRemote: commands_flutter.ListRemote, Releases: commands_flutter.Releases},
+}
+
+// All 返回所有支持的语言
+func All() []*Language {
+	return languages
+}
+
+// Find 根据名称或别名查找语言，不支持时返回 nil
+func Find(name string) *Language {
+	for _, language := range languages {
+		if language.Name == name {
+			return language
+		}
+		for _, alias := range language.Aliases {
+			if alias == name {
+				return language
+			}
+		}
+	}
+	return nil
+}
+
+// AsdfName 返回语言在 asdf 中的名称
+func (l *Language) AsdfName() string {
+	if len(l.Aliases) > 0 {
+		return l.Aliases[0]
+	}
+	return l.Name
+}
+
+// CurrentVersion 返回当前激活的版本，未激活时返回空
+func (l *Language) CurrentVersion() string {
+	return common.GetLinkedVersion(l.Link().Symlink, l.Prefix)
+}
+
+// InstallDir 返回版本的安装目录
+func (l *Language) InstallDir(version string) string {
+	return filepath.Join(l.Link().Downloads, l.Prefix+version)
+}
+
+// Check 检查版本的安装目录是否完整，未安装或不完整时返回 *health.BrokenError
+func (l *Language) Check(version string) error {
+	return health.Check(l.Name, l.InstallDir(version), version)
+}
+
+// BinDir 返回版本的可执行文件目录
+func (l *Language) BinDir(version string) string {
+	return filepath.Join(l.InstallDir(version), l.Bin)
+}
+
+// BinDirs 返回版本的可执行文件目录，存在 ToolBin 时包含在后面
+func (l *Language) BinDirs(version string) []string {
+	dirs := []string{l.BinDir(version)}
+	if l.ToolBin != nil {
+		if exists, _ := util.PathExists(l.ToolBin(version)); exists {
+			dirs = append(dirs, l.ToolBin(version))
+		}
+	}
+	return dirs
+}
+
+// Environ 返回使用该版本时需要设置的环境变量，包括 HomeEnv 及 config.toml 中为该版本配置的 version_env
+func (l *Language) Environ(version string) map[string]string {
+	env := map[string]string{}
+	if l.HomeEnv != "" {
+		env[l.HomeEnv] = l.InstallDir(version)
+	}
+	if l.Env != nil {
+		for name, value := range l.Env(version) {
+			env[name] = value
+		}
+	}
+	for name, value := range config.VersionEnv(l.Name, version) {
+		env[name] = value
+	}
+	return env
+}
+
+// AutoInstall 版本没有安装且配置了 auto_install 时安装，返回安装的具体版本及版本是否已经安装
+// 部分版本号(1.22)安装远程版本列表中最新的匹配版本，提示写到 stderr，安装过程的输出写到 util.Output
+// 不修改进程级的输出，envm daemon 可以在后台 goroutine 中调用；stdout 会被 eval 的命令先调用 RedirectInstallOutput
+func (l *Language) AutoInstall(version string) (string, bool) {
+	if common.IsInstalled(l.Link().Downloads, l.Prefix, version) {
+		return version, true
+	}
+	if !config.Default().Settings.AutoInstall {
+		return version, false
+	}
+	if resolved, err := l.ResolveVersion(version); err == nil && resolved != version {
+		fmt.Fprintf(os.Stderr, "envm: %s %s -> %s\n", l.Name, version, resolved)
+		version = resolved
+	}
+	fmt.Fprintf(os.Stderr, "envm: installing %s %s\n", l.Name, version)
+	err := l.Preflight()
+	if err == nil {
+		err = l.Install(context.Background(), version)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "envm: install %s %s failed + %v\n", l.Name, version, err)
+		return version, false
+	}
+	return version, true
+}
+
+// RedirectInstallOutput 将安装过程的输出(包括直接写到 stdout 的)重定向到 stderr 并不显示进度，返回恢复的函数
+// shell hook 等 stdout 会被 eval 的命令在自动安装前调用；修改的是进程级的 os.Stdout，只能在命令的主流程中调用
+func RedirectInstallOutput() (restore func()) {
+	stdout, output, quiet := os.Stdout, util.Output, util.Quiet
+	os.Stdout, util.Output, util.Quiet = os.Stderr, os.Stderr, true
+	return func() {
+		os.Stdout, util.Output, util.Quiet = stdout, output, quiet
+	}
+}
+
+// EnsureInstalled 将 input 解析为已经安装的版本，没有安装时不论 auto_install 都安装，用于 envm try 及 test-matrix 等明确需要该版本的命令
+// 部分版本号(1.22)优先选择已经安装的匹配版本，否则安装远程版本列表中最新的匹配版本
+func (l *Language) EnsureInstalled(input string) (string, error) {
+	version := l.InstalledVersion(input)
+	if common.IsInstalled(l.Link().Downloads, l.Prefix, version) {
+		return version, nil
+	}
+	if resolved, err := l.ResolveVersion(input); err == nil {
+		version = resolved
+	}
+	fmt.Fprintf(os.Stderr, "installing %s %s\n", l.Name, version)
+	if err := l.Preflight(); err != nil {
+		return "", err
+	}
+	if err := l.Install(context.Background(), version); err != nil {
+		return "", fmt.Errorf("install %s %s error + %w", l.Name, version, err)
+	}
+	return version, nil
+}
+
+// RemoteVersions 返回可以安装的版本并更新缓存，缓存在有效期内或配置了 offline 时直接使用缓存
+// refresh 为 true 时忽略有效期重新获取
+func (l *Language) RemoteVersions(refresh bool) ([]string, error) {
+	offline := config.Default().Settings.Offline
+	cached, err := index.Read(config.IndexDir(), l.Name)
+	if err == nil && (offline || !refresh && cached.Fresh(config.IndexTTL())) {
+		common.RecordStats(func(s *stats.Stats) { s.IndexHits++ })
+		return cached.Versions, nil
+	}
+	if offline {
+		return nil, fmt.Errorf("offline is set and there is no cached version list of %s", l.Name)
+	}
+	common.RecordStats(func(s *stats.Stats) { s.IndexMisses++ })
+	versions, err := l.ListRemote()
+	if err == nil && len(versions) == 0 {
+		err = fmt.Errorf("%w: %s", util.ErrNoVersions, l.Name)
+	}
+	if errors.Is(err, util.ErrNoVersions) {
+		// 来源可以访问但没有版本，通常是页面结构变化，使用过期的缓存而不是当作没有可以安装的版本
+		if cached != nil && len(cached.Versions) > 0 {
+			fmt.Fprintf(os.Stderr, "envm: %v, using the version list cached at %s\n", err, cached.FetchedAt.Format(time.DateTime))
+			return cached.Versions, nil
+		}
+		return nil, fmt.Errorf("%w, and there is no cached version list", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	_ = index.Write(config.IndexDir(), l.Name, versions)
+	return versions, nil
+}
+
+// RemoteReleases 按过滤条件返回可以安装的版本，需要按类型或平台过滤时重新获取带有类型及平台的版本，否则同 RemoteVersions
+func (l *Language) RemoteReleases(refresh bool, f listing.Filter) ([]listing.Release, error) {
+	if l.Releases != nil && (f.Kind != "" || f.OS != "" || f.Arch != "") {
+		if config.Default().Settings.Offline {
+			return nil, fmt.Errorf("offline is set, --kind, --os and --arch need the remote version list of %s", l.Name)
+		}
+		return l.Releases()
+	}
+	versions, err := l.RemoteVersions(refresh)
+	if err != nil {
+		return nil, err
+	}
+	return listing.FromVersions(versions), nil
+}
+
+// SecurityReleases 返回包含安全修复的版本并更新缓存，缓存策略同 RemoteVersions，没有来源时返回空
+func (l *Language) SecurityReleases(refresh bool) ([]string, error) {
+	if l.Advisories == nil {
+		return nil, nil
+	}
+	name := l.Name + "-security"
+	offline := config.Default().Settings.Offline
+	cached, err := index.Read(config.IndexDir(), name)
+	if err == nil && (offline || !refresh && cached.Fresh(config.IndexTTL())) {
+		return cached.Versions, nil
+	}
+	if offline {
+		return nil, fmt.Errorf("offline is set and there is no cached security releases of %s", l.Name)
+	}
+	versions, err := l.Advisories()
+	if err != nil {
+		return nil, err
+	}
+	_ = index.Write(config.IndexDir(), name, versions)
+	return versions, nil
+}
+
+// ResolveVersion 将 latest 及部分版本号(1.22)解析为远程版本列表中的具体版本，已经安装的版本不访问网络
+// lts 等其它关键字只有各语言的 install 命令支持
+func (l *Language) ResolveVersion(input string) (string, error) {
+	if input == "latest" {
+		versions, err := l.RemoteVersions(false)
+		if err != nil {
+			return "", err
+		}
+		if version := index.Latest(versions); version != "" {
+			return version, nil
+		}
+		return "", util.ErrVersionNotFound
+	}
+	if normalize.Keywords[input] {
+		return "", fmt.Errorf("%s is not supported here, use latest or a version number", input)
+	}
+	// envm link 登记的名称(custom-pgo)不是版本号，原样使用
+	if common.IsInstalled(l.Link().Downloads, l.Prefix, input) {
+		return input, nil
+	}
+	version, err := normalize.Clean(l.Name, input)
+	if err != nil {
+		return "", err
+	}
+	if common.IsInstalled(l.Link().Downloads, l.Prefix, version) {
+		return version, nil
+	}
+	// 部分语言的远程列表只包含较新的版本，查不到时原样返回
+	candidates, _ := l.RemoteVersions(false)
+	if resolved, ok := normalize.Resolve(version, candidates); ok {
+		return resolved, nil
+	}
+	return version, nil
+}
+
+// InstalledVersion 将 input 解析为已经安装的版本，只写了部分版本号(1.22)时选择已经安装的最新匹配版本
+// 没有匹配的已安装版本时返回规范化后的 input，不访问网络
+func (l *Language) InstalledVersion(input string) string {
+	if normalize.Keywords[input] {
+		return input
+	}
+	version, err := normalize.Clean(l.Name, input)
+	if err != nil {
+		return input
+	}
+	if resolved, ok := normalize.Resolve(version, common.GetInstalled(l.Link().Downloads, l.Prefix)); ok {
+		return resolved
+	}
+	return version
+}
+
+// Resolved 项目中声明的语言版本，Version 为解析后的已安装版本
+type Resolved struct {
+	Language *Language
+	project.Pin
+	// Requested 文件中声明的版本，例如 1.22
+	Requested string
+}
+
+// Resolve 查找 dir 所在项目声明的语言版本，每个语言取离 dir 最近的声明，不支持的名称被忽略
+// 部分版本号解析为已经安装的最新匹配版本
+func Resolve(dir string) (items []Resolved) {
+	seen := map[string]bool{}
+	for _, pin := range project.Find(dir) {
+		language := Find(pin.Name)
+		if language == nil || seen[language.Name] {
+			continue
+		}
+		seen[language.Name] = true
+		requested := pin.Version
+		pin.Version = language.InstalledVersion(requested)
+		items = append(items, Resolved{language, pin, requested})
+	}
+	return items
+}
+
+// References 返回最近被 shell hook 看到的项目中声明了 version 的版本文件，卸载前据此提示
+func (l *Language) References(version string) (files []string) {
+	seen := map[string]bool{}
+	for _, dir := range project.Recent(config.ProjectsFile()) {
+		for _, item := range Resolve(dir) {
+			if item.Language.Name == l.Name && item.Version == version && !seen[item.File] {
+				seen[item.File] = true
+				files = append(files, item.File)
+			}
+		}
+	}
+	return files
+}
+
+// Remember 记录声明了 items 的项目目录，供卸载前检查
+func Remember(items []Resolved) {
+	dirs := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.File != "" {
+			dirs = append(dirs, filepath.Dir(item.File))
+		}
+	}
+	if len(dirs) > 0 {
+		_ = project.Remember(config.ProjectsFile(), dirs)
+	}
+}
+
+// Selection 语言在某个目录下选择的版本
+type Selection struct {
+	Language *Language
+	Version  string
+	// Source 版本的来源，环境变量名、项目版本文件或全局 symlink
+	Source string
+	// Scope 来源的类型，shell、project 或 global
+	Scope string
+}
+
+// 版本来源的类型
+const (
+	ScopeShell   = "shell"
+	ScopeProject = "project"
+	ScopeGlobal  = "global"
+)
+
+// VersionEnv 在当前 shell 中选择版本的环境变量 ENVM_<LANG>_VERSION，优先于项目版本文件
+func (l *Language) VersionEnv() string {
+	return "ENVM_" + strings.ToUpper(l.Name) + "_VERSION"
+}
+
+// Selected 返回 dir 下应该使用的版本及来源
+// 优先级为 shell(ENVM_<LANG>_VERSION) > 项目版本文件 > 全局 symlink，都没有时返回空
+func (l *Language) Selected(dir string) (version, source string) {
+	selection := l.selection(Resolve(dir))
+	return selection.Version, selection.Source
+}
+
+// selection 按优先级在已经解析的项目声明中选择版本，避免每个语言重复查找项目文件
+func (l *Language) selection(items []Resolved) Selection {
+	env := l.VersionEnv()
+	if version := os.Getenv(env); version != "" {
+		return Selection{l, l.InstalledVersion(version), env, ScopeShell}
+	}
+	for _, item := range items {
+		if item.Language == l {
+			return Selection{l, item.Version, item.File, ScopeProject}
+		}
+	}
+	if version := l.CurrentVersion(); version != "" {
+		return Selection{l, version, l.Link().Symlink, ScopeGlobal}
+	}
+	return Selection{Language: l}
+}
+
+// Selections 返回 dir 下每个语言选择的版本，没有选择版本的语言被忽略，只读取本地文件
+func Selections(dir string) (items []Selection) {
+	resolved := Resolve(dir)
+	for _, language := range languages {
+		if selection := language.selection(resolved); selection.Version != "" {
+			items = append(items, selection)
+		}
+	}
+	return items
+}
+
+// Environment 返回 dir 下所有已选择且已经安装的版本需要加入 PATH 的目录及其它环境变量
+func Environment(dir string) (paths []string, env map[string]string) {
+	env = map[string]string{}
+	for _, item := range Selections(dir) {
+		language := item.Language
+		if !common.IsInstalled(language.Link().Downloads, language.Prefix, item.Version) {
+			continue
+		}
+		paths = append(paths, language.BinDirs(item.Version)...)
+		for name, value := range language.Environ(item.Version) {
+			env[name] = value
+		}
+	}
+	return paths, env
+}
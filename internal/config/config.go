@@ -2,11 +2,16 @@ package config
 
 import (
 	"errors"
-	"github.com/FirewineXie/envm/internal/arch"
+	"fmt"
 	"github.com/FirewineXie/envm/util"
 
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type EnvmConfig struct {
@@ -17,7 +22,152 @@ type EnvmConfig struct {
 	Settings    Settings `json:"settings"`
 }
 
+// Settings <root>/config.toml 中的配置
 type Settings struct {
+	// Tools 自定义的工具，与内置工具一起出现在 envm tool 中
+	Tools []ToolSetting `json:"tools"`
+	Go    GoSettings    `json:"go"`
+	// Portable 不修改 symlink/PATH/注册表，激活的版本记录在 <root>/state.json，通过 envm env、envm exec 及 shims 使用
+	Portable bool `json:"portable"`
+	// ShimsDir shims 目录，默认为 <root>/shims
+	ShimsDir string `json:"shims_dir"`
+	// BinPerLanguage shims 按语言生成到 <root>/<language>/bin，每个语言单独加入 PATH
+	// 从 PATH 中移除一个目录即可停止 envm 管理该语言，其它语言不受影响
+	BinPerLanguage bool `json:"bin_per_language"`
+	// SharedRoot 多用户共享的安装目录，例如 /opt/envm、C:\ProgramData\envm，工具链安装到 <SharedRoot>/downloads
+	// 激活的版本、配置等仍然保存在各自的 ENVM_HOME 中，也可以通过 ENVM_SHARED_ROOT 设置
+	SharedRoot string `json:"shared_root"`
+	// InstallRoot 个人安装目录，工具链安装到 <InstallRoot>/downloads，默认为 ENVM_HOME，由 envm relocate 写入
+	// 配置了共享安装目录(SharedRoot)时不生效
+	InstallRoot string `json:"install_root"`
+	// ReadOnly 只读模式，只能查看及切换管理员预先安装的版本，拒绝安装及卸载，也可以通过 ENVM_READ_ONLY 设置
+	// 使用共享安装目录且当前用户没有写入权限时自动进入只读模式
+	ReadOnly bool `json:"read_only"`
+	// AutoInstall shell hook 及 envm use 遇到项目中声明但没有安装的版本时自动安装，而不是报错
+	AutoInstall bool `json:"auto_install"`
+	// KeepDownloads 安装后保留校验过的安装包，envm reinstall 不访问网络即可重新生成安装目录
+	KeepDownloads bool `json:"keep_downloads"`
+	// Dedup 安装新版本后将该语言各个版本中相同的文件硬链接为同一份，同 envm dedup
+	Dedup bool `json:"dedup"`
+	// Offline 不访问网络，远程版本列表只使用缓存
+	Offline bool `json:"offline"`
+	// Prefetch 命令结束后在后台刷新过期的远程版本列表缓存
+	Prefetch bool `json:"prefetch"`
+	// IndexTTL 远程版本列表缓存的有效期，例如 "12h"，默认为 24h
+	IndexTTL string `json:"index_ttl"`
+	// Notify 命令结束后根据缓存的远程版本列表提示已激活的语言有新版本，每个语言每天最多一次，envm notify off 关闭
+	Notify bool `json:"notify"`
+	// NotifySecurityOnly 只提示包含安全修复的新版本，安全修复的来源见 envm outdated --security
+	NotifySecurityOnly bool `json:"notify_security_only"`
+	// DisableStats 不记录本地的使用统计(envm stats)，统计只保存在 <root>/stats.json，从不上传
+	DisableStats bool `json:"disable_stats"`
+	// PostUse 每次切换版本成功后执行的命令，例如通知 IDE、重新生成 .vscode/settings.json
+	// 通过 ENVM_LANG、ENVM_OLD_VERSION、ENVM_NEW_VERSION 得到本次切换的语言及版本
+	PostUse string `json:"post_use"`
+	// Activation 激活版本的方式，"symlink"(默认) 将 symlink 指向版本目录；"copy" 将版本目录增量复制到 symlink 所在位置的普通目录
+	// 部分工具及杀毒软件无法正确处理链接的工具链时使用 copy，切换版本时只复制有变化的文件
+	Activation string `json:"activation"`
+	// ConfirmDefault 卸载、删除插件、覆盖文件前询问时的默认回答，"no"(默认) 或 "yes"
+	// 为 yes 时直接回车及非交互模式都会执行，--yes 总是不询问
+	ConfirmDefault string `json:"confirm_default"`
+	// PackageKinds 各系统安装包种类的优先顺序，键为 GOOS，例如 darwin = ["Installer", "Archive"]
+	// 没有配置的系统及列表中都没有的安装包使用压缩包(Archive)
+	PackageKinds map[string][]string `json:"package_kinds"`
+	// Mirrors 各语言的下载镜像，键为语言，例如 node = ["https://npmmirror.com/mirrors/node/", "https://nodejs.org/dist/"]
+	// 按顺序尝试，前一个无法访问时使用下一个，envm mirrors test --reorder 按测得的速度重新排序
+	Mirrors map[string][]string `json:"mirrors"`
+	// VersionEnv 使用某些版本时额外设置的环境变量，键为 <language> 或 <language>@<version>，version 可以是部分版本号(1.21 匹配 1.21.x)
+	// 例如 [version_env."go@1.21"] GODEBUG = "tlsrsakex=1"，多个键匹配同一个变量时版本号更具体的优先
+	// shell hook、envm exec、envm env、--session 及 shims 使用该版本时设置
+	VersionEnv map[string]map[string]string `json:"version_env"`
+	// ChecksumProviders 通过制品库接口(Artifactory、Nexus)而不是同目录的校验文件获取校验和的镜像，见 ChecksumProvider
+	ChecksumProviders []ChecksumProvider `json:"checksum_providers"`
+	// Network 允许访问的域名，配置后 envm 拒绝访问其它域名，见 NetworkSettings
+	Network NetworkSettings `json:"network"`
+	// Proxy envm 访问网络时使用的代理及认证，见 ProxySettings
+	Proxy ProxySettings `json:"proxy"`
+	// CABundle 访问 HTTPS 时信任的根证书，为空时使用系统的根证书，系统没有根证书时(scratch、distroless 等容器)使用内置的根证书
+	// "embedded" 总是使用内置的根证书，其它值为 PEM 格式的证书文件路径，只信任其中的证书；也可以通过 ENVM_CA_BUNDLE 设置
+	CABundle string `json:"ca_bundle"`
+}
+
+const (
+	// ChecksumTemplate 从 url 模板得到的地址获取校验和
+	ChecksumTemplate = "template"
+	// ChecksumArtifactory 通过 Artifactory 的 storage 接口获取校验和
+	ChecksumArtifactory = "artifactory"
+	// ChecksumNexus 通过 Nexus 3 的 search 接口获取校验和
+	ChecksumNexus = "nexus"
+)
+
+// ChecksumProvider [[checksum_providers]] 配置，下载地址以 Mirror 开头的安装包通过该来源获取校验和
+// 例如 mirror = "https://artifactory.corp.example/artifactory/node-dist/"、type = "artifactory"
+// 只在版本列表中没有校验和时使用，代替与安装包同目录的校验文件(SHASUMS256.txt 等)
+type ChecksumProvider struct {
+	// Mirror 下载地址前缀，通常与 mirrors 中的镜像相同，artifactory 及 nexus 以最后一段路径为仓库名
+	Mirror string `json:"mirror"`
+	// Type "template"(默认)、"artifactory" 或 "nexus"
+	Type string `json:"type"`
+	// URL template 时校验和的地址模板，可以使用 {url} {path} {file}，例如 "{url}.sha256"
+	URL string `json:"url"`
+	// Field template 返回 JSON 时校验和所在的字段，点分隔，例如 "checksums.sha256"；为空时按 sha256sum 格式解析
+	Field string `json:"field"`
+	// Algorithm template 返回的校验和算法，SHA256(默认)、SHA1 或 SHA512
+	Algorithm string `json:"algorithm"`
+	// TokenEnv 保存访问令牌的环境变量，设置后以 Authorization: Bearer 发送，令牌不写在 config.toml 中
+	TokenEnv string `json:"token_env"`
+}
+
+// ProxySettings [proxy] 配置，同时用于版本列表及安装包下载；没有配置 url 时使用 HTTPS_PROXY、HTTP_PROXY 及 NO_PROXY
+// 各项都可以通过 ENVM_PROXY、ENVM_PROXY_USERNAME、ENVM_PROXY_PASSWORD、ENVM_PROXY_AUTH 覆盖，密码建议只放在环境变量中
+type ProxySettings struct {
+	// URL 代理地址，例如 http://proxy.corp.example:8080
+	URL string `json:"url"`
+	// Username 代理的账号，ntlm 时可以写为 DOMAIN\user，ntlm 时账号及密码都为空表示使用当前登录 windows 的账号
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// Auth 认证方式，"basic"(默认) 或 "ntlm"，ntlm 只支持 windows，通过 SSPI 完成认证
+	Auth string `json:"auth"`
+}
+
+// NetworkSettings [network] 配置，例如 allow = ["golang.google.cn", "*.nodejs.org", "mirrors.corp.example"]
+// example.com 只匹配该域名，*.example.com 匹配所有子域名；deny 优先，allow 为空时只拒绝 deny 中的域名
+// 只检查 envm 自身发出的 HTTP 请求(包括重定向)及 envm plugin add 的 git 地址，不影响安装的工具链
+type NetworkSettings struct {
+	NetworkRule
+	// Commands 各命令追加的规则，键为空格分隔的命令，例如 [network.commands."go install"]，多个匹配时使用最长的一个
+	Commands map[string]NetworkRule `json:"commands"`
+}
+
+// NetworkRule 允许及拒绝的域名
+type NetworkRule struct {
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+}
+
+// GoSettings [go] 配置
+type GoSettings struct {
+	// PerVersionGopath 每个版本使用独立的 GOPATH/GOBIN，模块缓存互不影响
+	PerVersionGopath bool `json:"per_version_gopath"`
+	// GopathRoot 独立 GOPATH 的根目录，默认为 <root>/gopath
+	GopathRoot string `json:"gopath_root"`
+}
+
+// ToolSetting 通过 https 直接下载的单文件工具，例如公司内部的命令行工具
+// 模板中可以使用 {version} {os} {arch} {ext} {exe}
+type ToolSetting struct {
+	Name string `json:"name"`
+	// URL 下载地址模板
+	URL string `json:"url"`
+	// Checksum sha256sum 格式的校验文件地址模板，为空时只能通过 --insecure 安装
+	Checksum string `json:"checksum"`
+	// Binary 压缩包内可执行文件路径模板，为空表示下载的文件本身就是可执行文件
+	Binary string `json:"binary"`
+	// Versions 可安装的版本，仅用于 lsr 展示
+	Versions []string          `json:"versions"`
+	OS       map[string]string `json:"os"`
+	Arch     map[string]string `json:"arch"`
+	Ext      map[string]string `json:"ext"`
 }
 
 type SubConfig struct {
@@ -26,54 +176,58 @@ type SubConfig struct {
 }
 
 var root = filepath.Clean(os.Getenv("ENVM_HOME"))
-var goSymlink = filepath.Clean(os.Getenv("ENVM_GO_SYMLINK"))
-var javaSymlink = filepath.Clean(os.Getenv("ENVM_JAVA_SYMLINK"))
-var nodeSymlink = filepath.Clean(os.Getenv("ENVM_NODE_SYMLINK"))
+
+// settingsErr 读取 config.toml 的错误，在 VerifyEnv 时返回
+var settingsErr error
 
 var env = EnvmConfig{
 	Root:        root,
-	Arch:        arch.Validate(),
+	Arch:        runtime.GOARCH, // 不使用 arch.Validate()，启动时不执行 uname
 	LinkSetting: map[string]SubConfig{},
 }
 
 func init() {
-	env.Downloads = filepath.Join(root, "downloads")
-	exists, _ := util.PathExists(env.Downloads)
-	if !exists {
-		_ = os.Mkdir(env.Downloads, os.ModePerm)
-	}
+	env.Settings, settingsErr = loadSettings(SettingsFile())
+	env.Downloads = filepath.Join(InstallRoot(), "downloads")
+	mkdir(env.Downloads)
 
-	if goSymlink != "." {
-		env.LinkSetting[GO] = SubConfig{
-			goSymlink,
-			filepath.Join(env.Downloads, "go"),
-		}
-		pathExists, _ := util.PathExists(env.LinkSetting[GO].Downloads)
-		if !pathExists {
-			_ = os.Mkdir(env.LinkSetting[GO].Downloads, os.ModePerm)
-		}
+	for _, language := range Languages {
+		registerLink(language)
+	}
+	// 工具不需要单独配置 symlink, 可执行文件统一链接到 <root>/bin
+	env.LinkSetting[TOOL] = SubConfig{
+		filepath.Join(root, "bin"),
+		filepath.Join(env.Downloads, TOOL),
+	}
+}
 
+// registerLink 读取 ENVM_<LANG>_SYMLINK 并初始化该语言的下载目录
+// 未配置 symlink 时仍然注册下载目录，shell hook 等不依赖 symlink 的方式也可以使用
+func registerLink(language string) {
+	symlink := filepath.Clean(os.Getenv("ENVM_" + strings.ToUpper(language) + "_SYMLINK"))
+	if symlink == "." {
+		symlink = ""
 	}
+	// portable 模式下 symlink 只作为 state.json 中的键，不会真正创建
+	if symlink == "" && env.Settings.Portable {
+		symlink = filepath.Join(root, "current", language)
+	}
+	env.LinkSetting[language] = SubConfig{
+		symlink,
+		filepath.Join(env.Downloads, language),
+	}
+	mkdir(env.LinkSetting[language].Downloads)
+}
 
-	if javaSymlink != "." {
-		env.LinkSetting[JAVA] = SubConfig{
-			javaSymlink,
-			filepath.Join(env.Downloads, "java"),
-		}
-		pathExists, _ := util.PathExists(env.LinkSetting[JAVA].Downloads)
-		if !pathExists {
-			_ = os.Mkdir(env.LinkSetting[JAVA].Downloads, os.ModePerm)
-		}
+// mkdir 创建不存在的目录，未配置 ENVM_HOME 时不在当前目录下创建，由 VerifyEnv 提示
+func mkdir(dir string) {
+	if root == "." {
+		return
 	}
-	if javaSymlink != "." {
-		env.LinkSetting[NODE] = SubConfig{
-			nodeSymlink,
-			filepath.Join(env.Downloads, "node"),
-		}
-		pathExists, _ := util.PathExists(env.LinkSetting[NODE].Downloads)
-		if !pathExists {
-			_ = os.Mkdir(env.LinkSetting[NODE].Downloads, os.ModePerm)
-		}
+	exists, _ := util.PathExists(dir)
+	if !exists {
+		// 共享目录需要其它用户可以读取
+		_ = os.Mkdir(dir, 0755)
 	}
 }
 
@@ -81,45 +235,326 @@ const (
 	GO   = "go"
 	JAVA = "java"
 	NODE = "node"
+	DENO = "deno"
+	BUN  = "bun"
+	ZIG  = "zig"
+	// MAVEN maven 的命令名为 mvn, 对应 ENVM_MVN_SYMLINK
+	MAVEN   = "mvn"
+	GRADLE  = "gradle"
+	PHP     = "php"
+	FLUTTER = "flutter"
+	// TOOL github release 发布的单文件工具
+	TOOL = "tool"
+)
+
+const (
+	// ActivationSymlink 通过 symlink 激活版本
+	ActivationSymlink = "symlink"
+	// ActivationCopy 将版本目录复制到 symlink 所在位置激活版本
+	ActivationCopy = "copy"
 )
 
+// Languages 所有支持的语言
+var Languages = []string{GO, JAVA, NODE, DENO, BUN, ZIG, MAVEN, GRADLE, PHP, FLUTTER}
+
 func Default() EnvmConfig {
 	return env
 }
 
+// VerifyEnv 每个命令执行前的检查，只读取环境变量及配置文件，不能访问网络、执行外部命令或遍历目录
+// envm current、status 及补全等命令依赖启动速度
 func VerifyEnv() error {
 	if root == "." {
 		return errors.New("root 路径不能为空，请配置  ENVM_HOME 为当前执行程序路径")
 	}
-	if env.Arch == "" {
-		return errors.New("arch 暂时不支持")
+	if settingsErr != nil {
+		return settingsErr
+	}
+	if exists, _ := util.PathExists(SharedRoot()); !exists {
+		return fmt.Errorf("shared root %s does not exist", SharedRoot())
+	}
+	if exists, _ := util.PathExists(InstallRoot()); !exists {
+		return fmt.Errorf("install root %s does not exist, mount the drive or remove install_root from %s", InstallRoot(), SettingsFile())
 	}
 
 	return nil
 }
 
-func VerifyEnvGo() error {
-	symlink := env.LinkSetting[GO].Symlink
+// VerifyEnvLanguage 校验指定语言的 symlink 是否已经配置
+func VerifyEnvLanguage(language string) error {
+	symlink := env.LinkSetting[language].Symlink
 
 	if symlink == "" {
-		return errors.New("请先配置 ENVM_GO_SYMLINK")
+		return fmt.Errorf("请先配置 ENVM_%s_SYMLINK", strings.ToUpper(language))
 	}
 	return nil
 }
 
+func VerifyEnvGo() error {
+	return VerifyEnvLanguage(GO)
+}
+
 func VerifyEnvJava() error {
-	symlink := env.LinkSetting[JAVA].Symlink
+	return VerifyEnvLanguage(JAVA)
+}
 
-	if symlink == "" {
-		return errors.New("请先配置 ENVM_JAVA_SYMLINK")
+func VerifyEnvNode() error {
+	return VerifyEnvLanguage(NODE)
+}
+
+// SharedRoot 工具链的安装根目录，未配置共享目录时为 ENVM_HOME
+func SharedRoot() string {
+	if shared := os.Getenv("ENVM_SHARED_ROOT"); shared != "" {
+		return filepath.Clean(shared)
 	}
-	return nil
+	if env.Settings.SharedRoot != "" {
+		return filepath.Clean(env.Settings.SharedRoot)
+	}
+	return root
 }
-func VerifyEnvNode() error {
-	symlink := env.LinkSetting[NODE].Symlink
 
-	if symlink == "" {
-		return errors.New("请先配置 ENVM_NODE_SYMLINK")
+// InstallRoot 安装目录，工具链安装到 <InstallRoot>/downloads
+// 使用共享安装目录时为 SharedRoot，否则为 config.toml 中的 install_root，都没有时为 ENVM_HOME
+func InstallRoot() string {
+	if !IsShared() && env.Settings.InstallRoot != "" {
+		return filepath.Clean(env.Settings.InstallRoot)
+	}
+	return SharedRoot()
+}
+
+// IsShared 是否使用多用户共享的安装目录
+func IsShared() bool {
+	return SharedRoot() != root
+}
+
+// ReadOnlyReason 配置了只读模式时返回来源，ENVM_READ_ONLY 优先于 config.toml 中的 read_only，没有配置时返回空
+func ReadOnlyReason() string {
+	if value := os.Getenv("ENVM_READ_ONLY"); value != "" {
+		if readOnly, err := strconv.ParseBool(value); err == nil {
+			if readOnly {
+				return "ENVM_READ_ONLY is set"
+			}
+			return ""
+		}
+	}
+	if env.Settings.ReadOnly {
+		return "read_only is set in " + SettingsFile()
+	}
+	return ""
+}
+
+// GopathRoot 每个 go 版本独立 GOPATH 的根目录
+func GopathRoot() string {
+	if env.Settings.Go.GopathRoot != "" {
+		return env.Settings.Go.GopathRoot
+	}
+	return filepath.Join(root, "gopath")
+}
+
+// ArchivesDir keep_downloads 保留的安装包目录，<root>/archives/<language>
+func ArchivesDir() string {
+	return filepath.Join(root, "archives")
+}
+
+// GoToolsRoot envm go tools 为每个 go 版本安装的工具的根目录，<root>/gotools/<version>/bin
+func GoToolsRoot() string {
+	return filepath.Join(root, "gotools")
+}
+
+const (
+	// ProxyBasic 代理使用 Basic 认证
+	ProxyBasic = "basic"
+	// ProxyNTLM 代理使用 NTLM 认证
+	ProxyNTLM = "ntlm"
+)
+
+// Proxy 合并环境变量后的代理配置，见 ProxySettings
+func Proxy() ProxySettings {
+	proxy := env.Settings.Proxy
+	for name, value := range map[string]*string{
+		"ENVM_PROXY":          &proxy.URL,
+		"ENVM_PROXY_USERNAME": &proxy.Username,
+		"ENVM_PROXY_PASSWORD": &proxy.Password,
+		"ENVM_PROXY_AUTH":     &proxy.Auth,
+	} {
+		if v := os.Getenv(name); v != "" {
+			*value = v
+		}
+	}
+	return proxy
+}
+
+// CABundle 合并环境变量后的 ca_bundle 配置，见 Settings.CABundle
+func CABundle() string {
+	if v := os.Getenv("ENVM_CA_BUNDLE"); v != "" {
+		return v
+	}
+	return env.Settings.CABundle
+}
+
+// CopyActivation 是否通过复制激活版本，见 Settings.Activation
+func CopyActivation() bool {
+	return env.Settings.Activation == ActivationCopy
+}
+
+// ShimsDir shims 目录，需要加入 PATH
+func ShimsDir() string {
+	if env.Settings.ShimsDir != "" {
+		return env.Settings.ShimsDir
+	}
+	return filepath.Join(root, "shims")
+}
+
+// LanguageShimsDir 语言的 shims 目录，配置了 bin_per_language 时为 LanguageBinDir，否则为共用的 ShimsDir
+func LanguageShimsDir(language string) string {
+	if env.Settings.BinPerLanguage {
+		return LanguageBinDir(language)
+	}
+	return ShimsDir()
+}
+
+// LanguageBinDir bin_per_language 时语言的 shims 目录，<root>/<language>/bin
+func LanguageBinDir(language string) string {
+	return filepath.Join(root, language, "bin")
+}
+
+// IndexDir 远程版本列表的缓存目录
+func IndexDir() string {
+	return filepath.Join(root, "cache", "index")
+}
+
+// NotifyFile 记录每个语言上一次提示新版本的时间
+func NotifyFile() string {
+	return filepath.Join(root, "cache", "notify.json")
+}
+
+// SelfChecksumFile 缓存的 envm release 校验文件，同一个版本的内容不会变化
+func SelfChecksumFile(version string) string {
+	return filepath.Join(root, "cache", "self", version+"-checksums.txt")
+}
+
+// HistoryFile 安装、卸载、切换版本的审计日志
+func HistoryFile() string {
+	return filepath.Join(root, "history.jsonl")
+}
+
+// StatsFile envm stats 的本地使用统计
+func StatsFile() string {
+	return filepath.Join(root, "stats.json")
+}
+
+// ChecksumsFile 第一次安装每个安装包时记录的 sha256，之后从任何镜像安装同一个安装包时比较
+func ChecksumsFile() string {
+	return filepath.Join(root, "checksums.json")
+}
+
+// TrialsDir envm try 记录正在试用的版本，每个语言一个文件
+func TrialsDir() string {
+	return filepath.Join(root, "trials")
+}
+
+// DaemonSocket envm daemon 监听的本地 socket
+func DaemonSocket() string {
+	return filepath.Join(root, "daemon.sock")
+}
+
+// ProjectsFile shell hook 最近看到的项目目录，卸载版本前检查这些项目的版本声明
+func ProjectsFile() string {
+	return filepath.Join(root, "projects.json")
+}
+
+// VersionEnv 返回使用 language 的 version 时配置的额外环境变量，见 Settings.VersionEnv
+func VersionEnv(language, version string) map[string]string {
+	type match struct {
+		version string
+		env     map[string]string
+	}
+	matches := make([]match, 0)
+	for key, vars := range env.Settings.VersionEnv {
+		name, pattern, _ := strings.Cut(key, "@")
+		if name == language && versionMatch(pattern, version) {
+			matches = append(matches, match{pattern, vars})
+		}
+	}
+	// 版本号更长的更具体，后设置
+	sort.Slice(matches, func(i, j int) bool { return len(matches[i].version) < len(matches[j].version) })
+	merged := map[string]string{}
+	for _, m := range matches {
+		for name, value := range m.env {
+			merged[name] = value
+		}
+	}
+	return merged
+}
+
+// versionMatch pattern 为空、与 version 相同或为其部分版本号，1.2 不匹配 1.22.3
+func versionMatch(pattern, version string) bool {
+	if pattern == "" || pattern == version {
+		return true
+	}
+	if !strings.HasPrefix(version, pattern) {
+		return false
+	}
+	next := version[len(pattern)]
+	return next < '0' || next > '9'
+}
+
+// IndexTTL 远程版本列表缓存的有效期，配置错误时使用默认的 24h
+func IndexTTL() time.Duration {
+	if ttl, err := time.ParseDuration(env.Settings.IndexTTL); err == nil && ttl > 0 {
+		return ttl
+	}
+	return 24 * time.Hour
+}
+
+// PackageKinds goos 下依次尝试的安装包种类，总是以 Archive 结尾
+func PackageKinds(goos string) []string {
+	kinds := make([]string, 0, 2)
+	for _, kind := range env.Settings.PackageKinds[goos] {
+		if kind != util.ArchiveKind {
+			kinds = append(kinds, kind)
+		}
+	}
+	return append(kinds, util.ArchiveKind)
+}
+
+// defaultMirrors 没有配置 mirrors 时使用的官方下载地址
+var defaultMirrors = map[string][]string{
+	GO:   {"https://golang.google.cn"},
+	NODE: {"https://nodejs.org/dist/"},
+}
+
+// MirrorLanguages 支持配置下载镜像的语言
+func MirrorLanguages() []string {
+	return []string{GO, NODE}
+}
+
+// Mirrors language 的下载镜像，没有配置时只有官方下载地址
+func Mirrors(language string) []string {
+	if mirrors := env.Settings.Mirrors[language]; len(mirrors) > 0 {
+		return mirrors
+	}
+	return defaultMirrors[language]
+}
+
+// StateFile 记录各 symlink 激活的版本目录，portable 模式下代替 symlink，其它模式下用于修复被破坏的 symlink
+func StateFile() string {
+	return filepath.Join(root, "state.json")
+}
+
+// PluginRoot 插件目录
+func PluginRoot() string {
+	return filepath.Join(root, "plugins")
+}
+
+// PluginLink 插件语言的链接配置，未配置 ENVM_<NAME>_SYMLINK 时链接到 <root>/current/<name>
+func PluginLink(name string) SubConfig {
+	symlink := filepath.Clean(os.Getenv("ENVM_" + strings.ToUpper(name) + "_SYMLINK"))
+	if symlink == "." {
+		symlink = filepath.Join(root, "current", name)
+	}
+	return SubConfig{
+		symlink,
+		filepath.Join(env.Downloads, "plugin", name),
 	}
-	return nil
 }
@@ -0,0 +1,209 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/FirewineXie/envm/util"
+)
+
+// SettingsFile 配置文件路径
+func SettingsFile() string {
+	return filepath.Join(root, "config.toml")
+}
+
+// loadSettings 读取配置文件，文件不存在时返回空配置
+func loadSettings(filename string) (settings Settings, err error) {
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
+	if err != nil {
+		return settings, err
+	}
+	return parseSettings(data)
+}
+
+func parseSettings(data []byte) (settings Settings, err error) {
+	values, err := decodeTOML(data)
+	if err != nil {
+		return settings, err
+	}
+	// 借助 json tag 映射到结构体
+	raw, err := json.Marshal(values)
+	if err != nil {
+		return settings, err
+	}
+	if err = json.Unmarshal(raw, &settings); err != nil {
+		return settings, fmt.Errorf("config.toml: %w", err)
+	}
+	if settings.ConfirmDefault != "" && settings.ConfirmDefault != "yes" && settings.ConfirmDefault != "no" {
+		return settings, fmt.Errorf("config.toml: confirm_default must be yes or no, got %q", settings.ConfirmDefault)
+	}
+	if settings.Activation != "" && settings.Activation != ActivationSymlink && settings.Activation != ActivationCopy {
+		return settings, fmt.Errorf("config.toml: activation must be %s or %s, got %q", ActivationSymlink, ActivationCopy, settings.Activation)
+	}
+	for goos, kinds := range settings.PackageKinds {
+		for i, kind := range kinds {
+			// 与 go 下载页面的写法一致，不区分大小写
+			switch {
+			case strings.EqualFold(kind, util.ArchiveKind):
+				kinds[i] = util.ArchiveKind
+			case strings.EqualFold(kind, util.InstallerKind):
+				kinds[i] = util.InstallerKind
+			default:
+				return settings, fmt.Errorf("config.toml: package_kinds.%s: unsupported kind %q, use %s or %s", goos, kind, util.InstallerKind, util.ArchiveKind)
+			}
+		}
+	}
+	for language, mirrors := range settings.Mirrors {
+		if _, ok := defaultMirrors[language]; !ok {
+			return settings, fmt.Errorf("config.toml: mirrors.%s: mirrors are supported for %s only", language, strings.Join(MirrorLanguages(), ", "))
+		}
+		for _, mirror := range mirrors {
+			if !strings.HasPrefix(mirror, "https://") && !strings.HasPrefix(mirror, "http://") {
+				return settings, fmt.Errorf("config.toml: mirrors.%s: %q is not an http(s) url", language, mirror)
+			}
+		}
+	}
+	rules := map[string]NetworkRule{"network": settings.Network.NetworkRule}
+	for command, rule := range settings.Network.Commands {
+		rules[fmt.Sprintf("network.commands.%q", command)] = rule
+	}
+	for name, rule := range rules {
+		for _, domain := range append(append([]string{}, rule.Allow...), rule.Deny...) {
+			if !validDomain(domain) {
+				return settings, fmt.Errorf("config.toml: %s: %q is not a domain, use example.com or *.example.com", name, domain)
+			}
+		}
+	}
+	if auth := settings.Proxy.Auth; auth != "" && auth != ProxyBasic && auth != ProxyNTLM {
+		return settings, fmt.Errorf("config.toml: proxy.auth must be %s or %s, got %q", ProxyBasic, ProxyNTLM, auth)
+	}
+	if proxy := settings.Proxy.URL; proxy != "" && !strings.HasPrefix(proxy, "http://") && !strings.HasPrefix(proxy, "https://") {
+		return settings, fmt.Errorf("config.toml: proxy.url: %q is not an http(s) url", proxy)
+	}
+	for i, provider := range settings.ChecksumProviders {
+		if !strings.HasPrefix(provider.Mirror, "https://") && !strings.HasPrefix(provider.Mirror, "http://") {
+			return settings, fmt.Errorf("config.toml: checksum_providers[%d]: mirror %q is not an http(s) url", i, provider.Mirror)
+		}
+		switch provider.Type {
+		case "", ChecksumTemplate:
+			if provider.URL == "" {
+				return settings, fmt.Errorf("config.toml: checksum_providers[%d]: type %s requires url", i, ChecksumTemplate)
+			}
+		case ChecksumArtifactory, ChecksumNexus:
+		default:
+			return settings, fmt.Errorf("config.toml: checksum_providers[%d]: type must be %s, %s or %s, got %q", i, ChecksumTemplate, ChecksumArtifactory, ChecksumNexus, provider.Type)
+		}
+		switch strings.ToUpper(provider.Algorithm) {
+		case "", "SHA256", "SHA1", "SHA512":
+		default:
+			return settings, fmt.Errorf("config.toml: checksum_providers[%d]: algorithm must be SHA256, SHA1 or SHA512, got %q", i, provider.Algorithm)
+		}
+	}
+	for key, vars := range settings.VersionEnv {
+		if name, version, found := strings.Cut(key, "@"); name == "" || found && version == "" {
+			return settings, fmt.Errorf("config.toml: version_env.%q: use <language> or <language>@<version>", key)
+		}
+		for name := range vars {
+			if name == "" || strings.ContainsAny(name, "= \t") {
+				return settings, fmt.Errorf("config.toml: version_env.%q: %q is not an environment variable name", key, name)
+			}
+		}
+	}
+	for i, tool := range settings.Tools {
+		if tool.Name == "" || tool.URL == "" {
+			return settings, fmt.Errorf("config.toml: tools[%d] requires name and url", i)
+		}
+	}
+	return settings, nil
+}
+
+// validDomain 域名规则不能包含协议、端口及路径，通配符只能作为第一段
+func validDomain(domain string) bool {
+	domain = strings.TrimPrefix(domain, "*.")
+	return domain != "" && !strings.ContainsAny(domain, "*/:@ ")
+}
+
+// SetSetting 修改 config.toml 中顶层的 key = value，value 为 TOML 格式的值(true、"text")
+// 保留文件中的注释及其它配置，key 不存在时插入到文件开头，文件不存在时创建
+// key 为 table.name 时修改 [table] 表中的 name，见 setTableKey
+func SetSetting(key, value string) error {
+	filename := SettingsFile()
+	data, err := os.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	updated := setTopLevel(string(data), key, value)
+	if table, name, ok := strings.Cut(key, "."); ok {
+		updated = setTableKey(string(data), table, name, value)
+	}
+	if _, err = parseSettings([]byte(updated)); err != nil {
+		return err
+	}
+	tmp := filename + ".tmp"
+	if err = os.WriteFile(tmp, []byte(updated), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
+
+// setTopLevel 替换第一个表之前的 key = value，没有时插入到开头
+func setTopLevel(content, key, value string) string {
+	line := key + " = " + value
+	pattern := regexp.MustCompile(`^\s*` + regexp.QuoteMeta(key) + `\s*=`)
+	lines := strings.Split(content, "\n")
+	for i, l := range lines {
+		if strings.HasPrefix(strings.TrimSpace(l), "[") {
+			break
+		}
+		if pattern.MatchString(l) {
+			lines[i] = line
+			return strings.Join(lines, "\n")
+		}
+	}
+	if content == "" {
+		return line + "\n"
+	}
+	return line + "\n" + content
+}
+
+// setTableKey 替换 [table] 表中的 name = value 或顶层的 table.name = value
+// 都没有时追加到 [table] 表头之后，没有该表时作为点分键插入到开头
+func setTableKey(content, table, name, value string) string {
+	dotted := regexp.MustCompile(`^\s*` + regexp.QuoteMeta(table) + `\s*\.\s*` + regexp.QuoteMeta(name) + `\s*=`)
+	pattern := regexp.MustCompile(`^\s*` + regexp.QuoteMeta(name) + `\s*=`)
+	lines := strings.Split(content, "\n")
+	current, header := "", -1
+	for i, l := range lines {
+		trimmed := strings.TrimSpace(l)
+		if strings.HasPrefix(trimmed, "[") {
+			current = strings.TrimSpace(strings.Trim(trimmed, "[]"))
+			if current == table && header < 0 {
+				header = i
+			}
+			continue
+		}
+		if (current == "" && dotted.MatchString(l)) || (current == table && pattern.MatchString(l)) {
+			// 跨多行的数组一起替换
+			end := i
+			for depth := strings.Count(l, "[") - strings.Count(l, "]"); depth > 0 && end+1 < len(lines); {
+				end++
+				depth += strings.Count(lines[end], "[") - strings.Count(lines[end], "]")
+			}
+			lines[i] = regexp.MustCompile(`=.*$`).ReplaceAllLiteralString(l, "= "+value)
+			lines = append(lines[:i+1], lines[end+1:]...)
+			return strings.Join(lines, "\n")
+		}
+	}
+	if header >= 0 {
+		lines = append(lines[:header+1], append([]string{name + " = " + value}, lines[header+1:]...)...)
+		return strings.Join(lines, "\n")
+	}
+	return setTopLevel(content, table+"."+name, value)
+}
@@ -0,0 +1,232 @@
+package config
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseSettings(t *testing.T) {
+	Convey("解析 config.toml", t, func() {
+		settings, err := parseSettings([]byte(`
+# 公司内部工具
+[[tools]]
+name = "acme"
+url = "https://dl.example.com/acme/{version}/acme_{os}_{arch}{ext}"
+checksum = 'https://dl.example.com/acme/{version}/SHA256SUMS'
+binary = "acme{exe}"
+versions = [
+  "1.2.0",
+  "1.1.3", # 旧版本
+]
+ext = { windows = ".zip", "*" = ".tar.gz" }
+
+[[tools]]
+name = "deployer"
+url = "https://dl.example.com/deployer-{version}-{os}-{arch}{exe}"
+os.darwin = "macos"
+`))
+		So(err, ShouldBeNil)
+		So(len(settings.Tools), ShouldEqual, 2)
+		So(settings.Tools[0].Checksum, ShouldEqual, "https://dl.example.com/acme/{version}/SHA256SUMS")
+		So(settings.Tools[0].Versions, ShouldResemble, []string{"1.2.0", "1.1.3"})
+		So(settings.Tools[0].Ext["*"], ShouldEqual, ".tar.gz")
+		So(settings.Tools[1].OS, ShouldResemble, map[string]string{"darwin": "macos"})
+	})
+
+	Convey("多行字符串、转义及内联表", t, func() {
+		settings, err := parseSettings([]byte(`
+proxy = { url = """
+http://proxy.example.com\u003a8080""", auth = 'basic' }
+mirrors.go = [
+  "https://mirrors.example.com/go/",
+]
+`))
+		So(err, ShouldBeNil)
+		So(settings.Proxy.URL, ShouldEqual, "http://proxy.example.com:8080")
+		So(settings.Proxy.Auth, ShouldEqual, ProxyBasic)
+		So(settings.Mirrors["go"], ShouldResemble, []string{"https://mirrors.example.com/go/"})
+	})
+
+	Convey("安装包种类的优先顺序", t, func() {
+		settings, err := parseSettings([]byte("[package_kinds]\ndarwin = [\"installer\", \"Archive\"]\n"))
+		So(err, ShouldBeNil)
+		So(settings.PackageKinds["darwin"], ShouldResemble, []string{"Installer", "Archive"})
+	})
+
+	Convey("配置错误", t, func() {
+		_, err := parseSettings([]byte("[[tools]]\nurl = \"https://example.com\"\n"))
+		So(err, ShouldNotBeNil)
+
+		_, err = parseSettings([]byte("name = \"a\"\nname = \"b\"\n"))
+		So(err, ShouldNotBeNil)
+
+		_, err = parseSettings([]byte("name = \"a\" extra\n"))
+		So(err, ShouldNotBeNil)
+
+		_, err = parseSettings([]byte("confirm_default = \"always\"\n"))
+		So(err, ShouldNotBeNil)
+
+		_, err = parseSettings([]byte("activation = \"hardlink\"\n"))
+		So(err, ShouldNotBeNil)
+
+		_, err = parseSettings([]byte("[package_kinds]\nwindows = [\"msi\"]\n"))
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("下载镜像只支持部分语言及 http(s) 地址", t, func() {
+		settings, err := parseSettings([]byte("[mirrors]\nnode = [\"https://npmmirror.com/mirrors/node/\", \"https://nodejs.org/dist/\"]\n"))
+		So(err, ShouldBeNil)
+		So(settings.Mirrors["node"], ShouldResemble, []string{"https://npmmirror.com/mirrors/node/", "https://nodejs.org/dist/"})
+
+		_, err = parseSettings([]byte("[mirrors]\njava = [\"https://example.com\"]\n"))
+		So(err, ShouldNotBeNil)
+		_, err = parseSettings([]byte("[mirrors]\ngo = [\"ftp://example.com\"]\n"))
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("代理认证方式", t, func() {
+		settings, err := parseSettings([]byte("[proxy]\nurl = \"http://proxy.corp.example:8080\"\nusername = 'CORP\\alice'\nauth = \"ntlm\"\n"))
+		So(err, ShouldBeNil)
+		So(settings.Proxy, ShouldResemble, ProxySettings{URL: "http://proxy.corp.example:8080", Username: `CORP\alice`, Auth: ProxyNTLM})
+
+		_, err = parseSettings([]byte("[proxy]\nauth = \"kerberos\"\n"))
+		So(err, ShouldNotBeNil)
+		_, err = parseSettings([]byte("[proxy]\nurl = \"proxy.corp.example:8080\"\n"))
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestSetTopLevel(t *testing.T) {
+	Convey("修改顶层配置", t, func() {
+		So(setTopLevel("", "notify", "false"), ShouldEqual, "notify = false\n")
+
+		content := "# envm\noffline = true\n\n[go]\nnotify = 1\n"
+		So(setTopLevel(content, "notify", "false"), ShouldEqual, "notify = false\n"+content)
+
+		content = "notify = true # 提示新版本\n[go]\nper_version_gopath = true\n"
+		updated := setTopLevel(content, "notify", "false")
+		So(updated, ShouldEqual, "notify = false\n[go]\nper_version_gopath = true\n")
+		settings, err := parseSettings([]byte(updated))
+		So(err, ShouldBeNil)
+		So(settings.Go.PerVersionGopath, ShouldBeTrue)
+	})
+}
+
+func TestParseNetwork(t *testing.T) {
+	Convey("域名规则及各命令的规则", t, func() {
+		settings, err := parseSettings([]byte("[network]\nallow = [\"golang.google.cn\", \"*.nodejs.org\"]\n[network.commands.\"go install\"]\nallow = [\"dl.google.com\"]\n"))
+		So(err, ShouldBeNil)
+		So(settings.Network.Allow, ShouldResemble, []string{"golang.google.cn", "*.nodejs.org"})
+		So(settings.Network.Commands["go install"].Allow, ShouldResemble, []string{"dl.google.com"})
+
+		_, err = parseSettings([]byte("[network]\nallow = [\"https://nodejs.org/dist\"]\n"))
+		So(err, ShouldNotBeNil)
+		_, err = parseSettings([]byte("[network.commands.lsr]\ndeny = [\"api.*.com\"]\n"))
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestSetTableKey(t *testing.T) {
+	Convey("修改表中的配置", t, func() {
+		value := `["https://b", "https://a"]`
+		So(setTableKey("", "mirrors", "go", value), ShouldEqual, "mirrors.go = "+value+"\n")
+		So(setTableKey("mirrors.go = []\n", "mirrors", "go", value), ShouldEqual, "mirrors.go = "+value+"\n")
+
+		content := "offline = true\n[mirrors]\nnode = [\n  \"https://a\",\n  \"https://b\",\n]\ngo = []\n"
+		updated := setTableKey(content, "mirrors", "node", value)
+		So(updated, ShouldEqual, "offline = true\n[mirrors]\nnode = "+value+"\ngo = []\n")
+		settings, err := parseSettings([]byte(updated))
+		So(err, ShouldBeNil)
+		So(settings.Mirrors["node"], ShouldResemble, []string{"https://b", "https://a"})
+
+		So(setTableKey("[mirrors]\nnode = []\n", "mirrors", "go", value), ShouldEqual, "[mirrors]\ngo = "+value+"\nnode = []\n")
+	})
+}
+
+func TestReadOnlyReason(t *testing.T) {
+	Convey("ENVM_READ_ONLY 优先于 read_only", t, func() {
+		settings, err := parseSettings([]byte("read_only = true\n"))
+		So(err, ShouldBeNil)
+		So(settings.ReadOnly, ShouldBeTrue)
+
+		saved := env.Settings
+		defer func() { env.Settings = saved }()
+		env.Settings = settings
+		t.Setenv("ENVM_READ_ONLY", "")
+		So(ReadOnlyReason(), ShouldStartWith, "read_only is set")
+		t.Setenv("ENVM_READ_ONLY", "0")
+		So(ReadOnlyReason(), ShouldEqual, "")
+
+		env.Settings.ReadOnly = false
+		t.Setenv("ENVM_READ_ONLY", "true")
+		So(ReadOnlyReason(), ShouldEqual, "ENVM_READ_ONLY is set")
+		t.Setenv("ENVM_READ_ONLY", "yes please")
+		So(ReadOnlyReason(), ShouldEqual, "")
+	})
+}
+
+func TestParseChecksumProviders(t *testing.T) {
+	Convey("checksum_providers", t, func() {
+		settings, err := parseSettings([]byte(`
+[[checksum_providers]]
+mirror = "https://artifactory.corp.example/artifactory/node-dist/"
+type = "artifactory"
+token_env = "ARTIFACTORY_TOKEN"
+
+[[checksum_providers]]
+mirror = "https://files.corp.example/go/"
+url = "{url}.sha256"
+`))
+		So(err, ShouldBeNil)
+		So(len(settings.ChecksumProviders), ShouldEqual, 2)
+		So(settings.ChecksumProviders[0].TokenEnv, ShouldEqual, "ARTIFACTORY_TOKEN")
+		So(settings.ChecksumProviders[1].URL, ShouldEqual, "{url}.sha256")
+
+		for _, content := range []string{
+			"[[checksum_providers]]\nmirror = \"files.corp.example\"\nurl = \"{url}.sha256\"\n",
+			"[[checksum_providers]]\nmirror = \"https://files.corp.example/\"\n",
+			"[[checksum_providers]]\nmirror = \"https://files.corp.example/\"\ntype = \"svn\"\n",
+			"[[checksum_providers]]\nmirror = \"https://files.corp.example/\"\nurl = \"{url}.md5\"\nalgorithm = \"md5\"\n",
+		} {
+			_, err = parseSettings([]byte(content))
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
+
+func TestVersionEnv(t *testing.T) {
+	Convey("version_env", t, func() {
+		settings, err := parseSettings([]byte(`
+[version_env.go]
+GOFLAGS = "-mod=mod"
+GODEBUG = "default"
+
+[version_env."go@1.21"]
+GODEBUG = "tlsrsakex=1"
+
+[version_env."go@1.21.5"]
+GODEBUG = "tlsrsakex=1,x509sha1=1"
+
+[version_env."java@21"]
+JAVA_TOOL_OPTIONS = "-Xmx2g"
+`))
+		So(err, ShouldBeNil)
+		saved := env.Settings
+		defer func() { env.Settings = saved }()
+		env.Settings = settings
+
+		So(VersionEnv("go", "1.22.3"), ShouldResemble, map[string]string{"GOFLAGS": "-mod=mod", "GODEBUG": "default"})
+		So(VersionEnv("go", "1.21.0"), ShouldResemble, map[string]string{"GOFLAGS": "-mod=mod", "GODEBUG": "tlsrsakex=1"})
+		So(VersionEnv("go", "1.21.5"), ShouldResemble, map[string]string{"GOFLAGS": "-mod=mod", "GODEBUG": "tlsrsakex=1,x509sha1=1"})
+		So(VersionEnv("go", "1.2"), ShouldResemble, map[string]string{"GOFLAGS": "-mod=mod", "GODEBUG": "default"})
+		So(VersionEnv("java", "21.0.2"), ShouldResemble, map[string]string{"JAVA_TOOL_OPTIONS": "-Xmx2g"})
+		So(VersionEnv("java", "210.0.0"), ShouldBeEmpty)
+		So(VersionEnv("node", "20.1.0"), ShouldBeEmpty)
+
+		_, err = parseSettings([]byte("[version_env.\"go@\"]\nGODEBUG = \"x\"\n"))
+		So(err, ShouldNotBeNil)
+		_, err = parseSettings([]byte("[version_env.\"@1.21\"]\nGODEBUG = \"x\"\n"))
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,17 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/toml"
+)
+
+// decodeTOML 解析 config.toml 为通用的 map，再借助 json tag 映射到 Settings，见 parseSettings
+// 重复的键、语法错误等返回带行号的错误
+func decodeTOML(data []byte) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	if err := toml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("config.toml: %w", err)
+	}
+	return values, nil
+}
@@ -0,0 +1,88 @@
+// Package advisory 根据发布说明判断哪些版本包含安全修复，用于 envm outdated --security 及新版本提示
+package advisory
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/blang/semver/v4"
+)
+
+// 安全修复版本的来源
+const (
+	// GoReleaseURL go 的发布历史，每个补丁版本一段说明，包含安全修复时写明 includes security fixes
+	GoReleaseURL = "https://go.dev/doc/devel/release"
+	// NodeIndexURL node 的版本列表，security 字段标记了安全修复版本
+	NodeIndexURL = "https://nodejs.org/dist/index.json"
+)
+
+// goSecurity 匹配 go1.22.5 (released 2024-07-02) includes security fixes 及 includes a security fix
+var goSecurity = regexp.MustCompile(`go(\d+\.\d+(?:\.\d+)?)\s+\(released\s+[^)]*\)\s+includes\s+(?:a\s+)?security\s+fix`)
+
+// ParseGoReleases 从 go 的发布历史页面中找出包含安全修复的版本
+func ParseGoReleases(page []byte) (versions []string) {
+	for _, m := range goSecurity.FindAllSubmatch(page, -1) {
+		versions = append(versions, string(m[1]))
+	}
+	return versions
+}
+
+// ParseNodeIndex 从 node 的 index.json 中找出包含安全修复的版本
+func ParseNodeIndex(data []byte) ([]string, error) {
+	var releases []struct {
+		Version  string `json:"version"`
+		Security bool   `json:"security"`
+	}
+	if err := json.Unmarshal(data, &releases); err != nil {
+		return nil, err
+	}
+	versions := make([]string, 0)
+	for _, release := range releases {
+		if release.Security {
+			versions = append(versions, strings.TrimPrefix(release.Version, "v"))
+		}
+	}
+	return versions, nil
+}
+
+func parse(name string) (semver.Version, bool) {
+	v, err := semver.ParseTolerant(strings.TrimPrefix(name, "go"))
+	return v, err == nil && len(v.Pre) == 0
+}
+
+// Successors 返回与 current 同一个 major.minor 下更新的正式版本中最新的一个，以及其中包含安全修复的版本
+// 升级到 latest 即可获得 fixes 中的所有修复
+func Successors(current string, versions, security []string) (latest string, fixes []string) {
+	cur, ok := parse(current)
+	if !ok {
+		return "", nil
+	}
+	flagged := make(map[string]bool, len(security))
+	for _, name := range security {
+		if v, ok := parse(name); ok {
+			flagged[v.String()] = true
+		}
+	}
+	var found semver.Version
+	successors := make([]semver.Version, 0)
+	names := map[string]string{}
+	for _, name := range versions {
+		v, ok := parse(name)
+		if !ok || !v.GT(cur) || v.Major != cur.Major || v.Minor != cur.Minor {
+			continue
+		}
+		successors = append(successors, v)
+		names[v.String()] = name
+		if latest == "" || v.GT(found) {
+			latest, found = name, v
+		}
+	}
+	semver.Sort(successors)
+	for _, v := range successors {
+		if flagged[v.String()] {
+			fixes = append(fixes, names[v.String()])
+		}
+	}
+	return latest, fixes
+}
@@ -0,0 +1,47 @@
+package advisory
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParse(t *testing.T) {
+	Convey("解析 go 的发布历史", t, func() {
+		page := []byte(`<p>
+go1.22.5 (released 2024-07-02) includes security fixes to the <code>net/http</code> package,
+as well as bug fixes to the compiler.
+</p>
+<p>
+go1.22.4 (released 2024-06-04) includes security fixes to the <code>archive/zip</code> and <code>net/netip</code> packages.
+</p>
+<p>go1.22.3 (released 2024-05-07) includes a security fix to the <code>net/http</code> package.</p>
+<p>go1.22.2 (released 2024-04-03) includes bug fixes to the compiler.</p>`)
+		So(ParseGoReleases(page), ShouldResemble, []string{"1.22.5", "1.22.4", "1.22.3"})
+	})
+
+	Convey("解析 node 的 index.json", t, func() {
+		versions, err := ParseNodeIndex([]byte(`[{"version":"v20.12.2","security":false},{"version":"v20.12.1","security":true}]`))
+		So(err, ShouldBeNil)
+		So(versions, ShouldResemble, []string{"20.12.1"})
+	})
+}
+
+func TestSuccessors(t *testing.T) {
+	Convey("查找包含安全修复的后续版本", t, func() {
+		versions := []string{"1.23.0", "1.22.6", "1.22.5", "1.22.4", "1.22.3", "1.22rc1"}
+		security := []string{"1.22.5", "1.22.4", "1.22.2", "1.23.0"}
+
+		latest, fixes := Successors("1.22.3", versions, security)
+		So(latest, ShouldEqual, "1.22.6")
+		So(fixes, ShouldResemble, []string{"1.22.4", "1.22.5"})
+
+		latest, fixes = Successors("1.22.6", versions, security)
+		So(latest, ShouldBeEmpty)
+		So(fixes, ShouldBeEmpty)
+
+		latest, fixes = Successors("1.22.5", versions, nil)
+		So(latest, ShouldEqual, "1.22.6")
+		So(fixes, ShouldBeEmpty)
+	})
+}
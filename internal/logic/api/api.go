@@ -0,0 +1,229 @@
+// Package api envm serve 的本地 HTTP JSON 接口，供编辑器插件及图形界面调用，不需要解析命令行的输出
+//
+//	GET  /v1/languages                      支持的语言及当前版本
+//	GET  /v1/languages/{name}/installed     已安装的版本
+//	GET  /v1/languages/{name}/remote        可以安装的版本，?refresh=true 时不使用缓存
+//	POST /v1/languages/{name}/install       {"version": "1.22"}，以 server-sent events 返回安装进度，最后一个事件为 done
+//	POST /v1/languages/{name}/use           {"version": "1.22.3"}，切换全局版本
+//
+// 设置了 token 时所有请求需要 Authorization: Bearer <token>，失败时返回 {"error": "..."}
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Version 接口的版本，即路径的前缀
+const Version = "v1"
+
+// EventDone 安装结束的事件，data 中 error 为空表示成功
+const EventDone = "done"
+
+// ErrUnknownLanguage 不支持的语言，返回 404
+var ErrUnknownLanguage = errors.New("unknown language")
+
+// Language 一个语言及其当前版本
+type Language struct {
+	Name    string `json:"name"`
+	Current string `json:"current"`
+}
+
+// Installed 已安装的版本
+type Installed struct {
+	Language string   `json:"language"`
+	Current  string   `json:"current"`
+	Versions []string `json:"versions"`
+}
+
+// Remote 可以安装的版本，顺序同 lsr
+type Remote struct {
+	Language string   `json:"language"`
+	Versions []string `json:"versions"`
+}
+
+// Switched 切换后的版本
+type Switched struct {
+	Language string `json:"language"`
+	Version  string `json:"version"`
+}
+
+// Backend 接口背后的实现，name 为不支持的语言时返回 ErrUnknownLanguage
+type Backend interface {
+	Languages() []Language
+	Installed(name string) (*Installed, error)
+	Remote(name string, refresh bool) (*Remote, error)
+	// Install 安装 version，每个进度事件调用一次 emit，data 为 JSON；ctx 在客户端断开时取消
+	// 返回的错误在没有输出过 done 事件时作为 done 事件输出
+	Install(ctx context.Context, name, version string, emit func(event string, data []byte)) error
+	Use(name, version string) (*Switched, error)
+}
+
+// versionBody install 及 use 的请求体
+type versionBody struct {
+	Version string `json:"version"`
+}
+
+// Handler 返回接口的 http.Handler，token 为空时不检查 Authorization
+func Handler(b Backend, token string) http.Handler {
+	return &handler{backend: b, token: token}
+}
+
+type handler struct {
+	backend Backend
+	token   string
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.token != "" && !h.authorized(r) {
+		writeError(w, http.StatusUnauthorized, errors.New("missing or wrong token, use Authorization: Bearer <token>"))
+		return
+	}
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] != Version || parts[1] != "languages" {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown path %s", r.URL.Path))
+		return
+	}
+	switch {
+	case len(parts) == 2:
+		if allow(w, r, http.MethodGet) {
+			writeJSON(w, http.StatusOK, h.backend.Languages())
+		}
+	case len(parts) == 4 && parts[3] == "installed":
+		if allow(w, r, http.MethodGet) {
+			installed, err := h.backend.Installed(parts[2])
+			respond(w, installed, err)
+		}
+	case len(parts) == 4 && parts[3] == "remote":
+		if allow(w, r, http.MethodGet) {
+			remote, err := h.backend.Remote(parts[2], r.URL.Query().Get("refresh") == "true")
+			respond(w, remote, err)
+		}
+	case len(parts) == 4 && parts[3] == "use":
+		if version, ok := readVersion(w, r); ok {
+			switched, err := h.backend.Use(parts[2], version)
+			respond(w, switched, err)
+		}
+	case len(parts) == 4 && parts[3] == "install":
+		if version, ok := readVersion(w, r); ok {
+			h.install(w, r, parts[2], version)
+		}
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown path %s", r.URL.Path))
+	}
+}
+
+// authorized 比较 token 的时间与内容无关
+func (h *handler) authorized(r *http.Request) bool {
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(got), []byte(h.token)) == 1
+}
+
+// install 以 server-sent events 输出安装进度，安装开始后错误只能通过 done 事件返回
+func (h *handler) install(w http.ResponseWriter, r *http.Request, name, version string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming is not supported"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	done := false
+	emit := func(event string, data []byte) {
+		if event == EventDone {
+			done = true
+		}
+		WriteEvent(w, event, data)
+		flusher.Flush()
+	}
+	err := h.backend.Install(r.Context(), name, version, emit)
+	if !done {
+		data, _ := json.Marshal(map[string]string{"event": EventDone, "language": name, "version": version, "error": errorText(err)})
+		emit(EventDone, data)
+	}
+}
+
+// WriteEvent 输出一个 server-sent event，data 中的换行拆分为多个 data 行
+func WriteEvent(w io.Writer, event string, data []byte) {
+	var b strings.Builder
+	if event != "" {
+		b.WriteString("event: " + event + "\n")
+	}
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+	_, _ = w.Write([]byte(b.String()))
+}
+
+// allow 检查请求方法，不允许时返回 405
+func allow(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method {
+		return true
+	}
+	w.Header().Set("Allow", method)
+	writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("use %s", method))
+	return false
+}
+
+// readVersion 读取 POST 请求体中的版本
+func readVersion(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if !allow(w, r, http.MethodPost) {
+		return "", false
+	}
+	var body versionBody
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid body, use {\"version\": \"<version>\"}: %w", err))
+		return "", false
+	}
+	if body.Version == "" {
+		writeError(w, http.StatusBadRequest, errors.New("version is missing"))
+		return "", false
+	}
+	// 版本作为命令行参数传给 Backend，不能被当作 flag
+	if strings.HasPrefix(body.Version, "-") {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid version %q", body.Version))
+		return "", false
+	}
+	return body.Version, true
+}
+
+// respond 输出 v，err 为 ErrUnknownLanguage 时返回 404，其它错误返回 500
+func respond(w http.ResponseWriter, v any, err error) {
+	switch {
+	case errors.Is(err, ErrUnknownLanguage):
+		writeError(w, http.StatusNotFound, err)
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err)
+	default:
+		writeJSON(w, http.StatusOK, v)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type fakeBackend struct {
+	current string
+	used    string
+}
+
+func (f *fakeBackend) Languages() []Language {
+	return []Language{{Name: "go", Current: f.current}}
+}
+
+func (f *fakeBackend) Installed(name string) (*Installed, error) {
+	if name != "go" {
+		return nil, fmt.Errorf("%w %s", ErrUnknownLanguage, name)
+	}
+	return &Installed{Language: name, Current: f.current, Versions: []string{"1.21.0", f.current}}, nil
+}
+
+func (f *fakeBackend) Remote(name string, refresh bool) (*Remote, error) {
+	if refresh {
+		return nil, errors.New("offline")
+	}
+	return &Remote{Language: name, Versions: []string{"1.23.0"}}, nil
+}
+
+func (f *fakeBackend) Install(ctx context.Context, name, version string, emit func(string, []byte)) error {
+	if version == "bad" {
+		return errors.New("no such version")
+	}
+	emit("download-progress", []byte(`{"event":"download-progress","bytes":5,"total":10}`))
+	emit(EventDone, []byte(`{"event":"done","version":"`+version+`"}`))
+	return nil
+}
+
+func (f *fakeBackend) Use(name, version string) (*Switched, error) {
+	f.used = version
+	return &Switched{Language: name, Version: version}, nil
+}
+
+func do(server *httptest.Server, method, path, token, body string) (int, string) {
+	req, _ := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err.Error()
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(data)
+}
+
+func TestHandler(t *testing.T) {
+	Convey("本地 HTTP 接口", t, func() {
+		backend := &fakeBackend{current: "1.22.3"}
+		server := httptest.NewServer(Handler(backend, "secret"))
+		defer server.Close()
+
+		Convey("没有 token 时拒绝", func() {
+			status, _ := do(server, http.MethodGet, "/v1/languages", "", "")
+			So(status, ShouldEqual, http.StatusUnauthorized)
+			status, _ = do(server, http.MethodGet, "/v1/languages", "wrong", "")
+			So(status, ShouldEqual, http.StatusUnauthorized)
+		})
+
+		Convey("列出语言及已安装的版本", func() {
+			status, body := do(server, http.MethodGet, "/v1/languages", "secret", "")
+			So(status, ShouldEqual, http.StatusOK)
+			var items []Language
+			So(json.Unmarshal([]byte(body), &items), ShouldBeNil)
+			So(items, ShouldResemble, []Language{{Name: "go", Current: "1.22.3"}})
+
+			status, body = do(server, http.MethodGet, "/v1/languages/go/installed", "secret", "")
+			So(status, ShouldEqual, http.StatusOK)
+			So(body, ShouldContainSubstring, `"versions":["1.21.0","1.22.3"]`)
+
+			status, body = do(server, http.MethodGet, "/v1/languages/ruby/installed", "secret", "")
+			So(status, ShouldEqual, http.StatusNotFound)
+			So(body, ShouldContainSubstring, "unknown language ruby")
+		})
+
+		Convey("远程版本，错误返回 500", func() {
+			status, body := do(server, http.MethodGet, "/v1/languages/go/remote", "secret", "")
+			So(status, ShouldEqual, http.StatusOK)
+			So(body, ShouldContainSubstring, `"versions":["1.23.0"]`)
+			status, body = do(server, http.MethodGet, "/v1/languages/go/remote?refresh=true", "secret", "")
+			So(status, ShouldEqual, http.StatusInternalServerError)
+			So(body, ShouldContainSubstring, "offline")
+		})
+
+		Convey("切换版本", func() {
+			status, _ := do(server, http.MethodGet, "/v1/languages/go/use", "secret", "")
+			So(status, ShouldEqual, http.StatusMethodNotAllowed)
+			status, _ = do(server, http.MethodPost, "/v1/languages/go/use", "secret", `{}`)
+			So(status, ShouldEqual, http.StatusBadRequest)
+			status, _ = do(server, http.MethodPost, "/v1/languages/go/use", "secret", `{"version":"--help"}`)
+			So(status, ShouldEqual, http.StatusBadRequest)
+			status, body := do(server, http.MethodPost, "/v1/languages/go/use", "secret", `{"version":"1.21.0"}`)
+			So(status, ShouldEqual, http.StatusOK)
+			So(body, ShouldContainSubstring, `"version":"1.21.0"`)
+			So(backend.used, ShouldEqual, "1.21.0")
+		})
+
+		Convey("安装以 server-sent events 返回进度", func() {
+			status, body := do(server, http.MethodPost, "/v1/languages/go/install", "secret", `{"version":"1.23.0"}`)
+			So(status, ShouldEqual, http.StatusOK)
+			So(body, ShouldEqual, "event: download-progress\ndata: {\"event\":\"download-progress\",\"bytes\":5,\"total\":10}\n\n"+
+				"event: done\ndata: {\"event\":\"done\",\"version\":\"1.23.0\"}\n\n")
+		})
+
+		Convey("安装失败且没有 done 事件时补充 done", func() {
+			_, body := do(server, http.MethodPost, "/v1/languages/go/install", "secret", `{"version":"bad"}`)
+			So(body, ShouldStartWith, "event: done\n")
+			So(body, ShouldContainSubstring, `"error":"no such version"`)
+		})
+
+		Convey("未知路径", func() {
+			status, _ := do(server, http.MethodGet, "/v2/languages", "secret", "")
+			So(status, ShouldEqual, http.StatusNotFound)
+			status, _ = do(server, http.MethodGet, "/v1/languages/go/unknown", "secret", "")
+			So(status, ShouldEqual, http.StatusNotFound)
+		})
+	})
+}
+
+func TestWriteEvent(t *testing.T) {
+	Convey("多行 data 拆分", t, func() {
+		var b strings.Builder
+		WriteEvent(&b, "", []byte("a\nb\n"))
+		So(b.String(), ShouldEqual, "data: a\ndata: b\n\n")
+	})
+}
@@ -0,0 +1,74 @@
+// Package cabundle envm 访问 HTTPS 时信任的根证书，见 ca_bundle 配置
+// scratch、distroless 等没有安装 ca-certificates 的容器中没有系统根证书，所有 HTTPS 请求都会失败
+package cabundle
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// Embedded 使用内置根证书的 ca_bundle 配置
+const Embedded = "embedded"
+
+//go:embed roots.pem
+var roots []byte
+
+var (
+	once     sync.Once
+	embedded *x509.CertPool
+	// fallback x509.SetFallbackRoots 只能调用一次
+	fallback sync.Once
+)
+
+// Roots 内置的根证书，见 roots.pem
+func Roots() *x509.CertPool {
+	once.Do(func() {
+		embedded = x509.NewCertPool()
+		embedded.AppendCertsFromPEM(roots)
+	})
+	return embedded
+}
+
+// Load 返回 bundle 对应的根证书，bundle 为 embedded 时使用内置的根证书，否则为 PEM 格式的证书文件路径
+func Load(bundle string) (*x509.CertPool, error) {
+	if bundle == Embedded {
+		return Roots(), nil
+	}
+	data, err := os.ReadFile(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("read the ca bundle error + %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("ca bundle %s contains no PEM certificate", bundle)
+	}
+	return pool, nil
+}
+
+// Install 设置 http.DefaultTransport 信任的根证书，需要在 netpolicy.Install 之前调用
+// bundle 为空时使用系统的根证书，系统没有根证书时使用内置的根证书；否则只信任 bundle 中的根证书
+func Install(bundle string) error {
+	if bundle == "" {
+		fallback.Do(func() { x509.SetFallbackRoots(Roots()) })
+		return nil
+	}
+	pool, err := Load(bundle)
+	if err != nil {
+		return err
+	}
+	t, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return errors.New("ca bundle must be applied before the network policy")
+	}
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+	t.TLSClientConfig.RootCAs = pool
+	return nil
+}
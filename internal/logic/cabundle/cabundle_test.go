@@ -0,0 +1,80 @@
+package cabundle
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// selfSigned 生成一个自签名的根证书
+func selfSigned() (*x509.Certificate, []byte) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "envm test root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, _ := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	cert, _ := x509.ParseCertificate(der)
+	return cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestRoots(t *testing.T) {
+	Convey("内置的根证书都是有效的根证书", t, func() {
+		count := 0
+		for rest := roots; ; {
+			var block *pem.Block
+			if block, rest = pem.Decode(rest); block == nil {
+				break
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			So(err, ShouldBeNil)
+			So(cert.IsCA, ShouldBeTrue)
+			So(cert.NotAfter.After(time.Now()), ShouldBeTrue)
+			count++
+		}
+		So(count, ShouldBeGreaterThan, 10)
+
+		pool, err := Load(Embedded)
+		So(err, ShouldBeNil)
+		So(pool, ShouldEqual, Roots())
+	})
+}
+
+func TestLoad(t *testing.T) {
+	Convey("从文件读取根证书", t, func() {
+		dir := t.TempDir()
+		cert, data := selfSigned()
+		filename := filepath.Join(dir, "ca.pem")
+		So(os.WriteFile(filename, data, 0o644), ShouldBeNil)
+
+		pool, err := Load(filename)
+		So(err, ShouldBeNil)
+		_, err = cert.Verify(x509.VerifyOptions{Roots: pool})
+		So(err, ShouldBeNil)
+		_, err = cert.Verify(x509.VerifyOptions{Roots: Roots()})
+		So(err, ShouldNotBeNil)
+
+		Convey("文件不存在或没有证书", func() {
+			_, err = Load(filepath.Join(dir, "missing.pem"))
+			So(err, ShouldNotBeNil)
+			So(os.WriteFile(filename, []byte("not a certificate"), 0o644), ShouldBeNil)
+			_, err = Load(filename)
+			So(err.Error(), ShouldContainSubstring, "contains no PEM certificate")
+		})
+	})
+}
@@ -0,0 +1,60 @@
+// Package changelog 各语言版本的发布说明地址，由版本号按各项目发布说明的固定路径得到，不访问网络
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/config"
+)
+
+// prerelease 版本号中预发布部分的开始，例如 1.23rc1、21-ea
+var prerelease = regexp.MustCompile(`[-+]|rc|beta|alpha`)
+
+// parts 按 . 拆分去掉预发布部分的版本号，不足三段时补 0
+func parts(version string) []string {
+	if loc := prerelease.FindStringIndex(version); loc != nil {
+		version = version[:loc[0]]
+	}
+	fields := strings.Split(version, ".")
+	for len(fields) < 3 {
+		fields = append(fields, "0")
+	}
+	return fields
+}
+
+// URL 返回 language 的 version 的发布说明地址，不支持的语言返回 false
+func URL(language, version string) (string, bool) {
+	version = strings.TrimPrefix(strings.TrimPrefix(version, "go"), "v")
+	p := parts(version)
+	switch language {
+	case config.GO:
+		// 1.x.0 及预发布版本的说明为单独的页面，补丁版本都在 release history 中
+		if p[2] == "0" || prerelease.MatchString(version) {
+			return fmt.Sprintf("https://go.dev/doc/go%s.%s", p[0], p[1]), true
+		}
+		return "https://go.dev/doc/devel/release#go" + version, true
+	case config.JAVA:
+		return fmt.Sprintf("https://www.oracle.com/java/technologies/javase/%sall-relnotes.html", p[0]), true
+	case config.NODE:
+		return "https://github.com/nodejs/node/releases/tag/v" + version, true
+	case config.DENO:
+		return "https://github.com/denoland/deno/releases/tag/v" + version, true
+	case config.BUN:
+		return "https://github.com/oven-sh/bun/releases/tag/bun-v" + version, true
+	case config.ZIG:
+		// 只有 0.x.0 有发布说明
+		return fmt.Sprintf("https://ziglang.org/download/%s.%s.0/release-notes.html", p[0], p[1]), true
+	case config.MAVEN:
+		return fmt.Sprintf("https://maven.apache.org/docs/%s/release-notes.html", version), true
+	case config.GRADLE:
+		return fmt.Sprintf("https://docs.gradle.org/%s/release-notes.html", version), true
+	case config.PHP:
+		return fmt.Sprintf("https://www.php.net/ChangeLog-%s.php#%s", p[0], version), true
+	case config.FLUTTER:
+		// 补丁版本的修复记录在 x.y.0 的发布说明中
+		return fmt.Sprintf("https://docs.flutter.dev/release/release-notes/release-notes-%s.%s.0", p[0], p[1]), true
+	}
+	return "", false
+}
@@ -0,0 +1,37 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/FirewineXie/envm/internal/config"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestURL(t *testing.T) {
+	Convey("go 的大版本及补丁版本", t, func() {
+		url, ok := URL(config.GO, "1.22.3")
+		So(ok, ShouldBeTrue)
+		So(url, ShouldEqual, "https://go.dev/doc/devel/release#go1.22.3")
+		url, _ = URL(config.GO, "1.22.0")
+		So(url, ShouldEqual, "https://go.dev/doc/go1.22")
+		url, _ = URL(config.GO, "1.20")
+		So(url, ShouldEqual, "https://go.dev/doc/go1.20")
+		url, _ = URL(config.GO, "1.23rc1")
+		So(url, ShouldEqual, "https://go.dev/doc/go1.23")
+	})
+
+	Convey("其它语言", t, func() {
+		url, _ := URL(config.NODE, "20.12.2")
+		So(url, ShouldEqual, "https://github.com/nodejs/node/releases/tag/v20.12.2")
+		url, _ = URL(config.JAVA, "17.0.2")
+		So(url, ShouldEqual, "https://www.oracle.com/java/technologies/javase/17all-relnotes.html")
+		url, _ = URL(config.ZIG, "0.12.1")
+		So(url, ShouldEqual, "https://ziglang.org/download/0.12.0/release-notes.html")
+		url, _ = URL(config.PHP, "8.3.6")
+		So(url, ShouldEqual, "https://www.php.net/ChangeLog-8.php#8.3.6")
+		url, _ = URL(config.GRADLE, "8.7")
+		So(url, ShouldEqual, "https://docs.gradle.org/8.7/release-notes.html")
+		_, ok := URL("ruby", "3.3.0")
+		So(ok, ShouldBeFalse)
+	})
+}
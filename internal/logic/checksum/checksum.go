@@ -0,0 +1,221 @@
+// Package checksum 从内部制品库(Artifactory、Nexus 等)的接口获取安装包的校验和
+// 这些仓库镜像工具链时通常不保留同目录的校验文件，而是在接口中提供每个文件的校验和
+package checksum
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/logic/web-github"
+	"github.com/FirewineXie/envm/util"
+)
+
+// Provider 校验和来源
+type Provider interface {
+	// Lookup 返回下载地址 rawURL 对应文件的校验和，键为大写的算法名称(SHA256、SHA1、SHA512)
+	Lookup(rawURL string) (map[string]string, error)
+}
+
+// Spec 一个校验和来源的配置，字段含义见 config.ChecksumProvider
+type Spec struct {
+	Mirror    string
+	Type      string
+	URL       string
+	Field     string
+	Algorithm string
+	// Token 访问令牌，不为空时以 Authorization: Bearer 发送
+	Token string
+}
+
+// Match 返回下载地址以 Mirror 开头的第一个来源，没有时返回空
+func Match(specs []Spec, rawURL string) *Spec {
+	for i := range specs {
+		if strings.HasPrefix(rawURL, withSlash(specs[i].Mirror)) {
+			return &specs[i]
+		}
+	}
+	return nil
+}
+
+// New 按 Type 创建来源
+func New(spec Spec) (Provider, error) {
+	switch spec.Type {
+	case "", "template":
+		return &template{spec}, nil
+	case "artifactory":
+		return &artifactory{spec}, nil
+	case "nexus":
+		return &nexus{spec}, nil
+	}
+	return nil, fmt.Errorf("unknown checksum provider type %q", spec.Type)
+}
+
+// Pick 优先选择 algorithm 的校验和，没有时依次选择 SHA256、SHA512、SHA1，不使用 MD5
+func Pick(sums map[string]string, algorithm string) (alg, value string, ok bool) {
+	for _, alg = range []string{strings.ToUpper(algorithm), "SHA256", "SHA512", "SHA1"} {
+		if value = sums[alg]; value != "" {
+			return alg, strings.ToLower(value), true
+		}
+	}
+	return "", "", false
+}
+
+// withSlash 镜像地址以 / 结尾，避免 https://repo/go 匹配 https://repo/golang/...
+func withSlash(mirror string) string {
+	return strings.TrimSuffix(mirror, "/") + "/"
+}
+
+// relative 返回下载地址在镜像中的路径，不以 / 开头
+func (s Spec) relative(rawURL string) string {
+	return strings.TrimPrefix(rawURL, withSlash(s.Mirror))
+}
+
+// repository 将镜像地址拆分为仓库所在的地址及仓库名，例如 https://host/artifactory/go-remote/ 为 https://host/artifactory 及 go-remote
+func (s Spec) repository() (base, repo string) {
+	mirror := strings.TrimSuffix(s.Mirror, "/")
+	i := strings.LastIndex(mirror, "/")
+	return mirror[:i], mirror[i+1:]
+}
+
+// template 从模板地址获取校验和，内容为 sha256sum 格式或 JSON
+type template struct{ Spec }
+
+func (t *template) Lookup(rawURL string) (map[string]string, error) {
+	relative := t.relative(rawURL)
+	address := strings.NewReplacer("{url}", rawURL, "{path}", relative, "{file}", path.Base(relative)).Replace(t.URL)
+	algorithm := strings.ToUpper(t.Algorithm)
+	if algorithm == "" {
+		algorithm = "SHA256"
+	}
+	if t.Field == "" {
+		body, err := t.get(address)
+		if err != nil {
+			return nil, err
+		}
+		value, err := web_github.ParseChecksum(bufio.NewScanner(bytes.NewReader(body)), path.Base(relative))
+		if err != nil {
+			return nil, fmt.Errorf("no checksum of %s in %s", path.Base(relative), address)
+		}
+		return map[string]string{algorithm: value}, nil
+	}
+	var doc any
+	if err := t.getJSON(address, &doc); err != nil {
+		return nil, err
+	}
+	value, ok := Field(doc, t.Field).(string)
+	if !ok || value == "" {
+		return nil, fmt.Errorf("no %s in the response of %s", t.Field, address)
+	}
+	return map[string]string{algorithm: value}, nil
+}
+
+// artifactory GET <base>/api/storage/<repo>/<path>，返回 {"checksums": {"sha1": ..., "sha256": ...}}
+type artifactory struct{ Spec }
+
+func (a *artifactory) Lookup(rawURL string) (map[string]string, error) {
+	base, repo := a.repository()
+	address := base + "/api/storage/" + repo + "/" + a.relative(rawURL)
+	var info struct {
+		Checksums map[string]string `json:"checksums"`
+	}
+	if err := a.getJSON(address, &info); err != nil {
+		return nil, err
+	}
+	return upper(info.Checksums), nil
+}
+
+// nexus GET <host>/service/rest/v1/search/assets?repository=<repo>&name=<path>，镜像地址为 <host>/repository/<repo>/
+type nexus struct{ Spec }
+
+func (n *nexus) Lookup(rawURL string) (map[string]string, error) {
+	base, repo := n.repository()
+	base = strings.TrimSuffix(base, "/repository")
+	relative := n.relative(rawURL)
+	address := base + "/service/rest/v1/search/assets?" + url.Values{"repository": {repo}, "name": {relative}}.Encode()
+	var result struct {
+		Items []struct {
+			Path     string            `json:"path"`
+			Checksum map[string]string `json:"checksum"`
+		} `json:"items"`
+	}
+	if err := n.getJSON(address, &result); err != nil {
+		return nil, err
+	}
+	for _, item := range result.Items {
+		if strings.TrimPrefix(item.Path, "/") == relative {
+			return upper(item.Checksum), nil
+		}
+	}
+	return nil, fmt.Errorf("%s is not found in the nexus repository %s", relative, repo)
+}
+
+// upper 将接口返回的 sha256 等算法名称转换为 util.Package.Algorithm 的写法
+func upper(sums map[string]string) map[string]string {
+	result := make(map[string]string, len(sums))
+	for alg, value := range sums {
+		result[strings.ToUpper(alg)] = value
+	}
+	return result
+}
+
+// Field 按点分隔的路径读取 JSON 中的字段，数字表示数组下标，例如 items.0.checksum.sha256
+func Field(doc any, field string) any {
+	for _, key := range strings.Split(field, ".") {
+		switch v := doc.(type) {
+		case map[string]any:
+			doc = v[key]
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil
+			}
+			doc = v[i]
+		default:
+			return nil
+		}
+	}
+	return doc
+}
+
+func (s Spec) getJSON(address string, v any) error {
+	body, err := s.get(address)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("parse the response of %s error + %w", address, err)
+	}
+	return nil
+}
+
+// get 读取接口返回的内容，通过 http.DefaultClient 发送，代理及 [network] 规则同样生效
+func (s Spec) get(address string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, address, nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, util.NewDownloadError(address, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, util.NewDownloadError(address, fmt.Errorf("unexpected status %s", resp.Status))
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, util.NewDownloadError(address, err)
+	}
+	return body, nil
+}
@@ -0,0 +1,83 @@
+package checksum
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestProviders(t *testing.T) {
+	var requested, auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested, auth = r.URL.RequestURI(), r.Header.Get("Authorization")
+		switch r.URL.Path {
+		case "/artifactory/api/storage/node-dist/v20.11.1/node-v20.11.1-linux-x64.tar.xz":
+			_, _ = w.Write([]byte(`{"checksums": {"sha1": "AA", "md5": "bb", "sha256": "CC"}}`))
+		case "/service/rest/v1/search/assets":
+			_, _ = w.Write([]byte(`{"items": [{"path": "v20.11.1/other.tar.xz", "checksum": {"sha256": "00"}},
+				{"path": "v20.11.1/node-v20.11.1-linux-x64.tar.xz", "checksum": {"sha1": "11", "sha512": "22"}}]}`))
+		case "/meta/v20.11.1/node-v20.11.1-linux-x64.tar.xz":
+			_, _ = w.Write([]byte(`{"files": [{"hash": "DD"}]}`))
+		case "/raw/v20.11.1/node-v20.11.1-linux-x64.tar.xz.sha256":
+			_, _ = w.Write([]byte("ee  node-v20.11.1-linux-x64.tar.xz\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	const file = "v20.11.1/node-v20.11.1-linux-x64.tar.xz"
+
+	Convey("artifactory 的 storage 接口", t, func() {
+		p, err := New(Spec{Mirror: server.URL + "/artifactory/node-dist", Type: "artifactory", Token: "secret"})
+		So(err, ShouldBeNil)
+		sums, err := p.Lookup(server.URL + "/artifactory/node-dist/" + file)
+		So(err, ShouldBeNil)
+		So(auth, ShouldEqual, "Bearer secret")
+		alg, value, ok := Pick(sums, "")
+		So(ok, ShouldBeTrue)
+		So(alg, ShouldEqual, "SHA256")
+		So(value, ShouldEqual, "cc")
+		alg, value, _ = Pick(sums, "sha1")
+		So(alg, ShouldEqual, "SHA1")
+		So(value, ShouldEqual, "aa")
+	})
+
+	Convey("nexus 的 search 接口", t, func() {
+		p, _ := New(Spec{Mirror: server.URL + "/repository/node-dist/", Type: "nexus"})
+		sums, err := p.Lookup(server.URL + "/repository/node-dist/" + file)
+		So(err, ShouldBeNil)
+		So(requested, ShouldEqual, "/service/rest/v1/search/assets?name=v20.11.1%2Fnode-v20.11.1-linux-x64.tar.xz&repository=node-dist")
+		alg, value, _ := Pick(sums, "SHA256")
+		So(alg, ShouldEqual, "SHA512")
+		So(value, ShouldEqual, "22")
+
+		_, err = p.Lookup(server.URL + "/repository/node-dist/v20.11.1/missing.tar.xz")
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("模板地址", t, func() {
+		p, _ := New(Spec{Mirror: server.URL + "/raw/", URL: "{url}.sha256"})
+		sums, err := p.Lookup(server.URL + "/raw/" + file)
+		So(err, ShouldBeNil)
+		So(sums, ShouldResemble, map[string]string{"SHA256": "ee"})
+
+		p, _ = New(Spec{Mirror: server.URL + "/raw/", URL: server.URL + "/meta/{path}", Field: "files.0.hash", Algorithm: "sha512"})
+		sums, err = p.Lookup(server.URL + "/raw/" + file)
+		So(err, ShouldBeNil)
+		So(sums, ShouldResemble, map[string]string{"SHA512": "DD"})
+
+		p, _ = New(Spec{Mirror: server.URL + "/raw/", URL: server.URL + "/meta/{path}", Field: "files.1.hash"})
+		_, err = p.Lookup(server.URL + "/raw/" + file)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("按镜像地址前缀匹配", t, func() {
+		specs := []Spec{{Mirror: "https://repo.example/go"}, {Mirror: "https://repo.example/golang/"}}
+		So(Match(specs, "https://repo.example/golang/go1.22.3.tar.gz").Mirror, ShouldEqual, "https://repo.example/golang/")
+		So(Match(specs, "https://other.example/go/go1.22.3.tar.gz"), ShouldBeNil)
+		_, err := New(Spec{Type: "svn"})
+		So(err, ShouldNotBeNil)
+	})
+}
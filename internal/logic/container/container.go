@@ -0,0 +1,113 @@
+// Package container 生成在镜像中安装项目声明版本的 Dockerfile 及 devcontainer 配置
+// 镜像中同样由 envm 根据相同的版本声明安装，本地与容器中的版本保持一致
+package container
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/logic/shell"
+)
+
+const (
+	// Module go install 安装 envm 的模块路径
+	Module = "github.com/FirewineXie/envm"
+	// Home 镜像中的 ENVM_HOME，语言的 symlink 为 <Home>/<name>
+	Home = "/opt/envm"
+	// Builder 编译 envm 的镜像，与 go.mod 中的 go 版本一致
+	Builder = "golang:1.21"
+	// DefaultBase dockerfile 默认的基础镜像
+	DefaultBase = "debian:bookworm-slim"
+	// DevcontainerBase devcontainer 默认的基础镜像
+	DevcontainerBase = "mcr.microsoft.com/devcontainers/base:bookworm"
+)
+
+// Toolchain 镜像中安装的一个语言版本
+type Toolchain struct {
+	Name    string
+	Version string
+	// Bin linux 下可执行文件在安装目录中的相对路径，为空表示在安装目录下
+	Bin string
+	// HomeEnv 指向安装目录的环境变量，可以为空
+	HomeEnv string
+}
+
+// Options 生成 Dockerfile 的参数
+type Options struct {
+	// Base 基础镜像，需要是 debian 系，为空时使用 DefaultBase
+	Base string
+	// EnvmVersion go install 时 envm 的版本，为空时使用 latest
+	EnvmVersion string
+	// Sources 声明版本的文件，写入注释
+	Sources    []string
+	Toolchains []Toolchain
+}
+
+// symlink 语言在镜像中的 symlink
+func symlink(name string) string {
+	return path.Join(Home, name)
+}
+
+// continued 将多行指令用 \ 连接，后续行缩进
+func continued(lines []string) string {
+	return strings.Join(lines, " \\\n    ")
+}
+
+// Dockerfile 返回多阶段的 Dockerfile，第一阶段编译 envm，第二阶段在 Base 中通过 envm sync 安装并激活所有版本
+func Dockerfile(opts Options) string {
+	base, version := opts.Base, opts.EnvmVersion
+	if base == "" {
+		base = DefaultBase
+	}
+	if version == "" {
+		version = "latest"
+	}
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "# generated by envm generate from %s\n", strings.Join(opts.Sources, ", "))
+	buf.WriteString("# regenerate it after changing the pinned versions so the image and the local machine stay identical\n")
+	fmt.Fprintf(&buf, "FROM %s AS envm\n", Builder)
+	fmt.Fprintf(&buf, "RUN GOBIN=/out go install %s@%s\n\n", Module, version)
+
+	fmt.Fprintf(&buf, "FROM %s\n", base)
+	buf.WriteString("RUN apt-get update && apt-get install -y --no-install-recommends ca-certificates xz-utils \\\n" +
+		"    && rm -rf /var/lib/apt/lists/*\n")
+	buf.WriteString("COPY --from=envm /out/envm /usr/local/bin/envm\n")
+
+	env := []string{"ENVM_HOME=" + Home}
+	pins := make([]string, 0, len(opts.Toolchains))
+	for _, toolchain := range opts.Toolchains {
+		env = append(env, fmt.Sprintf("ENVM_%s_SYMLINK=%s", strings.ToUpper(toolchain.Name), symlink(toolchain.Name)))
+		pins = append(pins, shell.Quote(shell.Bash, toolchain.Name+" "+toolchain.Version))
+	}
+	buf.WriteString("ENV " + continued(env) + "\n")
+	fmt.Fprintf(&buf, "RUN mkdir -p %s && cd %s \\\n", Home, Home)
+	fmt.Fprintf(&buf, "    && printf '%%s\\n' %s > .tool-versions \\\n", strings.Join(pins, " "))
+	buf.WriteString("    && envm --yes sync\n")
+
+	env = env[:0]
+	paths := make([]string, 0, len(opts.Toolchains))
+	for _, toolchain := range opts.Toolchains {
+		if toolchain.HomeEnv != "" {
+			env = append(env, toolchain.HomeEnv+"="+symlink(toolchain.Name))
+		}
+		paths = append(paths, path.Join(symlink(toolchain.Name), toolchain.Bin))
+	}
+	env = append(env, "PATH="+strings.Join(paths, ":")+":$PATH")
+	buf.WriteString("ENV " + continued(env) + "\n")
+	return buf.String()
+}
+
+// Devcontainer 返回使用同一目录下 Dockerfile 构建的 devcontainer.json
+func Devcontainer(name string) ([]byte, error) {
+	config := map[string]interface{}{
+		"name":  name,
+		"build": map[string]string{"dockerfile": "Dockerfile"},
+	}
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
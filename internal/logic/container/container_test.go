@@ -0,0 +1,52 @@
+package container
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDockerfile(t *testing.T) {
+	Convey("生成安装项目版本的 Dockerfile", t, func() {
+		dockerfile := Dockerfile(Options{
+			EnvmVersion: "v1.0.2",
+			Sources:     []string{"/app/.envmrc"},
+			Toolchains: []Toolchain{
+				{Name: "go", Version: "1.22.3", Bin: "bin", HomeEnv: "GOROOT"},
+				{Name: "zig", Version: "0.12.0"},
+			},
+		})
+		So(dockerfile, ShouldEqual, `# generated by envm generate from /app/.envmrc
+# regenerate it after changing the pinned versions so the image and the local machine stay identical
+FROM golang:1.21 AS envm
+RUN GOBIN=/out go install github.com/FirewineXie/envm@v1.0.2
+
+FROM debian:bookworm-slim
+RUN apt-get update && apt-get install -y --no-install-recommends ca-certificates xz-utils \
+    && rm -rf /var/lib/apt/lists/*
+COPY --from=envm /out/envm /usr/local/bin/envm
+ENV ENVM_HOME=/opt/envm \
+    ENVM_GO_SYMLINK=/opt/envm/go \
+    ENVM_ZIG_SYMLINK=/opt/envm/zig
+RUN mkdir -p /opt/envm && cd /opt/envm \
+    && printf '%s\n' 'go 1.22.3' 'zig 0.12.0' > .tool-versions \
+    && envm --yes sync
+ENV GOROOT=/opt/envm/go \
+    PATH=/opt/envm/go/bin:/opt/envm/zig:$PATH
+`)
+
+		So(Dockerfile(Options{Base: "ubuntu:24.04"}), ShouldContainSubstring, "go install github.com/FirewineXie/envm@latest\n\nFROM ubuntu:24.04\n")
+	})
+}
+
+func TestDevcontainer(t *testing.T) {
+	Convey("生成 devcontainer.json", t, func() {
+		data, err := Devcontainer("app")
+		So(err, ShouldBeNil)
+		var config map[string]interface{}
+		So(json.Unmarshal(data, &config), ShouldBeNil)
+		So(config["name"], ShouldEqual, "app")
+		So(config["build"], ShouldResemble, map[string]interface{}{"dockerfile": "Dockerfile"})
+	})
+}
@@ -0,0 +1,251 @@
+// Package daemon envm daemon 的缓存及进程间通信
+// 守护进程缓存每个目录解析出的项目版本，轮询版本文件、各级目录及安装目录的修改时间，变化时在后台重新解析
+// shell hook 通过本地 socket 查询，不需要在每次切换目录时逐级读取版本文件
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// OpResolve 查询目录中声明的版本
+	OpResolve = "resolve"
+	// OpStatus 查询守护进程的状态
+	OpStatus = "status"
+	// OpStop 停止守护进程
+	OpStop = "stop"
+)
+
+// Request 一行 JSON 的请求
+type Request struct {
+	Op  string `json:"op"`
+	Dir string `json:"dir,omitempty"`
+}
+
+// Response 一行 JSON 的回应
+type Response struct {
+	Entries []Entry `json:"entries,omitempty"`
+	Status  *Status `json:"status,omitempty"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// Entry 目录中声明的一个语言版本
+type Entry struct {
+	Language string `json:"language"`
+	// Version 解析后的版本，Requested 为文件中声明的版本
+	Version   string `json:"version"`
+	Requested string `json:"requested"`
+	File      string `json:"file"`
+}
+
+// Status 守护进程的状态
+type Status struct {
+	PID     int       `json:"pid"`
+	Started time.Time `json:"started"`
+	Dirs    int       `json:"dirs"`
+	Hits    int       `json:"hits"`
+	Misses  int       `json:"misses"`
+}
+
+// Snapshot 被监视路径的修改时间，不存在的路径为零值
+type Snapshot map[string]time.Time
+
+// Take 记录 paths 当前的修改时间
+func Take(paths []string) Snapshot {
+	s := make(Snapshot, len(paths))
+	for _, path := range paths {
+		s[path] = modTime(path)
+	}
+	return s
+}
+
+// Changed 是否有路径被修改、创建或删除，目录的修改时间在其中创建、删除或重命名文件时变化
+func (s Snapshot) Changed() bool {
+	for path, t := range s {
+		if !modTime(path).Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// Ancestors 返回 dir 及其所有上级目录，项目版本文件可能出现在其中任意一级
+func Ancestors(dir string) []string {
+	dirs := make([]string, 0)
+	dir = filepath.Clean(dir)
+	for {
+		dirs = append(dirs, dir)
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dirs
+		}
+		dir = parent
+	}
+}
+
+// ResolveFunc 解析 dir 中声明的版本，同时返回结果依赖的路径(版本文件、安装目录等)，dir 的各级目录由 Cache 监视
+type ResolveFunc func(dir string) (entries []Entry, watched []string)
+
+type entry struct {
+	entries  []Entry
+	snapshot Snapshot
+	used     time.Time
+}
+
+// Cache 目录到解析结果的缓存，最多保留 limit 个目录，超过时淘汰最久没有查询的目录
+type Cache struct {
+	mu      sync.Mutex
+	resolve ResolveFunc
+	limit   int
+	dirs    map[string]*entry
+	hits    int
+	misses  int
+}
+
+// NewCache 创建缓存
+func NewCache(resolve ResolveFunc, limit int) *Cache {
+	return &Cache{resolve: resolve, limit: limit, dirs: make(map[string]*entry)}
+}
+
+// Get 返回 dir 的解析结果，没有缓存时解析并开始监视
+func (c *Cache) Get(dir string) []Entry {
+	dir = filepath.Clean(dir)
+	c.mu.Lock()
+	if e, ok := c.dirs[dir]; ok {
+		e.used = time.Now()
+		c.hits++
+		c.mu.Unlock()
+		return e.entries
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	e := c.load(dir)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dirs[dir] = e
+	c.evict()
+	return e.entries
+}
+
+// Refresh 重新解析监视的路径发生变化的目录，返回这些目录
+func (c *Cache) Refresh() []string {
+	c.mu.Lock()
+	changed := make([]string, 0)
+	for dir, e := range c.dirs {
+		if e.snapshot.Changed() {
+			changed = append(changed, dir)
+		}
+	}
+	c.mu.Unlock()
+	for _, dir := range changed {
+		e := c.load(dir)
+		c.mu.Lock()
+		if old, ok := c.dirs[dir]; ok {
+			e.used = old.used
+			c.dirs[dir] = e
+		}
+		c.mu.Unlock()
+	}
+	return changed
+}
+
+// Stats 返回缓存的目录数及命中次数
+func (c *Cache) Stats() (dirs, hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.dirs), c.hits, c.misses
+}
+
+// load 先记录修改时间再解析，解析期间发生的修改在下一次 Refresh 时发现
+func (c *Cache) load(dir string) *entry {
+	// 解析前先记录各级目录，解析得到的其它路径在解析后记录
+	snapshot := Take(Ancestors(dir))
+	entries, watched := c.resolve(dir)
+	for path, t := range Take(watched) {
+		snapshot[path] = t
+	}
+	return &entry{entries: entries, snapshot: snapshot, used: time.Now()}
+}
+
+func (c *Cache) evict() {
+	for len(c.dirs) > c.limit {
+		var oldest string
+		for dir, e := range c.dirs {
+			if oldest == "" || e.used.Before(c.dirs[oldest].used) {
+				oldest = dir
+			}
+		}
+		delete(c.dirs, oldest)
+	}
+}
+
+// Serve 接受连接并由 handle 处理每一行请求，listener 关闭后返回
+func Serve(l net.Listener, handle func(Request) Response) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			scanner := bufio.NewScanner(conn)
+			encoder := json.NewEncoder(conn)
+			for scanner.Scan() {
+				var req Request
+				resp := Response{}
+				if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+					resp.Error = err.Error()
+				} else {
+					resp = handle(req)
+				}
+				if encoder.Encode(resp) != nil {
+					return
+				}
+			}
+		}()
+	}
+}
+
+// Query 连接 socket 发送一个请求，timeout 内没有完成时返回错误，hook 据此退回直接读取版本文件
+func Query(socket string, req Request, timeout time.Duration) (Response, error) {
+	var resp Response
+	conn, err := net.DialTimeout("unix", socket, timeout)
+	if err != nil {
+		return resp, err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+	if err = json.NewEncoder(conn).Encode(req); err != nil {
+		return resp, err
+	}
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		return resp, err
+	}
+	if err = json.Unmarshal(line, &resp); err != nil {
+		return resp, err
+	}
+	if resp.Error != "" {
+		return resp, errors.New(resp.Error)
+	}
+	return resp, nil
+}
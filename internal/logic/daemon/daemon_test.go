@@ -0,0 +1,99 @@
+package daemon
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCache(t *testing.T) {
+	Convey("版本文件变化后重新解析", t, func() {
+		root := t.TempDir()
+		project := filepath.Join(root, "project")
+		sub := filepath.Join(project, "sub")
+		So(os.MkdirAll(sub, 0755), ShouldBeNil)
+		file := filepath.Join(project, ".go-version")
+		calls := 0
+		resolve := func(dir string) ([]Entry, []string) {
+			calls++
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, nil
+			}
+			return []Entry{{Language: "go", Version: string(data), File: file}}, []string{file}
+		}
+		cache := NewCache(resolve, 8)
+		So(cache.Get(sub), ShouldBeEmpty)
+		So(cache.Get(sub), ShouldBeEmpty)
+		So(calls, ShouldEqual, 1)
+
+		// 在上级目录中创建版本文件
+		So(os.WriteFile(file, []byte("1.21"), 0644), ShouldBeNil)
+		touch(project)
+		So(cache.Refresh(), ShouldResemble, []string{sub})
+		So(cache.Get(sub)[0].Version, ShouldEqual, "1.21")
+
+		// 直接修改版本文件的内容
+		So(os.WriteFile(file, []byte("1.22"), 0644), ShouldBeNil)
+		touch(file)
+		So(cache.Refresh(), ShouldResemble, []string{sub})
+		So(cache.Get(sub)[0].Version, ShouldEqual, "1.22")
+		So(cache.Refresh(), ShouldBeEmpty)
+
+		dirs, hits, misses := cache.Stats()
+		So(dirs, ShouldEqual, 1)
+		So(hits, ShouldEqual, 3)
+		So(misses, ShouldEqual, 1)
+	})
+
+	Convey("超过上限时淘汰最久没有查询的目录", t, func() {
+		cache := NewCache(func(dir string) ([]Entry, []string) { return nil, nil }, 2)
+		root := t.TempDir()
+		for _, name := range []string{"a", "b", "a", "c"} {
+			cache.Get(filepath.Join(root, name))
+			time.Sleep(time.Millisecond)
+		}
+		dirs, _, _ := cache.Stats()
+		So(dirs, ShouldEqual, 2)
+		_, ok := cache.dirs[filepath.Join(root, "b")]
+		So(ok, ShouldBeFalse)
+	})
+}
+
+// touch 修改时间精度较低的文件系统上保证修改时间变化
+func touch(path string) {
+	later := time.Now().Add(time.Second)
+	_ = os.Chtimes(path, later, later)
+}
+
+func TestQuery(t *testing.T) {
+	Convey("通过 socket 查询", t, func() {
+		socket := filepath.Join(t.TempDir(), "daemon.sock")
+		l, err := net.Listen("unix", socket)
+		So(err, ShouldBeNil)
+		done := make(chan error)
+		go func() {
+			done <- Serve(l, func(req Request) Response {
+				if req.Op == OpStatus {
+					return Response{Status: &Status{PID: 42}}
+				}
+				return Response{Entries: []Entry{{Language: "go", Version: "1.22.3", File: req.Dir}}}
+			})
+		}()
+		resp, err := Query(socket, Request{Op: OpResolve, Dir: "/src/app"}, time.Second)
+		So(err, ShouldBeNil)
+		So(resp.Entries, ShouldResemble, []Entry{{Language: "go", Version: "1.22.3", File: "/src/app"}})
+		resp, err = Query(socket, Request{Op: OpStatus}, time.Second)
+		So(err, ShouldBeNil)
+		So(resp.Status.PID, ShouldEqual, 42)
+
+		So(l.Close(), ShouldBeNil)
+		So(<-done, ShouldBeNil)
+		_, err = Query(socket, Request{Op: OpStatus}, 100*time.Millisecond)
+		So(err, ShouldNotBeNil)
+	})
+}
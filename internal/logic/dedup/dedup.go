@@ -0,0 +1,125 @@
+// Package dedup 将多个安装目录中内容相同的文件硬链接为同一份，节省相邻版本重复占用的磁盘空间
+package dedup
+
+import (
+	"crypto/sha256"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/FirewineXie/envm/util"
+)
+
+// Result 一次去重的结果
+type Result struct {
+	// Linked 被替换为硬链接的文件数
+	Linked int
+	// Saved 释放的字节数，文件原来已经有其它硬链接时实际释放的空间会更少
+	Saved int64
+	// Failed 无法链接的文件数，例如位于不同的磁盘
+	Failed int
+}
+
+// candidate 大小及权限相同的文件才可能合并，硬链接共享权限
+type candidate struct {
+	size int64
+	mode fs.FileMode
+}
+
+// Run 查找 dirs 中内容相同的普通文件，保留第一个并将其余的替换为它的硬链接，dryRun 时只统计不修改
+func Run(dirs []string, dryRun bool) (result Result, err error) {
+	groups := map[candidate][]string{}
+	for _, dir := range dirs {
+		err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || !d.Type().IsRegular() {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if info.Size() == 0 {
+				return nil
+			}
+			key := candidate{info.Size(), info.Mode().Perm()}
+			groups[key] = append(groups[key], path)
+			return nil
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	for key, files := range groups {
+		if len(files) < 2 {
+			continue
+		}
+		byHash := map[[sha256.Size]byte][]string{}
+		for _, file := range files {
+			sum, err := hashFile(file)
+			if err != nil {
+				return result, err
+			}
+			byHash[sum] = append(byHash[sum], file)
+		}
+		for _, same := range byHash {
+			for _, file := range same[1:] {
+				if util.Interrupted() {
+					return result, util.ErrInterrupted
+				}
+				linked, err := link(same[0], file, dryRun)
+				switch {
+				case err != nil:
+					result.Failed++
+				case linked:
+					result.Linked++
+					result.Saved += key.size
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+func hashFile(filename string) (sum [sha256.Size]byte, err error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return sum, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return sum, err
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
+
+// link 将 file 替换为 source 的硬链接，两者已经是同一个文件时返回 false
+// 先链接到临时文件再重命名，中途失败不会丢失 file
+func link(source, file string, dryRun bool) (bool, error) {
+	sourceInfo, err := os.Stat(source)
+	if err != nil {
+		return false, err
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return false, err
+	}
+	if os.SameFile(sourceInfo, info) {
+		return false, nil
+	}
+	if dryRun {
+		return true, nil
+	}
+	tmp := file + ".envm-dedup"
+	if err = os.Link(source, tmp); err != nil {
+		return false, err
+	}
+	if err = os.Rename(tmp, file); err != nil {
+		_ = os.Remove(tmp)
+		return false, err
+	}
+	return true, nil
+}
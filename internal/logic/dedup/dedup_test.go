@@ -0,0 +1,47 @@
+package dedup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRun(t *testing.T) {
+	Convey("硬链接不同版本中相同的文件", t, func() {
+		root := t.TempDir()
+		write := func(name, content string) string {
+			filename := filepath.Join(root, name)
+			So(os.MkdirAll(filepath.Dir(filename), os.ModePerm), ShouldBeNil)
+			So(os.WriteFile(filename, []byte(content), 0644), ShouldBeNil)
+			return filename
+		}
+		a := write("go1.22.2/src/fmt/print.go", "package fmt")
+		b := write("go1.22.3/src/fmt/print.go", "package fmt")
+		c := write("go1.22.3/VERSION", "go1.22.3")
+		write("go1.22.2/VERSION", "go1.22.2")
+		dirs := []string{filepath.Join(root, "go1.22.2"), filepath.Join(root, "go1.22.3")}
+
+		result, err := Run(dirs, true)
+		So(err, ShouldBeNil)
+		So(result, ShouldResemble, Result{Linked: 1, Saved: int64(len("package fmt"))})
+		infoA, _ := os.Stat(a)
+		infoB, _ := os.Stat(b)
+		So(os.SameFile(infoA, infoB), ShouldBeFalse)
+
+		result, err = Run(dirs, false)
+		So(err, ShouldBeNil)
+		So(result.Linked, ShouldEqual, 1)
+		infoA, _ = os.Stat(a)
+		infoB, _ = os.Stat(b)
+		So(os.SameFile(infoA, infoB), ShouldBeTrue)
+		content, _ := os.ReadFile(c)
+		So(string(content), ShouldEqual, "go1.22.3")
+
+		// 已经链接过的文件不再重复统计
+		result, err = Run(dirs, false)
+		So(err, ShouldBeNil)
+		So(result, ShouldResemble, Result{})
+	})
+}
@@ -0,0 +1,15 @@
+// Package elevate 权限不足时以管理员权限重新执行当前命令，目前只支持 windows 的 UAC
+package elevate
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// ErrUnsupported 当前系统不支持提权重新执行
+var ErrUnsupported = errors.New("elevation is only supported on windows")
+
+// Required 判断错误是否由权限不足导致，windows 的 ERROR_ACCESS_DENIED 同样满足 fs.ErrPermission
+func Required(err error) bool {
+	return err != nil && errors.Is(err, fs.ErrPermission)
+}
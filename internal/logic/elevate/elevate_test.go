@@ -0,0 +1,19 @@
+package elevate
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRequired(t *testing.T) {
+	Convey("识别权限不足的错误", t, func() {
+		So(Required(nil), ShouldBeFalse)
+		So(Required(&os.PathError{Op: "symlink", Path: "/usr/local/go", Err: syscall.EACCES}), ShouldBeTrue)
+		So(Required(fmt.Errorf("wrap + %w", os.ErrPermission)), ShouldBeTrue)
+		So(Required(os.ErrNotExist), ShouldBeFalse)
+	})
+}
@@ -0,0 +1,18 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package elevate
+
+import "os"
+
+// Supported 是否可以提权重新执行
+const Supported = false
+
+// IsElevated 当前进程是否已经拥有管理员权限
+func IsElevated() bool {
+	return os.Geteuid() == 0
+}
+
+// Relaunch 以管理员权限重新执行 envm 并等待结束，返回退出码
+func Relaunch(args []string) (int, error) {
+	return 1, ErrUnsupported
+}
@@ -0,0 +1,50 @@
+//go:build windows
+
+package elevate
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Supported 是否可以提权重新执行
+const Supported = true
+
+// IsElevated 当前进程是否已经拥有管理员权限，只有管理员可以打开物理磁盘
+func IsElevated() bool {
+	f, err := os.Open(`\\.\PHYSICALDRIVE0`)
+	if err != nil {
+		return false
+	}
+	f.Close()
+	return true
+}
+
+// Relaunch 通过 UAC 以管理员权限重新执行 envm 并等待结束，返回退出码
+// 提权后的进程在新的窗口中运行，输出无法返回到当前终端
+func Relaunch(args []string) (int, error) {
+	envm, err := os.Executable()
+	if err != nil {
+		return 1, err
+	}
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, "'"+strings.ReplaceAll(arg, "'", "''")+"'")
+	}
+	script := "$p = Start-Process -FilePath '" + strings.ReplaceAll(envm, "'", "''") + "' -Verb RunAs -Wait -PassThru"
+	if len(quoted) > 0 {
+		script += " -ArgumentList " + strings.Join(quoted, ",")
+	}
+	script += "; exit $p.ExitCode"
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return 1, err
+	}
+	return 0, nil
+}
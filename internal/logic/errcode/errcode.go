@@ -0,0 +1,318 @@
+// Package errcode 为失败分配简短的错误码(ENVM-NET-002)，envm explain 根据错误码输出可能的原因及修复步骤
+// 错误码一经发布不再修改含义，新的失败类型使用新的编号
+package errcode
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/FirewineXie/envm/util"
+)
+
+// Prefix 错误码的前缀
+const Prefix = "ENVM-"
+
+// Unknown 没有归类的失败使用的错误码
+const Unknown = "ENVM-GEN-001"
+
+// Rejected 命令检查参数或状态后拒绝执行(cli.NewExitError)使用的错误码，由调用方区分，Of 不返回
+const Rejected = "ENVM-CMD-001"
+
+// Code 错误码及其说明
+type Code struct {
+	ID    string
+	Title string
+	// Causes 可能的原因，按可能性排序
+	Causes []string
+	// Steps 依次尝试的修复步骤
+	Steps []string
+	// match 判断错误是否属于该错误码，为空时只能通过 ID 查询(Unknown)
+	match func(err error) bool
+}
+
+// is 返回匹配任一 target 的判断函数
+func is(targets ...error) func(err error) bool {
+	return func(err error) bool {
+		for _, target := range targets {
+			if errors.Is(err, target) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Codes 所有的错误码，Of 按顺序匹配，具体的错误在前，所属的类别(ErrNetwork、ErrChecksum)在后
+var Codes = []Code{
+	{
+		ID:    "ENVM-NET-003",
+		Title: "the TLS certificate of the server is not trusted",
+		Causes: []string{
+			"the system has no root certificates, common in minimal containers",
+			"a corporate proxy or firewall re-signs HTTPS traffic with its own certificate",
+		},
+		Steps: []string{
+			"install the ca-certificates package of the system",
+			"or set ca_bundle = \"embedded\" in config.toml (ENVM_CA_BUNDLE=embedded) to use the roots shipped with envm",
+			"behind an intercepting proxy, set ca_bundle to the PEM file of the proxy certificate",
+		},
+		match: func(err error) bool {
+			var unknown x509.UnknownAuthorityError
+			var invalid x509.CertificateInvalidError
+			var hostname x509.HostnameError
+			return errors.As(err, &unknown) || errors.As(err, &invalid) || errors.As(err, &hostname)
+		},
+	},
+	{
+		ID:    "ENVM-NET-004",
+		Title: "the request timed out",
+		Causes: []string{
+			"the network is slow or the download source is overloaded",
+			"a firewall drops the connection instead of refusing it",
+		},
+		Steps: []string{
+			"retry the command, interrupted downloads resume where they stopped",
+			"switch to a closer mirror, see envm mirrors",
+			"configure [proxy] in config.toml when the network requires a proxy",
+		},
+		match: func(err error) bool {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return true
+			}
+			return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded)
+		},
+	},
+	{
+		ID:    "ENVM-NET-002",
+		Title: "the server responded with an unexpected HTTP status",
+		Causes: []string{
+			"the version or package does not exist on the source (404)",
+			"the source rate limits requests (403, 429), common with the GitHub API",
+			"the mirror is being updated or is temporarily down (5xx)",
+		},
+		Steps: []string{
+			"check the URL and status in the message, open the URL in a browser",
+			"retry in a few minutes or switch to another mirror, see envm mirrors",
+		},
+		match: func(err error) bool {
+			var netErr *util.NetworkError
+			return errors.As(err, &netErr) && netErr.Status != ""
+		},
+	},
+	{
+		ID:    "ENVM-NET-001",
+		Title: "the download source is unreachable",
+		Causes: []string{
+			"there is no network connection or DNS does not resolve the host",
+			"the network requires a proxy which is not configured",
+			"the [network] allow or deny rules of config.toml block the host",
+		},
+		Steps: []string{
+			"check the connection with curl -I on the URL in the message",
+			"configure [proxy] in config.toml or ENVM_PROXY, HTTPS_PROXY is also honoured",
+			"check the [network] rules in config.toml",
+			"switch to a reachable mirror, see envm mirrors",
+		},
+		match: is(util.ErrNetwork),
+	},
+	{
+		ID:    "ENVM-CHK-002",
+		Title: "no checksum is available for the package",
+		Causes: []string{
+			"the source or mirror does not publish checksums for this version",
+		},
+		Steps: []string{
+			"install from the official source, which publishes checksums",
+			"if you trust the source, pass --insecure to install without verification",
+		},
+		match: is(util.ErrChecksumMissing),
+	},
+	{
+		ID:    "ENVM-CHK-003",
+		Title: "the signature of the package is invalid",
+		Causes: []string{
+			"the package or its signature was modified after it was signed",
+			"the public key configured for the source does not match the signer",
+		},
+		Steps: []string{
+			"do not install the package, download it again from the official source",
+			"check the public key configured for the source",
+			"report the URL if the official release fails verification",
+		},
+		match: is(util.ErrInvalidSignature, util.ErrSignatureNotMatched),
+	},
+	{
+		ID:    "ENVM-CHK-004",
+		Title: "the checksum algorithm is not supported",
+		Causes: []string{
+			"the source publishes checksums with an algorithm envm does not know",
+		},
+		Steps: []string{
+			"update envm to the latest release",
+			"install from the official source, which publishes SHA256 checksums",
+		},
+		match: is(util.ErrUnsupportedChecksumAlgorithm),
+	},
+	{
+		ID:    "ENVM-CHK-001",
+		Title: "the downloaded file does not match its checksum",
+		Causes: []string{
+			"the download was truncated or corrupted on the way",
+			"a mirror or proxy serves a different file than the version index",
+			"the file was tampered with",
+		},
+		Steps: []string{
+			"install again, the mismatched file is not reused",
+			"switch to the official source or another mirror, see envm mirrors",
+			"do not pass --insecure, it does not skip mismatched checksums",
+		},
+		match: is(util.ErrChecksum),
+	},
+	{
+		ID:    "ENVM-VER-001",
+		Title: "the version does not exist",
+		Causes: []string{
+			"the version is misspelled or has not been released yet",
+			"the cached version list is out of date",
+		},
+		Steps: []string{
+			"list the available versions with envm <language> lsr",
+			"refresh the cached version lists with envm refresh",
+		},
+		match: is(util.ErrVersionNotFound),
+	},
+	{
+		ID:    "ENVM-VER-002",
+		Title: "the version publishes no package for this platform",
+		Causes: []string{
+			"the version was not built for this operating system or architecture",
+		},
+		Steps: []string{
+			"pick another version, envm <language> lsr lists the available ones",
+			"for go, install another architecture with --arch, e.g. --arch amd64 under Rosetta",
+		},
+		match: is(util.ErrPackageNotFound),
+	},
+	{
+		ID:    "ENVM-VER-003",
+		Title: "the source lists no versions",
+		Causes: []string{
+			"the mirror returned an empty or malformed version page",
+			"the source is being updated",
+		},
+		Steps: []string{
+			"retry in a few minutes",
+			"switch to another mirror, see envm mirrors",
+		},
+		match: is(util.ErrNoVersions),
+	},
+	{
+		ID:    "ENVM-FS-001",
+		Title: "permission denied",
+		Causes: []string{
+			"ENVM_HOME or the symlink directory belongs to another user",
+			"a shared installation is written without administrator rights",
+			"a file is in use by a running program (windows)",
+		},
+		Steps: []string{
+			"check the owner of the path in the message and of ENVM_HOME",
+			"for a shared installation run the command as administrator or with sudo",
+			"on windows close the programs using the toolchain and retry",
+		},
+		match: is(util.ErrPermission),
+	},
+	{
+		ID:    "ENVM-FS-002",
+		Title: "no space left on the device",
+		Causes: []string{
+			"the disk holding ENVM_HOME or the temporary directory is full",
+		},
+		Steps: []string{
+			"remove unused versions with envm <language> uninstall",
+			"envm dedup shares identical files between installed versions",
+			"move ENVM_HOME to a larger disk, see envm relocate",
+		},
+		match: is(syscall.ENOSPC),
+	},
+	{
+		ID:    "ENVM-INT-001",
+		Title: "the command was interrupted",
+		Causes: []string{
+			"Ctrl+C was pressed or the process received a termination signal",
+		},
+		Steps: []string{
+			"run the command again, downloads resume and partial installations are cleaned up",
+		},
+		match: is(util.ErrInterrupted),
+	},
+	{
+		ID:    Rejected,
+		Title: "the command rejected its arguments or the current state",
+		Causes: []string{
+			"an argument or flag is missing, misspelled or not supported",
+			"the version is not installed or is in use",
+			"a confirmation prompt was answered no",
+		},
+		Steps: []string{
+			"read the message, it names the argument or version involved",
+			"run the command with --help for its usage and examples",
+			"list the installed versions with envm <language> ls",
+		},
+	},
+	{
+		ID:    Unknown,
+		Title: "an unclassified error",
+		Causes: []string{
+			"the failure does not belong to a known category",
+		},
+		Steps: []string{
+			"read the message, it usually names the file or URL involved",
+			"run envm doctor to check the setup",
+			"report it with the command, the full output and the output of envm --version",
+		},
+	},
+}
+
+// Of 返回 err 的错误码，没有归类的错误返回 Unknown，err 为空时返回空
+func Of(err error) string {
+	if err == nil {
+		return ""
+	}
+	for _, code := range Codes {
+		if code.match != nil && code.match(err) {
+			return code.ID
+		}
+	}
+	return Unknown
+}
+
+// Lookup 按 ID 查询错误码，不区分大小写，可以省略 ENVM- 前缀(net-002)
+func Lookup(id string) (Code, bool) {
+	id = strings.ToUpper(strings.TrimSpace(id))
+	if !strings.HasPrefix(id, Prefix) {
+		id = Prefix + id
+	}
+	for _, code := range Codes {
+		if code.ID == id {
+			return code, true
+		}
+	}
+	return Code{}, false
+}
+
+// IDs 按字母顺序返回所有错误码
+func IDs() []string {
+	ids := make([]string, 0, len(Codes))
+	for _, code := range Codes {
+		ids = append(ids, code.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
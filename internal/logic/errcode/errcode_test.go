@@ -0,0 +1,57 @@
+package errcode
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/FirewineXie/envm/util"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOf(t *testing.T) {
+	Convey("按错误的类型分配错误码", t, func() {
+		So(Of(nil), ShouldEqual, "")
+		So(Of(util.NewNetworkError("https://go.dev/dl/", errors.New("connection refused"))), ShouldEqual, "ENVM-NET-001")
+		So(Of(util.NewStatusError("https://go.dev/dl/", "404 Not Found")), ShouldEqual, "ENVM-NET-002")
+		So(Of(util.NewNetworkError("https://go.dev/dl/", x509.UnknownAuthorityError{})), ShouldEqual, "ENVM-NET-003")
+		So(Of(util.NewNetworkError("https://go.dev/dl/", os.ErrDeadlineExceeded)), ShouldEqual, "ENVM-NET-004")
+		So(Of(fmt.Errorf("install go1.22.3: %w", util.ErrChecksumNotMatched)), ShouldEqual, "ENVM-CHK-001")
+		So(Of(util.ErrChecksumMissing), ShouldEqual, "ENVM-CHK-002")
+		So(Of(util.ErrSignatureNotMatched), ShouldEqual, "ENVM-CHK-003")
+		So(Of(util.ErrVersionNotFound), ShouldEqual, "ENVM-VER-001")
+		So(Of(&os.PathError{Op: "open", Path: "/opt/envm", Err: os.ErrPermission}), ShouldEqual, "ENVM-FS-001")
+		So(Of(errors.New("something else")), ShouldEqual, Unknown)
+	})
+}
+
+func TestLookup(t *testing.T) {
+	Convey("查询错误码", t, func() {
+		code, ok := Lookup("net-002")
+		So(ok, ShouldBeTrue)
+		So(code.ID, ShouldEqual, "ENVM-NET-002")
+		_, ok = Lookup(Rejected)
+		So(ok, ShouldBeTrue)
+		_, ok = Lookup(" ENVM-CHK-001 ")
+		So(ok, ShouldBeTrue)
+		_, ok = Lookup("ENVM-NET-999")
+		So(ok, ShouldBeFalse)
+	})
+
+	Convey("错误码唯一且都有原因及修复步骤", t, func() {
+		ids := IDs()
+		So(len(ids), ShouldEqual, len(Codes))
+		for i, id := range ids {
+			So(strings.HasPrefix(id, Prefix), ShouldBeTrue)
+			if i > 0 {
+				So(id, ShouldNotEqual, ids[i-1])
+			}
+			code, _ := Lookup(id)
+			So(code.Causes, ShouldNotBeEmpty)
+			So(code.Steps, ShouldNotBeEmpty)
+		}
+	})
+}
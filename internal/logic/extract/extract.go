@@ -0,0 +1,342 @@
+// Package extract 解压安装包，zip 及 tar.gz 由多个 worker 并发写入文件
+// JDK 等安装包包含上万个小文件，windows 上逐个写入文件是安装中最慢的阶段
+package extract
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/FirewineXie/envm/util"
+	"github.com/mholt/archiver/v3"
+)
+
+// inlineSize 超过该大小的 tar 文件直接在读取的 goroutine 中写入，限制缓存在内存中的数据量
+const inlineSize = 1 << 20
+
+// Workers 并发写入文件的 worker 数
+var Workers = runtime.NumCPU() * 2
+
+// Unarchive 将 archive 解压到 dest，zip 及 tar.gz 并发解压，安装程序(pkg/msi)只展开其中的文件，其它格式使用 archiver
+func Unarchive(archive, dest string) error {
+	defer util.Phase(util.PhaseExtract)()
+	name := strings.ToLower(archive)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return unzip(archive, dest)
+	case strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz"):
+		return untar(archive, dest)
+	case strings.HasSuffix(name, ".pkg") || strings.HasSuffix(name, ".msi"):
+		return unpackInstaller(archive, dest)
+	default:
+		return archiver.Unarchive(archive, dest)
+	}
+}
+
+// unpackInstaller 不执行安装，只把安装程序中的文件展开到 dest，不修改系统目录及注册表
+// macOS 的 pkg 使用 pkgutil --expand-full，windows 的 msi 使用 msiexec /a(管理安装，不需要管理员权限)
+func unpackInstaller(archive, dest string) error {
+	var cmd *exec.Cmd
+	if strings.HasSuffix(strings.ToLower(archive), ".msi") {
+		// msiexec 要求绝对路径
+		abs, err := filepath.Abs(dest)
+		if err != nil {
+			return err
+		}
+		if archive, err = filepath.Abs(archive); err != nil {
+			return err
+		}
+		cmd = exec.Command("msiexec", "/a", archive, "/qn", "TARGETDIR="+abs)
+	} else {
+		// pkgutil 要求 dest 不存在
+		cmd = exec.Command("pkgutil", "--expand-full", archive, dest)
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("unpack %s error + %w: %s", filepath.Base(archive), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// FindDir 返回 root 中名为 name(不区分大小写)的层级最浅的目录，没有时返回空
+// 安装程序展开后工具链位于安装位置对应的子目录中，例如 Payload/usr/local/go
+func FindDir(root, name string) string {
+	found, depth := "", -1
+	_ = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() || path == root || !strings.EqualFold(entry.Name(), name) {
+			return nil
+		}
+		if d := strings.Count(path, string(filepath.Separator)); depth < 0 || d < depth {
+			found, depth = path, d
+		}
+		return filepath.SkipDir
+	})
+	return found
+}
+
+// link 所有文件写入后再创建的链接
+type link struct {
+	path, target string
+	hard         bool
+}
+
+// pool 并发写文件，记录第一个错误
+type pool struct {
+	jobs chan func() error
+	wg   sync.WaitGroup
+	once sync.Once
+	err  error
+}
+
+func newPool() *pool {
+	workers := Workers
+	if workers < 1 {
+		workers = 1
+	}
+	p := &pool{jobs: make(chan func() error, workers)}
+	for i := 0; i < workers; i++ {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for job := range p.jobs {
+				if err := job(); err != nil {
+					p.fail(err)
+				}
+			}
+		}()
+	}
+	return p
+}
+
+func (p *pool) fail(err error) {
+	p.once.Do(func() { p.err = err })
+}
+
+// wait 等待所有文件写入完成
+func (p *pool) wait() error {
+	close(p.jobs)
+	p.wg.Wait()
+	return p.err
+}
+
+// target 返回 name 在 dest 下的路径，拒绝 ../ 等指向 dest 之外的路径
+func target(dest, name string) (string, error) {
+	dest = filepath.Clean(dest)
+	path := filepath.Join(dest, filepath.FromSlash(name))
+	if path != dest && !strings.HasPrefix(path, dest+string(os.PathSeparator)) {
+		return "", fmt.Errorf("illegal file path in archive: %s", name)
+	}
+	return path, nil
+}
+
+// writeFile 写入一个文件，目录已经提前创建
+func writeFile(path string, mode fs.FileMode, r io.Reader) error {
+	perm := mode.Perm()
+	if perm == 0 {
+		perm = 0644
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// createLinks 创建链接，硬链接的目标为压缩包内的路径
+func createLinks(dest string, links []link) error {
+	for _, l := range links {
+		_ = os.Remove(l.path)
+		if l.hard {
+			source, err := target(dest, l.target)
+			if err != nil {
+				return err
+			}
+			if err = os.Link(source, l.path); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.Symlink(l.target, l.path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mkdirs 按路径顺序创建目录，父目录先于子目录
+func mkdirs(dirs map[string]bool) error {
+	sorted := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		sorted = append(sorted, dir)
+	}
+	sort.Strings(sorted)
+	for _, dir := range sorted {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unzip zip 可以随机读取，先创建所有目录，再由 worker 各自打开条目写入
+func unzip(archive, dest string) error {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	dirs := map[string]bool{filepath.Clean(dest): true}
+	for _, f := range r.File {
+		path, err := target(dest, f.Name)
+		if err != nil {
+			return err
+		}
+		if f.FileInfo().IsDir() {
+			dirs[path] = true
+		} else {
+			dirs[filepath.Dir(path)] = true
+		}
+	}
+	if err = mkdirs(dirs); err != nil {
+		return err
+	}
+
+	p := newPool()
+	links := make([]link, 0)
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if util.Interrupted() {
+			p.fail(util.ErrInterrupted)
+			break
+		}
+		f := f
+		path, _ := target(dest, f.Name)
+		if f.Mode()&fs.ModeSymlink != 0 {
+			rc, err := f.Open()
+			if err != nil {
+				p.fail(err)
+				break
+			}
+			data, err := io.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				p.fail(err)
+				break
+			}
+			links = append(links, link{path: path, target: string(data)})
+			continue
+		}
+		p.jobs <- func() error {
+			rc, err := f.Open()
+			if err != nil {
+				return err
+			}
+			defer rc.Close()
+			return writeFile(path, f.Mode(), rc)
+		}
+	}
+	if err = p.wait(); err != nil {
+		return err
+	}
+	return createLinks(dest, links)
+}
+
+// untar tar 只能顺序读取，小文件读入内存后交给 worker 写入，大文件直接写入
+func untar(archive, dest string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	created := map[string]bool{}
+	mkdir := func(dir string) error {
+		if created[dir] {
+			return nil
+		}
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return err
+		}
+		created[dir] = true
+		return nil
+	}
+	if err = mkdir(filepath.Clean(dest)); err != nil {
+		return err
+	}
+
+	p := newPool()
+	links := make([]link, 0)
+	tr := tar.NewReader(gz)
+	for {
+		if util.Interrupted() {
+			p.fail(util.ErrInterrupted)
+			break
+		}
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			p.fail(err)
+			break
+		}
+		path, err := target(dest, header.Name)
+		if err != nil {
+			p.fail(err)
+			break
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = mkdir(path)
+		case tar.TypeSymlink, tar.TypeLink:
+			err = mkdir(filepath.Dir(path))
+			links = append(links, link{path: path, target: header.Linkname, hard: header.Typeflag == tar.TypeLink})
+		case tar.TypeReg, tar.TypeRegA:
+			if err = mkdir(filepath.Dir(path)); err != nil {
+				break
+			}
+			mode := header.FileInfo().Mode()
+			if header.Size > inlineSize {
+				err = writeFile(path, mode, tr)
+				break
+			}
+			data := make([]byte, header.Size)
+			if _, err = io.ReadFull(tr, data); err != nil {
+				break
+			}
+			p.jobs <- func() error {
+				return writeFile(path, mode, bytes.NewReader(data))
+			}
+		}
+		if err != nil {
+			p.fail(err)
+			break
+		}
+	}
+	if err = p.wait(); err != nil {
+		return err
+	}
+	return createLinks(dest, links)
+}
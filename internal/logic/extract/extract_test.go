@@ -0,0 +1,104 @@
+package extract
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func writeZip(filename string, files map[string]string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := zip.NewWriter(f)
+	for name, content := range files {
+		fw, err := w.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err = fw.Write([]byte(content)); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+func TestUnarchive(t *testing.T) {
+	Convey("并发解压 zip", t, func() {
+		root := t.TempDir()
+		archive := filepath.Join(root, "go.zip")
+		files := map[string]string{"go/VERSION": "go1.22.3"}
+		for i := 0; i < 50; i++ {
+			files[fmt.Sprintf("go/src/pkg%d/file.go", i)] = fmt.Sprintf("package pkg%d", i)
+		}
+		So(writeZip(archive, files), ShouldBeNil)
+
+		dest := filepath.Join(root, "out")
+		So(Unarchive(archive, dest), ShouldBeNil)
+		for name, content := range files {
+			data, err := os.ReadFile(filepath.Join(dest, filepath.FromSlash(name)))
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, content)
+		}
+	})
+
+	Convey("并发解压 tar.gz，保留权限及链接", t, func() {
+		root := t.TempDir()
+		archive := filepath.Join(root, "node.tar.gz")
+		f, err := os.Create(archive)
+		So(err, ShouldBeNil)
+		gz := gzip.NewWriter(f)
+		tw := tar.NewWriter(gz)
+		So(tw.WriteHeader(&tar.Header{Name: "node/bin/", Typeflag: tar.TypeDir, Mode: 0755}), ShouldBeNil)
+		body := "#!/bin/sh"
+		So(tw.WriteHeader(&tar.Header{Name: "node/bin/node", Typeflag: tar.TypeReg, Mode: 0755, Size: int64(len(body))}), ShouldBeNil)
+		_, err = tw.Write([]byte(body))
+		So(err, ShouldBeNil)
+		So(tw.WriteHeader(&tar.Header{Name: "node/bin/npm", Typeflag: tar.TypeSymlink, Linkname: "node"}), ShouldBeNil)
+		So(tw.Close(), ShouldBeNil)
+		So(gz.Close(), ShouldBeNil)
+		So(f.Close(), ShouldBeNil)
+
+		dest := filepath.Join(root, "out")
+		So(Unarchive(archive, dest), ShouldBeNil)
+		data, err := os.ReadFile(filepath.Join(dest, "node", "bin", "node"))
+		So(err, ShouldBeNil)
+		So(string(data), ShouldEqual, body)
+		if runtime.GOOS != "windows" {
+			info, err := os.Stat(filepath.Join(dest, "node", "bin", "node"))
+			So(err, ShouldBeNil)
+			So(info.Mode().Perm(), ShouldEqual, os.FileMode(0755))
+			linked, err := os.Readlink(filepath.Join(dest, "node", "bin", "npm"))
+			So(err, ShouldBeNil)
+			So(linked, ShouldEqual, "node")
+		}
+	})
+
+	Convey("拒绝指向解压目录之外的路径", t, func() {
+		root := t.TempDir()
+		archive := filepath.Join(root, "evil.zip")
+		So(writeZip(archive, map[string]string{"../evil": "x"}), ShouldBeNil)
+		So(Unarchive(archive, filepath.Join(root, "out")), ShouldNotBeNil)
+		exists, _ := os.Stat(filepath.Join(root, "evil"))
+		So(exists, ShouldBeNil)
+	})
+}
+
+func TestFindDir(t *testing.T) {
+	Convey("在展开的安装程序中查找工具链目录", t, func() {
+		root := t.TempDir()
+		So(os.MkdirAll(filepath.Join(root, "a", "Payload", "usr", "local", "go", "src", "go"), os.ModePerm), ShouldBeNil)
+		So(os.MkdirAll(filepath.Join(root, "b", "c", "d", "e", "f", "Go"), os.ModePerm), ShouldBeNil)
+		So(FindDir(root, "go"), ShouldEqual, filepath.Join(root, "a", "Payload", "usr", "local", "go"))
+		So(FindDir(root, "node"), ShouldBeEmpty)
+	})
+}
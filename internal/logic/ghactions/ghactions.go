@@ -0,0 +1,102 @@
+// Package ghactions GitHub Actions 的工作流命令及环境文件，envm 在 workflow 中安装版本后通过它们把结果交给后续步骤
+package ghactions
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrNotActions 没有 GITHUB_PATH，不在 GitHub Actions 的 job 中运行
+var ErrNotActions = errors.New("GITHUB_PATH is not set, run it inside a GitHub Actions job")
+
+// EndGroup 结束折叠的日志分组
+const EndGroup = "::endgroup::"
+
+// Files runner 提供的环境文件，写入的内容在后续步骤中生效
+type Files struct {
+	// Path 每行一个目录，追加到后续步骤的 PATH 前面
+	Path string
+	// Env 后续步骤的环境变量
+	Env string
+	// Output 当前步骤的输出，旧版本的 runner 没有时为空
+	Output string
+}
+
+// FromEnv 从 GITHUB_PATH、GITHUB_ENV、GITHUB_OUTPUT 读取环境文件
+func FromEnv() (Files, error) {
+	files := Files{Path: os.Getenv("GITHUB_PATH"), Env: os.Getenv("GITHUB_ENV"), Output: os.Getenv("GITHUB_OUTPUT")}
+	if files.Path == "" {
+		return files, ErrNotActions
+	}
+	return files, nil
+}
+
+// AddPath 将 dir 加入后续步骤的 PATH
+func (f Files) AddPath(dir string) error {
+	return appendFile(f.Path, dir+"\n")
+}
+
+// SetEnv 设置后续步骤的环境变量
+func (f Files) SetEnv(name, value string) error {
+	if f.Env == "" {
+		return errors.New("GITHUB_ENV is not set")
+	}
+	return appendFile(f.Env, keyValue(name, value))
+}
+
+// SetOutput 设置当前步骤的输出，没有 GITHUB_OUTPUT 时忽略
+func (f Files) SetOutput(name, value string) error {
+	if f.Output == "" {
+		return nil
+	}
+	return appendFile(f.Output, keyValue(name, value))
+}
+
+func appendFile(file, content string) error {
+	out, err := os.OpenFile(file, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = out.WriteString(content); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// keyValue 使用 name<<delimiter 的多行格式，值中包含换行时也不会被解析成其它变量
+func keyValue(name, value string) string {
+	delimiter := "ghadelimiter_"
+	for {
+		buf := make([]byte, 8)
+		_, _ = rand.Read(buf)
+		if candidate := fmt.Sprintf("%s%x", delimiter, buf); !strings.Contains(value, candidate) {
+			delimiter = candidate
+			break
+		}
+	}
+	return fmt.Sprintf("%s<<%s\n%s\n%s\n", name, delimiter, value, delimiter)
+}
+
+// Group 开始折叠的日志分组，之后的输出显示在 title 下
+func Group(title string) string {
+	return "::group::" + escapeData(title)
+}
+
+// Error 输出为 workflow 的错误注解，同时显示在日志及 job 的概要中
+func Error(title, message string) string {
+	return fmt.Sprintf("::error title=%s::%s", escapeProperty(title), escapeData(message))
+}
+
+// escapeData 工作流命令的消息中 %、换行需要转义，否则多行错误只有第一行成为注解
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty 命令属性中还需要转义 : 和 ,
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,48 @@
+package ghactions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFiles(t *testing.T) {
+	Convey("写入 GitHub Actions 的环境文件", t, func() {
+		dir := t.TempDir()
+		t.Setenv("GITHUB_PATH", "")
+		_, err := FromEnv()
+		So(err, ShouldEqual, ErrNotActions)
+
+		t.Setenv("GITHUB_PATH", filepath.Join(dir, "path"))
+		t.Setenv("GITHUB_ENV", filepath.Join(dir, "env"))
+		t.Setenv("GITHUB_OUTPUT", "")
+		files, err := FromEnv()
+		So(err, ShouldBeNil)
+
+		So(files.AddPath("/opt/envm/downloads/go1.22.3/bin"), ShouldBeNil)
+		So(files.AddPath("/opt/envm/downloads/node20.12.2/bin"), ShouldBeNil)
+		data, _ := os.ReadFile(files.Path)
+		So(string(data), ShouldEqual, "/opt/envm/downloads/go1.22.3/bin\n/opt/envm/downloads/node20.12.2/bin\n")
+
+		So(files.SetEnv("GOROOT", "/opt/envm/downloads/go1.22.3"), ShouldBeNil)
+		data, _ = os.ReadFile(files.Env)
+		lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		So(len(lines), ShouldEqual, 3)
+		So(lines[0], ShouldStartWith, "GOROOT<<ghadelimiter_")
+		So(lines[1], ShouldEqual, "/opt/envm/downloads/go1.22.3")
+		So(lines[2], ShouldEqual, strings.TrimPrefix(lines[0], "GOROOT<<"))
+
+		// 没有 GITHUB_OUTPUT 时忽略输出
+		So(files.SetOutput("version", "1.22.3"), ShouldBeNil)
+	})
+}
+
+func TestCommands(t *testing.T) {
+	Convey("工作流命令的转义", t, func() {
+		So(Group("install go 1.22.3"), ShouldEqual, "::group::install go 1.22.3")
+		So(Error("envm: go", "100% failed\nretry"), ShouldEqual, "::error title=envm%3A go::100%25 failed%0Aretry")
+	})
+}
@@ -0,0 +1,87 @@
+// Package githook 生成在提交或推送前检查工具链版本的 git hook，避免使用与项目声明不一致的版本构建及提交
+// hook 只调用 envm check，版本的比较及修复提示都在 envm 中，升级 envm 后不需要重新生成
+package githook
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Hooks 支持的 hook
+var Hooks = []string{"pre-commit", "pre-push"}
+
+// 输出的格式
+const (
+	// FormatGit 写入仓库的 hooks 目录(.git/hooks 或 core.hooksPath)
+	FormatGit = "git"
+	// FormatHusky 写入 .husky/<hook>，随仓库提交后所有开发者共用
+	FormatHusky = "husky"
+	// FormatPreCommit 输出 pre-commit 框架(.pre-commit-config.yaml)的本地 hook 配置
+	FormatPreCommit = "pre-commit"
+)
+
+// Formats 支持的格式
+var Formats = []string{FormatGit, FormatHusky, FormatPreCommit}
+
+// Marker 生成的 hook 中的标记，存在该标记的文件可以直接覆盖
+const Marker = "generated by envm generate git-hook"
+
+// Check 检查 hook 及格式是否支持
+func Check(hook, format string) error {
+	if !contains(Hooks, hook) {
+		return fmt.Errorf("unsupported hook %s, use %s", hook, strings.Join(Hooks, " or "))
+	}
+	if !contains(Formats, format) {
+		return fmt.Errorf("unsupported format %s, use %s", format, strings.Join(Formats, ", "))
+	}
+	return nil
+}
+
+func contains(items []string, item string) bool {
+	for _, v := range items {
+		if v == item {
+			return true
+		}
+	}
+	return false
+}
+
+// skip 跳过 hook 的 git 命令
+func skip(hook string) string {
+	if hook == "pre-push" {
+		return "git push --no-verify"
+	}
+	return "git commit --no-verify"
+}
+
+// Script 返回 git 及 husky 使用的 sh 脚本，windows 上的 git 同样通过自带的 sh 执行
+// 没有安装 envm 的开发者(例如 GUI 客户端的 PATH 中没有 envm)只提示，不阻止提交
+func Script(hook string) string {
+	return fmt.Sprintf(`#!/bin/sh
+# %s, checks that the active toolchains match the versions pinned by the project
+# regenerate it with envm generate git-hook --hook %s
+if ! command -v envm >/dev/null 2>&1; then
+    echo "%s: envm is not in PATH, the toolchain versions are not checked" >&2
+    exit 0
+fi
+if ! envm check; then
+    echo "%s: switch to the pinned versions as shown above, or skip the check with %s" >&2
+    exit 1
+fi
+`, Marker, hook, hook, hook, skip(hook))
+}
+
+// PreCommitConfig 返回 .pre-commit-config.yaml 中 repos 的一项，hook 为 pre-commit 框架中的 stage
+func PreCommitConfig(hook string) string {
+	return fmt.Sprintf(`# %s, add it to the repos of .pre-commit-config.yaml
+- repo: local
+  hooks:
+    - id: envm-check
+      name: check the toolchain versions pinned by the project
+      entry: envm check
+      language: system
+      pass_filenames: false
+      always_run: true
+      stages: [%s]
+`, Marker, hook)
+}
@@ -0,0 +1,30 @@
+package githook
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCheck(t *testing.T) {
+	Convey("检查 hook 及格式", t, func() {
+		So(Check("pre-commit", FormatGit), ShouldBeNil)
+		So(Check("pre-push", FormatPreCommit), ShouldBeNil)
+		So(Check("post-merge", FormatGit).Error(), ShouldEqual, "unsupported hook post-merge, use pre-commit or pre-push")
+		So(Check("pre-commit", "lefthook").Error(), ShouldEqual, "unsupported format lefthook, use git, husky, pre-commit")
+	})
+}
+
+func TestScript(t *testing.T) {
+	Convey("生成的脚本", t, func() {
+		script := Script("pre-push")
+		So(script, ShouldStartWith, "#!/bin/sh\n# "+Marker)
+		So(script, ShouldContainSubstring, "if ! envm check; then")
+		So(script, ShouldContainSubstring, "skip the check with git push --no-verify")
+		So(Script("pre-commit"), ShouldContainSubstring, "git commit --no-verify")
+
+		config := PreCommitConfig("pre-commit")
+		So(config, ShouldContainSubstring, "entry: envm check\n")
+		So(config, ShouldEndWith, "stages: [pre-commit]\n")
+	})
+}
@@ -0,0 +1,85 @@
+// Package goenv 比较两个 go 版本的环境、支持的平台及自带的工具，排查在 1.21 可以、1.22 不可以的问题
+package goenv
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Change 两个版本中值不同的一项，为空表示该版本中没有
+type Change struct {
+	Key string
+	A   string
+	B   string
+}
+
+// buildDir GOGCCFLAGS 中每次执行都不同的临时编译目录
+var buildDir = regexp.MustCompile(`go-build\d+`)
+
+// ParseEnv 解析 go env -json 的输出，去掉每次执行都不同的临时目录
+func ParseEnv(data []byte) (map[string]string, error) {
+	env := map[string]string{}
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, err
+	}
+	if flags, ok := env["GOGCCFLAGS"]; ok {
+		env["GOGCCFLAGS"] = buildDir.ReplaceAllString(flags, "go-build")
+	}
+	return env, nil
+}
+
+// ParseList 解析每行一项的输出，例如 go tool dist list，忽略空行
+func ParseList(data string) []string {
+	items := make([]string, 0)
+	for _, line := range strings.Split(data, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			items = append(items, line)
+		}
+	}
+	return items
+}
+
+// Diff 返回 a、b 中值不同的键，按键排序
+func Diff(a, b map[string]string) []Change {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	changes := make([]Change, 0)
+	for _, key := range keys {
+		if a[key] != b[key] {
+			changes = append(changes, Change{Key: key, A: a[key], B: b[key]})
+		}
+	}
+	return changes
+}
+
+// Compare 返回只在 a 中及只在 b 中的项，均已排序
+func Compare(a, b []string) (onlyA, onlyB []string) {
+	inA, inB := map[string]bool{}, map[string]bool{}
+	for _, item := range a {
+		inA[item] = true
+	}
+	for _, item := range b {
+		inB[item] = true
+		if !inA[item] {
+			onlyB = append(onlyB, item)
+		}
+	}
+	for _, item := range a {
+		if !inB[item] {
+			onlyA = append(onlyA, item)
+		}
+	}
+	sort.Strings(onlyA)
+	sort.Strings(onlyB)
+	return onlyA, onlyB
+}
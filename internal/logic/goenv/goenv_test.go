@@ -0,0 +1,32 @@
+package goenv
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDiff(t *testing.T) {
+	Convey("只列出值不同的环境变量", t, func() {
+		a, err := ParseEnv([]byte(`{"GOROOT":"/envm/go1.21.0","GOARCH":"amd64","GOAMD64":"v1","GOEXPERIMENT":""}`))
+		So(err, ShouldBeNil)
+		b, err := ParseEnv([]byte(`{"GOROOT":"/envm/go1.22.3","GOARCH":"amd64","GOAMD64":"v1","GOTOOLCHAIN":"local"}`))
+		So(err, ShouldBeNil)
+		So(Diff(a, b), ShouldResemble, []Change{
+			{Key: "GOROOT", A: "/envm/go1.21.0", B: "/envm/go1.22.3"},
+			{Key: "GOTOOLCHAIN", B: "local"},
+		})
+
+		a, _ = ParseEnv([]byte(`{"GOGCCFLAGS":"-fPIC -ffile-prefix-map=/tmp/go-build3663456016=/tmp/go-build"}`))
+		b, _ = ParseEnv([]byte(`{"GOGCCFLAGS":"-fPIC -ffile-prefix-map=/tmp/go-build360015280=/tmp/go-build"}`))
+		So(Diff(a, b), ShouldBeEmpty)
+	})
+
+	Convey("比较支持的平台", t, func() {
+		a := ParseList("linux/amd64\nnacl/386\nwasip1/wasm\n")
+		b := ParseList("linux/amd64\r\nwasip1/wasm\r\nopenbsd/riscv64\r\n")
+		onlyA, onlyB := Compare(a, b)
+		So(onlyA, ShouldResemble, []string{"nacl/386"})
+		So(onlyB, ShouldResemble, []string{"openbsd/riscv64"})
+	})
+}
@@ -0,0 +1,142 @@
+// Package gomod 读取 go.mod 中的 go 与 toolchain 指令，并按 go 工具链选择的规则比较版本
+package gomod
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// FileName go 模块文件
+const FileName = "go.mod"
+
+// Requirement go.mod 对工具链的要求
+type Requirement struct {
+	// Go go 指令，最低版本
+	Go string
+	// Toolchain toolchain 指令去掉 go 前缀后的版本，推荐使用的版本
+	Toolchain string
+}
+
+// Read 读取 go.mod，只解析 go 与 toolchain 两个指令
+func Read(filename string) (req Requirement, err error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return req, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		switch fields[0] {
+		case "go":
+			req.Go = fields[1]
+		case "toolchain":
+			// toolchain default 表示不指定
+			if fields[1] != "default" {
+				req.Toolchain = strings.TrimPrefix(fields[1], "go")
+			}
+		}
+	}
+	return req, scanner.Err()
+}
+
+// Satisfied 判断版本是否满足 go 指令的最低版本要求
+func (r Requirement) Satisfied(version string) bool {
+	return r.Go == "" || Compare(version, r.Go) >= 0
+}
+
+// Preferred 返回需要安装时使用的版本，优先 toolchain 指令
+// 1.21 之后 go 1.21 表示语言版本，对应的第一个发布版本为 1.21.0
+func (r Requirement) Preferred() string {
+	if r.Toolchain != "" {
+		return r.Toolchain
+	}
+	v := parse(r.Go)
+	if v.major == 1 && v.minor >= 21 && !v.hasPatch && v.kind == "" {
+		return r.Go + ".0"
+	}
+	return r.Go
+}
+
+type version struct {
+	major, minor, patch int
+	hasPatch            bool
+	// kind 为空表示正式版本，否则为 beta/rc
+	kind string
+	pre  int
+}
+
+// parse 解析 1.21 1.21.5 1.22rc1 1.18beta2 格式的版本，无法解析的部分按 0 处理
+func parse(s string) (v version) {
+	s = strings.TrimPrefix(s, "go")
+	for _, kind := range []string{"beta", "rc"} {
+		if i := strings.Index(s, kind); i >= 0 {
+			v.kind = kind
+			v.pre, _ = strconv.Atoi(s[i+len(kind):])
+			s = s[:i]
+			break
+		}
+	}
+	parts := strings.Split(s, ".")
+	v.major, _ = strconv.Atoi(parts[0])
+	if len(parts) > 1 {
+		v.minor, _ = strconv.Atoi(parts[1])
+	}
+	if len(parts) > 2 {
+		v.patch, _ = strconv.Atoi(parts[2])
+		v.hasPatch = true
+	}
+	return v
+}
+
+// Compare 比较两个 go 版本，规则同 go/version: 1.21 < 1.21rc1 < 1.21.0 < 1.21.1
+func Compare(a, b string) int {
+	x, y := parse(a), parse(b)
+	for _, d := range []int{x.major - y.major, x.minor - y.minor} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+	if d := x.rank() - y.rank(); d != 0 {
+		return sign(d)
+	}
+	if x.kind != "" {
+		return sign(x.pre - y.pre)
+	}
+	return sign(x.patch - y.patch)
+}
+
+// rank 同一个 minor 中的顺序: 语言版本 < beta < rc < 正式版本
+func (v version) rank() int {
+	switch {
+	case v.kind == "beta":
+		return 1
+	case v.kind == "rc":
+		return 2
+	case v.hasPatch:
+		return 3
+	case v.major == 1 && v.minor < 21:
+		// 1.21 之前 1.20 就是第一个正式版本
+		return 3
+	}
+	return 0
+}
+
+func sign(d int) int {
+	switch {
+	case d > 0:
+		return 1
+	case d < 0:
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,39 @@
+package gomod
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRead(t *testing.T) {
+	Convey("读取 go 与 toolchain 指令", t, func() {
+		filename := filepath.Join(t.TempDir(), FileName)
+		content := "module example.com/m\n\ngo 1.21 // min\n\ntoolchain go1.22.3\n\nrequire (\n\tgo.uber.org/zap v1.27.0\n)\n"
+		So(os.WriteFile(filename, []byte(content), 0644), ShouldBeNil)
+
+		req, err := Read(filename)
+		So(err, ShouldBeNil)
+		So(req, ShouldResemble, Requirement{Go: "1.21", Toolchain: "1.22.3"})
+		So(req.Preferred(), ShouldEqual, "1.22.3")
+		So(req.Satisfied("1.21.0"), ShouldBeTrue)
+		So(req.Satisfied("1.20.14"), ShouldBeFalse)
+		So(Requirement{Go: "1.21"}.Preferred(), ShouldEqual, "1.21.0")
+		So(Requirement{Go: "1.19"}.Preferred(), ShouldEqual, "1.19")
+	})
+}
+
+func TestCompare(t *testing.T) {
+	Convey("比较 go 版本", t, func() {
+		So(Compare("1.21", "1.21rc1"), ShouldEqual, -1)
+		So(Compare("1.21rc1", "1.21rc2"), ShouldEqual, -1)
+		So(Compare("1.21rc2", "1.21.0"), ShouldEqual, -1)
+		So(Compare("1.21.0", "1.21.1"), ShouldEqual, -1)
+		So(Compare("1.20", "1.20.0"), ShouldEqual, 0)
+		So(Compare("1.19.13", "1.20"), ShouldEqual, -1)
+		So(Compare("1.22.0", "1.21.9"), ShouldEqual, 1)
+		So(Compare("1.18beta2", "1.18rc1"), ShouldEqual, -1)
+	})
+}
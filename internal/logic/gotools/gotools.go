@@ -0,0 +1,62 @@
+// Package gotools 通过 go install 为每个 go 版本单独安装的常用工具
+package gotools
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Tool 可以通过 go install 安装的工具
+type Tool struct {
+	// Name 可执行文件名
+	Name string
+	// Package go install 的包路径
+	Package string
+	// Version 模块版本，默认为 latest
+	Version string
+}
+
+// Target go install 的参数
+func (t Tool) Target() string {
+	return t.Package + "@" + t.Version
+}
+
+// Catalog envm go tools install 不指定工具时安装的工具
+var Catalog = []Tool{
+	{Name: "gopls", Package: "golang.org/x/tools/gopls"},
+	{Name: "dlv", Package: "github.com/go-delve/delve/cmd/dlv"},
+	{Name: "golangci-lint", Package: "github.com/golangci/golangci-lint/cmd/golangci-lint"},
+	{Name: "staticcheck", Package: "honnef.co/go/tools/cmd/staticcheck"},
+}
+
+// majorSuffix 模块路径末尾的主版本号，例如 /v2
+var majorSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// Parse 解析 name[@version] 或 package[@version]，name 为 Catalog 中的名称
+// 包路径的可执行文件名同 go install，为去掉主版本号后的最后一段
+func Parse(arg string) (Tool, error) {
+	target, version, _ := strings.Cut(arg, "@")
+	if version == "" {
+		version = "latest"
+	}
+	for _, tool := range Catalog {
+		if tool.Name == target || tool.Package == target {
+			tool.Version = version
+			return tool, nil
+		}
+	}
+	if !strings.Contains(target, "/") {
+		names := make([]string, 0, len(Catalog))
+		for _, tool := range Catalog {
+			names = append(names, tool.Name)
+		}
+		return Tool{}, fmt.Errorf("unknown tool %q, use one of %s or a package path such as golang.org/x/tools/cmd/goimports", target, strings.Join(names, ", "))
+	}
+	name := path.Base(target)
+	if majorSuffix.MatchString(name) {
+		name = path.Base(path.Dir(target))
+	}
+	return Tool{Name: name, Package: target, Version: version}, nil
+}
@@ -0,0 +1,38 @@
+package gotools
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParse(t *testing.T) {
+	Convey("内置工具的名称及包路径", t, func() {
+		tool, err := Parse("gopls")
+		So(err, ShouldBeNil)
+		So(tool.Target(), ShouldEqual, "golang.org/x/tools/gopls@latest")
+
+		tool, err = Parse("dlv@v1.22.1")
+		So(err, ShouldBeNil)
+		So(tool.Name, ShouldEqual, "dlv")
+		So(tool.Target(), ShouldEqual, "github.com/go-delve/delve/cmd/dlv@v1.22.1")
+
+		_, err = Parse("goimports")
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("其它包路径使用 go install 的可执行文件名", t, func() {
+		tool, err := Parse("golang.org/x/tools/cmd/goimports@v0.20.0")
+		So(err, ShouldBeNil)
+		So(tool.Name, ShouldEqual, "goimports")
+		So(tool.Version, ShouldEqual, "v0.20.0")
+
+		tool, err = Parse("github.com/golangci/golangci-lint/v2/cmd/golangci-lint")
+		So(err, ShouldBeNil)
+		So(tool.Name, ShouldEqual, "golangci-lint")
+
+		tool, err = Parse("mvdan.cc/gofumpt/v3")
+		So(err, ShouldBeNil)
+		So(tool.Name, ShouldEqual, "gofumpt")
+	})
+}
@@ -0,0 +1,138 @@
+// Package health 检查安装目录是否完整，避免激活解压中断或被误删文件的版本
+package health
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/config"
+)
+
+// Spec 一个语言安装目录的完整性要求
+type Spec struct {
+	// Prefix 安装目录名的前缀，<Prefix><version>
+	Prefix string
+	// Binaries 必须存在且可执行的文件，相对安装目录
+	Binaries []string
+	// VersionFile 记录版本号的文件，为空表示不检查版本
+	VersionFile string
+	// Matches 判断 VersionFile 的内容与目录名中的版本是否一致
+	Matches func(content, version string) bool
+}
+
+// pick windows 上的可执行文件名及位置与其它系统不同
+func pick(unix, windows string) string {
+	if runtime.GOOS == "windows" {
+		return windows
+	}
+	return unix
+}
+
+// Specs 各语言的检查规则，插件等没有规则的语言只检查目录是否存在
+var Specs = map[string]Spec{
+	config.GO: {
+		Prefix:      config.GO,
+		Binaries:    []string{pick("bin/go", "bin/go.exe")},
+		VersionFile: "VERSION",
+		Matches: func(content, version string) bool {
+			return firstLine(content) == "go"+version
+		},
+	},
+	config.JAVA: {
+		Prefix:      "jdk-",
+		Binaries:    []string{pick("bin/java", "bin/java.exe")},
+		VersionFile: "release",
+		Matches: func(content, version string) bool {
+			// 手动解压的目录名可能只写主版本号，例如 jdk-17 对应 17.0.2
+			actual := property(content, "JAVA_VERSION")
+			return actual != "" && (strings.HasPrefix(actual, version) || strings.HasPrefix(version, actual))
+		},
+	},
+	config.NODE:    {Prefix: config.NODE, Binaries: []string{pick("bin/node", "node.exe")}},
+	config.DENO:    {Prefix: config.DENO, Binaries: []string{pick("bin/deno", "bin/deno.exe")}},
+	config.BUN:     {Prefix: config.BUN, Binaries: []string{pick("bin/bun", "bin/bun.exe")}},
+	config.ZIG:     {Prefix: config.ZIG, Binaries: []string{pick("zig", "zig.exe")}},
+	config.MAVEN:   {Prefix: config.MAVEN, Binaries: []string{pick("bin/mvn", "bin/mvn.cmd")}},
+	config.GRADLE:  {Prefix: config.GRADLE, Binaries: []string{pick("bin/gradle", "bin/gradle.bat")}},
+	config.PHP:     {Prefix: config.PHP, Binaries: []string{pick("bin/php", "php.exe")}},
+	config.FLUTTER: {Prefix: config.FLUTTER, Binaries: []string{pick("bin/flutter", "bin/flutter.bat")}},
+}
+
+// BrokenError 安装目录不完整
+type BrokenError struct {
+	Language string
+	Version  string
+	Dir      string
+	Reason   string
+}
+
+func (e *BrokenError) Error() string {
+	hint := fmt.Sprintf("reinstall it with envm %s uninstall %s && envm %s install %s", e.Language, e.Version, e.Language, e.Version)
+	if e.Language == config.JAVA {
+		// jdk 需要手动下载
+		hint = "download and extract it into " + e.Dir + " again"
+	}
+	return fmt.Sprintf("%s %s in %s is broken: %s, %s", e.Language, e.Version, e.Dir, e.Reason, hint)
+}
+
+// Check 检查 dir 是否是 language 的 version 的完整安装，不完整时返回 *BrokenError
+func Check(language, dir, version string) error {
+	broken := func(format string, args ...interface{}) error {
+		return &BrokenError{Language: language, Version: version, Dir: dir, Reason: fmt.Sprintf(format, args...)}
+	}
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		return broken("the directory does not exist")
+	}
+	spec, ok := Specs[language]
+	if !ok {
+		return nil
+	}
+	for _, binary := range spec.Binaries {
+		info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(binary)))
+		if err != nil {
+			return broken("%s is missing", binary)
+		}
+		if runtime.GOOS != "windows" && info.Mode()&0111 == 0 {
+			return broken("%s is not executable", binary)
+		}
+	}
+	// envm link 登记的自定义名称(custom-pgo 等)不是版本号，无法与版本文件比较
+	if spec.VersionFile != "" && version != "" && version[0] >= '0' && version[0] <= '9' {
+		content, err := os.ReadFile(filepath.Join(dir, spec.VersionFile))
+		if err != nil {
+			return broken("%s is missing", spec.VersionFile)
+		}
+		if !spec.Matches(string(content), version) {
+			return broken("%s does not match the version", spec.VersionFile)
+		}
+	}
+	return nil
+}
+
+// CheckDir 根据目录名 <Prefix><version> 检查安装目录，用于只知道目录名的激活流程
+func CheckDir(language, downloads, dirName string) error {
+	version := strings.TrimPrefix(dirName, Specs[language].Prefix)
+	return Check(language, filepath.Join(downloads, dirName), version)
+}
+
+func firstLine(content string) string {
+	line, _, _ := strings.Cut(content, "\n")
+	return strings.TrimSpace(line)
+}
+
+// property 读取 release 文件中 KEY="value" 格式的值
+func property(content, key string) string {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		name, value, ok := strings.Cut(scanner.Text(), "=")
+		if ok && strings.TrimSpace(name) == key {
+			return strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	return ""
+}
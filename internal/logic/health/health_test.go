@@ -0,0 +1,63 @@
+package health
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/FirewineXie/envm/internal/config"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCheck(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("binaries are named differently on windows")
+	}
+	Convey("检查 go 安装目录是否完整", t, func() {
+		downloads := t.TempDir()
+		dir := filepath.Join(downloads, "go1.22.3")
+		So(os.MkdirAll(filepath.Join(dir, "bin"), os.ModePerm), ShouldBeNil)
+
+		err := CheckDir(config.GO, downloads, "go1.22.3")
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "bin/go is missing")
+		So(err.Error(), ShouldContainSubstring, "envm go install 1.22.3")
+
+		So(os.WriteFile(filepath.Join(dir, "bin", "go"), []byte("#!/bin/sh"), 0644), ShouldBeNil)
+		So(Check(config.GO, dir, "1.22.3").Error(), ShouldContainSubstring, "not executable")
+
+		So(os.Chmod(filepath.Join(dir, "bin", "go"), 0755), ShouldBeNil)
+		So(Check(config.GO, dir, "1.22.3").Error(), ShouldContainSubstring, "VERSION is missing")
+
+		So(os.WriteFile(filepath.Join(dir, "VERSION"), []byte("go1.22.2\ntime 2024-04-02T20:22:04Z\n"), 0644), ShouldBeNil)
+		So(Check(config.GO, dir, "1.22.3").Error(), ShouldContainSubstring, "does not match")
+
+		So(os.WriteFile(filepath.Join(dir, "VERSION"), []byte("go1.22.3\ntime 2024-05-01T19:59:14Z\n"), 0644), ShouldBeNil)
+		So(CheckDir(config.GO, downloads, "go1.22.3"), ShouldBeNil)
+	})
+
+	Convey("envm link 登记的自定义名称不与版本文件比较", t, func() {
+		dir := filepath.Join(t.TempDir(), "gocustom-pgo")
+		So(os.MkdirAll(filepath.Join(dir, "bin"), os.ModePerm), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(dir, "bin", "go"), []byte("#!/bin/sh"), 0755), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(dir, "bin", "gofmt"), []byte("#!/bin/sh"), 0755), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(dir, "VERSION"), []byte("devel go1.23-abcdef\n"), 0644), ShouldBeNil)
+		So(Check(config.GO, dir, "custom-pgo"), ShouldBeNil)
+	})
+
+	Convey("jdk 目录名可以只包含主版本号", t, func() {
+		dir := filepath.Join(t.TempDir(), "jdk-17")
+		So(os.MkdirAll(filepath.Join(dir, "bin"), os.ModePerm), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(dir, "bin", "java"), []byte("#!/bin/sh"), 0755), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(dir, "release"), []byte("IMPLEMENTOR=\"Eclipse Adoptium\"\nJAVA_VERSION=\"17.0.2\"\n"), 0644), ShouldBeNil)
+		So(Check(config.JAVA, dir, "17"), ShouldBeNil)
+		So(Check(config.JAVA, dir, "21"), ShouldNotBeNil)
+	})
+
+	Convey("没有规则的语言只检查目录是否存在", t, func() {
+		root := t.TempDir()
+		So(Check("ruby", root, "3.3.0"), ShouldBeNil)
+		So(Check("ruby", filepath.Join(root, "ruby3.3.0"), "3.3.0"), ShouldNotBeNil)
+	})
+}
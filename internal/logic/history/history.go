@@ -0,0 +1,128 @@
+// Package history 安装、卸载、切换版本的审计日志，每行一条 JSON 记录，便于在共享的构建机上追溯变更
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+)
+
+const (
+	ActionInstall   = "install"
+	ActionUninstall = "uninstall"
+	ActionActivate  = "activate"
+)
+
+// Record 一条审计记录，Source/Checksum 只有通过安装包安装时才有
+type Record struct {
+	Time     time.Time `json:"time"`
+	User     string    `json:"user"`
+	Action   string    `json:"action"`
+	Language string    `json:"language"`
+	Version  string    `json:"version"`
+	Source   string    `json:"source,omitempty"`
+	Checksum string    `json:"checksum,omitempty"`
+}
+
+// CurrentUser 当前用户名，获取失败时读取 USER/USERNAME 环境变量
+func CurrentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+}
+
+// Append 追加一条记录到 file，Time/User 为空时使用当前时间及当前用户
+// 每条记录一次 O_APPEND 写入，多个 envm 进程同时追加时不会交错
+func Append(file string, record Record) error {
+	if record.Time.IsZero() {
+		record.Time = time.Now()
+	}
+	if record.User == "" {
+		record.User = CurrentUser()
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Filter 筛选记录的条件，空值表示不限制
+type Filter struct {
+	Language string
+	Action   string
+	Since    time.Time
+}
+
+func (f Filter) match(record Record) bool {
+	return (f.Language == "" || record.Language == f.Language) &&
+		(f.Action == "" || record.Action == f.Action) &&
+		(f.Since.IsZero() || !record.Time.Before(f.Since))
+}
+
+// Read 按写入顺序读取 file 中符合 filter 的记录，文件不存在时返回空
+// 无法解析的行(例如写入时磁盘已满)直接跳过
+func Read(file string, filter Filter) ([]Record, error) {
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record Record
+		if json.Unmarshal(scanner.Bytes(), &record) != nil {
+			continue
+		}
+		if filter.match(record) {
+			records = append(records, record)
+		}
+	}
+	return records, scanner.Err()
+}
+
+// Previous records 中 language 最近一次切换到的、与 current 不同的版本，没有时返回空
+// 用于 envm <lang> active -，像 cd - 一样在两个版本之间来回切换
+func Previous(records []Record, language, current string) string {
+	for i := len(records) - 1; i >= 0; i-- {
+		record := records[i]
+		if record.Action == ActionActivate && record.Language == language && record.Version != current {
+			return record.Version
+		}
+	}
+	return ""
+}
+
+// Latest 每个语言最近一条 action 的记录
+func Latest(records []Record, action string) map[string]Record {
+	latest := map[string]Record{}
+	for _, record := range records {
+		if record.Action == action {
+			latest[record.Language] = record
+		}
+	}
+	return latest
+}
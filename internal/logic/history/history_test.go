@@ -0,0 +1,68 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAppendRead(t *testing.T) {
+	Convey("追加并按条件读取审计记录", t, func() {
+		file := filepath.Join(t.TempDir(), "history.jsonl")
+
+		records, err := Read(file, Filter{})
+		So(err, ShouldBeNil)
+		So(records, ShouldBeEmpty)
+
+		yesterday := time.Now().Add(-24 * time.Hour)
+		So(Append(file, Record{Time: yesterday, User: "ci", Action: ActionInstall, Language: "go", Version: "1.22.3",
+			Source: "https://golang.google.cn/dl/go1.22.3.linux-amd64.tar.gz", Checksum: "8920ea52"}), ShouldBeNil)
+		So(Append(file, Record{Action: ActionActivate, Language: "go", Version: "1.22.3"}), ShouldBeNil)
+		So(Append(file, Record{Action: ActionInstall, Language: "node", Version: "20.12.2"}), ShouldBeNil)
+
+		// 写了一半的行被跳过
+		f, err := os.OpenFile(file, os.O_WRONLY|os.O_APPEND, 0644)
+		So(err, ShouldBeNil)
+		_, _ = f.WriteString(`{"time":"2024`)
+		So(f.Close(), ShouldBeNil)
+
+		records, err = Read(file, Filter{})
+		So(err, ShouldBeNil)
+		So(len(records), ShouldEqual, 3)
+		So(records[0].User, ShouldEqual, "ci")
+		So(records[0].Checksum, ShouldEqual, "8920ea52")
+		So(records[1].User, ShouldEqual, CurrentUser())
+		So(records[1].Time.IsZero(), ShouldBeFalse)
+
+		records, _ = Read(file, Filter{Language: "go"})
+		So(len(records), ShouldEqual, 2)
+		records, _ = Read(file, Filter{Action: ActionInstall})
+		So(len(records), ShouldEqual, 2)
+		records, _ = Read(file, Filter{Since: time.Now().Add(-time.Hour)})
+		So(len(records), ShouldEqual, 2)
+		So(records[0].Action, ShouldEqual, ActionActivate)
+	})
+}
+
+func TestPrevious(t *testing.T) {
+	Convey("最近使用过的其它版本", t, func() {
+		records := []Record{
+			{Action: ActionActivate, Language: "go", Version: "1.21.0"},
+			{Action: ActionInstall, Language: "go", Version: "1.22.3"},
+			{Action: ActionActivate, Language: "go", Version: "1.22.3"},
+			{Action: ActionActivate, Language: "node", Version: "20.12.2"},
+		}
+		So(Previous(records, "go", "1.22.3"), ShouldEqual, "1.21.0")
+		So(Previous(records, "go", "1.21.0"), ShouldEqual, "1.22.3")
+		So(Previous(records, "node", "20.12.2"), ShouldBeEmpty)
+		So(Previous(nil, "go", ""), ShouldBeEmpty)
+
+		latest := Latest(records, ActionActivate)
+		So(latest["go"].Version, ShouldEqual, "1.22.3")
+		So(latest["node"].Version, ShouldEqual, "20.12.2")
+		So(Latest(records, ActionInstall), ShouldHaveLength, 1)
+	})
+}
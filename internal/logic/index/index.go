@@ -0,0 +1,129 @@
+// Package index 缓存各个语言的远程版本列表，install latest 等命令优先使用缓存，避免每次等待网络
+package index
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/blang/semver/v4"
+)
+
+// Index 缓存的远程版本列表
+type Index struct {
+	Versions  []string  `json:"versions"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// filename 每个语言一个缓存文件
+func filename(dir, name string) string {
+	return filepath.Join(dir, name+".json")
+}
+
+// Read 读取缓存，不存在时返回 os.ErrNotExist
+func Read(dir, name string) (*Index, error) {
+	data, err := os.ReadFile(filename(dir, name))
+	if err != nil {
+		return nil, err
+	}
+	idx := &Index{}
+	if err = json.Unmarshal(data, idx); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// Write 写入缓存，先写临时文件再重命名，后台刷新时不会读到一半的内容
+func Write(dir, name string, versions []string) error {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(Index{versions, time.Now()}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filename(dir, name) + ".tmp"
+	if err = os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filename(dir, name))
+}
+
+// Fresh 缓存是否在 ttl 之内
+func (idx *Index) Fresh(ttl time.Duration) bool {
+	return time.Since(idx.FetchedAt) < ttl
+}
+
+// Stale 缓存存在且已经超过 ttl，只根据文件修改时间判断，不读取内容
+// 从未查询过的语言没有缓存，不需要后台刷新
+func Stale(dir, name string, ttl time.Duration) bool {
+	info, err := os.Stat(filename(dir, name))
+	return err == nil && time.Since(info.ModTime()) >= ttl
+}
+
+// Latest 返回最新的正式版本，忽略预发布版本及无法解析的名称(例如 zig 的 master)
+func Latest(versions []string) string {
+	latest, found := "", semver.Version{}
+	for _, name := range versions {
+		v, err := semver.ParseTolerant(strings.TrimPrefix(name, "go"))
+		if err != nil || len(v.Pre) > 0 {
+			continue
+		}
+		if latest == "" || v.GT(found) {
+			latest, found = name, v
+		}
+	}
+	return latest
+}
+
+// Cached 返回 dir 中有缓存的名称，按文件名排序
+func Cached(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), ".json"); ok && entry.Type().IsRegular() {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Prune 删除 dir 中 known 不认识的名称(例如已经删除的插件)、超过 maxAge 没有刷新的缓存，以及中断的写入留下的临时文件
+// 长时间没有刷新说明已经不再使用该语言，不需要继续定时刷新；返回删除的文件名
+func Prune(dir string, known func(name string) bool, maxAge time.Duration) (removed []string, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		age := time.Since(info.ModTime())
+		name, cache := strings.CutSuffix(entry.Name(), ".json")
+		switch {
+		case strings.HasSuffix(entry.Name(), ".tmp"):
+			// 正在进行的写入很快就会重命名
+			if age < time.Minute {
+				continue
+			}
+		case cache && (!known(name) || age >= maxAge):
+		default:
+			continue
+		}
+		if err = os.Remove(filepath.Join(dir, entry.Name())); err != nil && !os.IsNotExist(err) {
+			return removed, err
+		}
+		removed = append(removed, entry.Name())
+	}
+	return removed, nil
+}
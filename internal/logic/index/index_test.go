@@ -0,0 +1,69 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLatest(t *testing.T) {
+	Convey("选择最新的正式版本", t, func() {
+		So(Latest([]string{"1.21.10", "1.22.3", "1.23rc1", "1.22.2"}), ShouldEqual, "1.22.3")
+		So(Latest([]string{"master", "0.12.0", "0.11.0"}), ShouldEqual, "0.12.0")
+		So(Latest([]string{"v20.11.1", "v21.6.2", "v22.0.0-nightly"}), ShouldEqual, "v21.6.2")
+		So(Latest(nil), ShouldEqual, "")
+	})
+}
+
+func TestReadWrite(t *testing.T) {
+	Convey("读写缓存", t, func() {
+		dir := filepath.Join(t.TempDir(), "index")
+		_, err := Read(dir, "go")
+		So(os.IsNotExist(err), ShouldBeTrue)
+		So(Stale(dir, "go", time.Hour), ShouldBeFalse)
+
+		So(Write(dir, "go", []string{"1.22.3", "1.22.2"}), ShouldBeNil)
+		idx, err := Read(dir, "go")
+		So(err, ShouldBeNil)
+		So(idx.Versions, ShouldResemble, []string{"1.22.3", "1.22.2"})
+		So(idx.Fresh(time.Hour), ShouldBeTrue)
+		So(Stale(dir, "go", time.Hour), ShouldBeFalse)
+
+		old := time.Now().Add(-2 * time.Hour)
+		So(os.Chtimes(filepath.Join(dir, "go.json"), old, old), ShouldBeNil)
+		So(Stale(dir, "go", time.Hour), ShouldBeTrue)
+	})
+}
+
+func TestPrune(t *testing.T) {
+	Convey("清理缓存", t, func() {
+		dir := filepath.Join(t.TempDir(), "index")
+		removed, err := Prune(dir, func(string) bool { return true }, time.Hour)
+		So(err, ShouldBeNil)
+		So(removed, ShouldBeEmpty)
+
+		for _, name := range []string{"go", "node", "ruby"} {
+			So(Write(dir, name, []string{"1.0.0"}), ShouldBeNil)
+		}
+		So(os.WriteFile(filepath.Join(dir, "go.json.tmp"), nil, 0644), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(dir, "node.json.tmp"), nil, 0644), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(dir, ".refresh"), nil, 0644), ShouldBeNil)
+		old := time.Now().Add(-2 * time.Hour)
+		So(os.Chtimes(filepath.Join(dir, "node.json"), old, old), ShouldBeNil)
+		So(os.Chtimes(filepath.Join(dir, "go.json.tmp"), old, old), ShouldBeNil)
+		So(Cached(dir), ShouldResemble, []string{"go", "node", "ruby"})
+
+		known := func(name string) bool { return name != "ruby" }
+		removed, err = Prune(dir, known, time.Hour)
+		So(err, ShouldBeNil)
+		So(removed, ShouldResemble, []string{"go.json.tmp", "node.json", "ruby.json"})
+		So(Cached(dir), ShouldResemble, []string{"go"})
+		_, err = os.Stat(filepath.Join(dir, "node.json.tmp"))
+		So(err, ShouldBeNil)
+		_, err = os.Stat(filepath.Join(dir, ".refresh"))
+		So(err, ShouldBeNil)
+	})
+}
@@ -0,0 +1,66 @@
+// Package inuse 找出正在使用某个安装目录的进程，windows 上删除或替换正在运行的 go.exe、java.exe 会失败
+package inuse
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Process 可执行文件位于被检查目录中的进程
+type Process struct {
+	PID  int
+	Name string
+	Path string
+}
+
+func (p Process) String() string {
+	return fmt.Sprintf("%s (pid %d)", p.Name, p.PID)
+}
+
+// Error 目录被进程占用，无法删除或替换
+// 不实现 Unwrap，被占用时的 ERROR_ACCESS_DENIED 不应该触发提权
+type Error struct {
+	Dir       string
+	Processes []Process
+	Err       error
+}
+
+func (e *Error) Error() string {
+	names := make([]string, 0, len(e.Processes))
+	for _, p := range e.Processes {
+		names = append(names, p.String())
+	}
+	message := e.Dir + " is in use"
+	if len(names) > 0 {
+		message += " by " + strings.Join(names, ", ")
+	}
+	message += ", close them and try again"
+	if e.Err != nil {
+		message += " ==> " + e.Err.Error()
+	}
+	return message
+}
+
+// Processes 返回可执行文件位于 dirs 中任一目录下的进程，不支持的系统返回空
+func Processes(dirs ...string) (found []Process) {
+	for _, p := range processes() {
+		for _, dir := range dirs {
+			if dir != "" && within(p.Path, dir) {
+				found = append(found, p)
+				break
+			}
+		}
+	}
+	return found
+}
+
+// within path 是否位于 dir 中，windows 上不区分大小写
+func within(path, dir string) bool {
+	path, dir = filepath.Clean(path), filepath.Clean(dir)
+	if runtime.GOOS == "windows" {
+		path, dir = strings.ToLower(path), strings.ToLower(dir)
+	}
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
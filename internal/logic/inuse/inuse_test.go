@@ -0,0 +1,38 @@
+package inuse
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestProcesses(t *testing.T) {
+	Convey("找出可执行文件位于目录中的进程", t, func() {
+		if runtime.GOOS != "linux" && runtime.GOOS != "windows" {
+			return
+		}
+		executable, err := os.Executable()
+		So(err, ShouldBeNil)
+		found := Processes(filepath.Join(t.TempDir(), "missing"), filepath.Dir(executable))
+		pids := make([]int, 0, len(found))
+		for _, p := range found {
+			pids = append(pids, p.PID)
+		}
+		So(pids, ShouldContain, os.Getpid())
+	})
+
+	Convey("只匹配目录本身及其子路径", t, func() {
+		dir := filepath.Join("opt", "go1.22")
+		So(within(filepath.Join(dir, "bin", "go"), dir), ShouldBeTrue)
+		So(within(filepath.Join("opt", "go1.22.3", "bin", "go"), dir), ShouldBeFalse)
+	})
+
+	Convey("错误信息列出占用的进程", t, func() {
+		err := &Error{Dir: "go1.22.3", Processes: []Process{{PID: 42, Name: "go.exe"}}, Err: errors.New("Access is denied.")}
+		So(err.Error(), ShouldEqual, "go1.22.3 is in use by go.exe (pid 42), close them and try again ==> Access is denied.")
+	})
+}
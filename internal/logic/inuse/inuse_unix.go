@@ -0,0 +1,40 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package inuse
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+)
+
+// Locks 删除正在运行的程序所在的目录是否会失败
+const Locks = false
+
+// Busy 错误是否由文件被占用导致，unix 上只有写入正在运行的可执行文件时会失败
+func Busy(err error) bool {
+	return errors.Is(err, syscall.ETXTBSY)
+}
+
+// processes 通过 /proc/<pid>/exe 获取进程的可执行文件，没有 /proc 的系统返回空
+// unix 上删除正在使用的目录不会失败，这里只用于提示
+func processes() (list []Process) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		path, err := os.Readlink(filepath.Join("/proc", entry.Name(), "exe"))
+		if err != nil {
+			continue
+		}
+		list = append(list, Process{PID: pid, Name: filepath.Base(path), Path: path})
+	}
+	return list
+}
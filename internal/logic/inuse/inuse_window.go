@@ -0,0 +1,61 @@
+//go:build windows
+
+package inuse
+
+import (
+	"errors"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	errorAccessDenied       = syscall.Errno(5)
+	errorSharingViolation   = syscall.Errno(32)
+	errorLockViolation      = syscall.Errno(33)
+	queryLimitedInformation = 0x1000
+)
+
+// Locks 删除正在运行的程序所在的目录是否会失败
+const Locks = true
+
+var procQueryFullProcessImageName = syscall.NewLazyDLL("kernel32.dll").NewProc("QueryFullProcessImageNameW")
+
+// Busy 错误是否可能由文件被占用导致，删除正在运行的 exe 返回 ERROR_ACCESS_DENIED，需要再结合 Processes 判断
+func Busy(err error) bool {
+	return errors.Is(err, errorSharingViolation) || errors.Is(err, errorLockViolation) || errors.Is(err, errorAccessDenied)
+}
+
+// processes 遍历进程快照，通过 QueryFullProcessImageNameW 获取可执行文件的完整路径
+// 没有权限查询的进程(其它用户、系统进程)被跳过
+func processes() (list []Process) {
+	snapshot, err := syscall.CreateToolhelp32Snapshot(syscall.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return nil
+	}
+	defer syscall.CloseHandle(snapshot)
+	var entry syscall.ProcessEntry32
+	entry.Size = uint32(unsafe.Sizeof(entry))
+	for err = syscall.Process32First(snapshot, &entry); err == nil; err = syscall.Process32Next(snapshot, &entry) {
+		path := imagePath(entry.ProcessID)
+		if path == "" {
+			continue
+		}
+		list = append(list, Process{PID: int(entry.ProcessID), Name: syscall.UTF16ToString(entry.ExeFile[:]), Path: path})
+	}
+	return list
+}
+
+func imagePath(pid uint32) string {
+	handle, err := syscall.OpenProcess(queryLimitedInformation, false, pid)
+	if err != nil {
+		return ""
+	}
+	defer syscall.CloseHandle(handle)
+	buf := make([]uint16, syscall.MAX_LONG_PATH)
+	size := uint32(len(buf))
+	ok, _, _ := procQueryFullProcessImageName.Call(uintptr(handle), 0, uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&size)))
+	if ok == 0 {
+		return ""
+	}
+	return syscall.UTF16ToString(buf[:size])
+}
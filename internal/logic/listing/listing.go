@@ -0,0 +1,227 @@
+// Package listing 过滤及排序远程版本列表，各语言的 lsr 及 ls-remote 共用，不需要再通过 grep/head 处理输出
+package listing
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/FirewineXie/envm/util"
+	"github.com/blang/semver/v4"
+)
+
+const (
+	// SortAsc 按版本号从旧到新
+	SortAsc = "asc"
+	// SortDesc 按版本号从新到旧
+	SortDesc = "desc"
+)
+
+// Release 一个远程版本
+type Release struct {
+	Version string
+	// Kind 版本类型，例如 go 的 stable/archived、node 的 lts/current，来源没有分类时为空
+	Kind string
+	// Platforms 提供安装包的平台，GOOS/GOARCH 形式，为空表示与平台无关(例如 maven)或来源没有平台信息
+	Platforms []string
+	// Note 输出在版本后的说明，例如 flutter 的渠道及 dart 版本
+	Note string
+}
+
+// Filter lsr 的过滤条件，字段为空表示不过滤
+type Filter struct {
+	OS    string
+	Arch  string
+	Kind  string
+	Limit int
+	// Sort 为空时保持来源的顺序
+	Sort string
+}
+
+// osNames 各来源使用的操作系统名称
+var osNames = map[string]string{
+	"macos": "darwin",
+	"osx":   "darwin",
+	"mac":   "darwin",
+	"win":   "windows",
+	"sunos": "solaris",
+}
+
+// archNames 各来源使用的硬件架构名称
+var archNames = map[string]string{
+	"x86-64":      "amd64",
+	"x86_64":      "amd64",
+	"x64":         "amd64",
+	"aarch64":     "arm64",
+	"x86":         "386",
+	"i386":        "386",
+	"i686":        "386",
+	"armv6":       "arm",
+	"armv6l":      "arm",
+	"armv7":       "arm",
+	"armv7l":      "arm",
+	"armv7a":      "arm",
+	"powerpc64le": "ppc64le",
+}
+
+// OS 将来源中的操作系统名称(macOS、osx、win)转换为 GOOS
+func OS(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if goos, ok := osNames[name]; ok {
+		return goos
+	}
+	return name
+}
+
+// Arch 将来源中的硬件架构名称(x86-64、x64、aarch64)转换为 GOARCH
+func Arch(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if goarch, ok := archNames[name]; ok {
+		return goarch
+	}
+	return name
+}
+
+// Platform 返回 GOOS/GOARCH 形式的平台
+func Platform(os, arch string) string {
+	return OS(os) + "/" + Arch(arch)
+}
+
+// FromVersions 将只有版本号的列表转换为 Release，例如缓存的版本列表
+func FromVersions(versions []string) []Release {
+	releases := make([]Release, 0, len(versions))
+	for _, version := range versions {
+		releases = append(releases, Release{Version: version})
+	}
+	return releases
+}
+
+// Versions 返回 releases 中的版本号
+func Versions(releases []Release) []string {
+	versions := make([]string, 0, len(releases))
+	for _, r := range releases {
+		versions = append(versions, r.Version)
+	}
+	return versions
+}
+
+// Platforms 返回安装包的平台，去掉重复及没有操作系统的安装包(例如源码包)
+func Platforms(pkgs []*util.Package) []string {
+	seen := make(map[string]bool)
+	platforms := make([]string, 0)
+	for _, pkg := range pkgs {
+		if pkg == nil || pkg.OS == "" || pkg.Arch == "" {
+			continue
+		}
+		platform := Platform(pkg.OS, pkg.Arch)
+		if !seen[platform] {
+			seen[platform] = true
+			platforms = append(platforms, platform)
+		}
+	}
+	return platforms
+}
+
+// Check 检查排序方式，并将 OS、Arch 转换为 GOOS/GOARCH
+func (f *Filter) Check() error {
+	switch f.Sort {
+	case "", SortAsc, SortDesc:
+	default:
+		return fmt.Errorf("unknown sort %q, use %s or %s", f.Sort, SortAsc, SortDesc)
+	}
+	if f.Limit < 0 {
+		return fmt.Errorf("limit must not be negative")
+	}
+	if f.OS != "" {
+		f.OS = OS(f.OS)
+	}
+	if f.Arch != "" {
+		f.Arch = Arch(f.Arch)
+	}
+	f.Kind = strings.ToLower(f.Kind)
+	return nil
+}
+
+// Match 判断版本是否满足 OS、Arch 及 Kind，没有平台信息的版本不按平台过滤
+func (f Filter) Match(r Release) bool {
+	if f.Kind != "" && !strings.EqualFold(r.Kind, f.Kind) {
+		return false
+	}
+	if (f.OS == "" && f.Arch == "") || len(r.Platforms) == 0 {
+		return true
+	}
+	for _, platform := range r.Platforms {
+		goos, goarch, _ := strings.Cut(platform, "/")
+		if (f.OS == "" || goos == f.OS) && (f.Arch == "" || goarch == f.Arch) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply 过滤后排序，最后只保留前 Limit 个，Limit 为 0 时保留全部
+func Apply(releases []Release, f Filter) []Release {
+	matched := make([]Release, 0, len(releases))
+	for _, r := range releases {
+		if f.Match(r) {
+			matched = append(matched, r)
+		}
+	}
+	if f.Sort != "" {
+		sort.SliceStable(matched, func(i, j int) bool {
+			if f.Sort == SortAsc {
+				return Compare(matched[i].Version, matched[j].Version) < 0
+			}
+			return Compare(matched[i].Version, matched[j].Version) > 0
+		})
+	}
+	if f.Limit > 0 && len(matched) > f.Limit {
+		matched = matched[:f.Limit]
+	}
+	return matched
+}
+
+// Kinds 返回列表中出现的版本类型，按出现的顺序
+func Kinds(releases []Release) []string {
+	seen := make(map[string]bool)
+	kinds := make([]string, 0)
+	for _, r := range releases {
+		if r.Kind != "" && !seen[r.Kind] {
+			seen[r.Kind] = true
+			kinds = append(kinds, r.Kind)
+		}
+	}
+	return kinds
+}
+
+// preRelease go 的 1.23rc1、1.21beta1 等预发布版本号中没有 -
+var preRelease = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)*)([a-z]+[0-9]*)$`)
+
+// parse 解析版本号，兼容 go 的预发布版本号，预发布版本的版本号需要补齐三段
+func parse(name string) (semver.Version, error) {
+	name = strings.TrimPrefix(name, "go")
+	if m := preRelease.FindStringSubmatch(name); m != nil {
+		core := m[1]
+		for strings.Count(core, ".") < 2 {
+			core += ".0"
+		}
+		name = core + "-" + m[2]
+	}
+	return semver.ParseTolerant(name)
+}
+
+// Compare 比较两个版本号，无法解析的版本排在可以解析的版本之前，之间按字符串比较
+func Compare(a, b string) int {
+	va, erra := parse(a)
+	vb, errb := parse(b)
+	switch {
+	case erra == nil && errb == nil:
+		return va.Compare(vb)
+	case erra == nil:
+		return 1
+	case errb == nil:
+		return -1
+	}
+	return strings.Compare(a, b)
+}
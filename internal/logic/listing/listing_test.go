@@ -0,0 +1,53 @@
+package listing
+
+import (
+	"testing"
+
+	"github.com/FirewineXie/envm/util"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestApply(t *testing.T) {
+	releases := []Release{
+		{Version: "1.22.3", Kind: "stable", Platforms: []string{"linux/amd64", "darwin/arm64"}},
+		{Version: "1.23rc1", Kind: "unstable", Platforms: []string{"linux/amd64"}},
+		{Version: "1.21.10", Kind: "stable", Platforms: []string{"linux/amd64", "windows/386"}},
+		{Version: "1.9", Kind: "archived"},
+	}
+
+	Convey("按平台及类型过滤，没有平台信息的版本保留", t, func() {
+		f := Filter{OS: "macOS", Arch: "aarch64"}
+		So(f.Check(), ShouldBeNil)
+		So(Versions(Apply(releases, f)), ShouldResemble, []string{"1.22.3", "1.9"})
+
+		f = Filter{Arch: "x86", Kind: "STABLE"}
+		So(f.Check(), ShouldBeNil)
+		So(Versions(Apply(releases, f)), ShouldResemble, []string{"1.21.10"})
+	})
+
+	Convey("排序后再截取", t, func() {
+		So(Versions(Apply(releases, Filter{Sort: SortAsc, Limit: 2})), ShouldResemble, []string{"1.9", "1.21.10"})
+		So(Versions(Apply(releases, Filter{Sort: SortDesc})), ShouldResemble, []string{"1.23rc1", "1.22.3", "1.21.10", "1.9"})
+		So(Versions(Apply(releases, Filter{Limit: 1})), ShouldResemble, []string{"1.22.3"})
+	})
+
+	Convey("不支持的排序方式", t, func() {
+		f := Filter{Sort: "newest"}
+		So(f.Check(), ShouldNotBeNil)
+		So(Kinds(releases), ShouldResemble, []string{"stable", "unstable", "archived"})
+	})
+}
+
+func TestPlatforms(t *testing.T) {
+	Convey("统一各来源的平台名称，忽略源码包", t, func() {
+		pkgs := []*util.Package{
+			{OS: "Linux", Arch: "x86-64"},
+			{OS: "macos", Arch: "aarch64"},
+			{OS: "linux", Arch: "amd64"},
+			{Kind: util.SourceKind},
+		}
+		So(Platforms(pkgs), ShouldResemble, []string{"linux/amd64", "darwin/arm64"})
+		So(Compare("master", "0.1.0"), ShouldBeLessThan, 0)
+		So(Compare("1.21beta1", "1.21.0"), ShouldBeLessThan, 0)
+	})
+}
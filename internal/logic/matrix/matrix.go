@@ -0,0 +1,84 @@
+// Package matrix 展开 envm go test-matrix 的版本列表
+package matrix
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Expand 展开版本列表，每个参数可以用逗号分隔多个版本，去掉重复并保持顺序
+// a..b 展开为两端之间的每个次版本(1.20..1.22 为 1.20、1.21、1.22)，两端只写主版本号时展开每个主版本(18..20)
+func Expand(args []string) ([]string, error) {
+	seen := make(map[string]bool)
+	versions := make([]string, 0)
+	add := func(version string) {
+		if !seen[version] {
+			seen[version] = true
+			versions = append(versions, version)
+		}
+	}
+	for _, arg := range args {
+		for _, item := range strings.Split(arg, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			from, to, ok := strings.Cut(item, "..")
+			if !ok {
+				add(item)
+				continue
+			}
+			expanded, err := expandRange(from, to)
+			if err != nil {
+				return nil, err
+			}
+			for _, version := range expanded {
+				add(version)
+			}
+		}
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no version is given, e.g. 1.21,1.22 or 1.20..1.23")
+	}
+	return versions, nil
+}
+
+// expandRange 展开 from..to，两端的主版本号相同时按次版本号展开，都只有主版本号时按主版本号展开
+func expandRange(from, to string) ([]string, error) {
+	a, erra := segments(from)
+	b, errb := segments(to)
+	if erra != nil || errb != nil || len(a) != len(b) || len(a) > 2 {
+		return nil, fmt.Errorf("invalid range %s..%s, use major.minor..major.minor (1.20..1.23) or major..major (18..22)", from, to)
+	}
+	if len(a) == 2 && a[0] != b[0] {
+		return nil, fmt.Errorf("invalid range %s..%s, both ends must have the same major version", from, to)
+	}
+	last := len(a) - 1
+	if a[last] > b[last] {
+		return nil, fmt.Errorf("invalid range %s..%s, the start is after the end", from, to)
+	}
+	versions := make([]string, 0, b[last]-a[last]+1)
+	for n := a[last]; n <= b[last]; n++ {
+		if len(a) == 2 {
+			versions = append(versions, fmt.Sprintf("%d.%d", a[0], n))
+		} else {
+			versions = append(versions, strconv.Itoa(n))
+		}
+	}
+	return versions, nil
+}
+
+// segments 解析 . 分隔的数字版本号，允许 go 前缀
+func segments(version string) ([]int, error) {
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(version), "go"), ".")
+	numbers := make([]int, 0, len(parts))
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid version %s", version)
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, nil
+}
@@ -0,0 +1,32 @@
+package matrix
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestExpand(t *testing.T) {
+	Convey("展开版本列表及范围", t, func() {
+		versions, err := Expand([]string{"1.21,1.22.3", "1.20..1.23", "1.21"})
+		So(err, ShouldBeNil)
+		So(versions, ShouldResemble, []string{"1.21", "1.22.3", "1.20", "1.22", "1.23"})
+
+		versions, err = Expand([]string{"18..20", "latest"})
+		So(err, ShouldBeNil)
+		So(versions, ShouldResemble, []string{"18", "19", "20", "latest"})
+
+		versions, err = Expand([]string{"go1.21..go1.22"})
+		So(err, ShouldBeNil)
+		So(versions, ShouldResemble, []string{"1.21", "1.22"})
+	})
+
+	Convey("无效的范围", t, func() {
+		for _, arg := range []string{"1.23..1.21", "1.21..2.1", "1.21.1..1.21.5", "1.21..22", "a..b"} {
+			_, err := Expand([]string{arg})
+			So(err, ShouldNotBeNil)
+		}
+		_, err := Expand([]string{" , "})
+		So(err, ShouldNotBeNil)
+	})
+}
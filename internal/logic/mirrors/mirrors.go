@@ -0,0 +1,91 @@
+// Package mirrors 下载镜像的地址替换及测速
+package mirrors
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Rewrite 将 url 中的 official 前缀替换为镜像 mirror，不以 official 开头的 url(例如其它网站的校验文件)不变
+// official 为空表示 url 是相对于下载站点的路径，例如 go 版本列表中的 /dl/go1.22.3.src.tar.gz
+func Rewrite(url, official, mirror string) string {
+	if url == "" || !strings.HasPrefix(url, official) {
+		return url
+	}
+	return strings.TrimSuffix(mirror, "/") + "/" + strings.TrimPrefix(strings.TrimPrefix(url, official), "/")
+}
+
+// Result 一个镜像的测速结果
+type Result struct {
+	Mirror string
+	// Latency 发出请求到收到响应头的时间，包括重定向
+	Latency time.Duration
+	// Throughput 下载响应内容的速度，字节/秒
+	Throughput float64
+	Err        error
+}
+
+// Probe 通过 Range 请求下载 url 的前 size 字节，测量镜像 mirror 的延迟及吞吐量
+// 不支持 Range 的服务端返回完整文件时同样只读取 size 字节
+func Probe(ctx context.Context, client *http.Client, mirror, url string, size int64) Result {
+	result := Result{Mirror: mirror}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", size-1))
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer resp.Body.Close()
+	result.Latency = time.Since(start)
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		result.Err = fmt.Errorf("unexpected status %s", resp.Status)
+		return result
+	}
+	start = time.Now()
+	n, err := io.Copy(io.Discard, io.LimitReader(resp.Body, size))
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if elapsed := time.Since(start); elapsed > 0 {
+		result.Throughput = float64(n) / elapsed.Seconds()
+	}
+	return result
+}
+
+// Sort 按吞吐量从高到低排序，吞吐量相同时延迟低的在前，失败的镜像排在最后并保持原来的顺序
+func Sort(results []Result) {
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if (a.Err == nil) != (b.Err == nil) {
+			return a.Err == nil
+		}
+		if a.Err != nil {
+			return false
+		}
+		if a.Throughput != b.Throughput {
+			return a.Throughput > b.Throughput
+		}
+		return a.Latency < b.Latency
+	})
+}
+
+// Order 排序后的镜像地址
+func Order(results []Result) []string {
+	order := make([]string, 0, len(results))
+	for _, result := range results {
+		order = append(order, result.Mirror)
+	}
+	return order
+}
@@ -0,0 +1,60 @@
+package mirrors
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRewrite(t *testing.T) {
+	Convey("替换官方地址前缀", t, func() {
+		So(Rewrite("/dl/go1.22.3.src.tar.gz", "", "https://go.dev/"), ShouldEqual, "https://go.dev/dl/go1.22.3.src.tar.gz")
+		So(Rewrite("https://nodejs.org/dist/v20.1.0/SHASUMS256.txt", "https://nodejs.org/dist/", "https://npmmirror.com/mirrors/node"),
+			ShouldEqual, "https://npmmirror.com/mirrors/node/v20.1.0/SHASUMS256.txt")
+		So(Rewrite("https://example.com/sums.txt", "https://nodejs.org/dist/", "https://npmmirror.com/mirrors/node/"), ShouldEqual, "https://example.com/sums.txt")
+		So(Rewrite("", "", "https://go.dev"), ShouldEqual, "")
+	})
+}
+
+func TestProbe(t *testing.T) {
+	Convey("只下载前 size 字节", t, func() {
+		var ranges []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ranges = append(ranges, r.Header.Get("Range"))
+			if r.URL.Path == "/missing" {
+				http.NotFound(w, r)
+				return
+			}
+			http.ServeContent(w, r, "file", time.Time{}, strings.NewReader(strings.Repeat("x", 4096)))
+		}))
+		defer server.Close()
+
+		result := Probe(context.Background(), server.Client(), server.URL, server.URL+"/file", 1024)
+		So(result.Err, ShouldBeNil)
+		So(result.Throughput, ShouldBeGreaterThan, 0)
+		So(ranges, ShouldResemble, []string{"bytes=0-1023"})
+
+		result = Probe(context.Background(), server.Client(), server.URL, server.URL+"/missing", 1024)
+		So(result.Err, ShouldNotBeNil)
+	})
+}
+
+func TestSort(t *testing.T) {
+	Convey("快的在前，失败的在最后", t, func() {
+		results := []Result{
+			{Mirror: "failed-a", Err: errors.New("timeout")},
+			{Mirror: "slow", Throughput: 100, Latency: time.Millisecond},
+			{Mirror: "failed-b", Err: errors.New("404")},
+			{Mirror: "fast", Throughput: 1000, Latency: time.Second},
+			{Mirror: "near", Throughput: 1000, Latency: time.Millisecond},
+		}
+		Sort(results)
+		So(Order(results), ShouldResemble, []string{"near", "fast", "slow", "failed-a", "failed-b"})
+	})
+}
@@ -0,0 +1,160 @@
+// Package netpolicy 限制 envm 可以访问的域名，用于只允许访问指定站点及内部镜像的受监管环境
+package netpolicy
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Policy 域名规则，example.com 只匹配该域名，*.example.com 匹配所有子域名
+// Deny 优先；Allow 不为空时只能访问其中的域名，为空时只拒绝 Deny 中的域名
+type Policy struct {
+	Allow []string
+	Deny  []string
+}
+
+// Enabled 是否配置了任何规则
+func (p Policy) Enabled() bool {
+	return len(p.Allow) > 0 || len(p.Deny) > 0
+}
+
+// Match 判断 host 是否匹配规则 pattern，不区分大小写
+func Match(pattern, host string) bool {
+	pattern, host = strings.ToLower(pattern), strings.ToLower(strings.TrimSuffix(host, "."))
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(host, "."+suffix)
+	}
+	return host == pattern
+}
+
+// DeniedError 访问了规则不允许的域名
+type DeniedError struct {
+	Host string
+	// Rule 匹配的 Deny 规则，为空表示不在 Allow 中
+	Rule string
+}
+
+func (e *DeniedError) Error() string {
+	if e.Rule != "" {
+		return fmt.Sprintf("network policy: %s is denied by %q", e.Host, e.Rule)
+	}
+	return fmt.Sprintf("network policy: %s is not in the allowed domains", e.Host)
+}
+
+// Check 检查是否可以访问 host
+func (p Policy) Check(host string) error {
+	for _, rule := range p.Deny {
+		if Match(rule, host) {
+			return &DeniedError{Host: host, Rule: rule}
+		}
+	}
+	if len(p.Allow) == 0 {
+		return nil
+	}
+	for _, rule := range p.Allow {
+		if Match(rule, host) {
+			return nil
+		}
+	}
+	return &DeniedError{Host: host}
+}
+
+// Resolve 合并全局规则及 commands 中与 args 匹配的命令的规则，键为空格分隔的命令，例如 "go install"
+// 多个键匹配时使用最长的一个，命令的规则追加到全局规则之后
+func Resolve(global Policy, commands map[string]Policy, args []string) Policy {
+	keys := make([]string, 0, len(commands))
+	for key := range commands {
+		keys = append(keys, key)
+	}
+	// 先比较段数，相同时按字母顺序，保证结果稳定
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := strings.Fields(keys[i]), strings.Fields(keys[j])
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return keys[i] < keys[j]
+	})
+	for _, key := range keys {
+		fields := strings.Fields(key)
+		if len(fields) == 0 || len(fields) > len(args) {
+			continue
+		}
+		matched := true
+		for i, field := range fields {
+			if args[i] != field {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			rule := commands[key]
+			return Policy{
+				Allow: append(append([]string{}, global.Allow...), rule.Allow...),
+				Deny:  append(append([]string{}, global.Deny...), rule.Deny...),
+			}
+		}
+	}
+	return global
+}
+
+// GitHost 返回 git 仓库地址中的域名，支持 https://host/repo 及 git@host:repo，本地路径返回空字符串
+func GitHost(repo string) string {
+	if u, err := url.Parse(repo); err == nil && u.Host != "" {
+		return u.Hostname()
+	}
+	// scp 形式 [user@]host:path，Windows 盘符(C:\repo)不是域名
+	if i := strings.Index(repo, ":"); i > 1 && !strings.ContainsAny(repo[:i], `/\`) {
+		host := repo[:i]
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			host = host[at+1:]
+		}
+		return host
+	}
+	return ""
+}
+
+var (
+	mu     sync.RWMutex
+	active Policy
+	once   sync.Once
+)
+
+// Install 使用 p 检查之后所有经过 http.DefaultTransport 及 Wrap 的请求，包括重定向
+func Install(p Policy) {
+	mu.Lock()
+	active = p
+	mu.Unlock()
+	once.Do(func() {
+		http.DefaultTransport = Wrap(http.DefaultTransport)
+	})
+}
+
+// Active 当前生效的规则
+func Active() Policy {
+	mu.RLock()
+	defer mu.RUnlock()
+	return active
+}
+
+// Wrap 在发出请求前按当前生效的规则检查域名，用于自定义 Transport 的 http.Client
+func Wrap(base http.RoundTripper) http.RoundTripper {
+	return transport{base: base}
+}
+
+type transport struct {
+	base http.RoundTripper
+}
+
+func (t transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := Active().Check(req.URL.Hostname()); err != nil {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,76 @@
+package netpolicy
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCheck(t *testing.T) {
+	Convey("deny 优先，allow 不为空时只允许其中的域名", t, func() {
+		p := Policy{Allow: []string{"golang.google.cn", "*.nodejs.org"}, Deny: []string{"*.evil.nodejs.org"}}
+		So(p.Check("golang.google.cn"), ShouldBeNil)
+		So(p.Check("GOLANG.google.cn."), ShouldBeNil)
+		So(p.Check("dl.nodejs.org"), ShouldBeNil)
+		So(p.Check("nodejs.org"), ShouldNotBeNil)
+		So(p.Check("google.cn"), ShouldNotBeNil)
+
+		var denied *DeniedError
+		So(errors.As(p.Check("x.evil.nodejs.org"), &denied), ShouldBeTrue)
+		So(denied.Rule, ShouldEqual, "*.evil.nodejs.org")
+
+		So(Policy{}.Check("anything.example"), ShouldBeNil)
+		So(Policy{Deny: []string{"api.github.com"}}.Check("api.github.com"), ShouldNotBeNil)
+	})
+}
+
+func TestGitHost(t *testing.T) {
+	Convey("git 仓库地址中的域名", t, func() {
+		So(GitHost("https://github.com/envm/plugin-ruby.git"), ShouldEqual, "github.com")
+		So(GitHost("ssh://git@git.corp.example:2222/envm/ruby.git"), ShouldEqual, "git.corp.example")
+		So(GitHost("git@github.com:envm/plugin-ruby.git"), ShouldEqual, "github.com")
+		So(GitHost("/srv/plugins/ruby"), ShouldEqual, "")
+		So(GitHost(`C:\plugins\ruby`), ShouldEqual, "")
+	})
+}
+
+func TestResolve(t *testing.T) {
+	Convey("使用匹配的最长的命令规则", t, func() {
+		global := Policy{Allow: []string{"golang.google.cn"}}
+		commands := map[string]Policy{
+			"go":         {Allow: []string{"go.dev"}},
+			"go install": {Allow: []string{"dl.google.com"}},
+		}
+		So(Resolve(global, commands, []string{"go", "install", "1.22"}).Allow, ShouldResemble, []string{"golang.google.cn", "dl.google.com"})
+		So(Resolve(global, commands, []string{"go", "lsr"}).Allow, ShouldResemble, []string{"golang.google.cn", "go.dev"})
+		So(Resolve(global, commands, []string{"node", "install"}), ShouldResemble, global)
+		So(global.Allow, ShouldResemble, []string{"golang.google.cn"})
+	})
+}
+
+func TestWrap(t *testing.T) {
+	Convey("拒绝的请求不会发出", t, func() {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+		}))
+		defer server.Close()
+		defer Install(Policy{})
+
+		client := &http.Client{Transport: Wrap(http.DefaultTransport)}
+		Install(Policy{Allow: []string{"example.com"}})
+		_, err := client.Get(server.URL)
+		var denied *DeniedError
+		So(errors.As(err, &denied), ShouldBeTrue)
+		So(requests, ShouldEqual, 0)
+
+		Install(Policy{Allow: []string{"127.0.0.1"}})
+		resp, err := http.Get(server.URL)
+		So(err, ShouldBeNil)
+		resp.Body.Close()
+		So(requests, ShouldEqual, 1)
+	})
+}
@@ -0,0 +1,101 @@
+// Package normalize 统一 install/active/uninstall 接受的版本号写法，例如 go1.22.3、v20.12.2、jdk-17、1.22
+package normalize
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/blang/semver/v4"
+)
+
+// Keywords 由各语言自己解析的版本关键字，不做规范化
+var Keywords = map[string]bool{"latest": true, "lts": true, "stable": true, "beta": true, "master": true}
+
+// prefixes 各语言版本号常见的前缀，所有语言都可以使用 v 前缀
+var prefixes = map[string][]string{
+	"go":     {"go"},
+	"java":   {"jdk-", "jdk"},
+	"node":   {"node-", "node"},
+	"zig":    {"zig-"},
+	"mvn":    {"apache-maven-", "maven-"},
+	"gradle": {"gradle-"},
+	"php":    {"php-"},
+}
+
+// valid 数字开头，点分隔的数字之后可以跟 rc1、-beta.1、+9、-dev.3180+83e578a18 等后缀
+var valid = regexp.MustCompile(`^[0-9]+(\.[0-9]+)*([a-zA-Z+_-][0-9A-Za-z.+_-]*)?$`)
+
+// InvalidError 版本号格式错误
+type InvalidError struct {
+	Input      string
+	Suggestion string
+}
+
+func (e *InvalidError) Error() string {
+	message := fmt.Sprintf("invalid version %q", e.Input)
+	if e.Suggestion != "" {
+		message += fmt.Sprintf(", did you mean %s?", e.Suggestion)
+	}
+	return message
+}
+
+// Clean 去掉 language 的前缀及 v 前缀并校验格式，格式错误时返回带有建议的 *InvalidError
+func Clean(language, input string) (string, error) {
+	version := strings.TrimSpace(input)
+	lower := strings.ToLower(version)
+	for _, prefix := range prefixes[language] {
+		if strings.HasPrefix(lower, prefix) {
+			version, lower = version[len(prefix):], lower[len(prefix):]
+			break
+		}
+	}
+	if strings.HasPrefix(lower, "v") {
+		version = version[1:]
+	}
+	if valid.MatchString(version) {
+		return version, nil
+	}
+	err := &InvalidError{Input: input}
+	if suggestion := suggest(version); suggestion != "" && valid.MatchString(suggestion) {
+		err.Suggestion = suggestion
+	}
+	return "", err
+}
+
+// suggest 修正常见的笔误: 逗号、空格、连续或结尾的点、x 通配符
+func suggest(version string) string {
+	version = strings.NewReplacer(",", ".", " ", "", "\t", "").Replace(version)
+	for strings.Contains(version, "..") {
+		version = strings.ReplaceAll(version, "..", ".")
+	}
+	version = strings.TrimSuffix(version, ".x")
+	version = strings.TrimSuffix(version, ".*")
+	return strings.Trim(version, ".")
+}
+
+// Resolve 在 versions 中查找 version，没有完全一致的版本时选择以 version 开头的最新版本
+// 例如 1.22 解析为 1.22.3，17 解析为 17.0.9+9，优先选择正式版本，找不到时返回 false
+func Resolve(version string, versions []string) (string, bool) {
+	var matched []string
+	for _, name := range versions {
+		if name == version {
+			return name, true
+		}
+		if strings.HasPrefix(name, version+".") || strings.HasPrefix(name, version+"+") {
+			matched = append(matched, name)
+		}
+	}
+	best, bestVersion, bestStable := "", semver.Version{}, false
+	for _, name := range matched {
+		v, err := semver.ParseTolerant(name)
+		if err != nil {
+			continue
+		}
+		stable := len(v.Pre) == 0
+		if best == "" || (stable && !bestStable) || (stable == bestStable && v.GT(bestVersion)) {
+			best, bestVersion, bestStable = name, v, stable
+		}
+	}
+	return best, best != ""
+}
@@ -0,0 +1,57 @@
+package normalize
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestClean(t *testing.T) {
+	Convey("去掉语言前缀及 v 前缀", t, func() {
+		cases := map[string][2]string{
+			"go1.22.3":                  {"go", "1.22.3"},
+			"1.22":                      {"go", "1.22"},
+			"1.23rc1":                   {"go", "1.23rc1"},
+			"v20.12.2":                  {"node", "20.12.2"},
+			"node-v20.12":               {"node", "20.12"},
+			"jdk-17.0.9+9":              {"java", "17.0.9+9"},
+			"17.0.9+9":                  {"java", "17.0.9+9"},
+			" 3.9.6 ":                   {"mvn", "3.9.6"},
+			"0.12.0-dev.3180+83e578a18": {"zig", "0.12.0-dev.3180+83e578a18"},
+		}
+		for input, c := range cases {
+			version, err := Clean(c[0], input)
+			So(err, ShouldBeNil)
+			So(version, ShouldEqual, c[1])
+		}
+	})
+
+	Convey("格式错误时给出建议", t, func() {
+		_, err := Clean("go", "1,22")
+		So(err, ShouldResemble, &InvalidError{Input: "1,22", Suggestion: "1.22"})
+		_, err = Clean("node", "20..1")
+		So(err.Error(), ShouldEqual, `invalid version "20..1", did you mean 20.1?`)
+		_, err = Clean("node", "20.x")
+		So(err.(*InvalidError).Suggestion, ShouldEqual, "20")
+		_, err = Clean("go", "hello")
+		So(err, ShouldResemble, &InvalidError{Input: "hello"})
+	})
+}
+
+func TestResolve(t *testing.T) {
+	Convey("部分版本号选择最新的匹配版本", t, func() {
+		versions := []string{"1.21.10", "1.22.3", "1.22.2", "1.23rc1", "1.20"}
+		version, ok := Resolve("1.22", versions)
+		So(ok, ShouldBeTrue)
+		So(version, ShouldEqual, "1.22.3")
+		version, _ = Resolve("1.20", versions)
+		So(version, ShouldEqual, "1.20")
+		_, ok = Resolve("1.19", versions)
+		So(ok, ShouldBeFalse)
+
+		version, _ = Resolve("17", []string{"17.0.9+9", "17.0.10+7", "21.0.2+13"})
+		So(version, ShouldEqual, "17.0.10+7")
+		version, _ = Resolve("8", []string{"8.8-rc-1", "8.7"})
+		So(version, ShouldEqual, "8.7")
+	})
+}
@@ -0,0 +1,90 @@
+// Package notify 根据缓存的远程版本列表提示已激活的版本有更新，不访问网络
+package notify
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/blang/semver/v4"
+)
+
+// Interval 同一个语言两次提示之间的最短间隔
+const Interval = 24 * time.Hour
+
+func parse(name string) (semver.Version, bool) {
+	v, err := semver.ParseTolerant(strings.TrimPrefix(name, "go"))
+	return v, err == nil && len(v.Pre) == 0
+}
+
+// Newer 返回比 current 更新的正式版本，优先返回同一个 major.minor 下最新的补丁版本(patch 为 true)
+// 没有补丁版本时返回最新的版本，都没有或 current 无法解析时返回空
+func Newer(current string, versions []string) (version string, patch bool) {
+	cur, ok := parse(current)
+	if !ok {
+		return "", false
+	}
+	var latest, latestPatch string
+	var found, foundPatch semver.Version
+	for _, name := range versions {
+		v, ok := parse(name)
+		if !ok || !v.GT(cur) {
+			continue
+		}
+		if v.Major == cur.Major && v.Minor == cur.Minor && (latestPatch == "" || v.GT(foundPatch)) {
+			latestPatch, foundPatch = name, v
+		}
+		if latest == "" || v.GT(found) {
+			latest, found = name, v
+		}
+	}
+	if latestPatch != "" {
+		return latestPatch, true
+	}
+	return latest, false
+}
+
+func load(file string) map[string]time.Time {
+	shown := map[string]time.Time{}
+	if data, err := os.ReadFile(file); err == nil {
+		_ = json.Unmarshal(data, &shown)
+	}
+	return shown
+}
+
+// Due 返回 keys 中距离上一次提示超过 Interval 的项
+func Due(file string, keys []string) (due []string) {
+	shown := load(file)
+	for _, key := range keys {
+		if time.Since(shown[key]) >= Interval {
+			due = append(due, key)
+		}
+	}
+	return due
+}
+
+// Mark 记录 keys 刚刚提示过
+func Mark(file string, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	shown := load(file)
+	now := time.Now()
+	for _, key := range keys {
+		shown[key] = now
+	}
+	data, err := json.MarshalIndent(shown, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	if err = os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
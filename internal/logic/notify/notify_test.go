@@ -0,0 +1,36 @@
+package notify
+
+import (
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNewer(t *testing.T) {
+	Convey("查找更新的版本", t, func() {
+		versions := []string{"1.23.1", "1.23rc1", "1.22.6", "1.22.5", "1.22.3", "1.21.13"}
+
+		version, patch := Newer("1.22.3", versions)
+		So(version, ShouldEqual, "1.22.6")
+		So(patch, ShouldBeTrue)
+
+		version, patch = Newer("1.22.6", versions)
+		So(version, ShouldEqual, "1.23.1")
+		So(patch, ShouldBeFalse)
+
+		version, _ = Newer("1.23.1", versions)
+		So(version, ShouldBeEmpty)
+		version, _ = Newer("master", versions)
+		So(version, ShouldBeEmpty)
+	})
+}
+
+func TestDue(t *testing.T) {
+	Convey("每个语言每天最多提示一次", t, func() {
+		file := filepath.Join(t.TempDir(), "cache", "notify.json")
+		So(Due(file, []string{"go", "node"}), ShouldResemble, []string{"go", "node"})
+		So(Mark(file, []string{"go"}), ShouldBeNil)
+		So(Due(file, []string{"go", "node"}), ShouldResemble, []string{"node"})
+	})
+}
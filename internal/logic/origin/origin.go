@@ -0,0 +1,38 @@
+// Package origin 判断链接到 envm 安装目录中的外部版本来自哪里，例如系统 JDK、nvm
+package origin
+
+import "strings"
+
+// rules 按路径片段识别来源，先匹配的优先
+var rules = []struct {
+	fragment string
+	label    string
+}{
+	{"/.nvm/", "nvm"},
+	{"/.sdkman/", "sdkman"},
+	{"/.asdf/", "asdf"},
+	{"/.local/share/mise/", "mise"},
+	{"/.volta/", "volta"},
+	{"/.gvm/", "gvm"},
+	{"/.jdks/", "IntelliJ JDK"},
+	{"/usr/lib/jvm/", "system JDK"},
+	{"/Library/Java/JavaVirtualMachines/", "system JDK"},
+	{"/Program Files/Java/", "system JDK"},
+	{"/Program Files/Eclipse Adoptium/", "system JDK"},
+	{"/usr/local/go/", "system Go"},
+	{"/Program Files/Go/", "system Go"},
+	{"/opt/homebrew/", "homebrew"},
+	{"/usr/local/Cellar/", "homebrew"},
+}
+
+// Label 返回外部目录 target 的来源，无法识别时返回 external
+func Label(target string) string {
+	// windows 的路径在其它系统上也按 / 比较
+	path := strings.ReplaceAll(target, `\`, "/") + "/"
+	for _, rule := range rules {
+		if strings.Contains(path, rule.fragment) {
+			return rule.label
+		}
+	}
+	return "external"
+}
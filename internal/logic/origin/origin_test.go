@@ -0,0 +1,18 @@
+package origin
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLabel(t *testing.T) {
+	Convey("识别外部版本的来源", t, func() {
+		So(Label("/home/me/.nvm/versions/node/v20.12.2"), ShouldEqual, "nvm")
+		So(Label("/usr/lib/jvm/java-17-openjdk-amd64"), ShouldEqual, "system JDK")
+		So(Label("/Library/Java/JavaVirtualMachines/temurin-21.jdk/Contents/Home"), ShouldEqual, "system JDK")
+		So(Label(`C:\Program Files\Java\jdk-17`), ShouldEqual, "system JDK")
+		So(Label("/usr/local/go"), ShouldEqual, "system Go")
+		So(Label("/data/toolchains/go1.22.3"), ShouldEqual, "external")
+	})
+}
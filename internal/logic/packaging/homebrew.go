@@ -0,0 +1,47 @@
+package packaging
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Homebrew 生成 homebrew tap 中的 Formula/envm.rb，只包含 macOS 及 linux 的发布文件
+func Homebrew(p Package) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# generated by envm release homebrew, do not edit\n")
+	fmt.Fprintf(&b, "class Envm < Formula\n")
+	fmt.Fprintf(&b, "  desc %q\n", p.Description)
+	fmt.Fprintf(&b, "  homepage %q\n", Homepage)
+	fmt.Fprintf(&b, "  version %q\n", p.Version)
+	fmt.Fprintf(&b, "  license %q\n", License)
+	for _, system := range []struct{ os, block string }{{"darwin", "on_macos"}, {"linux", "on_linux"}} {
+		blocks := make([]string, 0, 2)
+		for _, arch := range []struct{ arch, block string }{{"amd64", "on_intel"}, {"arm64", "on_arm"}} {
+			if artifact, ok := p.Find(Target{system.os, arch.arch}); ok {
+				blocks = append(blocks, fmt.Sprintf("    %s do\n      url %q\n      sha256 %q\n    end\n", arch.block, artifact.URL, artifact.SHA256))
+			}
+		}
+		if len(blocks) > 0 {
+			fmt.Fprintf(&b, "\n  %s do\n%s  end\n", system.block, strings.Join(blocks, "\n"))
+		}
+	}
+	// 压缩包解压后为 envm，可执行文件本身以附件名称下载
+	b.WriteString(`
+  def install
+    bin.install Dir["envm*"].first => "envm"
+  end
+
+  def caveats
+    <<~EOS
+      envm keeps the installed versions in ENVM_HOME, add it to your shell profile:
+        export ENVM_HOME="$HOME/.envm"
+    EOS
+  end
+
+  test do
+    assert_match version.to_s, shell_output("#{bin}/envm --version")
+  end
+end
+`)
+	return b.String()
+}
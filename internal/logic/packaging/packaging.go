@@ -0,0 +1,151 @@
+// Package packaging 根据 release 中的附件及校验文件生成 homebrew formula、scoop manifest 及 winget manifest
+// 每次发布后重新生成，包管理器中的地址及校验和与 release 保持一致
+package packaging
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	web_github "github.com/FirewineXie/envm/internal/logic/web-github"
+)
+
+const (
+	// Name 包名及可执行文件名
+	Name = "envm"
+	// Publisher 发布者，也是 winget 包标识的前缀
+	Publisher = "FirewineXie"
+	// Homepage 项目主页
+	Homepage = "https://github.com/FirewineXie/envm"
+	// License 与 LICENSE 文件一致的 SPDX 标识
+	License = "Apache-2.0"
+)
+
+// Target 发布的平台
+type Target struct {
+	OS   string
+	Arch string
+}
+
+// Targets 生成到包管理器中的平台，release 中没有的平台被跳过
+var Targets = []Target{
+	{"darwin", "amd64"}, {"darwin", "arm64"},
+	{"linux", "amd64"}, {"linux", "arm64"},
+	{"windows", "amd64"}, {"windows", "arm64"},
+}
+
+// aliases 附件名称中系统及架构的其它写法
+var aliases = map[string]string{"macos": "darwin", "x86_64": "amd64", "x64": "amd64", "aarch64": "arm64"}
+
+// Artifact 某个平台的发布文件
+type Artifact struct {
+	Target
+	Name   string
+	URL    string
+	SHA256 string
+	// Archive 是否为压缩包(tar.gz/zip)，否则为可执行文件本身
+	Archive bool
+}
+
+// Package 生成各个包管理器文件需要的信息
+type Package struct {
+	// Version 不带 v 前缀的版本号
+	Version     string
+	Description string
+	// ChecksumURL release 中校验文件的地址，scoop 自动更新时使用
+	ChecksumURL string
+	Artifacts   []Artifact
+}
+
+// Find 返回 target 的发布文件
+func (p Package) Find(target Target) (Artifact, bool) {
+	for _, artifact := range p.Artifacts {
+		if artifact.Target == target {
+			return artifact, true
+		}
+	}
+	return Artifact{}, false
+}
+
+// target 从附件名称识别平台，例如 envm_1.0.2_linux_x86_64.tar.gz，不是可执行文件或压缩包时返回 false
+func target(name string) (Target, bool, bool) {
+	lower := strings.ToLower(name)
+	archive := strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz") || strings.HasSuffix(lower, ".zip")
+	if ext := lower[strings.LastIndex(lower, ".")+1:]; !archive && ext != "exe" && ext != lower && isWord(ext) {
+		// 校验文件、签名、sbom、deb 等，没有扩展名的可执行文件中的 . 来自版本号
+		return Target{}, false, false
+	}
+	lower = strings.ReplaceAll(lower, "x86_64", "amd64")
+	var t Target
+	for _, token := range strings.FieldsFunc(lower, func(r rune) bool { return r == '_' || r == '-' || r == '.' }) {
+		if alias, ok := aliases[token]; ok {
+			token = alias
+		}
+		for _, candidate := range Targets {
+			if token == candidate.OS {
+				t.OS = token
+			}
+			if token == candidate.Arch {
+				t.Arch = token
+			}
+		}
+	}
+	return t, archive, t.OS != "" && t.Arch != ""
+}
+
+// Artifacts 从 release 的附件中找出各个平台的发布文件，校验和来自 "<checksum>  <filename>" 格式的 checksums
+// 同一平台有多个文件时优先使用压缩包，平台的文件没有校验和时返回错误
+func Artifacts(assets []*web_github.Asset, checksums []byte) ([]Artifact, error) {
+	sums := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) >= 2 {
+			sums[strings.TrimPrefix(fields[len(fields)-1], "*")] = strings.ToLower(fields[0])
+		}
+	}
+	found := map[Target]Artifact{}
+	for _, asset := range assets {
+		t, archive, ok := target(asset.Name)
+		if !ok {
+			continue
+		}
+		if existing, ok := found[t]; ok && existing.Archive {
+			continue
+		}
+		sum, ok := sums[asset.Name]
+		if !ok {
+			return nil, fmt.Errorf("%s has no checksum in the published checksums", asset.Name)
+		}
+		found[t] = Artifact{Target: t, Name: asset.Name, URL: asset.URL, SHA256: sum, Archive: archive}
+	}
+	artifacts := make([]Artifact, 0, len(found))
+	for _, t := range Targets {
+		if artifact, ok := found[t]; ok {
+			artifacts = append(artifacts, artifact)
+		}
+	}
+	if len(artifacts) == 0 {
+		return nil, errors.New("the release publishes no binary for " + strings.Join(targetNames(), ", "))
+	}
+	return artifacts, nil
+}
+
+// isWord s 是否只包含小写字母及数字
+func isWord(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func targetNames() []string {
+	names := make([]string, len(Targets))
+	for i, t := range Targets {
+		names[i] = t.OS + "/" + t.Arch
+	}
+	return names
+}
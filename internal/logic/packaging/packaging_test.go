@@ -0,0 +1,83 @@
+package packaging
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	web_github "github.com/FirewineXie/envm/internal/logic/web-github"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const base = "https://github.com/FirewineXie/envm/releases/download/v1.0.2/"
+
+func release() []*web_github.Asset {
+	names := []string{"envm_1.0.2_darwin_arm64.tar.gz", "envm_1.0.2_linux_x86_64.tar.gz", "envm_1.0.2_linux_amd64",
+		"envm_1.0.2_windows_amd64.exe", "envm_1.0.2_windows_arm64.zip", "checksums.txt", "envm_1.0.2_linux_amd64.sbom.json"}
+	assets := make([]*web_github.Asset, len(names))
+	for i, name := range names {
+		assets[i] = &web_github.Asset{Name: name, URL: base + name}
+	}
+	return assets
+}
+
+const checksums = "aa11  envm_1.0.2_darwin_arm64.tar.gz\nbb22  envm_1.0.2_linux_x86_64.tar.gz\ncc33 *envm_1.0.2_linux_amd64\n" +
+	"dd44  envm_1.0.2_windows_amd64.exe\nee55  envm_1.0.2_windows_arm64.zip\n"
+
+func TestArtifacts(t *testing.T) {
+	Convey("从 release 附件中识别各平台的发布文件", t, func() {
+		artifacts, err := Artifacts(release(), []byte(checksums))
+		So(err, ShouldBeNil)
+		So(artifacts, ShouldHaveLength, 4)
+		So(artifacts[0], ShouldResemble, Artifact{Target: Target{"darwin", "arm64"}, Name: "envm_1.0.2_darwin_arm64.tar.gz",
+			URL: base + "envm_1.0.2_darwin_arm64.tar.gz", SHA256: "aa11", Archive: true})
+		// 同一平台优先使用压缩包
+		So(artifacts[1].Name, ShouldEqual, "envm_1.0.2_linux_x86_64.tar.gz")
+		So(artifacts[2].Archive, ShouldBeFalse)
+		So(artifacts[3].Target, ShouldResemble, Target{"windows", "arm64"})
+
+		_, err = Artifacts(release(), []byte("aa11  envm_1.0.2_darwin_arm64.tar.gz\n"))
+		So(err, ShouldNotBeNil)
+		_, err = Artifacts([]*web_github.Asset{{Name: "checksums.txt"}}, nil)
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestManifests(t *testing.T) {
+	artifacts, _ := Artifacts(release(), []byte(checksums))
+	p := Package{Version: "1.0.2", Description: "Any More Version Manager", ChecksumURL: base + "checksums.txt", Artifacts: artifacts}
+
+	Convey("homebrew formula", t, func() {
+		formula := Homebrew(p)
+		So(formula, ShouldContainSubstring, "  version \"1.0.2\"\n")
+		So(formula, ShouldContainSubstring, "  on_macos do\n    on_arm do\n      url \""+base+"envm_1.0.2_darwin_arm64.tar.gz\"\n      sha256 \"aa11\"\n    end\n  end\n")
+		So(formula, ShouldContainSubstring, "  on_linux do\n    on_intel do\n")
+		So(formula, ShouldNotContainSubstring, "windows")
+	})
+
+	Convey("scoop manifest", t, func() {
+		data, err := Scoop(p)
+		So(err, ShouldBeNil)
+		var manifest map[string]interface{}
+		So(json.Unmarshal(data, &manifest), ShouldBeNil)
+		architecture := manifest["architecture"].(map[string]interface{})
+		So(architecture["64bit"], ShouldResemble, map[string]interface{}{"url": base + "envm_1.0.2_windows_amd64.exe#/envm.exe", "hash": "dd44", "bin": "envm.exe"})
+		So(architecture["arm64"].(map[string]interface{})["url"], ShouldEqual, base+"envm_1.0.2_windows_arm64.zip")
+		autoupdate := manifest["autoupdate"].(map[string]interface{})
+		So(autoupdate["architecture"].(map[string]interface{})["64bit"].(map[string]interface{})["url"], ShouldEqual,
+			"https://github.com/FirewineXie/envm/releases/download/v$version/envm_$version_windows_amd64.exe#/envm.exe")
+		So(autoupdate["hash"].(map[string]interface{})["url"], ShouldEqual, "https://github.com/FirewineXie/envm/releases/download/v$version/checksums.txt")
+	})
+
+	Convey("winget manifests", t, func() {
+		files := Winget(p)
+		So(files, ShouldHaveLength, 3)
+		installer := files["FirewineXie.envm.installer.yaml"]
+		So(installer, ShouldContainSubstring, "- Architecture: x64\n  InstallerType: portable\n  InstallerUrl: "+base+"envm_1.0.2_windows_amd64.exe\n  InstallerSha256: DD44\n")
+		So(installer, ShouldContainSubstring, "  NestedInstallerType: portable\n")
+		So(strings.HasSuffix(installer, "ManifestType: installer\nManifestVersion: 1.6.0\n"), ShouldBeTrue)
+		So(files["FirewineXie.envm.yaml"], ShouldContainSubstring, "DefaultLocale: en-US\n")
+		So(files["FirewineXie.envm.locale.en-US.yaml"], ShouldContainSubstring, "License: Apache-2.0\n")
+		So(WingetDir("1.0.2"), ShouldEqual, "manifests/f/FirewineXie/envm/1.0.2")
+	})
+}
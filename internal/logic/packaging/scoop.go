@@ -0,0 +1,67 @@
+package packaging
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// scoopArchitectures scoop 中的架构名称
+var scoopArchitectures = map[string]string{"amd64": "64bit", "arm64": "arm64"}
+
+type scoopArchitecture struct {
+	URL  string `json:"url"`
+	Hash string `json:"hash,omitempty"`
+	Bin  string `json:"bin,omitempty"`
+}
+
+type scoopManifest struct {
+	Version      string                       `json:"version"`
+	Description  string                       `json:"description"`
+	Homepage     string                       `json:"homepage"`
+	License      string                       `json:"license"`
+	Architecture map[string]scoopArchitecture `json:"architecture"`
+	// Persist 安装的版本保存在 persist 目录中，升级 envm 不会丢失
+	Persist    string            `json:"persist"`
+	EnvSet     map[string]string `json:"env_set"`
+	Checkver   string            `json:"checkver"`
+	Autoupdate struct {
+		Architecture map[string]scoopArchitecture `json:"architecture"`
+		Hash         map[string]string            `json:"hash,omitempty"`
+	} `json:"autoupdate"`
+}
+
+// Scoop 生成 scoop bucket 中的 envm.json，只包含 windows 的发布文件
+// 可执行文件通过 url 的 #/envm.exe 重命名，autoupdate 中的地址将版本号替换为 $version
+func Scoop(p Package) ([]byte, error) {
+	manifest := scoopManifest{
+		Version:      p.Version,
+		Description:  p.Description,
+		Homepage:     Homepage,
+		License:      License,
+		Architecture: map[string]scoopArchitecture{},
+		Persist:      "home",
+		EnvSet:       map[string]string{"ENVM_HOME": `$dir\home`},
+		Checkver:     "github",
+	}
+	manifest.Autoupdate.Architecture = map[string]scoopArchitecture{}
+	for _, artifact := range p.Artifacts {
+		name, ok := scoopArchitectures[artifact.Arch]
+		if artifact.OS != "windows" || !ok {
+			continue
+		}
+		url := artifact.URL
+		if !artifact.Archive {
+			url += "#/envm.exe"
+		}
+		manifest.Architecture[name] = scoopArchitecture{URL: url, Hash: artifact.SHA256, Bin: "envm.exe"}
+		manifest.Autoupdate.Architecture[name] = scoopArchitecture{URL: strings.ReplaceAll(url, p.Version, "$version")}
+	}
+	if p.ChecksumURL != "" {
+		manifest.Autoupdate.Hash = map[string]string{"url": strings.ReplaceAll(p.ChecksumURL, p.Version, "$version")}
+	}
+	data, err := json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
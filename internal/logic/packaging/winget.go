@@ -0,0 +1,58 @@
+package packaging
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+const (
+	// WingetIdentifier winget 的包标识
+	WingetIdentifier = Publisher + "." + Name
+	// wingetManifestVersion manifest 的 schema 版本
+	wingetManifestVersion = "1.6.0"
+)
+
+// wingetArchitectures winget 中的架构名称
+var wingetArchitectures = map[string]string{"amd64": "x64", "arm64": "arm64"}
+
+// WingetDir manifest 在 winget-pkgs 仓库中的目录
+func WingetDir(version string) string {
+	return path.Join("manifests", strings.ToLower(Publisher[:1]), Publisher, Name, version)
+}
+
+// Winget 生成 winget-pkgs 需要的 version、installer、defaultLocale 三个 manifest，返回文件名到内容
+// 可执行文件作为 portable 安装，zip 通过 NestedInstallerFiles 指定其中的 envm.exe
+func Winget(p Package) map[string]string {
+	header := fmt.Sprintf("# generated by envm release winget, do not edit\nPackageIdentifier: %s\nPackageVersion: %s\n", WingetIdentifier, p.Version)
+	footer := func(manifestType string) string {
+		return fmt.Sprintf("ManifestType: %s\nManifestVersion: %s\n", manifestType, wingetManifestVersion)
+	}
+
+	var installer strings.Builder
+	installer.WriteString(header)
+	installer.WriteString("Commands:\n- envm\nInstallers:\n")
+	for _, artifact := range p.Artifacts {
+		arch, ok := wingetArchitectures[artifact.Arch]
+		if artifact.OS != "windows" || !ok {
+			continue
+		}
+		fmt.Fprintf(&installer, "- Architecture: %s\n", arch)
+		if artifact.Archive {
+			installer.WriteString("  InstallerType: zip\n  NestedInstallerType: portable\n  NestedInstallerFiles:\n" +
+				"  - RelativeFilePath: envm.exe\n    PortableCommandAlias: envm\n")
+		} else {
+			installer.WriteString("  InstallerType: portable\n")
+		}
+		fmt.Fprintf(&installer, "  InstallerUrl: %s\n  InstallerSha256: %s\n", artifact.URL, strings.ToUpper(artifact.SHA256))
+	}
+	installer.WriteString(footer("installer"))
+
+	return map[string]string{
+		WingetIdentifier + ".yaml":           header + "DefaultLocale: en-US\n" + footer("version"),
+		WingetIdentifier + ".installer.yaml": installer.String(),
+		WingetIdentifier + ".locale.en-US.yaml": header + "PackageLocale: en-US\n" +
+			fmt.Sprintf("Publisher: %s\nPackageName: %s\nPackageUrl: %s\nLicense: %s\nShortDescription: %s\n",
+				Publisher, Name, Homepage, License, p.Description) + footer("defaultLocale"),
+	}
+}
@@ -0,0 +1,83 @@
+// Package pinning 首次安装某个安装包时记录它的 sha256(trust on first use)
+// 之后从任何镜像再次安装同一个安装包时比较，发现被篡改的镜像
+package pinning
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Pin 一个安装包第一次安装时的校验和
+type Pin struct {
+	SHA256 string    `json:"sha256"`
+	Source string    `json:"source,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+// MismatchError 同一个安装包的校验和与第一次安装时记录的不一致
+type MismatchError struct {
+	Name   string
+	Pinned Pin
+	Got    Pin
+	File   string
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("checksum of %s changed since it was first installed\n"+
+		"  pinned  sha256:%s from %s at %s\n"+
+		"  now     sha256:%s from %s\n"+
+		"the mirror may have been tampered with, remove %q from %s only if the change is expected",
+		e.Name, e.Pinned.SHA256, e.Pinned.Source, e.Pinned.Time.Format(time.RFC3339),
+		e.Got.SHA256, e.Got.Source, e.Name, e.File)
+}
+
+// Load 读取 file 中按安装包文件名记录的校验和，文件不存在时返回空
+// 文件损坏时返回错误而不是当作空，避免已经记录的校验和被悄悄丢弃
+func Load(file string) (map[string]Pin, error) {
+	pins := map[string]Pin{}
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return pins, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &pins); err != nil {
+		return nil, fmt.Errorf("parse %s error + %w", file, err)
+	}
+	return pins, nil
+}
+
+// Check 比较安装包 name 的校验和与 file 中记录的是否一致，没有记录时写入 pin
+// 不一致时返回 *MismatchError，不会覆盖原来的记录
+func Check(file, name string, pin Pin) error {
+	pins, err := Load(file)
+	if err != nil {
+		return err
+	}
+	if pinned, ok := pins[name]; ok {
+		if pinned.SHA256 != pin.SHA256 {
+			return &MismatchError{Name: name, Pinned: pinned, Got: pin, File: file}
+		}
+		return nil
+	}
+	if pin.Time.IsZero() {
+		pin.Time = time.Now()
+	}
+	pins[name] = pin
+	data, err := json.MarshalIndent(pins, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	if err = os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
@@ -0,0 +1,42 @@
+package pinning
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCheck(t *testing.T) {
+	Convey("第一次安装时记录校验和，之后比较", t, func() {
+		file := filepath.Join(t.TempDir(), "checksums.json")
+		name := "go1.22.3.linux-amd64.tar.gz"
+		So(Check(file, name, Pin{SHA256: "aaa", Source: "https://go.dev/dl/" + name}), ShouldBeNil)
+		So(Check(file, name, Pin{SHA256: "aaa", Source: "https://golang.google.cn/dl/" + name}), ShouldBeNil)
+
+		err := Check(file, name, Pin{SHA256: "bbb", Source: "https://mirror.example.com/" + name})
+		var mismatch *MismatchError
+		So(errors.As(err, &mismatch), ShouldBeTrue)
+		So(mismatch.Pinned.SHA256, ShouldEqual, "aaa")
+		So(mismatch.Pinned.Source, ShouldEqual, "https://go.dev/dl/"+name)
+		So(err.Error(), ShouldContainSubstring, "mirror.example.com")
+
+		// 不一致时不覆盖原来的记录
+		pins, err := Load(file)
+		So(err, ShouldBeNil)
+		So(pins[name].SHA256, ShouldEqual, "aaa")
+		So(Check(file, "node-v20.12.2-linux-x64.tar.xz", Pin{SHA256: "ccc"}), ShouldBeNil)
+		pins, _ = Load(file)
+		So(pins, ShouldHaveLength, 2)
+	})
+
+	Convey("数据库损坏时返回错误", t, func() {
+		file := filepath.Join(t.TempDir(), "checksums.json")
+		So(os.WriteFile(file, []byte("{"), 0644), ShouldBeNil)
+		So(Check(file, "a.tar.gz", Pin{SHA256: "aaa"}), ShouldNotBeNil)
+		data, _ := os.ReadFile(file)
+		So(string(data), ShouldEqual, "{")
+	})
+}
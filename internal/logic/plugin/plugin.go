@@ -0,0 +1,166 @@
+// Package plugin 基于可执行文件的插件协议，用于社区维护的语言后端(ruby/erlang/julia 等)
+//
+// 插件目录位于 <ENVM_HOME>/plugins/<name>，bin 下放置以下钩子，均可以是任意可执行文件:
+//
+//	bin/list-remote  输出所有可安装版本，以空白或换行分隔，必须
+//	bin/download     将 ENVM_VERSION 对应的安装包下载到 ENVM_DOWNLOAD_PATH，可选
+//	bin/install      将 ENVM_VERSION 安装到 ENVM_INSTALL_PATH，必须
+//	bin/activate     激活后执行，可以输出需要设置的环境变量，可选
+//
+// 钩子执行时可以使用的环境变量: ENVM_VERSION ENVM_DOWNLOAD_PATH ENVM_INSTALL_PATH ENVM_OS ENVM_ARCH
+package plugin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/FirewineXie/envm/util"
+)
+
+const (
+	HookListRemote = "list-remote"
+	HookDownload   = "download"
+	HookInstall    = "install"
+	HookActivate   = "activate"
+)
+
+// ErrHookNotFound 插件没有实现该钩子
+var ErrHookNotFound = errors.New("plugin hook not found")
+
+// Plugin 已安装的插件
+type Plugin struct {
+	Name string
+	Dir  string
+}
+
+// Path 返回插件 name 在 root 下的目录，名称为空、包含 .. 或路径分隔符等会指向 root 之外的目录时返回错误
+func Path(root, name string) (string, error) {
+	if name == "" || name == "." || strings.Contains(name, "..") || strings.ContainsAny(name, `/\:`) || filepath.IsAbs(name) {
+		return "", fmt.Errorf("invalid plugin name %q", name)
+	}
+	dir := filepath.Join(root, name)
+	if filepath.Dir(dir) != filepath.Clean(root) {
+		return "", fmt.Errorf("invalid plugin name %q", name)
+	}
+	return dir, nil
+}
+
+// Open 打开 root 下的插件
+func Open(root, name string) (*Plugin, error) {
+	dir, err := Path(root, name)
+	if err != nil {
+		return nil, err
+	}
+	p := &Plugin{Name: name, Dir: dir}
+	if exists, _ := util.PathExists(p.hook(HookInstall)); !exists {
+		return nil, fmt.Errorf("plugin %s is not installed or has no bin/%s", name, HookInstall)
+	}
+	return p, nil
+}
+
+// List 返回 root 下的所有插件
+func List(root string) (plugins []*Plugin) {
+	files, _ := os.ReadDir(root)
+	for _, file := range files {
+		if !file.IsDir() {
+			continue
+		}
+		if p, err := Open(root, file.Name()); err == nil {
+			plugins = append(plugins, p)
+		}
+	}
+	return plugins
+}
+
+// hook 返回钩子路径，windows 下依次查找 .exe/.cmd/.bat 后缀
+func (p *Plugin) hook(name string) string {
+	path := filepath.Join(p.Dir, "bin", name)
+	if runtime.GOOS == "windows" {
+		for _, ext := range []string{".exe", ".cmd", ".bat"} {
+			if exists, _ := util.PathExists(path + ext); exists {
+				return path + ext
+			}
+		}
+	}
+	return path
+}
+
+// Has 插件是否实现了钩子
+func (p *Plugin) Has(name string) bool {
+	exists, _ := util.PathExists(p.hook(name))
+	return exists
+}
+
+// Env 钩子执行时的环境变量
+type Env struct {
+	Version      string
+	DownloadPath string
+	InstallPath  string
+}
+
+func (e Env) environ() []string {
+	return append(os.Environ(),
+		"ENVM_VERSION="+e.Version,
+		"ENVM_DOWNLOAD_PATH="+e.DownloadPath,
+		"ENVM_INSTALL_PATH="+e.InstallPath,
+		"ENVM_OS="+runtime.GOOS,
+		"ENVM_ARCH="+runtime.GOARCH,
+	)
+}
+
+// Run 执行钩子，stdout 作为结果返回，stderr 直接输出
+func (p *Plugin) Run(name string, env Env) ([]byte, error) {
+	if !p.Has(name) {
+		return nil, ErrHookNotFound
+	}
+	var stdout bytes.Buffer
+	cmd := exec.Command(p.hook(name))
+	cmd.Dir = p.Dir
+	cmd.Env = env.environ()
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("plugin %s %s: %w", p.Name, name, err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// ListRemote 执行 list-remote 钩子
+func (p *Plugin) ListRemote() ([]string, error) {
+	output, err := p.Run(HookListRemote, Env{})
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// Install 依次执行 download 与 install 钩子，失败时清理安装目录
+func (p *Plugin) Install(version, downloadPath, installPath string) error {
+	env := Env{Version: version, DownloadPath: downloadPath, InstallPath: installPath}
+	for _, dir := range []string{downloadPath, installPath} {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return err
+		}
+	}
+	defer os.RemoveAll(downloadPath)
+	// 插件进程同样收到中断信号，envm 负责清理两个目录
+	defer util.OnInterrupt(func() {
+		_ = os.RemoveAll(downloadPath)
+		_ = os.RemoveAll(installPath)
+	})()
+	if _, err := p.Run(HookDownload, env); err != nil && !errors.Is(err, ErrHookNotFound) {
+		_ = os.RemoveAll(installPath)
+		return err
+	}
+	if _, err := p.Run(HookInstall, env); err != nil {
+		_ = os.RemoveAll(installPath)
+		return err
+	}
+	return nil
+}
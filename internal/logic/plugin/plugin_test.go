@@ -0,0 +1,67 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func writeHook(t *testing.T, dir, name, script string) {
+	path := filepath.Join(dir, "bin", name)
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks are shell scripts")
+	}
+	Convey("执行插件钩子", t, func() {
+		root := t.TempDir()
+		dir := filepath.Join(root, "ruby")
+		writeHook(t, dir, HookListRemote, "echo 3.3.0 3.2.4")
+		writeHook(t, dir, HookInstall, `echo "$ENVM_VERSION" > "$ENVM_INSTALL_PATH/VERSION"`)
+
+		p, err := Open(root, "ruby")
+		So(err, ShouldBeNil)
+		So(len(List(root)), ShouldEqual, 1)
+
+		versions, err := p.ListRemote()
+		So(err, ShouldBeNil)
+		So(versions, ShouldResemble, []string{"3.3.0", "3.2.4"})
+
+		install := filepath.Join(root, "ruby3.3.0")
+		So(p.Install("3.3.0", install+".download", install), ShouldBeNil)
+		content, err := os.ReadFile(filepath.Join(install, "VERSION"))
+		So(err, ShouldBeNil)
+		So(string(content), ShouldEqual, "3.3.0\n")
+
+		_, err = p.Run(HookActivate, Env{})
+		So(err, ShouldEqual, ErrHookNotFound)
+
+		_, err = Open(root, "julia")
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestPath(t *testing.T) {
+	Convey("插件名称不能指向插件目录之外", t, func() {
+		root := t.TempDir()
+		dir, err := Path(root, "ruby")
+		So(err, ShouldBeNil)
+		So(dir, ShouldEqual, filepath.Join(root, "ruby"))
+		for _, name := range []string{"", ".", "..", "../../x", "a/b", `a\b`, "/tmp/x", "C:x"} {
+			_, err = Path(root, name)
+			So(err, ShouldNotBeNil)
+		}
+		_, err = Open(root, "../x")
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,136 @@
+// Package project 查找项目目录中声明的版本
+//
+// 支持的文件(同一目录中按顺序优先):
+//
+//	.envmrc          每行 "<name> <version>"，可以同时声明多个语言
+//	.go-version      go
+//	.java-version    java
+//	.nvmrc           node
+//	.node-version    node
+//	.tool-versions   asdf 格式
+package project
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/internal/logic/toolversions"
+)
+
+// EnvmrcName 统一的项目版本文件
+const EnvmrcName = ".envmrc"
+
+// singleFiles 只声明一个语言的版本文件
+var singleFiles = []struct {
+	name     string
+	language string
+}{
+	{".go-version", config.GO},
+	{".java-version", config.JAVA},
+	{".nvmrc", config.NODE},
+	{".node-version", config.NODE},
+}
+
+// Pin 项目中声明的版本
+type Pin struct {
+	// Name 文件中的名称，.envmrc/.tool-versions 中可能是 asdf 的别名
+	Name    string
+	Version string
+	// File 声明所在的文件
+	File string
+}
+
+// Find 从 dir 开始逐级向上查找版本声明，离 dir 越近越靠前
+// 同一名称可能出现多次，调用方取第一个即可
+func Find(dir string) (pins []Pin) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+	for {
+		pins = append(pins, findInDir(dir)...)
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return pins
+		}
+		dir = parent
+	}
+}
+
+// skipDirs 查找子项目时跳过的依赖目录，以 . 开头的目录同样跳过
+var skipDirs = map[string]bool{"node_modules": true, "vendor": true}
+
+// Projects 返回 root 及其子目录中包含版本文件的目录，用于包含多个子项目的仓库(monorepo)，按路径排序
+// 不进入以 . 开头的目录、node_modules、vendor 及链接的目录
+func Projects(root string) (dirs []string, err error) {
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// 没有权限读取的子目录不影响其它项目
+			if path != root && d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && (strings.HasPrefix(d.Name(), ".") || skipDirs[d.Name()]) {
+			return filepath.SkipDir
+		}
+		if len(findInDir(path)) > 0 {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	return dirs, err
+}
+
+func findInDir(dir string) (pins []Pin) {
+	pins = append(pins, readList(filepath.Join(dir, EnvmrcName))...)
+	for _, file := range singleFiles {
+		filename := filepath.Join(dir, file.name)
+		if version := readSingle(filename); version != "" {
+			pins = append(pins, Pin{Name: file.language, Version: version, File: filename})
+		}
+	}
+	return append(pins, readList(filepath.Join(dir, toolversions.FileName))...)
+}
+
+// readList 读取 .envmrc/.tool-versions 格式的文件
+func readList(filename string) (pins []Pin) {
+	if _, err := os.Stat(filename); err != nil {
+		return nil
+	}
+	f, err := toolversions.Read(filename)
+	if err != nil {
+		return nil
+	}
+	for _, entry := range f.Entries() {
+		pins = append(pins, Pin{Name: entry.Name, Version: entry.Version(), File: filename})
+	}
+	return pins
+}
+
+// readSingle 读取单版本文件的第一行有效内容，去掉 .nvmrc 常见的 v 前缀
+func readSingle(filename string) string {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			return strings.TrimPrefix(line, "v")
+		}
+	}
+	return ""
+}
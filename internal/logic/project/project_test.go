@@ -0,0 +1,72 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFind(t *testing.T) {
+	Convey("向上查找项目版本文件", t, func() {
+		root := t.TempDir()
+		sub := filepath.Join(root, "service", "api")
+		So(os.MkdirAll(sub, os.ModePerm), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(root, ".tool-versions"), []byte("golang 1.21.5\nnodejs 18.19.0\n"), 0644), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(root, "service", ".nvmrc"), []byte("v20.10.0\n"), 0644), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(sub, ".envmrc"), []byte("# api\ngo 1.22.3\n"), 0644), ShouldBeNil)
+
+		pins := Find(sub)
+		So(len(pins), ShouldBeGreaterThanOrEqualTo, 4)
+		So(pins[0], ShouldResemble, Pin{Name: "go", Version: "1.22.3", File: filepath.Join(sub, ".envmrc")})
+		So(pins[1], ShouldResemble, Pin{Name: "node", Version: "20.10.0", File: filepath.Join(root, "service", ".nvmrc")})
+		So(pins[2].Name, ShouldEqual, "golang")
+		So(pins[3].Name, ShouldEqual, "nodejs")
+	})
+}
+
+func TestProjects(t *testing.T) {
+	Convey("查找包含版本文件的子项目", t, func() {
+		root := t.TempDir()
+		for _, dir := range []string{"services/api", "services/web/node_modules/pkg", "tools", ".cache/x"} {
+			So(os.MkdirAll(filepath.Join(root, dir), os.ModePerm), ShouldBeNil)
+		}
+		So(os.WriteFile(filepath.Join(root, ".envmrc"), []byte("go 1.22.3\n"), 0644), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(root, "services", "api", ".go-version"), []byte("1.21.9\n"), 0644), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(root, "services", "web", ".nvmrc"), []byte("20\n"), 0644), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(root, "services", "web", "node_modules", "pkg", ".nvmrc"), []byte("16\n"), 0644), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(root, ".cache", "x", ".envmrc"), []byte("go 1.20\n"), 0644), ShouldBeNil)
+
+		dirs, err := Projects(root)
+		So(err, ShouldBeNil)
+		So(dirs, ShouldResemble, []string{root, filepath.Join(root, "services", "api"), filepath.Join(root, "services", "web")})
+	})
+}
+
+func TestRemember(t *testing.T) {
+	Convey("记录 shell hook 看到的项目目录", t, func() {
+		root := t.TempDir()
+		file := filepath.Join(root, "projects.json")
+		api, web := filepath.Join(root, "api"), filepath.Join(root, "web")
+		So(os.MkdirAll(api, os.ModePerm), ShouldBeNil)
+		So(os.MkdirAll(web, os.ModePerm), ShouldBeNil)
+
+		So(Recent(file), ShouldBeEmpty)
+		So(Remember(file, []string{api, web}), ShouldBeNil)
+		So(Recent(file), ShouldResemble, []string{api, web})
+
+		// 一天内再次看到不重写文件
+		info, err := os.Stat(file)
+		So(err, ShouldBeNil)
+		So(os.Chtimes(file, info.ModTime().Add(-time.Hour), info.ModTime().Add(-time.Hour)), ShouldBeNil)
+		So(Remember(file, []string{api}), ShouldBeNil)
+		again, _ := os.Stat(file)
+		So(again.ModTime().Before(info.ModTime()), ShouldBeTrue)
+
+		// 删除的项目不再返回
+		So(os.RemoveAll(web), ShouldBeNil)
+		So(Recent(file), ShouldResemble, []string{api})
+	})
+}
@@ -0,0 +1,75 @@
+package project
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	// seenTTL 超过该时间没有进入过的项目不再视为在使用
+	seenTTL = 90 * 24 * time.Hour
+	// seenRefresh 已经记录过的项目，距离上次记录超过该时间才重新写入，避免每次 cd 都写文件
+	seenRefresh = 24 * time.Hour
+)
+
+// loadSeen 读取项目目录及最后一次被 shell hook 看到的时间
+func loadSeen(file string) map[string]time.Time {
+	seen := map[string]time.Time{}
+	if data, err := os.ReadFile(file); err == nil {
+		_ = json.Unmarshal(data, &seen)
+	}
+	return seen
+}
+
+// Remember 记录 shell hook 看到的项目目录，卸载版本前检查这些项目是否还声明了该版本
+// 同时清理过期或已经删除的项目，没有变化时不写文件
+func Remember(file string, dirs []string) error {
+	seen := loadSeen(file)
+	now := time.Now()
+	changed := false
+	for _, dir := range dirs {
+		if now.Sub(seen[dir]) > seenRefresh {
+			seen[dir] = now
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	for dir, last := range seen {
+		if now.Sub(last) > seenTTL {
+			delete(seen, dir)
+		} else if _, err := os.Stat(dir); err != nil {
+			delete(seen, dir)
+		}
+	}
+	data, err := json.MarshalIndent(seen, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	if err = os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// Recent 返回最近被 shell hook 看到且仍然存在的项目目录
+func Recent(file string) (dirs []string) {
+	for dir, last := range loadSeen(file) {
+		if time.Since(last) > seenTTL {
+			continue
+		}
+		if _, err := os.Stat(dir); err == nil {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
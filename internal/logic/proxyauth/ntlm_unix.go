@@ -0,0 +1,7 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package proxyauth
+
+func newNTLM(username, password string) (Authenticator, error) {
+	return nil, ErrNTLMUnsupported
+}
@@ -0,0 +1,147 @@
+//go:build windows
+
+package proxyauth
+
+import (
+	"fmt"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	secur32                        = syscall.NewLazyDLL("secur32.dll")
+	procAcquireCredentialsHandleW  = secur32.NewProc("AcquireCredentialsHandleW")
+	procInitializeSecurityContextW = secur32.NewProc("InitializeSecurityContextW")
+	procDeleteSecurityContext      = secur32.NewProc("DeleteSecurityContext")
+	procFreeCredentialsHandle      = secur32.NewProc("FreeCredentialsHandle")
+)
+
+const (
+	secpkgCredOutbound      = 2
+	securityNativeDrep      = 0x10
+	secbufferVersion        = 0
+	secbufferToken          = 2
+	secWinntAuthIdentityUni = 2
+	iscReqConnection        = 0x800
+	secEOk                  = 0
+	secIContinueNeeded      = 0x00090312
+	secICompleteNeeded      = 0x00090313
+	secICompleteAndContinue = 0x00090314
+	maxTokenSize            = 16 << 10
+)
+
+type secHandle struct {
+	lower, upper uintptr
+}
+
+type timeStamp struct {
+	low, high uint32
+}
+
+type secBuffer struct {
+	size       uint32
+	bufferType uint32
+	buffer     *byte
+}
+
+type secBufferDesc struct {
+	version uint32
+	count   uint32
+	buffers *secBuffer
+}
+
+// authIdentity SEC_WINNT_AUTH_IDENTITY_W
+type authIdentity struct {
+	user           *uint16
+	userLength     uint32
+	domain         *uint16
+	domainLength   uint32
+	password       *uint16
+	passwordLength uint32
+	flags          uint32
+}
+
+// sspi 通过 SSPI 的 NTLM 包生成 token，不需要自己实现 NTLM，账号为空时使用当前登录的账号
+type sspi struct {
+	credentials secHandle
+	context     secHandle
+	started     bool
+}
+
+func newNTLM(username, password string) (Authenticator, error) {
+	if err := procAcquireCredentialsHandleW.Find(); err != nil {
+		return nil, err
+	}
+	var identity *authIdentity
+	if username != "" {
+		domain, user, ok := strings.Cut(username, `\`)
+		if !ok {
+			domain, user = "", username
+		}
+		identity = &authIdentity{flags: secWinntAuthIdentityUni}
+		identity.user, identity.userLength = utf16(user)
+		identity.domain, identity.domainLength = utf16(domain)
+		identity.password, identity.passwordLength = utf16(password)
+	}
+	pkg, err := syscall.UTF16PtrFromString("NTLM")
+	if err != nil {
+		return nil, err
+	}
+	s := &sspi{}
+	var expiry timeStamp
+	status, _, _ := procAcquireCredentialsHandleW.Call(0, uintptr(unsafe.Pointer(pkg)), secpkgCredOutbound, 0,
+		uintptr(unsafe.Pointer(identity)), 0, 0, uintptr(unsafe.Pointer(&s.credentials)), uintptr(unsafe.Pointer(&expiry)))
+	if status != secEOk {
+		return nil, fmt.Errorf("AcquireCredentialsHandle error 0x%08x", uint32(status))
+	}
+	return s, nil
+}
+
+// utf16 返回 SEC_WINNT_AUTH_IDENTITY_W 需要的字符串指针及不含结尾 0 的长度
+func utf16(s string) (*uint16, uint32) {
+	encoded, err := syscall.UTF16FromString(s)
+	if err != nil {
+		return nil, 0
+	}
+	return &encoded[0], uint32(len(encoded) - 1)
+}
+
+func (s *sspi) Next(challenge []byte) ([]byte, error) {
+	output := make([]byte, maxTokenSize)
+	outBuffer := secBuffer{size: uint32(len(output)), bufferType: secbufferToken, buffer: &output[0]}
+	outDesc := secBufferDesc{version: secbufferVersion, count: 1, buffers: &outBuffer}
+
+	var context, input uintptr
+	var inBuffer secBuffer
+	var inDesc secBufferDesc
+	if s.started {
+		context = uintptr(unsafe.Pointer(&s.context))
+		if len(challenge) > 0 {
+			inBuffer = secBuffer{size: uint32(len(challenge)), bufferType: secbufferToken, buffer: &challenge[0]}
+			inDesc = secBufferDesc{version: secbufferVersion, count: 1, buffers: &inBuffer}
+			input = uintptr(unsafe.Pointer(&inDesc))
+		}
+	}
+	var attributes uint32
+	var expiry timeStamp
+	status, _, _ := procInitializeSecurityContextW.Call(uintptr(unsafe.Pointer(&s.credentials)), context, 0,
+		iscReqConnection, 0, securityNativeDrep, input, 0, uintptr(unsafe.Pointer(&s.context)),
+		uintptr(unsafe.Pointer(&outDesc)), uintptr(unsafe.Pointer(&attributes)), uintptr(unsafe.Pointer(&expiry)))
+	switch status {
+	case secEOk, secIContinueNeeded:
+	case secICompleteNeeded, secICompleteAndContinue:
+		// NTLM 不需要 CompleteAuthToken
+	default:
+		return nil, fmt.Errorf("InitializeSecurityContext error 0x%08x", uint32(status))
+	}
+	s.started = true
+	return output[:outBuffer.size], nil
+}
+
+func (s *sspi) Close() {
+	if s.started {
+		_, _, _ = procDeleteSecurityContext.Call(uintptr(unsafe.Pointer(&s.context)))
+	}
+	_, _, _ = procFreeCredentialsHandle.Call(uintptr(unsafe.Pointer(&s.credentials)))
+}
@@ -0,0 +1,208 @@
+// Package proxyauth 为 envm 的 HTTP 请求配置代理及代理认证
+// Basic 认证由 net/http 处理；NTLM 需要在同一个连接上多次往返，通过 CONNECT 隧道完成认证后再交给 net/http
+package proxyauth
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// ErrNTLMUnsupported 当前系统不支持 NTLM 代理认证
+var ErrNTLMUnsupported = errors.New("ntlm proxy authentication is only supported on windows, use basic credentials instead")
+
+// Config 代理及认证配置
+type Config struct {
+	// URL 代理地址，为空时使用 HTTPS_PROXY、HTTP_PROXY 及 NO_PROXY
+	URL      string
+	Username string
+	Password string
+	// NTLM 使用 NTLM 认证，账号及密码都为空时使用当前登录的账号
+	NTLM bool
+}
+
+// Enabled 是否需要修改默认的代理设置
+func (c Config) Enabled() bool {
+	return c.URL != "" || c.Username != "" || c.NTLM
+}
+
+// Authenticator 需要在同一个连接上多次往返的代理认证
+type Authenticator interface {
+	// Next 根据代理返回的 challenge(第一次为 nil)生成下一个 token
+	Next(challenge []byte) ([]byte, error)
+	Close()
+}
+
+// NewNTLM 创建 NTLM 认证，不同的系统实现不同，见 ntlm_window.go
+var NewNTLM = newNTLM
+
+// Error 代理拒绝了请求，通常是账号错误或没有权限
+type Error struct {
+	Proxy  string
+	Target string
+	Status string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("proxy %s refused CONNECT %s: %s, check the proxy credentials", e.Proxy, e.Target, e.Status)
+}
+
+// ProxyFunc 返回请求使用的代理，配置了 URL 时仍然按 NO_PROXY 跳过代理，配置了账号时加入代理地址中
+func ProxyFunc(c Config) func(*http.Request) (*url.URL, error) {
+	environment := httpproxy.FromEnvironment()
+	if c.URL != "" {
+		environment.HTTPProxy, environment.HTTPSProxy = c.URL, c.URL
+	}
+	proxy := environment.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		u, err := proxy(req.URL)
+		if err != nil || u == nil {
+			return u, err
+		}
+		if c.Username != "" && !c.NTLM {
+			u.User = url.UserPassword(c.Username, c.Password)
+		}
+		return u, nil
+	}
+}
+
+// Configure 修改 t 的代理设置，NTLM 时由 DialContext 建立认证过的 CONNECT 隧道
+// 不支持 NTLM 的系统在访问网络时才返回 ErrNTLMUnsupported，不影响不访问网络的命令
+func Configure(t *http.Transport, c Config) {
+	proxy := ProxyFunc(c)
+	if !c.NTLM {
+		t.Proxy = proxy
+		return
+	}
+	dial := t.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	t.Proxy = nil
+	t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		// 隧道中的请求与直接连接相同，按 https 选择代理
+		u, err := proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: addr}})
+		if err != nil {
+			return nil, err
+		}
+		if u == nil {
+			return dial(ctx, network, addr)
+		}
+		if u.Scheme != "http" {
+			return nil, fmt.Errorf("ntlm proxy authentication requires an http:// proxy, got %s", u.Redacted())
+		}
+		auth, err := NewNTLM(c.Username, c.Password)
+		if err != nil {
+			return nil, err
+		}
+		defer auth.Close()
+		host := u.Host
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "80")
+		}
+		conn, err := dial(ctx, network, host)
+		if err != nil {
+			return nil, err
+		}
+		tunnel, err := Connect(conn, u.Host, addr, "NTLM", auth)
+		if err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		return tunnel, nil
+	}
+}
+
+// maxRounds 认证的最大往返次数，NTLM 只需要两次
+const maxRounds = 4
+
+// Connect 在到代理的连接 conn 上建立到 addr 的 CONNECT 隧道，按 scheme 认证方式与代理往返直到认证成功或被拒绝
+func Connect(conn net.Conn, proxy, addr, scheme string, auth Authenticator) (net.Conn, error) {
+	reader := bufio.NewReader(conn)
+	var challenge []byte
+	for round := 0; round < maxRounds; round++ {
+		token, err := auth.Next(challenge)
+		if err != nil {
+			return nil, fmt.Errorf("%s proxy authentication error + %w", scheme, err)
+		}
+		req := &http.Request{
+			Method: http.MethodConnect,
+			URL:    &url.URL{Opaque: addr},
+			Host:   addr,
+			Header: http.Header{
+				"Proxy-Authorization": {scheme + " " + base64.StdEncoding.EncodeToString(token)},
+				"Proxy-Connection":    {"Keep-Alive"},
+			},
+		}
+		if err = req.Write(conn); err != nil {
+			return nil, err
+		}
+		resp, err := http.ReadResponse(reader, req)
+		if err != nil {
+			return nil, err
+		}
+		switch resp.StatusCode {
+		case http.StatusOK:
+			return &bufferedConn{Conn: conn, reader: reader}, nil
+		case http.StatusProxyAuthRequired:
+		default:
+			_ = resp.Body.Close()
+			return nil, &Error{Proxy: proxy, Target: addr, Status: resp.Status}
+		}
+		// 认证需要在同一个连接上继续，先读完响应体
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		if resp.Close {
+			return nil, &Error{Proxy: proxy, Target: addr, Status: resp.Status + " (connection closed during authentication)"}
+		}
+		challenge = parseChallenge(resp.Header.Values("Proxy-Authenticate"), scheme)
+		if challenge == nil {
+			// 代理不再给出 challenge，说明账号被拒绝
+			return nil, &Error{Proxy: proxy, Target: addr, Status: resp.Status}
+		}
+	}
+	return nil, &Error{Proxy: proxy, Target: addr, Status: fmt.Sprintf("authentication did not finish after %d rounds", maxRounds)}
+}
+
+// parseChallenge 从 Proxy-Authenticate 中取出 scheme 的 challenge，没有时返回 nil
+func parseChallenge(headers []string, scheme string) []byte {
+	for _, header := range headers {
+		name, value, _ := strings.Cut(strings.TrimSpace(header), " ")
+		if !strings.EqualFold(name, scheme) || value == "" {
+			continue
+		}
+		if challenge, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value)); err == nil {
+			return challenge
+		}
+	}
+	return nil
+}
+
+// bufferedConn 先读出读取 CONNECT 响应时多读的数据
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+// Install 修改 http.DefaultTransport 的代理设置，需要在 netpolicy.Install 之前调用
+func Install(c Config) error {
+	t, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("proxy settings must be applied before the network policy")
+	}
+	Configure(t, c)
+	return nil
+}
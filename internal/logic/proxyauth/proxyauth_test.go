@@ -0,0 +1,137 @@
+package proxyauth
+
+import (
+	"bufio"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fakeAuth 第一次返回 negotiate，之后返回 response:<challenge>
+type fakeAuth struct{}
+
+func (fakeAuth) Next(challenge []byte) ([]byte, error) {
+	if challenge == nil {
+		return []byte("negotiate"), nil
+	}
+	return append([]byte("response:"), challenge...), nil
+}
+
+func (fakeAuth) Close() {}
+
+// ntlmProxy 只接受 CONNECT，按 fakeAuth 的 token 认证后转发到目标地址
+func ntlmProxy(t *testing.T, challenge string) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					req, err := http.ReadRequest(reader)
+					if err != nil || req.Method != http.MethodConnect {
+						return
+					}
+					token, _ := base64.StdEncoding.DecodeString(req.Header.Get("Proxy-Authorization")[len("NTLM "):])
+					switch string(token) {
+					case "negotiate":
+						_, _ = io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\nProxy-Authenticate: NTLM "+
+							base64.StdEncoding.EncodeToString([]byte(challenge))+"\r\nContent-Length: 4\r\n\r\ndeny")
+						continue
+					case "response:secret":
+					default:
+						_, _ = io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\nProxy-Authenticate: NTLM\r\nContent-Length: 0\r\n\r\n")
+						continue
+					}
+					target, err := net.Dial("tcp", req.Host)
+					if err != nil {
+						return
+					}
+					_, _ = io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+					go func() { _, _ = io.Copy(target, reader) }()
+					_, _ = io.Copy(conn, target)
+					_ = target.Close()
+					return
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestProxyFunc(t *testing.T) {
+	Convey("配置的代理加入账号，NO_PROXY 中的地址不使用代理", t, func() {
+		t.Setenv("NO_PROXY", "internal.corp.example")
+		proxy := ProxyFunc(Config{URL: "http://proxy.corp.example:8080", Username: "alice", Password: "p@ss"})
+		u, err := proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: "go.dev"}})
+		So(err, ShouldBeNil)
+		So(u.Host, ShouldEqual, "proxy.corp.example:8080")
+		password, _ := u.User.Password()
+		So(u.User.Username()+":"+password, ShouldEqual, "alice:p@ss")
+
+		u, err = proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: "internal.corp.example"}})
+		So(err, ShouldBeNil)
+		So(u, ShouldBeNil)
+	})
+}
+
+func TestBasic(t *testing.T) {
+	Convey("Basic 认证由 net/http 加入 Proxy-Authorization", t, func() {
+		var header string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header.Get("Proxy-Authorization")
+			_, _ = io.WriteString(w, "via proxy")
+		}))
+		defer proxy.Close()
+		transport := &http.Transport{}
+		Configure(transport, Config{URL: proxy.URL, Username: "alice", Password: "secret"})
+		resp, err := (&http.Client{Transport: transport}).Get("http://example.com/go.tar.gz")
+		So(err, ShouldBeNil)
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		So(string(body), ShouldEqual, "via proxy")
+		So(header, ShouldEqual, "Basic "+base64.StdEncoding.EncodeToString([]byte("alice:secret")))
+	})
+}
+
+func TestNTLM(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "through the tunnel")
+	}))
+	defer target.Close()
+
+	Convey("认证通过后通过隧道访问", t, func() {
+		NewNTLM = func(username, password string) (Authenticator, error) { return fakeAuth{}, nil }
+		defer func() { NewNTLM = newNTLM }()
+		transport := &http.Transport{}
+		Configure(transport, Config{URL: "http://" + ntlmProxy(t, "secret"), NTLM: true})
+		resp, err := (&http.Client{Transport: transport}).Get(target.URL)
+		So(err, ShouldBeNil)
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		So(string(body), ShouldEqual, "through the tunnel")
+	})
+
+	Convey("认证失败时返回代理拒绝的错误", t, func() {
+		conn, err := net.Dial("tcp", ntlmProxy(t, "other"))
+		So(err, ShouldBeNil)
+		defer conn.Close()
+		_, err = Connect(conn, "proxy", target.Listener.Addr().String(), "NTLM", fakeAuth{})
+		var refused *Error
+		So(err, ShouldHaveSameTypeAs, refused)
+	})
+}
@@ -0,0 +1,99 @@
+// Package relocate 将安装目录迁移到其它位置，例如从已满的磁盘迁移到新的磁盘
+package relocate
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Within 判断 path 是否为 root 本身或在 root 下
+func Within(path, root string) bool {
+	rel, err := filepath.Rel(filepath.Clean(root), filepath.Clean(path))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+// Rebase 将 oldRoot 下的 path 改为 newRoot 下相同的相对路径，不在 oldRoot 下时返回 false
+func Rebase(path, oldRoot, newRoot string) (string, bool) {
+	if !Within(path, oldRoot) {
+		return path, false
+	}
+	rel, _ := filepath.Rel(filepath.Clean(oldRoot), filepath.Clean(path))
+	return filepath.Join(newRoot, rel), true
+}
+
+// Move 将目录 src 移动到不存在的 dst，返回是否通过复制完成
+// 同一个文件系统内直接重命名；跨磁盘时复制到 dst.tmp 后再重命名，不会留下不完整的 dst
+// 复制时 src 保持不变，由调用者在更新配置后删除
+func Move(src, dst string) (copied bool, err error) {
+	if _, err = os.Lstat(dst); err == nil {
+		return false, fmt.Errorf("%s already exists", dst)
+	}
+	if err = os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return false, err
+	}
+	if os.Rename(src, dst) == nil {
+		return false, nil
+	}
+	tmp := dst + ".tmp"
+	_ = os.RemoveAll(tmp)
+	if err = Copy(src, tmp); err != nil {
+		_ = os.RemoveAll(tmp)
+		return true, err
+	}
+	if err = os.Rename(tmp, dst); err != nil {
+		_ = os.RemoveAll(tmp)
+		return true, err
+	}
+	return true, nil
+}
+
+// Copy 复制目录 src 到 dst，保留文件权限，链接只复制链接本身(例如 envm link 登记的外部目录)
+func Copy(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		default:
+			return copyFile(path, target, info.Mode().Perm())
+		}
+	})
+}
+
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,60 @@
+package relocate
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRebase(t *testing.T) {
+	Convey("只改写旧目录下的路径", t, func() {
+		root := filepath.FromSlash("/data/envm/downloads")
+		path, ok := Rebase(filepath.Join(root, "go", "go1.22.3"), root, filepath.FromSlash("/mnt/big/downloads"))
+		So(ok, ShouldBeTrue)
+		So(path, ShouldEqual, filepath.FromSlash("/mnt/big/downloads/go/go1.22.3"))
+
+		_, ok = Rebase(filepath.FromSlash("/data/envm/downloads-old/go"), root, "/mnt")
+		So(ok, ShouldBeFalse)
+		_, ok = Rebase(filepath.FromSlash("/usr/lib/jvm/java-17"), root, "/mnt")
+		So(ok, ShouldBeFalse)
+		So(Within(root, root), ShouldBeTrue)
+	})
+}
+
+func TestMove(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symlinks requires privileges on windows")
+	}
+	Convey("复制时保留文件权限及链接", t, func() {
+		src := filepath.Join(t.TempDir(), "downloads")
+		So(os.MkdirAll(filepath.Join(src, "go", "go1.22.3", "bin"), os.ModePerm), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(src, "go", "go1.22.3", "bin", "go"), []byte("#!/bin/sh"), 0755), ShouldBeNil)
+		external := t.TempDir()
+		So(os.Symlink(external, filepath.Join(src, "go", "gocustom")), ShouldBeNil)
+
+		dst := filepath.Join(t.TempDir(), "downloads")
+		So(Copy(src, dst), ShouldBeNil)
+		info, err := os.Stat(filepath.Join(dst, "go", "go1.22.3", "bin", "go"))
+		So(err, ShouldBeNil)
+		So(info.Mode().Perm(), ShouldEqual, os.FileMode(0755))
+		link, err := os.Readlink(filepath.Join(dst, "go", "gocustom"))
+		So(err, ShouldBeNil)
+		So(link, ShouldEqual, external)
+	})
+
+	Convey("目标已经存在时不移动", t, func() {
+		src, dst := t.TempDir(), t.TempDir()
+		_, err := Move(src, dst)
+		So(err, ShouldNotBeNil)
+
+		moved := filepath.Join(t.TempDir(), "new", "downloads")
+		copied, err := Move(src, moved)
+		So(err, ShouldBeNil)
+		So(copied, ShouldBeFalse)
+		_, err = os.Stat(src)
+		So(os.IsNotExist(err), ShouldBeTrue)
+	})
+}
@@ -0,0 +1,221 @@
+// Package sbom 生成已安装工具链的软件物料清单(SBOM)，支持 CycloneDX 1.5 及 SPDX 2.3 的 JSON 格式
+package sbom
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// 支持的格式
+const (
+	CycloneDX = "cyclonedx"
+	SPDX      = "spdx"
+)
+
+// Component 一个已安装的工具链版本，Checksum/URL 来自安装时的审计记录，更早安装的版本没有
+type Component struct {
+	Name     string
+	Version  string
+	Supplier string
+	Checksum string
+	URL      string
+}
+
+// Document 生成清单需要的信息
+type Document struct {
+	// Tool 生成清单的工具及版本
+	Tool        string
+	ToolVersion string
+	Created     time.Time
+	Components  []Component
+}
+
+// Encode 按 format 生成 JSON 格式的清单
+func Encode(doc Document, format string) ([]byte, error) {
+	switch format {
+	case CycloneDX:
+		return json.MarshalIndent(doc.cycloneDX(), "", "  ")
+	case SPDX:
+		return json.MarshalIndent(doc.spdx(), "", "  ")
+	default:
+		return nil, fmt.Errorf("unknown sbom format %q, use %s or %s", format, CycloneDX, SPDX)
+	}
+}
+
+// algorithm 根据十六进制校验和的长度判断算法，返回 CycloneDX 及 SPDX 中的名称，无法判断时返回空
+func algorithm(checksum string) (cyclonedx, spdx string) {
+	switch len(checksum) {
+	case 40:
+		return "SHA-1", "SHA1"
+	case 64:
+		return "SHA-256", "SHA256"
+	case 128:
+		return "SHA-512", "SHA512"
+	}
+	return "", ""
+}
+
+// uuid 随机生成 v4 UUID，作为清单的唯一标识
+func uuid() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+type cdxDocument struct {
+	BOMFormat    string         `json:"bomFormat"`
+	SpecVersion  string         `json:"specVersion"`
+	SerialNumber string         `json:"serialNumber"`
+	Version      int            `json:"version"`
+	Metadata     cdxMetadata    `json:"metadata"`
+	Components   []cdxComponent `json:"components"`
+}
+
+type cdxMetadata struct {
+	Timestamp string `json:"timestamp"`
+	Tools     struct {
+		Components []cdxComponent `json:"components"`
+	} `json:"tools"`
+}
+
+type cdxComponent struct {
+	Type               string           `json:"type"`
+	BOMRef             string           `json:"bom-ref,omitempty"`
+	Name               string           `json:"name"`
+	Version            string           `json:"version"`
+	Supplier           *cdxSupplier     `json:"supplier,omitempty"`
+	Hashes             []cdxHash        `json:"hashes,omitempty"`
+	ExternalReferences []cdxExternalRef `json:"externalReferences,omitempty"`
+}
+
+type cdxSupplier struct {
+	Name string `json:"name"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cdxExternalRef struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+func (doc Document) cycloneDX() cdxDocument {
+	out := cdxDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + uuid(),
+		Version:      1,
+		Components:   []cdxComponent{},
+	}
+	out.Metadata.Timestamp = doc.Created.UTC().Format(time.RFC3339)
+	out.Metadata.Tools.Components = []cdxComponent{{Type: "application", Name: doc.Tool, Version: doc.ToolVersion}}
+	for _, c := range doc.Components {
+		component := cdxComponent{Type: "application", BOMRef: c.Name + "@" + c.Version, Name: c.Name, Version: c.Version}
+		if c.Supplier != "" {
+			component.Supplier = &cdxSupplier{Name: c.Supplier}
+		}
+		if alg, _ := algorithm(c.Checksum); alg != "" {
+			component.Hashes = []cdxHash{{Alg: alg, Content: c.Checksum}}
+		}
+		if c.URL != "" {
+			component.ExternalReferences = []cdxExternalRef{{Type: "distribution", URL: c.URL}}
+		}
+		out.Components = append(out.Components, component)
+	}
+	return out
+}
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name             string         `json:"name"`
+	SPDXID           string         `json:"SPDXID"`
+	VersionInfo      string         `json:"versionInfo"`
+	Supplier         string         `json:"supplier"`
+	DownloadLocation string         `json:"downloadLocation"`
+	FilesAnalyzed    bool           `json:"filesAnalyzed"`
+	Checksums        []spdxChecksum `json:"checksums,omitempty"`
+	LicenseConcluded string         `json:"licenseConcluded"`
+	LicenseDeclared  string         `json:"licenseDeclared"`
+	CopyrightText    string         `json:"copyrightText"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// spdxIDChars SPDXID 只能包含字母、数字、. 和 -
+var spdxIDChars = regexp.MustCompile(`[^A-Za-z0-9.-]`)
+
+// noAssertion SPDX 中表示没有相关信息
+const noAssertion = "NOASSERTION"
+
+func (doc Document) spdx() spdxDocument {
+	id := uuid()
+	out := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              doc.Tool + "-toolchains",
+		DocumentNamespace: "https://spdx.org/spdxdocs/" + doc.Tool + "-toolchains-" + id,
+		CreationInfo: spdxCreationInfo{
+			Created:  doc.Created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: " + doc.Tool + "-" + doc.ToolVersion},
+		},
+		Packages:      []spdxPackage{},
+		Relationships: []spdxRelationship{},
+	}
+	for _, c := range doc.Components {
+		pkg := spdxPackage{
+			Name:             c.Name,
+			SPDXID:           "SPDXRef-Package-" + spdxIDChars.ReplaceAllString(c.Name+"-"+c.Version, "-"),
+			VersionInfo:      c.Version,
+			Supplier:         noAssertion,
+			DownloadLocation: noAssertion,
+			LicenseConcluded: noAssertion,
+			LicenseDeclared:  noAssertion,
+			CopyrightText:    noAssertion,
+		}
+		if c.Supplier != "" {
+			pkg.Supplier = "Organization: " + c.Supplier
+		}
+		if c.URL != "" {
+			pkg.DownloadLocation = c.URL
+		}
+		if _, alg := algorithm(c.Checksum); alg != "" {
+			pkg.Checksums = []spdxChecksum{{Algorithm: alg, ChecksumValue: c.Checksum}}
+		}
+		out.Packages = append(out.Packages, pkg)
+		out.Relationships = append(out.Relationships, spdxRelationship{"SPDXRef-DOCUMENT", "DESCRIBES", pkg.SPDXID})
+	}
+	return out
+}
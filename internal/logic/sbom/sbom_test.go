@@ -0,0 +1,59 @@
+package sbom
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestEncode(t *testing.T) {
+	doc := Document{
+		Tool:        "envm",
+		ToolVersion: "v1.0.2",
+		Created:     time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC),
+		Components: []Component{
+			{Name: "go", Version: "1.22.3", Supplier: "Google LLC", URL: "https://golang.google.cn/dl/go1.22.3.linux-amd64.tar.gz",
+				Checksum: strings.Repeat("8", 64)},
+			// 审计日志之前安装的版本没有来源
+			{Name: "node", Version: "20.12.2+build"},
+		},
+	}
+
+	Convey("生成 CycloneDX 清单", t, func() {
+		data, err := Encode(doc, CycloneDX)
+		So(err, ShouldBeNil)
+		var out map[string]interface{}
+		So(json.Unmarshal(data, &out), ShouldBeNil)
+		So(out["bomFormat"], ShouldEqual, "CycloneDX")
+		So(out["serialNumber"], ShouldStartWith, "urn:uuid:")
+		components := out["components"].([]interface{})
+		So(len(components), ShouldEqual, 2)
+		goc := components[0].(map[string]interface{})
+		So(goc["bom-ref"], ShouldEqual, "go@1.22.3")
+		So(goc["supplier"].(map[string]interface{})["name"], ShouldEqual, "Google LLC")
+		So(goc["hashes"].([]interface{})[0].(map[string]interface{})["alg"], ShouldEqual, "SHA-256")
+		So(components[1].(map[string]interface{})["hashes"], ShouldBeNil)
+	})
+
+	Convey("生成 SPDX 清单", t, func() {
+		data, err := Encode(doc, SPDX)
+		So(err, ShouldBeNil)
+		var out spdxDocument
+		So(json.Unmarshal(data, &out), ShouldBeNil)
+		So(out.CreationInfo.Created, ShouldEqual, "2024-05-01T08:00:00Z")
+		So(len(out.Packages), ShouldEqual, 2)
+		So(out.Packages[0].Supplier, ShouldEqual, "Organization: Google LLC")
+		So(out.Packages[0].Checksums[0].Algorithm, ShouldEqual, "SHA256")
+		So(out.Packages[1].SPDXID, ShouldEqual, "SPDXRef-Package-node-20.12.2-build")
+		So(out.Packages[1].DownloadLocation, ShouldEqual, noAssertion)
+		So(len(out.Relationships), ShouldEqual, 2)
+	})
+
+	Convey("不支持的格式", t, func() {
+		_, err := Encode(doc, "xml")
+		So(err, ShouldNotBeNil)
+	})
+}
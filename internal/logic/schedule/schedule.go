@@ -0,0 +1,164 @@
+// Package schedule 生成定时执行 envm 命令的配置，支持 cron、systemd timer、launchd 及 windows 任务计划程序
+// 只输出配置，由用户自己安装，envm 不修改系统的定时任务
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 支持的格式
+const (
+	Cron     = "cron"
+	Systemd  = "systemd"
+	Launchd  = "launchd"
+	Schtasks = "schtasks"
+)
+
+// Formats 支持的格式
+var Formats = []string{Cron, Systemd, Launchd, Schtasks}
+
+// Job 定时执行的命令
+type Job struct {
+	// Name 任务名称，例如 envm-refresh
+	Name string
+	// Command 可执行文件的绝对路径及参数
+	Command []string
+	// Every 执行间隔，按小时取整，1h 到 24h
+	Every time.Duration
+	// Minute 每次在整点之后的第几分钟执行，各台机器取不同的值，避免同时访问镜像
+	Minute int
+	// Description 任务说明
+	Description string
+}
+
+// Check 检查格式、间隔及分钟
+func (j Job) Check(format string) error {
+	if !contains(Formats, format) {
+		return fmt.Errorf("unknown schedule %s, use one of %s", format, strings.Join(Formats, ", "))
+	}
+	if j.Every < time.Hour || j.Every > 24*time.Hour {
+		return fmt.Errorf("interval %s is not supported, use 1h to 24h", j.Every)
+	}
+	if j.Minute < 0 || j.Minute > 59 {
+		return fmt.Errorf("minute %d is out of range", j.Minute)
+	}
+	if len(j.Command) == 0 {
+		return fmt.Errorf("command is empty")
+	}
+	return nil
+}
+
+// Format 返回 format 格式的配置
+func Format(format string, j Job) (string, error) {
+	if err := j.Check(format); err != nil {
+		return "", err
+	}
+	switch format {
+	case Cron:
+		return cron(j), nil
+	case Systemd:
+		return systemd(j), nil
+	case Launchd:
+		return launchd(j), nil
+	}
+	return schtasks(j), nil
+}
+
+// hours 间隔的小时数
+func (j Job) hours() int {
+	return int(j.Every.Round(time.Hour) / time.Hour)
+}
+
+// cron crontab 中的一行
+func cron(j Job) string {
+	hours := "*"
+	switch h := j.hours(); {
+	case h == 24:
+		hours = "0"
+	case h > 1:
+		hours = fmt.Sprintf("*/%d", h)
+	}
+	return fmt.Sprintf("# %s\n%d %s * * * %s\n", j.Description, j.Minute, hours, shellQuote(j.Command))
+}
+
+// systemd 用户级的 service 及 timer，分别保存为 ~/.config/systemd/user/<name>.service 及 <name>.timer
+func systemd(j Job) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# ~/.config/systemd/user/%s.service\n", j.Name)
+	fmt.Fprintf(&b, "[Unit]\nDescription=%s\n\n[Service]\nType=oneshot\nExecStart=%s\n\n", j.Description, systemdQuote(j.Command))
+	fmt.Fprintf(&b, "# ~/.config/systemd/user/%s.timer, enable with systemctl --user enable --now %s.timer\n", j.Name, j.Name)
+	fmt.Fprintf(&b, "[Unit]\nDescription=%s\n\n[Timer]\nOnCalendar=*-*-* 00/%d:%02d:00\nPersistent=true\n\n[Install]\nWantedBy=timers.target\n", j.Description, j.hours(), j.Minute)
+	return b.String()
+}
+
+// launchd ~/Library/LaunchAgents/<name>.plist，launchd 按间隔执行，Minute 不起作用
+func launchd(j Job) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<!-- ~/Library/LaunchAgents/%s.plist, load with launchctl load -w ~/Library/LaunchAgents/%s.plist -->\n", j.Name, j.Name)
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+`)
+	fmt.Fprintf(&b, "  <key>Label</key>\n  <string>%s</string>\n  <key>ProgramArguments</key>\n  <array>\n", xmlEscape(j.Name))
+	for _, arg := range j.Command {
+		fmt.Fprintf(&b, "    <string>%s</string>\n", xmlEscape(arg))
+	}
+	fmt.Fprintf(&b, "  </array>\n  <key>StartInterval</key>\n  <integer>%d</integer>\n  <key>RunAtLoad</key>\n  <false/>\n</dict>\n</plist>\n", j.hours()*3600)
+	return b.String()
+}
+
+// schtasks 创建 windows 计划任务的命令
+func schtasks(j Job) string {
+	schedule := fmt.Sprintf("/SC HOURLY /MO %d", j.hours())
+	if j.hours() == 24 {
+		schedule = "/SC DAILY"
+	}
+	quoted := make([]string, 0, len(j.Command))
+	for _, arg := range j.Command {
+		if strings.ContainsAny(arg, ` "`) {
+			arg = `\"` + strings.ReplaceAll(arg, `"`, `\\\"`) + `\"`
+		}
+		quoted = append(quoted, arg)
+	}
+	return fmt.Sprintf("schtasks /Create /F /TN %s %s /ST 00:%02d /TR \"%s\"\n", j.Name, schedule, j.Minute, strings.Join(quoted, " "))
+}
+
+// shellQuote 用单引号引用包含特殊字符的参数
+func shellQuote(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t'\"\\$`&|;<>()*?[]#~%") {
+			arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+		quoted = append(quoted, arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// systemdQuote 用双引号引用包含空白或引号的参数
+func systemdQuote(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\"'\\%") {
+			arg = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%").Replace(arg) + `"`
+		}
+		quoted = append(quoted, arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func xmlEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
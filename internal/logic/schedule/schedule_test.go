@@ -0,0 +1,56 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFormat(t *testing.T) {
+	Convey("生成定时任务配置", t, func() {
+		job := Job{Name: "envm-refresh", Command: []string{"/opt/my tools/envm", "refresh", "--jitter", "10m"}, Every: 6 * time.Hour, Minute: 17, Description: "refresh envm indexes"}
+
+		Convey("cron", func() {
+			out, err := Format(Cron, job)
+			So(err, ShouldBeNil)
+			So(out, ShouldEndWith, "17 */6 * * * '/opt/my tools/envm' refresh --jitter 10m\n")
+			job.Every = 24 * time.Hour
+			out, _ = Format(Cron, job)
+			So(out, ShouldContainSubstring, "17 0 * * * ")
+			job.Every = time.Hour
+			out, _ = Format(Cron, job)
+			So(out, ShouldContainSubstring, "17 * * * * ")
+		})
+
+		Convey("systemd", func() {
+			out, err := Format(Systemd, job)
+			So(err, ShouldBeNil)
+			So(out, ShouldContainSubstring, `ExecStart="/opt/my tools/envm" refresh --jitter 10m`)
+			So(out, ShouldContainSubstring, "OnCalendar=*-*-* 00/6:17:00")
+		})
+
+		Convey("launchd", func() {
+			out, err := Format(Launchd, job)
+			So(err, ShouldBeNil)
+			So(out, ShouldContainSubstring, "<string>/opt/my tools/envm</string>")
+			So(out, ShouldContainSubstring, "<integer>21600</integer>")
+		})
+
+		Convey("schtasks", func() {
+			job.Command[0] = `C:\Program Files\envm\envm.exe`
+			out, err := Format(Schtasks, job)
+			So(err, ShouldBeNil)
+			So(out, ShouldEqual, `schtasks /Create /F /TN envm-refresh /SC HOURLY /MO 6 /ST 00:17 /TR "\"C:\Program Files\envm\envm.exe\" refresh --jitter 10m"`+"\n")
+		})
+
+		Convey("不支持的格式及间隔", func() {
+			_, err := Format("at", job)
+			So(err.Error(), ShouldContainSubstring, "unknown schedule")
+			job.Every = 30 * time.Minute
+			_, err = Format(Cron, job)
+			So(strings.Contains(err.Error(), "1h to 24h"), ShouldBeTrue)
+		})
+	})
+}
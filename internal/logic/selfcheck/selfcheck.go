@@ -0,0 +1,69 @@
+// Package selfcheck 校验 envm 自身的可执行文件是否与 release 中发布的校验和一致，用于发现损坏或被篡改的更新
+package selfcheck
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	web_github "github.com/FirewineXie/envm/internal/logic/web-github"
+	"github.com/FirewineXie/envm/util"
+)
+
+// ChecksumAssets release 中校验文件可能的名称，按顺序查找
+var ChecksumAssets = []string{"checksums.txt", "SHA256SUMS", "sha256sums.txt"}
+
+// ErrMismatch 可执行文件与校验文件中的所有条目都不一致
+var ErrMismatch = fmt.Errorf("%w: the envm binary does not match any published checksum", util.ErrChecksum)
+
+// Sum 计算文件的 sha256
+func Sum(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// ChecksumAsset 返回 release 中的校验文件，没有时返回 nil
+func ChecksumAsset(release *web_github.Release) *web_github.Asset {
+	for _, name := range ChecksumAssets {
+		if asset := release.FindAsset(name); asset != nil {
+			return asset
+		}
+	}
+	return nil
+}
+
+// Match 在 "<checksum>  <filename>" 格式的校验文件中查找与 sum 一致的条目，返回对应的文件名
+func Match(checksums []byte, sum string) (name string, ok bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || !strings.EqualFold(fields[0], sum) {
+			continue
+		}
+		return strings.TrimPrefix(fields[len(fields)-1], "*"), true
+	}
+	return "", false
+}
+
+// Entries 返回校验文件中的条目数量，用于提示校验了多少个发布的文件
+func Entries(checksums []byte) (n int) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		if len(strings.Fields(scanner.Text())) >= 2 {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,38 @@
+package selfcheck
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	web_github "github.com/FirewineXie/envm/internal/logic/web-github"
+	"github.com/FirewineXie/envm/util"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMatch(t *testing.T) {
+	Convey("在校验文件中查找可执行文件", t, func() {
+		file := filepath.Join(t.TempDir(), "envm")
+		So(os.WriteFile(file, []byte("envm"), 0755), ShouldBeNil)
+		sum, err := Sum(file)
+		So(err, ShouldBeNil)
+		So(sum, ShouldEqual, "aea54315db8f99512b9f364c26b37f6109b069545e0cc8dd42abb843efde8021")
+
+		checksums := []byte("0000  envm_linux_arm64\n\n" + sum + " *envm_linux_amd64\n")
+		name, ok := Match(checksums, sum)
+		So(ok, ShouldBeTrue)
+		So(name, ShouldEqual, "envm_linux_amd64")
+		So(Entries(checksums), ShouldEqual, 2)
+
+		_, ok = Match([]byte("0000  envm_linux_amd64\n"), sum)
+		So(ok, ShouldBeFalse)
+		So(errors.Is(ErrMismatch, util.ErrChecksum), ShouldBeTrue)
+	})
+
+	Convey("查找 release 中的校验文件", t, func() {
+		release := &web_github.Release{Assets: []*web_github.Asset{{Name: "envm_linux_amd64"}, {Name: "SHA256SUMS"}}}
+		So(ChecksumAsset(release).Name, ShouldEqual, "SHA256SUMS")
+		So(ChecksumAsset(&web_github.Release{}), ShouldBeNil)
+	})
+}
@@ -0,0 +1,91 @@
+package shell
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Detect 根据 $SHELL 判断用户使用的 shell，windows 上为 PowerShell，无法判断时返回空
+func Detect(goos string, getenv func(string) string) string {
+	switch name := strings.TrimSuffix(filepath.Base(getenv("SHELL")), ".exe"); name {
+	case Bash, Zsh, Fish:
+		return name
+	case "pwsh":
+		return PowerShell
+	}
+	if goos == "windows" {
+		return PowerShell
+	}
+	return ""
+}
+
+// CompletionTarget 安装补全脚本的位置
+type CompletionTarget struct {
+	// File 补全脚本
+	File string
+	// RC 需要加载 File 的启动脚本，bash-completion 及 fish 按命令名自动加载时为空
+	RC string
+}
+
+// CompletionTargets 返回 sh 的补全脚本应该安装的位置
+// bash 使用 bash-completion 的用户目录，fish 使用 completions 目录，都按命令名自动加载
+// zsh 写入数据目录后在 .zshrc 中加载；PowerShell 写入 profile 所在目录后在 profile 中加载，PowerShell 7 及 Windows PowerShell 的 profile 不同
+func CompletionTargets(sh, goos, home string, getenv func(string) string) []CompletionTarget {
+	dataHome := getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	configHome := getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(home, ".config")
+	}
+	switch sh {
+	case Bash:
+		dir := getenv("BASH_COMPLETION_USER_DIR")
+		if dir == "" {
+			dir = filepath.Join(dataHome, "bash-completion")
+		}
+		return []CompletionTarget{{File: filepath.Join(dir, "completions", "envm")}}
+	case Zsh:
+		rcDir := getenv("ZDOTDIR")
+		if rcDir == "" {
+			rcDir = home
+		}
+		return []CompletionTarget{{File: filepath.Join(dataHome, "envm", "completion.zsh"), RC: filepath.Join(rcDir, ".zshrc")}}
+	case Fish:
+		return []CompletionTarget{{File: filepath.Join(configHome, "fish", "completions", "envm.fish")}}
+	case PowerShell:
+		if goos != "windows" {
+			dir := filepath.Join(configHome, "powershell")
+			return []CompletionTarget{{File: filepath.Join(dir, "envm-completion.ps1"), RC: filepath.Join(dir, "Microsoft.PowerShell_profile.ps1")}}
+		}
+		targets := make([]CompletionTarget, 0, 2)
+		for _, edition := range []string{"PowerShell", "WindowsPowerShell"} {
+			dir := filepath.Join(home, "Documents", edition)
+			targets = append(targets, CompletionTarget{File: filepath.Join(dir, "envm-completion.ps1"), RC: filepath.Join(dir, "Microsoft.PowerShell_profile.ps1")})
+		}
+		return targets
+	}
+	return nil
+}
+
+// SourceLine 启动脚本中加载补全脚本的语句，文件不存在时不报错
+func SourceLine(sh, file string) string {
+	if sh == PowerShell {
+		return "if (Test-Path " + Quote(sh, file) + ") { . " + Quote(sh, file) + " } # envm completion"
+	}
+	return "[ -f " + Quote(sh, file) + " ] && source " + Quote(sh, file) + " # envm completion"
+}
+
+// AppendLine content 中没有 line 时追加到末尾，已经存在时原样返回 false，重复执行不会重复添加
+func AppendLine(content, line string) (string, bool) {
+	for _, existing := range strings.Split(content, "\n") {
+		if strings.TrimSpace(strings.TrimSuffix(existing, "\r")) == line {
+			return content, false
+		}
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + line + "\n", true
+}
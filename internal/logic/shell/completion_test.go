@@ -0,0 +1,55 @@
+package shell
+
+import (
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDetect(t *testing.T) {
+	Convey("根据 $SHELL 判断 shell", t, func() {
+		env := func(value string) func(string) string {
+			return func(string) string { return value }
+		}
+		So(Detect("linux", env("/usr/bin/zsh")), ShouldEqual, Zsh)
+		So(Detect("darwin", env("/opt/homebrew/bin/fish")), ShouldEqual, Fish)
+		So(Detect("linux", env("/usr/bin/pwsh")), ShouldEqual, PowerShell)
+		So(Detect("windows", env("")), ShouldEqual, PowerShell)
+		So(Detect("linux", env("/bin/tcsh")), ShouldEqual, "")
+	})
+}
+
+func TestCompletionTargets(t *testing.T) {
+	Convey("补全脚本的位置", t, func() {
+		home := filepath.FromSlash("/home/alice")
+		getenv := func(name string) string {
+			if name == "XDG_CONFIG_HOME" {
+				return filepath.FromSlash("/cfg")
+			}
+			return ""
+		}
+		So(CompletionTargets(Bash, "linux", home, getenv), ShouldResemble,
+			[]CompletionTarget{{File: filepath.FromSlash("/home/alice/.local/share/bash-completion/completions/envm")}})
+		So(CompletionTargets(Fish, "linux", home, getenv), ShouldResemble,
+			[]CompletionTarget{{File: filepath.FromSlash("/cfg/fish/completions/envm.fish")}})
+		So(CompletionTargets(Zsh, "linux", home, getenv), ShouldResemble,
+			[]CompletionTarget{{File: filepath.FromSlash("/home/alice/.local/share/envm/completion.zsh"), RC: filepath.FromSlash("/home/alice/.zshrc")}})
+		So(CompletionTargets(PowerShell, "windows", home, getenv), ShouldHaveLength, 2)
+		So(CompletionTargets(Cmd, "windows", home, getenv), ShouldBeEmpty)
+	})
+}
+
+func TestAppendLine(t *testing.T) {
+	Convey("只添加一次", t, func() {
+		line := SourceLine(Zsh, "/home/alice/.local/share/envm/completion.zsh")
+		So(line, ShouldEqual, "[ -f '/home/alice/.local/share/envm/completion.zsh' ] && source '/home/alice/.local/share/envm/completion.zsh' # envm completion")
+
+		content, changed := AppendLine("export EDITOR=vim", line)
+		So(changed, ShouldBeTrue)
+		So(content, ShouldEqual, "export EDITOR=vim\n"+line+"\n")
+		again, changed := AppendLine(content, line)
+		So(changed, ShouldBeFalse)
+		So(again, ShouldEqual, content)
+	})
+}
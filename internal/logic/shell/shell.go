@@ -0,0 +1,110 @@
+// Package shell 生成不同 shell 中设置环境变量的语句
+package shell
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	Bash       = "bash"
+	Zsh        = "zsh"
+	Fish       = "fish"
+	PowerShell = "powershell"
+	// Cmd cmd.exe，输出可以保存为 .cmd 文件后 call
+	Cmd = "cmd"
+	// Dotenv docker compose 等读取的 .env 文件，每行 KEY=VALUE，不是 shell，不在 Shells 中
+	Dotenv = "dotenv"
+)
+
+// Shells 支持的 shell
+var Shells = []string{Bash, Zsh, Fish, PowerShell, Cmd}
+
+// Supported 判断是否支持该 shell
+func Supported(sh string) bool {
+	for _, s := range Shells {
+		if s == sh {
+			return true
+		}
+	}
+	return false
+}
+
+// Quote 按 shell 的规则给值加上单引号，cmd 没有引号转义，只需要处理 .cmd 文件中的 %
+func Quote(sh, value string) string {
+	if sh == Cmd {
+		return strings.ReplaceAll(value, "%", "%%")
+	}
+	if sh == PowerShell {
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+	if sh == Fish {
+		return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(value) + "'"
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// dotenvQuote .env 中包含空格、# 及引号等字符的值需要加引号，单引号中的内容不会被展开，值中有单引号时使用双引号
+func dotenvQuote(value string) string {
+	if !strings.ContainsAny(value, " \t#'\"$\\") {
+		return value
+	}
+	if !strings.Contains(value, "'") {
+		return "'" + value + "'"
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`).Replace(value) + `"`
+}
+
+// Export 设置环境变量，fish 中 PATH 为列表需要拆开
+func Export(sh, name, value string) string {
+	switch sh {
+	case Dotenv:
+		return name + "=" + dotenvQuote(value)
+	case Cmd:
+		return fmt.Sprintf(`@set "%s=%s"`, name, Quote(sh, value))
+	case PowerShell:
+		return fmt.Sprintf("$env:%s = %s", name, Quote(sh, value))
+	case Fish:
+		if name == "PATH" {
+			items := make([]string, 0)
+			for _, item := range strings.Split(value, string(os.PathListSeparator)) {
+				items = append(items, Quote(sh, item))
+			}
+			return fmt.Sprintf("set -gx PATH %s;", strings.Join(items, " "))
+		}
+		return fmt.Sprintf("set -gx %s %s;", name, Quote(sh, value))
+	default:
+		return fmt.Sprintf("export %s=%s", name, Quote(sh, value))
+	}
+}
+
+// Unset 删除环境变量
+func Unset(sh, name string) string {
+	switch sh {
+	case Cmd:
+		return fmt.Sprintf(`@set "%s="`, name)
+	case PowerShell:
+		return fmt.Sprintf("Remove-Item Env:%s -ErrorAction SilentlyContinue", name)
+	case Fish:
+		return fmt.Sprintf("set -e %s;", name)
+	default:
+		return "unset " + name
+	}
+}
+
+// PrependPath 将 dirs 加到 path 的最前面，并去掉上一次加入的 previous
+func PrependPath(path string, previous, dirs []string) string {
+	separator := string(os.PathListSeparator)
+	removed := map[string]bool{}
+	for _, dir := range append(append([]string{}, previous...), dirs...) {
+		removed[dir] = true
+	}
+	items := append([]string{}, dirs...)
+	for _, item := range strings.Split(path, separator) {
+		if item != "" && !removed[item] {
+			items = append(items, item)
+		}
+	}
+	return strings.Join(items, separator)
+}
@@ -0,0 +1,42 @@
+package shell
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestExport(t *testing.T) {
+	Convey("生成设置环境变量的语句", t, func() {
+		So(Export(Bash, "GOROOT", "/opt/it's"), ShouldEqual, `export GOROOT='/opt/it'\''s'`)
+		So(Export(PowerShell, "GOROOT", `C:\it's`), ShouldEqual, `$env:GOROOT = 'C:\it''s'`)
+		So(Export(Fish, "GOROOT", "/opt/go"), ShouldEqual, "set -gx GOROOT '/opt/go';")
+		So(Unset(Fish, "GOROOT"), ShouldEqual, "set -e GOROOT;")
+		So(Unset(Zsh, "GOROOT"), ShouldEqual, "unset GOROOT")
+		So(Export(Cmd, "GOROOT", `C:\Program Files\100%`), ShouldEqual, `@set "GOROOT=C:\Program Files\100%%"`)
+		So(Unset(Cmd, "GOROOT"), ShouldEqual, `@set "GOROOT="`)
+		So(Export(Dotenv, "GOROOT", "/opt/go"), ShouldEqual, "GOROOT=/opt/go")
+		So(Export(Dotenv, "GOROOT", `C:\Program Files\go`), ShouldEqual, `GOROOT='C:\Program Files\go'`)
+		So(Export(Dotenv, "GOROOT", `/opt/it's $HOME`), ShouldEqual, `GOROOT="/opt/it's \$HOME"`)
+	})
+}
+
+func TestPrependPath(t *testing.T) {
+	Convey("替换上一次加入 PATH 的目录", t, func() {
+		join := func(items ...string) string {
+			return strings.Join(items, string(os.PathListSeparator))
+		}
+		path := join("/envm/go1.21/bin", "/usr/bin", "/bin")
+		So(PrependPath(path, []string{"/envm/go1.21/bin"}, []string{"/envm/go1.22/bin"}), ShouldEqual, join("/envm/go1.22/bin", "/usr/bin", "/bin"))
+		So(PrependPath(path, []string{"/envm/go1.21/bin"}, nil), ShouldEqual, join("/usr/bin", "/bin"))
+	})
+}
+
+func TestExportFishPath(t *testing.T) {
+	Convey("fish 中 PATH 是列表，每个目录单独作为一个元素", t, func() {
+		path := strings.Join([]string{"/envm/go/bin", "/opt/my tools", "/bin"}, string(os.PathListSeparator))
+		So(Export(Fish, "PATH", path), ShouldEqual, "set -gx PATH '/envm/go/bin' '/opt/my tools' '/bin';")
+	})
+}
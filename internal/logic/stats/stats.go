@@ -0,0 +1,137 @@
+// Package stats 本地的使用统计，只保存在 <root>/stats.json，从不上传，供用户调整镜像及并发设置
+package stats
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/FirewineXie/envm/util"
+)
+
+// Stats 累计的计数
+type Stats struct {
+	// Since 开始统计的时间
+	Since time.Time `json:"since"`
+	// Installs 每个语言安装的次数
+	Installs map[string]int `json:"installs"`
+	// IndexHits 远程版本列表直接使用缓存的次数
+	IndexHits int `json:"index_hits"`
+	// IndexMisses 远程版本列表需要访问网络的次数
+	IndexMisses int `json:"index_misses"`
+	// Downloads 按域名统计的下载
+	Downloads map[string]*Transfer `json:"downloads"`
+}
+
+// Transfer 一个域名的下载次数、字节数及耗时
+type Transfer struct {
+	Count   int     `json:"count"`
+	Bytes   int64   `json:"bytes"`
+	Seconds float64 `json:"seconds"`
+}
+
+// Speed 平均下载速度，字节/秒，没有耗时时为 0
+func (t *Transfer) Speed() float64 {
+	if t.Seconds <= 0 {
+		return 0
+	}
+	return float64(t.Bytes) / t.Seconds
+}
+
+// HitRate 版本列表缓存的命中率，没有查询过时 ok 为 false
+func (s *Stats) HitRate() (rate float64, ok bool) {
+	total := s.IndexHits + s.IndexMisses
+	if total == 0 {
+		return 0, false
+	}
+	return float64(s.IndexHits) / float64(total), true
+}
+
+// Hosts 按下载字节数从多到少返回域名
+func (s *Stats) Hosts() []string {
+	hosts := make([]string, 0, len(s.Downloads))
+	for host := range s.Downloads {
+		hosts = append(hosts, host)
+	}
+	sort.Slice(hosts, func(i, j int) bool {
+		if s.Downloads[hosts[i]].Bytes != s.Downloads[hosts[j]].Bytes {
+			return s.Downloads[hosts[i]].Bytes > s.Downloads[hosts[j]].Bytes
+		}
+		return hosts[i] < hosts[j]
+	})
+	return hosts
+}
+
+// AddDownload 累加一次下载
+func (s *Stats) AddDownload(host string, bytes int64, elapsed time.Duration) {
+	t := s.Downloads[host]
+	if t == nil {
+		t = &Transfer{}
+		s.Downloads[host] = t
+	}
+	t.Count++
+	t.Bytes += bytes
+	t.Seconds += elapsed.Seconds()
+}
+
+// Read 读取统计，文件不存在时返回空的统计
+func Read(file string) (*Stats, error) {
+	s := &Stats{}
+	data, err := os.ReadFile(file)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err = json.Unmarshal(data, s); err != nil {
+			return nil, err
+		}
+	}
+	if s.Installs == nil {
+		s.Installs = map[string]int{}
+	}
+	if s.Downloads == nil {
+		s.Downloads = map[string]*Transfer{}
+	}
+	return s, nil
+}
+
+// Update 持有锁读取统计并调用 fn 修改后写回，多个 envm 进程同时更新时不会丢失计数
+// 无法解析的文件重新开始统计
+func Update(file string, fn func(s *Stats)) error {
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return err
+	}
+	unlock, err := util.LockFileWait(file+".lock", func() {})
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	s, err := Read(file)
+	if err != nil {
+		s, _ = Read("")
+	}
+	if s.Since.IsZero() {
+		s.Since = time.Now()
+	}
+	fn(s)
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	if err = os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// Remove 删除统计，下一次更新时重新开始
+func Remove(file string) error {
+	if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package stats
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUpdate(t *testing.T) {
+	Convey("并发更新不会丢失计数", t, func() {
+		file := filepath.Join(t.TempDir(), "stats.json")
+		var wg sync.WaitGroup
+		errs := make([]error, 8)
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs[i] = Update(file, func(s *Stats) { s.Installs["go"]++ })
+			}(i)
+		}
+		wg.Wait()
+		So(errs, ShouldResemble, make([]error, 8))
+		s, err := Read(file)
+		So(err, ShouldBeNil)
+		So(s.Installs["go"], ShouldEqual, 8)
+		So(s.Since.IsZero(), ShouldBeFalse)
+
+		So(Remove(file), ShouldBeNil)
+		s, err = Read(file)
+		So(err, ShouldBeNil)
+		So(s.Installs, ShouldBeEmpty)
+	})
+
+	Convey("无法解析的文件重新开始统计", t, func() {
+		file := filepath.Join(t.TempDir(), "stats.json")
+		So(os.WriteFile(file, []byte("{"), 0644), ShouldBeNil)
+		So(Update(file, func(s *Stats) { s.IndexHits++ }), ShouldBeNil)
+		s, err := Read(file)
+		So(err, ShouldBeNil)
+		So(s.IndexHits, ShouldEqual, 1)
+	})
+}
+
+func TestSummary(t *testing.T) {
+	Convey("命中率及按下载量排序的域名", t, func() {
+		s, _ := Read("")
+		_, ok := s.HitRate()
+		So(ok, ShouldBeFalse)
+		s.IndexHits, s.IndexMisses = 3, 1
+		rate, ok := s.HitRate()
+		So(ok, ShouldBeTrue)
+		So(rate, ShouldEqual, 0.75)
+
+		s.AddDownload("nodejs.org", 10<<20, 2*time.Second)
+		s.AddDownload("golang.google.cn", 60<<20, 4*time.Second)
+		s.AddDownload("golang.google.cn", 60<<20, 8*time.Second)
+		So(s.Hosts(), ShouldResemble, []string{"golang.google.cn", "nodejs.org"})
+		So(s.Downloads["golang.google.cn"].Count, ShouldEqual, 2)
+		So(s.Downloads["golang.google.cn"].Speed(), ShouldEqual, float64(10<<20))
+		So((&Transfer{}).Speed(), ShouldEqual, 0)
+	})
+}
@@ -0,0 +1,76 @@
+// Package suggest 根据编辑距离及前缀为写错的版本号、命令给出 "did you mean" 建议
+package suggest
+
+import (
+	"sort"
+	"strings"
+)
+
+// Closest 返回 candidates 中与 input 最接近的最多 n 个，差别太大的不返回
+// 互为前缀的候选项(1.22 与 1.22.3)优先，其次按编辑距离排序
+func Closest(input string, candidates []string, n int) []string {
+	type scored struct {
+		name     string
+		distance int
+		prefix   bool
+	}
+	limit := len([]rune(input)) / 3
+	if limit < 2 {
+		limit = 2
+	}
+	list := make([]scored, 0)
+	seen := map[string]bool{}
+	for _, candidate := range candidates {
+		if candidate == "" || seen[candidate] {
+			continue
+		}
+		seen[candidate] = true
+		prefix := strings.HasPrefix(candidate, input) || strings.HasPrefix(input, candidate)
+		distance := Distance(input, candidate)
+		if !prefix && distance > limit {
+			continue
+		}
+		list = append(list, scored{candidate, distance, prefix})
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].prefix != list[j].prefix {
+			return list[i].prefix
+		}
+		return list[i].distance < list[j].distance
+	})
+	names := make([]string, 0, n)
+	for i := 0; i < len(list) && i < n; i++ {
+		names = append(names, list[i].name)
+	}
+	return names
+}
+
+// Distance 两个字符串的 Levenshtein 编辑距离
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+// Message 返回 ", did you mean a or b?"，没有建议时返回空
+func Message(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	return ", did you mean " + strings.Join(names, " or ") + "?"
+}
@@ -0,0 +1,29 @@
+package suggest
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestClosest(t *testing.T) {
+	Convey("编辑距离", t, func() {
+		So(Distance("isntall", "install"), ShouldEqual, 2)
+		So(Distance("", "go"), ShouldEqual, 2)
+		So(Distance("1.22.9", "1.22.3"), ShouldEqual, 1)
+	})
+
+	Convey("选择最接近的命令", t, func() {
+		commands := []string{"install", "uninstall", "ls", "lsr", "active"}
+		So(Closest("isntall", commands, 2), ShouldResemble, []string{"install"})
+		So(Closest("lss", commands, 2), ShouldResemble, []string{"ls", "lsr"})
+		So(Closest("deploy", commands, 2), ShouldBeEmpty)
+	})
+
+	Convey("前缀相同的版本优先", t, func() {
+		versions := []string{"1.21.10", "1.22.3", "1.22.2", "1.2.2"}
+		So(Closest("1.22.9", versions, 2), ShouldResemble, []string{"1.22.3", "1.22.2"})
+		So(Closest("1.22", versions, 1), ShouldResemble, []string{"1.22.3"})
+		So(Message([]string{"1.22.3", "1.22.2"}), ShouldEqual, ", did you mean 1.22.3 or 1.22.2?")
+	})
+}
@@ -0,0 +1,144 @@
+// Package syncdir 将目录增量同步到另一个目录，类似 rsync -a --delete，用于通过复制激活版本
+package syncdir
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Stats 一次同步中复制、删除及未变化的文件数量
+type Stats struct {
+	Copied  int
+	Removed int
+	Skipped int
+}
+
+// Sync 使 dst 与 src 的内容一致，dst 不存在时创建
+// 大小及修改时间(精确到秒)都相同的文件视为未变化，其它文件复制并保留权限及修改时间，链接只复制链接本身
+// dst 中 src 没有的文件及目录被删除，dst 根目录下名称在 keep 中的文件除外
+func Sync(src, dst string, keep ...string) (stats Stats, err error) {
+	err = filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		existing, statErr := os.Lstat(target)
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			if statErr == nil && existing.Mode()&os.ModeSymlink != 0 {
+				if old, err := os.Readlink(target); err == nil && old == link {
+					stats.Skipped++
+					return nil
+				}
+			}
+			if statErr == nil {
+				if err = os.RemoveAll(target); err != nil {
+					return err
+				}
+			}
+			stats.Copied++
+			return os.Symlink(link, target)
+		case d.IsDir():
+			if statErr == nil && !existing.IsDir() {
+				if err = os.RemoveAll(target); err != nil {
+					return err
+				}
+			}
+			if err = os.MkdirAll(target, info.Mode().Perm()|0700); err != nil {
+				return err
+			}
+			return os.Chmod(target, info.Mode().Perm()|0700)
+		case statErr == nil && existing.Mode().IsRegular() && existing.Size() == info.Size() && existing.ModTime().Unix() == info.ModTime().Unix():
+			stats.Skipped++
+			return nil
+		default:
+			if statErr == nil && !existing.Mode().IsRegular() {
+				if err = os.RemoveAll(target); err != nil {
+					return err
+				}
+			}
+			stats.Copied++
+			return copyFile(path, target, info)
+		}
+	})
+	if err != nil {
+		return stats, err
+	}
+
+	kept := map[string]bool{}
+	for _, name := range keep {
+		kept[name] = true
+	}
+	err = filepath.WalkDir(dst, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dst, path)
+		if err != nil || rel == "." || kept[rel] {
+			return err
+		}
+		if _, err = os.Lstat(filepath.Join(src, rel)); err == nil {
+			return nil
+		}
+		if err = os.RemoveAll(path); err != nil {
+			return err
+		}
+		stats.Removed++
+		if d.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return stats, err
+}
+
+// copyFile 先写入同一目录下的临时文件再重命名，正在运行的旧文件不会被截断
+func copyFile(src, dst string, info fs.FileInfo) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	tmp := dst + ".envm-tmp"
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		_ = out.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err = out.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	// umask 可能去掉了部分权限
+	if err = os.Chmod(tmp, info.Mode().Perm()); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err = os.Chtimes(tmp, info.ModTime(), info.ModTime()); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err = os.Rename(tmp, dst); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
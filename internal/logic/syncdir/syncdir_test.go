@@ -0,0 +1,63 @@
+package syncdir
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSync(t *testing.T) {
+	Convey("只复制有变化的文件并删除多余的文件", t, func() {
+		src, dst := t.TempDir(), filepath.Join(t.TempDir(), "current")
+		So(os.MkdirAll(filepath.Join(src, "bin"), os.ModePerm), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(src, "bin", "go"), []byte("go1.22.3"), 0755), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(src, "VERSION"), []byte("go1.22.3"), 0644), ShouldBeNil)
+
+		stats, err := Sync(src, dst)
+		So(err, ShouldBeNil)
+		So(stats, ShouldResemble, Stats{Copied: 2})
+		data, err := os.ReadFile(filepath.Join(dst, "bin", "go"))
+		So(err, ShouldBeNil)
+		So(string(data), ShouldEqual, "go1.22.3")
+		if runtime.GOOS != "windows" {
+			info, err := os.Stat(filepath.Join(dst, "bin", "go"))
+			So(err, ShouldBeNil)
+			So(info.Mode().Perm(), ShouldEqual, os.FileMode(0755))
+		}
+
+		stats, err = Sync(src, dst)
+		So(err, ShouldBeNil)
+		So(stats, ShouldResemble, Stats{Skipped: 2})
+
+		later := time.Now().Add(time.Hour)
+		So(os.WriteFile(filepath.Join(src, "VERSION"), []byte("go1.23.0"), 0644), ShouldBeNil)
+		So(os.Chtimes(filepath.Join(src, "VERSION"), later, later), ShouldBeNil)
+		So(os.Remove(filepath.Join(src, "bin", "go")), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(dst, ".envm-source"), []byte(src), 0644), ShouldBeNil)
+		stats, err = Sync(src, dst, ".envm-source")
+		So(err, ShouldBeNil)
+		So(stats, ShouldResemble, Stats{Copied: 1, Removed: 1})
+		data, _ = os.ReadFile(filepath.Join(dst, "VERSION"))
+		So(string(data), ShouldEqual, "go1.23.0")
+		_, err = os.Stat(filepath.Join(dst, "bin", "go"))
+		So(os.IsNotExist(err), ShouldBeTrue)
+		_, err = os.Stat(filepath.Join(dst, ".envm-source"))
+		So(err, ShouldBeNil)
+	})
+
+	Convey("文件与目录互相替换", t, func() {
+		src, dst := t.TempDir(), t.TempDir()
+		So(os.WriteFile(filepath.Join(dst, "lib"), []byte("file"), 0644), ShouldBeNil)
+		So(os.MkdirAll(filepath.Join(src, "lib"), os.ModePerm), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(src, "lib", "a"), []byte("a"), 0644), ShouldBeNil)
+		_, err := Sync(src, dst)
+		So(err, ShouldBeNil)
+		data, err := os.ReadFile(filepath.Join(dst, "lib", "a"))
+		So(err, ShouldBeNil)
+		So(string(data), ShouldEqual, "a")
+	})
+}
@@ -0,0 +1,134 @@
+// Package tabular 列表命令的 --output，table 为对齐的列，tsv/csv 便于交给 cut、awk 或表格软件处理
+package tabular
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// 支持的输出格式
+const (
+	Table = "table"
+	TSV   = "tsv"
+	CSV   = "csv"
+)
+
+// Check 检查 format 是否支持，空字符串视为 table
+func Check(format string) error {
+	switch format {
+	case "", Table, TSV, CSV:
+		return nil
+	}
+	return fmt.Errorf("unsupported output format %q, use %s, %s or %s", format, Table, TSV, CSV)
+}
+
+// tsvEscape tsv 的字段中不能出现制表符及换行，替换为空格
+var tsvEscape = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+
+// Write 按 format 输出表头及各行，tsv/csv 的第一行为表头
+func Write(w io.Writer, format string, header []string, rows [][]string) error {
+	if err := Check(format); err != nil {
+		return err
+	}
+	switch format {
+	case CSV:
+		writer := csv.NewWriter(w)
+		_ = writer.Write(header)
+		_ = writer.WriteAll(rows)
+		return writer.Error()
+	case TSV:
+		for _, row := range append([][]string{header}, rows...) {
+			fields := make([]string, len(row))
+			for i, field := range row {
+				fields[i] = tsvEscape.Replace(field)
+			}
+			if _, err := fmt.Fprintln(w, strings.Join(fields, "\t")); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return Render(w, append([][]string{header}, rows...), 0)
+}
+
+// padding table 中列之间的空格数
+const padding = 2
+
+// minWidth 截断后每列至少保留的宽度，本来就更窄的列不截断
+const minWidth = 20
+
+// ellipsis 截断时替换中间部分的省略号
+const ellipsis = "..."
+
+// Render 输出对齐的列，width 大于 0 时从最宽的列开始截断中间部分，使每行不超过 width，末尾的空列不输出
+func Render(w io.Writer, rows [][]string, width int) error {
+	writer := tabwriter.NewWriter(w, 0, 4, padding, ' ', 0)
+	for _, row := range Fit(rows, width) {
+		for len(row) > 0 && row[len(row)-1] == "" {
+			row = row[:len(row)-1]
+		}
+		_, _ = fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	return writer.Flush()
+}
+
+// Fit 计算每列的宽度，总宽度超过 width 时每次缩小当前最宽的列，直到不超过 width 或所有列都已经缩小到 minWidth
+// 返回截断后的副本，width 不大于 0 时原样返回
+func Fit(rows [][]string, width int) [][]string {
+	if width <= 0 {
+		return rows
+	}
+	widths := make([]int, 0)
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			if n := len([]rune(cell)); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	total := padding * (len(widths) - 1)
+	for _, n := range widths {
+		total += n
+	}
+	for total > width {
+		widest := 0
+		for i, n := range widths {
+			if n > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= minWidth {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+	fitted := make([][]string, len(rows))
+	for i, row := range rows {
+		fitted[i] = make([]string, len(row))
+		for j, cell := range row {
+			fitted[i][j] = Truncate(cell, widths[j])
+		}
+	}
+	return fitted
+}
+
+// Truncate 超过 width 个字符时保留开头及结尾，中间替换为省略号，URL 及路径的两端通常最有辨识度
+func Truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	if width <= len(ellipsis) {
+		return string(runes[:width])
+	}
+	keep := width - len(ellipsis)
+	head := (keep + 1) / 2
+	return string(runes[:head]) + ellipsis + string(runes[len(runes)-(keep-head):])
+}
@@ -0,0 +1,53 @@
+package tabular
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWrite(t *testing.T) {
+	Convey("按格式输出列表", t, func() {
+		header := []string{"LANGUAGE", "VERSION", "SOURCE"}
+		rows := [][]string{{"go", "1.22.3", "/home/me/app/.go-version"}, {"node", "20.12.2", "a,b\tc"}}
+
+		var buf strings.Builder
+		So(Write(&buf, TSV, header, rows), ShouldBeNil)
+		So(buf.String(), ShouldEqual, "LANGUAGE\tVERSION\tSOURCE\ngo\t1.22.3\t/home/me/app/.go-version\nnode\t20.12.2\ta,b c\n")
+
+		buf.Reset()
+		So(Write(&buf, CSV, header, rows), ShouldBeNil)
+		So(buf.String(), ShouldEqual, "LANGUAGE,VERSION,SOURCE\ngo,1.22.3,/home/me/app/.go-version\nnode,20.12.2,\"a,b\tc\"\n")
+
+		buf.Reset()
+		So(Write(&buf, "", header, rows[:1]), ShouldBeNil)
+		So(buf.String(), ShouldEqual, "LANGUAGE  VERSION  SOURCE\ngo        1.22.3   /home/me/app/.go-version\n")
+
+		So(Write(&buf, "json", header, rows), ShouldNotBeNil)
+		So(Check(TSV), ShouldBeNil)
+	})
+}
+
+func TestFit(t *testing.T) {
+	Convey("截断中间部分适应终端宽度", t, func() {
+		So(Truncate("https://example.com/go1.22.3.tar.gz", 20), ShouldEqual, "https://e...3.tar.gz")
+		So(Truncate("1.22.3", 20), ShouldEqual, "1.22.3")
+		So(Truncate("abcdef", 2), ShouldEqual, "ab")
+
+		rows := [][]string{{"go", "1.22.3", "https://go.dev/dl/go1.22.3.linux-amd64.tar.gz"}}
+		So(Fit(rows, 0), ShouldResemble, rows)
+		So(Fit(rows, 80), ShouldResemble, rows)
+		fitted := Fit(rows, 40)
+		So(fitted[0][:2], ShouldResemble, rows[0][:2])
+		So(fitted[0][2], ShouldEqual, "https://go.de...amd64.tar.gz")
+		So(rows[0][2], ShouldEqual, "https://go.dev/dl/go1.22.3.linux-amd64.tar.gz")
+
+		// 各列都缩小到 minWidth 后不再截断
+		So(Fit(rows, 5)[0][2], ShouldHaveLength, minWidth)
+
+		var buf strings.Builder
+		So(Render(&buf, [][]string{{"a", "b", ""}, {"ccc", "", "d"}}, 0), ShouldBeNil)
+		So(buf.String(), ShouldEqual, "a    b\nccc    d\n")
+	})
+}
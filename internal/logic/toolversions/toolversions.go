@@ -0,0 +1,110 @@
+// Package toolversions 读写 asdf 的 .tool-versions 文件
+//
+// 每行格式为 "<name> <version> [<version>...]"，# 之后为注释，多个版本时优先使用第一个
+package toolversions
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"strings"
+)
+
+// FileName asdf 版本文件名
+const FileName = ".tool-versions"
+
+// Entry 单个工具的版本声明
+type Entry struct {
+	Name     string
+	Versions []string
+}
+
+// Version 返回优先使用的版本
+func (e Entry) Version() string {
+	if len(e.Versions) == 0 {
+		return ""
+	}
+	return e.Versions[0]
+}
+
+// File 保留原始行的 .tool-versions 内容，写回时不影响注释及无关的行
+type File struct {
+	lines []string
+}
+
+// Parse 解析 .tool-versions 内容
+func Parse(r io.Reader) (*File, error) {
+	f := &File{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		f.lines = append(f.lines, scanner.Text())
+	}
+	return f, scanner.Err()
+}
+
+// Read 读取文件，文件不存在时返回空内容
+func Read(filename string) (*File, error) {
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return &File{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return Parse(bytes.NewReader(data))
+}
+
+// parseLine 解析单行，空行及纯注释行返回 false
+func parseLine(line string) (Entry, bool) {
+	if i := strings.Index(line, "#"); i >= 0 {
+		line = line[:i]
+	}
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return Entry{}, false
+	}
+	return Entry{Name: fields[0], Versions: fields[1:]}, true
+}
+
+// Entries 返回所有版本声明，保持文件中的顺序
+func (f *File) Entries() (entries []Entry) {
+	for _, line := range f.lines {
+		if entry, ok := parseLine(line); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Set 设置工具的版本，已存在时替换该行(保留行尾注释)，否则追加到末尾
+func (f *File) Set(name, version string) {
+	for i, line := range f.lines {
+		entry, ok := parseLine(line)
+		if !ok || entry.Name != name {
+			continue
+		}
+		newLine := name + " " + version
+		if j := strings.Index(line, "#"); j >= 0 {
+			newLine += " " + line[j:]
+		}
+		f.lines[i] = newLine
+		return
+	}
+	f.lines = append(f.lines, name+" "+version)
+}
+
+// Bytes 返回文件内容
+func (f *File) Bytes() []byte {
+	var buf bytes.Buffer
+	for _, line := range f.lines {
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// Write 写入文件
+func (f *File) Write(filename string) error {
+	return os.WriteFile(filename, f.Bytes(), 0644)
+}
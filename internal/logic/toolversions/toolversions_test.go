@@ -0,0 +1,27 @@
+package toolversions
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFile(t *testing.T) {
+	Convey("解析 .tool-versions", t, func() {
+		content := "# team versions\ngolang 1.21.5\nnodejs 20.10.0 18.19.0 # lts\n\nruby\n"
+		f, err := Parse(strings.NewReader(content))
+		So(err, ShouldBeNil)
+
+		entries := f.Entries()
+		So(len(entries), ShouldEqual, 2)
+		So(entries[0], ShouldResemble, Entry{Name: "golang", Versions: []string{"1.21.5"}})
+		So(entries[1].Version(), ShouldEqual, "20.10.0")
+
+		Convey("写回时保留注释及无关的行", func() {
+			f.Set("nodejs", "20.11.0")
+			f.Set("zig", "0.11.0")
+			So(string(f.Bytes()), ShouldEqual, "# team versions\ngolang 1.21.5\nnodejs 20.11.0 # lts\n\nruby\nzig 0.11.0\n")
+		})
+	})
+}
@@ -0,0 +1,91 @@
+// Package trial 记录 envm try 正在试用的版本，试用只影响 envm try 启动的 shell，记录用于列出及避免同一语言同时试用
+package trial
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Trial 一个语言正在试用的版本
+type Trial struct {
+	Language string `json:"language"`
+	Version  string `json:"version"`
+	// Previous 试用开始时全局激活的版本，其它 shell 继续使用该版本
+	Previous string    `json:"previous"`
+	Started  time.Time `json:"started"`
+	// Until 到期时间，为空表示直到 shell 退出
+	Until time.Time `json:"until,omitempty"`
+	PID   int       `json:"pid"`
+}
+
+// File 记录文件 <dir>/<language>.json
+func File(dir, language string) string {
+	return filepath.Join(dir, language+".json")
+}
+
+// LockFile 试用期间 envm try 一直持有的锁，锁可以被获取说明试用的进程已经退出
+func LockFile(dir, language string) string {
+	return filepath.Join(dir, language+".lock")
+}
+
+// Read 读取语言的试用记录，没有试用时返回 os.ErrNotExist
+func Read(dir, language string) (*Trial, error) {
+	data, err := os.ReadFile(File(dir, language))
+	if err != nil {
+		return nil, err
+	}
+	t := &Trial{}
+	if err = json.Unmarshal(data, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Write 写入试用记录，先写临时文件再重命名，其它进程不会读到一半的内容
+func Write(dir string, t *Trial) error {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := File(dir, t.Language) + ".tmp"
+	if err = os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, File(dir, t.Language))
+}
+
+// Remove 删除试用记录，不存在时忽略
+func Remove(dir, language string) error {
+	if err := os.Remove(File(dir, language)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List 返回所有试用记录，按语言排序，目录不存在时返回空，无法解析的记录被忽略
+func List(dir string) []*Trial {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	trials := make([]*Trial, 0)
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		if t, err := Read(dir, name); err == nil {
+			trials = append(trials, t)
+		}
+	}
+	sort.Slice(trials, func(i, j int) bool { return trials[i].Language < trials[j].Language })
+	return trials
+}
@@ -0,0 +1,31 @@
+package trial
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTrial(t *testing.T) {
+	Convey("写入后可以读取及列出，删除后不存在", t, func() {
+		dir := t.TempDir()
+		So(List(dir), ShouldBeEmpty)
+		started := time.Now().Truncate(time.Second)
+		So(Write(dir, &Trial{Language: "go", Version: "1.23rc1", Previous: "1.22.3", Started: started, Until: started.Add(2 * time.Hour), PID: 42}), ShouldBeNil)
+		So(Write(dir, &Trial{Language: "bun", Version: "1.1.0", Previous: "1.0.0", Started: started}), ShouldBeNil)
+		So(os.WriteFile(File(dir, "broken"), []byte("{"), 0644), ShouldBeNil)
+
+		trials := List(dir)
+		So(len(trials), ShouldEqual, 2)
+		So(trials[0].Language, ShouldEqual, "bun")
+		So(trials[1].Previous, ShouldEqual, "1.22.3")
+		So(trials[1].Until.Equal(started.Add(2*time.Hour)), ShouldBeTrue)
+
+		So(Remove(dir, "go"), ShouldBeNil)
+		So(Remove(dir, "go"), ShouldBeNil)
+		_, err := Read(dir, "go")
+		So(os.IsNotExist(err), ShouldBeTrue)
+	})
+}
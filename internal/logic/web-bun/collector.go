@@ -0,0 +1,85 @@
+package web_bun
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/logic/web-github"
+	"github.com/FirewineXie/envm/util"
+)
+
+const (
+	// Repo bun 的 github 仓库
+	Repo = "oven-sh/bun"
+	// tagPrefix bun 的 tag 格式为 bun-v1.1.8
+	tagPrefix = "bun-v"
+	// checksumAsset 每个 release 附带的校验文件
+	checksumAsset = "SHASUMS256.txt"
+)
+
+// targets GOOS/GOARCH 与 bun 发布包 target 的对应关系
+var targets = map[string]string{
+	"linux/amd64":   "linux-x64",
+	"linux/arm64":   "linux-aarch64",
+	"darwin/amd64":  "darwin-x64",
+	"darwin/arm64":  "darwin-aarch64",
+	"windows/amd64": "windows-x64",
+}
+
+type VersionBun struct {
+	util.Version
+}
+
+// AllVersions 返回所有已发布的版本，canary 等非版本号 tag 会被忽略
+func AllVersions() (items []*VersionBun, err error) {
+	collector, err := web_github.NewCollector(Repo)
+	if err != nil {
+		return nil, err
+	}
+	for _, release := range collector.Releases() {
+		if !strings.HasPrefix(release.TagName, tagPrefix) {
+			continue
+		}
+		items = append(items, convert(release))
+	}
+	return items, nil
+}
+
+func convert(release *web_github.Release) *VersionBun {
+	v := &VersionBun{}
+	v.Name = strings.TrimPrefix(release.TagName, tagPrefix)
+	sum := release.FindAsset(checksumAsset)
+	for key, target := range targets {
+		asset := release.FindAsset("bun-" + target + ".zip")
+		if asset == nil {
+			continue
+		}
+		split := strings.Split(key, "/")
+		pkg := &util.Package{
+			// zip 包内的目录名
+			FileName:    "bun-" + target,
+			ArchiveName: "bun" + v.Name + ".zip",
+			URL:         asset.URL,
+			Size:        strconv.FormatInt(asset.Size, 10),
+			Kind:        util.ArchiveKind,
+			OS:          split[0],
+			Arch:        split[1],
+			Algorithm:   "SHA256",
+		}
+		if sum != nil {
+			pkg.ChecksumURL = sum.URL
+		}
+		v.Packages = append(v.Packages, pkg)
+	}
+	return v
+}
+
+// FindPackage 返回指定操作系统和硬件架构的版本包
+func (v *VersionBun) FindPackage(kind, goos, goarch string) (*util.Package, error) {
+	for _, pkg := range v.Packages {
+		if pkg.Kind == kind && pkg.OS == goos && pkg.Arch == goarch {
+			return pkg, nil
+		}
+	}
+	return nil, util.ErrPackageNotFound
+}
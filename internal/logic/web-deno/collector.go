@@ -0,0 +1,88 @@
+package web_deno
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/logic/web-github"
+	"github.com/FirewineXie/envm/util"
+)
+
+const (
+	// Repo deno 的 github 仓库
+	Repo = "denoland/deno"
+)
+
+// targets GOOS/GOARCH 与 deno 发布包 target 的对应关系
+var targets = map[string]string{
+	"linux/amd64":   "x86_64-unknown-linux-gnu",
+	"linux/arm64":   "aarch64-unknown-linux-gnu",
+	"darwin/amd64":  "x86_64-apple-darwin",
+	"darwin/arm64":  "aarch64-apple-darwin",
+	"windows/amd64": "x86_64-pc-windows-msvc",
+}
+
+type VersionDeno struct {
+	util.Version
+}
+
+// AllVersions 返回所有已发布的版本
+func AllVersions() (items []*VersionDeno, err error) {
+	collector, err := web_github.NewCollector(Repo)
+	if err != nil {
+		return nil, err
+	}
+	for _, release := range collector.Releases() {
+		items = append(items, convert(release))
+	}
+	return items, nil
+}
+
+func convert(release *web_github.Release) *VersionDeno {
+	v := &VersionDeno{}
+	v.Name = strings.TrimPrefix(release.TagName, "v")
+	for _, asset := range release.Assets {
+		if !strings.HasPrefix(asset.Name, "deno-") || !strings.HasSuffix(asset.Name, ".zip") {
+			continue
+		}
+		target := strings.TrimSuffix(strings.TrimPrefix(asset.Name, "deno-"), ".zip")
+		goos, goarch := parseTarget(target)
+		if goos == "" {
+			continue
+		}
+		pkg := &util.Package{
+			ArchiveName: "deno" + v.Name + ".zip",
+			URL:         asset.URL,
+			Size:        strconv.FormatInt(asset.Size, 10),
+			Kind:        util.ArchiveKind,
+			OS:          goos,
+			Arch:        goarch,
+			Algorithm:   "SHA256",
+		}
+		if sum := release.FindAsset(asset.Name + ".sha256sum"); sum != nil {
+			pkg.ChecksumURL = sum.URL
+		}
+		v.Packages = append(v.Packages, pkg)
+	}
+	return v
+}
+
+func parseTarget(target string) (goos, goarch string) {
+	for key, value := range targets {
+		if value == target {
+			split := strings.Split(key, "/")
+			return split[0], split[1]
+		}
+	}
+	return "", ""
+}
+
+// FindPackage 返回指定操作系统和硬件架构的版本包
+func (v *VersionDeno) FindPackage(kind, goos, goarch string) (*util.Package, error) {
+	for _, pkg := range v.Packages {
+		if pkg.Kind == kind && pkg.OS == goos && pkg.Arch == goarch {
+			return pkg, nil
+		}
+	}
+	return nil, util.ErrPackageNotFound
+}
@@ -0,0 +1,35 @@
+package web_deno
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/FirewineXie/envm/internal/logic/web-github"
+	"github.com/FirewineXie/envm/util"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestConvert(t *testing.T) {
+	Convey("转换 github release", t, func() {
+		release := &web_github.Release{
+			TagName: "v1.43.1",
+			Assets: []*web_github.Asset{
+				{Name: "deno-x86_64-unknown-linux-gnu.zip", URL: "https://example.com/deno-x86_64-unknown-linux-gnu.zip"},
+				{Name: "deno-x86_64-unknown-linux-gnu.zip.sha256sum", URL: "https://example.com/sum"},
+				{Name: "denort-x86_64-unknown-linux-gnu.zip"},
+				{Name: "lib.deno.d.ts"},
+			},
+		}
+		v := convert(release)
+		So(v.Name, ShouldEqual, "1.43.1")
+		So(len(v.Packages), ShouldEqual, 1)
+
+		pkg, err := v.FindPackage(util.ArchiveKind, "linux", "amd64")
+		So(err, ShouldBeNil)
+		So(pkg.ChecksumURL, ShouldEqual, "https://example.com/sum")
+		So(pkg.ArchiveName, ShouldEqual, "deno1.43.1.zip")
+
+		_, err = v.FindPackage(util.ArchiveKind, runtime.GOOS, "mips")
+		So(err, ShouldEqual, util.ErrPackageNotFound)
+	})
+}
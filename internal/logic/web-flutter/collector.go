@@ -0,0 +1,151 @@
+package web_flutter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+
+	"github.com/FirewineXie/envm/util"
+)
+
+const (
+	// DefaultURL flutter 官方发布信息，每个平台一个 json 文件
+	DefaultURL = "https://storage.googleapis.com/flutter_infra_release/releases/releases_%s.json"
+	// Stable 稳定渠道
+	Stable = "stable"
+	// Beta 测试渠道
+	Beta = "beta"
+)
+
+// osNames GOOS 与 flutter 平台名称的对应关系
+var osNames = map[string]string{
+	"linux":   "linux",
+	"darwin":  "macos",
+	"windows": "windows",
+}
+
+// archNames GOARCH 与 dart_sdk_arch 的对应关系
+var archNames = map[string]string{
+	"amd64": "x64",
+	"arm64": "arm64",
+}
+
+// release 发布信息中的单个版本
+type release struct {
+	Hash           string `json:"hash"`
+	Channel        string `json:"channel"`
+	Version        string `json:"version"`
+	DartSdkVersion string `json:"dart_sdk_version"`
+	DartSdkArch    string `json:"dart_sdk_arch"`
+	ReleaseDate    string `json:"release_date"`
+	Archive        string `json:"archive"`
+	Sha256         string `json:"sha256"`
+}
+
+type index struct {
+	BaseURL        string            `json:"base_url"`
+	CurrentRelease map[string]string `json:"current_release"`
+	Releases       []release         `json:"releases"`
+}
+
+type VersionFlutter struct {
+	util.Version
+	Channel string
+	// DartVersion 内置的 dart sdk 版本
+	DartVersion string
+}
+
+type Collector struct {
+	url   string
+	index index
+}
+
+// NewCollector 返回指定操作系统的采集器实例
+func NewCollector(goos string) (*Collector, error) {
+	name, ok := osNames[goos]
+	if !ok {
+		return nil, util.ErrPackageNotFound
+	}
+	c := Collector{
+		url: fmt.Sprintf(DefaultURL, name),
+	}
+	resp, err := http.Get(c.url)
+	if err != nil {
+		return nil, util.NewNetworkError(c.url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, util.NewStatusError(c.url, resp.Status)
+	}
+	return &c, c.parse(resp.Body)
+}
+
+func (c *Collector) parse(r io.Reader) error {
+	return json.NewDecoder(r).Decode(&c.index)
+}
+
+// ChannelVersions 返回指定渠道的版本，channel 为空时返回 stable 与 beta
+// 同一个版本在 macos 上会有 x64 与 arm64 两个包，合并为一个版本
+func (c *Collector) ChannelVersions(channel string) (items []*VersionFlutter) {
+	versions := make(map[string]*VersionFlutter)
+	for _, r := range c.index.Releases {
+		if channel == "" && r.Channel != Stable && r.Channel != Beta {
+			continue
+		}
+		if channel != "" && r.Channel != channel {
+			continue
+		}
+		v, ok := versions[r.Version]
+		if !ok {
+			v = &VersionFlutter{Channel: r.Channel, DartVersion: r.DartSdkVersion}
+			v.Name = r.Version
+			versions[r.Version] = v
+			items = append(items, v)
+		}
+		arch := r.DartSdkArch
+		if arch == "" {
+			arch = "x64"
+		}
+		v.Packages = append(v.Packages, &util.Package{
+			// 压缩包内固定为 flutter 目录
+			FileName:    "flutter",
+			ArchiveName: path.Base(r.Archive),
+			URL:         c.index.BaseURL + "/" + r.Archive,
+			Kind:        util.ArchiveKind,
+			Arch:        arch,
+			Checksum:    r.Sha256,
+			Algorithm:   "SHA256",
+		})
+	}
+	return items
+}
+
+// FindVersion 查找版本，name 为 stable/beta 时返回该渠道的当前版本
+func (c *Collector) FindVersion(name string) (*VersionFlutter, error) {
+	if hash, ok := c.index.CurrentRelease[name]; ok {
+		for _, r := range c.index.Releases {
+			if r.Hash == hash {
+				name = r.Version
+				break
+			}
+		}
+	}
+	for _, v := range c.ChannelVersions("") {
+		if v.Name == name {
+			return v, nil
+		}
+	}
+	return nil, util.ErrVersionNotFound
+}
+
+// FindPackage 返回指定硬件架构的版本包，操作系统已经由采集器确定
+func (v *VersionFlutter) FindPackage(kind, goos, goarch string) (*util.Package, error) {
+	for _, pkg := range v.Packages {
+		if pkg.Kind == kind && pkg.Arch == archNames[goarch] {
+			return pkg, nil
+		}
+	}
+	return nil, util.ErrPackageNotFound
+}
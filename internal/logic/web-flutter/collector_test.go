@@ -0,0 +1,55 @@
+package web_flutter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/FirewineXie/envm/util"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const releases = `{
+  "base_url": "https://storage.googleapis.com/flutter_infra_release/releases",
+  "current_release": {"beta": "b1", "dev": "d1", "stable": "s2"},
+  "releases": [
+    {"hash": "b1", "channel": "beta", "version": "3.23.0-0.1.pre", "dart_sdk_version": "3.5.0", "dart_sdk_arch": "arm64", "archive": "beta/macos/flutter_macos_arm64_3.23.0-0.1.pre-beta.zip", "sha256": "aaa"},
+    {"hash": "s2", "channel": "stable", "version": "3.22.0", "dart_sdk_version": "3.4.0", "dart_sdk_arch": "arm64", "archive": "stable/macos/flutter_macos_arm64_3.22.0-stable.zip", "sha256": "bbb"},
+    {"hash": "s2", "channel": "stable", "version": "3.22.0", "dart_sdk_version": "3.4.0", "dart_sdk_arch": "x64", "archive": "stable/macos/flutter_macos_3.22.0-stable.zip", "sha256": "ccc"},
+    {"hash": "d1", "channel": "dev", "version": "2.11.0-0.1.pre", "archive": "dev/macos/flutter_macos_2.11.0-0.1.pre-dev.zip", "sha256": "ddd"},
+    {"hash": "s1", "channel": "stable", "version": "1.0.0", "archive": "stable/macos/flutter_macos_v1.0.0-stable.zip", "sha256": "eee"}
+  ]
+}`
+
+func getCollector() *Collector {
+	c := &Collector{}
+	if err := c.parse(strings.NewReader(releases)); err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func TestChannelVersions(t *testing.T) {
+	Convey("按渠道查询flutter版本", t, func() {
+		c := getCollector()
+		So(len(c.ChannelVersions("")), ShouldEqual, 3)
+		So(len(c.ChannelVersions(Stable)), ShouldEqual, 2)
+		So(len(c.ChannelVersions(Beta)), ShouldEqual, 1)
+	})
+}
+
+func TestFindVersion(t *testing.T) {
+	Convey("查找版本及安装包", t, func() {
+		v, err := getCollector().FindVersion(Stable)
+		So(err, ShouldBeNil)
+		So(v.Name, ShouldEqual, "3.22.0")
+		So(v.DartVersion, ShouldEqual, "3.4.0")
+
+		pkg, err := v.FindPackage(util.ArchiveKind, "darwin", "amd64")
+		So(err, ShouldBeNil)
+		So(pkg.Checksum, ShouldEqual, "ccc")
+		So(pkg.URL, ShouldEqual, "https://storage.googleapis.com/flutter_infra_release/releases/stable/macos/flutter_macos_3.22.0-stable.zip")
+
+		_, err = getCollector().FindVersion("2.11.0-0.1.pre")
+		So(err, ShouldEqual, util.ErrVersionNotFound)
+	})
+}
@@ -0,0 +1,198 @@
+package web_github
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/FirewineXie/envm/util"
+)
+
+const (
+	// DefaultURL github releases api 地址
+	DefaultURL = "https://api.github.com/repos/"
+	// pages 最多拉取的分页数量
+	pages = 3
+)
+
+// Asset release 中的单个附件
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+	Size int64  `json:"size"`
+	// Digest github 计算的附件摘要，格式为 sha256:<hex>，较早上传的附件为空
+	Digest string `json:"digest"`
+}
+
+// SHA256 返回 Digest 中的 sha256 校验和，没有时返回空
+func (a *Asset) SHA256() string {
+	sum, ok := strings.CutPrefix(a.Digest, "sha256:")
+	if !ok {
+		return ""
+	}
+	return strings.ToLower(sum)
+}
+
+// Release github release 信息
+type Release struct {
+	TagName    string   `json:"tag_name"`
+	Draft      bool     `json:"draft"`
+	Prerelease bool     `json:"prerelease"`
+	HtmlURL    string   `json:"html_url"`
+	Assets     []*Asset `json:"assets"`
+}
+
+// FindAsset 根据附件名称查找
+func (r *Release) FindAsset(name string) *Asset {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset
+		}
+	}
+	return nil
+}
+
+type Collector struct {
+	url      string
+	releases []*Release
+}
+
+// NewCollector 返回采集器实例, repo 格式为 owner/name
+func NewCollector(repo string) (*Collector, error) {
+	defer util.Phase(util.PhaseResolve)()
+	c := Collector{
+		url: DefaultURL + repo + "/releases",
+	}
+	for page := 1; page <= pages; page++ {
+		releases, err := c.loadPage(page)
+		if err != nil {
+			return nil, err
+		}
+		c.releases = append(c.releases, releases...)
+		if len(releases) < 100 {
+			break
+		}
+	}
+	return &c, nil
+}
+
+func (c *Collector) loadPage(page int) (releases []*Release, err error) {
+	url := fmt.Sprintf("%s?per_page=100&page=%d", c.url, page)
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, NewURLUnreachableError(url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, NewURLUnreachableError(url, nil)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&releases)
+	return releases, err
+}
+
+// FindRelease 查询指定 tag 的 release
+func FindRelease(repo, tag string) (*Release, error) {
+	url := DefaultURL + repo + "/releases/tags/" + tag
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, NewURLUnreachableError(url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, util.ErrVersionNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, NewURLUnreachableError(url, nil)
+	}
+	var release Release
+	err = json.NewDecoder(resp.Body).Decode(&release)
+	return &release, err
+}
+
+// Releases 返回所有非草稿的 release
+func (c *Collector) Releases() (items []*Release) {
+	for _, release := range c.releases {
+		if release.Draft {
+			continue
+		}
+		items = append(items, release)
+	}
+	return items
+}
+
+// URLUnreachableError URL不可达错误
+type URLUnreachableError struct {
+	err error
+	url string
+}
+
+// NewURLUnreachableError 返回URL不可达错误实例
+func NewURLUnreachableError(url string, err error) error {
+	return &URLUnreachableError{
+		err: err,
+		url: url,
+	}
+}
+
+func (e *URLUnreachableError) Error() string {
+	var buf strings.Builder
+	buf.WriteString(fmt.Sprintf("URL %q is unreachable", e.url))
+	if e.err != nil {
+		buf.WriteString(" ==> " + e.err.Error())
+	}
+	return buf.String()
+}
+
+// URL 不可达的地址
+func (e *URLUnreachableError) URL() string {
+	return e.url
+}
+
+// Unwrap errors.Is(err, util.ErrNetwork) 成立，同时可以判断原始错误
+func (e *URLUnreachableError) Unwrap() []error {
+	return util.NetworkCauses(e.err)
+}
+
+// FetchChecksum 下载 sha256sum 格式的校验文件，返回指定文件名的校验和
+// 文件中只有一行且没有文件名时，直接返回该行的校验和
+func FetchChecksum(url, fileName string) (string, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return "", NewURLUnreachableError(url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", NewURLUnreachableError(url, nil)
+	}
+	return ParseChecksum(bufio.NewScanner(resp.Body), fileName)
+}
+
+// ParseChecksum 解析 "<checksum>  <filename>" 格式的内容
+func ParseChecksum(scanner *bufio.Scanner, fileName string) (string, error) {
+	var single string
+	lines := 0
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		lines++
+		if len(fields) == 1 {
+			single = fields[0]
+			continue
+		}
+		if strings.TrimPrefix(fields[len(fields)-1], "*") == fileName {
+			return strings.ToLower(fields[0]), nil
+		}
+		single = fields[0]
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	if lines == 1 && single != "" {
+		return strings.ToLower(single), nil
+	}
+	return "", util.ErrPackageNotFound
+}
@@ -0,0 +1,35 @@
+package web_github
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/FirewineXie/envm/util"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseChecksum(t *testing.T) {
+	Convey("解析校验文件", t, func() {
+		content := "aaa  bun-linux-x64.zip\nBBB *bun-darwin-x64.zip\n"
+
+		sum, err := ParseChecksum(bufio.NewScanner(strings.NewReader(content)), "bun-darwin-x64.zip")
+		So(err, ShouldBeNil)
+		So(sum, ShouldEqual, "bbb")
+
+		_, err = ParseChecksum(bufio.NewScanner(strings.NewReader(content)), "bun-windows-x64.zip")
+		So(err, ShouldEqual, util.ErrPackageNotFound)
+
+		sum, err = ParseChecksum(bufio.NewScanner(strings.NewReader("ccc\n")), "deno.zip")
+		So(err, ShouldBeNil)
+		So(sum, ShouldEqual, "ccc")
+	})
+}
+
+func TestAssetSHA256(t *testing.T) {
+	Convey("附件摘要", t, func() {
+		So((&Asset{Digest: "sha256:ABCdef"}).SHA256(), ShouldEqual, "abcdef")
+		So((&Asset{Digest: "sha512:abc"}).SHA256(), ShouldBeEmpty)
+		So((&Asset{}).SHA256(), ShouldBeEmpty)
+	})
+}
@@ -37,9 +37,26 @@ func (e *URLUnreachableError) Error() string {
 	return buf.String()
 }
 
+// URL 不可达的地址
+func (e *URLUnreachableError) URL() string {
+	return e.url
+}
+
+// Unwrap errors.Is(err, util.ErrNetwork) 成立，同时可以判断原始错误
+func (e *URLUnreachableError) Unwrap() []error {
+	return util.NetworkCauses(e.err)
+}
+
 type Collector struct {
 	url string
 	doc *goquery.Document
+	// fallback 页面中解析不到任何版本时从 JSON 接口获取的版本，为 nil 时使用页面
+	fallback *jsonVersions
+}
+
+// jsonVersions JSON 接口中的版本
+type jsonVersions struct {
+	stable, archived []*VersionGO
 }
 
 // NewCollector 返回采集器实例
@@ -52,16 +69,39 @@ func NewCollector(url string) (*Collector, error) {
 	}
 	resp, err := http.Get(c.url)
 	if err != nil {
-		return nil, err
+		return nil, NewURLUnreachableError(c.url, err)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
 		return nil, NewURLUnreachableError(c.url, nil)
 	}
 	c.doc, err = goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.checkEmpty(); err != nil {
+		return nil, err
+	}
 	return &c, nil
 }
 
+// checkEmpty 页面可以访问但解析不到任何版本(页面结构变化)时改为使用 JSON 接口，两者都没有版本时返回 util.ErrNoVersions
+// 避免空列表被当作没有可以安装的版本
+func (c *Collector) checkEmpty() error {
+	if c.doc.Find("#stable").NextUntil("#archive").Filter("[id]").Length() > 0 || c.doc.Find("#archive").Find("div.toggle[id]").Length() > 0 {
+		return nil
+	}
+	stable, archived, err := fetchJSON(c.url)
+	if err != nil {
+		return fmt.Errorf("%w: %s, the JSON API failed too + %w", util.ErrNoVersions, c.url, err)
+	}
+	if len(stable)+len(archived) == 0 {
+		return fmt.Errorf("%w: neither %s nor its JSON API", util.ErrNoVersions, c.url)
+	}
+	c.fallback = &jsonVersions{stable, archived}
+	return nil
+}
+
 func (c *Collector) loadDocument() (err error) {
 	resp, err := http.Get(c.url)
 	if err != nil {
@@ -96,6 +136,9 @@ func (c *Collector) findPackages(table *goquery.Selection) (pkgs []*util.Package
 
 // StableVersions 返回所有稳定版本
 func (c *Collector) StableVersions() (items []*VersionGO, err error) {
+	if c.fallback != nil {
+		return c.fallback.stable, nil
+	}
 	c.doc.Find("#stable").NextUntil("#archive").Each(func(i int, div *goquery.Selection) {
 		vname, ok := div.Attr("id")
 		if !ok {
@@ -112,6 +155,9 @@ func (c *Collector) StableVersions() (items []*VersionGO, err error) {
 
 // ArchivedVersions 返回已归档版本
 func (c *Collector) ArchivedVersions() (items []*VersionGO, err error) {
+	if c.fallback != nil {
+		return c.fallback.archived, nil
+	}
 	c.doc.Find("#archive").Find("div.toggle").Each(func(i int, div *goquery.Selection) {
 		vname, ok := div.Attr("id")
 		if !ok {
@@ -143,6 +189,16 @@ type VersionGO struct {
 	util.Version
 }
 
+// SourcePackage 返回源码包 go<version>.src.tar.gz，没有官方二进制包的平台从源码编译
+func (v *VersionGO) SourcePackage() (*util.Package, error) {
+	for _, pkg := range v.Packages {
+		if pkg != nil && strings.EqualFold(pkg.Kind, util.SourceKind) {
+			return pkg, nil
+		}
+	}
+	return nil, util.ErrPackageNotFound
+}
+
 // FindPackage 返回指定操作系统和硬件架构的版本包
 func (v *VersionGO) FindPackage(kind, goos, goarch string) (*util.Package, error) {
 	if goos == "linux" && goarch == "x86_64" {
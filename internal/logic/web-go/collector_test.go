@@ -102,5 +102,49 @@ func TestURLUnreachableError(t *testing.T) {
 		So(e.url, ShouldEqual, url)
 		So(e.err, ShouldEqual, core)
 		So(e.Error(), ShouldEqual, fmt.Sprintf("URL %q is unreachable ==> %s", url, core.Error()))
+		So(errors.Is(err, util.ErrNetwork), ShouldBeTrue)
+		So(errors.Is(err, core), ShouldBeTrue)
+		So(errors.Is(NewURLUnreachableError(url, nil), util.ErrNetwork), ShouldBeTrue)
+	})
+}
+
+func TestFindPackage(t *testing.T) {
+	Convey("查询版本下的安装包", t, func() {
+		v := &VersionGO{util.Version{
+			Name: "1.12.4",
+			Packages: []*util.Package{
+				{
+					FileName: "go1.12.4.src.tar.gz",
+					Kind:     util.SourceKind,
+					Size:     "21MB",
+				},
+				{
+					FileName: "go1.12.4.darwin-amd64.tar.gz",
+					Kind:     util.ArchiveKind,
+					OS:       "macOS",
+					Arch:     "x86-64",
+					Size:     "122MB",
+				},
+				{
+					FileName: "go1.12.4.windows-386.msi",
+					Kind:     util.InstallerKind,
+					OS:       "Windows",
+					Arch:     "x86",
+					Size:     "102MB",
+				},
+			},
+		}}
+
+		pkg, err := v.FindPackage(util.ArchiveKind, "darwin", "amd64")
+		So(err, ShouldBeNil)
+		So(pkg, ShouldNotBeNil)
+		So(pkg.FileName, ShouldEqual, "go1.12.4.darwin-amd64.tar.gz")
+		So(pkg.Kind, ShouldEqual, util.ArchiveKind)
+		So(pkg.OS, ShouldEqual, "macOS")
+		So(pkg.Arch, ShouldEqual, "x86-64")
+
+		pkg, err = v.FindPackage(util.ArchiveKind, "darwin", "386")
+		So(err, ShouldEqual, util.ErrPackageNotFound)
+		So(pkg, ShouldBeNil)
 	})
 }
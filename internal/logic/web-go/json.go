@@ -0,0 +1,112 @@
+package web_go
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/FirewineXie/envm/util"
+)
+
+// jsonQuery 下载页面的 JSON 接口，include=all 时包括所有版本，页面结构变化导致解析不到版本时使用
+const jsonQuery = "?mode=json&include=all"
+
+// jsonKinds 接口中的安装包种类与页面中的写法不同
+var jsonKinds = map[string]string{
+	"source":    util.SourceKind,
+	"archive":   util.ArchiveKind,
+	"installer": util.InstallerKind,
+}
+
+type jsonRelease struct {
+	Version string     `json:"version"`
+	Stable  bool       `json:"stable"`
+	Files   []jsonFile `json:"files"`
+}
+
+type jsonFile struct {
+	Filename string `json:"filename"`
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	Sha256   string `json:"sha256"`
+	Size     int64  `json:"size"`
+	Kind     string `json:"kind"`
+}
+
+// jsonURL 返回页面对应的 JSON 接口地址
+func jsonURL(page string) string {
+	return strings.TrimSuffix(page, "/") + "/" + jsonQuery
+}
+
+// fetchJSON 从 JSON 接口获取版本，与页面一样分为稳定版本及归档版本
+func fetchJSON(page string) (stable, archived []*VersionGO, err error) {
+	url := jsonURL(page)
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, nil, NewURLUnreachableError(url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, NewURLUnreachableError(url, nil)
+	}
+	return parseJSON(resp.Body)
+}
+
+// parseJSON 接口按从新到旧的顺序返回版本，与页面一致，稳定版本为最新两个次版本各自的最新版本，以及比它们更新的预发布版本
+// 安装包地址与页面一样为 /dl/ 开头的路径
+func parseJSON(r io.Reader) (stable, archived []*VersionGO, err error) {
+	var releases []jsonRelease
+	if err = json.NewDecoder(r).Decode(&releases); err != nil {
+		return nil, nil, err
+	}
+	minors := map[string]bool{}
+	for _, release := range releases {
+		name := strings.TrimPrefix(release.Version, "go")
+		if name == "" {
+			continue
+		}
+		version := &VersionGO{}
+		version.Name = name
+		for _, file := range release.Files {
+			kind, ok := jsonKinds[file.Kind]
+			if !ok {
+				kind = file.Kind
+			}
+			version.Packages = append(version.Packages, &util.Package{
+				FileName:  file.Filename,
+				URL:       "/dl/" + file.Filename,
+				Kind:      kind,
+				OS:        file.OS,
+				Arch:      file.Arch,
+				Size:      strconv.FormatInt(file.Size, 10),
+				Checksum:  file.Sha256,
+				Algorithm: "SHA256",
+			})
+		}
+		minor := minorOf(name)
+		switch {
+		case !release.Stable && len(minors) == 0:
+			stable = append(stable, version)
+		case release.Stable && !minors[minor] && len(minors) < 2:
+			minors[minor] = true
+			stable = append(stable, version)
+		default:
+			if release.Stable {
+				minors[minor] = true
+			}
+			archived = append(archived, version)
+		}
+	}
+	return stable, archived, nil
+}
+
+// minorOf 返回 1.22.3 的 1.22
+func minorOf(name string) string {
+	parts := strings.SplitN(name, ".", 3)
+	if len(parts) < 2 {
+		return name
+	}
+	return parts[0] + "." + strings.TrimRightFunc(parts[1], func(r rune) bool { return r < '0' || r > '9' })
+}
@@ -0,0 +1,101 @@
+package web_go
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/FirewineXie/envm/util"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const jsonPage = `[
+{"version": "go1.23rc1", "stable": false, "files": []},
+{"version": "go1.22.3", "stable": true, "files": [
+  {"filename": "go1.22.3.linux-amd64.tar.gz", "os": "linux", "arch": "amd64", "version": "go1.22.3", "sha256": "abc", "size": 68958945, "kind": "archive"},
+  {"filename": "go1.22.3.src.tar.gz", "os": "", "arch": "", "version": "go1.22.3", "sha256": "def", "size": 27600000, "kind": "source"}
+]},
+{"version": "go1.22.2", "stable": true, "files": []},
+{"version": "go1.21.10", "stable": true, "files": []},
+{"version": "go1.21rc2", "stable": false, "files": []},
+{"version": "go1.20.14", "stable": true, "files": []}
+]`
+
+func names(items []*VersionGO) []string {
+	out := make([]string, 0, len(items))
+	for _, v := range items {
+		out = append(out, v.Name)
+	}
+	return out
+}
+
+func Test_parseJSON(t *testing.T) {
+	Convey("解析 JSON 接口", t, func() {
+		stable, archived, err := parseJSON(strings.NewReader(jsonPage))
+		So(err, ShouldBeNil)
+		So(names(stable), ShouldResemble, []string{"1.23rc1", "1.22.3", "1.21.10"})
+		So(names(archived), ShouldResemble, []string{"1.22.2", "1.21rc2", "1.20.14"})
+
+		pkg, err := stable[1].FindPackage(util.ArchiveKind, "linux", "amd64")
+		So(err, ShouldBeNil)
+		So(pkg.URL, ShouldEqual, "/dl/go1.22.3.linux-amd64.tar.gz")
+		So(pkg.Size, ShouldEqual, "68958945")
+		So(pkg.Checksum, ShouldEqual, "abc")
+		So(pkg.Algorithm, ShouldEqual, "SHA256")
+		src, err := stable[1].SourcePackage()
+		So(err, ShouldBeNil)
+		So(src.FileName, ShouldEqual, "go1.22.3.src.tar.gz")
+	})
+}
+
+func TestFallback(t *testing.T) {
+	Convey("页面中解析不到版本时使用 JSON 接口", t, func() {
+		api := jsonPage
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("mode") == "json" {
+				_, _ = w.Write([]byte(api))
+				return
+			}
+			_, _ = w.Write([]byte(`<html><body><main id="redesigned"></main></body></html>`))
+		}))
+		defer server.Close()
+
+		c, err := NewCollector(server.URL + "/")
+		So(err, ShouldBeNil)
+		items, err := c.AllVersions()
+		So(err, ShouldBeNil)
+		So(len(items), ShouldEqual, 6)
+
+		sections := make([]string, 0)
+		err = EachVersion(server.URL+"/", func(name, section string) bool {
+			sections = append(sections, name+" "+section)
+			return len(sections) < 4
+		})
+		So(err, ShouldBeNil)
+		So(sections, ShouldResemble, []string{"1.23rc1 stable", "1.22.3 stable", "1.21.10 stable", "1.22.2 archived"})
+
+		Convey("JSON 接口同样没有版本时返回 ErrNoVersions", func() {
+			api = "[]"
+			_, err := NewCollector(server.URL + "/")
+			So(errors.Is(err, util.ErrNoVersions), ShouldBeTrue)
+			err = EachVersion(server.URL+"/", func(string, string) bool { return true })
+			So(errors.Is(err, util.ErrNoVersions), ShouldBeTrue)
+		})
+	})
+
+	Convey("页面中有版本时不访问 JSON 接口", t, func() {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			_, _ = w.Write([]byte(streamPage))
+		}))
+		defer server.Close()
+		c, err := NewCollector(server.URL + "/")
+		So(err, ShouldBeNil)
+		items, _ := c.AllVersions()
+		So(items, ShouldNotBeEmpty)
+		So(requests, ShouldEqual, 1)
+	})
+}
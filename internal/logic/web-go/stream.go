@@ -0,0 +1,109 @@
+package web_go
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/FirewineXie/envm/util"
+	"golang.org/x/net/html"
+)
+
+const (
+	// SectionStable 稳定版本，与 StableVersions 一致，包括页面中 #stable 与 #archive 之间的所有版本
+	SectionStable = "stable"
+	// SectionArchived 已归档版本
+	SectionArchived = "archived"
+)
+
+// EachVersion 边下载边解析下载页面，按页面顺序(新版本在前)对每个版本调用 fn
+// 只解析版本名，不保留安装包列表，fn 返回 false 时立即停止读取，适合只需要最新几个版本的场景
+func EachVersion(url string, fn func(name, section string) bool) error {
+	if url == "" {
+		url = DefaultURL
+	}
+	resp, err := http.Get(url)
+	if err != nil {
+		return NewURLUnreachableError(url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return NewURLUnreachableError(url, nil)
+	}
+	found := false
+	err = eachVersion(resp.Body, func(name, section string) bool {
+		found = true
+		return fn(name, section)
+	})
+	if err != nil || found {
+		return err
+	}
+	// 页面中解析不到任何版本，同 Collector 改为使用 JSON 接口
+	stable, archived, err := fetchJSON(url)
+	if err != nil {
+		return fmt.Errorf("%w: %s, the JSON API failed too + %w", util.ErrNoVersions, url, err)
+	}
+	if len(stable)+len(archived) == 0 {
+		return fmt.Errorf("%w: neither %s nor its JSON API", util.ErrNoVersions, url)
+	}
+	eachJSON(stable, archived, fn)
+	return nil
+}
+
+// eachJSON 按页面的顺序对 JSON 接口中的版本调用 fn，fn 返回 false 时停止
+func eachJSON(stable, archived []*VersionGO, fn func(name, section string) bool) {
+	for _, v := range stable {
+		if !fn(v.Name, SectionStable) {
+			return
+		}
+	}
+	for _, v := range archived {
+		if !fn(v.Name, SectionArchived) {
+			return
+		}
+	}
+}
+
+// eachVersion 使用 tokenizer 顺序扫描页面，版本为 id 以 go 开头且 class 包含 toggle 的 div
+func eachVersion(r io.Reader, fn func(name, section string) bool) error {
+	z := html.NewTokenizer(r)
+	section := ""
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if z.Err() == io.EOF {
+				return nil
+			}
+			return z.Err()
+		case html.StartTagToken:
+			name, hasAttr := z.TagName()
+			if !hasAttr {
+				continue
+			}
+			var id, class string
+			for {
+				key, val, more := z.TagAttr()
+				switch string(key) {
+				case "id":
+					id = string(val)
+				case "class":
+					class = string(val)
+				}
+				if !more {
+					break
+				}
+			}
+			switch {
+			case id == "stable":
+				section = SectionStable
+			case id == "archive":
+				section = SectionArchived
+			case section != "" && string(name) == "div" && strings.HasPrefix(id, "go") && strings.Contains(class, "toggle"):
+				if !fn(strings.TrimPrefix(id, "go"), section) {
+					return nil
+				}
+			}
+		}
+	}
+}
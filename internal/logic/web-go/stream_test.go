@@ -0,0 +1,41 @@
+package web_go
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const streamPage = `<html><body>
+<h2 id="stable">Stable versions</h2>
+<div class="toggleVisible" id="go1.22.3"><table></table></div>
+<div class="toggle" id="go1.21.10"><table></table></div>
+<h2 id="archive">Archived versions</h2>
+<div class="toggle" id="go1.20.14"><table></table></div>
+<div class="toggle" id="go1.19.13"><table></table></div>
+</body></html>`
+
+func Test_eachVersion(t *testing.T) {
+	Convey("流式解析下载页面中的版本", t, func() {
+		var names, sections []string
+		err := eachVersion(strings.NewReader(streamPage), func(name, section string) bool {
+			names = append(names, name)
+			sections = append(sections, section)
+			return true
+		})
+		So(err, ShouldBeNil)
+		So(names, ShouldResemble, []string{"1.22.3", "1.21.10", "1.20.14", "1.19.13"})
+		So(sections, ShouldResemble, []string{SectionStable, SectionStable, SectionArchived, SectionArchived})
+	})
+
+	Convey("回调返回 false 时停止解析", t, func() {
+		names := make([]string, 0)
+		err := eachVersion(strings.NewReader(streamPage), func(name, section string) bool {
+			names = append(names, name)
+			return len(names) < 3
+		})
+		So(err, ShouldBeNil)
+		So(names, ShouldResemble, []string{"1.22.3", "1.21.10", "1.20.14"})
+	})
+}
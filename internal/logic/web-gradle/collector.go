@@ -0,0 +1,119 @@
+package web_gradle
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+
+	"github.com/FirewineXie/envm/util"
+)
+
+const (
+	// DefaultURL gradle 官方版本列表
+	DefaultURL = "https://services.gradle.org/versions/all"
+	// WrapperProperties gradle wrapper 配置文件相对项目根目录的位置
+	WrapperProperties = "gradle/wrapper/gradle-wrapper.properties"
+)
+
+var wrapperVersion = regexp.MustCompile(`gradle-([0-9][^/]*?)-(bin|all)\.zip$`)
+
+// release services.gradle.org 返回的单个版本
+type release struct {
+	Version      string `json:"version"`
+	DownloadURL  string `json:"downloadUrl"`
+	ChecksumURL  string `json:"checksumUrl"`
+	Snapshot     bool   `json:"snapshot"`
+	Nightly      bool   `json:"nightly"`
+	Broken       bool   `json:"broken"`
+	ActiveRc     bool   `json:"activeRc"`
+	RcFor        string `json:"rcFor"`
+	MilestoneFor string `json:"milestoneFor"`
+}
+
+type VersionGradle struct {
+	util.Version
+	// Prerelease rc 或 milestone 版本
+	Prerelease bool
+}
+
+type Collector struct {
+	url      string
+	releases []release
+}
+
+// NewCollector 返回采集器实例
+func NewCollector(url string) (*Collector, error) {
+	if url == "" {
+		url = DefaultURL
+	}
+	c := Collector{
+		url: url,
+	}
+	resp, err := http.Get(c.url)
+	if err != nil {
+		return nil, util.NewNetworkError(c.url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, util.NewStatusError(c.url, resp.Status)
+	}
+	return &c, c.parse(resp.Body)
+}
+
+func (c *Collector) parse(r io.Reader) error {
+	return json.NewDecoder(r).Decode(&c.releases)
+}
+
+// AllVersions 返回所有可用版本，忽略 snapshot、nightly 以及标记为 broken 的版本
+func (c *Collector) AllVersions() (items []*VersionGradle) {
+	for _, r := range c.releases {
+		if r.Snapshot || r.Nightly || r.Broken || r.DownloadURL == "" {
+			continue
+		}
+		v := &VersionGradle{Prerelease: r.RcFor != "" || r.MilestoneFor != ""}
+		v.Name = r.Version
+		v.Packages = []*util.Package{
+			{
+				FileName:    "gradle-" + r.Version,
+				ArchiveName: path.Base(r.DownloadURL),
+				URL:         r.DownloadURL,
+				ChecksumURL: r.ChecksumURL,
+				Kind:        util.ArchiveKind,
+				Algorithm:   "SHA256",
+			},
+		}
+		items = append(items, v)
+	}
+	return items
+}
+
+// FindVersion 返回指定版本
+func (c *Collector) FindVersion(name string) (*VersionGradle, error) {
+	for _, v := range c.AllVersions() {
+		if v.Name == name {
+			return v, nil
+		}
+	}
+	return nil, util.ErrVersionNotFound
+}
+
+// FindPackage gradle 与平台无关，只有一个 bin 包
+func (v *VersionGradle) FindPackage(kind, goos, goarch string) (*util.Package, error) {
+	for _, pkg := range v.Packages {
+		if pkg.Kind == kind {
+			return pkg, nil
+		}
+	}
+	return nil, util.ErrPackageNotFound
+}
+
+// VersionFromURL 从 wrapper 的 distributionUrl 中解析版本号
+func VersionFromURL(url string) (string, bool) {
+	match := wrapperVersion.FindStringSubmatch(url)
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
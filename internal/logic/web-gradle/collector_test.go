@@ -0,0 +1,49 @@
+package web_gradle
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/FirewineXie/envm/util"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const versions = `[
+  {"version": "8.8-20240515004136+0000", "downloadUrl": "https://services.gradle.org/distributions-snapshots/gradle-8.8-20240515004136+0000-bin.zip", "snapshot": true, "nightly": true},
+  {"version": "8.8-rc-1", "downloadUrl": "https://services.gradle.org/distributions/gradle-8.8-rc-1-bin.zip", "checksumUrl": "https://services.gradle.org/distributions/gradle-8.8-rc-1-bin.zip.sha256", "rcFor": "8.8"},
+  {"version": "8.7", "downloadUrl": "https://services.gradle.org/distributions/gradle-8.7-bin.zip", "checksumUrl": "https://services.gradle.org/distributions/gradle-8.7-bin.zip.sha256"},
+  {"version": "0.9-rc-3", "downloadUrl": "https://services.gradle.org/distributions/gradle-0.9-rc-3-bin.zip", "broken": true}
+]`
+
+func TestAllVersions(t *testing.T) {
+	Convey("查询所有gradle版本列表", t, func() {
+		c := &Collector{url: DefaultURL}
+		So(c.parse(strings.NewReader(versions)), ShouldBeNil)
+
+		items := c.AllVersions()
+		So(len(items), ShouldEqual, 2)
+		So(items[0].Prerelease, ShouldBeTrue)
+
+		v, err := c.FindVersion("8.7")
+		So(err, ShouldBeNil)
+		pkg, err := v.FindPackage(util.ArchiveKind, "linux", "amd64")
+		So(err, ShouldBeNil)
+		So(pkg.FileName, ShouldEqual, "gradle-8.7")
+		So(pkg.ArchiveName, ShouldEqual, "gradle-8.7-bin.zip")
+	})
+}
+
+func TestVersionFromURL(t *testing.T) {
+	Convey("解析 wrapper 中的版本", t, func() {
+		v, ok := VersionFromURL("https://services.gradle.org/distributions/gradle-8.7-bin.zip")
+		So(ok, ShouldBeTrue)
+		So(v, ShouldEqual, "8.7")
+
+		v, ok = VersionFromURL("https://services.gradle.org/distributions/gradle-8.8-rc-1-all.zip")
+		So(ok, ShouldBeTrue)
+		So(v, ShouldEqual, "8.8-rc-1")
+
+		_, ok = VersionFromURL("https://example.com/tool.zip")
+		So(ok, ShouldBeFalse)
+	})
+}
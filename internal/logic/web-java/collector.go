@@ -36,6 +36,16 @@ func (e *URLUnreachableError) Error() string {
 	return buf.String()
 }
 
+// URL 不可达的地址
+func (e *URLUnreachableError) URL() string {
+	return e.url
+}
+
+// Unwrap errors.Is(err, util.ErrNetwork) 成立，同时可以判断原始错误
+func (e *URLUnreachableError) Unwrap() []error {
+	return util.NetworkCauses(e.err)
+}
+
 type Collector struct {
 	url string
 	doc *goquery.Document
@@ -51,7 +61,7 @@ func NewCollector(url string) (*Collector, error) {
 	}
 	resp, err := http.Get(c.url)
 	if err != nil {
-		return nil, err
+		return nil, NewURLUnreachableError(c.url, err)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
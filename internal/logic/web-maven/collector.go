@@ -0,0 +1,117 @@
+package web_maven
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/FirewineXie/envm/util"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/blang/semver/v4"
+)
+
+const (
+	// DefaultURL apache 归档中 maven 3 的版本目录
+	DefaultURL = "https://archive.apache.org/dist/maven/maven-3/"
+	// WrapperProperties maven wrapper 配置文件相对项目根目录的位置
+	WrapperProperties = ".mvn/wrapper/maven-wrapper.properties"
+)
+
+var wrapperVersion = regexp.MustCompile(`apache-maven-([0-9][^/]*?)-bin\.(zip|tar\.gz)$`)
+
+type Collector struct {
+	url string
+	doc *goquery.Document
+}
+
+// NewCollector 返回采集器实例
+func NewCollector(url string) (*Collector, error) {
+	if url == "" {
+		url = DefaultURL
+	}
+	c := Collector{
+		url: url,
+	}
+	resp, err := http.Get(c.url)
+	if err != nil {
+		return nil, util.NewNetworkError(c.url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, util.NewStatusError(c.url, resp.Status)
+	}
+	c.doc, err = goquery.NewDocumentFromReader(resp.Body)
+	return &c, err
+}
+
+type VersionMaven struct {
+	util.Version
+}
+
+// AllVersions 返回目录列表中的所有版本，按版本号倒序
+func (c *Collector) AllVersions() (items []*VersionMaven) {
+	list := make([]semver.Version, 0)
+	c.doc.Find("a").Each(func(i int, a *goquery.Selection) {
+		name := strings.TrimSuffix(a.AttrOr("href", ""), "/")
+		if v, err := semver.Make(name); err == nil {
+			list = append(list, v)
+		}
+	})
+	semver.Sort(list)
+	for i := len(list) - 1; i >= 0; i-- {
+		items = append(items, c.newVersion(list[i].String()))
+	}
+	return items
+}
+
+func (c *Collector) newVersion(name string) *VersionMaven {
+	v := &VersionMaven{}
+	v.Name = name
+	for _, ext := range []string{"tar.gz", "zip"} {
+		archiveName := fmt.Sprintf("apache-maven-%s-bin.%s", name, ext)
+		url := fmt.Sprintf("%s%s/binaries/%s", c.url, name, archiveName)
+		v.Packages = append(v.Packages, &util.Package{
+			FileName:    "apache-maven-" + name,
+			ArchiveName: archiveName,
+			URL:         url,
+			ChecksumURL: url + ".sha512",
+			Kind:        util.ArchiveKind,
+			Algorithm:   "SHA512",
+		})
+	}
+	return v
+}
+
+// FindVersion 返回指定版本
+func (c *Collector) FindVersion(name string) (*VersionMaven, error) {
+	for _, v := range c.AllVersions() {
+		if v.Name == name {
+			return v, nil
+		}
+	}
+	return nil, util.ErrVersionNotFound
+}
+
+// FindPackage maven 与平台无关，windows 使用 zip，其余使用 tar.gz
+func (v *VersionMaven) FindPackage(kind, goos, goarch string) (*util.Package, error) {
+	suffix := ".tar.gz"
+	if goos == "windows" {
+		suffix = ".zip"
+	}
+	for _, pkg := range v.Packages {
+		if pkg.Kind == kind && strings.HasSuffix(pkg.ArchiveName, suffix) {
+			return pkg, nil
+		}
+	}
+	return nil, util.ErrPackageNotFound
+}
+
+// VersionFromURL 从 wrapper 的 distributionUrl 中解析版本号
+func VersionFromURL(url string) (string, bool) {
+	match := wrapperVersion.FindStringSubmatch(url)
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
@@ -40,7 +40,7 @@ func GetAvailable() (all []string, lts []string, current []string, stable []stri
 	meta = make(map[string]VersionNode)
 	resp, errr := DownloadContent(DefaultURL + "index.json")
 	if errr != nil {
-		err = errors.New("getting mirrors " + errr.Error())
+		err = fmt.Errorf("getting mirrors %w", errr)
 		return
 	}
 	// Check the service to make sure the version is available
@@ -53,7 +53,7 @@ func GetAvailable() (all []string, lts []string, current []string, stable []stri
 	var data []FileData
 	errr = json.Unmarshal(resp, &data)
 	if errr != nil {
-		err = errors.New("retrieving version " + errr.Error())
+		err = fmt.Errorf("retrieving version %w", errr)
 		return
 	}
 	npm = make(map[string]string, len(data))
@@ -134,10 +134,10 @@ func GetAvailable() (all []string, lts []string, current []string, stable []stri
 						}
 						return "unknown"
 					}(),
-					Arch:      split[1],
-					Size:      "",
-					Checksum:  "SHA256",
-					Algorithm: "SHA256",
+					Arch:        split[1],
+					Size:        "",
+					ChecksumURL: DefaultURL + "v" + version + "/SHASUMS256.txt",
+					Algorithm:   "SHA256",
 				})
 			}
 			meta[version] = nodeVersion
@@ -147,13 +147,14 @@ func GetAvailable() (all []string, lts []string, current []string, stable []stri
 			npm[version] = element.Npm
 		}
 
-		if isLTS(element) {
+		switch Classify(element) {
+		case KindLTS:
 			lts = append(lts, version)
-		} else if isCurrent(element) {
+		case KindCurrent:
 			current = append(current, version)
-		} else if isStable(element) {
+		case KindStable:
 			stable = append(stable, version)
-		} else if IsUnstable(element) {
+		case KindUnstable:
 			unstable = append(unstable, version)
 		}
 	}
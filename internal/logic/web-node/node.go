@@ -68,8 +68,11 @@ func IsUnstable(element FileData) bool {
 }
 
 func exchangeArch(arch string) string {
-	if arch == "amd64" {
+	switch arch {
+	case "amd64":
 		return "x64"
+	case "386":
+		return "x86"
 	}
 	return arch
 }
@@ -0,0 +1,70 @@
+package web_node
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// KindLTS 长期支持版本
+	KindLTS = "lts"
+	// KindCurrent 非 LTS 的 1.0 之后的版本
+	KindCurrent = "current"
+	// KindStable 0.x 中的稳定版本
+	KindStable = "stable"
+	// KindUnstable 0.x 中的不稳定版本
+	KindUnstable = "unstable"
+)
+
+// Classify 返回版本的类型，与 GetAvailable 的分类相同
+func Classify(element FileData) string {
+	switch {
+	case isLTS(element):
+		return KindLTS
+	case isCurrent(element):
+		return KindCurrent
+	case isStable(element):
+		return KindStable
+	case IsUnstable(element):
+		return KindUnstable
+	}
+	return ""
+}
+
+// EachRelease 边下载边解析 index.json，按发布顺序(新版本在前)对每个版本调用 fn
+// 不保留整个列表，fn 返回 false 时立即停止读取
+func EachRelease(fn func(element FileData) bool) error {
+	resp, err := client.Get(DefaultURL + "index.json")
+	if err != nil {
+		return fmt.Errorf("getting mirrors %w", err)
+	}
+	defer resp.Body.Close()
+	return eachRelease(resp.Body, fn)
+}
+
+// eachRelease 逐个解码 JSON 数组中的元素
+func eachRelease(r io.Reader, fn func(element FileData) bool) error {
+	dec := json.NewDecoder(r)
+	token, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("retrieving version %w", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return errors.New("retrieving version: index.json is not an array")
+	}
+	for dec.More() {
+		var element FileData
+		if err = dec.Decode(&element); err != nil {
+			return fmt.Errorf("retrieving version %w", err)
+		}
+		if len(element.Version) < 2 {
+			continue
+		}
+		if !fn(element) {
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package web_node
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const streamIndex = `[
+{"version":"v21.7.2","files":["linux-x64"],"lts":false},
+{"version":"v20.12.1","files":["linux-x64"],"lts":"Iron"},
+{"version":"v0.12.18","files":["linux-x64"],"lts":false},
+{"version":"v0.11.16","files":["linux-x64"],"lts":false}
+]`
+
+// failingReader 读完 data 后返回错误，用于确认提前停止时不会继续读取
+type failingReader struct {
+	data io.Reader
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	n, err := r.data.Read(p)
+	if err == io.EOF {
+		return n, errors.New("read past the requested versions")
+	}
+	return n, err
+}
+
+func Test_eachRelease(t *testing.T) {
+	Convey("流式解析 index.json 并分类", t, func() {
+		kinds := make([]string, 0)
+		err := eachRelease(strings.NewReader(streamIndex), func(element FileData) bool {
+			kinds = append(kinds, element.Version+" "+Classify(element))
+			return true
+		})
+		So(err, ShouldBeNil)
+		So(kinds, ShouldResemble, []string{"v21.7.2 current", "v20.12.1 lts", "v0.12.18 stable", "v0.11.16 unstable"})
+	})
+
+	Convey("回调返回 false 时不再读取剩余内容", t, func() {
+		partial := `[{"version":"v21.7.2","lts":false},{"version":"v20.12.1","lts":"Iron"},`
+		versions := make([]string, 0)
+		err := eachRelease(&failingReader{strings.NewReader(partial)}, func(element FileData) bool {
+			versions = append(versions, element.Version)
+			return len(versions) < 2
+		})
+		So(err, ShouldBeNil)
+		So(versions, ShouldResemble, []string{"v21.7.2", "v20.12.1"})
+	})
+}
@@ -5,6 +5,9 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+
+	"github.com/FirewineXie/envm/internal/logic/netpolicy"
+	"github.com/FirewineXie/envm/util"
 )
 
 /*
@@ -19,18 +22,21 @@ var client = &http.Client{}
 func SetProxy(p string, verifyssl bool) {
 	if p != "" && p != "none" {
 		proxyUrl, _ := url.Parse(p)
-		client = &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyUrl), TLSClientConfig: &tls.Config{InsecureSkipVerify: verifyssl}}}
+		client = &http.Client{Transport: netpolicy.Wrap(&http.Transport{Proxy: http.ProxyURL(proxyUrl), TLSClientConfig: &tls.Config{InsecureSkipVerify: verifyssl}})}
 	} else {
-		client = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: verifyssl}}}
+		client = &http.Client{Transport: netpolicy.Wrap(&http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: verifyssl}})}
 	}
 }
 
 func DownloadContent(url string) (content []byte, err error) {
 	resp, err := client.Get(url)
 	if err != nil {
-		return nil, err
+		return nil, util.NewNetworkError(url, err)
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, util.NewStatusError(url, resp.Status)
+	}
 
 	return io.ReadAll(resp.Body)
 
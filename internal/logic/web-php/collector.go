@@ -0,0 +1,175 @@
+package web_php
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/FirewineXie/envm/util"
+	"github.com/blang/semver/v4"
+)
+
+const (
+	// DefaultURL php.net 官方发布信息，只提供源码包
+	DefaultURL = "https://www.php.net/releases/index.php?json&max=100&version="
+	// WindowsURL windows.php.net 提供的 windows 二进制包，每个分支只保留最新版本
+	WindowsURL = "https://windows.php.net/downloads/releases/"
+)
+
+// majors 查询的大版本
+var majors = []string{"8", "7"}
+
+// source php.net 中的源码包
+type source struct {
+	Filename string `json:"filename"`
+	Sha256   string `json:"sha256"`
+}
+
+// release php.net 中的单个版本
+type release struct {
+	Date   string   `json:"date"`
+	Source []source `json:"source"`
+}
+
+// windowsBuild windows.php.net 中的单个构建
+type windowsBuild struct {
+	Zip struct {
+		Path   string `json:"path"`
+		Size   string `json:"size"`
+		Sha256 string `json:"sha256"`
+	} `json:"zip"`
+}
+
+type VersionPHP struct {
+	util.Version
+}
+
+// FindPackage 返回指定操作系统和硬件架构的版本包
+// windows 使用 nts 二进制包，其他系统使用源码包编译安装
+func (v *VersionPHP) FindPackage(kind, goos, goarch string) (*util.Package, error) {
+	if goos == "windows" {
+		kind = util.ArchiveKind
+	} else {
+		kind = util.SourceKind
+		goos, goarch = "", ""
+	}
+	for _, pkg := range v.Packages {
+		if pkg.Kind == kind && pkg.OS == goos && pkg.Arch == goarch {
+			return pkg, nil
+		}
+	}
+	return nil, util.ErrPackageNotFound
+}
+
+// SourceVersions 返回 php.net 上的所有版本，按版本号倒序
+func SourceVersions() (items []*VersionPHP, err error) {
+	for _, major := range majors {
+		resp, err := http.Get(DefaultURL + major)
+		if err != nil {
+			return nil, util.NewNetworkError(DefaultURL+major, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, util.NewStatusError(DefaultURL+major, resp.Status)
+		}
+		list, err := parseSource(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, list...)
+	}
+	sortVersions(items)
+	return items, nil
+}
+
+func parseSource(r io.Reader) (items []*VersionPHP, err error) {
+	var releases map[string]release
+	if err = json.NewDecoder(r).Decode(&releases); err != nil {
+		return nil, err
+	}
+	for name, rel := range releases {
+		v := &VersionPHP{}
+		v.Name = name
+		for _, src := range rel.Source {
+			// 只使用 tar.gz，解压不依赖 xz
+			if !strings.HasSuffix(src.Filename, ".tar.gz") {
+				continue
+			}
+			v.Packages = append(v.Packages, &util.Package{
+				FileName:    strings.TrimSuffix(src.Filename, ".tar.gz"),
+				ArchiveName: src.Filename,
+				URL:         "https://www.php.net/distributions/" + src.Filename,
+				Kind:        util.SourceKind,
+				Checksum:    src.Sha256,
+				Algorithm:   "SHA256",
+			})
+		}
+		items = append(items, v)
+	}
+	return items, nil
+}
+
+// WindowsVersions 返回 windows.php.net 上每个分支的最新版本
+func WindowsVersions() (items []*VersionPHP, err error) {
+	resp, err := http.Get(WindowsURL + "releases.json")
+	if err != nil {
+		return nil, util.NewNetworkError(WindowsURL+"releases.json", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, util.NewStatusError(WindowsURL+"releases.json", resp.Status)
+	}
+	items, err = parseWindows(resp.Body)
+	sortVersions(items)
+	return items, err
+}
+
+func parseWindows(r io.Reader) (items []*VersionPHP, err error) {
+	var branches map[string]map[string]json.RawMessage
+	if err = json.NewDecoder(r).Decode(&branches); err != nil {
+		return nil, err
+	}
+	for _, fields := range branches {
+		v := &VersionPHP{}
+		if err = json.Unmarshal(fields["version"], &v.Name); err != nil {
+			continue
+		}
+		for key, raw := range fields {
+			// 只使用非线程安全版本，如 nts-vs16-x64
+			if !strings.HasPrefix(key, "nts-") {
+				continue
+			}
+			var build windowsBuild
+			if json.Unmarshal(raw, &build) != nil || build.Zip.Path == "" {
+				continue
+			}
+			arch := "amd64"
+			if strings.HasSuffix(key, "-x86") {
+				arch = "386"
+			}
+			v.Packages = append(v.Packages, &util.Package{
+				ArchiveName: build.Zip.Path,
+				URL:         WindowsURL + build.Zip.Path,
+				Kind:        util.ArchiveKind,
+				OS:          "windows",
+				Arch:        arch,
+				Size:        build.Zip.Size,
+				Checksum:    build.Zip.Sha256,
+				Algorithm:   "SHA256",
+			})
+		}
+		items = append(items, v)
+	}
+	return items, nil
+}
+
+func sortVersions(items []*VersionPHP) {
+	sort.Slice(items, func(i, j int) bool {
+		vi, _ := semver.ParseTolerant(items[i].Name)
+		vj, _ := semver.ParseTolerant(items[j].Name)
+		return vi.GT(vj)
+	})
+}
@@ -0,0 +1,56 @@
+package web_php
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/FirewineXie/envm/util"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const sourceJSON = `{
+  "8.3.7": {"date": "09 May 2024", "source": [
+    {"filename": "php-8.3.7.tar.bz2", "sha256": "aaa"},
+    {"filename": "php-8.3.7.tar.gz", "sha256": "bbb"},
+    {"filename": "php-8.3.7.tar.xz", "sha256": "ccc"}
+  ]},
+  "8.2.19": {"date": "09 May 2024", "source": [{"filename": "php-8.2.19.tar.gz", "sha256": "ddd"}]}
+}`
+
+const windowsJSON = `{
+  "8.3": {
+    "version": "8.3.7",
+    "nts-vs16-x64": {"zip": {"path": "php-8.3.7-nts-Win32-vs16-x64.zip", "size": "30.6MB", "sha256": "eee"}},
+    "ts-vs16-x64": {"zip": {"path": "php-8.3.7-Win32-vs16-x64.zip", "size": "30.7MB", "sha256": "fff"}},
+    "nts-vs16-x86": {"zip": {"path": "php-8.3.7-nts-Win32-vs16-x86.zip", "size": "27MB", "sha256": "ggg"}}
+  }
+}`
+
+func TestParseSource(t *testing.T) {
+	Convey("解析 php.net 源码包", t, func() {
+		items, err := parseSource(strings.NewReader(sourceJSON))
+		So(err, ShouldBeNil)
+		sortVersions(items)
+		So(len(items), ShouldEqual, 2)
+		So(items[0].Name, ShouldEqual, "8.3.7")
+
+		pkg, err := items[0].FindPackage(util.ArchiveKind, "linux", "amd64")
+		So(err, ShouldBeNil)
+		So(pkg.Kind, ShouldEqual, util.SourceKind)
+		So(pkg.FileName, ShouldEqual, "php-8.3.7")
+		So(pkg.Checksum, ShouldEqual, "bbb")
+	})
+}
+
+func TestParseWindows(t *testing.T) {
+	Convey("解析 windows.php.net 二进制包", t, func() {
+		items, err := parseWindows(strings.NewReader(windowsJSON))
+		So(err, ShouldBeNil)
+		So(len(items), ShouldEqual, 1)
+		So(len(items[0].Packages), ShouldEqual, 2)
+
+		pkg, err := items[0].FindPackage(util.ArchiveKind, "windows", "amd64")
+		So(err, ShouldBeNil)
+		So(pkg.Checksum, ShouldEqual, "eee")
+	})
+}
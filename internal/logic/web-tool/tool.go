@@ -0,0 +1,222 @@
+package web_tool
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/FirewineXie/envm/internal/logic/web-github"
+	"github.com/FirewineXie/envm/util"
+)
+
+//go:embed tools.json
+var builtin []byte
+
+// ErrUnknownTool 没有该名称的内置或自定义工具
+var ErrUnknownTool = errors.New("unknown tool")
+
+// Tool 通过 github release 或 https 直接发布的单文件工具
+// 模板中可以使用 {version} {os} {arch} {ext} {exe}
+type Tool struct {
+	Name string `json:"name"`
+	Repo string `json:"repo"`
+	// URL 直接下载地址模板，配置后不再访问 github，Checksum 也为完整的地址模板
+	URL string `json:"url"`
+	// Releases URL 方式发布的工具可安装的版本
+	Releases []string `json:"versions"`
+	// Tag release 的 tag 模板，默认为 v{version}
+	Tag string `json:"tag"`
+	// Asset 附件名模板
+	Asset string `json:"asset"`
+	// Assets 个别系统命名规则不同时，按 GOOS 覆盖 Asset
+	Assets map[string]string `json:"assets"`
+	// Checksum 校验文件附件名模板，为空或 release 中没有该文件时使用 github 提供的附件摘要(digest)
+	Checksum string `json:"checksum"`
+	// Binary 压缩包内可执行文件路径模板，为空表示附件本身就是可执行文件
+	Binary string `json:"binary"`
+	// Include 压缩包内需要一并安装的目录，安装到可执行文件的 ../include，例如 protoc 的 well-known types
+	Include string `json:"include"`
+	// OS GOOS 到附件中系统名称的映射，未配置时使用 GOOS
+	OS map[string]string `json:"os"`
+	// Arch GOARCH 到附件中架构名称的映射，未配置时使用 GOARCH
+	Arch map[string]string `json:"arch"`
+	// Ext 不同系统的压缩包后缀，* 为默认值
+	Ext map[string]string `json:"ext"`
+}
+
+// Builtin 返回内置的工具定义
+func Builtin() (tools []*Tool) {
+	if err := json.Unmarshal(builtin, &tools); err != nil {
+		panic(err)
+	}
+	return tools
+}
+
+// custom 通过 Register 注册的工具定义
+var custom []*Tool
+
+// Register 注册自定义工具，同名时覆盖内置的定义
+func Register(tools ...*Tool) {
+	custom = append(custom, tools...)
+}
+
+// All 返回自定义及内置的工具定义，同名的内置工具被忽略
+func All() []*Tool {
+	tools := append([]*Tool{}, custom...)
+	for _, tool := range Builtin() {
+		overridden := false
+		for _, c := range custom {
+			if c.Name == tool.Name {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			tools = append(tools, tool)
+		}
+	}
+	return tools
+}
+
+// Find 根据名称查找工具定义，没有时返回包装了 ErrUnknownTool 的错误
+func Find(name string) (*Tool, error) {
+	for _, tool := range All() {
+		if tool.Name == name {
+			return tool, nil
+		}
+	}
+	return nil, fmt.Errorf("%w %s, see envm tool list", ErrUnknownTool, name)
+}
+
+// render 替换模板中的变量
+func (t *Tool) render(tpl, version, goos, goarch string) string {
+	name := func(m map[string]string, key string) string {
+		if v, ok := m[key]; ok {
+			return v
+		}
+		if v, ok := m["*"]; ok {
+			return v
+		}
+		return key
+	}
+	ext := name(t.Ext, goos)
+	if ext == goos {
+		ext = ""
+	}
+	exe := ""
+	if goos == "windows" {
+		exe = ".exe"
+	}
+	return strings.NewReplacer(
+		"{version}", version,
+		"{os}", name(t.OS, goos),
+		"{arch}", name(t.Arch, goarch),
+		"{ext}", ext,
+		"{exe}", exe,
+	).Replace(tpl)
+}
+
+// tag 返回版本对应的 release tag
+func (t *Tool) tag(version string) string {
+	if t.Tag == "" {
+		return "v" + version
+	}
+	return strings.ReplaceAll(t.Tag, "{version}", version)
+}
+
+// BinaryName 安装后可执行文件的名称
+func (t *Tool) BinaryName(goos string) string {
+	if t.Binary == "" {
+		return t.render(t.Name+"{exe}", "", goos, "")
+	}
+	return path.Base(t.render(t.Binary, "", goos, ""))
+}
+
+// Versions 返回 github 上的所有正式版本，URL 方式发布的工具返回配置的版本
+func (t *Tool) Versions() (items []string, err error) {
+	if t.URL != "" {
+		return t.Releases, nil
+	}
+	collector, err := web_github.NewCollector(t.Repo)
+	if err != nil {
+		return nil, err
+	}
+	prefix, suffix, _ := strings.Cut(t.tag("{version}"), "{version}")
+	for _, release := range collector.Releases() {
+		if release.Prerelease || !strings.HasPrefix(release.TagName, prefix) || !strings.HasSuffix(release.TagName, suffix) {
+			continue
+		}
+		items = append(items, strings.TrimSuffix(strings.TrimPrefix(release.TagName, prefix), suffix))
+	}
+	return items, nil
+}
+
+// FindPackage 返回指定版本、操作系统和硬件架构的安装包
+func (t *Tool) FindPackage(version, goos, goarch string) (*util.Package, error) {
+	if t.URL != "" {
+		return t.urlPackage(version, goos, goarch), nil
+	}
+	release, err := web_github.FindRelease(t.Repo, t.tag(version))
+	if err != nil {
+		return nil, err
+	}
+	return t.newPackage(release, version, goos, goarch)
+}
+
+func (t *Tool) newPackage(release *web_github.Release, version, goos, goarch string) (*util.Package, error) {
+	tpl := t.Asset
+	if override, ok := t.Assets[goos]; ok {
+		tpl = override
+	}
+	asset := release.FindAsset(t.render(tpl, version, goos, goarch))
+	if asset == nil {
+		return nil, util.ErrPackageNotFound
+	}
+	pkg := &util.Package{
+		ArchiveName: asset.Name,
+		URL:         asset.URL,
+		Size:        strconv.FormatInt(asset.Size, 10),
+		Kind:        util.BinaryKind,
+		OS:          goos,
+		Arch:        goarch,
+		Algorithm:   "SHA256",
+	}
+	if t.Binary != "" {
+		pkg.Kind = util.ArchiveKind
+		pkg.FileName = t.render(t.Binary, version, goos, goarch)
+	}
+	if t.Checksum != "" {
+		if sum := release.FindAsset(t.render(t.Checksum, version, goos, goarch)); sum != nil {
+			pkg.ChecksumURL = sum.URL
+		}
+	}
+	if pkg.ChecksumURL == "" {
+		pkg.Checksum = asset.SHA256()
+	}
+	return pkg, nil
+}
+
+// urlPackage 根据下载地址模板生成安装包
+func (t *Tool) urlPackage(version, goos, goarch string) *util.Package {
+	url := t.render(t.URL, version, goos, goarch)
+	pkg := &util.Package{
+		ArchiveName: path.Base(url),
+		URL:         url,
+		Kind:        util.BinaryKind,
+		OS:          goos,
+		Arch:        goarch,
+		Algorithm:   "SHA256",
+	}
+	if t.Binary != "" {
+		pkg.Kind = util.ArchiveKind
+		pkg.FileName = t.render(t.Binary, version, goos, goarch)
+	}
+	if t.Checksum != "" {
+		pkg.ChecksumURL = t.render(t.Checksum, version, goos, goarch)
+	}
+	return pkg
+}
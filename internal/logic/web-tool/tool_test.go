@@ -0,0 +1,161 @@
+package web_tool
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/FirewineXie/envm/internal/logic/web-github"
+	"github.com/FirewineXie/envm/util"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBuiltin(t *testing.T) {
+	Convey("内置工具定义", t, func() {
+		tools := Builtin()
+		So(len(tools), ShouldBeGreaterThan, 0)
+		for _, tool := range tools {
+			So(tool.Name, ShouldNotBeEmpty)
+			So(tool.Repo, ShouldNotBeEmpty)
+			So(tool.Asset, ShouldNotBeEmpty)
+		}
+	})
+}
+
+func TestBuiltinChecksum(t *testing.T) {
+	Convey("内置工具都能找到校验和", t, func() {
+		platforms := [][2]string{{"linux", "amd64"}, {"darwin", "arm64"}, {"windows", "amd64"}}
+		for _, tool := range Builtin() {
+			for _, platform := range platforms {
+				goos, goarch := platform[0], platform[1]
+				tpl := tool.Asset
+				if override, ok := tool.Assets[goos]; ok {
+					tpl = override
+				}
+				release := &web_github.Release{
+					TagName: tool.tag("1.0.0"),
+					Assets: []*web_github.Asset{
+						{Name: tool.render(tpl, "1.0.0", goos, goarch), URL: "https://example.com/pkg", Digest: "sha256:ABC"},
+					},
+				}
+				if tool.Checksum != "" {
+					release.Assets = append(release.Assets, &web_github.Asset{
+						Name: tool.render(tool.Checksum, "1.0.0", goos, goarch), URL: "https://example.com/sum",
+					})
+				}
+				pkg, err := tool.newPackage(release, "1.0.0", goos, goarch)
+				So(err, ShouldBeNil)
+				So(pkg.Checksum != "" || pkg.ChecksumURL != "", ShouldBeTrue)
+			}
+		}
+
+		yq, err := Find("yq")
+		So(err, ShouldBeNil)
+		release := &web_github.Release{
+			TagName: "v4.44.1",
+			Assets:  []*web_github.Asset{{Name: "yq_linux_amd64", URL: "https://example.com/yq", Digest: "sha256:ABC"}},
+		}
+		pkg, err := yq.newPackage(release, "4.44.1", "linux", "amd64")
+		So(err, ShouldBeNil)
+		So(pkg.Checksum, ShouldEqual, "abc")
+		So(pkg.ChecksumURL, ShouldBeEmpty)
+
+		release.Assets[0].Digest = ""
+		pkg, err = yq.newPackage(release, "4.44.1", "linux", "amd64")
+		So(err, ShouldBeNil)
+		So(pkg.Checksum, ShouldBeEmpty)
+	})
+}
+
+func TestNewPackage(t *testing.T) {
+	Convey("根据模板查找附件", t, func() {
+		tool, err := Find("golangci-lint")
+		So(err, ShouldBeNil)
+
+		release := &web_github.Release{
+			TagName: "v1.59.0",
+			Assets: []*web_github.Asset{
+				{Name: "golangci-lint-1.59.0-linux-amd64.tar.gz", URL: "https://example.com/a.tar.gz"},
+				{Name: "golangci-lint-1.59.0-windows-amd64.zip", URL: "https://example.com/a.zip"},
+				{Name: "golangci-lint-1.59.0-checksums.txt", URL: "https://example.com/sum"},
+			},
+		}
+		pkg, err := tool.newPackage(release, "1.59.0", "windows", "amd64")
+		So(err, ShouldBeNil)
+		So(pkg.Kind, ShouldEqual, util.ArchiveKind)
+		So(pkg.FileName, ShouldEqual, "golangci-lint-1.59.0-windows-amd64/golangci-lint.exe")
+		So(pkg.ChecksumURL, ShouldEqual, "https://example.com/sum")
+		So(tool.BinaryName("windows"), ShouldEqual, "golangci-lint.exe")
+
+		_, err = tool.newPackage(release, "1.59.0", "darwin", "arm64")
+		So(err, ShouldEqual, util.ErrPackageNotFound)
+
+		jq, err := Find("jq")
+		So(err, ShouldBeNil)
+		So(jq.tag("1.7.1"), ShouldEqual, "jq-1.7.1")
+		So(jq.render(jq.Asset, "1.7.1", "darwin", "arm64"), ShouldEqual, "jq-macos-arm64")
+		So(jq.BinaryName("linux"), ShouldEqual, "jq")
+
+		_, err = Find("jqq")
+		So(errors.Is(err, ErrUnknownTool), ShouldBeTrue)
+		So(err.Error(), ShouldContainSubstring, "jqq")
+	})
+}
+
+func TestURLPackage(t *testing.T) {
+	Convey("https 直接发布的工具", t, func() {
+		Register(&Tool{
+			Name:     "jq",
+			URL:      "https://dl.example.com/jq/{version}/jq_{os}_{arch}{ext}",
+			Checksum: "https://dl.example.com/jq/{version}/SHA256SUMS",
+			Binary:   "jq{exe}",
+			Releases: []string{"1.7.1"},
+			Ext:      map[string]string{"windows": ".zip", "*": ".tar.gz"},
+		})
+		defer func() { custom = nil }()
+
+		tool, err := Find("jq")
+		So(err, ShouldBeNil)
+		So(tool.URL, ShouldNotBeEmpty)
+		So(len(All()), ShouldEqual, len(Builtin()))
+
+		versions, err := tool.Versions()
+		So(err, ShouldBeNil)
+		So(versions, ShouldResemble, []string{"1.7.1"})
+
+		pkg, err := tool.FindPackage("1.7.1", "windows", "amd64")
+		So(err, ShouldBeNil)
+		So(pkg.URL, ShouldEqual, "https://dl.example.com/jq/1.7.1/jq_windows_amd64.zip")
+		So(pkg.ArchiveName, ShouldEqual, "jq_windows_amd64.zip")
+		So(pkg.FileName, ShouldEqual, "jq.exe")
+		So(pkg.ChecksumURL, ShouldEqual, "https://dl.example.com/jq/1.7.1/SHA256SUMS")
+	})
+}
+
+func TestProtoc(t *testing.T) {
+	Convey("protoc 及插件", t, func() {
+		protoc, err := Find("protoc")
+		So(err, ShouldBeNil)
+		So(protoc.Include, ShouldEqual, "include")
+
+		release := &web_github.Release{
+			TagName: "v25.1",
+			Assets: []*web_github.Asset{
+				{Name: "protoc-25.1-linux-aarch_64.zip", URL: "https://example.com/linux.zip"},
+				{Name: "protoc-25.1-win64.zip", URL: "https://example.com/win64.zip"},
+			},
+		}
+		pkg, err := protoc.newPackage(release, "25.1", "windows", "amd64")
+		So(err, ShouldBeNil)
+		So(pkg.URL, ShouldEqual, "https://example.com/win64.zip")
+		So(pkg.FileName, ShouldEqual, "bin/protoc.exe")
+
+		pkg, err = protoc.newPackage(release, "25.1", "linux", "arm64")
+		So(err, ShouldBeNil)
+		So(pkg.URL, ShouldEqual, "https://example.com/linux.zip")
+
+		grpc, err := Find("protoc-gen-go-grpc")
+		So(err, ShouldBeNil)
+		So(grpc.tag("1.3.0"), ShouldEqual, "cmd/protoc-gen-go-grpc/v1.3.0")
+		So(grpc.render(grpc.Asset, "1.3.0", "darwin", "arm64"), ShouldEqual, "protoc-gen-go-grpc.v1.3.0.darwin.arm64.tar.gz")
+	})
+}
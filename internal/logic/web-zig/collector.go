@@ -0,0 +1,164 @@
+package web_zig
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/FirewineXie/envm/util"
+	"github.com/blang/semver/v4"
+)
+
+const (
+	// DefaultURL zig 官方版本索引
+	DefaultURL = "https://ziglang.org/download/index.json"
+	// PublicKey zig 官方发布包的 minisign 公钥
+	PublicKey = "RWSGOq2NVecA2UPNdBUZykf1CCb147pkmdtYxgb3Ti+JO/wCYvhbAb/U"
+	// Master nightly 版本在索引中的名称
+	Master = "master"
+)
+
+// osNames GOOS 与 zig 平台名称的对应关系
+var osNames = map[string]string{
+	"linux":   "linux",
+	"darwin":  "macos",
+	"windows": "windows",
+	"freebsd": "freebsd",
+}
+
+// archNames GOARCH 与 zig 平台名称的对应关系
+var archNames = map[string]string{
+	"amd64":   "x86_64",
+	"arm64":   "aarch64",
+	"386":     "x86",
+	"arm":     "armv7a",
+	"riscv64": "riscv64",
+}
+
+// target 单个平台的发布包
+type target struct {
+	Tarball string `json:"tarball"`
+	Shasum  string `json:"shasum"`
+	Size    string `json:"size"`
+}
+
+type VersionZig struct {
+	util.Version
+	// Nightly master 构建
+	Nightly bool
+	// Date 发布日期
+	Date string
+}
+
+type Collector struct {
+	url   string
+	index map[string]map[string]json.RawMessage
+}
+
+// NewCollector 返回采集器实例
+func NewCollector(url string) (*Collector, error) {
+	if url == "" {
+		url = DefaultURL
+	}
+	c := Collector{
+		url: url,
+	}
+	resp, err := http.Get(c.url)
+	if err != nil {
+		return nil, util.NewNetworkError(c.url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, util.NewStatusError(c.url, resp.Status)
+	}
+	return &c, c.parse(resp.Body)
+}
+
+func (c *Collector) parse(r io.Reader) error {
+	return json.NewDecoder(r).Decode(&c.index)
+}
+
+// AllVersions 返回所有版本，master 在最前，其余按版本号倒序
+func (c *Collector) AllVersions() (items []*VersionZig) {
+	for name, fields := range c.index {
+		v := &VersionZig{}
+		v.Name = name
+		if name == Master {
+			v.Nightly = true
+			_ = json.Unmarshal(fields["version"], &v.Name)
+		}
+		_ = json.Unmarshal(fields["date"], &v.Date)
+		for key, raw := range fields {
+			var t target
+			if err := json.Unmarshal(raw, &t); err != nil || t.Tarball == "" {
+				continue
+			}
+			split := strings.SplitN(key, "-", 2)
+			if len(split) != 2 {
+				continue
+			}
+			archiveName := path.Base(t.Tarball)
+			v.Packages = append(v.Packages, &util.Package{
+				FileName:    trimArchiveExt(archiveName),
+				ArchiveName: archiveName,
+				URL:         t.Tarball,
+				Kind:        util.ArchiveKind,
+				OS:          split[1],
+				Arch:        split[0],
+				Size:        t.Size,
+				Checksum:    t.Shasum,
+				Algorithm:   "SHA256",
+			})
+		}
+		items = append(items, v)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Nightly != items[j].Nightly {
+			return items[i].Nightly
+		}
+		vi, erri := semver.Make(items[i].Name)
+		vj, errj := semver.Make(items[j].Name)
+		if erri != nil || errj != nil {
+			return items[i].Name > items[j].Name
+		}
+		return vi.GT(vj)
+	})
+	return items
+}
+
+// FindVersion 返回指定版本，name 为 master 时返回 nightly 构建
+func (c *Collector) FindVersion(name string) (*VersionZig, error) {
+	for _, v := range c.AllVersions() {
+		if v.Name == name || (name == Master && v.Nightly) {
+			return v, nil
+		}
+	}
+	return nil, util.ErrVersionNotFound
+}
+
+// FindPackage 返回指定操作系统和硬件架构的版本包
+func (v *VersionZig) FindPackage(kind, goos, goarch string) (*util.Package, error) {
+	for _, pkg := range v.Packages {
+		if pkg.Kind == kind && pkg.OS == osNames[goos] && pkg.Arch == archNames[goarch] {
+			return pkg, nil
+		}
+	}
+	return nil, util.ErrPackageNotFound
+}
+
+// SignatureURL 发布包对应的 minisign 签名地址
+func SignatureURL(pkg *util.Package) string {
+	return pkg.URL + ".minisig"
+}
+
+func trimArchiveExt(name string) string {
+	for _, ext := range []string{".tar.xz", ".tar.gz", ".zip"} {
+		if strings.HasSuffix(name, ext) {
+			return strings.TrimSuffix(name, ext)
+		}
+	}
+	return name
+}
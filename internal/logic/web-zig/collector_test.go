@@ -0,0 +1,66 @@
+package web_zig
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/FirewineXie/envm/util"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const index = `{
+  "master": {
+    "version": "0.13.0-dev.211+6a65561e3",
+    "date": "2024-05-20",
+    "x86_64-linux": {"tarball": "https://ziglang.org/builds/zig-linux-x86_64-0.13.0-dev.211+6a65561e3.tar.xz", "shasum": "aaa", "size": "47000000"}
+  },
+  "0.12.0": {
+    "date": "2024-04-20",
+    "notes": "https://ziglang.org/download/0.12.0/release-notes.html",
+    "x86_64-linux": {"tarball": "https://ziglang.org/download/0.12.0/zig-linux-x86_64-0.12.0.tar.xz", "shasum": "bbb", "size": "45000000"},
+    "x86_64-windows": {"tarball": "https://ziglang.org/download/0.12.0/zig-windows-x86_64-0.12.0.zip", "shasum": "ccc", "size": "77000000"}
+  },
+  "0.9.1": {
+    "date": "2022-02-14",
+    "aarch64-macos": {"tarball": "https://ziglang.org/download/0.9.1/zig-macos-aarch64-0.9.1.tar.xz", "shasum": "ddd", "size": "38000000"}
+  }
+}`
+
+func getCollector() *Collector {
+	c := &Collector{url: DefaultURL}
+	if err := c.parse(strings.NewReader(index)); err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func TestAllVersions(t *testing.T) {
+	Convey("查询所有zig版本列表", t, func() {
+		items := getCollector().AllVersions()
+		So(len(items), ShouldEqual, 3)
+		So(items[0].Nightly, ShouldBeTrue)
+		So(items[0].Name, ShouldEqual, "0.13.0-dev.211+6a65561e3")
+		So(items[1].Name, ShouldEqual, "0.12.0")
+		So(items[2].Name, ShouldEqual, "0.9.1")
+	})
+}
+
+func TestFindPackage(t *testing.T) {
+	Convey("查找目标平台的安装包", t, func() {
+		v, err := getCollector().FindVersion("0.12.0")
+		So(err, ShouldBeNil)
+
+		pkg, err := v.FindPackage(util.ArchiveKind, "windows", "amd64")
+		So(err, ShouldBeNil)
+		So(pkg.FileName, ShouldEqual, "zig-windows-x86_64-0.12.0")
+		So(pkg.Checksum, ShouldEqual, "ccc")
+		So(SignatureURL(pkg), ShouldEqual, "https://ziglang.org/download/0.12.0/zig-windows-x86_64-0.12.0.zip.minisig")
+
+		_, err = v.FindPackage(util.ArchiveKind, "darwin", "arm64")
+		So(err, ShouldEqual, util.ErrPackageNotFound)
+
+		master, err := getCollector().FindVersion(Master)
+		So(err, ShouldBeNil)
+		So(master.Nightly, ShouldBeTrue)
+	})
+}
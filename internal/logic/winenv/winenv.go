@@ -0,0 +1,39 @@
+// Package winenv 读写 windows 注册表中的环境变量，修改后广播 WM_SETTINGCHANGE，新打开的终端即可生效
+package winenv
+
+import (
+	"errors"
+	"strings"
+)
+
+// Scope 环境变量的作用范围
+type Scope int
+
+const (
+	// User HKCU\Environment
+	User Scope = iota
+	// System HKLM\...\Session Manager\Environment，需要管理员权限
+	System
+)
+
+func (s Scope) String() string {
+	if s == System {
+		return "system"
+	}
+	return "user"
+}
+
+// ErrUnsupported 非 windows 系统
+var ErrUnsupported = errors.New("registry environment is only supported on windows")
+
+// AddPath 将 dir 放到 PATH 最前面，已经存在时(不区分大小写)移到最前面
+func AddPath(path, dir string) string {
+	items := []string{dir}
+	for _, item := range strings.Split(path, ";") {
+		if item == "" || strings.EqualFold(strings.TrimRight(item, `\`), strings.TrimRight(dir, `\`)) {
+			continue
+		}
+		items = append(items, item)
+	}
+	return strings.Join(items, ";")
+}
@@ -0,0 +1,15 @@
+package winenv
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAddPath(t *testing.T) {
+	Convey("调整 PATH 中的目录", t, func() {
+		So(AddPath(`C:\Windows;C:\envm\go\bin`, `C:\envm\go\bin`), ShouldEqual, `C:\envm\go\bin;C:\Windows`)
+		So(AddPath(`C:\Windows;c:\ENVM\go\bin\;`, `C:\envm\go\bin`), ShouldEqual, `C:\envm\go\bin;C:\Windows`)
+		So(AddPath("", `C:\envm\shims`), ShouldEqual, `C:\envm\shims`)
+	})
+}
@@ -0,0 +1,18 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package winenv
+
+// Get 读取环境变量
+func Get(scope Scope, name string) (string, error) {
+	return "", ErrUnsupported
+}
+
+// Set 写入环境变量
+func Set(scope Scope, name, value string) error {
+	return ErrUnsupported
+}
+
+// Broadcast 通知其它程序环境变量已经修改
+func Broadcast() error {
+	return ErrUnsupported
+}
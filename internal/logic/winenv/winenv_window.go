@@ -0,0 +1,99 @@
+//go:build windows
+
+package winenv
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var (
+	advapi32           = syscall.NewLazyDLL("advapi32.dll")
+	procRegSetValueExW = advapi32.NewProc("RegSetValueExW")
+	user32             = syscall.NewLazyDLL("user32.dll")
+	procSendMessageW   = user32.NewProc("SendMessageTimeoutW")
+)
+
+const (
+	hwndBroadcast   = 0xffff
+	wmSettingChange = 0x001a
+	smtoAbortIfHung = 0x0002
+)
+
+func open(scope Scope, access uint32) (syscall.Handle, error) {
+	root, path := syscall.Handle(syscall.HKEY_CURRENT_USER), "Environment"
+	if scope == System {
+		root, path = syscall.HKEY_LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Control\Session Manager\Environment`
+	}
+	var key syscall.Handle
+	err := syscall.RegOpenKeyEx(root, syscall.StringToUTF16Ptr(path), 0, access, &key)
+	return key, err
+}
+
+// Get 读取环境变量，不存在时返回空
+func Get(scope Scope, name string) (string, error) {
+	key, err := open(scope, syscall.KEY_READ)
+	if err != nil {
+		return "", err
+	}
+	defer syscall.RegCloseKey(key)
+
+	var typ, size uint32
+	err = syscall.RegQueryValueEx(key, syscall.StringToUTF16Ptr(name), nil, &typ, nil, &size)
+	if err == syscall.ERROR_FILE_NOT_FOUND {
+		return "", nil
+	}
+	if err != nil || size == 0 {
+		return "", err
+	}
+	buf := make([]uint16, size/2)
+	err = syscall.RegQueryValueEx(key, syscall.StringToUTF16Ptr(name), nil, &typ, (*byte)(unsafe.Pointer(&buf[0])), &size)
+	if err != nil {
+		return "", err
+	}
+	return syscall.UTF16ToString(buf), nil
+}
+
+// Set 写入环境变量，统一使用 REG_EXPAND_SZ，保留值中的 %VAR% 引用
+func Set(scope Scope, name, value string) error {
+	key, err := open(scope, syscall.KEY_SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer syscall.RegCloseKey(key)
+
+	data, err := syscall.UTF16FromString(value)
+	if err != nil {
+		return err
+	}
+	r, _, _ := procRegSetValueExW.Call(
+		uintptr(key),
+		uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(name))),
+		0,
+		syscall.REG_EXPAND_SZ,
+		uintptr(unsafe.Pointer(&data[0])),
+		uintptr(len(data)*2),
+	)
+	if r != 0 {
+		return syscall.Errno(r)
+	}
+	return nil
+}
+
+// Broadcast 广播 WM_SETTINGCHANGE，资源管理器及新打开的终端会重新读取环境变量
+func Broadcast() error {
+	var result uintptr
+	r, _, err := procSendMessageW.Call(
+		hwndBroadcast,
+		wmSettingChange,
+		0,
+		uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr("Environment"))),
+		smtoAbortIfHung,
+		5000,
+		uintptr(unsafe.Pointer(&result)),
+	)
+	if r == 0 {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+// Package writable 在耗时的下载开始前检查目录是否可以写入
+package writable
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Check 检查 dir 是否可以写入，dir 不存在时检查最近的已存在的上级目录(创建 dir 需要在其中写入)
+// 返回实际检查的目录，没有权限时错误满足 errors.Is(err, fs.ErrPermission)
+// 通过创建临时文件判断，可以覆盖只读挂载、ACL 等仅凭权限位无法判断的情况
+func Check(dir string) (checked string, err error) {
+	checked = filepath.Clean(dir)
+	for {
+		info, statErr := os.Stat(checked)
+		if statErr == nil {
+			if !info.IsDir() {
+				return checked, fmt.Errorf("%s is not a directory", checked)
+			}
+			break
+		}
+		if !os.IsNotExist(statErr) {
+			return checked, statErr
+		}
+		parent := filepath.Dir(checked)
+		if parent == checked {
+			return checked, statErr
+		}
+		checked = parent
+	}
+
+	f, err := os.CreateTemp(checked, ".envm-write-*")
+	if err != nil {
+		if os.IsPermission(err) {
+			return checked, &fs.PathError{Op: "write", Path: checked, Err: fs.ErrPermission}
+		}
+		return checked, err
+	}
+	name := f.Name()
+	_ = f.Close()
+	return checked, os.Remove(name)
+}
@@ -0,0 +1,45 @@
+package writable
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCheck(t *testing.T) {
+	Convey("检查目录是否可以写入", t, func() {
+		dir := t.TempDir()
+
+		checked, err := Check(dir)
+		So(err, ShouldBeNil)
+		So(checked, ShouldEqual, dir)
+
+		// 不存在的目录检查最近的已存在的上级目录
+		checked, err = Check(filepath.Join(dir, "downloads", "go"))
+		So(err, ShouldBeNil)
+		So(checked, ShouldEqual, dir)
+
+		entries, _ := os.ReadDir(dir)
+		So(entries, ShouldBeEmpty)
+
+		file := filepath.Join(dir, "file")
+		So(os.WriteFile(file, nil, 0644), ShouldBeNil)
+		_, err = Check(filepath.Join(file, "go"))
+		So(err, ShouldNotBeNil)
+		So(errors.Is(err, fs.ErrPermission), ShouldBeFalse)
+
+		// root 及 windows 不受权限位限制
+		if runtime.GOOS != "windows" && os.Geteuid() != 0 {
+			readonly := filepath.Join(dir, "readonly")
+			So(os.Mkdir(readonly, 0555), ShouldBeNil)
+			checked, err = Check(filepath.Join(readonly, "go"))
+			So(checked, ShouldEqual, readonly)
+			So(errors.Is(err, fs.ErrPermission), ShouldBeTrue)
+		}
+	})
+}
@@ -0,0 +1,102 @@
+// Package wsl 识别 WSL 环境，处理 windows 上安装的 envm 与 linux 版本在 PATH 中的冲突
+package wsl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// windowsEnvm windows 版本的可执行文件名，所在目录即 windows 的 ENVM_HOME
+const windowsEnvm = "envm.exe"
+
+var (
+	detectOnce sync.Once
+	detected   bool
+)
+
+// Detected 是否运行在 WSL 中
+func Detected() bool {
+	detectOnce.Do(func() {
+		if os.Getenv("WSL_DISTRO_NAME") != "" {
+			detected = true
+			return
+		}
+		release, err := os.ReadFile("/proc/sys/kernel/osrelease")
+		detected = err == nil && isWSL(string(release))
+	})
+	return detected
+}
+
+// isWSL 根据内核版本判断，例如 5.15.133.1-microsoft-standard-WSL2
+func isWSL(release string) bool {
+	release = strings.ToLower(release)
+	return strings.Contains(release, "microsoft") || strings.Contains(release, "wsl")
+}
+
+// IsWindowsPath 是否是 WSL 自动挂载的 windows 盘符下的路径，例如 /mnt/c/Program Files
+func IsWindowsPath(path string) bool {
+	rest := strings.TrimPrefix(filepath.ToSlash(path), "/mnt/")
+	if len(rest) == len(path) || rest == "" {
+		return false
+	}
+	drive := rest[0]
+	if !(drive >= 'a' && drive <= 'z' || drive >= 'A' && drive <= 'Z') {
+		return false
+	}
+	return len(rest) == 1 || rest[1] == '/'
+}
+
+// WindowsEnvm 在 path 中属于 windows 的目录里查找 envm.exe，返回所在目录
+func WindowsEnvm(path string) (roots []string) {
+	for _, dir := range filepath.SplitList(path) {
+		if !IsWindowsPath(dir) {
+			continue
+		}
+		if info, err := os.Stat(filepath.Join(dir, windowsEnvm)); err == nil && !info.IsDir() {
+			roots = append(roots, filepath.Clean(dir))
+		}
+	}
+	return roots
+}
+
+// Path 在 WSL 中去掉 path 里属于 windows envm 的目录，避免 windows 版本的 npm、gradle 等脚本覆盖 linux 版本
+// 不在 WSL 中时原样返回
+func Path(path string) string {
+	if !Detected() {
+		return path
+	}
+	return Strip(path, WindowsEnvm(path))
+}
+
+// Strip 去掉 path 中位于 roots 下的目录，目录可能是指向 roots 下的 symlink(windows 的 junction)
+// roots 本身(envm.exe 所在目录)保留，windows 的 envm 仍然可以通过 envm.exe 调用
+func Strip(path string, roots []string) string {
+	if len(roots) == 0 {
+		return path
+	}
+	items := make([]string, 0)
+	for _, dir := range filepath.SplitList(path) {
+		if !Owned(dir, roots) {
+			items = append(items, dir)
+		}
+	}
+	return strings.Join(items, string(os.PathListSeparator))
+}
+
+// Owned dir 是否位于 roots 中某个目录之下(不含 root 本身)
+func Owned(dir string, roots []string) bool {
+	candidates := []string{filepath.Clean(dir)}
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		candidates = append(candidates, resolved)
+	}
+	for _, candidate := range candidates {
+		for _, root := range roots {
+			if rel, err := filepath.Rel(root, candidate); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package wsl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIsWSL(t *testing.T) {
+	Convey("根据内核版本识别 WSL", t, func() {
+		So(isWSL("5.15.133.1-microsoft-standard-WSL2\n"), ShouldBeTrue)
+		So(isWSL("4.4.0-19041-Microsoft"), ShouldBeTrue)
+		So(isWSL("6.5.0-14-generic"), ShouldBeFalse)
+	})
+}
+
+func TestIsWindowsPath(t *testing.T) {
+	Convey("识别挂载的 windows 盘符", t, func() {
+		So(IsWindowsPath("/mnt/c"), ShouldBeTrue)
+		So(IsWindowsPath("/mnt/c/Program Files/nodejs"), ShouldBeTrue)
+		So(IsWindowsPath("/mnt/data/bin"), ShouldBeFalse)
+		So(IsWindowsPath("/usr/local/bin"), ShouldBeFalse)
+		So(IsWindowsPath("/mnt/"), ShouldBeFalse)
+	})
+}
+
+func TestStrip(t *testing.T) {
+	Convey("去掉属于 windows envm 的目录", t, func() {
+		root := t.TempDir()
+		home := filepath.Join(root, "envm")
+		node := filepath.Join(home, "downloads", "node", "node20.1.0")
+		So(os.MkdirAll(node, os.ModePerm), ShouldBeNil)
+		// windows 上 envm node active 创建的 symlink
+		link := filepath.Join(root, "nodejs")
+		So(os.Symlink(node, link), ShouldBeNil)
+
+		join := func(items ...string) string {
+			return strings.Join(items, string(os.PathListSeparator))
+		}
+		path := join("/usr/bin", home, filepath.Join(home, "bin"), link, "/bin")
+		So(Strip(path, []string{home}), ShouldEqual, join("/usr/bin", home, "/bin"))
+		So(Strip(path, nil), ShouldEqual, path)
+	})
+}
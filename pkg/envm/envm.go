@@ -0,0 +1,170 @@
+// Package envm 供其它 Go 程序(IDE 插件、环境初始化工具等)直接嵌入的 envm 接口，不需要再调用 envm 命令
+//
+// 配置与命令行相同，在程序启动时从 ENVM_HOME、ENVM_<LANG>_SYMLINK 等环境变量及 config.toml 读取
+// 嵌入时默认不输出任何内容，也不会等待输入，SetOutput 可以接收安装过程的提示及下载进度
+// 不捕获 SIGINT/SIGTERM，由调用方处理信号并取消 ctx
+// 返回的错误可以用 errors.Is 判断 ErrVersionNotFound、ErrNetwork、ErrChecksum、ErrPermission
+package envm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/FirewineXie/envm/internal/commands/common"
+	"github.com/FirewineXie/envm/internal/commands/languages"
+	"github.com/FirewineXie/envm/internal/config"
+	"github.com/FirewineXie/envm/util"
+)
+
+// 失败的类别，与命令行使用的错误相同
+var (
+	ErrUnknownLanguage = errors.New("unknown language")
+	ErrVersionNotFound = util.ErrVersionNotFound
+	ErrNetwork         = util.ErrNetwork
+	ErrChecksum        = util.ErrChecksum
+	ErrPermission      = util.ErrPermission
+)
+
+func init() {
+	util.Output = io.Discard
+	util.NonInteractive = true
+	util.PlainProgress = true
+	util.HandleSignals = false
+}
+
+// SetOutput 设置安装过程的提示及下载进度的输出位置，nil 表示丢弃
+// 下载进度每 10% 输出一行
+func SetOutput(w io.Writer) {
+	if w == nil {
+		w = io.Discard
+	}
+	util.Output = w
+}
+
+// Languages 返回支持的语言名称
+func Languages() []string {
+	names := make([]string, 0, len(languages.All()))
+	for _, language := range languages.All() {
+		names = append(names, language.Name)
+	}
+	return names
+}
+
+// lookup 检查配置并根据名称或别名(golang、nodejs 等)查找语言
+func lookup(name string) (*languages.Language, error) {
+	if err := config.VerifyEnv(); err != nil {
+		return nil, err
+	}
+	language := languages.Find(name)
+	if language == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownLanguage, name)
+	}
+	return language, nil
+}
+
+// RemoteVersions 返回可以安装的版本，缓存在有效期内时不访问网络
+// ctx 取消时立即返回 ctx.Err()，正在进行的请求在后台结束后更新缓存
+func RemoteVersions(ctx context.Context, name string) ([]string, error) {
+	language, err := lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	type result struct {
+		versions []string
+		err      error
+	}
+	done := make(chan result, 1)
+	go func() {
+		versions, err := language.RemoteVersions(false)
+		done <- result{versions, err}
+	}()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-done:
+		return r.versions, r.err
+	}
+}
+
+// Resolve 将 latest 及部分版本号(1.22)解析为具体的版本，已经安装的版本不访问网络
+func Resolve(ctx context.Context, name, version string) (string, error) {
+	language, err := lookup(name)
+	if err != nil {
+		return "", err
+	}
+	if err = ctx.Err(); err != nil {
+		return "", err
+	}
+	return language.ResolveVersion(version)
+}
+
+// Installed 返回已经安装的版本
+func Installed(name string) ([]string, error) {
+	language, err := lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return common.GetInstalled(language.Link().Downloads, language.Prefix), nil
+}
+
+// Current 返回当前激活(symlink 指向)的版本，未激活时返回空
+func Current(name string) (string, error) {
+	language, err := lookup(name)
+	if err != nil {
+		return "", err
+	}
+	return language.CurrentVersion(), nil
+}
+
+// Install 解析版本后下载、校验并安装，返回安装的具体版本，已经安装时直接返回
+// 下载前检查安装目录的写入权限，ctx 取消时中断下载，已经下载的部分保留用于下次续传
+func Install(ctx context.Context, name, version string) (string, error) {
+	language, err := lookup(name)
+	if err != nil {
+		return "", err
+	}
+	if version, err = Resolve(ctx, name, version); err != nil {
+		return "", err
+	}
+	if !common.IsInstalled(language.Link().Downloads, language.Prefix, version) {
+		if err = language.Preflight(); err != nil {
+			return "", err
+		}
+	}
+	if err = language.Install(ctx, version); err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+// Verify 检查已经安装的版本是否完整，未安装或文件缺失时返回错误
+func Verify(name, version string) error {
+	language, err := lookup(name)
+	if err != nil {
+		return err
+	}
+	return language.Check(version)
+}
+
+// Activate 将语言的 symlink 指向已经安装的版本，与 envm <language> active 相同
+func Activate(name, version string) error {
+	language, err := lookup(name)
+	if err != nil {
+		return err
+	}
+	return language.Activate(version)
+}
+
+// Environment 返回使用该版本时需要加入 PATH 的目录及其它环境变量(GOROOT、JAVA_HOME 等)，不修改 symlink
+func Environment(name, version string) (binDir string, env map[string]string, err error) {
+	language, err := lookup(name)
+	if err != nil {
+		return "", nil, err
+	}
+	if !common.IsInstalled(language.Link().Downloads, language.Prefix, version) {
+		return "", nil, fmt.Errorf("%s %s is not installed", language.Name, version)
+	}
+	return language.BinDir(version), language.Environ(version), nil
+}
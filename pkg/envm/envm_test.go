@@ -0,0 +1,21 @@
+package envm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/FirewineXie/envm/util"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestInstallCancelled(t *testing.T) {
+	Convey("嵌入时不捕获中断信号", t, func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := Install(ctx, "go", "1.22.3")
+		So(err, ShouldNotBeNil)
+		util.OnInterrupt(func() {})()
+		So(util.SignalsHandled(), ShouldBeFalse)
+	})
+}
@@ -0,0 +1,125 @@
+package util
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ErrArtifactMismatch 下载到的文件与版本列表中记录的文件名或大小不一致
+var ErrArtifactMismatch = newKindError(ErrChecksum, "downloaded file does not match the version index")
+
+// artifactExts 安装包常见的扩展名，重定向后的地址以这些扩展名结尾时才比较文件名，CDN 的对象地址通常没有扩展名
+var artifactExts = []string{".tar.gz", ".tgz", ".tar.xz", ".txz", ".zip", ".7z", ".msi", ".pkg", ".exe", ".jar"}
+
+// sizeTolerance 版本列表中四舍五入的大小(如 66MB)允许的误差
+const sizeTolerance = 0.1
+
+// ArtifactError 镜像返回的文件与版本列表中的记录不一致，通常是重定向到了过期或改名的文件
+type ArtifactError struct {
+	URL      string
+	FinalURL string
+	Reason   string
+	// Err 为 ErrArtifactMismatch 或 ErrChecksumNotMatched
+	Err error
+}
+
+func (e *ArtifactError) Error() string {
+	message := fmt.Sprintf("%v, %s", e.Err, e.Reason)
+	if e.FinalURL != "" && e.FinalURL != e.URL {
+		message += fmt.Sprintf(", %s was redirected to %s, the mirror may serve a stale or renamed file", e.URL, e.FinalURL)
+	}
+	return message
+}
+
+func (e *ArtifactError) Unwrap() error {
+	return e.Err
+}
+
+// servedName 返回服务端实际返回的文件名，优先使用 Content-Disposition，其次是重定向后的地址
+// 无法判断(没有重定向、地址没有安装包扩展名)时返回空
+func servedName(resp *http.Response, original string) string {
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		return params["filename"]
+	}
+	final := resp.Request.URL.String()
+	if final == original {
+		return ""
+	}
+	name := path.Base(resp.Request.URL.Path)
+	for _, ext := range artifactExts {
+		if strings.HasSuffix(name, ext) {
+			return name
+		}
+	}
+	return ""
+}
+
+// checkServedName 重定向后的文件名必须与原地址的文件名一致
+func (pkg *Package) checkServedName(resp *http.Response) error {
+	expected := path.Base(pkg.URL)
+	if i := strings.IndexAny(expected, "?#"); i >= 0 {
+		expected = expected[:i]
+	}
+	if served := servedName(resp, pkg.URL); served != "" && served != expected {
+		return &ArtifactError{URL: pkg.URL, FinalURL: pkg.FinalURL, Err: ErrArtifactMismatch,
+			Reason: fmt.Sprintf("expected %s but the server sent %s", expected, served)}
+	}
+	return nil
+}
+
+// parseSize 解析版本列表中的大小，纯数字为准确的字节数，66MB、25.34 MB 等为四舍五入的大小
+func parseSize(size string) (bytes int64, exact, ok bool) {
+	size = strings.TrimSpace(size)
+	if n, err := strconv.ParseInt(size, 10, 64); err == nil {
+		return n, true, n > 0
+	}
+	units := []struct {
+		suffix string
+		scale  float64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}}
+	upper := strings.ToUpper(size)
+	for _, unit := range units {
+		if strings.HasSuffix(upper, unit.suffix) {
+			n, err := strconv.ParseFloat(strings.TrimSpace(upper[:len(upper)-len(unit.suffix)]), 64)
+			if err != nil || n <= 0 {
+				return 0, false, false
+			}
+			return int64(n * unit.scale), false, true
+		}
+	}
+	return 0, false, false
+}
+
+// checkSize 下载完成的文件大小必须与版本列表中的记录一致，没有记录时不检查
+func (pkg *Package) checkSize(filename string) error {
+	expected, exact, ok := parseSize(pkg.Size)
+	if !ok {
+		return nil
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+	if actual := info.Size(); !sizeMatches(actual, expected, exact) {
+		return &ArtifactError{URL: pkg.URL, FinalURL: pkg.FinalURL, Err: ErrArtifactMismatch,
+			Reason: fmt.Sprintf("the index lists %s but %d bytes were downloaded", pkg.Size, actual)}
+	}
+	return nil
+}
+
+// sizeMatches 判断 actual 与版本列表中的大小是否一致，四舍五入的大小允许 sizeTolerance 的误差
+func sizeMatches(actual, expected int64, exact bool) bool {
+	if exact {
+		return actual == expected
+	}
+	diff := float64(actual - expected)
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= float64(expected)*sizeTolerance
+}
@@ -0,0 +1,20 @@
+package util
+
+import (
+	"os/exec"
+	"runtime"
+)
+
+// OpenURL 使用系统默认的浏览器打开 url，不等待浏览器退出
+func OpenURL(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
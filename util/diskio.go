@@ -0,0 +1,50 @@
+package util
+
+import (
+	"bufio"
+	"io"
+	"os"
+)
+
+// 下载写入文件时缓冲区的大小，按安装包大小选择
+// 网络每次读取的数据通常只有十几 KB，合并为大块再写入，减少机械硬盘的寻道及网络文件系统的往返
+const (
+	smallBuffer = 64 << 10
+	largeBuffer = 1 << 20
+	// largeFile 不小于该大小的安装包使用 largeBuffer
+	largeFile = 32 << 20
+)
+
+// bufferSize 返回读写 total 字节时的缓冲区大小，total 未知(<= 0)时按大文件处理
+func bufferSize(total int64) int {
+	if total > 0 && total < largeFile {
+		return smallBuffer
+	}
+	return largeBuffer
+}
+
+// newFileWriter 从 f 的当前位置 offset 写入剩余的 length 字节(未知时 <= 0)，写入经过缓冲，调用方需要 Flush
+// 已知大小时预先分配磁盘空间，减少碎片；分配不改变文件大小(续传根据文件大小计算已下载的部分)，失败或不支持时忽略
+func newFileWriter(f *os.File, offset, length int64) *bufio.Writer {
+	if length > 0 {
+		_ = preallocate(f, offset, length)
+	}
+	return bufio.NewWriterSize(f, bufferSize(length))
+}
+
+// openSequential 打开需要从头读到尾的文件(计算校验和)，提示系统加大预读
+// 不使用 O_DIRECT: 下载后马上校验及解压，绕过页缓存反而需要再从磁盘读一遍
+func openSequential(name string) (*os.File, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	adviseSequential(f)
+	return f, nil
+}
+
+// copyBuffered 以 bufferSize(total) 大小的缓冲区从 src 复制到 dst
+func copyBuffered(dst io.Writer, src io.Reader, total int64) (int64, error) {
+	// 隐藏 *os.File 的 WriterTo，否则 io.CopyBuffer 不使用缓冲区
+	return io.CopyBuffer(dst, struct{ io.Reader }{src}, make([]byte, bufferSize(total)))
+}
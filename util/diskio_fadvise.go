@@ -0,0 +1,16 @@
+//go:build linux && (amd64 || arm64 || loong64 || mips64 || mips64le || ppc64 || ppc64le || riscv64 || s390x)
+
+package util
+
+import (
+	"os"
+	"syscall"
+)
+
+// fadvSequential POSIX_FADV_SEQUENTIAL
+const fadvSequential = 2
+
+// adviseSequential 通过 posix_fadvise 提示 f 将被顺序读取，内核加大预读，失败时忽略
+func adviseSequential(f *os.File) {
+	_, _, _ = syscall.Syscall6(syscall.SYS_FADVISE64, f.Fd(), 0, 0, fadvSequential, 0, 0)
+}
@@ -0,0 +1,8 @@
+//go:build !linux || 386 || arm || mips || mipsle
+
+package util
+
+import "os"
+
+// adviseSequential 32 位 linux 的 fadvise64_64 参数布局与架构有关，其它系统没有对应的接口，不提示
+func adviseSequential(f *os.File) {}
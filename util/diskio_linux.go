@@ -0,0 +1,21 @@
+//go:build linux
+
+package util
+
+import (
+	"os"
+	"syscall"
+)
+
+// fallocKeepSize FALLOC_FL_KEEP_SIZE，分配空间但不改变文件大小
+const fallocKeepSize = 0x1
+
+// preallocate 通过 fallocate 预先分配 [offset, offset+length) 的磁盘空间，不支持的文件系统(例如部分网络文件系统)返回 EOPNOTSUPP
+func preallocate(f *os.File, offset, length int64) error {
+	for {
+		err := syscall.Fallocate(int(f.Fd()), fallocKeepSize, offset, length)
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !windows
+
+package util
+
+import "os"
+
+// preallocate 其它系统不预先分配，由文件系统按写入分配
+func preallocate(f *os.File, offset, length int64) error {
+	return nil
+}
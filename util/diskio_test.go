@@ -0,0 +1,115 @@
+package util
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBufferSize(t *testing.T) {
+	Convey("按大小选择缓冲区", t, func() {
+		So(bufferSize(1<<20), ShouldEqual, smallBuffer)
+		So(bufferSize(largeFile), ShouldEqual, largeBuffer)
+		So(bufferSize(0), ShouldEqual, largeBuffer)
+		So(bufferSize(-1), ShouldEqual, largeBuffer)
+	})
+}
+
+func TestFileWriter(t *testing.T) {
+	Convey("预先分配空间不改变文件大小", t, func() {
+		f, err := os.Create(filepath.Join(t.TempDir(), "pkg.tmp"))
+		So(err, ShouldBeNil)
+		defer f.Close()
+		_, err = f.Write([]byte("head"))
+		So(err, ShouldBeNil)
+
+		w := newFileWriter(f, 4, 8<<20)
+		info, _ := f.Stat()
+		So(info.Size(), ShouldEqual, 4)
+		_, err = w.Write([]byte("tail"))
+		So(err, ShouldBeNil)
+		So(w.Flush(), ShouldBeNil)
+		data, _ := os.ReadFile(f.Name())
+		So(string(data), ShouldEqual, "headtail")
+	})
+}
+
+func TestDownloadBuffered(t *testing.T) {
+	Convey("缓冲写入后下载的内容及校验和完整", t, func() {
+		content := bytes.Repeat([]byte("envm"), 3<<20)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.ServeContent(w, r, "go1.22.3.linux-amd64.tar.gz", time.Time{}, bytes.NewReader(content))
+		}))
+		defer server.Close()
+
+		dst := filepath.Join(t.TempDir(), "go1.22.3.linux-amd64.tar.gz")
+		pkg := &Package{URL: server.URL + "/go1.22.3.linux-amd64.tar.gz", Algorithm: "SHA256", Checksum: fmt.Sprintf("%x", sha256.Sum256(content))}
+		So(pkg.DownloadV2(dst), ShouldBeNil)
+		data, err := os.ReadFile(dst)
+		So(err, ShouldBeNil)
+		So(bytes.Equal(data, content), ShouldBeTrue)
+		So(pkg.VerifyChecksum(dst), ShouldBeNil)
+	})
+}
+
+// netReader 模拟网络连接，每次最多读取 16 KB
+type netReader struct {
+	data []byte
+}
+
+func (r *netReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p[:min(len(p), 16<<10)], r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+// BenchmarkFileWrite 对比下载写入文件时直接写入与缓冲、预分配后写入的吞吐量
+// 机械硬盘或网络文件系统上执行 go test -bench FileWrite -benchtime 10x，TMPDIR 指向需要测试的磁盘
+func BenchmarkFileWrite(b *testing.B) {
+	content := bytes.Repeat([]byte("envm"), 16<<20)
+	dir := b.TempDir()
+	b.Run("direct", func(b *testing.B) {
+		b.SetBytes(int64(len(content)))
+		for i := 0; i < b.N; i++ {
+			f, err := os.Create(filepath.Join(dir, "direct.tmp"))
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err = io.Copy(f, &netReader{content}); err != nil {
+				b.Fatal(err)
+			}
+			_ = f.Sync()
+			_ = f.Close()
+		}
+	})
+	b.Run("buffered", func(b *testing.B) {
+		b.SetBytes(int64(len(content)))
+		for i := 0; i < b.N; i++ {
+			f, err := os.Create(filepath.Join(dir, "buffered.tmp"))
+			if err != nil {
+				b.Fatal(err)
+			}
+			w := newFileWriter(f, 0, int64(len(content)))
+			if _, err = copyBuffered(w, &netReader{content}, int64(len(content))); err != nil {
+				b.Fatal(err)
+			}
+			if err = w.Flush(); err != nil {
+				b.Fatal(err)
+			}
+			_ = f.Sync()
+			_ = f.Close()
+		}
+	})
+}
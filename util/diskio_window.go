@@ -0,0 +1,25 @@
+//go:build windows
+
+package util
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var procSetFileInformationByHandle = syscall.NewLazyDLL("kernel32.dll").NewProc("SetFileInformationByHandle")
+
+// fileAllocationInfo FILE_INFO_BY_HANDLE_CLASS 中的 FileAllocationInfo
+const fileAllocationInfo = 5
+
+// preallocate 通过 FileAllocationInfo 设置分配大小，与 fallocate 的 KEEP_SIZE 相同不改变文件大小
+// 不使用 SetEndOfFile: 它会改变文件大小，中断后无法根据文件大小续传
+func preallocate(f *os.File, offset, length int64) error {
+	size := offset + length
+	r, _, err := procSetFileInformationByHandle.Call(f.Fd(), fileAllocationInfo, uintptr(unsafe.Pointer(&size)), unsafe.Sizeof(size))
+	if r == 0 {
+		return err
+	}
+	return nil
+}
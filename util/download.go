@@ -1,8 +1,10 @@
 package util
 
 import (
+	"context"
 	"crypto/sha1"
 	"crypto/sha256"
+	"crypto/sha512"
 	"errors"
 	"fmt"
 	"hash"
@@ -11,6 +13,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 /*
@@ -31,7 +34,9 @@ type Package struct {
 	Arch        string
 	Size        string
 	Checksum    string
+	ChecksumURL string // 校验文件地址，Checksum 为空时从该地址获取
 	Algorithm   string // checksum algorithm
+	FinalURL    string // 下载时重定向后的实际地址，由 DownloadV2 设置
 }
 
 const (
@@ -41,8 +46,13 @@ const (
 	ArchiveKind = "Archive"
 	// InstallerKind go安装包种类-可安装程序
 	InstallerKind = "Installer"
+	// BinaryKind 安装包种类-可直接执行的单个文件
+	BinaryKind = "Binary"
 )
 
+// Downloaded 下载完成后调用，记录本地的下载统计，url 为重定向后的实际地址，bytes 不包括续传前已经下载的部分
+var Downloaded func(url string, bytes int64, elapsed time.Duration)
+
 // Download 下载版本另存为指定文件并校验sha256哈希值
 func (pkg *Package) Download(dst string) (size int64, err error) {
 	resp, err := http.Get(pkg.URL)
@@ -63,35 +73,175 @@ func (pkg *Package) Download(dst string) (size int64, err error) {
 }
 
 // DownloadV2 下载版本另存为指定文件并校验sha256哈希值
+// 下载中断后保留 .tmp 文件，再次下载时按续传日志校验已经下载的部分后通过 Range 请求断点续传，见 journal
 func (pkg *Package) DownloadV2(dst string) (err error) {
+	return pkg.DownloadContext(context.Background(), dst)
+}
+
+// DownloadContext 同 DownloadV2，ctx 取消时中断下载并保留 .tmp 文件
+func (pkg *Package) DownloadContext(ctx context.Context, dst string) (err error) {
+	defer Phase(PhaseDownload)()
+	// 失效的地址在创建 .tmp 文件之前失败
+	remote, err := pkg.head(ctx)
+	if err != nil {
+		return err
+	}
+	perm := os.FileMode(0644)
+	if Shared {
+		// 其它用户同时下载同一个安装包时等待，不能同时写入同一个 .tmp 文件
+		unlock, err := LockFile(dst + ".lock")
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		perm = sharedPerm
+	}
 	// Create the file, but give it a tmp file extension, this means we won't overwrite a
 	// file until it's downloaded, but we'll remove the tmp extension once downloaded.
-	out, err := os.Create(dst + ".tmp")
+	out, err := os.OpenFile(dst+".tmp", os.O_CREATE|os.O_RDWR, perm)
+	if Shared && errors.Is(err, os.ErrPermission) {
+		// 其它用户中断的下载无法续传，持有锁时可以安全地删除后重新下载
+		_ = os.Remove(dst + ".tmp")
+		_ = os.Remove(dst + ".tmp.journal")
+		out, err = os.OpenFile(dst+".tmp", os.O_CREATE|os.O_RDWR, perm)
+	}
 	if err != nil {
 		return err
 	}
-	resp, err := http.Get(pkg.URL)
+	defer out.Close()
+	journal := openJournal(dst + ".tmp.journal")
+	// 中断时保留 .tmp 文件，再次执行相同的命令可以继续下载
+	defer OnInterrupt(func() {
+		fmt.Fprintf(os.Stderr, "download of %s is kept in %s.tmp, run the same command again to resume\n", pkg.URL, dst)
+	})()
+	size, err := out.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if size > 0 && (remote.noResume || (remote.size >= 0 && size > remote.size)) {
+		// 服务端不支持续传或文件已经变化，不必校验已经下载的部分
+		fmt.Fprintf(os.Stderr, "partial download %s.tmp cannot be resumed, downloading it again\n", dst)
+		if err = out.Truncate(0); err != nil {
+			return err
+		}
+		if _, err = out.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		journal.reset()
+		size = 0
+	}
+	// 续传前校验已经下载的部分，只从最后一个校验通过的位置继续
+	offset, corrupted := journal.verify(out, size)
+	if corrupted >= 0 {
+		fmt.Fprintf(os.Stderr, "partial download %s.tmp is corrupted after %d bytes, downloading the rest again\n", dst, corrupted)
+	}
+	if offset < size {
+		if err = out.Truncate(offset); err != nil {
+			return err
+		}
+		if _, err = out.Seek(offset, io.SeekStart); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pkg.URL, nil)
+	if err != nil {
+		return NewDownloadError(pkg.URL, err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	started := time.Now()
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return NewDownloadError(pkg.URL, err)
 	}
 	defer resp.Body.Close()
+	pkg.FinalURL = resp.Request.URL.String()
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusPartialContent {
+		// 镜像重定向到改名或过期的文件时不下载，续传的部分也不能再使用
+		if err = pkg.checkServedName(resp); err != nil {
+			out.Close()
+			_ = os.Remove(dst + ".tmp")
+			journal.remove()
+			return err
+		}
+	}
 
-	parseInt, _ := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		ProgressPrintln(fmt.Sprintf("Resume download from %d bytes", offset))
+	case http.StatusOK:
+		// 服务端不支持断点续传，重新下载
+		if offset > 0 {
+			if err = out.Truncate(0); err != nil {
+				return err
+			}
+			if _, err = out.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			offset = 0
+			journal.reset()
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		// 上次已经下载完整，只是没有完成重命名
+		out.Close()
+		if err = os.Rename(dst+".tmp", dst); err != nil {
+			return err
+		}
+		journal.remove()
+		return pkg.checkDownloaded(dst)
+	default:
+		return NewDownloadError(pkg.URL, fmt.Errorf("unexpected status %s", resp.Status))
+	}
+
+	parseInt, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	if err != nil && remote.size > offset {
+		// GET 没有返回大小(例如分块传输)时使用 HEAD 得到的大小显示进度
+		parseInt = remote.size - offset
+	}
 	// Create our progress reporter and pass it to be used alongside our writer
-	counter := NewOption(0, parseInt)
-	_, err = io.Copy(out, io.TeeReader(resp.Body, counter))
+	counter := NewOption(offset, offset+parseInt)
+	w := newFileWriter(out, offset, parseInt)
+	copied, err := copyBuffered(io.MultiWriter(w, journal), io.TeeReader(resp.Body, counter), parseInt)
+	// 下载失败时同样写入已经收到的数据，以便续传
+	if flushErr := w.Flush(); err == nil {
+		err = flushErr
+	}
+	// 同时进行的下载共用一行进度，全部结束后才换行
+	counter.Done()
 	if err != nil {
-		return err
+		return NewDownloadError(pkg.URL, err)
+	}
+	if Downloaded != nil {
+		Downloaded(pkg.FinalURL, copied, time.Since(started))
 	}
 
 	out.Close()
-
-	// The progress use the same line so print a new line once it's finished downloading
-	fmt.Print("\n")
 	err = os.Rename(dst+".tmp", dst)
 	if err != nil {
 		return err
 	}
+	journal.remove()
+	return pkg.checkDownloaded(dst)
+}
+
+// RemoveDownloaded 在解压等后续步骤结束后删除已经下载完整的安装包，返回的函数删除文件并取消注册
+// 后续步骤被中断时同样删除，避免残留在 downloads 中；envm serve 等常驻进程中完成后不再保留中断时的清理
+func RemoveDownloaded(dst string) (done func()) {
+	remove := OnInterrupt(func() { _ = os.Remove(dst) })
+	return func() {
+		remove()
+		_ = os.Remove(dst)
+	}
+}
+
+// checkDownloaded 下载完成后核对文件大小，与版本列表不一致时删除文件，避免安装错误的版本
+func (pkg *Package) checkDownloaded(dst string) error {
+	if err := pkg.checkSize(dst); err != nil {
+		_ = os.Remove(dst)
+		return err
+	}
 	return nil
 }
 
@@ -118,16 +268,48 @@ func (e *DownloadError) Error() string {
 	return buf.String()
 }
 
+// URL 下载失败的地址
+func (e *DownloadError) URL() string {
+	return e.url
+}
+
+// Unwrap errors.Is(err, ErrNetwork) 成立，同时可以判断原始错误
+func (e *DownloadError) Unwrap() []error {
+	return NetworkCauses(e.err)
+}
+
 var (
 	// ErrUnsupportedChecksumAlgorithm 不支持的校验和算法
-	ErrUnsupportedChecksumAlgorithm = errors.New("unsupported checksum algorithm")
+	ErrUnsupportedChecksumAlgorithm = newKindError(ErrChecksum, "unsupported checksum algorithm")
 	// ErrChecksumNotMatched 校验和不匹配
-	ErrChecksumNotMatched = errors.New("file checksum does not match the computed checksum")
+	ErrChecksumNotMatched = newKindError(ErrChecksum, "file checksum does not match the computed checksum")
+	// ErrChecksumMissing 安装包没有可用的校验和
+	ErrChecksumMissing = newKindError(ErrChecksum, "no checksum is available for the package, pass --insecure to install it without verification")
 )
 
+// Insecure 允许安装没有校验和的安装包，由全局的 --insecure 设置，校验和不匹配时仍然失败
+var Insecure bool
+
+// Verify 解压前必须执行的校验，没有校验和时除非指定了 --insecure 否则拒绝安装
+func (pkg *Package) Verify(filename string) error {
+	if pkg.Checksum == "" {
+		if !Insecure {
+			return ErrChecksumMissing
+		}
+		fmt.Fprintf(os.Stderr, "warning: %s is not verified, no checksum is available\n", filename)
+		return nil
+	}
+	err := pkg.VerifyChecksum(filename)
+	if errors.Is(err, ErrChecksumNotMatched) && pkg.FinalURL != "" && pkg.FinalURL != pkg.URL {
+		return &ArtifactError{URL: pkg.URL, FinalURL: pkg.FinalURL, Err: err, Reason: "the index lists " + pkg.Checksum}
+	}
+	return err
+}
+
 // VerifyChecksum 验证目标文件的校验和与当前安装包的校验和是否一致
 func (pkg *Package) VerifyChecksum(filename string) (err error) {
-	f, err := os.Open(filename)
+	defer Phase(PhaseVerify)()
+	f, err := openSequential(filename)
 	if err != nil {
 		return err
 	}
@@ -139,11 +321,17 @@ func (pkg *Package) VerifyChecksum(filename string) (err error) {
 		h = sha256.New()
 	case "SHA1":
 		h = sha1.New()
+	case "SHA512":
+		h = sha512.New()
 	default:
 		return ErrUnsupportedChecksumAlgorithm
 	}
 
-	if _, err := io.Copy(h, f); err != nil {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err := copyBuffered(h, f, info.Size()); err != nil {
 		return err
 	}
 	if pkg.Checksum != fmt.Sprintf("%x", h.Sum(nil)) {
@@ -151,3 +339,16 @@ func (pkg *Package) VerifyChecksum(filename string) (err error) {
 	}
 	return nil
 }
+
+// FetchContent 获取小文件内容，如签名、校验文件
+func FetchContent(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, NewDownloadError(url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, NewDownloadError(url, fmt.Errorf("unexpected status %s", resp.Status))
+	}
+	return io.ReadAll(resp.Body)
+}
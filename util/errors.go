@@ -0,0 +1,79 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// 失败的类别，各层返回的错误通过 %w 包装，命令行及库的调用方都可以用 errors.Is 判断
+// 版本不存在使用 ErrVersionNotFound
+var (
+	// ErrNetwork 访问网络失败，包括连接失败及非预期的 HTTP 状态码
+	ErrNetwork = errors.New("network error")
+	// ErrChecksum 安装包的校验和或签名校验失败，包括没有可用的校验和
+	ErrChecksum = errors.New("checksum verification failed")
+	// ErrPermission 没有写入权限，即 fs.ErrPermission，os 返回的权限错误同样满足 errors.Is
+	ErrPermission = fs.ErrPermission
+)
+
+// kindError 属于某个类别的 sentinel 错误，errors.Is 既可以匹配它本身，也可以匹配所属的类别
+type kindError struct {
+	kind    error
+	message string
+}
+
+func newKindError(kind error, message string) error {
+	return &kindError{kind: kind, message: message}
+}
+
+func (e *kindError) Error() string {
+	return e.message
+}
+
+func (e *kindError) Unwrap() error {
+	return e.kind
+}
+
+// NetworkCauses 网络错误 Unwrap 的结果，cause 为空(非预期的状态码)时只有 ErrNetwork
+func NetworkCauses(cause error) []error {
+	if cause == nil {
+		return []error{ErrNetwork}
+	}
+	return []error{ErrNetwork, cause}
+}
+
+// NetworkError 访问 URL 失败
+type NetworkError struct {
+	URL string
+	// Status 非预期的 HTTP 状态，连接失败时为空
+	Status string
+	Err    error
+}
+
+// NewNetworkError 返回连接 url 失败的错误
+func NewNetworkError(url string, err error) error {
+	return &NetworkError{URL: url, Err: err}
+}
+
+// NewStatusError 返回 url 响应了非预期状态码的错误
+func NewStatusError(url, status string) error {
+	return &NetworkError{URL: url, Status: status}
+}
+
+func (e *NetworkError) Error() string {
+	var buf strings.Builder
+	buf.WriteString(fmt.Sprintf("URL %q is unreachable", e.URL))
+	if e.Status != "" {
+		buf.WriteString(" ==> unexpected status " + e.Status)
+	}
+	if e.Err != nil {
+		buf.WriteString(" ==> " + e.Err.Error())
+	}
+	return buf.String()
+}
+
+func (e *NetworkError) Unwrap() []error {
+	return NetworkCauses(e.Err)
+}
@@ -0,0 +1,54 @@
+package util
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Events 不为空时每行输出一个 JSON 事件，例如 install --json，包装 envm 的程序及图形界面据此展示自己的进度
+// 各阶段(resolve、download、verify、extract、activate)开始时输出同名事件
+var Events io.Writer
+
+// 阶段之外的事件
+const (
+	// EventDownloadProgress 下载进度，与终端进度条的刷新频率相同
+	EventDownloadProgress = "download-progress"
+	// EventChecksumChanged 安装包的校验和与第一次安装时记录的不一致，Error 为详细信息
+	EventChecksumChanged = "checksum-changed"
+	// EventDone 命令结束，Error 为空表示成功
+	EventDone = "done"
+)
+
+// Event 一行事件
+type Event struct {
+	Event    string    `json:"event"`
+	Time     time.Time `json:"time"`
+	Language string    `json:"language,omitempty"`
+	Version  string    `json:"version,omitempty"`
+	// Bytes/Total 已下载及总字节数，Total 未知时为 0
+	Bytes int64 `json:"bytes,omitempty"`
+	Total int64 `json:"total,omitempty"`
+	// Dir 安装目录
+	Dir   string `json:"dir,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+var eventsMu sync.Mutex
+
+// Emit 输出一行事件，Events 为空时不做任何事，写入失败被忽略
+func Emit(event Event) {
+	if Events == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+	// 错误信息中的 URL 等保持原样，不转义 < > &
+	encoder := json.NewEncoder(Events)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(event)
+}
@@ -0,0 +1,25 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package util
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// Exec 使用当前环境变量执行 binary，替换当前进程，信号及退出码由目标程序直接处理
+// args[0] 为程序名
+func Exec(binary string, args []string) error {
+	return syscall.Exec(binary, args, os.Environ())
+}
+
+// StartDetached 在后台启动 binary，不等待结束，输入输出重定向到空设备，不受终端信号影响
+func StartDetached(binary string, args ...string) error {
+	cmd := exec.Command(binary, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
@@ -0,0 +1,41 @@
+//go:build windows
+
+package util
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// Exec 使用当前环境变量执行 binary，windows 不支持替换进程，等待子进程结束后使用相同的退出码
+// args[0] 为程序名
+func Exec(binary string, args []string) error {
+	cmd := exec.Command(binary, args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.ExitCode())
+	}
+	return err
+}
+
+// detachedProcess DETACHED_PROCESS，子进程不使用当前的控制台
+const detachedProcess = 0x00000008
+
+// StartDetached 在后台启动 binary，不等待结束，不打开新的控制台窗口
+func StartDetached(binary string, args ...string) error {
+	cmd := exec.Command(binary, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: detachedProcess | syscall.CREATE_NEW_PROCESS_GROUP,
+		HideWindow:    true,
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
@@ -5,75 +5,67 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
-	"strings"
-	"sync"
+	"sync/atomic"
 )
 
+// Bar 单个下载的进度，同时进行的多个下载由 progress 合并为一行输出
 type Bar struct {
-	percent    float32     // 百分比
-	percentInt int         //
-	cur        int64       // 当前进度位置
-	total      int64       // 总进度
-	rate       string      // 进度条
-	graph      string      // 显示符号
-	lock       *sync.Mutex // 读写锁，正确打印
+	cur   int64 // 当前进度位置，原子操作
+	total int64 // 总进度，未知时为 0
+	graph string
+	done  bool
 }
 
-var bar *Bar
+// Quiet 不输出下载进度，用于 shell hook 自动安装等不能占用终端的场景
+var Quiet bool
+
+// Output 安装、激活过程中的提示及下载进度的输出位置，命令行中为标准输出，嵌入 envm 的程序可以替换或丢弃
+var Output io.Writer = os.Stdout
 
 func NewOptionWithGraph(start, total int64, graph string) *Bar {
-	bar = new(Bar)
-	bar.cur = start
-	bar.total = total
-	if graph == "" {
+	bar := &Bar{cur: start, total: total, graph: graph}
+	if bar.graph == "" {
 		bar.graph = ">"
 	}
-	bar.lock = new(sync.Mutex)
-	bar.percent = bar.getPercent()
-
+	renderer.add(bar)
 	return bar
 }
 
-func (bar *Bar) getPercent() float32 {
-	return float32(bar.cur) / float32(bar.total) * 100
-}
-
 func NewOption(start, total int64) *Bar {
 
 	return NewOptionWithGraph(start, total, "")
 }
 
+// Play 输出当前进度，短时间内多次调用只输出一次
 func (bar *Bar) Play() {
-	bar.lock.Lock()
-	defer bar.lock.Unlock()
-	bar.percent = bar.getPercent()
-	i := int(bar.percent)
-	if bar.percentInt <= i {
-		bar.percentInt = i
-		bar.rate = strings.Repeat(bar.graph, i/2)
-		fmt.Printf("\r[%-50s]%0.2f%% %8d/%d", bar.rate, bar.percent, bar.cur, bar.total)
-	}
-
+	renderer.draw(false)
 }
 
 func (bar *Bar) Write(p []byte) (int, error) {
 	n := len(p)
-	bar.cur += int64(n)
+	atomic.AddInt64(&bar.cur, int64(n))
 	bar.Play()
 	return n, nil
 }
 
-func Process() {
-	fmt.Println("Download Started")
+// Done 下载结束(包括失败)时调用，所有下载都结束后输出最终进度并换行
+func (bar *Bar) Done() {
+	renderer.finish(bar)
+}
+
+// Process 下载 go1.11.1 的源码包到 dir
+func Process(dir string) {
+	fmt.Fprintln(Output, "Download Started")
 
 	fileUrl := "https://dl.google.com/go/go1.11.1.src.tar.gz"
-	err := DownloadFile("go1.11.1.src.tar.gz", fileUrl)
+	err := DownloadFile(filepath.Join(dir, "go1.11.1.src.tar.gz"), fileUrl)
 	if err != nil {
 		panic(err)
 	}
 
-	fmt.Println("Download Finished")
+	fmt.Fprintln(Output, "Download Finished")
 }
 
 // DownloadFile will download a url to a local file. It's efficient because it will
@@ -105,7 +97,7 @@ func DownloadFile(filepath string, url string) error {
 
 	out.Close()
 	// The progress use the same line so print a new line once it's finished downloading
-	fmt.Print("\n")
+	counter.Done()
 	err = os.Rename(filepath+".tmp", filepath)
 	if err != nil {
 		return err
@@ -3,5 +3,5 @@ package util
 import "testing"
 
 func TestProcess(t *testing.T) {
-	Process()
+	Process(t.TempDir())
 }
@@ -0,0 +1,90 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+)
+
+// ErrInterrupted 收到中断信号后提前结束的操作返回的错误
+var ErrInterrupted = errors.New("interrupted")
+
+const (
+	// ExitInterrupted 被 SIGINT(Ctrl-C) 中断时的退出码
+	ExitInterrupted = 130
+	// ExitTerminated 被 SIGTERM 终止时的退出码
+	ExitTerminated = 143
+)
+
+// HandleSignals 是否捕获 SIGINT/SIGTERM 执行清理后退出，嵌入 envm 的程序关闭后由调用方处理信号，通过 ctx 取消操作
+var HandleSignals = true
+
+var interrupt struct {
+	sync.Mutex
+	once     sync.Once
+	flag     int32
+	notified int32
+	next     int
+	cleanups map[int]func()
+}
+
+// Interrupted 是否已经收到中断信号，耗时的循环据此提前结束
+func Interrupted() bool {
+	return atomic.LoadInt32(&interrupt.flag) == 1
+}
+
+// OnInterrupt 注册收到 SIGINT/SIGTERM 时执行的清理，返回取消注册的函数
+// 只有注册过清理的命令才会捕获信号，envm exec 等命令仍然由子进程处理 Ctrl-C
+// 清理按注册的相反顺序执行，执行后以 ExitInterrupted/ExitTerminated 退出；HandleSignals 为 false 时不注册
+func OnInterrupt(cleanup func()) (remove func()) {
+	if !HandleSignals {
+		return func() {}
+	}
+	interrupt.once.Do(func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		atomic.StoreInt32(&interrupt.notified, 1)
+		go handleInterrupt(signals)
+	})
+	interrupt.Lock()
+	defer interrupt.Unlock()
+	if interrupt.cleanups == nil {
+		interrupt.cleanups = map[int]func(){}
+	}
+	id := interrupt.next
+	interrupt.next++
+	interrupt.cleanups[id] = cleanup
+	return func() {
+		interrupt.Lock()
+		defer interrupt.Unlock()
+		delete(interrupt.cleanups, id)
+	}
+}
+
+// SignalsHandled 是否已经捕获了 SIGINT/SIGTERM
+func SignalsHandled() bool {
+	return atomic.LoadInt32(&interrupt.notified) == 1
+}
+
+func handleInterrupt(signals chan os.Signal) {
+	sig := <-signals
+	atomic.StoreInt32(&interrupt.flag, 1)
+	fmt.Fprintf(os.Stderr, "\n%s, cleaning up\n", sig)
+
+	interrupt.Lock()
+	for id := interrupt.next - 1; id >= 0; id-- {
+		if cleanup, ok := interrupt.cleanups[id]; ok {
+			cleanup()
+		}
+	}
+	interrupt.Unlock()
+
+	if sig == syscall.SIGTERM {
+		os.Exit(ExitTerminated)
+	}
+	os.Exit(ExitInterrupted)
+}
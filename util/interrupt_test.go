@@ -0,0 +1,32 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRemoveDownloaded(t *testing.T) {
+	Convey("安装结束后删除安装包并取消中断时的清理", t, func() {
+		dst := filepath.Join(t.TempDir(), "go1.22.3.linux-amd64.tar.gz")
+		So(os.WriteFile(dst, []byte("archive"), 0644), ShouldBeNil)
+
+		interrupt.Lock()
+		registered := len(interrupt.cleanups)
+		interrupt.Unlock()
+
+		done := RemoveDownloaded(dst)
+		interrupt.Lock()
+		So(len(interrupt.cleanups), ShouldEqual, registered+1)
+		interrupt.Unlock()
+
+		done()
+		exists, _ := PathExists(dst)
+		So(exists, ShouldBeFalse)
+		interrupt.Lock()
+		So(len(interrupt.cleanups), ShouldEqual, registered)
+		interrupt.Unlock()
+	})
+}
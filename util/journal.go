@@ -0,0 +1,94 @@
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+)
+
+// journalChunk 续传日志中相邻两个校验点之间的字节数
+const journalChunk = 4 << 20
+
+// journal 续传日志，记录 .tmp 文件中已经下载的每 Chunk 字节的 sha256，保存在 <dst>.tmp.journal
+// 续传前先校验已经下载的部分，损坏的部分重新下载，而不是下载完剩余部分后才发现校验和不匹配
+type journal struct {
+	Chunk int64    `json:"chunk"`
+	Sums  []string `json:"sums"`
+
+	file string
+	h    hash.Hash
+	n    int64
+}
+
+// openJournal 读取续传日志，不存在或无法解析时返回空的日志
+func openJournal(file string) *journal {
+	j := &journal{file: file, h: sha256.New()}
+	if data, err := os.ReadFile(file); err == nil && json.Unmarshal(data, j) == nil && j.Chunk == journalChunk {
+		return j
+	}
+	j.Chunk, j.Sums = journalChunk, nil
+	return j
+}
+
+// verify 按日志校验 f 中已经下载的 size 字节，返回可以续传的偏移量及第一个损坏的校验点之前的字节数(没有损坏时为 -1)
+// 最后一个校验点之后的部分无法校验，同样需要重新下载
+func (j *journal) verify(f io.ReaderAt, size int64) (offset, corrupted int64) {
+	corrupted = -1
+	for i, sum := range j.Sums {
+		start := int64(i) * j.Chunk
+		if start+j.Chunk > size {
+			j.Sums = j.Sums[:i]
+			break
+		}
+		h := sha256.New()
+		if _, err := io.Copy(h, io.NewSectionReader(f, start, j.Chunk)); err != nil || fmt.Sprintf("%x", h.Sum(nil)) != sum {
+			j.Sums, corrupted = j.Sums[:i], start
+			break
+		}
+	}
+	return int64(len(j.Sums)) * j.Chunk, corrupted
+}
+
+// reset 重新开始下载时清空日志
+func (j *journal) reset() {
+	j.Sums, j.n = nil, 0
+	j.h.Reset()
+}
+
+// Write 计算写入 .tmp 文件的数据的校验和，每满 Chunk 字节保存一个校验点
+func (j *journal) Write(p []byte) (int, error) {
+	written := len(p)
+	for len(p) > 0 {
+		size := int64(len(p))
+		if rest := j.Chunk - j.n; size > rest {
+			size = rest
+		}
+		j.h.Write(p[:size])
+		j.n += size
+		p = p[size:]
+		if j.n == j.Chunk {
+			j.Sums = append(j.Sums, fmt.Sprintf("%x", j.h.Sum(nil)))
+			j.h.Reset()
+			j.n = 0
+			// 日志写入失败只影响下一次续传时的校验，不中断下载
+			_ = j.save()
+		}
+	}
+	return written, nil
+}
+
+func (j *journal) save() error {
+	data, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(j.file, data, 0644)
+}
+
+// remove 下载完成或 .tmp 文件被删除后删除日志
+func (j *journal) remove() {
+	_ = os.Remove(j.file)
+}
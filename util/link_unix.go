@@ -0,0 +1,10 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package util
+
+import "os"
+
+// Symlink 创建软链接
+func Symlink(target, link string) error {
+	return os.Symlink(target, link)
+}
@@ -0,0 +1,59 @@
+//go:build windows
+
+package util
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Symlink 创建软链接，没有开发者模式或管理员权限时，目录使用 junction，文件直接复制
+func Symlink(target, link string) error {
+	target, link = filepath.Clean(target), filepath.Clean(link)
+	err := os.Symlink(target, link)
+	if err == nil {
+		return nil
+	}
+	info, statErr := os.Stat(target)
+	if statErr != nil {
+		return err
+	}
+	if info.IsDir() {
+		return junction(target, link)
+	}
+	return copyFile(target, link, info.Mode())
+}
+
+// junction 目录联接不需要特殊权限，但只能指向本机的绝对路径
+func junction(target, link string) error {
+	target, err := filepath.Abs(target)
+	if err != nil {
+		return err
+	}
+	output, err := exec.Command("cmd", "/c", "mklink", "/J", link, target).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("create junction %s error + %v %s", link, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func copyFile(source, target string, mode os.FileMode) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
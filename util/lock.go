@@ -0,0 +1,58 @@
+package util
+
+import (
+	"fmt"
+	"os"
+)
+
+// Shared 工具链安装在多用户共享的目录中，由启动时的配置设置
+// 此时下载、解压都持有锁文件，不同用户同时安装同一个版本时不会写坏对方的安装包
+var Shared bool
+
+// sharedPerm 共享目录中的锁文件及未完成的下载同组用户可写，便于继续对方中断的下载，其它用户只读
+const sharedPerm = 0664
+
+// LockFile 以排它方式锁定 name(不存在时创建)，其它进程持有锁时提示后等待，返回释放锁的函数
+// 锁随进程退出由系统释放，中断或崩溃后不会留下需要手动删除的锁；锁文件本身保留，删除会使等待中的进程锁定已经删除的文件
+func LockFile(name string) (unlock func(), err error) {
+	return LockFileWait(name, func() {
+		fmt.Fprintf(os.Stderr, "waiting for another envm process holding %s\n", name)
+	})
+}
+
+// LockFileWait 同 LockFile，其它进程持有锁时调用 waiting 提示后等待
+func LockFileWait(name string, waiting func()) (unlock func(), err error) {
+	f, err := os.OpenFile(name, os.O_RDONLY|os.O_CREATE, sharedPerm)
+	if err != nil {
+		return nil, err
+	}
+	// 不受 umask 影响，其它用户创建的锁文件不能修改权限，忽略错误
+	_ = os.Chmod(name, sharedPerm)
+	locked, err := tryLock(f)
+	if err == nil && !locked {
+		waiting()
+		err = lock(f)
+	}
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("lock %s error + %w", name, err)
+	}
+	return func() { _ = f.Close() }, nil
+}
+
+// TryLockFile 不等待地锁定 name(不存在时创建)，其它进程持有锁时 locked 为 false
+// 持有锁的进程退出后锁由系统释放，可以据此判断记录了锁文件的进程是否仍在运行；aix/solaris 不支持 flock，总是可以锁定
+func TryLockFile(name string) (unlock func(), locked bool, err error) {
+	f, err := os.OpenFile(name, os.O_RDONLY|os.O_CREATE, sharedPerm)
+	if err != nil {
+		return nil, false, err
+	}
+	if locked, err = tryLock(f); err != nil || !locked {
+		_ = f.Close()
+		if err != nil {
+			return nil, false, fmt.Errorf("lock %s error + %w", name, err)
+		}
+		return nil, false, nil
+	}
+	return func() { _ = f.Close() }, true, nil
+}
@@ -0,0 +1,14 @@
+//go:build aix || solaris
+
+package util
+
+import "os"
+
+// tryLock aix/solaris 不支持 flock，不加锁
+func tryLock(f *os.File) (bool, error) {
+	return true, nil
+}
+
+func lock(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,43 @@
+//go:build windows
+
+package util
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var procLockFileEx = syscall.NewLazyDLL("kernel32.dll").NewProc("LockFileEx")
+
+const (
+	lockfileFailImmediately = 0x1
+	lockfileExclusiveLock   = 0x2
+	// errorLockViolation ERROR_LOCK_VIOLATION
+	errorLockViolation syscall.Errno = 33
+)
+
+// lockFileEx 锁定整个文件，关闭文件时释放
+func lockFileEx(f *os.File, flags uint32) error {
+	var overlapped syscall.Overlapped
+	ok, _, err := procLockFileEx.Call(f.Fd(), uintptr(flags), 0, 1, 0, uintptr(unsafe.Pointer(&overlapped)))
+	if ok == 0 {
+		return err
+	}
+	return nil
+}
+
+// tryLock 不等待地锁定 f，已经被其它进程锁定时返回 false
+func tryLock(f *os.File) (bool, error) {
+	err := lockFileEx(f, lockfileExclusiveLock|lockfileFailImmediately)
+	if errors.Is(err, errorLockViolation) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// lock 锁定 f，等待其它进程释放
+func lock(f *os.File) error {
+	return lockFileEx(f, lockfileExclusiveLock)
+}
@@ -0,0 +1,83 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+var (
+	// ErrInvalidSignature 签名文件或公钥格式错误
+	ErrInvalidSignature = newKindError(ErrChecksum, "invalid minisign signature")
+	// ErrSignatureNotMatched 签名校验失败
+	ErrSignatureNotMatched = newKindError(ErrChecksum, "file signature does not match the public key")
+)
+
+// VerifyMinisign 使用 minisign 公钥校验文件签名，同时支持 Ed(原始) 与 ED(blake2b 预哈希) 两种算法
+func VerifyMinisign(filename string, signature []byte, publicKey string) error {
+	defer Phase(PhaseVerify)()
+	pk, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(pk) != 42 || string(pk[:2]) != "Ed" {
+		return ErrInvalidSignature
+	}
+
+	lines := make([]string, 0, 4)
+	scanner := bufio.NewScanner(bytes.NewReader(signature))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) < 4 || !strings.HasPrefix(lines[2], "trusted comment: ") {
+		return ErrInvalidSignature
+	}
+	sig, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(sig) != 74 {
+		return ErrInvalidSignature
+	}
+	globalSig, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil || len(globalSig) != ed25519.SignatureSize {
+		return ErrInvalidSignature
+	}
+	if !bytes.Equal(sig[2:10], pk[2:10]) {
+		return ErrSignatureNotMatched
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var message []byte
+	switch string(sig[:2]) {
+	case "Ed":
+		if message, err = io.ReadAll(f); err != nil {
+			return err
+		}
+	case "ED":
+		h, _ := blake2b.New512(nil)
+		if _, err = io.Copy(h, f); err != nil {
+			return err
+		}
+		message = h.Sum(nil)
+	default:
+		return ErrInvalidSignature
+	}
+
+	key := ed25519.PublicKey(pk[10:])
+	if !ed25519.Verify(key, message, sig[10:]) {
+		return ErrSignatureNotMatched
+	}
+	trusted := append(append([]byte{}, sig[10:]...), strings.TrimPrefix(lines[2], "trusted comment: ")...)
+	if !ed25519.Verify(key, trusted, globalSig) {
+		return ErrSignatureNotMatched
+	}
+	return nil
+}
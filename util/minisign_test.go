@@ -0,0 +1,59 @@
+package util
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// minisign 生成的签名，公钥及签名取自 jedisct1/go-minisign 的测试，被签名的内容为 "test"
+const (
+	minisignPublicKey = "RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3"
+	// minisignLegacy Ed 算法，直接签名文件内容
+	minisignLegacy = "untrusted comment: signature from minisign secret key\n" +
+		"RWQf6LRCGA9i59SLOFxz6NxvASXDJeRtuZykwQepbDEGt87ig1BNpWaVWuNrm73YiIiJbq71Wi+dP9eKL8OC351vwIasSSbXxwA=\n" +
+		"trusted comment: timestamp:1635442742\tfile:test\n" +
+		"0YteLgV960ia80vnA/fHbvkyjl/IoP/HNOCaZfrF0CdhAlp7ok+Tpkya+VpWPX5C/Is3q8a/kEDSY7fBmmgJCg==\n"
+	// minisignPrehashed ED 算法，签名文件内容的 BLAKE2b-512
+	minisignPrehashed = "untrusted comment: signature from minisign secret key\n" +
+		"RUQf6LRCGA9i559r3g7V1qNyJDApGip8MfqcadIgT9CuhV3EMhHoN1mGTkUidF/z7SrlQgXdy8ofjb7bNJJylDOocrCo8KLzZwo=\n" +
+		"trusted comment: timestamp:1635443258\tfile:test\thashed\n" +
+		"/cj37GK60vryibFn+ftOgbCvW9NKhKYgjVpFFQUcWPAnjO23wrvVDTt7cloNC06maoBli9q6qwZDXXoaxweICQ==\n"
+)
+
+func TestVerifyMinisign(t *testing.T) {
+	Convey("校验 minisign 签名", t, func() {
+		dir := t.TempDir()
+		signed := filepath.Join(dir, "test")
+		So(os.WriteFile(signed, []byte("test"), 0644), ShouldBeNil)
+		tampered := filepath.Join(dir, "tampered")
+		So(os.WriteFile(tampered, []byte("tesT"), 0644), ShouldBeNil)
+
+		Convey("Ed 及 ED 算法的签名都可以通过校验", func() {
+			So(VerifyMinisign(signed, []byte(minisignLegacy), minisignPublicKey), ShouldBeNil)
+			So(VerifyMinisign(signed, []byte(minisignPrehashed), minisignPublicKey), ShouldBeNil)
+		})
+
+		Convey("内容被修改后校验失败", func() {
+			So(VerifyMinisign(tampered, []byte(minisignLegacy), minisignPublicKey), ShouldEqual, ErrSignatureNotMatched)
+			So(VerifyMinisign(tampered, []byte(minisignPrehashed), minisignPublicKey), ShouldEqual, ErrSignatureNotMatched)
+		})
+
+		Convey("trusted comment 被修改后校验失败", func() {
+			forged := strings.Replace(minisignPrehashed, "file:test", "file:evil", 1)
+			So(VerifyMinisign(signed, []byte(forged), minisignPublicKey), ShouldEqual, ErrSignatureNotMatched)
+		})
+
+		Convey("其它公钥或格式错误的签名", func() {
+			// Zig 的公钥，key id 与签名不同
+			So(VerifyMinisign(signed, []byte(minisignPrehashed), "RWSGOq2NVecA2UPNdBUZykf1CCb147pkmdtYxgb3Ti+JO/wCYvhbAb/U"), ShouldEqual, ErrSignatureNotMatched)
+			So(VerifyMinisign(signed, []byte("untrusted comment: x\n"), minisignPublicKey), ShouldEqual, ErrInvalidSignature)
+			So(VerifyMinisign(signed, []byte(minisignPrehashed), "not a key"), ShouldEqual, ErrInvalidSignature)
+			So(errors.Is(ErrSignatureNotMatched, ErrChecksum), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,49 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// probe 下载前 HEAD 请求得到的安装包信息
+type probe struct {
+	// size 服务端返回的文件大小，未知时为 -1
+	size int64
+	// noResume 服务端明确声明不支持 Range 请求(Accept-Ranges: none)
+	noResume bool
+}
+
+// head 在创建任何文件之前通过 HEAD 请求确认安装包存在，并得到大小及是否支持断点续传
+// 地址失效(404、410)、无法连接、文件名或大小与版本列表不一致时立即失败，不必等到 JDK、Flutter 等大文件下载完成
+// 服务端不支持 HEAD(405、501)或拒绝 HEAD(例如只对 GET 签名的地址返回 403)时不做判断，交给 GET 请求处理
+func (pkg *Package) head(ctx context.Context) (probe, error) {
+	unknown := probe{size: -1}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, pkg.URL, nil)
+	if err != nil {
+		return unknown, NewDownloadError(pkg.URL, err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return unknown, NewDownloadError(pkg.URL, err)
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusGone:
+		return unknown, NewDownloadError(pkg.URL, fmt.Errorf("unexpected status %s", resp.Status))
+	default:
+		return unknown, nil
+	}
+
+	pkg.FinalURL = resp.Request.URL.String()
+	if err = pkg.checkServedName(resp); err != nil {
+		return unknown, err
+	}
+	result := probe{size: resp.ContentLength, noResume: resp.Header.Get("Accept-Ranges") == "none"}
+	if expected, exact, ok := parseSize(pkg.Size); ok && result.size >= 0 && !sizeMatches(result.size, expected, exact) {
+		return unknown, &ArtifactError{URL: pkg.URL, FinalURL: pkg.FinalURL, Err: ErrArtifactMismatch,
+			Reason: fmt.Sprintf("the index lists %s but the server reports %d bytes", pkg.Size, result.size)}
+	}
+	return result, nil
+}
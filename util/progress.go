@@ -0,0 +1,202 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// drawInterval 两次输出进度的最小间隔，避免下载速度快时频繁刷新终端
+const drawInterval = 100 * time.Millisecond
+
+// plainBytes 纯文本进度每隔 10% 输出一行，大小未知时每隔 plainBytes 字节输出一行
+const plainBytes = 10 << 20
+
+// PlainProgress 进度逐行输出，不使用 \r 刷新同一行，用于非交互模式及输出不是终端(CI 日志、docker build)时
+var PlainProgress bool
+
+// progress 所有下载共用一行进度，只有它写终端，多个下载同时进行时不会互相覆盖
+// 只有一个下载时与之前的单行进度条相同，多个下载时显示合计进度及下载数量
+type progress struct {
+	sync.Mutex
+	bars  []*Bar
+	last  time.Time
+	width int   // 当前行已经输出的宽度，换成更短的内容时用空格覆盖
+	step  int64 // 纯文本进度已经输出的档位加一，0 表示还没有输出
+	// phases 正在进行的阶段，没有下载时在同一行显示最后一个阶段的 spinner
+	phases   []string
+	spinning bool
+	frame    int
+}
+
+// spinnerFrames spinner 的帧，只使用 ASCII，windows 控制台也可以正常显示
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+// spinnerLabels 显示 spinner 的阶段，下载有自己的进度条，激活很快，不显示
+var spinnerLabels = map[string]string{
+	PhaseResolve: "listing versions",
+	PhaseVerify:  "verifying checksum",
+	PhaseExtract: "extracting",
+}
+
+var renderer progress
+
+func (p *progress) add(bar *Bar) {
+	p.Lock()
+	defer p.Unlock()
+	p.bars = append(p.bars, bar)
+}
+
+// finish 标记 bar 结束，已经结束的下载仍然计入合计进度，全部结束后换行并开始新的一轮
+func (p *progress) finish(bar *Bar) {
+	p.Lock()
+	defer p.Unlock()
+	bar.done = true
+	for _, b := range p.bars {
+		if !b.done {
+			return
+		}
+	}
+	if len(p.bars) > 0 && (!Quiet || Events != nil) {
+		p.render()
+		if !Quiet && !PlainProgress {
+			fmt.Fprint(Output, "\n")
+		}
+	}
+	p.bars, p.width, p.step = nil, 0, 0
+}
+
+func (p *progress) draw(force bool) {
+	if Quiet && Events == nil {
+		return
+	}
+	p.Lock()
+	defer p.Unlock()
+	if !force && time.Since(p.last) < drawInterval {
+		return
+	}
+	p.render()
+}
+
+// render 在当前行输出进度并输出下载进度事件，Quiet 时只输出事件，调用方持有锁
+func (p *progress) render() {
+	if len(p.bars) == 0 {
+		return
+	}
+	var cur, total int64
+	for _, bar := range p.bars {
+		cur += atomic.LoadInt64(&bar.cur)
+		total += bar.total
+	}
+	Emit(Event{Event: EventDownloadProgress, Bytes: cur, Total: total})
+	if Quiet {
+		p.last = time.Now()
+		return
+	}
+	var line string
+	if total > 0 {
+		percent := float32(cur) / float32(total) * 100
+		line = fmt.Sprintf("[%-50s]%0.2f%% %8d/%d", strings.Repeat(p.bars[0].graph, int(percent)/2), percent, cur, total)
+	} else {
+		// 服务端没有返回 Content-Length
+		line = fmt.Sprintf("%8d bytes", cur)
+	}
+	if len(p.bars) > 1 {
+		line += fmt.Sprintf(" (%d downloads)", len(p.bars))
+	}
+	if PlainProgress {
+		step := cur/plainBytes + 1
+		if total > 0 {
+			step = cur*10/total + 1
+		}
+		if step != p.step {
+			fmt.Fprintln(Output, line)
+			p.step = step
+		}
+		p.last = time.Now()
+		return
+	}
+	p.overwrite(line)
+	p.last = time.Now()
+}
+
+// overwrite 用 line 覆盖当前行，调用方持有锁
+func (p *progress) overwrite(line string) {
+	padding := ""
+	if len(line) < p.width {
+		padding = strings.Repeat(" ", p.width-len(line))
+	}
+	fmt.Fprint(Output, "\r"+line+padding)
+	p.width = len(line)
+}
+
+// clear 清除当前行，调用方持有锁
+func (p *progress) clear() {
+	if p.width > 0 {
+		fmt.Fprint(Output, "\r"+strings.Repeat(" ", p.width)+"\r")
+		p.width = 0
+	}
+}
+
+// spin 开始显示阶段 name 的 spinner，返回结束的函数，Quiet、逐行进度及没有标签的阶段不显示
+// 有下载进行中时只显示下载进度
+func (p *progress) spin(name string) func() {
+	label, ok := spinnerLabels[name]
+	if !ok || Quiet || PlainProgress {
+		return func() {}
+	}
+	p.Lock()
+	defer p.Unlock()
+	p.phases = append(p.phases, label)
+	if !p.spinning {
+		p.spinning = true
+		go p.spinLoop()
+	}
+	return func() {
+		p.Lock()
+		defer p.Unlock()
+		for i := len(p.phases) - 1; i >= 0; i-- {
+			if p.phases[i] == label {
+				p.phases = append(p.phases[:i], p.phases[i+1:]...)
+				break
+			}
+		}
+		if len(p.phases) == 0 && len(p.bars) == 0 {
+			p.clear()
+		}
+	}
+}
+
+// spinLoop 每隔 drawInterval 刷新 spinner，所有阶段结束后退出
+func (p *progress) spinLoop() {
+	ticker := time.NewTicker(drawInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		p.Lock()
+		if len(p.phases) == 0 {
+			p.spinning = false
+			p.Unlock()
+			return
+		}
+		if len(p.bars) == 0 {
+			p.overwrite(spinnerFrames[p.frame%len(spinnerFrames)] + " " + p.phases[len(p.phases)-1] + "...")
+			p.frame++
+		}
+		p.Unlock()
+	}
+}
+
+// ProgressPrintln 在进度行之上输出一行信息，下载进行中时先清除进度行，输出后重新绘制
+func ProgressPrintln(message string) {
+	renderer.Lock()
+	defer renderer.Unlock()
+	if !Quiet && !PlainProgress {
+		renderer.clear()
+	}
+	fmt.Fprintln(Output, message)
+	if !Quiet {
+		renderer.render()
+	}
+}
@@ -0,0 +1,142 @@
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 不能在终端中询问时的回答方式，由全局参数设置
+var (
+	// AssumeYes --yes，所有询问直接回答 yes
+	AssumeYes bool
+	// NonInteractive --non-interactive，不读取标准输入，所有询问按 no 处理，标准输入不是终端时自动开启
+	NonInteractive bool
+	// DefaultYes 删除等破坏性操作询问时直接回车及非交互模式下的回答，由配置 confirm_default 设置
+	DefaultYes bool
+)
+
+// IsTerminal f 是否为终端，管道、重定向的文件及 docker build 中的标准输入都不是
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Confirm 在终端中询问 yes/no，直接回车或读取失败时返回 false
+// --yes 时直接返回 true，非交互模式直接返回 false，都不会等待输入
+func Confirm(question string) bool {
+	if AssumeYes {
+		fmt.Fprintf(Output, "%s [y/N] y (--yes)\n", question)
+		return true
+	}
+	if NonInteractive {
+		fmt.Fprintf(Output, "%s [y/N] n (non-interactive, pass --yes to accept)\n", question)
+		return false
+	}
+	fmt.Fprintf(Output, "%s [y/N] ", question)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Fprintln(Output)
+		return false
+	}
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes"
+}
+
+// ConfirmRemoval 卸载、删除、覆盖文件等破坏性操作前询问，与 Confirm 不同的是默认回答由 DefaultYes 决定
+// 配置了 confirm_default = "yes" 时直接回车及非交互模式都回答 yes，恢复不询问直接删除的行为
+func ConfirmRemoval(question string) bool {
+	if AssumeYes || !DefaultYes {
+		return Confirm(question)
+	}
+	if NonInteractive {
+		fmt.Fprintf(Output, "%s [Y/n] y (non-interactive, confirm_default = yes)\n", question)
+		return true
+	}
+	fmt.Fprintf(Output, "%s [Y/n] ", question)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Fprintln(Output)
+		return true
+	}
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "" || answer == "y" || answer == "yes"
+}
+
+// Choose 在终端中列出 items 供多选，输入编号(如 1 3 5-7)或 all，locked 中的编号(从 0 开始)不能选择
+// 直接回车表示不选，输入有误时重新询问，返回选中的编号(从 0 开始)
+// --yes 及非交互模式不等待输入，返回空，避免误删
+func Choose(question string, items []string, locked map[int]bool) []int {
+	fmt.Fprintln(Output, question)
+	for i, item := range items {
+		mark := "[ ]"
+		if locked[i] {
+			mark = "[-]"
+		}
+		fmt.Fprintf(Output, "  %s %2d) %s\n", mark, i+1, item)
+	}
+	if AssumeYes || NonInteractive {
+		fmt.Fprintln(Output, "nothing selected (non-interactive, pass the versions as arguments instead)")
+		return nil
+	}
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Fprint(Output, "select (e.g. 1 3 5-7, all, empty to cancel): ")
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
+			fmt.Fprintln(Output)
+			return nil
+		}
+		chosen, err := ParseChoice(line, len(items))
+		if err != nil {
+			fmt.Fprintln(Output, err)
+			continue
+		}
+		selected := make([]int, 0, len(chosen))
+		for _, i := range chosen {
+			if !locked[i] {
+				selected = append(selected, i)
+			} else if strings.TrimSpace(line) != "all" {
+				fmt.Fprintf(Output, "%d) can not be selected, skipped\n", i+1)
+			}
+		}
+		return selected
+	}
+}
+
+// ParseChoice 解析 Choose 的输入，编号可以用空格或逗号分隔，a-b 表示范围，all 表示全部
+// 返回去重排序后从 0 开始的编号，编号超出 1..n 时返回错误
+func ParseChoice(line string, n int) ([]int, error) {
+	line = strings.TrimSpace(line)
+	if line == "all" {
+		all := make([]int, n)
+		for i := range all {
+			all[i] = i
+		}
+		return all, nil
+	}
+	seen := map[int]bool{}
+	for _, field := range strings.FieldsFunc(line, func(r rune) bool { return r == ' ' || r == ',' }) {
+		low, high, isRange := strings.Cut(field, "-")
+		if !isRange {
+			high = low
+		}
+		from, err1 := strconv.Atoi(low)
+		to, err2 := strconv.Atoi(high)
+		if err1 != nil || err2 != nil || from < 1 || to > n || from > to {
+			return nil, fmt.Errorf("invalid selection %q, use numbers between 1 and %d", field, n)
+		}
+		for i := from; i <= to; i++ {
+			seen[i-1] = true
+		}
+	}
+	chosen := make([]int, 0, len(seen))
+	for i := range seen {
+		chosen = append(chosen, i)
+	}
+	sort.Ints(chosen)
+	return chosen, nil
+}
@@ -0,0 +1,34 @@
+package util
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseChoice(t *testing.T) {
+	Convey("解析多选的输入", t, func() {
+		chosen, err := ParseChoice("3 1,2", 5)
+		So(err, ShouldBeNil)
+		So(chosen, ShouldResemble, []int{0, 1, 2})
+
+		chosen, err = ParseChoice(" 2-4 3 4-4\n", 5)
+		So(err, ShouldBeNil)
+		So(chosen, ShouldResemble, []int{1, 2, 3})
+
+		chosen, err = ParseChoice("all", 3)
+		So(err, ShouldBeNil)
+		So(chosen, ShouldResemble, []int{0, 1, 2})
+
+		chosen, err = ParseChoice("\n", 3)
+		So(err, ShouldBeNil)
+		So(chosen, ShouldBeEmpty)
+	})
+
+	Convey("编号超出范围或格式错误", t, func() {
+		for _, line := range []string{"0", "4", "2-5", "3-1", "a", "1-", "-1"} {
+			_, err := ParseChoice(line, 3)
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package util
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ReadProperties 读取 java properties 格式的文件，只支持单行的 key=value
+func ReadProperties(filename string) (map[string]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	properties := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		index := strings.IndexAny(line, "=:")
+		if index < 0 {
+			continue
+		}
+		key := strings.TrimSpace(line[:index])
+		// properties 中 \: \= 为转义
+		value := strings.NewReplacer(`\:`, ":", `\=`, "=", `\\`, `\`).Replace(strings.TrimSpace(line[index+1:]))
+		properties[key] = value
+	}
+	return properties, scanner.Err()
+}
+
+// FindUpward 从 dir 开始逐级向上查找 name，返回找到的完整路径
+func FindUpward(dir, name string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		candidate := filepath.Join(dir, name)
+		if exists, _ := PathExists(candidate); exists {
+			return candidate, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,17 @@
+package util
+
+import (
+	"os"
+	"strconv"
+)
+
+// TerminalWidth 标准输出所在终端的列数，环境变量 COLUMNS 优先，输出不是终端或无法获取时返回 0 表示不限制宽度
+func TerminalWidth() int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	if !IsTerminal(os.Stdout) {
+		return 0
+	}
+	return terminalWidth(os.Stdout)
+}
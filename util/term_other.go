@@ -0,0 +1,10 @@
+//go:build aix || solaris
+
+package util
+
+import "os"
+
+// terminalWidth 这些系统的 syscall 包没有提供 ioctl，只能通过 COLUMNS 设置宽度
+func terminalWidth(f *os.File) int {
+	return 0
+}
@@ -0,0 +1,20 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package util
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// terminalWidth 通过 TIOCGWINSZ 获取终端的列数
+func terminalWidth(f *os.File) int {
+	var size struct {
+		Row, Col, X, Y uint16
+	}
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&size))); errno != 0 {
+		return 0
+	}
+	return int(size.Col)
+}
@@ -0,0 +1,28 @@
+//go:build windows
+
+package util
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var procGetConsoleScreenBufferInfo = syscall.NewLazyDLL("kernel32.dll").NewProc("GetConsoleScreenBufferInfo")
+
+// consoleScreenBufferInfo CONSOLE_SCREEN_BUFFER_INFO
+type consoleScreenBufferInfo struct {
+	Size, CursorPosition     struct{ X, Y int16 }
+	Attributes               uint16
+	Left, Top, Right, Bottom int16
+	MaximumWindowSize        struct{ X, Y int16 }
+}
+
+// terminalWidth 控制台窗口(而不是缓冲区)的列数
+func terminalWidth(f *os.File) int {
+	var info consoleScreenBufferInfo
+	if ok, _, _ := procGetConsoleScreenBufferInfo.Call(f.Fd(), uintptr(unsafe.Pointer(&info))); ok == 0 {
+		return 0
+	}
+	return int(info.Right-info.Left) + 1
+}
@@ -0,0 +1,78 @@
+package util
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// 安装过程的阶段，--timings 按这些名称汇总耗时
+const (
+	PhaseResolve  = "resolve"
+	PhaseDownload = "download"
+	PhaseVerify   = "verify"
+	PhaseExtract  = "extract"
+	PhaseActivate = "activate"
+)
+
+var timings struct {
+	sync.Mutex
+	enabled bool
+	start   time.Time
+	names   []string
+	spent   map[string]time.Duration
+}
+
+// EnableTimings 开始记录各阶段耗时，未开启时 Phase 不做任何事
+func EnableTimings() {
+	timings.Lock()
+	defer timings.Unlock()
+	timings.enabled = true
+	timings.start = time.Now()
+	timings.spent = map[string]time.Duration{}
+}
+
+// Phase 开始计时一个阶段，返回结束计时的函数，用法为 defer util.Phase(util.PhaseDownload)()
+// 同名阶段多次出现时耗时累加，例如 sync 安装多个语言，开启了 Events 时输出阶段开始的事件
+// 终端中没有下载进度时显示该阶段的 spinner，避免解压、校验等耗时操作看起来像卡住
+func Phase(name string) func() {
+	Emit(Event{Event: name})
+	stop := renderer.spin(name)
+	timings.Lock()
+	enabled := timings.enabled
+	timings.Unlock()
+	if !enabled {
+		return stop
+	}
+	start := time.Now()
+	return func() {
+		stop()
+		timings.Lock()
+		defer timings.Unlock()
+		if _, ok := timings.spent[name]; !ok {
+			timings.names = append(timings.names, name)
+		}
+		timings.spent[name] += time.Since(start)
+	}
+}
+
+// PrintTimings 输出各阶段耗时，未计入任何阶段的时间(启动、查询版本列表等)记为 other
+func PrintTimings(w io.Writer) {
+	timings.Lock()
+	defer timings.Unlock()
+	if !timings.enabled {
+		return
+	}
+	total := time.Since(timings.start)
+	other := total
+	for _, name := range timings.names {
+		fmt.Fprintf(w, "%-10s %s\n", name, timings.spent[name].Round(time.Millisecond))
+		other -= timings.spent[name]
+	}
+	if other < 0 {
+		other = 0
+	}
+	fmt.Fprintf(w, "%-10s %s\n", "other", other.Round(time.Millisecond))
+	fmt.Fprintf(w, "%-10s %s\n", "total", total.Round(time.Millisecond))
+}
@@ -7,6 +7,9 @@ import (
 // ErrVersionNotFound 版本不存在
 var ErrVersionNotFound = errors.New("version not found")
 
+// ErrNoVersions 来源可以访问但解析不到任何版本，通常是页面结构发生了变化，不表示没有可以安装的版本
+var ErrNoVersions = errors.New("the source lists no versions")
+
 // FindVersion 返回指定名称的版本
 func FindVersion(all []*Version, name string) (*Version, error) {
 	for i := range all {
@@ -38,47 +38,6 @@ func TestFindVersion(t *testing.T) {
 	})
 }
 
-func TestFindPackage(t *testing.T) {
-	Convey("查询版本下的安装包", t, func() {
-		v := &Version{
-			Name: "1.12.4",
-			Packages: []*Package{
-				{
-					FileName: "go1.12.4.src.tar.gz",
-					Kind:     SourceKind,
-					Size:     "21MB",
-				},
-				{
-					FileName: "go1.12.4.darwin-amd64.tar.gz",
-					Kind:     ArchiveKind,
-					OS:       "macOS",
-					Arch:     "x86-64",
-					Size:     "122MB",
-				},
-				{
-					FileName: "go1.12.4.windows-386.msi",
-					Kind:     InstallerKind,
-					OS:       "Windows",
-					Arch:     "x86",
-					Size:     "102MB",
-				},
-			},
-		}
-
-		pkg, err := v.FindPackage(ArchiveKind, "darwin", "amd64")
-		So(err, ShouldBeNil)
-		So(pkg, ShouldNotBeNil)
-		So(pkg.FileName, ShouldEqual, "go1.12.4.darwin-amd64.tar.gz")
-		So(pkg.Kind, ShouldEqual, ArchiveKind)
-		So(pkg.OS, ShouldEqual, "macOS")
-		So(pkg.Arch, ShouldEqual, "x86-64")
-
-		pkg, err = v.FindPackage(ArchiveKind, "darwin", "386")
-		So(err, ShouldEqual, ErrPackageNotFound)
-		So(pkg, ShouldBeNil)
-	})
-}
-
 func TestDownloadError(t *testing.T) {
 	Convey("安装包下载错误", t, func() {
 		url := "https://dl.google.com/go/go1.12.5.linux-amd64.tar.gz"